@@ -0,0 +1,233 @@
+// Command bench compares the position solvers on random scenes over a grid of
+// dimensions and sensor counts and prints their accuracy next to their speed, so a
+// regression in either shows up before it reaches the simulation:
+//
+//	go run ./cmd/bench -dims 2-10 -sensors 4,10,30,100 -solvers ls,wls,nonlinear,robust
+//
+// Every solver sees the same scenes. Sensors are placed uniformly in [-100, 100]^dim
+// around the target and each has its own range noise sigma, drawn from [noise/2, 2*noise];
+// -outliers adds that fraction of non-line-of-sight ranges (too long by up to 50 units).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// solvers maps -solvers names to solvers.
+var solvers = map[string]multilateration.SolveFunc{
+	"ls":        multilateration.SolveLeastSquares,
+	"wls":       multilateration.SolveWeightedLeastSquares,
+	"nonlinear": multilateration.RobustSolver(multilateration.RobustOptions{HuberThreshold: math.Inf(1)}),
+	"robust":    multilateration.RobustSolver(multilateration.DefaultRobustOptions()),
+	"toa":       multilateration.SolvePseudoranges,
+}
+
+// result is one row of the report.
+type result struct {
+	dimension, sensors int
+	solver             string
+	rmse, median, max  float64 // Localization error over the solved scenes
+	failures           int
+	nsPerOp            float64
+	allocsPerOp        float64
+	bytesPerOp         float64
+}
+
+func main() {
+	dimList := flag.String("dims", "2-10", "dimensions, comma separated values or ranges (e.g. 2,3,5-10)")
+	sensorList := flag.String("sensors", "4,10,30,100", "sensor counts, comma separated values or ranges; counts below dimension+1 are skipped")
+	solverNames := flag.String("solvers", "ls,wls,nonlinear,robust", "comma separated solvers: ls, wls, nonlinear, robust or toa")
+	scenes := flag.Int("scenes", 200, "random scenes per dimension and sensor count")
+	noise := flag.Float64("noise", 0.5, "typical range noise sigma (units)")
+	outliers := flag.Float64("outliers", 0, "fraction of ranges with a non-line-of-sight bias")
+	minTime := flag.Duration("time", 100*time.Millisecond, "minimum timing duration per solver and configuration")
+	seed := flag.Int64("seed", 1, "seed of the random scenes")
+	flag.Parse()
+
+	dims, err := parseInts(*dimList)
+	if err != nil {
+		log.Fatalf("Invalid -dims: %v", err)
+	}
+	sensorCounts, err := parseInts(*sensorList)
+	if err != nil {
+		log.Fatalf("Invalid -sensors: %v", err)
+	}
+	names := split(*solverNames)
+	for _, name := range names {
+		if _, ok := solvers[name]; !ok {
+			log.Fatalf("unknown solver %q", name)
+		}
+	}
+	if *scenes < 1 {
+		log.Fatalf("-scenes must be at least 1, got %d", *scenes)
+	}
+	if *outliers < 0 || *outliers > 1 {
+		log.Fatalf("-outliers must be in [0, 1], got %g", *outliers)
+	}
+
+	var results []result
+	for _, dim := range dims {
+		for _, count := range sensorCounts {
+			if dim < 1 || count < dim+1 {
+				continue
+			}
+			rng := rand.New(rand.NewSource(*seed + int64(dim)*1000 + int64(count)))
+			measurements := make([][]multilateration.Measurement, *scenes)
+			targets := make([]common.Vector, *scenes)
+			for i := range measurements {
+				measurements[i], targets[i] = randomScene(rng, dim, count, *noise, *outliers)
+			}
+			for _, name := range names {
+				results = append(results, run(name, solvers[name], dim, count, measurements, targets, *minTime))
+			}
+		}
+	}
+	if len(results) == 0 {
+		log.Fatal("no configuration to run, every sensor count is below dimension+1")
+	}
+	printResults(os.Stdout, results)
+}
+
+// run measures the accuracy of a solver over the scenes and then times it by cycling
+// through them until minTime has passed.
+func run(name string, solve multilateration.SolveFunc, dim, count int, measurements [][]multilateration.Measurement, targets []common.Vector, minTime time.Duration) result {
+	r := result{dimension: dim, sensors: count, solver: name}
+	var errs []float64
+	for i, m := range measurements {
+		solution, err := solve(m, dim)
+		if err != nil {
+			r.failures++
+			continue
+		}
+		e, err := solution.Position.Distance(targets[i])
+		if err != nil {
+			r.failures++
+			continue
+		}
+		errs = append(errs, e)
+	}
+	r.rmse, r.median, r.max = summarizeErrors(errs)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	ops := 0
+	for elapsed := time.Duration(0); elapsed < minTime; elapsed = time.Since(start) {
+		for _, m := range measurements {
+			_, _ = solve(m, dim)
+		}
+		ops += len(measurements)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	r.nsPerOp = float64(elapsed.Nanoseconds()) / float64(ops)
+	r.allocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(ops)
+	r.bytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(ops)
+	return r
+}
+
+// randomScene places count sensors in [-100, 100]^dim around a random target.
+func randomScene(rng *rand.Rand, dim, count int, noise, outliers float64) ([]multilateration.Measurement, common.Vector) {
+	randomPoint := func() common.Vector {
+		v := common.NewVector(dim)
+		for i := range v {
+			v[i] = rng.Float64()*200 - 100
+		}
+		return v
+	}
+	target := randomPoint()
+	measurements := make([]multilateration.Measurement, count)
+	for i := range measurements {
+		pos := randomPoint()
+		dist, _ := pos.Distance(target)
+		sigma := noise * (0.5 + 1.5*rng.Float64())
+		dist += rng.NormFloat64() * sigma
+		if rng.Float64() < outliers {
+			dist += 50 * rng.Float64()
+		}
+		measurements[i] = multilateration.Measurement{SensorID: strconv.Itoa(i), SensorPosition: pos, Distance: dist, Variance: sigma * sigma}
+	}
+	return measurements, target
+}
+
+func printResults(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "dim\tsensors\tsolver\tRMSE\tmedian\tmax\tfail\tns/op\tallocs/op\tB/op\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%.3f\t%.3f\t%.3f\t%d\t%.0f\t%.0f\t%.0f\t\n", r.dimension, r.sensors, r.solver,
+			r.rmse, r.median, r.max, r.failures, r.nsPerOp, r.allocsPerOp, r.bytesPerOp)
+	}
+	tw.Flush()
+}
+
+// summarizeErrors returns the RMSE, median and maximum of the errors, NaN if there are none.
+func summarizeErrors(errs []float64) (rmse, median, max float64) {
+	if len(errs) == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	sorted := append([]float64(nil), errs...)
+	sort.Float64s(sorted)
+	sumSq := 0.0
+	for _, e := range sorted {
+		sumSq += e * e
+	}
+	if mid := len(sorted) / 2; len(sorted)%2 == 1 {
+		median = sorted[mid]
+	} else {
+		median = (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return math.Sqrt(sumSq / float64(len(sorted))), median, sorted[len(sorted)-1]
+}
+
+// parseInts parses a comma separated list of integers and inclusive ranges like 2-10.
+func parseInts(list string) ([]int, error) {
+	var values []int
+	for _, item := range split(list) {
+		lo, hi, isRange := strings.Cut(item, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				return nil, err
+			}
+			if last < first {
+				return nil, fmt.Errorf("empty range %q", item)
+			}
+		}
+		for v := first; v <= last; v++ {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values in %q", list)
+	}
+	return values, nil
+}
+
+func split(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
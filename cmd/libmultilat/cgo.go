@@ -0,0 +1,35 @@
+//go:build cgo
+
+package main
+
+import "C"
+
+import "unsafe"
+
+// cDoubles passes a Go slice where the API takes a C double array, NULL for an empty
+// one. Test files cannot import "C", so the tests call the exported functions through
+// these conversions.
+func cDoubles(xs []float64) *C.double {
+	if len(xs) == 0 {
+		return nil
+	}
+	return (*C.double)(unsafe.Pointer(&xs[0]))
+}
+
+// cInt converts a size, method or handle.
+func cInt(i int) C.int {
+	return C.int(i)
+}
+
+// cChars passes a Go buffer where ml_last_error takes a C string buffer.
+func cChars(buf []byte) *C.char {
+	if len(buf) == 0 {
+		return nil
+	}
+	return (*C.char)(unsafe.Pointer(&buf[0]))
+}
+
+// cDouble converts a time or a sigma.
+func cDouble(f float64) C.double {
+	return C.double(f)
+}
@@ -0,0 +1,234 @@
+//go:build cgo
+
+// Command libmultilat exposes the localization core as a C shared library, so
+// non-Go pipelines can call the same solvers and trackers the simulator validates:
+//
+//	go build -buildmode=c-shared -o libmultilat.so ./cmd/libmultilat
+//
+// The build also writes libmultilat.h with the exported prototypes. Positions are
+// passed as flat row-major double arrays (n sensors x dim coordinates). Every
+// function returns ML_OK (0) on success or a negative error code; the message of
+// the last error is available through ml_last_error. All functions may be called from
+// several threads; updates of the same tracker handle run one at a time. See
+// examples/python for a ctypes wrapper.
+package main
+
+/*
+#include <stddef.h>
+
+enum {
+	ML_OK = 0,
+	ML_ERR_ARGUMENT = -1, // Invalid pointer, size or option
+	ML_ERR_SOLVE = -2,    // The solver or tracker failed, e.g. degenerate geometry
+	ML_ERR_HANDLE = -3,   // Unknown tracker handle
+};
+
+enum {
+	ML_METHOD_LEAST_SQUARES = 0,
+	ML_METHOD_WEIGHTED = 1,
+	ML_METHOD_ROBUST = 2,
+};
+*/
+import "C"
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+	"sync"
+	"unsafe"
+)
+
+var (
+	mu        sync.Mutex
+	lastError string
+	trackers        = make(map[C.int]*trackerEntry)
+	nextID    C.int = 1
+)
+
+// trackerEntry is a tracker created through ml_tracker_new.
+type trackerEntry struct {
+	mu        sync.Mutex // Serializes updates, the tracker is not safe for concurrent use
+	tracker   tracking.Tracker
+	dimension int
+}
+
+// fail records err as the last error and returns code.
+func fail(code C.int, err error) C.int {
+	mu.Lock()
+	lastError = err.Error()
+	mu.Unlock()
+	return code
+}
+
+// doubles views a C array of n doubles as a Go slice, nil for a NULL pointer.
+func doubles(ptr *C.double, n int) []float64 {
+	if ptr == nil || n <= 0 {
+		return nil
+	}
+	return unsafe.Slice((*float64)(unsafe.Pointer(ptr)), n)
+}
+
+// measurements builds the solver input from flat sensor positions, ranges and
+// optional variances (NULL means unknown).
+func measurements(sensors, distances, variances *C.double, n, dim C.int) ([]multilateration.Measurement, error) {
+	if sensors == nil || distances == nil || n <= 0 || dim <= 0 {
+		return nil, fmt.Errorf("sensors and distances are required, got n=%d dim=%d", n, dim)
+	}
+	positions := doubles(sensors, int(n)*int(dim)) // Multiplied as int, C.int could overflow
+	ranges := doubles(distances, int(n))
+	vars := doubles(variances, int(n))
+
+	ms := make([]multilateration.Measurement, n)
+	for i := range ms {
+		pos := common.NewVector(int(dim))
+		copy(pos, positions[i*int(dim):(i+1)*int(dim)])
+		ms[i] = multilateration.Measurement{SensorPosition: pos, Distance: ranges[i]}
+		if vars != nil {
+			ms[i].Variance = vars[i]
+		}
+	}
+	return ms, nil
+}
+
+//export ml_last_error
+func ml_last_error(buf *C.char, size C.int) C.int {
+	mu.Lock()
+	msg := lastError
+	mu.Unlock()
+	if buf != nil && size > 0 {
+		out := unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(size))
+		n := copy(out[:len(out)-1], msg)
+		out[n] = 0
+	}
+	return C.int(len(msg)) // Full length, so callers can detect truncation
+}
+
+//export ml_solve
+func ml_solve(method C.int, sensors, distances, variances *C.double, n, dim C.int, outPosition, outResidual, outGDOP *C.double) C.int {
+	if outPosition == nil {
+		return fail(C.ML_ERR_ARGUMENT, fmt.Errorf("output position is NULL"))
+	}
+	ms, err := measurements(sensors, distances, variances, n, dim)
+	if err != nil {
+		return fail(C.ML_ERR_ARGUMENT, err)
+	}
+
+	var sol multilateration.Solution
+	switch method {
+	case C.ML_METHOD_LEAST_SQUARES:
+		sol, err = multilateration.SolveLeastSquares(ms, int(dim))
+	case C.ML_METHOD_WEIGHTED:
+		sol, err = multilateration.SolveWeightedLeastSquares(ms, int(dim))
+	case C.ML_METHOD_ROBUST:
+		sol, err = multilateration.SolveRobust(ms, int(dim), multilateration.DefaultRobustOptions())
+	default:
+		return fail(C.ML_ERR_ARGUMENT, fmt.Errorf("unknown method %d", method))
+	}
+	if err != nil {
+		return fail(C.ML_ERR_SOLVE, err)
+	}
+
+	copy(doubles(outPosition, int(dim)), sol.Position)
+	if outResidual != nil {
+		*outResidual = C.double(sol.ResidualError)
+	}
+	if outGDOP != nil {
+		*outGDOP = C.double(sol.GDOP)
+	}
+	return C.ML_OK
+}
+
+//export ml_dop
+func ml_dop(sensors *C.double, n C.int, position *C.double, dim C.int, outGDOP, outHDOP, outVDOP *C.double) C.int {
+	if sensors == nil || position == nil || n <= 0 || dim <= 0 {
+		return fail(C.ML_ERR_ARGUMENT, fmt.Errorf("sensors and position are required, got n=%d dim=%d", n, dim))
+	}
+	flat := doubles(sensors, int(n)*int(dim))
+	sensorPositions := make([]common.Vector, n)
+	for i := range sensorPositions {
+		sensorPositions[i] = common.Vector(flat[i*int(dim) : (i+1)*int(dim)]).Clone()
+	}
+	dop, err := multilateration.ComputeDOP(sensorPositions, common.Vector(doubles(position, int(dim))).Clone())
+	if err != nil {
+		return fail(C.ML_ERR_SOLVE, err)
+	}
+	if outGDOP != nil {
+		*outGDOP = C.double(dop.GDOP)
+	}
+	if outHDOP != nil {
+		*outHDOP = C.double(dop.HDOP)
+	}
+	if outVDOP != nil {
+		*outVDOP = C.double(dop.VDOP)
+	}
+	return C.ML_OK
+}
+
+// ml_tracker_new creates a sliding-window factor graph tracker and returns its
+// handle (positive) or an error code. window <= 0 and range_sigma <= 0 keep the defaults.
+//
+//export ml_tracker_new
+func ml_tracker_new(dim, window C.int, rangeSigma C.double) C.int {
+	config := tracking.DefaultSmootherConfig()
+	if window > 0 {
+		config.Window = int(window)
+	}
+	if rangeSigma > 0 {
+		config.RangeSigma = float64(rangeSigma)
+	}
+	tracker, err := tracking.NewFactorGraphSmoother(int(dim), config)
+	if err != nil {
+		return fail(C.ML_ERR_ARGUMENT, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	handle := nextID
+	nextID++
+	trackers[handle] = &trackerEntry{tracker: tracker, dimension: int(dim)}
+	return handle
+}
+
+// ml_tracker_update feeds the ranges taken at time t (seconds) to a tracker and
+// writes the filtered position.
+//
+//export ml_tracker_update
+func ml_tracker_update(handle C.int, t C.double, sensors, distances, variances *C.double, n, dim C.int, outPosition *C.double) C.int {
+	mu.Lock()
+	entry, ok := trackers[handle]
+	mu.Unlock()
+	if !ok {
+		return fail(C.ML_ERR_HANDLE, fmt.Errorf("unknown tracker handle %d", handle))
+	}
+	if int(dim) != entry.dimension {
+		return fail(C.ML_ERR_ARGUMENT, fmt.Errorf("dimension %d does not match the tracker dimension %d", dim, entry.dimension))
+	}
+	if outPosition == nil {
+		return fail(C.ML_ERR_ARGUMENT, fmt.Errorf("output position is NULL"))
+	}
+	ms, err := measurements(sensors, distances, variances, n, dim)
+	if err != nil {
+		return fail(C.ML_ERR_ARGUMENT, err)
+	}
+	for i := range ms {
+		ms[i].Timestamp = float64(t)
+	}
+	entry.mu.Lock()
+	position, err := entry.tracker.Update(float64(t), ms, nil)
+	entry.mu.Unlock()
+	if err != nil {
+		return fail(C.ML_ERR_SOLVE, err)
+	}
+	copy(doubles(outPosition, int(dim)), position)
+	return C.ML_OK
+}
+
+//export ml_tracker_free
+func ml_tracker_free(handle C.int) {
+	mu.Lock()
+	delete(trackers, handle)
+	mu.Unlock()
+}
+
+func main() {} // Required by -buildmode=c-shared
@@ -0,0 +1,193 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Return codes of the API, see the enum in main.go.
+const (
+	codeOK       = 0
+	codeArgument = -1
+	codeSolve    = -2
+	codeHandle   = -3
+)
+
+// square holds four sensors on the corners of a 10 x 10 square, row-major, and their
+// exact ranges to (3, 4).
+var (
+	square       = []float64{0, 0, 10, 0, 0, 10, 10, 10}
+	squareTarget = []float64{3, 4}
+)
+
+func squareRanges() []float64 {
+	ranges := make([]float64, 4)
+	for i := range ranges {
+		ranges[i] = math.Hypot(square[2*i]-squareTarget[0], square[2*i+1]-squareTarget[1])
+	}
+	return ranges
+}
+
+// lastErrorMessage returns the message of ml_last_error.
+func lastErrorMessage(t *testing.T) string {
+	t.Helper()
+	buf := make([]byte, 256)
+	n := int(ml_last_error(cChars(buf), cInt(len(buf))))
+	msg := string(buf[:bytes.IndexByte(buf, 0)])
+	if n != len(msg) {
+		t.Errorf("ml_last_error returned length %d for %q", n, msg)
+	}
+	return msg
+}
+
+func TestSolve(t *testing.T) {
+	ranges := squareRanges()
+	sensors := []common.Vector{square[0:2], square[2:4], square[4:6], square[6:8]}
+	dop, err := multilateration.ComputeDOP(sensors, squareTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []int{0, 1, 2} {
+		position, out := make([]float64, 2), make([]float64, 2)
+		code := ml_solve(cInt(method), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2),
+			cDoubles(position), cDoubles(out[:1]), cDoubles(out[1:]))
+		if code != codeOK {
+			t.Fatalf("method %d: code %d, %s", method, code, lastErrorMessage(t))
+		}
+		if math.Hypot(position[0]-3, position[1]-4) > 1e-6 {
+			t.Errorf("method %d: position %v, want %v", method, position, squareTarget)
+		}
+		if residual, gdop := out[0], out[1]; residual > 1e-6 || math.Abs(gdop-dop.GDOP) > 1e-6 {
+			t.Errorf("method %d: residual %g, GDOP %g, want 0 and %g", method, residual, gdop, dop.GDOP)
+		}
+		// The residual and GDOP outputs are optional
+		if code := ml_solve(cInt(method), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2), cDoubles(position), nil, nil); code != codeOK {
+			t.Errorf("method %d without optional outputs: code %d", method, code)
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	ranges := squareRanges()
+	position := make([]float64, 2)
+	tests := []struct {
+		name    string
+		code    func() int
+		want    int
+		message string
+	}{
+		{"unknown method", func() int {
+			return int(ml_solve(cInt(7), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2), cDoubles(position), nil, nil))
+		}, codeArgument, "unknown method 7"},
+		{"NULL output", func() int {
+			return int(ml_solve(cInt(0), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2), nil, nil, nil))
+		}, codeArgument, "output position is NULL"},
+		{"no sensors", func() int {
+			return int(ml_solve(cInt(0), nil, cDoubles(ranges), nil, cInt(4), cInt(2), cDoubles(position), nil, nil))
+		}, codeArgument, "sensors and distances are required"},
+		{"zero dimension", func() int {
+			return int(ml_solve(cInt(0), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(0), cDoubles(position), nil, nil))
+		}, codeArgument, "sensors and distances are required"},
+		{"collinear sensors", func() int {
+			line := []float64{0, 0, 5, 0, 10, 0}
+			return int(ml_solve(cInt(0), cDoubles(line), cDoubles(ranges[:3]), nil, cInt(3), cInt(2), cDoubles(position), nil, nil))
+		}, codeSolve, ""},
+		{"DOP without position", func() int {
+			return int(ml_dop(cDoubles(square), cInt(4), nil, cInt(2), nil, nil, nil))
+		}, codeArgument, "sensors and position are required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.code(); got != tt.want {
+				t.Fatalf("code %d, want %d", got, tt.want)
+			}
+			if msg := lastErrorMessage(t); !strings.Contains(msg, tt.message) || msg == "" {
+				t.Errorf("last error %q, want it to contain %q", msg, tt.message)
+			}
+		})
+	}
+}
+
+func TestLastErrorTruncates(t *testing.T) {
+	ml_solve(cInt(7), cDoubles(square), cDoubles(squareRanges()), nil, cInt(4), cInt(2), cDoubles(make([]float64, 2)), nil, nil)
+	buf := make([]byte, 5)
+	if n := int(ml_last_error(cChars(buf), cInt(len(buf)))); n != len("unknown method 7") {
+		t.Errorf("full length %d, want %d", n, len("unknown method 7"))
+	}
+	if got := string(buf); got != "unkn\x00" {
+		t.Errorf("buffer %q, want the message cut to fit and NUL-terminated", got)
+	}
+}
+
+func TestDOP(t *testing.T) {
+	sensors := []float64{-10, 0, 10, 0, 0, 10}
+	out := make([]float64, 2)
+	code := ml_dop(cDoubles(sensors), cInt(3), cDoubles([]float64{0, 0}), cInt(2), cDoubles(out[:1]), cDoubles(out[1:]), nil)
+	if gdop, hdop := out[0], out[1]; code != codeOK || math.Abs(gdop-1.2247) > 1e-4 || math.Abs(hdop-gdop) > 1e-12 {
+		t.Errorf("code %d, GDOP %g, HDOP %g, want 1.2247 for both", code, gdop, hdop)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	handle := ml_tracker_new(cInt(2), cInt(5), cDouble(0.1))
+	if handle <= 0 {
+		t.Fatalf("ml_tracker_new: %d, %s", handle, lastErrorMessage(t))
+	}
+	ranges := squareRanges()
+	position := make([]float64, 2)
+	for step := range 5 {
+		if code := ml_tracker_update(handle, cDouble(0.1*float64(step)), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2), cDoubles(position)); code != codeOK {
+			t.Fatalf("update %d: code %d, %s", step, code, lastErrorMessage(t))
+		}
+	}
+	if math.Hypot(position[0]-3, position[1]-4) > 1e-3 {
+		t.Errorf("tracked position %v, want %v", position, squareTarget)
+	}
+
+	if code := ml_tracker_update(handle, 1, cDoubles([]float64{0, 0, 0}), cDoubles(ranges[:1]), nil, cInt(1), cInt(3), cDoubles(position)); code != codeArgument {
+		t.Errorf("update with dimension 3: code %d, want %d", code, codeArgument)
+	}
+	if code := ml_tracker_new(cInt(0), cInt(0), 0); code != codeArgument {
+		t.Errorf("tracker of dimension 0: code %d, want %d", code, codeArgument)
+	}
+	ml_tracker_free(handle)
+	if code := ml_tracker_update(handle, 1, cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2), cDoubles(position)); code != codeHandle {
+		t.Errorf("update after free: code %d, want %d", code, codeHandle)
+	}
+	if msg := lastErrorMessage(t); !strings.Contains(msg, "unknown tracker handle") {
+		t.Errorf("last error %q", msg)
+	}
+}
+
+// Threads sharing a handle take turns on the tracker; run with -race.
+func TestTrackerConcurrentUpdates(t *testing.T) {
+	handle := ml_tracker_new(cInt(2), cInt(20), cDouble(0.1))
+	if handle <= 0 {
+		t.Fatalf("ml_tracker_new: %d", handle)
+	}
+	defer ml_tracker_free(handle)
+	ranges := squareRanges()
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			position := make([]float64, 2)
+			for step := range 10 {
+				ts := float64(g) + 0.01*float64(step)
+				if code := ml_tracker_update(handle, cDouble(ts), cDoubles(square), cDoubles(ranges), nil, cInt(4), cInt(2), cDoubles(position)); code != codeOK {
+					t.Errorf("update: code %d", code)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
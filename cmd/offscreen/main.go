@@ -0,0 +1,81 @@
+// Command offscreen runs a scenario headlessly and renders it to PNG frames, an animated
+// GIF and/or a contact sheet of key moments, without opening a window:
+//
+//	go run ./cmd/offscreen -scenario scenario.json -steps 300 -frames out/ -gif run.gif -sheet sheet.png
+package main
+
+import (
+	"flag"
+	"log"
+	"multilateration-sim/internal/offscreen"
+	"multilateration-sim/internal/scenario"
+	"multilateration-sim/internal/simulation"
+	"time"
+)
+
+func main() {
+	scenarioPath := flag.String("scenario", "", "scenario file to run (required)")
+	steps := flag.Int("steps", 300, "number of simulation steps to record")
+	framesDir := flag.String("frames", "", "directory for frame_NNNNN.png images, empty to skip")
+	frameStep := flag.Int("every", 1, "render every n-th step as a frame")
+	gifPath := flag.String("gif", "", "animated GIF path (of every n-th step, like -frames), empty to skip")
+	gifDelay := flag.Duration("gif-delay", 0, "display time of each GIF frame, 0 for the simulated time between frames")
+	sheetPath := flag.String("sheet", "", "contact sheet PNG path, empty to skip")
+	sheetCount := flag.Int("sheet-count", 12, "number of key frames on the contact sheet")
+	sheetColumns := flag.Int("sheet-columns", 4, "columns of the contact sheet")
+	width := flag.Int("width", 800, "frame width in pixels")
+	height := flag.Int("height", 600, "frame height in pixels")
+	axisX := flag.Int("axis-x", 0, "simulation axis drawn horizontally")
+	axisY := flag.Int("axis-y", 1, "simulation axis drawn vertically")
+	trail := flag.Int("trail", 50, "trail length in steps, 0 to disable")
+	flag.Parse()
+
+	if *scenarioPath == "" || (*framesDir == "" && *gifPath == "" && *sheetPath == "") {
+		flag.Usage()
+		log.Fatal("a scenario and at least one of -frames, -gif or -sheet are required")
+	}
+	sc, err := scenario.Load(*scenarioPath)
+	if err != nil {
+		log.Fatalf("Error loading scenario: %v", err)
+	}
+	session, err := sc.NewSession()
+	if err != nil {
+		log.Fatalf("Error creating simulation from %s: %v", *scenarioPath, err)
+	}
+	sim := session.Simulation()
+	recorder := offscreen.NewRecorder(sim)
+	simulation.NewSimulationRunner(sim).RunTicks(*steps)
+
+	opts := offscreen.Options{Width: *width, Height: *height, AxisX: *axisX, AxisY: *axisY, TrailLength: *trail}
+	if *framesDir != "" {
+		paths, err := offscreen.WriteFrames(*framesDir, recorder.Frames(), *frameStep, opts)
+		if err != nil {
+			log.Fatalf("Error writing frames: %v", err)
+		}
+		log.Printf("Wrote %d frames to %s", len(paths), *framesDir)
+	}
+	if *gifPath != "" {
+		delay := *gifDelay
+		if delay <= 0 {
+			delay = sim.GetTickDuration() * time.Duration(*frameStep)
+		}
+		anim, err := offscreen.Animate(recorder.Frames(), *frameStep, opts, delay)
+		if err != nil {
+			log.Fatalf("Error rendering animation: %v", err)
+		}
+		if err := offscreen.WriteGIF(*gifPath, anim); err != nil {
+			log.Fatalf("Error writing animation: %v", err)
+		}
+		log.Printf("Wrote %d-frame animation %s", len(anim.Image), *gifPath)
+	}
+	if *sheetPath != "" {
+		sheet, err := offscreen.ContactSheet(recorder.Frames(), *sheetCount, *sheetColumns, opts)
+		if err != nil {
+			log.Fatalf("Error rendering contact sheet: %v", err)
+		}
+		if err := offscreen.WritePNG(*sheetPath, sheet); err != nil {
+			log.Fatalf("Error writing contact sheet: %v", err)
+		}
+		log.Printf("Wrote contact sheet %s", *sheetPath)
+	}
+}
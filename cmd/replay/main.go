@@ -0,0 +1,151 @@
+// Command replay re-runs a recorded measurement log (E in the simulation window writes
+// measurements.jsonl) through every combination of the chosen solvers and trackers and
+// prints their accuracy side by side, so algorithm changes are compared on identical data:
+//
+//	go run ./cmd/replay -log measurements.jsonl -solvers ls,wls,robust -trackers none,factorgraph
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+	"multilateration-sim/internal/tracking/replay"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// solvers maps -solvers names to snapshot solvers; "none" leaves the tracker alone.
+var solvers = map[string]multilateration.SolveFunc{
+	"none":   nil,
+	"ls":     multilateration.SolveLeastSquares,
+	"wls":    multilateration.SolveWeightedLeastSquares,
+	"robust": multilateration.RobustSolver(multilateration.DefaultRobustOptions()),
+	"toa":    multilateration.SolvePseudoranges,
+}
+
+// trackerFactories maps -trackers names to tracker factories for a dimension.
+var trackerFactories = map[string]func(dimension int) func(string) tracking.Tracker{
+	"none": func(int) func(string) tracking.Tracker { return nil },
+	"factorgraph": func(dimension int) func(string) tracking.Tracker {
+		return func(string) tracking.Tracker {
+			smoother, err := tracking.NewFactorGraphSmoother(dimension, tracking.DefaultSmootherConfig())
+			if err != nil {
+				return nil
+			}
+			return smoother
+		}
+	},
+}
+
+func main() {
+	logPath := flag.String("log", "", "measurement log to replay (required)")
+	solverNames := flag.String("solvers", "ls,wls,robust", "comma separated solvers: none, ls, wls, robust or toa (estimates a clock bias)")
+	trackerNames := flag.String("trackers", "none", "comma separated trackers: none or factorgraph")
+	outDir := flag.String("out", "", "directory for a tracks_<pipeline>.csv per pipeline, empty to skip")
+	flag.Parse()
+
+	if *logPath == "" {
+		flag.Usage()
+		log.Fatal("a measurement log is required")
+	}
+	f, err := os.Open(*logPath)
+	if err != nil {
+		log.Fatalf("Error opening measurement log: %v", err)
+	}
+	measurements, err := export.ReadMeasurementLog(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", *logPath, err)
+	}
+
+	pipelines, err := buildPipelines(split(*solverNames), split(*trackerNames), measurements.Dimension)
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := replay.Compare(measurements, pipelines...)
+	if err != nil {
+		log.Fatalf("Error replaying %s: %v", *logPath, err)
+	}
+	fmt.Printf("%s: %d target-steps, dimension %d\n\n", *logPath, len(measurements.Records), measurements.Dimension)
+	printResults(os.Stdout, results)
+
+	if *outDir != "" {
+		for _, r := range results {
+			path := filepath.Join(*outDir, "tracks_"+r.Pipeline+".csv")
+			if err := writeFile(path, func(w io.Writer) error { return export.WriteTracksCSV(w, r.Tracks) }); err != nil {
+				log.Fatalf("Error writing tracks: %v", err)
+			}
+		}
+		log.Printf("Wrote %d track files to %s", len(results), *outDir)
+	}
+}
+
+// buildPipelines combines every solver with every tracker, skipping none+none.
+func buildPipelines(solverNames, trackerNames []string, dimension int) ([]replay.Pipeline, error) {
+	var pipelines []replay.Pipeline
+	for _, s := range solverNames {
+		solver, ok := solvers[s]
+		if !ok {
+			return nil, fmt.Errorf("unknown solver %q", s)
+		}
+		for _, t := range trackerNames {
+			factory, ok := trackerFactories[t]
+			if !ok {
+				return nil, fmt.Errorf("unknown tracker %q", t)
+			}
+			if s == "none" && t == "none" {
+				continue
+			}
+			name := s
+			switch {
+			case s == "none":
+				name = t
+			case t != "none":
+				name = s + "+" + t
+			}
+			pipelines = append(pipelines, replay.Pipeline{Name: name, Solver: solver, Tracker: factory(dimension)})
+		}
+	}
+	if len(pipelines) == 0 {
+		return nil, fmt.Errorf("no pipeline to run, choose a solver or a tracker")
+	}
+	return pipelines, nil
+}
+
+func printResults(w io.Writer, results []replay.Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "pipeline\tlocalized\tRMSE\tmean\tmedian\tmax\tsolver fail\ttracker fail\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%.3f\t%.3f\t%.3f\t%.3f\t%d\t%d\t\n", r.Pipeline, 100*r.LocalizedFraction(),
+			r.Error.RMSE, r.Error.Mean, r.Error.Median, r.Error.Max, r.SolverFailures, r.TrackerFailures)
+	}
+	tw.Flush()
+}
+
+func split(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Close()
+}
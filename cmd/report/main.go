@@ -0,0 +1,146 @@
+// Command report compares recorded runs, e.g. of different solvers or noise levels, and
+// writes an experiment report: localization and error tables over all scenarios and per
+// scenario, an RMSE table of every configuration on every scenario, and error CDF plots
+// as PNG images next to it:
+//
+//	go run ./cmd/replay -log urban/measurements.jsonl -solvers ls,wls,robust -out urban
+//	go run ./cmd/replay -log open/measurements.jsonl -solvers ls,wls,robust -out open
+//	go run ./cmd/report -out report urban/tracks_*.csv open/tracks_*.csv
+//
+// Every argument is a track table, as E in the simulation window (tracks.csv) or
+// cmd/replay -out (tracks_<pipeline>.csv) write it. A run is named after the file: the
+// directory is the scenario and the file name without a tracks_ prefix the
+// configuration. scenario/config=path names it explicitly, e.g.
+// urban/sigma2=runs/3/tracks.csv for a window export at a noise sigma of 2.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/offscreen"
+	"multilateration-sim/internal/report"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	outDir := flag.String("out", "report", "directory for the report and its plots, created if missing")
+	formats := flag.String("format", "md,html", "comma separated report formats: md or html")
+	width := flag.Int("width", 640, "plot width in pixels")
+	height := flag.Int("height", 400, "plot height in pixels")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		log.Fatal("at least one track table is required")
+	}
+	var runs []report.Run
+	for _, arg := range flag.Args() {
+		run, err := loadRun(arg)
+		if err != nil {
+			log.Fatalf("Error loading run %s: %v", arg, err)
+		}
+		runs = append(runs, run)
+	}
+	rep, err := report.Build(runs)
+	if err != nil {
+		log.Fatalf("Error building the report: %v", err)
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("Error creating %s: %v", *outDir, err)
+	}
+
+	plots := make(map[string]string)
+	for _, scenario := range append([]string{""}, rep.Scenarios...) {
+		img, err := report.RenderCDF(rep.CDFErrors(scenario), rep.MaxError(scenario), *width, *height)
+		if err != nil {
+			log.Fatalf("Error plotting: %v", err)
+		}
+		name := "cdf.png"
+		if scenario != "" {
+			name = "cdf_" + fileName(scenario) + ".png"
+		}
+		if err := offscreen.WritePNG(filepath.Join(*outDir, name), img); err != nil {
+			log.Fatalf("Error writing plot: %v", err)
+		}
+		plots[scenario] = name
+	}
+
+	writers := map[string]func(io.Writer, *report.Report, map[string]string) error{
+		"md":   report.WriteMarkdown,
+		"html": report.WriteHTML,
+	}
+	for _, format := range split(*formats) {
+		write, ok := writers[format]
+		if !ok {
+			log.Fatalf("unknown format %q, expected md or html", format)
+		}
+		path := filepath.Join(*outDir, "report."+format)
+		if err := writeFile(path, func(w io.Writer) error { return write(w, rep, plots) }); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		log.Printf("Wrote %s", path)
+	}
+}
+
+// loadRun reads the track table of an argument, path or scenario/config=path.
+func loadRun(arg string) (report.Run, error) {
+	path := arg
+	var scenario, config string
+	if name, p, ok := strings.Cut(arg, "="); ok {
+		if scenario, config, ok = strings.Cut(name, "/"); !ok || scenario == "" || config == "" {
+			return report.Run{}, fmt.Errorf("invalid run name %q, expected scenario/config", name)
+		}
+		path = p
+	} else {
+		scenario = filepath.Base(filepath.Dir(path))
+		config = strings.TrimPrefix(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), "tracks_")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return report.Run{}, err
+	}
+	defer f.Close()
+	tracks, err := export.ReadTracksCSV(f)
+	if err != nil {
+		return report.Run{}, fmt.Errorf("read %s: %w", path, err)
+	}
+	return report.Run{Scenario: scenario, Config: config, Tracks: tracks}, nil
+}
+
+// fileName replaces the characters of a scenario name that do not belong in a file name.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func split(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Close()
+}
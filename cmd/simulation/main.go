@@ -1,11 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
+	_ "expvar" // Registers /debug/vars
+	"flag"
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
-	"multilateration-sim/internal/simulation"    // Замените на ваше имя модуля
+	"log/slog"
+	"math"
+	"multilateration-sim/internal/api"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/offscreen"
+	"multilateration-sim/internal/scenario"
+	"multilateration-sim/internal/simulation" // Замените на ваше имя модуля
+	"multilateration-sim/internal/terminal"
 	"multilateration-sim/internal/visualization" // Импортируем пакет визуализации
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -23,16 +39,11 @@ func createBounds(dim int, bound float64) []float64 {
 	return bounds
 }
 
-const (
-	screenWidth  = 1024
-	screenHeight = 768
-)
-
-func main() {
-	rand.Seed(time.Now().UnixNano())
-
+// createRandomSimulation builds the default setup: noiseless sensors and random-walk
+// targets at random positions. A deployment layout (see simulation.ParseDeployment)
+// places the sensors instead of random positions.
+func createRandomSimulation(simDimension int, deployment string) *simulation.Simulation {
 	// --- Simulation Parameters ---
-	simDimension := 2
 	worldBound := 100.0 // Max coordinate value for random placement
 	simBounds := createBounds(simDimension, worldBound)
 
@@ -47,6 +58,12 @@ func main() {
 	// --- Add Sensors ---
 	numSensors := 6       // Increased for better coverage in 3D
 	sensorRadius := 100.0 // Detection radius
+	if simDimension > 3 {
+		// Localization needs dimension+1 ranges, and in high dimensions random points are
+		// nearly equidistant, so a fixed radius would cover almost nothing
+		numSensors = 2 * (simDimension + 1)
+		sensorRadius = 0 // Unlimited
+	}
 	noiseFuncs := []simulation.NoiseFunction{
 		nil, // No noise
 		simulation.GaussianNoise(1.0),
@@ -55,12 +72,22 @@ func main() {
 		simulation.GaussianNoise(0.5),
 		simulation.UniformNoise(1.0),
 	}
+	if deployment != "" {
+		positions, err := simulation.ParseDeployment(deployment, simBounds)
+		if err != nil {
+			log.Fatalf("Invalid -deploy: %v", err)
+		}
+		if _, err := sim.AddSensors(positions, sensorRadius, noiseFuncs[0]); err != nil {
+			log.Fatalf("Error deploying sensors: %v", err)
+		}
+		numSensors = 0
+	}
 	for i := 0; i < numSensors; i++ {
 		// noiseFunc := noiseFuncs[i%len(noiseFuncs)]
 		noiseFunc := noiseFuncs[0]
 		err := sim.AddRandomSensor(sensorRadius, noiseFunc)
 		if err != nil {
-			log.Printf("Warning: could not add sensor %d: %v", i, err)
+			slog.Warn("could not add sensor", "index", i, "err", err)
 		}
 	}
 
@@ -69,42 +96,406 @@ func main() {
 	for i := 0; i < numTargets; i++ {
 		err := sim.AddRandomTarget()
 		if err != nil {
-			log.Printf("Warning: could not add target %d: %v", i, err)
+			slog.Warn("could not add target", "index", i, "err", err)
 		}
 	}
+	return sim
+}
+
+// serveDebug exposes the simulation metrics for lightweight monitoring.
+func serveDebug(addr string, sim *simulation.Simulation) {
+	sim.Metrics().Publish("simulation")
+	http.Handle("/debug/simulation", sim.Metrics().Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			slog.Error("debug server stopped", "err", err)
+		}
+	}()
+	slog.Info("debug endpoints", "vars", "http://"+addr+"/debug/vars", "simulation", "http://"+addr+"/debug/simulation")
+}
+
+// serveAPI starts the control API in the background.
+func serveAPI(addr string, server *api.Server) {
+	go func() {
+		if err := http.ListenAndServe(addr, server); err != nil {
+			slog.Error("control API stopped", "err", err)
+		}
+	}()
+	slog.Info("control API", "url", "http://"+addr+"/api/objects")
+}
+
+// exportTracks writes the CSV, GPX and NMEA track of one target, or a CSV of all
+// targets and the measurement log (for cmd/replay) if targetID is empty, into dir.
+// The NMEA log holds the estimated track.
+func exportTracks(recorder *export.TrackRecorder, measurements *export.MeasurementRecorder, targetID, dir string, ref export.GeoReference) ([]string, error) {
+	if targetID == "" {
+		path := filepath.Join(dir, "tracks.csv")
+		if err := writeFile(path, recorder.WriteCSV); err != nil {
+			return nil, err
+		}
+		logPath := filepath.Join(dir, "measurements.jsonl")
+		if err := writeFile(logPath, measurements.WriteJSONL); err != nil {
+			return nil, err
+		}
+		return []string{path, logPath}, nil
+	}
+	points := recorder.Track(targetID)
+	csvPath := filepath.Join(dir, "track_"+targetID+".csv")
+	if err := writeFile(csvPath, func(w io.Writer) error { return export.WriteTrackCSV(w, points) }); err != nil {
+		return nil, err
+	}
+	gpxPath := filepath.Join(dir, "track_"+targetID+".gpx")
+	if err := writeFile(gpxPath, func(w io.Writer) error { return export.WriteTrackGPX(w, targetID, points, ref) }); err != nil {
+		return nil, err
+	}
+	nmeaPath := filepath.Join(dir, "track_"+targetID+".nmea")
+	if err := writeFile(nmeaPath, func(w io.Writer) error { return export.WriteTrackNMEA(w, points, ref, export.TrackEstimate) }); err != nil {
+		return nil, err
+	}
+	return []string{csvPath, gpxPath, nmeaPath}, nil
+}
+
+// addSurvey appends the anchors of a survey CSV file to the scenario's sensors.
+func addSurvey(sc *scenario.Scenario, path string, radius float64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	points, err := scenario.ReadSurveyCSV(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return sc.AddSurvey(points, radius)
+}
+
+// finishRecording waits for the frames to be saved and assembles the GIF if requested,
+// with each frame shown for delay so it plays back in real time.
+func finishRecording(writer *offscreen.FrameWriter, gifPath string, delay time.Duration) {
+	paths, err := writer.Close()
+	if err != nil {
+		slog.Error("frame recording failed", "err", err)
+	}
+	slog.Info("frames recorded", "count", len(paths))
+	if gifPath == "" || len(paths) == 0 {
+		return
+	}
+	if err := offscreen.AssembleGIF(gifPath, paths, delay); err != nil {
+		slog.Error("could not assemble GIF", "err", err)
+		return
+	}
+	slog.Info("animation written", "path", gifPath)
+}
+
+// runTUI watches the simulation in the terminal until q is entered or the process is
+// interrupted. The control API, if enabled, drives the same runner.
+func runTUI(sim *simulation.Simulation, session *scenario.Session, axes, apiAddr string) {
+	opts := terminal.DefaultOptions()
+	if _, err := fmt.Sscanf(axes, "%d,%d", &opts.AxisX, &opts.AxisY); err != nil {
+		log.Fatalf("Invalid -tui-axes %q, expected two axes like 0,1: %v", axes, err)
+	}
+	if opts.AxisX < 0 || opts.AxisY < 0 || opts.AxisX >= sim.GetDimension() || opts.AxisY >= sim.GetDimension() && sim.GetDimension() > 1 {
+		log.Fatalf("-tui-axes %q out of range for a %dD scene", axes, sim.GetDimension())
+	}
+	runner := simulation.NewSimulationRunner(sim)
+	if apiAddr != "" {
+		serveAPI(apiAddr, api.NewServer(session, runner))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	viewer := terminal.NewViewer(runner, os.Stdout, opts)
+	if err := viewer.Run(ctx, os.Stdin, time.Second/10); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Terminal view failed: %v", err)
+	}
+}
+
+// setupLogging installs the default structured logger used by the simulation and by
+// the log package, writing to out at the given level.
+func setupLogging(out io.Writer, level string, asJSON bool) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	options := &slog.HandlerOptions{Level: l}
+	var handler slog.Handler = slog.NewTextHandler(out, options)
+	if asJSON {
+		handler = slog.NewJSONHandler(out, options)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// writeFile creates path and fills it with write.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Close()
+}
+
+const (
+	screenWidth  = 1024
+	screenHeight = 768
+)
+
+func main() {
+	dimension := flag.Int("dim", 2, "dimension of the random simulation (1 shows a position-time diagram)")
+	deploy := flag.String("deploy", "", "sensor layout of the random simulation: grid:N (N per axis), circle:N, sphere:N or vertices (bounding box corners); empty places sensors randomly")
+	scenarioPath := flag.String("scenario", "", "load the simulation from a scenario file instead of placing objects randomly")
+	surveyPath := flag.String("survey", "", "add the anchors of a survey CSV (id,x,y,z,accuracy) to the scenario as sensors")
+	surveyRadius := flag.Float64("survey-radius", 100, "detection radius of the sensors added with -survey")
+	writeSurvey := flag.String("write-survey", "", "write the sensor layout as survey CSV to this file and exit")
+	savePath := flag.String("save", "scenario.json", "file written when pressing Ctrl+S")
+	exportDir := flag.String("export-dir", ".", "directory for track exports (right click on a target, E for all tracks and the measurement log)")
+	originLat := flag.Float64("origin-lat", 0, "latitude of the world origin for GPX and NMEA export, degrees")
+	originLon := flag.Float64("origin-lon", 0, "longitude of the world origin for GPX and NMEA export, degrees")
+	originTime := flag.String("origin-time", "", "wall-clock time of simulation time 0 for GPX and NMEA export, RFC 3339 (e.g. 2024-05-01T12:00:00Z)")
+	projectorName := flag.String("projector", "pca", "initial 2D projection: pca, mds (classical multidimensional scaling), random (cheap for high dimensions) or perspective (3D view with a rotatable camera, 3D+ scenes)")
+	mdsMetric := flag.String("mds-metric", "euclidean", "distance preserved by the MDS projection: euclidean, manhattan or chebyshev")
+	associationName := flag.String("association", "", "hide which target each range belongs to and match detections to targets: nn, gnn or jpda (empty: labeled ranges)")
+	falseAlarms := flag.Float64("false-alarms", 0, "spurious ranges per sensor and measurement round (clutter), seen only with -association")
+	calibrationSigma := flag.Float64("calibration-sigma", 0, "standard deviation of the error of the sensor positions handed to the solver (survey error), per axis")
+	ticks := flag.Int("ticks", 0, "run this many ticks as fast as possible without the window, log the final state and exit")
+	recordFrames := flag.String("record-frames", "", "save every rendered frame of the window as frame_NNNNN.png to this directory")
+	recordEvery := flag.Int("record-every", 1, "with -record-frames, save only every n-th frame")
+	recordGIF := flag.String("record-gif", "", "with -record-frames, assemble the saved frames into this animated GIF when the window is closed")
+	tui := flag.Bool("tui", false, "show the simulation as text in the terminal (character map and target table, e.g. over SSH) instead of the window; logs are discarded")
+	tuiAxes := flag.String("tui-axes", "0,1", "simulation axes shown horizontally and vertically by -tui")
+	selectionName := flag.String("selection", "", "use only some in-range sensors per target: gdop:K (best geometry) or roundrobin:K (even energy use); empty uses all")
+	boundaryName := flag.String("boundary", "", "what moving objects do at the edge of the bounds: bounce, wrap (re-enter opposite), clamp (stop) or absorb (leave the simulation); empty keeps the scenario's, bounce by default")
+	workers := flag.Int("workers", 0, "goroutines that measure and solve in parallel in every step, 0 = one per CPU")
+	adaptive := flag.Bool("adaptive", false, "choose the step length from target speed and estimate uncertainty instead of a fixed tick")
+	relative := flag.Bool("relative", false, "also map all sensors and targets from the ranges between them alone (anchor-free MDS-MAP), scored against the truth after alignment")
+	debugAddr := flag.String("debug-addr", "", "serve /debug/vars (expvar) and /debug/simulation (JSON) on this address, e.g. localhost:6060")
+	apiAddr := flag.String("api-addr", "", "serve the JSON control API (objects, estimates, pause, speed) and the WebSocket telemetry stream on this address, e.g. localhost:8080")
+	logLevel := flag.String("log-level", "info", "log verbosity: debug, info, warn or error")
+	logJSON := flag.Bool("log-json", false, "write logs as JSON lines instead of key=value text")
+	flag.Parse()
+
+	logOutput := io.Writer(os.Stderr)
+	if *tui { // Log lines would tear the redrawn view
+		logOutput = io.Discard
+	}
+	if err := setupLogging(logOutput, *logLevel, *logJSON); err != nil {
+		log.Fatalf("Invalid -log-level: %v", err)
+	}
+
+	geoRef := export.GeoReference{Latitude: *originLat, Longitude: *originLon}
+	if *originTime != "" {
+		epoch, err := time.Parse(time.RFC3339, *originTime)
+		if err != nil {
+			log.Fatalf("Invalid -origin-time: %v", err)
+		}
+		geoRef.Epoch = epoch
+	}
+
+	var session *scenario.Session
+	if *scenarioPath != "" {
+		sc, err := scenario.Load(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Error loading scenario: %v", err)
+		}
+		if *surveyPath != "" {
+			if err := addSurvey(sc, *surveyPath, *surveyRadius); err != nil {
+				log.Fatalf("Error importing survey: %v", err)
+			}
+		}
+		if session, err = sc.NewSession(); err != nil {
+			log.Fatalf("Error creating simulation from %s: %v", *scenarioPath, err)
+		}
+	} else {
+		if *surveyPath != "" {
+			log.Fatal("-survey requires -scenario, which sets the dimension and bounds")
+		}
+		if *dimension < 1 {
+			log.Fatalf("-dim must be at least 1, got %d", *dimension)
+		}
+		session = scenario.WrapSession(createRandomSimulation(*dimension, *deploy), nil)
+	}
+	if *writeSurvey != "" {
+		points, err := session.Survey()
+		if err == nil {
+			err = writeFile(*writeSurvey, func(w io.Writer) error { return scenario.WriteSurveyCSV(w, points) })
+		}
+		if err != nil {
+			log.Fatalf("Error writing survey: %v", err)
+		}
+		fmt.Printf("Расстановка сенсоров записана в %s\n", *writeSurvey)
+		return
+	}
+	sim := session.Simulation()
+	if *adaptive && sim.AdaptiveStepping() == nil {
+		config := simulation.DefaultAdaptiveStepping(sim.GetTickDuration().Seconds())
+		if err := sim.SetAdaptiveStepping(&config); err != nil {
+			log.Fatalf("Error enabling adaptive stepping: %v", err)
+		}
+	}
+	if *relative && sim.RelativePositioning() == nil {
+		config := simulation.DefaultRelativePositioning()
+		if err := sim.SetRelativePositioning(&config); err != nil {
+			log.Fatalf("Error enabling relative positioning: %v", err)
+		}
+	}
+	if *associationName != "" {
+		associator, err := association.Parse(*associationName, association.DefaultOptions())
+		if err != nil {
+			log.Fatal(err)
+		}
+		sim.SetAssociator(associator)
+	}
+	if *falseAlarms > 0 {
+		for _, sen := range sim.GetSensors() {
+			if err := sen.SetFalseAlarmRate(*falseAlarms); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	selection, err := simulation.ParseSelectionPolicy(*selectionName)
+	if err != nil {
+		log.Fatalf("Invalid -selection: %v", err)
+	}
+	sim.SetSelectionPolicy(selection)
+	if *boundaryName != "" {
+		mode, err := simulation.ParseBoundaryMode(*boundaryName)
+		if err == nil {
+			err = sim.SetBoundaryMode(mode)
+		}
+		if err != nil {
+			log.Fatalf("Invalid -boundary: %v", err)
+		}
+	}
+	if err := sim.SetConcurrency(*workers); err != nil {
+		log.Fatalf("Invalid -workers: %v", err)
+	}
+	if *calibrationSigma > 0 {
+		if err := sim.PerturbSensorPositions(*calibrationSigma); err != nil {
+			log.Fatal(err)
+		}
+	}
+	recorder := export.NewTrackRecorder(sim)
+	measurementRecorder := export.NewMeasurementRecorder(sim)
+	if *debugAddr != "" {
+		serveDebug(*debugAddr, sim)
+	}
+
+	// Surface measurement and solver errors instead of silently dropping them
+	lastLoggedSecond := -1
+	sim.OnStep(func(report simulation.StepReport) {
+		if err := report.Err(); err != nil {
+			slog.Warn("step degraded", "sim_time", report.Time, "tick", report.State.Tick, "err", err)
+		}
+		if second := int(report.Time); second != lastLoggedSecond { // Dump the state roughly every simulated second
+			lastLoggedSecond = second
+			sim.LogCurrentState()
+		}
+	})
+
+	if *ticks > 0 {
+		simulation.NewSimulationRunner(sim).RunTicks(*ticks)
+		sim.PrintState()
+		return
+	}
+	if *tui {
+		runTUI(sim, session, *tuiAxes, *apiAddr)
+		return
+	}
 
 	// --- Initialize Projector & Renderer ---
-	projector := visualization.NewPCAProjector()
-	ebitenRenderer := visualization.NewRenderer(sim, projector)
+	pca := visualization.NewPCAProjector()
+	_ = pca.SetStabilization(visualization.PCASmoothed, 0.05) // Keep the view from rotating or flipping
+	metric, err := visualization.ParseMDSMetric(*mdsMetric)
+	if err != nil {
+		log.Fatalf("Invalid -mds-metric: %v", err)
+	}
+	mds := visualization.NewMDSProjector(metric)
+	random := visualization.NewRandomProjector(sim.Seeds().Placement)
+	perspective := visualization.NewPerspectiveProjector(math.Pi/6, math.Pi/6)
+	var ebitenRenderer *visualization.Renderer
+	switch *projectorName {
+	case "pca":
+		ebitenRenderer = visualization.NewRenderer(sim, pca)
+		ebitenRenderer.AddProjector(mds)
+	case "mds":
+		ebitenRenderer = visualization.NewRenderer(sim, mds)
+		ebitenRenderer.AddProjector(pca)
+	case "random":
+		ebitenRenderer = visualization.NewRenderer(sim, random)
+		ebitenRenderer.AddProjector(pca)
+		ebitenRenderer.AddProjector(mds)
+	case "perspective":
+		if sim.GetDimension() < 3 {
+			log.Fatalf("-projector perspective needs a 3D or higher scene, got %dD", sim.GetDimension())
+		}
+		ebitenRenderer = visualization.NewRenderer(sim, perspective)
+		ebitenRenderer.AddProjector(pca)
+		ebitenRenderer.AddProjector(mds)
+	default:
+		log.Fatalf("Unknown -projector %q, expected pca, mds, random or perspective", *projectorName)
+	}
+	if sim.GetDimension() >= 3 { // Alternative views, cycled with P
+		if slice, err := visualization.NewSliceProjector(0, 2); err == nil {
+			ebitenRenderer.AddProjector(slice)
+		}
+		ebitenRenderer.AddProjector(visualization.NewOrthographicProjector(math.Pi/6, math.Pi/4))
+		if *projectorName != "perspective" {
+			ebitenRenderer.AddProjector(perspective)
+		}
+		if *projectorName != "random" {
+			ebitenRenderer.AddProjector(random)
+		}
+	}
+	ebitenRenderer.SetAnnotations(session.Annotations())
+	ebitenRenderer.SetSaveHandler(func() (string, error) {
+		session.SetAnnotations(ebitenRenderer.Annotations())
+		return *savePath, session.Save(*savePath)
+	})
+
+	ebitenRenderer.SetNoiseDescriber(func(sensorID string) (string, bool) {
+		if spec, ok := session.SensorNoise(sensorID); ok {
+			return spec.String(), true
+		}
+		return "нет", true // Sessions record every noisy sensor
+	})
+	ebitenRenderer.SetTrackExporter(func(targetID string) ([]string, error) {
+		return exportTracks(recorder, measurementRecorder, targetID, *exportDir, geoRef)
+	})
+
+	if *apiAddr != "" {
+		serveAPI(*apiAddr, api.NewServer(session, ebitenRenderer))
+	}
+	var frameWriter *offscreen.FrameWriter
+	if *recordFrames != "" {
+		if frameWriter, err = offscreen.NewFrameWriter(*recordFrames); err != nil {
+			log.Fatalf("Error setting up -record-frames: %v", err)
+		}
+		if err := ebitenRenderer.SetFrameRecorder(frameWriter, *recordEvery); err != nil {
+			log.Fatalf("Invalid -record-every: %v", err)
+		}
+	} else if *recordGIF != "" {
+		log.Fatal("-record-gif needs -record-frames")
+	}
 
 	// --- Ebiten Game Loop Setup ---
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("N-Мерная Мультилатерационная Симуляция (PCA в 2D)")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled) // Allow window resizing
 
-	// --- Simulation Control (Separate Goroutine or Ticker) ---
-	// We want the simulation to step at its own pace (simTickDuration),
-	// while Ebiten renders at its own pace (typically 60 FPS).
-
-	go func() { // Run simulation stepping in a separate goroutine
-		ticker := time.NewTicker(simTickDuration)
-		defer ticker.Stop()
-		for range ticker.C {
-			sim.Step(simTickDuration.Seconds())       // Step the simulation
-			if int(sim.GetCurrentTime()*10)%10 == 0 { // roughly every second if tick is 0.1s
-				fmt.Printf("\n--- Sim Time: %.2fs ---\n", sim.GetCurrentTime())
-				sim.LogCurrentState()
-			}
-		}
-	}()
-
 	// --- Start Ebiten Game Loop ---
-	// The renderer's Update method will handle PCA projection based on the latest sim state.
+	// The renderer's Update method steps the simulation (space/arrow/+/- control playback)
+	// and handles PCA projection based on the latest sim state.
 	// The renderer's Draw method will draw it.
 	fmt.Println("Запуск Ebiten UI...")
 	if err := ebiten.RunGame(ebitenRenderer); err != nil {
 		log.Fatalf("Ebiten RunGame error: %v", err)
 	}
+	if frameWriter != nil {
+		finishRecording(frameWriter, *recordGIF, time.Duration(*recordEvery)*time.Second/time.Duration(ebiten.TPS()))
+	}
 
 	fmt.Println("\nСимуляция завершена.")
 }
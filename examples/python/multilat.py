@@ -0,0 +1,161 @@
+"""Thin ctypes wrapper around libmultilat, the C shared library of the Go solvers.
+
+Build the library first (from the repository root):
+
+    go build -buildmode=c-shared -o libmultilat.so ./cmd/libmultilat
+
+Then run this file for a small demo, pointing MULTILAT_LIB at the library if it
+is not in the current directory:
+
+    MULTILAT_LIB=./libmultilat.so python3 examples/python/multilat.py
+"""
+
+import ctypes
+import math
+import os
+
+LEAST_SQUARES = 0
+WEIGHTED = 1
+ROBUST = 2
+
+_DOUBLE_P = ctypes.POINTER(ctypes.c_double)
+
+
+class MultilatError(RuntimeError):
+    """Raised when a library call returns a non-zero code."""
+
+    def __init__(self, code, message):
+        super().__init__(f"{message} (code {code})")
+        self.code = code
+
+
+def _array(values):
+    values = list(values)
+    return (ctypes.c_double * len(values))(*values)
+
+
+def _flatten(positions):
+    dim = len(positions[0])
+    flat = []
+    for p in positions:
+        if len(p) != dim:
+            raise ValueError("all positions must have the same dimension")
+        flat.extend(p)
+    return _array(flat), dim
+
+
+class Library:
+    """Loaded libmultilat with typed entry points."""
+
+    def __init__(self, path=None):
+        path = path or os.environ.get("MULTILAT_LIB", "./libmultilat.so")
+        lib = ctypes.CDLL(path)
+        lib.ml_last_error.argtypes = [ctypes.c_char_p, ctypes.c_int]
+        lib.ml_last_error.restype = ctypes.c_int
+        lib.ml_solve.argtypes = [ctypes.c_int, _DOUBLE_P, _DOUBLE_P, _DOUBLE_P,
+                                 ctypes.c_int, ctypes.c_int, _DOUBLE_P, _DOUBLE_P, _DOUBLE_P]
+        lib.ml_solve.restype = ctypes.c_int
+        lib.ml_dop.argtypes = [_DOUBLE_P, ctypes.c_int, _DOUBLE_P, ctypes.c_int,
+                               _DOUBLE_P, _DOUBLE_P, _DOUBLE_P]
+        lib.ml_dop.restype = ctypes.c_int
+        lib.ml_tracker_new.argtypes = [ctypes.c_int, ctypes.c_int, ctypes.c_double]
+        lib.ml_tracker_new.restype = ctypes.c_int
+        lib.ml_tracker_update.argtypes = [ctypes.c_int, ctypes.c_double, _DOUBLE_P, _DOUBLE_P, _DOUBLE_P,
+                                          ctypes.c_int, ctypes.c_int, _DOUBLE_P]
+        lib.ml_tracker_update.restype = ctypes.c_int
+        lib.ml_tracker_free.argtypes = [ctypes.c_int]
+        lib.ml_tracker_free.restype = None
+        self._lib = lib
+
+    def _check(self, code):
+        if code < 0:
+            buf = ctypes.create_string_buffer(512)
+            self._lib.ml_last_error(buf, len(buf))
+            raise MultilatError(code, buf.value.decode())
+        return code
+
+    def solve(self, sensors, distances, variances=None, method=LEAST_SQUARES):
+        """Estimates a position from sensor positions and ranges.
+
+        Returns (position, residual, gdop).
+        """
+        flat, dim = _flatten(sensors)
+        n = len(sensors)
+        if len(distances) != n or (variances is not None and len(variances) != n):
+            raise ValueError("need one distance (and variance) per sensor")
+        position = (ctypes.c_double * dim)()
+        residual, gdop = ctypes.c_double(), ctypes.c_double()
+        self._check(self._lib.ml_solve(method, flat, _array(distances),
+                                       _array(variances) if variances is not None else None,
+                                       n, dim, position, ctypes.byref(residual), ctypes.byref(gdop)))
+        return list(position), residual.value, gdop.value
+
+    def dop(self, sensors, position):
+        """Returns (GDOP, HDOP, VDOP) of the sensor geometry at position."""
+        flat, dim = _flatten(sensors)
+        if len(position) != dim:
+            raise ValueError("position dimension does not match the sensors")
+        gdop, hdop, vdop = ctypes.c_double(), ctypes.c_double(), ctypes.c_double()
+        self._check(self._lib.ml_dop(flat, len(sensors), _array(position), dim,
+                                     ctypes.byref(gdop), ctypes.byref(hdop), ctypes.byref(vdop)))
+        return gdop.value, hdop.value, vdop.value
+
+    def tracker(self, dim, window=0, range_sigma=0.0):
+        """Creates a sliding-window factor graph tracker (0 keeps the defaults)."""
+        return Tracker(self, dim, window, range_sigma)
+
+
+class Tracker:
+    """Tracker handle, usable as a context manager to free it."""
+
+    def __init__(self, library, dim, window, range_sigma):
+        self._library = library
+        self.dim = dim
+        self._handle = library._check(library._lib.ml_tracker_new(dim, window, range_sigma))
+
+    def update(self, t, sensors, distances, variances=None):
+        """Feeds ranges taken at time t (seconds) and returns the filtered position."""
+        flat, dim = _flatten(sensors)
+        n = len(sensors)
+        position = (ctypes.c_double * dim)()
+        lib = self._library
+        lib._check(lib._lib.ml_tracker_update(self._handle, t, flat, _array(distances),
+                                              _array(variances) if variances is not None else None,
+                                              n, dim, position))
+        return list(position)
+
+    def close(self):
+        if self._handle:
+            self._library._lib.ml_tracker_free(self._handle)
+            self._handle = 0
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.close()
+
+
+def _demo():
+    lib = Library()
+    sensors = [(0, 0), (1000, 0), (0, 1000), (1000, 1000)]
+    target = (300, 400)
+    ranges = [math.dist(s, target) for s in sensors]
+
+    position, residual, gdop = lib.solve(sensors, ranges, method=ROBUST)
+    print(f"robust fix: ({position[0]:.1f}, {position[1]:.1f}), residual {residual:.3g}, GDOP {gdop:.2f}")
+
+    with lib.tracker(2) as tracker:
+        for step in range(5):
+            moved = (target[0] + 10 * step, target[1])
+            estimate = tracker.update(step * 0.1, sensors, [math.dist(s, moved) for s in sensors])
+        print(f"tracked: ({estimate[0]:.1f}, {estimate[1]:.1f})")
+
+    try:
+        lib.solve(sensors[:2], ranges[:2])
+    except MultilatError as err:
+        print("expected error:", err)
+
+
+if __name__ == "__main__":
+    _demo()
@@ -0,0 +1,310 @@
+// Package api serves a JSON control API for a running simulation, so it can be driven
+// by external scripts and dashboards: list objects, add and remove sensors and targets,
+// query estimates, pause, resume and change the speed and tick at runtime. A WebSocket
+// stream pushes the state of every step to browser viewers and analytics.
+//
+// Endpoints:
+//
+//	GET    /api/objects             sensors and targets
+//	GET    /api/objects/{id}
+//	DELETE /api/objects/{id}
+//	POST   /api/sensors             body: scenario.SensorSpec, returns {"id": ...}
+//	POST   /api/targets             body: scenario.TargetSpec
+//	GET    /api/estimates           latest estimate of every target
+//	GET    /api/estimates/{id}
+//	GET    /api/control             time, pause state, speed and tick
+//	PATCH  /api/control             body: {"paused": true, "speed": 2, "tick_seconds": 0.05}
+//	POST   /api/control/pause
+//	POST   /api/control/resume
+//	GET    /api/telemetry           WebSocket, a TelemetryFrame per step (?every=n thins it out)
+//
+// Errors are returned as {"error": "..."} with a matching status code.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"multilateration-sim/internal/scenario"
+	"multilateration-sim/internal/simulation"
+	"net/http"
+	"time"
+)
+
+// Controller pauses and paces the loop that steps the simulation, e.g. the renderer.
+// Its methods are called from the HTTP goroutines.
+type Controller interface {
+	Paused() bool
+	SetPaused(paused bool)
+	Speed() float64 // Multiplier relative to real time
+	SetSpeed(speed float64)
+}
+
+// ObjectView is a sensor or target as served by the API.
+type ObjectView struct {
+	ID       string    `json:"id"`
+	Label    string    `json:"label,omitempty"`
+	Kind     string    `json:"kind"` // sensor or target
+	Position []float64 `json:"position"`
+	Velocity []float64 `json:"velocity,omitempty"`
+	Radius   float64   `json:"radius,omitempty"` // Sensor detection radius, 0 means unlimited
+	Class    string    `json:"class,omitempty"`  // Target class, empty if unclassified
+}
+
+// EstimateView is the latest localization result of a target.
+type EstimateView struct {
+	TargetID  string    `json:"target_id"`
+	Localized bool      `json:"localized"`
+	Position  []float64 `json:"position,omitempty"`
+	Error     *float64  `json:"error,omitempty"` // Distance to the true position
+	Residual  *float64  `json:"residual,omitempty"`
+	GDOP      *float64  `json:"gdop,omitempty"` // Absent when infinite (degenerate geometry)
+}
+
+// ControlView is the playback state. In PATCH requests absent fields are left unchanged.
+type ControlView struct {
+	Time        float64  `json:"time,omitempty"` // Read only
+	Tick        int64    `json:"tick,omitempty"` // Read only, steps performed
+	Paused      *bool    `json:"paused,omitempty"`
+	Speed       *float64 `json:"speed,omitempty"`
+	TickSeconds *float64 `json:"tick_seconds,omitempty"`
+}
+
+// Server is the control API of one simulation session.
+type Server struct {
+	session *scenario.Session
+	control Controller // nil if nothing can be paused, e.g. a headless batch run
+	mux     *http.ServeMux
+}
+
+// NewServer creates the API for a session. control may be nil, pausing and speed
+// changes are then rejected.
+func NewServer(session *scenario.Session, control Controller) *Server {
+	s := &Server{session: session, control: control, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /api/objects", s.listObjects)
+	s.mux.HandleFunc("GET /api/objects/{id}", s.getObject)
+	s.mux.HandleFunc("DELETE /api/objects/{id}", s.removeObject)
+	s.mux.HandleFunc("POST /api/sensors", s.addSensor)
+	s.mux.HandleFunc("POST /api/targets", s.addTarget)
+	s.mux.HandleFunc("GET /api/estimates", s.listEstimates)
+	s.mux.HandleFunc("GET /api/estimates/{id}", s.getEstimate)
+	s.mux.HandleFunc("GET /api/control", s.getControl)
+	s.mux.HandleFunc("PATCH /api/control", s.patchControl)
+	s.mux.HandleFunc("POST /api/control/pause", func(w http.ResponseWriter, r *http.Request) { s.setPaused(w, true) })
+	s.mux.HandleFunc("POST /api/control/resume", func(w http.ResponseWriter, r *http.Request) { s.setPaused(w, false) })
+	s.mux.HandleFunc("GET /api/telemetry", s.streamTelemetry)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Handle registers an additional handler on the API's mux, e.g. a telemetry stream.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+func (s *Server) sim() *simulation.Simulation {
+	return s.session.Simulation()
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, _ *http.Request) {
+	state := s.sim().Snapshot()
+	objects := make([]ObjectView, 0, len(state.Sensors)+len(state.Targets))
+	for _, sen := range state.Sensors {
+		objects = append(objects, sensorView(sen))
+	}
+	for _, tar := range state.Targets {
+		objects = append(objects, targetView(tar))
+	}
+	writeJSON(w, http.StatusOK, objects)
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	state := s.sim().Snapshot()
+	if tar, ok := state.Target(id); ok {
+		writeJSON(w, http.StatusOK, targetView(tar))
+		return
+	}
+	for _, sen := range state.Sensors {
+		if sen.ID == id {
+			writeJSON(w, http.StatusOK, sensorView(sen))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("object %s does not exist", id))
+}
+
+func (s *Server) removeObject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.session.RemoveObject(id); err != nil {
+		writeError(w, errorStatus(err, http.StatusConflict), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) addSensor(w http.ResponseWriter, r *http.Request) {
+	var spec scenario.SensorSpec
+	if !readJSON(w, r, &spec) {
+		return
+	}
+	id, err := s.session.AddSensor(spec)
+	if err != nil {
+		writeError(w, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (s *Server) addTarget(w http.ResponseWriter, r *http.Request) {
+	var spec scenario.TargetSpec
+	if !readJSON(w, r, &spec) {
+		return
+	}
+	id, err := s.session.AddTarget(spec)
+	if err != nil {
+		writeError(w, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (s *Server) listEstimates(w http.ResponseWriter, _ *http.Request) {
+	state := s.sim().Snapshot()
+	estimates := make([]EstimateView, 0, len(state.Targets))
+	for _, tar := range state.Targets {
+		estimates = append(estimates, estimateView(tar))
+	}
+	writeJSON(w, http.StatusOK, estimates)
+}
+
+func (s *Server) getEstimate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	tar, ok := s.sim().Snapshot().Target(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("target %s does not exist", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, estimateView(tar))
+}
+
+func (s *Server) controlView() ControlView {
+	state := s.sim().Snapshot()
+	tick := s.sim().GetTickDuration().Seconds()
+	view := ControlView{Time: state.Time, Tick: state.Tick, TickSeconds: &tick}
+	if s.control != nil {
+		paused, speed := s.control.Paused(), s.control.Speed()
+		view.Paused, view.Speed = &paused, &speed
+	}
+	return view
+}
+
+func (s *Server) getControl(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.controlView())
+}
+
+func (s *Server) patchControl(w http.ResponseWriter, r *http.Request) {
+	var patch ControlView
+	if !readJSON(w, r, &patch) {
+		return
+	}
+	if (patch.Paused != nil || patch.Speed != nil) && s.control == nil {
+		writeError(w, http.StatusNotImplemented, errors.New("this simulation cannot be paused or sped up"))
+		return
+	}
+	if patch.Speed != nil && (*patch.Speed <= 0 || math.IsInf(*patch.Speed, 0)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("speed must be positive, got %g", *patch.Speed))
+		return
+	}
+	if patch.TickSeconds != nil {
+		tick := time.Duration(*patch.TickSeconds * float64(time.Second))
+		if err := s.sim().SetTickDuration(tick); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if patch.Speed != nil {
+		s.control.SetSpeed(*patch.Speed)
+	}
+	if patch.Paused != nil {
+		s.control.SetPaused(*patch.Paused)
+	}
+	writeJSON(w, http.StatusOK, s.controlView())
+}
+
+func (s *Server) setPaused(w http.ResponseWriter, paused bool) {
+	if s.control == nil {
+		writeError(w, http.StatusNotImplemented, errors.New("this simulation cannot be paused"))
+		return
+	}
+	s.control.SetPaused(paused)
+	writeJSON(w, http.StatusOK, s.controlView())
+}
+
+func sensorView(sen simulation.SensorState) ObjectView {
+	return ObjectView{ID: sen.ID, Label: sen.Label, Kind: "sensor", Position: sen.Position, Velocity: sen.Velocity, Radius: sen.DetectionRadius}
+}
+
+func targetView(tar simulation.TargetState) ObjectView {
+	return ObjectView{ID: tar.ID, Label: tar.Label, Kind: "target", Position: tar.Position, Velocity: tar.Velocity, Class: tar.Class}
+}
+
+func estimateView(tar simulation.TargetState) EstimateView {
+	view := EstimateView{TargetID: tar.ID, Localized: tar.Estimate.Position != nil}
+	if !view.Localized {
+		return view
+	}
+	view.Position = tar.Estimate.Position
+	if tar.Error >= 0 {
+		view.Error = finite(tar.Error)
+	}
+	view.Residual = finite(tar.Estimate.ResidualError)
+	view.GDOP = finite(tar.Estimate.GDOP)
+	return view
+}
+
+// finite returns a pointer to x, nil if JSON cannot represent it.
+func finite(x float64) *float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil
+	}
+	return &x
+}
+
+// readJSON decodes the request body into v, answering 400 and returning false on failure.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) // The status is sent, nothing left to report to
+}
+
+// errorStatus returns the HTTP status of a simulation error: 404 for a missing object,
+// 409 for a taken ID and fallback otherwise.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, simulation.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, simulation.ErrDuplicateID):
+		return http.StatusConflict
+	}
+	return fallback
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
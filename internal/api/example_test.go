@@ -0,0 +1,132 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"multilateration-sim/internal/api"
+	"multilateration-sim/internal/scenario"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// manualClock is a Controller for a loop that is stepped by hand.
+type manualClock struct {
+	paused bool
+	speed  float64
+}
+
+func (c *manualClock) Paused() bool           { return c.paused }
+func (c *manualClock) SetPaused(paused bool)  { c.paused = paused }
+func (c *manualClock) Speed() float64         { return c.speed }
+func (c *manualClock) SetSpeed(speed float64) { c.speed = speed }
+
+// Drive a simulation through the API as an external script would.
+func ExampleNewServer() {
+	sc := &scenario.Scenario{Dimension: 2, Bounds: []float64{-100, 100, -100, 100}, TickSeconds: 0.1}
+	session, _ := sc.NewSession()
+	server := httptest.NewServer(api.NewServer(session, &manualClock{speed: 1}))
+	defer server.Close()
+
+	call := func(method, path, body string) string {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err.Error()
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return strings.TrimSpace(fmt.Sprintf("%d %s", resp.StatusCode, data))
+	}
+
+	for _, pos := range []string{"[-100, -100]", "[100, -100]", "[0, 100]"} {
+		call("POST", "/api/sensors", `{"position": `+pos+`, "radius": 0}`)
+	}
+	created := call("POST", "/api/targets", `{"position": [10, 20]}`)
+	var target struct{ ID string }
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(created, "201 ")), &target)
+	fmt.Println(created[:3], "target created")
+
+	session.Simulation().Step(0.1)
+	var estimate api.EstimateView
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(call("GET", "/api/estimates/"+target.ID, ""), "200 ")), &estimate)
+	fmt.Printf("localized %v at [%.1f %.1f], error %.3f\n", estimate.Localized, estimate.Position[0], estimate.Position[1], *estimate.Error)
+
+	fmt.Println(call("PATCH", "/api/control", `{"paused": true, "tick_seconds": 0.05}`))
+	fmt.Println(call("POST", "/api/sensors", `{"position": [1, 2, 3]}`))
+	fmt.Println(call("DELETE", "/api/objects/"+target.ID, ""))
+	fmt.Println(strings.Replace(call("GET", "/api/objects/"+target.ID, ""), target.ID, "<id>", 1))
+	fmt.Println(strings.Replace(call("DELETE", "/api/objects/"+target.ID, ""), target.ID, "<id>", 1))
+	// Output:
+	// 201 target created
+	// localized true at [10.0 20.0], error 0.000
+	// 200 {"time":0.1,"tick":1,"paused":true,"speed":1,"tick_seconds":0.05}
+	// 400 {"error":"dimension mismatch: object dimension 3 does not match simulation dimension 2"}
+	// 204
+	// 404 {"error":"object <id> does not exist"}
+	// 404 {"error":"not found: object with ID <id> does not exist"}
+}
+
+// Follow the telemetry stream with a bare-bones WebSocket client; a browser would use
+// new WebSocket("ws://host/api/telemetry") instead.
+func ExampleTelemetryFrame() {
+	sc := &scenario.Scenario{
+		Dimension: 2, Bounds: []float64{-100, 100, -100, 100}, TickSeconds: 0.1,
+		Sensors: []scenario.SensorSpec{{Position: []float64{-100, -100}}, {Position: []float64{100, -100}}, {Position: []float64{0, 100}}},
+		Targets: []scenario.TargetSpec{{Position: []float64{0, 0}, Velocity: []float64{10, 0}, Motion: &scenario.MotionSpec{Type: "constant_velocity"}}},
+	}
+	session, _ := sc.NewSession()
+	server := httptest.NewServer(api.NewServer(session, nil))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /api/telemetry?every=2 HTTP/1.1\r\nHost: sim\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(resp.Status, resp.Header.Get("Sec-WebSocket-Accept"))
+
+	// Server frames are unmasked; these messages are short, so the length fits the second byte
+	readMessage := func() api.TelemetryFrame {
+		var head [2]byte
+		_, _ = io.ReadFull(reader, head[:])
+		payload := make([]byte, head[1]&0x7F)
+		if head[1]&0x7F == 126 {
+			var ext [2]byte
+			_, _ = io.ReadFull(reader, ext[:])
+			payload = make([]byte, int(ext[0])<<8|int(ext[1]))
+		}
+		_, _ = io.ReadFull(reader, payload)
+		var frame api.TelemetryFrame
+		_ = json.Unmarshal(payload, &frame)
+		return frame
+	}
+	show := func(f api.TelemetryFrame) {
+		t := f.Targets[0]
+		fmt.Printf("tick %d: %d sensors, target at x=%.0f, localized %v\n", f.Tick, len(f.Sensors), t.Position[0], t.Estimate.Localized)
+	}
+
+	show(readMessage()) // The current state, sent on connect
+	for i := 0; i < 4; i++ {
+		session.Simulation().Step(0.1)
+	}
+	show(readMessage())
+	show(readMessage())
+	// Output:
+	// 101 Switching Protocols s3pPLMBiTxaQ9kYGzzhZRbK+xOo=
+	// tick 0: 3 sensors, target at x=0, localized false
+	// tick 2: 3 sensors, target at x=2, localized true
+	// tick 4: 3 sensors, target at x=4, localized true
+}
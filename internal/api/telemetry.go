@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"multilateration-sim/internal/simulation"
+	"net/http"
+	"strconv"
+)
+
+// telemetryBuffer is the number of frames queued for a slow client before the
+// oldest ones are skipped.
+const telemetryBuffer = 8
+
+// TelemetryFrame is the message pushed to telemetry clients after every step.
+type TelemetryFrame struct {
+	Time    float64           `json:"time"`
+	Tick    int64             `json:"tick"`
+	Sensors []ObjectView      `json:"sensors"`
+	Targets []TargetTelemetry `json:"targets"`
+}
+
+// TargetTelemetry is the true state of a target together with its estimate.
+type TargetTelemetry struct {
+	ObjectView
+	Estimate EstimateView `json:"estimate"`
+}
+
+// NewTelemetryFrame converts a simulation state into a telemetry message.
+func NewTelemetryFrame(state simulation.SimState) TelemetryFrame {
+	frame := TelemetryFrame{
+		Time:    state.Time,
+		Tick:    state.Tick,
+		Sensors: make([]ObjectView, 0, len(state.Sensors)),
+		Targets: make([]TargetTelemetry, 0, len(state.Targets)),
+	}
+	for _, sen := range state.Sensors {
+		frame.Sensors = append(frame.Sensors, sensorView(sen))
+	}
+	for _, tar := range state.Targets {
+		frame.Targets = append(frame.Targets, TargetTelemetry{ObjectView: targetView(tar), Estimate: estimateView(tar)})
+	}
+	return frame
+}
+
+// streamTelemetry upgrades to a WebSocket and pushes a TelemetryFrame as a JSON text
+// message for the current state and then after every step. ?every=n sends only every
+// n-th step, for clients that do not need the full rate. A client that cannot keep up
+// skips frames rather than slowing down the simulation.
+func (s *Server) streamTelemetry(w http.ResponseWriter, r *http.Request) {
+	every := int64(1)
+	if v := r.URL.Query().Get("every"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("every must be a positive integer, got %q", v))
+			return
+		}
+		every = n
+	}
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return // Already answered
+	}
+	defer ws.conn.Close()
+
+	sim := s.sim()
+	sub := sim.Subscribe(telemetryBuffer)
+	defer sub.Close()
+	done := make(chan struct{})
+	go ws.serveControl(done)
+
+	send := func(state simulation.SimState) bool {
+		data, err := json.Marshal(NewTelemetryFrame(state))
+		if err != nil {
+			slog.Error("encode telemetry frame", "err", err)
+			return false
+		}
+		return ws.writeFrame(wsOpText, data) == nil
+	}
+	if !send(sim.Snapshot()) { // The scene right away, also while paused
+		return
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case state, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if state.Tick%every != 0 {
+				continue
+			}
+			if !send(state) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,454 @@
+// Package association matches anonymous range detections to target tracks. A sensor
+// that does not know which target it measured reports bare ranges; before solving,
+// every range has to be attributed to one of the tracked targets or rejected as
+// clutter. Detections are associated sensor by sensor: a sensor sees every target at
+// most once, so each of its detections goes to at most one track and vice versa.
+package association
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"sort"
+)
+
+// Track is what the association knows about a target: where it is expected to be.
+type Track struct {
+	ID       string
+	Position common.Vector // Predicted position
+	Sigma    float64       // Uncertainty of the prediction (units), 0 if unknown
+}
+
+// Assignment attributes a detection to a track with probability Weight: 1 for the
+// hard decisions of nearest neighbor and GNN, the association probability for JPDA.
+type Assignment struct {
+	TrackID   string
+	Detection int // Index into the detections passed to Associate
+	Weight    float64
+}
+
+// Associator matches detections (measurements without target identity; SensorID must
+// be set) to tracks. Detections outside every gate are left unassigned, as clutter.
+type Associator interface {
+	Associate(tracks []Track, detections []multilateration.Measurement) []Assignment
+	// String returns the name accepted by Parse.
+	String() string
+}
+
+// Options configures the associators.
+type Options struct {
+	Gate       float64 // Largest range residual of a valid pairing, in standard deviations
+	RangeSigma float64 // Range noise of detections that carry no Variance (units)
+
+	DetectionProbability float64 // JPDA: probability that a sensor detects a target in range
+	ClutterDensity       float64 // JPDA: expected false detections per sensor and unit of range
+}
+
+// DefaultOptions returns options for unit range noise and little clutter.
+func DefaultOptions() Options {
+	return Options{Gate: 4, RangeSigma: 1, DetectionProbability: 0.9, ClutterDensity: 1e-3}
+}
+
+// Parse returns the associator with the given name: "nn" (nearest neighbor), "gnn"
+// (global nearest neighbor) or "jpda" (joint probabilistic data association).
+func Parse(name string, opts Options) (Associator, error) {
+	switch name {
+	case "nn":
+		return NewNearestNeighbor(opts), nil
+	case "gnn":
+		return NewGNN(opts), nil
+	case "jpda":
+		return NewJPDA(opts), nil
+	}
+	return nil, fmt.Errorf("unknown association %q, expected nn, gnn or jpda", name)
+}
+
+// TrackMeasurements turns the assignments of one track into solver input. Hard
+// assignments are used as they are. Soft assignments of a sensor are merged into their
+// probability weighted mean range, with the spread of the merged ranges added to the
+// variance, if the sensor probably detected the track (weights summing to 0.5 or more).
+func TrackMeasurements(trackID string, assignments []Assignment, detections []multilateration.Measurement) []multilateration.Measurement {
+	type merged struct {
+		weight, sum, sumSq float64
+		first              int // Detection whose sensor data is reused
+	}
+	bySensor := make(map[string]*merged)
+	var order []string // Sensors in the order of their first assignment
+	for _, a := range assignments {
+		if a.TrackID != trackID || a.Weight <= 0 {
+			continue
+		}
+		d := detections[a.Detection]
+		m, ok := bySensor[d.SensorID]
+		if !ok {
+			m = &merged{first: a.Detection}
+			bySensor[d.SensorID] = m
+			order = append(order, d.SensorID)
+		}
+		m.weight += a.Weight
+		m.sum += a.Weight * d.Distance
+		m.sumSq += a.Weight * d.Distance * d.Distance
+	}
+
+	measurements := make([]multilateration.Measurement, 0, len(order))
+	for _, sensorID := range order {
+		m := bySensor[sensorID]
+		if m.weight < 0.5 {
+			continue // Probably missed by this sensor
+		}
+		out := detections[m.first]
+		mean := m.sum / m.weight
+		out.Distance = mean
+		if spread := m.sumSq/m.weight - mean*mean; spread > 0 {
+			out.Variance += spread
+		}
+		measurements = append(measurements, out)
+	}
+	return measurements
+}
+
+// sensorGroup is the detections of one sensor, as indices into all detections.
+type sensorGroup []int
+
+// groupBySensor splits the detections by sensor, in order of first appearance.
+func groupBySensor(detections []multilateration.Measurement) []sensorGroup {
+	index := make(map[string]int)
+	var groups []sensorGroup
+	for i, d := range detections {
+		g, ok := index[d.SensorID]
+		if !ok {
+			g = len(groups)
+			index[d.SensorID] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// costs holds the normalized squared residuals of one sensor's detections against the
+// tracks; +Inf outside the gate.
+type costs struct {
+	values [][]float64 // [detection in group][track]
+	sigma  [][]float64 // Standard deviation of each residual
+}
+
+// gatedCosts computes the costs of one sensor group.
+func gatedCosts(tracks []Track, detections []multilateration.Measurement, group sensorGroup, opts Options) costs {
+	c := costs{values: make([][]float64, len(group)), sigma: make([][]float64, len(group))}
+	for gi, di := range group {
+		d := detections[di]
+		c.values[gi] = make([]float64, len(tracks))
+		c.sigma[gi] = make([]float64, len(tracks))
+		variance := d.Variance
+		if variance <= 0 {
+			variance = opts.RangeSigma * opts.RangeSigma
+		}
+		for ti, t := range tracks {
+			c.values[gi][ti] = math.Inf(1)
+			predicted, err := t.Position.Distance(d.SensorPosition)
+			if err != nil {
+				continue
+			}
+			sigma := math.Sqrt(variance + t.Sigma*t.Sigma)
+			if sigma == 0 {
+				sigma = 1e-9
+			}
+			r := (d.Distance - predicted) / sigma
+			c.sigma[gi][ti] = sigma
+			if math.Abs(r) <= opts.Gate {
+				c.values[gi][ti] = r * r
+			}
+		}
+	}
+	return c
+}
+
+// NearestNeighbor greedily pairs the closest detection and track of every sensor, then
+// the closest of the remaining ones, and so on. Cheap, but an early wrong pairing
+// cannot be undone.
+type NearestNeighbor struct {
+	opts Options
+}
+
+// NewNearestNeighbor creates a nearest neighbor associator.
+func NewNearestNeighbor(opts Options) *NearestNeighbor {
+	return &NearestNeighbor{opts: opts}
+}
+
+// String implements Associator.
+func (*NearestNeighbor) String() string { return "nn" }
+
+// Associate implements Associator.
+func (a *NearestNeighbor) Associate(tracks []Track, detections []multilateration.Measurement) []Assignment {
+	var assignments []Assignment
+	for _, group := range groupBySensor(detections) {
+		c := gatedCosts(tracks, detections, group, a.opts)
+		type pair struct {
+			detection, track int
+			cost             float64
+		}
+		var pairs []pair
+		for gi := range group {
+			for ti := range tracks {
+				if !math.IsInf(c.values[gi][ti], 1) {
+					pairs = append(pairs, pair{gi, ti, c.values[gi][ti]})
+				}
+			}
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].cost < pairs[j].cost })
+		usedDetection, usedTrack := make(map[int]bool), make(map[int]bool)
+		for _, p := range pairs {
+			if usedDetection[p.detection] || usedTrack[p.track] {
+				continue
+			}
+			usedDetection[p.detection], usedTrack[p.track] = true, true
+			assignments = append(assignments, Assignment{TrackID: tracks[p.track].ID, Detection: group[p.detection], Weight: 1})
+		}
+	}
+	return assignments
+}
+
+// GNN (global nearest neighbor) picks, for every sensor, the pairing of detections and
+// tracks with the smallest total cost, solved exactly with the Hungarian algorithm.
+type GNN struct {
+	opts Options
+}
+
+// NewGNN creates a global nearest neighbor associator.
+func NewGNN(opts Options) *GNN {
+	return &GNN{opts: opts}
+}
+
+// String implements Associator.
+func (*GNN) String() string { return "gnn" }
+
+// Associate implements Associator.
+func (a *GNN) Associate(tracks []Track, detections []multilateration.Measurement) []Assignment {
+	var assignments []Assignment
+	for _, group := range groupBySensor(detections) {
+		c := gatedCosts(tracks, detections, group, a.opts)
+		for gi, ti := range optimalAssignment(c.values, a.opts.Gate*a.opts.Gate) {
+			if ti >= 0 {
+				assignments = append(assignments, Assignment{TrackID: tracks[ti].ID, Detection: group[gi], Weight: 1})
+			}
+		}
+	}
+	return assignments
+}
+
+// optimalAssignment returns, for every row (detection), the column (track) it is paired
+// with or -1, minimizing the total cost. Leaving a detection unpaired costs missCost;
+// +Inf entries are never paired.
+func optimalAssignment(cost [][]float64, missCost float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return nil
+	}
+	cols := len(cost[0])
+	// Square matrix: detections and dummy "clutter" rows against tracks and dummy
+	// "unassigned" columns, so that every detection may stay unpaired.
+	n := rows + cols
+	const forbidden = 1e18
+	square := make([][]float64, n)
+	for i := range square {
+		square[i] = make([]float64, n)
+		for j := range square[i] {
+			switch {
+			case i < rows && j < cols:
+				square[i][j] = cost[i][j]
+				if math.IsInf(square[i][j], 1) {
+					square[i][j] = forbidden
+				}
+			case i < rows: // Detection left unpaired
+				square[i][j] = forbidden
+				if j-cols == i {
+					square[i][j] = missCost
+				}
+			case j < cols: // Track without a detection
+				square[i][j] = 0
+			}
+		}
+	}
+	match := hungarian(square)
+	result := make([]int, rows)
+	for i := range result {
+		result[i] = -1
+		if j := match[i]; j < cols && square[i][j] < forbidden {
+			result[i] = j
+		}
+	}
+	return result
+}
+
+// hungarian solves the square assignment problem in O(n³) and returns the column of
+// every row (potentials method).
+func hungarian(a [][]float64) []int {
+	n := len(a)
+	u, v := make([]float64, n+1), make([]float64, n+1)
+	p, way := make([]int, n+1), make([]int, n+1) // p[j]: row matched to column j (1-based)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		for {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.Inf(1), 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := a[i0-1][j-1] - u[i0] - v[j]; cur < minv[j] {
+					minv[j], way[j] = cur, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+	rowToCol := make([]int, n)
+	for j := 1; j <= n; j++ {
+		if p[j] > 0 {
+			rowToCol[p[j]-1] = j - 1
+		}
+	}
+	return rowToCol
+}
+
+// maxJPDAEvents bounds the joint events enumerated per sensor; beyond it the sensor
+// falls back to GNN, since the count grows exponentially with crowded gates.
+const maxJPDAEvents = 100000
+
+// JPDA (joint probabilistic data association) weighs every feasible joint pairing of a
+// sensor's detections with the tracks by its likelihood, including missed detections
+// and clutter, and assigns each detection to each track with its marginal probability.
+// Ambiguous ranges are thus shared between tracks instead of being decided early.
+type JPDA struct {
+	opts Options
+}
+
+// NewJPDA creates a JPDA associator.
+func NewJPDA(opts Options) *JPDA {
+	return &JPDA{opts: opts}
+}
+
+// String implements Associator.
+func (*JPDA) String() string { return "jpda" }
+
+// Associate implements Associator.
+func (a *JPDA) Associate(tracks []Track, detections []multilateration.Measurement) []Assignment {
+	pd := math.Min(math.Max(a.opts.DetectionProbability, 1e-6), 1-1e-6)
+	clutter := math.Max(a.opts.ClutterDensity, 1e-12)
+	var assignments []Assignment
+	for _, group := range groupBySensor(detections) {
+		c := gatedCosts(tracks, detections, group, a.opts)
+		// Likelihood ratio of pairing versus clutter, relative to missing the track
+		ratio := make([][]float64, len(group))
+		for gi := range group {
+			ratio[gi] = make([]float64, len(tracks))
+			for ti := range tracks {
+				if math.IsInf(c.values[gi][ti], 1) {
+					continue
+				}
+				density := math.Exp(-c.values[gi][ti]/2) / (math.Sqrt(2*math.Pi) * c.sigma[gi][ti])
+				ratio[gi][ti] = pd * density / ((1 - pd) * clutter)
+			}
+		}
+
+		marginals, ok := jpdaMarginals(ratio, len(tracks))
+		if !ok {
+			for gi, ti := range optimalAssignment(c.values, a.opts.Gate*a.opts.Gate) {
+				if ti >= 0 {
+					assignments = append(assignments, Assignment{TrackID: tracks[ti].ID, Detection: group[gi], Weight: 1})
+				}
+			}
+			continue
+		}
+		for gi := range group {
+			for ti := range tracks {
+				if w := marginals[gi][ti]; w > 1e-6 {
+					assignments = append(assignments, Assignment{TrackID: tracks[ti].ID, Detection: group[gi], Weight: w})
+				}
+			}
+		}
+	}
+	return assignments
+}
+
+// jpdaMarginals enumerates the joint events (each detection to one gated track or to
+// clutter, each track used at most once) and returns the probability of every pairing.
+// ok is false if there are too many events.
+func jpdaMarginals(ratio [][]float64, numTracks int) (marginals [][]float64, ok bool) {
+	marginals = make([][]float64, len(ratio))
+	for i := range marginals {
+		marginals[i] = make([]float64, numTracks)
+	}
+	used := make([]bool, numTracks)
+	choice := make([]int, len(ratio))
+	total, events := 0.0, 0
+	var visit func(d int, weight float64) bool
+	visit = func(d int, weight float64) bool {
+		if d == len(ratio) {
+			events++
+			if events > maxJPDAEvents {
+				return false
+			}
+			total += weight
+			for i, t := range choice {
+				if t >= 0 {
+					marginals[i][t] += weight
+				}
+			}
+			return true
+		}
+		choice[d] = -1 // Clutter
+		if !visit(d+1, weight) {
+			return false
+		}
+		for t := 0; t < numTracks; t++ {
+			if used[t] || ratio[d][t] == 0 {
+				continue
+			}
+			used[t], choice[d] = true, t
+			ok := visit(d+1, weight*ratio[d][t])
+			used[t] = false
+			if !ok {
+				return false
+			}
+		}
+		choice[d] = -1
+		return true
+	}
+	if !visit(0, 1) {
+		return nil, false
+	}
+	for i := range marginals {
+		for t := range marginals[i] {
+			marginals[i][t] /= total
+		}
+	}
+	return marginals, true
+}
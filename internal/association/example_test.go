@@ -0,0 +1,58 @@
+package association_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+func ExampleGNN() {
+	// Seen from the sensor at the origin, a is expected at range 10 and b at 11
+	tracks := []association.Track{
+		{ID: "a", Position: common.Vector{10, 0}},
+		{ID: "b", Position: common.Vector{0, 11}},
+	}
+	sensor := common.Vector{0, 0}
+	detections := []multilateration.Measurement{
+		{SensorID: "s", SensorPosition: sensor, Distance: 10.9},
+		{SensorID: "s", SensorPosition: sensor, Distance: 12.5},
+		{SensorID: "s", SensorPosition: sensor, Distance: 30}, // Clutter
+	}
+	opts := association.DefaultOptions()
+	for _, associator := range []association.Associator{association.NewNearestNeighbor(opts), association.NewGNN(opts)} {
+		fmt.Print(associator, ":")
+		for _, a := range associator.Associate(tracks, detections) {
+			fmt.Printf(" %.1f->%s", detections[a.Detection].Distance, a.TrackID)
+		}
+		fmt.Println()
+	}
+	// Output:
+	// nn: 10.9->b 12.5->a
+	// gnn: 10.9->a 12.5->b
+}
+
+func ExampleJPDA() {
+	tracks := []association.Track{
+		{ID: "a", Position: common.Vector{10, 0}},
+		{ID: "b", Position: common.Vector{0, 11}},
+	}
+	// The ranges lie between the predictions: either pairing is plausible
+	sensor := common.Vector{0, 0}
+	detections := []multilateration.Measurement{
+		{SensorID: "s", SensorPosition: sensor, Distance: 10.4},
+		{SensorID: "s", SensorPosition: sensor, Distance: 10.7},
+	}
+	assignments := association.NewJPDA(association.DefaultOptions()).Associate(tracks, detections)
+	for _, a := range assignments {
+		fmt.Printf("%.1f->%s: %.2f\n", detections[a.Detection].Distance, a.TrackID, a.Weight)
+	}
+	merged := association.TrackMeasurements("a", assignments, detections)
+	fmt.Printf("a: range %.2f, variance %.2f\n", merged[0].Distance, merged[0].Variance)
+	// Output:
+	// 10.4->a: 0.57
+	// 10.4->b: 0.43
+	// 10.7->a: 0.43
+	// 10.7->b: 0.57
+	// a: range 10.53, variance 0.02
+}
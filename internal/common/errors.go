@@ -0,0 +1,10 @@
+package common
+
+import "errors"
+
+// Errors shared by the packages built on common. They are returned wrapped with
+// details; check for them with errors.Is.
+var (
+	ErrDimensionMismatch = errors.New("dimension mismatch") // Vectors or objects of different dimensions
+	ErrOutOfRange        = errors.New("out of range")       // A parameter outside its valid range
+)
@@ -0,0 +1,103 @@
+package common_test
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// Print values with a precision and units that fit the scenario scale.
+func ExampleFormat() {
+	v := common.Vector{1234.5678, -0.5}
+	fmt.Println(v)
+
+	km := common.Format{Precision: 1, Unit: "km"}
+	fmt.Println(v.FormatWith(km))
+	fmt.Println(km.Float(42))
+
+	sci := common.Format{Precision: 2, Scientific: true}
+	fmt.Println(v.FormatWith(sci))
+	// Output:
+	// [1234.568, -0.500]
+	// [1234.6, -0.5] km
+	// 42.0 km
+	// [1.23e+03, -5.00e-01]
+}
+
+// Express a sensor mounted on a moving, rotated platform in world coordinates.
+func ExampleFrameGraph() {
+	frames := common.NewFrameGraph(2)
+
+	platformPos := common.Vector{100, 50}
+	_ = frames.AddDynamicFrame("platform", common.WorldFrame, func() common.Transform {
+		heading, _ := common.NewPlaneRotation(2, 0, 1, math.Pi/2) // Facing +Y
+		heading.Translation = platformPos
+		return heading
+	})
+	_ = frames.AddStaticFrame("sensor", "platform", common.NewTranslation(common.Vector{2, 0})) // 2 units ahead
+
+	world, _ := frames.Convert(common.Vector{0, 0}, "sensor", common.WorldFrame)
+	fmt.Println(world)
+
+	platformPos[0] = 200 // The platform moved, the dynamic frame follows
+	world, _ = frames.Convert(common.Vector{0, 0}, "sensor", common.WorldFrame)
+	fmt.Println(world)
+
+	back, _ := frames.Convert(world, common.WorldFrame, "sensor")
+	fmt.Println(back)
+	// Output:
+	// [100.000, 52.000]
+	// [200.000, 52.000]
+	// [0.000, 0.000]
+}
+
+// The same pair of points in three metrics; great-circle points are (lat, lon) in degrees.
+func ExampleMetric() {
+	a, b := common.Vector{0, 0}, common.Vector{3, 4}
+	euclidean, _ := a.DistanceIn(b, common.Euclidean{})
+	manhattan, _ := a.DistanceIn(b, common.Manhattan{})
+	fmt.Printf("euclidean %.0f, manhattan %.0f\n", euclidean, manhattan)
+
+	moscow, petersburg := common.Vector{55.7558, 37.6173}, common.Vector{59.9343, 30.3351}
+	km, _ := moscow.DistanceIn(petersburg, common.GreatCircle{Radius: common.EarthRadius / 1000})
+	fmt.Printf("Moscow - St Petersburg: %.0f km\n", km)
+	// Output:
+	// euclidean 5, manhattan 7
+	// Moscow - St Petersburg: 633 km
+}
+
+// Move a point towards a waypoint and step it along a velocity without allocating.
+func ExampleVector() {
+	pos, waypoint := common.Vector{0, 0}, common.Vector{30, 40}
+	toWaypoint, _ := waypoint.Subtract(pos)
+	fmt.Println(toWaypoint.Norm(), toWaypoint.Normalize())
+	halfway, _ := pos.Lerp(waypoint, 0.5)
+	fmt.Println(halfway)
+
+	velocity := common.Vector{3, 4}
+	speed := velocity.Norm()
+	velocity.ScaleInPlace(2 / speed) // 2 units per second
+	for range 10 {
+		_ = pos.AddScaledInPlace(velocity, 0.5)
+	}
+	along, _ := pos.DotProduct(toWaypoint.Normalize())
+	fmt.Println(pos, along, pos.Equal(common.Vector{6, 8}, 1e-9))
+	// Output:
+	// 50 [0.600, 0.800]
+	// [15.000, 20.000]
+	// [6.000, 8.000] 10 true
+}
+
+// Split stacked poses and stack them back, without gonum.
+func ExampleVector_Split() {
+	poses, _ := common.Vector{1, 2, 3, 4}.Split(2)
+	fmt.Println(poses)
+	data, _ := common.StackRows(poses)
+	fmt.Println(data)
+	_, err := common.Vector{1, 2, 3}.Split(2)
+	fmt.Println(err)
+	// Output:
+	// [[1.000, 2.000] [3.000, 4.000]]
+	// [1 2 3 4]
+	// dimension mismatch: length 3 is not a multiple of dimension 2
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Format describes how coordinates and distances are rendered as text.
+// The zero value prints with 0 decimal places, use DefaultFormat() as a starting point.
+type Format struct {
+	Precision  int    // Number of digits after the decimal point
+	Scientific bool   // Use scientific notation (%e) instead of fixed point (%f)
+	Unit       string // Optional units suffix, e.g. "m" or "km". Empty means no suffix
+}
+
+var defaultFormat atomic.Pointer[Format]
+
+func init() {
+	defaultFormat.Store(&Format{Precision: 3})
+}
+
+// DefaultFormat returns the process-wide format used by Vector.String and friends.
+func DefaultFormat() Format {
+	return *defaultFormat.Load()
+}
+
+// SetDefaultFormat changes the process-wide output format.
+// Safe to call concurrently with printing.
+func SetDefaultFormat(f Format) {
+	if f.Precision < 0 {
+		f.Precision = 0
+	}
+	defaultFormat.Store(&f)
+}
+
+// Number formats a single value without the units suffix.
+func (f Format) Number(val float64) string {
+	verb := 'f'
+	if f.Scientific {
+		verb = 'e'
+	}
+	return fmt.Sprintf("%.*"+string(verb), f.Precision, val)
+}
+
+// Float formats a single scalar value, e.g. a distance, including the units suffix.
+func (f Format) Float(val float64) string {
+	return f.withUnit(f.Number(val))
+}
+
+// Vector formats a vector as "[x, y, ...]" followed by the units suffix.
+func (f Format) Vector(v Vector) string {
+	strs := make([]string, len(v))
+	for i, val := range v {
+		strs[i] = f.Number(val)
+	}
+	return f.withUnit(fmt.Sprintf("[%s]", strings.Join(strs, ", ")))
+}
+
+func (f Format) withUnit(s string) string {
+	if f.Unit == "" {
+		return s
+	}
+	return s + " " + f.Unit
+}
@@ -0,0 +1,264 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// WorldFrame is the name of the root frame of every FrameGraph.
+const WorldFrame = "world"
+
+// Transform is a rigid transform from a child frame into its parent frame:
+// x_parent = Rotation * x_child + Translation.
+type Transform struct {
+	Rotation    []float64 // Row-major n x n orthonormal matrix, nil means identity
+	Translation Vector    // nil means no translation
+}
+
+// NewTranslation creates a pure translation transform.
+func NewTranslation(offset Vector) Transform {
+	return Transform{Translation: offset.Clone()}
+}
+
+// NewPlaneRotation creates a rotation by angle (radians) in the plane spanned
+// by axisU and axisV of an n-dimensional space, e.g. (0, 1) for yaw in 2D/3D.
+func NewPlaneRotation(dimension, axisU, axisV int, angle float64) (Transform, error) {
+	if axisU < 0 || axisU >= dimension || axisV < 0 || axisV >= dimension || axisU == axisV {
+		return Transform{}, fmt.Errorf("%w: invalid rotation plane axes (%d, %d) for dimension %d", ErrOutOfRange, axisU, axisV, dimension)
+	}
+	rot := identityMatrix(dimension)
+	c, s := math.Cos(angle), math.Sin(angle)
+	rot[axisU*dimension+axisU] = c
+	rot[axisU*dimension+axisV] = -s
+	rot[axisV*dimension+axisU] = s
+	rot[axisV*dimension+axisV] = c
+	return Transform{Rotation: rot}, nil
+}
+
+// Apply maps a point from the child frame into the parent frame.
+func (t Transform) Apply(v Vector) (Vector, error) {
+	n := v.Dimension()
+	if t.Rotation != nil && len(t.Rotation) != n*n {
+		return nil, fmt.Errorf("rotation is not %dx%d", n, n)
+	}
+	if t.Translation != nil && t.Translation.Dimension() != n {
+		return nil, fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, t.Translation.Dimension(), n)
+	}
+	result := v.Clone()
+	if t.Rotation != nil {
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				sum += t.Rotation[i*n+j] * v[j]
+			}
+			result[i] = sum
+		}
+	}
+	if t.Translation != nil {
+		for i := range result {
+			result[i] += t.Translation[i]
+		}
+	}
+	return result, nil
+}
+
+// Inverse returns the transform from the parent frame back into the child frame.
+// The rotation is assumed orthonormal, so its inverse is the transpose.
+func (t Transform) Inverse() Transform {
+	var inv Transform
+	n := t.Translation.Dimension()
+	if t.Rotation != nil {
+		n = int(math.Round(math.Sqrt(float64(len(t.Rotation)))))
+		inv.Rotation = make([]float64, n*n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				inv.Rotation[j*n+i] = t.Rotation[i*n+j]
+			}
+		}
+	}
+	if t.Translation != nil {
+		// x_child = R^T (x_parent - T) = R^T x_parent - R^T T
+		rotated, err := Transform{Rotation: inv.Rotation}.Apply(t.Translation)
+		if err == nil {
+			inv.Translation = rotated.MultiplyByScalar(-1)
+		}
+	}
+	return inv
+}
+
+// Then returns the transform that applies t first and outer afterwards,
+// i.e. child -> t's parent -> outer's parent.
+func (t Transform) Then(outer Transform) (Transform, error) {
+	var n int
+	switch {
+	case t.Rotation != nil:
+		n = int(math.Round(math.Sqrt(float64(len(t.Rotation)))))
+	case outer.Rotation != nil:
+		n = int(math.Round(math.Sqrt(float64(len(outer.Rotation)))))
+	case t.Translation != nil:
+		n = t.Translation.Dimension()
+	default:
+		n = outer.Translation.Dimension()
+	}
+	if n == 0 {
+		return Transform{}, nil // Both are identity transforms
+	}
+
+	combined := Transform{}
+	switch {
+	case t.Rotation == nil:
+		combined.Rotation = cloneFloats(outer.Rotation)
+	case outer.Rotation == nil:
+		combined.Rotation = cloneFloats(t.Rotation)
+	default:
+		combined.Rotation = make([]float64, n*n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				sum := 0.0
+				for k := 0; k < n; k++ {
+					sum += outer.Rotation[i*n+k] * t.Rotation[k*n+j]
+				}
+				combined.Rotation[i*n+j] = sum
+			}
+		}
+	}
+
+	// Translation: outer applied to t's translation
+	translation := NewVector(n)
+	if t.Translation != nil {
+		translation = t.Translation.Clone()
+	}
+	moved, err := outer.Apply(translation)
+	if err != nil {
+		return Transform{}, err
+	}
+	combined.Translation = moved
+	return combined, nil
+}
+
+// TransformFunc returns the current transform of a dynamic frame, e.g. the pose of a moving platform.
+type TransformFunc func() Transform
+
+// FrameGraph is a tree of coordinate frames rooted at WorldFrame, e.g.
+// world -> platform -> sensor. Each frame stores the transform into its parent,
+// either fixed (static) or queried on demand (dynamic). Safe for concurrent use.
+type FrameGraph struct {
+	mu        sync.RWMutex
+	dimension int
+	frames    map[string]*frameNode
+}
+
+type frameNode struct {
+	parent  string
+	static  Transform
+	dynamic TransformFunc // Takes precedence over static when set
+}
+
+// NewFrameGraph creates a graph containing only the world frame.
+func NewFrameGraph(dimension int) *FrameGraph {
+	return &FrameGraph{
+		dimension: dimension,
+		frames:    map[string]*frameNode{WorldFrame: {}},
+	}
+}
+
+// AddStaticFrame adds a frame with a fixed transform into its parent.
+func (g *FrameGraph) AddStaticFrame(name, parent string, toParent Transform) error {
+	return g.addFrame(name, parent, &frameNode{parent: parent, static: toParent})
+}
+
+// AddDynamicFrame adds a frame whose transform into its parent is re-evaluated on every query.
+func (g *FrameGraph) AddDynamicFrame(name, parent string, toParent TransformFunc) error {
+	if toParent == nil {
+		return fmt.Errorf("frame %s: transform function is nil", name)
+	}
+	return g.addFrame(name, parent, &frameNode{parent: parent, dynamic: toParent})
+}
+
+func (g *FrameGraph) addFrame(name, parent string, node *frameNode) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, exists := g.frames[name]; exists {
+		return fmt.Errorf("frame %s already exists", name)
+	}
+	if _, exists := g.frames[parent]; !exists {
+		return fmt.Errorf("parent frame %s does not exist", parent)
+	}
+	g.frames[name] = node
+	return nil
+}
+
+// SetTransform replaces the transform of a frame into its parent, making it static.
+func (g *FrameGraph) SetTransform(name string, toParent Transform) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	node, exists := g.frames[name]
+	if !exists || name == WorldFrame {
+		return fmt.Errorf("frame %s does not exist or cannot be modified", name)
+	}
+	node.static = toParent
+	node.dynamic = nil
+	return nil
+}
+
+// ToWorld returns the transform from the named frame into the world frame.
+func (g *FrameGraph) ToWorld(name string) (Transform, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	result := Transform{}
+	for current := name; current != WorldFrame; {
+		node, exists := g.frames[current]
+		if !exists {
+			return Transform{}, fmt.Errorf("frame %s does not exist", current)
+		}
+		toParent := node.static
+		if node.dynamic != nil {
+			toParent = node.dynamic()
+		}
+		var err error
+		if result, err = result.Then(toParent); err != nil {
+			return Transform{}, fmt.Errorf("frame %s: %w", current, err)
+		}
+		current = node.parent
+	}
+	if result.Translation == nil {
+		result.Translation = NewVector(g.dimension)
+	}
+	return result, nil
+}
+
+// Convert expresses a point given in frame "from" in frame "to".
+func (g *FrameGraph) Convert(point Vector, from, to string) (Vector, error) {
+	fromWorld, err := g.ToWorld(from)
+	if err != nil {
+		return nil, err
+	}
+	toWorld, err := g.ToWorld(to)
+	if err != nil {
+		return nil, err
+	}
+	worldPoint, err := fromWorld.Apply(point)
+	if err != nil {
+		return nil, err
+	}
+	return toWorld.Inverse().Apply(worldPoint)
+}
+
+func identityMatrix(n int) []float64 {
+	m := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		m[i*n+i] = 1
+	}
+	return m
+}
+
+func cloneFloats(src []float64) []float64 {
+	if src == nil {
+		return nil
+	}
+	dst := make([]float64, len(src))
+	copy(dst, src)
+	return dst
+}
@@ -0,0 +1,55 @@
+//go:build !lite
+
+package common
+
+import "gonum.org/v1/gonum/mat"
+
+// Conversions between vectors and gonum types. They copy the coordinates, so the results
+// share no memory with their inputs. Excluded from the lite build, which has no gonum;
+// code compiled into it uses the plain-slice counterparts Vector.Split and StackRows.
+
+// ToVecDense returns the vector as a gonum column vector.
+func (v Vector) ToVecDense() *mat.VecDense {
+	if len(v) == 0 {
+		return &mat.VecDense{} // gonum does not allow zero-length vectors
+	}
+	return mat.NewVecDense(len(v), v.Clone())
+}
+
+// FromVecDense returns the elements of a gonum vector.
+func FromVecDense(v mat.Vector) Vector {
+	result := NewVector(v.Len())
+	for i := range result {
+		result[i] = v.AtVec(i)
+	}
+	return result
+}
+
+// SplitVecDense splits a gonum vector stacking several vectors of the given dimension,
+// e.g. the state of several poses, into those vectors.
+func SplitVecDense(v mat.Vector, dimension int) ([]Vector, error) {
+	return FromVecDense(v).Split(dimension)
+}
+
+// PackRows returns a matrix with the vectors as its rows, e.g. samples for PCA. The
+// vectors must be non-empty and of the same dimension.
+func PackRows(rows []Vector) (*mat.Dense, error) {
+	data, err := StackRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return mat.NewDense(len(rows), len(rows[0]), data), nil
+}
+
+// UnpackRows returns the rows of a matrix as vectors.
+func UnpackRows(m mat.Matrix) []Vector {
+	r, c := m.Dims()
+	rows := make([]Vector, r)
+	for i := range rows {
+		rows[i] = NewVector(c)
+		for j := range c {
+			rows[i][j] = m.At(i, j)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,34 @@
+//go:build !lite
+
+package common_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Hand positions to gonum and read the results back as vectors.
+func ExamplePackRows() {
+	points := []common.Vector{{1, 2}, {3, 4}, {5, 6}}
+	m, _ := common.PackRows(points)
+	var shifted mat.Dense
+	shifted.Apply(func(_, j int, v float64) float64 { return v + float64(10*j) }, m)
+	fmt.Println(common.UnpackRows(&shifted))
+
+	var sum mat.VecDense
+	sum.AddVec(points[0].ToVecDense(), points[1].ToVecDense())
+	fmt.Println(common.FromVecDense(&sum))
+
+	stacked := mat.NewVecDense(4, []float64{1, 2, 3, 4})
+	poses, _ := common.SplitVecDense(stacked, 2)
+	fmt.Println(poses)
+	_, err := common.PackRows([]common.Vector{{1, 2}, {3}})
+	fmt.Println(err)
+	// Output:
+	// [[1.000, 12.000] [3.000, 14.000] [5.000, 16.000]]
+	// [4.000, 6.000]
+	// [[1.000, 2.000] [3.000, 4.000]]
+	// dimension mismatch: row 1 has dimension 1, expected 2
+}
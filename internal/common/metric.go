@@ -0,0 +1,175 @@
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean radius of the Earth in meters (IUGG).
+const EarthRadius = 6371008.8
+
+// Metric measures distances in the simulated space. Sensors measure ranges in it and
+// iterative solvers fit positions to them, so the simulator can model positioning on a
+// sphere or in grid-constrained environments, not only in flat Euclidean space.
+type Metric interface {
+	// Distance returns the distance between a and b, which must have the same dimension.
+	Distance(a, b Vector) (float64, error)
+	// Gradient returns the derivative of Distance(a, b) with respect to a. Where it is
+	// undefined (e.g. a == b) it returns the zero vector.
+	Gradient(a, b Vector) (Vector, error)
+	// String returns the name accepted by ParseMetric.
+	String() string
+}
+
+// ParseMetric parses "euclidean", "manhattan" or "great-circle" (Earth radius).
+func ParseMetric(name string) (Metric, error) {
+	switch name {
+	case "euclidean":
+		return Euclidean{}, nil
+	case "manhattan":
+		return Manhattan{}, nil
+	case "great-circle":
+		return GreatCircle{Radius: EarthRadius}, nil
+	}
+	return nil, fmt.Errorf("unknown metric %q", name)
+}
+
+// MetricOrDefault returns m, or Euclidean if m is nil.
+func MetricOrDefault(m Metric) Metric {
+	if m == nil {
+		return Euclidean{}
+	}
+	return m
+}
+
+// Euclidean is the straight-line distance, the default of the simulator.
+type Euclidean struct{}
+
+// Distance implements Metric.
+func (Euclidean) Distance(a, b Vector) (float64, error) {
+	if err := sameDimension(a, b); err != nil {
+		return 0, err
+	}
+	sumOfSquares := 0.0
+	for i := range a {
+		diff := a[i] - b[i]
+		sumOfSquares += diff * diff
+	}
+	return math.Sqrt(sumOfSquares), nil
+}
+
+// Gradient implements Metric: the unit vector from b towards a.
+func (e Euclidean) Gradient(a, b Vector) (Vector, error) {
+	d, err := e.Distance(a, b)
+	if err != nil {
+		return nil, err
+	}
+	g := NewVector(len(a))
+	if d == 0 {
+		return g, nil
+	}
+	for i := range a {
+		g[i] = (a[i] - b[i]) / d
+	}
+	return g, nil
+}
+
+// String implements Metric.
+func (Euclidean) String() string { return "euclidean" }
+
+// Manhattan is the sum of absolute coordinate differences, the path length along a
+// street grid aligned with the axes.
+type Manhattan struct{}
+
+// Distance implements Metric.
+func (Manhattan) Distance(a, b Vector) (float64, error) {
+	if err := sameDimension(a, b); err != nil {
+		return 0, err
+	}
+	sum := 0.0
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+	return sum, nil
+}
+
+// Gradient implements Metric: the sign of every coordinate difference.
+func (Manhattan) Gradient(a, b Vector) (Vector, error) {
+	if err := sameDimension(a, b); err != nil {
+		return nil, err
+	}
+	g := NewVector(len(a))
+	for i := range a {
+		switch {
+		case a[i] > b[i]:
+			g[i] = 1
+		case a[i] < b[i]:
+			g[i] = -1
+		}
+	}
+	return g, nil
+}
+
+// String implements Metric.
+func (Manhattan) String() string { return "manhattan" }
+
+// GreatCircle is the distance along the surface of a sphere between points given as
+// (latitude, longitude) in degrees, in the unit of Radius. Further coordinates, e.g. an
+// altitude, are ignored.
+type GreatCircle struct {
+	Radius float64
+}
+
+// Distance implements Metric using the haversine formula.
+func (g GreatCircle) Distance(a, b Vector) (float64, error) {
+	h, err := g.haversine(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return 2 * g.Radius * math.Asin(math.Sqrt(h)), nil
+}
+
+// Gradient implements Metric, per degree of latitude and longitude of a.
+func (g GreatCircle) Gradient(a, b Vector) (Vector, error) {
+	h, err := g.haversine(a, b)
+	if err != nil {
+		return nil, err
+	}
+	grad := NewVector(len(a))
+	if h <= 0 || h >= 1 { // Coincident or antipodal: no unique direction
+		return grad, nil
+	}
+	lat1, lat2 := a[0]*math.Pi/180, b[0]*math.Pi/180
+	dLon := (a[1] - b[1]) * math.Pi / 180
+	sinHalfLon := math.Sin(dLon / 2)
+	dhdLat := math.Sin(lat1-lat2)/2 - math.Sin(lat1)*math.Cos(lat2)*sinHalfLon*sinHalfLon
+	dhdLon := math.Cos(lat1) * math.Cos(lat2) * math.Sin(dLon) / 2
+	scale := g.Radius / math.Sqrt(h*(1-h)) * math.Pi / 180 // d(distance)/dh, per degree
+	grad[0], grad[1] = scale*dhdLat, scale*dhdLon
+	return grad, nil
+}
+
+// String implements Metric.
+func (GreatCircle) String() string { return "great-circle" }
+
+// haversine returns sin²(Δlat/2) + cos(lat1)cos(lat2)sin²(Δlon/2), clamped to [0, 1].
+func (g GreatCircle) haversine(a, b Vector) (float64, error) {
+	if err := sameDimension(a, b); err != nil {
+		return 0, err
+	}
+	if len(a) < 2 {
+		return 0, fmt.Errorf("%w: great-circle distance needs latitude and longitude, got dimension %d", ErrDimensionMismatch, len(a))
+	}
+	lat1, lat2 := a[0]*math.Pi/180, b[0]*math.Pi/180
+	sinHalfLat := math.Sin((lat1 - lat2) / 2)
+	sinHalfLon := math.Sin((a[1] - b[1]) * math.Pi / 180 / 2)
+	h := sinHalfLat*sinHalfLat + math.Cos(lat1)*math.Cos(lat2)*sinHalfLon*sinHalfLon
+	return math.Min(math.Max(h, 0), 1), nil
+}
+
+func sameDimension(a, b Vector) error {
+	if a.Dimension() != b.Dimension() {
+		return fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, a.Dimension(), b.Dimension())
+	}
+	return nil
+}
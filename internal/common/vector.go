@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"strings"
 )
 
 // Vector represents a point or vector in n-dimensional space.
@@ -18,14 +17,23 @@ func NewVector(dimension int) Vector {
 // NewRandomVector creates a vector with random coordinates within given bounds.
 // bounds should have dimension * 2 elements: [minX, maxX, minY, maxY, ...]
 func NewRandomVector(dimension int, bounds []float64) (Vector, error) {
+	return NewRandomVectorFrom(dimension, bounds, nil)
+}
+
+// NewRandomVectorFrom is like NewRandomVector but draws from rng (the global source if nil).
+func NewRandomVectorFrom(dimension int, bounds []float64, rng *rand.Rand) (Vector, error) {
 	if len(bounds) != dimension*2 {
 		return nil, fmt.Errorf("bounds length must be dimension * 2, got %d, expected %d", len(bounds), dimension*2)
 	}
+	draw := rand.Float64
+	if rng != nil {
+		draw = rng.Float64
+	}
 	v := NewVector(dimension)
 	for i := 0; i < dimension; i++ {
 		min := bounds[i*2]
 		max := bounds[i*2+1]
-		v[i] = min + rand.Float64()*(max-min) // Generate random float between min and max
+		v[i] = min + draw()*(max-min) // Generate random float between min and max
 	}
 	return v, nil
 }
@@ -37,21 +45,19 @@ func (v Vector) Dimension() int {
 
 // Distance calculates the Euclidean distance between two vectors.
 func (v Vector) Distance(other Vector) (float64, error) {
-	if v.Dimension() != other.Dimension() {
-		return 0, fmt.Errorf("vectors must have the same dimension: %d != %d", v.Dimension(), other.Dimension())
-	}
-	sumOfSquares := 0.0
-	for i := range v {
-		diff := v[i] - other[i]
-		sumOfSquares += diff * diff
-	}
-	return math.Sqrt(sumOfSquares), nil
+	return Euclidean{}.Distance(v, other)
+}
+
+// DistanceIn calculates the distance between two vectors in the given metric,
+// Euclidean if m is nil.
+func (v Vector) DistanceIn(other Vector, m Metric) (float64, error) {
+	return MetricOrDefault(m).Distance(v, other)
 }
 
 // Add adds another vector to this vector.
 func (v Vector) Add(other Vector) (Vector, error) {
 	if v.Dimension() != other.Dimension() {
-		return nil, fmt.Errorf("vectors must have the same dimension: %d != %d", v.Dimension(), other.Dimension())
+		return nil, fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, v.Dimension(), other.Dimension())
 	}
 	result := NewVector(v.Dimension())
 	for i := range v {
@@ -63,7 +69,7 @@ func (v Vector) Add(other Vector) (Vector, error) {
 // Subtract subtracts another vector from this vector.
 func (v Vector) Subtract(other Vector) (Vector, error) {
 	if v.Dimension() != other.Dimension() {
-		return nil, fmt.Errorf("vectors must have the same dimension: %d != %d", v.Dimension(), other.Dimension())
+		return nil, fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, v.Dimension(), other.Dimension())
 	}
 	result := NewVector(v.Dimension())
 	for i := range v {
@@ -81,14 +87,14 @@ func (v Vector) MultiplyByScalar(scalar float64) Vector {
 	return result
 }
 
-// String returns a string representation of the vector.
+// String returns a string representation of the vector using the default format.
 func (v Vector) String() string {
-	// Format with limited precision for cleaner output
-	strs := make([]string, len(v))
-	for i, val := range v {
-		strs[i] = fmt.Sprintf("%.3f", val)
-	}
-	return fmt.Sprintf("[%s]", strings.Join(strs, ", ")) // Changed from %v for better formatting
+	return DefaultFormat().Vector(v)
+}
+
+// FormatWith returns a string representation of the vector using the given format.
+func (v Vector) FormatWith(f Format) string {
+	return f.Vector(v)
 }
 
 // Clone creates a deep copy of the vector.
@@ -98,6 +104,37 @@ func (v Vector) Clone() Vector {
 	return clone
 }
 
+// Split splits a vector stacking several vectors of the given dimension, e.g. the state
+// of several poses, into copies of those vectors.
+func (v Vector) Split(dimension int) ([]Vector, error) {
+	if dimension <= 0 || len(v)%dimension != 0 {
+		return nil, fmt.Errorf("%w: length %d is not a multiple of dimension %d", ErrDimensionMismatch, len(v), dimension)
+	}
+	parts := make([]Vector, len(v)/dimension)
+	for k := range parts {
+		parts[k] = v[k*dimension : (k+1)*dimension].Clone()
+	}
+	return parts, nil
+}
+
+// StackRows returns the coordinates of the vectors one after another, the row-major data
+// of a matrix with the vectors as its rows. The vectors must be non-empty and of the
+// same dimension.
+func StackRows(rows []Vector) ([]float64, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("%w: cannot stack %d rows of dimension 0", ErrOutOfRange, len(rows))
+	}
+	cols := len(rows[0])
+	data := make([]float64, 0, len(rows)*cols)
+	for i, row := range rows {
+		if len(row) != cols {
+			return nil, fmt.Errorf("%w: row %d has dimension %d, expected %d", ErrDimensionMismatch, i, len(row), cols)
+		}
+		data = append(data, row...)
+	}
+	return data, nil
+}
+
 // NormSq calculates the squared Euclidean norm (magnitude squared) of the vector (dot product with itself).
 func (v Vector) NormSq() float64 {
 	sumOfSquares := 0.0
@@ -107,7 +144,89 @@ func (v Vector) NormSq() float64 {
 	return sumOfSquares
 }
 
-// --- Potentially add more vector operations as needed ---
-// Magnitude (Norm), Normalize, DotProduct etc.
+// Norm calculates the Euclidean norm (magnitude) of the vector.
+func (v Vector) Norm() float64 {
+	return math.Sqrt(v.NormSq())
+}
+
+// DotProduct calculates the dot product with another vector.
+func (v Vector) DotProduct(other Vector) (float64, error) {
+	if v.Dimension() != other.Dimension() {
+		return 0, fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, v.Dimension(), other.Dimension())
+	}
+	dot := 0.0
+	for i := range v {
+		dot += v[i] * other[i]
+	}
+	return dot, nil
+}
+
+// Normalize returns the unit vector in the direction of v. The zero vector has no
+// direction and is returned as a zero vector.
+func (v Vector) Normalize() Vector {
+	norm := v.Norm()
+	if norm == 0 {
+		return NewVector(v.Dimension())
+	}
+	return v.MultiplyByScalar(1 / norm)
+}
 
-// --- Need strings import for String() method ---
+// Lerp interpolates linearly between v (t = 0) and other (t = 1); t outside [0, 1]
+// extrapolates.
+func (v Vector) Lerp(other Vector, t float64) (Vector, error) {
+	if v.Dimension() != other.Dimension() {
+		return nil, fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, v.Dimension(), other.Dimension())
+	}
+	result := NewVector(v.Dimension())
+	for i := range v {
+		result[i] = v[i] + (other[i]-v[i])*t
+	}
+	return result, nil
+}
+
+// Equal reports whether v and other have the same dimension and differ by at most
+// tolerance in every coordinate.
+func (v Vector) Equal(other Vector, tolerance float64) bool {
+	if v.Dimension() != other.Dimension() {
+		return false
+	}
+	for i := range v {
+		if math.Abs(v[i]-other[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// In-place variants of the operations above modify the receiver instead of allocating
+// a result, for hot loops.
+
+// ScaleInPlace multiplies the vector by a scalar value in place.
+func (v Vector) ScaleInPlace(scalar float64) {
+	for i := range v {
+		v[i] *= scalar
+	}
+}
+
+// AddInPlace adds another vector to this vector in place.
+func (v Vector) AddInPlace(other Vector) error {
+	if v.Dimension() != other.Dimension() {
+		return fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, v.Dimension(), other.Dimension())
+	}
+	for i := range v {
+		v[i] += other[i]
+	}
+	return nil
+}
+
+// AddScaledInPlace adds other multiplied by scalar to this vector in place, e.g. to
+// advance a position by velocity * time.
+func (v Vector) AddScaledInPlace(other Vector, scalar float64) error {
+	if v.Dimension() != other.Dimension() {
+		return fmt.Errorf("%w: vectors have dimension %d and %d", ErrDimensionMismatch, v.Dimension(), other.Dimension())
+	}
+	for i := range v {
+		v[i] += other[i] * scalar
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+// Package experiment runs Monte Carlo comparisons of simulation configurations.
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/simulation"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scenario describes the scene shared by all configurations of an experiment.
+type Scenario struct {
+	Dimension int
+	Bounds    []float64
+	// Populate adds sensors and targets. It runs after the seeds are set, so random
+	// placement is controlled by Seeds.Placement.
+	Populate func(sim *simulation.Simulation) error
+}
+
+// Config is one configuration under comparison, e.g. a solver or a noise level.
+type Config struct {
+	Name  string
+	Apply func(sim *simulation.Simulation) error // Runs after Populate, nil means unchanged
+}
+
+// Runner executes Runs replications of every configuration.
+type Runner struct {
+	Scenario   Scenario
+	Configs    []Config // The first configuration is the baseline of the comparisons
+	Runs       int
+	Steps      int     // Steps per run
+	DeltaTime  float64 // Seconds per step
+	MasterSeed int64
+
+	// Workers is the number of replications run concurrently, 0 or 1 runs them one at a
+	// time. Simulations share no state, so the results do not depend on it, but
+	// Populate and Apply must then be safe for concurrent use.
+	Workers int
+
+	// CommonRandomNumbers gives every configuration the same seeds in a given
+	// replication, so placement, motion and noise are identical and only the
+	// configuration differs. Differences are then compared pairwise, which needs
+	// far fewer runs than comparing independent samples.
+	CommonRandomNumbers bool
+
+	ConfidenceLevel    float64 // Level of the bootstrap confidence intervals, 0 means 0.95
+	BootstrapResamples int     // 0 means 2000
+	Alpha              float64 // Significance level of the comparisons, 0 means 0.05
+}
+
+// RunResult is the outcome of one replication of one configuration.
+type RunResult struct {
+	Config      string
+	Replication int
+	Seeds       simulation.Seeds
+	MeanError   float64 // Mean localization error over all localized target-steps, -1 if none
+	Localized   float64 // Fraction of target-steps with an estimate
+}
+
+// Comparison compares one configuration against the baseline.
+type Comparison struct {
+	Baseline, Config string
+	MeanDifference   float64                    // Config minus baseline mean error
+	DifferenceCI     metrics.ConfidenceInterval // Bootstrap interval of the (paired) mean difference
+	Paired           bool                       // Paired t-test (common random numbers) or Welch t-test
+	Test             metrics.TestResult
+	Wilcoxon         *metrics.TestResult // Signed-rank test of the paired differences, nil if unpaired
+	Significant      bool                // The t-test rejects equal means at the runner's Alpha
+}
+
+// Report collects the results of an experiment.
+type Report struct {
+	Configs     []string               // Configuration names in the order they were run
+	Results     map[string][]RunResult // Config name -> replications in order
+	Summaries   map[string]metrics.Summary
+	Intervals   map[string]metrics.ConfidenceInterval // Bootstrap interval of each config's mean error
+	Comparisons []Comparison
+}
+
+// Run executes the experiment.
+func (r *Runner) Run() (*Report, error) {
+	if len(r.Configs) == 0 {
+		return nil, fmt.Errorf("experiment needs at least one configuration")
+	}
+	if r.Runs < 1 || r.Steps < 1 || r.DeltaTime <= 0 {
+		return nil, fmt.Errorf("runs, steps and delta time must be positive")
+	}
+	level, resamples, alpha := r.ConfidenceLevel, r.BootstrapResamples, r.Alpha
+	if level == 0 {
+		level = 0.95
+	}
+	if resamples == 0 {
+		resamples = 2000
+	}
+	if alpha == 0 {
+		alpha = 0.05
+	}
+	bootstrapRand := rand.New(rand.NewSource(r.MasterSeed)) // Reproducible intervals
+
+	report := &Report{
+		Results:   make(map[string][]RunResult),
+		Summaries: make(map[string]metrics.Summary),
+		Intervals: make(map[string]metrics.ConfidenceInterval),
+	}
+	for c, cfg := range r.Configs {
+		if _, dup := report.Results[cfg.Name]; dup {
+			return nil, fmt.Errorf("duplicate configuration name %q", cfg.Name)
+		}
+		results, err := r.runReplications(c, cfg)
+		if err != nil {
+			return nil, err
+		}
+		report.Configs = append(report.Configs, cfg.Name)
+		report.Results[cfg.Name] = results
+		report.Summaries[cfg.Name] = metrics.Summarize(meanErrors(results))
+		if ci, err := metrics.BootstrapMeanCI(meanErrors(results), level, resamples, bootstrapRand); err == nil {
+			report.Intervals[cfg.Name] = ci
+		}
+	}
+
+	baseline := r.Configs[0].Name
+	for _, cfg := range r.Configs[1:] {
+		base, other := meanErrors(report.Results[baseline]), meanErrors(report.Results[cfg.Name])
+		cmp := Comparison{
+			Baseline:       baseline,
+			Config:         cfg.Name,
+			MeanDifference: report.Summaries[cfg.Name].Mean - report.Summaries[baseline].Mean,
+			Paired:         r.CommonRandomNumbers && len(base) == len(other),
+		}
+		var err error
+		if cmp.Paired {
+			cmp.Test, err = metrics.PairedTTest(other, base)
+			if w, wErr := metrics.WilcoxonSignedRank(other, base); wErr == nil {
+				cmp.Wilcoxon = &w
+			}
+			diffs := make([]float64, len(base))
+			for i := range base {
+				diffs[i] = other[i] - base[i]
+			}
+			cmp.DifferenceCI, _ = metrics.BootstrapMeanCI(diffs, level, resamples, bootstrapRand)
+		} else {
+			cmp.Test, err = metrics.WelchTTest(other, base)
+			cmp.DifferenceCI = unpairedDifferenceCI(other, base, level, resamples, bootstrapRand)
+		}
+		if err == nil {
+			cmp.Significant = cmp.Test.Significant(alpha)
+			report.Comparisons = append(report.Comparisons, cmp)
+		}
+	}
+	return report, nil
+}
+
+// runReplications runs all replications of a configuration on up to Workers goroutines
+// and returns them in order.
+func (r *Runner) runReplications(c int, cfg Config) ([]RunResult, error) {
+	results := make([]RunResult, r.Runs)
+	errs := make([]error, r.Runs)
+	replications := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(r.Workers, 1), r.Runs) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rep := range replications {
+				results[rep], errs[rep] = r.runOnce(cfg, r.seedsFor(c, rep))
+				results[rep].Replication = rep
+			}
+		}()
+	}
+	for rep := range r.Runs {
+		replications <- rep
+	}
+	close(replications)
+	wg.Wait()
+	for rep, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("config %s, replication %d: %w", cfg.Name, rep, err)
+		}
+	}
+	return results, nil
+}
+
+// seedsFor returns the seeds of one replication. With common random numbers the
+// configuration index is ignored.
+func (r *Runner) seedsFor(config, replication int) simulation.Seeds {
+	stream := int64(replication)
+	if !r.CommonRandomNumbers {
+		stream += int64(config) * int64(r.Runs)
+	}
+	return simulation.NewSeeds(r.MasterSeed + stream*0x5DEECE66D)
+}
+
+// runOnce builds and runs a single simulation.
+func (r *Runner) runOnce(cfg Config, seeds simulation.Seeds) (RunResult, error) {
+	sim, err := simulation.NewSimulation(r.Scenario.Dimension, r.Scenario.Bounds, time.Duration(r.DeltaTime*float64(time.Second)))
+	if err != nil {
+		return RunResult{}, err
+	}
+	sim.SetSeeds(seeds)
+	if r.Scenario.Populate != nil {
+		if err := r.Scenario.Populate(sim); err != nil {
+			return RunResult{}, fmt.Errorf("populate: %w", err)
+		}
+	}
+	if cfg.Apply != nil {
+		if err := cfg.Apply(sim); err != nil {
+			return RunResult{}, fmt.Errorf("apply: %w", err)
+		}
+	}
+
+	sumErr, localized, total := 0.0, 0, 0
+	for step := 0; step < r.Steps; step++ {
+		sim.Step(r.DeltaTime)
+		for _, tar := range sim.GetTargets() {
+			total++
+			if locErr, ok := sim.GetLastLocalizationError(tar.GetID()); ok && locErr >= 0 {
+				sumErr += locErr
+				localized++
+			}
+		}
+	}
+	res := RunResult{Config: cfg.Name, Seeds: seeds, MeanError: -1}
+	if localized > 0 {
+		res.MeanError = sumErr / float64(localized)
+	}
+	if total > 0 {
+		res.Localized = float64(localized) / float64(total)
+	}
+	return res, nil
+}
+
+// unpairedDifferenceCI bootstraps the difference of means of two independent samples.
+func unpairedDifferenceCI(a, b []float64, level float64, resamples int, rng *rand.Rand) metrics.ConfidenceInterval {
+	diffs := make([]float64, resamples)
+	for r := range diffs {
+		diffs[r] = resampleMean(a, rng) - resampleMean(b, rng)
+	}
+	sort.Float64s(diffs)
+	alpha := (1 - level) / 2
+	lo := int(math.Floor(alpha * float64(resamples-1)))
+	hi := int(math.Ceil((1 - alpha) * float64(resamples-1)))
+	return metrics.ConfidenceInterval{Lower: diffs[lo], Upper: diffs[hi], Level: level}
+}
+
+// resampleMean returns the mean of one bootstrap resample.
+func resampleMean(xs []float64, rng *rand.Rand) float64 {
+	sum := 0.0
+	for range xs {
+		sum += xs[rng.Intn(len(xs))]
+	}
+	return sum / float64(len(xs))
+}
+
+// meanErrors extracts the mean errors of the runs that localized anything.
+func meanErrors(results []RunResult) []float64 {
+	errs := make([]float64, 0, len(results))
+	for _, res := range results {
+		if res.MeanError >= 0 {
+			errs = append(errs, res.MeanError)
+		}
+	}
+	return errs
+}
+
+// String renders the report as a plain-text table.
+func (rep *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-20s %6s %12s %12s  %s\n", "config", "runs", "mean error", "std dev", "confidence interval")
+	for _, name := range rep.Configs {
+		s := rep.Summaries[name]
+		ci := rep.Intervals[name]
+		fmt.Fprintf(&b, "%-20s %6d %12.3f %12.3f  [%.3f, %.3f] (%.0f%%)\n", name, s.N, s.Mean, s.StdDev, ci.Lower, ci.Upper, ci.Level*100)
+	}
+	for _, c := range rep.Comparisons {
+		test := "Welch t-test"
+		if c.Paired {
+			test = "paired t-test"
+		}
+		verdict := "not significant"
+		if c.Significant {
+			verdict = "significant"
+		}
+		fmt.Fprintf(&b, "%s vs %s: diff %+.3f [%.3f, %.3f], %s t=%.2f p=%.4f", c.Config, c.Baseline, c.MeanDifference, c.DifferenceCI.Lower, c.DifferenceCI.Upper, test, c.Test.Statistic, c.Test.PValue)
+		if c.Wilcoxon != nil {
+			fmt.Fprintf(&b, ", Wilcoxon p=%.4f", c.Wilcoxon.PValue)
+		}
+		fmt.Fprintf(&b, " -> %s\n", verdict)
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"multilateration-sim/internal/common"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteTrackCSV writes one row per point: time, the true coordinates and the estimated
+// coordinates (empty when there was no estimate).
+func WriteTrackCSV(w io.Writer, points []TrackPoint) error {
+	return writeCSV(w, false, map[string][]TrackPoint{"": points}, []string{""})
+}
+
+// WriteCSV writes all recorded tracks into one table with a leading target_id column.
+func (rec *TrackRecorder) WriteCSV(w io.Writer) error {
+	ids := rec.TargetIDs()
+	tracks := make(map[string][]TrackPoint, len(ids))
+	for _, id := range ids {
+		tracks[id] = rec.Track(id)
+	}
+	return writeCSV(w, true, tracks, ids)
+}
+
+// WriteTracksCSV writes several tracks (targetID -> points) into one table like
+// TrackRecorder.WriteCSV, ordered by target ID.
+func WriteTracksCSV(w io.Writer, tracks map[string][]TrackPoint) error {
+	ids := make([]string, 0, len(tracks))
+	for id := range tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return writeCSV(w, true, tracks, ids)
+}
+
+func writeCSV(w io.Writer, withID bool, tracks map[string][]TrackPoint, ids []string) error {
+	dimension := 0
+	for _, id := range ids {
+		if len(tracks[id]) > 0 {
+			dimension = tracks[id][0].Truth.Dimension()
+			break
+		}
+	}
+
+	out := csv.NewWriter(w)
+	header := []string{"time"}
+	if withID {
+		header = append([]string{"target_id"}, header...)
+	}
+	for i := 0; i < dimension; i++ {
+		header = append(header, fmt.Sprintf("true_x%d", i))
+	}
+	for i := 0; i < dimension; i++ {
+		header = append(header, fmt.Sprintf("est_x%d", i))
+	}
+	if err := out.Write(header); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	for _, id := range ids {
+		for _, p := range tracks[id] {
+			row := make([]string, 0, len(header))
+			if withID {
+				row = append(row, id)
+			}
+			row = append(row, strconv.FormatFloat(p.Time, 'f', -1, 64))
+			row = appendCoordinates(row, p.Truth, dimension)
+			row = appendCoordinates(row, p.Estimate, dimension)
+			if err := out.Write(row); err != nil {
+				return fmt.Errorf("write csv row: %w", err)
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// appendCoordinates appends dimension cells, empty where v has no value.
+func appendCoordinates(row []string, v []float64, dimension int) []string {
+	for i := 0; i < dimension; i++ {
+		if i < len(v) {
+			row = append(row, strconv.FormatFloat(v[i], 'f', -1, 64))
+		} else {
+			row = append(row, "")
+		}
+	}
+	return row
+}
+
+// ReadTracksCSV parses a table written by WriteCSV, WriteTracksCSV or WriteTrackCSV into
+// targetID -> points; a table without a target_id column is read as the target "".
+func ReadTracksCSV(r io.Reader) (map[string][]TrackPoint, error) {
+	in := csv.NewReader(r)
+	header, err := in.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	withID := len(header) > 0 && header[0] == "target_id"
+	first := 0
+	if withID {
+		first = 1
+	}
+	if len(header) <= first || header[first] != "time" || (len(header)-first-1)%2 != 0 {
+		return nil, fmt.Errorf("not a track table: header %v", header)
+	}
+	dimension := (len(header) - first - 1) / 2
+
+	tracks := make(map[string][]TrackPoint)
+	for line := 2; ; line++ {
+		row, err := in.Read()
+		if err == io.EOF {
+			return tracks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv line %d: %w", line, err)
+		}
+		id := ""
+		if withID {
+			id = row[0]
+		}
+		point := TrackPoint{}
+		if point.Time, err = strconv.ParseFloat(row[first], 64); err != nil {
+			return nil, fmt.Errorf("csv line %d: time: %w", line, err)
+		}
+		if point.Truth, err = parseCoordinates(row[first+1 : first+1+dimension]); err != nil {
+			return nil, fmt.Errorf("csv line %d: true position: %w", line, err)
+		}
+		if point.Truth == nil {
+			return nil, fmt.Errorf("csv line %d: no true position", line)
+		}
+		if point.Estimate, err = parseCoordinates(row[first+1+dimension:]); err != nil {
+			return nil, fmt.Errorf("csv line %d: estimate: %w", line, err)
+		}
+		tracks[id] = append(tracks[id], point)
+	}
+}
+
+// parseCoordinates parses cells written by appendCoordinates; nil if all are empty.
+func parseCoordinates(cells []string) (common.Vector, error) {
+	if strings.Join(cells, "") == "" {
+		return nil, nil
+	}
+	v := make(common.Vector, len(cells))
+	for i, cell := range cells {
+		x, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = x
+	}
+	return v, nil
+}
@@ -0,0 +1,102 @@
+package export_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/simulation"
+	"os"
+	"strings"
+	"time"
+)
+
+// Record a short run and look at the trajectory of one target.
+func ExampleTrackRecorder() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+
+	recorder := export.NewTrackRecorder(sim)
+	for i := 0; i < 3; i++ {
+		sim.Step(0.5)
+	}
+	for _, p := range recorder.Track(target.GetID()) {
+		miss, _ := p.Truth.Distance(p.Estimate) // Rounding noise of the solver, whose sign varies
+		fmt.Printf("t=%.1f truth %v estimate within 1e-9: %v\n", p.Time, p.Truth, miss < 1e-9)
+	}
+	// Output:
+	// t=0.5 truth [5.000, 0.000] estimate within 1e-9: true
+	// t=1.0 truth [10.000, 0.000] estimate within 1e-9: true
+	// t=1.5 truth [15.000, 0.000] estimate within 1e-9: true
+}
+
+// Steps without an estimate leave the estimate columns empty.
+func ExampleWriteTrackCSV() {
+	points := []export.TrackPoint{
+		{Time: 0.1, Truth: common.Vector{1, 2}, Estimate: common.Vector{1.25, 1.5}},
+		{Time: 0.2, Truth: common.Vector{2, 2}},
+	}
+	if err := export.WriteTrackCSV(os.Stdout, points); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// time,true_x0,true_x1,est_x0,est_x1
+	// 0.1,1,2,1.25,1.5
+	// 0.2,2,2,,
+}
+
+// Track tables read back into the points they were written from.
+func ExampleReadTracksCSV() {
+	table := "target_id,time,true_x0,true_x1,est_x0,est_x1\n" +
+		"a,0.1,1,2,1.25,1.5\n" +
+		"a,0.2,2,2,,\n" +
+		"b,0.1,5,5,5,4\n"
+	tracks, err := export.ReadTracksCSV(strings.NewReader(table))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, id := range []string{"a", "b"} {
+		for _, p := range tracks[id] {
+			fmt.Printf("%s t=%.1f truth %v estimate %v\n", id, p.Time, p.Truth, p.Estimate)
+		}
+	}
+	// Output:
+	// a t=0.1 truth [1.000, 2.000] estimate [1.250, 1.500]
+	// a t=0.2 truth [2.000, 2.000] estimate []
+	// b t=0.1 truth [5.000, 5.000] estimate [5.000, 4.000]
+}
+
+func ExampleGeoReference_ToGeodetic() {
+	ref := export.GeoReference{Latitude: 55.75, Longitude: 37.62}
+	lat, lon, _ := ref.ToGeodetic([]float64{1000, 1000}) // 1 km east, 1 km north
+	fmt.Printf("%.5f %.5f\n", lat, lon)
+	// Output:
+	// 55.75899 37.63598
+}
+
+// A receiver-style log of the estimated track, starting at a fixed wall-clock time.
+func ExampleWriteTrackNMEA() {
+	ref := export.GeoReference{Latitude: 55.75, Longitude: 37.62, Epoch: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	points := []export.TrackPoint{
+		{Time: 0, Truth: common.Vector{0, 0}, Estimate: common.Vector{0, 0}},
+		{Time: 1, Truth: common.Vector{10, 0}, Estimate: common.Vector{10, 0}},
+		{Time: 2, Truth: common.Vector{20, 0}},
+	}
+	var buf strings.Builder
+	if err := export.WriteTrackNMEA(&buf, points, ref, export.TrackEstimate); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(strings.ReplaceAll(buf.String(), "\r\n", "\n")) // Sentences end in CRLF
+	// Output:
+	// $GPGGA,120000.00,5545.0000,N,03737.2000,E,1,,,0.0,M,,M,,*5C
+	// $GPRMC,120000.00,A,5545.0000,N,03737.2000,E,0.00,0.0,010524,,,A*6C
+	// $GPGGA,120001.00,5545.0000,N,03737.2096,E,1,,,0.0,M,,M,,*52
+	// $GPRMC,120001.00,A,5545.0000,N,03737.2096,E,19.44,90.0,010524,,,A*63
+	// $GPGGA,120002.00,,,,,0,00,,,M,,M,,*49
+	// $GPRMC,120002.00,V,,,,,,,010524,,,N*7E
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const earthRadius = 6371000.0 // Mean Earth radius in meters
+
+// GeoReference anchors the simulation's Cartesian frame on the globe: the origin is at
+// (Latitude, Longitude), axis 0 points east, axis 1 north and axis 2 up, in meters.
+// The flat-Earth approximation is accurate to well below a meter over a few kilometers.
+type GeoReference struct {
+	Latitude  float64 // Degrees
+	Longitude float64 // Degrees
+	Altitude  float64 // Meters, added to axis 2
+
+	Epoch time.Time // Wall-clock time of simulation time 0; zero writes GPX without times
+}
+
+// ToGeodetic converts a local position into latitude, longitude (degrees) and altitude.
+func (g GeoReference) ToGeodetic(pos []float64) (lat, lon, alt float64) {
+	var east, north float64
+	alt = g.Altitude
+	if len(pos) > 0 {
+		east = pos[0]
+	}
+	if len(pos) > 1 {
+		north = pos[1]
+	}
+	if len(pos) > 2 {
+		alt += pos[2]
+	}
+	lat = g.Latitude + north/earthRadius*180/math.Pi
+	lon = g.Longitude + east/(earthRadius*math.Cos(g.Latitude*math.Pi/180))*180/math.Pi
+	return lat, lon, alt
+}
+
+type gpxFile struct {
+	XMLName xml.Name   `xml:"gpx"`
+	Version string     `xml:"version,attr"`
+	Creator string     `xml:"creator,attr"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Tracks  []gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat       float64  `xml:"lat,attr"`
+	Lon       float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele,omitempty"`
+	Time      string   `xml:"time,omitempty"`
+}
+
+// WriteTrackGPX writes the true and the estimated trajectory of one target as two GPX
+// tracks. Gaps without an estimate split the estimated track into segments. Point times
+// are written only if ref has an Epoch, since simulation time has no calendar date.
+func WriteTrackGPX(w io.Writer, name string, points []TrackPoint, ref GeoReference) error {
+	truth := gpxTrack{Name: name + " (truth)"}
+	estimate := gpxTrack{Name: name + " (estimate)"}
+	var truthSeg, estSeg gpxSegment
+	for _, p := range points {
+		truthSeg.Points = append(truthSeg.Points, ref.gpxPoint(p.Truth, p.Time))
+		if p.Estimate == nil {
+			if len(estSeg.Points) > 0 {
+				estimate.Segments = append(estimate.Segments, estSeg)
+				estSeg = gpxSegment{}
+			}
+			continue
+		}
+		estSeg.Points = append(estSeg.Points, ref.gpxPoint(p.Estimate, p.Time))
+	}
+	if len(truthSeg.Points) > 0 {
+		truth.Segments = append(truth.Segments, truthSeg)
+	}
+	if len(estSeg.Points) > 0 {
+		estimate.Segments = append(estimate.Segments, estSeg)
+	}
+
+	doc := gpxFile{
+		Version: "1.1",
+		Creator: "multilateration-sim",
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Tracks:  []gpxTrack{truth, estimate},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write gpx: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("write gpx: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (g GeoReference) gpxPoint(pos []float64, t float64) gpxPoint {
+	lat, lon, alt := g.ToGeodetic(pos)
+	p := gpxPoint{Lat: lat, Lon: lon}
+	if len(pos) > 2 || g.Altitude != 0 {
+		p.Elevation = &alt
+	}
+	if !g.Epoch.IsZero() {
+		p.Time = g.Timestamp(t).Format(time.RFC3339Nano)
+	}
+	return p
+}
@@ -0,0 +1,201 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/simulation"
+	"sync"
+)
+
+// measurementLogFormat identifies measurement logs in their header line.
+const measurementLogFormat = "multilateration-measurements/1"
+
+// MeasurementRecord is the input of the solver for one target in one step, together
+// with the true position to score estimates against.
+type MeasurementRecord struct {
+	Time         float64
+	TargetID     string
+	Truth        common.Vector
+	Measurements []multilateration.Measurement
+}
+
+// MeasurementLog is a recorded run: every solver input, in step order.
+type MeasurementLog struct {
+	Dimension int
+	Records   []MeasurementRecord
+}
+
+// MeasurementRecorder records the solver input of every target and step, so the run
+// can be re-evaluated later with other solvers and trackers on identical data.
+// Targets outside the region of interest are not solved and therefore not recorded.
+// Safe for concurrent use.
+type MeasurementRecorder struct {
+	mu        sync.RWMutex
+	dimension int
+	records   []MeasurementRecord
+}
+
+// NewMeasurementRecorder creates a recorder and subscribes it to the simulation's steps.
+func NewMeasurementRecorder(sim *simulation.Simulation) *MeasurementRecorder {
+	rec := &MeasurementRecorder{dimension: sim.GetDimension()}
+	sim.OnStep(rec.HandleStep)
+	return rec
+}
+
+// HandleStep appends the measurements of every reported target.
+func (rec *MeasurementRecorder) HandleStep(report simulation.StepReport) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for _, t := range report.Targets {
+		if t.Outcome == simulation.OutcomeOutsideRegion {
+			continue
+		}
+		st, ok := report.State.Target(t.TargetID)
+		if !ok {
+			continue
+		}
+		rec.records = append(rec.records, MeasurementRecord{
+			Time:         report.Time,
+			TargetID:     t.TargetID,
+			Truth:        st.Position,
+			Measurements: cloneMeasurements(t.Measurements), // The report's slice is shared
+		})
+	}
+}
+
+// Log returns a copy of everything recorded so far.
+func (rec *MeasurementRecorder) Log() MeasurementLog {
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+	return MeasurementLog{Dimension: rec.dimension, Records: append([]MeasurementRecord(nil), rec.records...)}
+}
+
+// WriteJSONL writes the recorded log, see WriteMeasurementLog.
+func (rec *MeasurementRecorder) WriteJSONL(w io.Writer) error {
+	return WriteMeasurementLog(w, rec.Log())
+}
+
+// logHeader is the first line of a measurement log.
+type logHeader struct {
+	Format    string `json:"format"`
+	Dimension int    `json:"dimension"`
+}
+
+// logRecord is one line of a measurement log after the header.
+type logRecord struct {
+	Time         float64          `json:"time"`
+	TargetID     string           `json:"target_id"`
+	Truth        []float64        `json:"truth"`
+	Measurements []logMeasurement `json:"measurements"`
+}
+
+type logMeasurement struct {
+	SensorID       string    `json:"sensor_id,omitempty"`
+	SensorPosition []float64 `json:"sensor_position"`
+	Distance       float64   `json:"distance"`
+	Timestamp      float64   `json:"timestamp"`
+	Variance       float64   `json:"variance,omitempty"`
+	RangeRate      *float64  `json:"range_rate,omitempty"` // Absent without Doppler
+	SensorVelocity []float64 `json:"sensor_velocity,omitempty"`
+}
+
+// WriteMeasurementLog writes a log as JSON lines: a header with the format and the
+// dimension, then one line per record. The format is line oriented so that logs of
+// long runs can be cut with standard tools.
+func WriteMeasurementLog(w io.Writer, log MeasurementLog) error {
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	if err := enc.Encode(logHeader{Format: measurementLogFormat, Dimension: log.Dimension}); err != nil {
+		return fmt.Errorf("write measurement log header: %w", err)
+	}
+	for _, r := range log.Records {
+		line := logRecord{Time: r.Time, TargetID: r.TargetID, Truth: r.Truth, Measurements: make([]logMeasurement, len(r.Measurements))}
+		for i, m := range r.Measurements {
+			lm := logMeasurement{
+				SensorID:       m.SensorID,
+				SensorPosition: m.SensorPosition,
+				Distance:       m.Distance,
+				Timestamp:      m.Timestamp,
+				Variance:       m.Variance,
+				SensorVelocity: m.SensorVelocity,
+			}
+			if m.HasRangeRate {
+				rate := m.RangeRate
+				lm.RangeRate = &rate
+			}
+			line.Measurements[i] = lm
+		}
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("write measurement log record at t=%g: %w", r.Time, err)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write measurement log: %w", err)
+	}
+	return nil
+}
+
+// ReadMeasurementLog parses a log written by WriteMeasurementLog.
+func ReadMeasurementLog(r io.Reader) (MeasurementLog, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var header logHeader
+	if err := dec.Decode(&header); err != nil {
+		return MeasurementLog{}, fmt.Errorf("read measurement log header: %w", err)
+	}
+	if header.Format != measurementLogFormat {
+		return MeasurementLog{}, fmt.Errorf("not a measurement log: format %q, expected %q", header.Format, measurementLogFormat)
+	}
+	if header.Dimension < 1 {
+		return MeasurementLog{}, fmt.Errorf("invalid measurement log dimension %d", header.Dimension)
+	}
+
+	log := MeasurementLog{Dimension: header.Dimension}
+	for line := 2; dec.More(); line++ {
+		var lr logRecord
+		if err := dec.Decode(&lr); err != nil {
+			return MeasurementLog{}, fmt.Errorf("read measurement log line %d: %w", line, err)
+		}
+		if len(lr.Truth) != header.Dimension {
+			return MeasurementLog{}, fmt.Errorf("measurement log line %d: truth has dimension %d, expected %d", line, len(lr.Truth), header.Dimension)
+		}
+		record := MeasurementRecord{Time: lr.Time, TargetID: lr.TargetID, Truth: lr.Truth, Measurements: make([]multilateration.Measurement, len(lr.Measurements))}
+		for i, lm := range lr.Measurements {
+			if len(lm.SensorPosition) != header.Dimension {
+				return MeasurementLog{}, fmt.Errorf("measurement log line %d: sensor position has dimension %d, expected %d", line, len(lm.SensorPosition), header.Dimension)
+			}
+			m := multilateration.Measurement{
+				SensorID:       lm.SensorID,
+				SensorPosition: lm.SensorPosition,
+				Distance:       lm.Distance,
+				Timestamp:      lm.Timestamp,
+				Variance:       lm.Variance,
+				SensorVelocity: lm.SensorVelocity,
+			}
+			if lm.RangeRate != nil {
+				m.RangeRate, m.HasRangeRate = *lm.RangeRate, true
+			}
+			record.Measurements[i] = m
+		}
+		log.Records = append(log.Records, record)
+	}
+	return log, nil
+}
+
+func cloneMeasurements(ms []multilateration.Measurement) []multilateration.Measurement {
+	out := make([]multilateration.Measurement, len(ms))
+	for i, m := range ms {
+		out[i] = m
+		if m.SensorPosition != nil {
+			out[i].SensorPosition = m.SensorPosition.Clone()
+		}
+		if m.SensorVelocity != nil {
+			out[i].SensorVelocity = m.SensorVelocity.Clone()
+		}
+	}
+	return out
+}
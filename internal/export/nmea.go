@@ -0,0 +1,107 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	metersPerSecondToKnots = 3600.0 / 1852
+	defaultNMEAEpoch       = "2000-01-01T00:00:00Z" // NMEA needs a date; used when GeoReference.Epoch is zero
+)
+
+// TrackSource selects which trajectory of a target an export describes.
+type TrackSource int
+
+const (
+	TrackEstimate TrackSource = iota // What a receiver running the solver would report
+	TrackTruth                       // Ground truth
+)
+
+// Timestamp converts simulation seconds into wall-clock time: Epoch plus t, or
+// 2000-01-01 UTC plus t if no epoch is set.
+func (g GeoReference) Timestamp(t float64) time.Time {
+	epoch := g.Epoch
+	if epoch.IsZero() {
+		epoch, _ = time.Parse(time.RFC3339, defaultNMEAEpoch)
+	}
+	return epoch.Add(time.Duration(t * float64(time.Second))).UTC()
+}
+
+// WriteTrackNMEA writes one trajectory of a target as NMEA 0183 sentences, a GGA (fix)
+// and an RMC (course and speed) per point, so it can be replayed into GIS tools and
+// navigation software as a simulated receiver. Points without an estimate are written
+// as invalid fixes, the way a receiver reports lost lock.
+func WriteTrackNMEA(w io.Writer, points []TrackPoint, ref GeoReference, source TrackSource) error {
+	out := bufio.NewWriter(w)
+	var prev []float64
+	prevTime := 0.0
+	for _, p := range points {
+		pos := p.Truth
+		if source == TrackEstimate {
+			pos = p.Estimate
+		}
+		ts := ref.Timestamp(p.Time)
+		if pos == nil {
+			writeSentence(out, fmt.Sprintf("GPGGA,%s,,,,,0,00,,,M,,M,,", nmeaTime(ts)))
+			writeSentence(out, fmt.Sprintf("GPRMC,%s,V,,,,,,,%s,,,N", nmeaTime(ts), ts.Format("020106")))
+			prev = nil
+			continue
+		}
+
+		lat, lon, alt := ref.ToGeodetic(pos)
+		latField, lonField := nmeaAngle(lat, 2, "N", "S"), nmeaAngle(lon, 3, "E", "W")
+		writeSentence(out, fmt.Sprintf("GPGGA,%s,%s,%s,1,,,%.1f,M,,M,,", nmeaTime(ts), latField, lonField, alt))
+
+		speed, course := 0.0, 0.0
+		if prev != nil && p.Time > prevTime {
+			east, north := axis(pos, 0)-axis(prev, 0), axis(pos, 1)-axis(prev, 1)
+			speed = math.Hypot(east, north) / (p.Time - prevTime) * metersPerSecondToKnots
+			course = math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360)
+		}
+		writeSentence(out, fmt.Sprintf("GPRMC,%s,A,%s,%s,%.2f,%.1f,%s,,,A", nmeaTime(ts), latField, lonField, speed, course, ts.Format("020106")))
+		prev, prevTime = pos, p.Time
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("write nmea: %w", err)
+	}
+	return nil
+}
+
+// writeSentence appends the checksum and line ending; errors surface in Flush.
+func writeSentence(out *bufio.Writer, body string) {
+	var checksum byte
+	for i := 0; i < len(body); i++ {
+		checksum ^= body[i]
+	}
+	fmt.Fprintf(out, "$%s*%02X\r\n", body, checksum)
+}
+
+// nmeaTime formats hhmmss.ss.
+func nmeaTime(t time.Time) string {
+	return fmt.Sprintf("%02d%02d%02d.%02d", t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1e7)
+}
+
+// nmeaAngle formats degrees as (d)ddmm.mmmm with a hemisphere field.
+func nmeaAngle(deg float64, degreeDigits int, positive, negative string) string {
+	hemisphere := positive
+	if deg < 0 {
+		hemisphere, deg = negative, -deg
+	}
+	whole := math.Floor(deg)
+	minutes := (deg - whole) * 60
+	if math.Round(minutes*1e4) >= 60e4 { // Rounding up to a full degree
+		whole, minutes = whole+1, 0
+	}
+	return fmt.Sprintf("%0*d%07.4f,%s", degreeDigits, int(whole), minutes, hemisphere)
+}
+
+func axis(pos []float64, i int) float64 {
+	if i < len(pos) {
+		return pos[i]
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+// Package export records target trajectories of a simulation run and writes them to
+// files for external analysis (CSV) or map tools (GPX, NMEA). Measurement logs keep
+// the solver input of a run for replaying it through other algorithms.
+package export
+
+import (
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"sort"
+	"sync"
+)
+
+// TrackPoint is the state of one target at the end of a step.
+type TrackPoint struct {
+	Time     float64
+	Truth    common.Vector
+	Estimate common.Vector // nil if the target was not localized in this step
+}
+
+// TrackRecorder keeps the full trajectory history of every target for the current run.
+// Safe for concurrent use, so tracks can be exported while the simulation runs.
+type TrackRecorder struct {
+	mu     sync.RWMutex
+	tracks map[string][]TrackPoint // targetID -> points, oldest first
+}
+
+// NewTrackRecorder creates a recorder and subscribes it to the simulation's steps.
+func NewTrackRecorder(sim *simulation.Simulation) *TrackRecorder {
+	rec := &TrackRecorder{tracks: make(map[string][]TrackPoint)}
+	sim.OnStep(rec.HandleStep)
+	return rec
+}
+
+// HandleStep appends the current truth and estimate of every reported target.
+func (rec *TrackRecorder) HandleStep(report simulation.StepReport) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for _, t := range report.Targets {
+		st, ok := report.State.Target(t.TargetID)
+		if !ok {
+			continue
+		}
+		point := TrackPoint{Time: report.Time, Truth: st.Position}
+		if t.Outcome == simulation.OutcomeLocalized {
+			point.Estimate = st.Estimate.Position
+		}
+		rec.tracks[t.TargetID] = append(rec.tracks[t.TargetID], point)
+	}
+}
+
+// Track returns a copy of the recorded history of a target, oldest first.
+func (rec *TrackRecorder) Track(targetID string) []TrackPoint {
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+	return append([]TrackPoint(nil), rec.tracks[targetID]...)
+}
+
+// TargetIDs returns the IDs of all recorded targets, sorted.
+func (rec *TrackRecorder) TargetIDs() []string {
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+	ids := make([]string, 0, len(rec.tracks))
+	for id := range rec.tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"runtime"
+)
+
+// RuntimeStats is a small subset of the Go runtime statistics, enough to spot leaks
+// and allocation-heavy code paths without a profiler.
+type RuntimeStats struct {
+	Goroutines      int    `json:"goroutines"`
+	HeapAllocBytes  uint64 `json:"heap_alloc_bytes"`  // Bytes of live heap objects
+	TotalAllocBytes uint64 `json:"total_alloc_bytes"` // Cumulative bytes allocated
+	Mallocs         uint64 `json:"mallocs"`           // Cumulative heap objects allocated
+	NumGC           uint32 `json:"num_gc"`
+}
+
+// ReadRuntimeStats samples the runtime. It briefly stops the world, so call it at
+// monitoring rates, not per step.
+func ReadRuntimeStats() RuntimeStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return RuntimeStats{
+		Goroutines:      runtime.NumGoroutine(),
+		HeapAllocBytes:  ms.HeapAlloc,
+		TotalAllocBytes: ms.TotalAlloc,
+		Mallocs:         ms.Mallocs,
+		NumGC:           ms.NumGC,
+	}
+}
+
+// debugState is the document served by Handler and published by Publish.
+type debugState struct {
+	Snapshot
+	Runtime RuntimeStats `json:"runtime"`
+}
+
+func (r *Registry) debugState() debugState {
+	return debugState{Snapshot: r.Snapshot(), Runtime: ReadRuntimeStats()}
+}
+
+// Publish exposes the registry and runtime stats as an expvar under name, so they
+// show up at /debug/vars of any server using http.DefaultServeMux. Like expvar.Publish
+// it panics if the name is already taken.
+func (r *Registry) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return r.debugState() }))
+}
+
+// Handler serves the registry and runtime stats as a JSON document, for monitoring
+// without expvar or a Prometheus stack.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r.debugState()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/metrics"
+	"net/http/httptest"
+)
+
+// Decide whether solver B is more accurate than solver A on the same ten scenarios.
+func ExampleWilcoxonSignedRank() {
+	errorsA := []float64{2.1, 3.4, 1.9, 5.0, 2.7, 3.3, 4.1, 2.2, 3.8, 2.9}
+	errorsB := []float64{1.8, 3.0, 1.7, 4.1, 2.6, 2.8, 3.5, 2.05, 3.1, 2.45}
+
+	wilcoxon, _ := metrics.WilcoxonSignedRank(errorsB, errorsA)
+	tTest, _ := metrics.PairedTTest(errorsB, errorsA)
+	fmt.Printf("Wilcoxon W+=%.0f p=%.4f\n", wilcoxon.Statistic, wilcoxon.PValue)
+	fmt.Printf("paired t-test significant: %t\n", tTest.Significant(0.05))
+
+	ci, _ := metrics.BootstrapMeanCI(errorsA, 0.95, 2000, rand.New(rand.NewSource(1)))
+	fmt.Printf("mean of A within its interval: %t\n", ci.Contains(metrics.Summarize(errorsA).Mean))
+	// Output:
+	// Wilcoxon W+=0 p=0.0020
+	// paired t-test significant: true
+	// mean of A within its interval: true
+}
+
+// Serve the registry as JSON for monitoring.
+func ExampleRegistry_Handler() {
+	reg := metrics.NewRegistry()
+	reg.Add("steps", 3)
+	reg.Set("targets", 2)
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/simulation", nil))
+
+	var doc struct {
+		Counters map[string]float64
+		Gauges   map[string]float64
+		Runtime  metrics.RuntimeStats
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(rec.Header().Get("Content-Type"))
+	fmt.Println(doc.Counters["steps"], doc.Gauges["targets"], doc.Runtime.Goroutines > 0)
+	// Output:
+	// application/json
+	// 3 2 true
+}
+
+// An auto-surveyed trajectory is correct up to a rotated and shifted frame: the raw
+// ATE reflects the frame offset, the rigidly aligned ATE does not.
+func ExampleAbsoluteTrajectoryError() {
+	var truth, estimate []common.Vector
+	angle := math.Pi / 6
+	for i := 0; i < 10; i++ {
+		x, y := float64(i)*2, math.Sin(float64(i))
+		truth = append(truth, common.Vector{x, y})
+		estimate = append(estimate, common.Vector{
+			math.Cos(angle)*x - math.Sin(angle)*y + 5,
+			math.Sin(angle)*x + math.Cos(angle)*y - 3,
+		})
+	}
+	estimate[4] = nil // A step without a fix
+
+	raw, _ := metrics.AbsoluteTrajectoryError(truth, estimate, metrics.AlignNone)
+	aligned, _ := metrics.AbsoluteTrajectoryError(truth, estimate, metrics.AlignRigid)
+	fmt.Printf("raw ATE RMSE %.2f over %d poses\n", raw.RMSE, raw.N)
+	fmt.Printf("aligned ATE RMSE %.2f\n", aligned.RMSE)
+
+	// The relative error ignores translation only, so undo the rotation first
+	for i, e := range estimate {
+		if e != nil {
+			estimate[i], _ = aligned.Alignment.Apply(e)
+		}
+	}
+	for _, delta := range []int{1, 3} {
+		rpe, _ := metrics.RelativePoseError(truth, estimate, delta)
+		fmt.Printf("RPE(%d) RMSE %.2f over %d pairs\n", delta, rpe.RMSE, rpe.N)
+	}
+	// Output:
+	// raw ATE RMSE 5.04 over 9 poses
+	// aligned ATE RMSE 0.00
+	// RPE(1) RMSE 0.00 over 7 pairs
+	// RPE(3) RMSE 0.00 over 5 pairs
+}
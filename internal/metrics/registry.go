@@ -0,0 +1,119 @@
+// Package metrics collects runtime counters and gauges of a simulation run.
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry is a thread-safe store of named counters (monotonic sums) and gauges (last value).
+type Registry struct {
+	mu       sync.RWMutex
+	counters map[string]float64
+	gauges   map[string]float64
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		counters: make(map[string]float64),
+		gauges:   make(map[string]float64),
+	}
+}
+
+// Add increases a counter by delta.
+func (r *Registry) Add(name string, delta float64) {
+	r.mu.Lock()
+	r.counters[name] += delta
+	r.mu.Unlock()
+}
+
+// Inc increases a counter by one.
+func (r *Registry) Inc(name string) {
+	r.Add(name, 1)
+}
+
+// Set sets a gauge to value.
+func (r *Registry) Set(name string, value float64) {
+	r.mu.Lock()
+	r.gauges[name] = value
+	r.mu.Unlock()
+}
+
+// Counter returns the current value of a counter, 0 if it was never incremented.
+func (r *Registry) Counter(name string) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.counters[name]
+}
+
+// Gauge returns the current value of a gauge and whether it was ever set.
+func (r *Registry) Gauge(name string) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	val, ok := r.gauges[name]
+	return val, ok
+}
+
+// Snapshot is a point-in-time copy of all metrics.
+type Snapshot struct {
+	Counters map[string]float64 `json:"counters"`
+	Gauges   map[string]float64 `json:"gauges"`
+}
+
+// Snapshot copies all metrics.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snap := Snapshot{
+		Counters: make(map[string]float64, len(r.counters)),
+		Gauges:   make(map[string]float64, len(r.gauges)),
+	}
+	for k, v := range r.counters {
+		snap.Counters[k] = v
+	}
+	for k, v := range r.gauges {
+		snap.Gauges[k] = v
+	}
+	return snap
+}
+
+// Restore replaces all metrics with the values of a snapshot, e.g. when a simulation
+// resumes from a checkpoint.
+func (r *Registry) Restore(snap Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters = make(map[string]float64, len(snap.Counters))
+	r.gauges = make(map[string]float64, len(snap.Gauges))
+	for k, v := range snap.Counters {
+		r.counters[k] = v
+	}
+	for k, v := range snap.Gauges {
+		r.gauges[k] = v
+	}
+}
+
+// Reset clears all metrics.
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	r.counters = make(map[string]float64)
+	r.gauges = make(map[string]float64)
+	r.mu.Unlock()
+}
+
+// Key builds a metric name with labels in Prometheus style, e.g.
+// Key("measurements_dropped", "sensor", "sensor-1a2b") -> measurements_dropped{sensor="sensor-1a2b"}.
+// labels are key/value pairs; a trailing key without value is ignored.
+func Key(name string, labels ...string) string {
+	if len(labels) < 2 {
+		return name
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], labels[i+1]))
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
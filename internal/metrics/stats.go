@@ -0,0 +1,222 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Summary describes a sample of a scalar metric, e.g. the mean error of every run.
+type Summary struct {
+	N      int
+	Mean   float64
+	StdDev float64 // Sample standard deviation, 0 if N < 2
+}
+
+// Summarize computes the summary of a sample.
+func Summarize(xs []float64) Summary {
+	s := Summary{N: len(xs)}
+	if len(xs) == 0 {
+		return s
+	}
+	s.Mean = stat.Mean(xs, nil)
+	if len(xs) > 1 {
+		s.StdDev = stat.StdDev(xs, nil)
+	}
+	return s
+}
+
+// TestResult is the outcome of a two-sided significance test.
+type TestResult struct {
+	Statistic float64 // Test statistic (t for t-tests)
+	DF        float64 // Degrees of freedom, 0 if not applicable
+	PValue    float64 // Two-sided p-value
+}
+
+// Significant reports whether the null hypothesis is rejected at level alpha (e.g. 0.05).
+func (r TestResult) Significant(alpha float64) bool {
+	return r.PValue < alpha
+}
+
+// PairedTTest tests whether the mean of the pairwise differences a[i]-b[i] is zero.
+// Use it when both samples share their random numbers (common random numbers),
+// which removes the between-run variance from the comparison.
+func PairedTTest(a, b []float64) (TestResult, error) {
+	if len(a) != len(b) {
+		return TestResult{}, fmt.Errorf("paired samples must have the same length, got %d and %d", len(a), len(b))
+	}
+	if len(a) < 2 {
+		return TestResult{}, fmt.Errorf("paired t-test needs at least 2 pairs, got %d", len(a))
+	}
+	diffs := make([]float64, len(a))
+	for i := range a {
+		diffs[i] = a[i] - b[i]
+	}
+	mean, sd := stat.MeanStdDev(diffs, nil)
+	df := float64(len(diffs) - 1)
+	return tTest(mean, sd/math.Sqrt(float64(len(diffs))), df), nil
+}
+
+// WelchTTest tests whether two independent samples have the same mean,
+// without assuming equal variances.
+func WelchTTest(a, b []float64) (TestResult, error) {
+	if len(a) < 2 || len(b) < 2 {
+		return TestResult{}, fmt.Errorf("Welch t-test needs at least 2 samples per group, got %d and %d", len(a), len(b))
+	}
+	meanA, varA := stat.MeanVariance(a, nil)
+	meanB, varB := stat.MeanVariance(b, nil)
+	na, nb := float64(len(a)), float64(len(b))
+	qa, qb := varA/na, varB/nb
+	se := math.Sqrt(qa + qb)
+	df := (qa + qb) * (qa + qb) / (qa*qa/(na-1) + qb*qb/(nb-1))
+	return tTest(meanA-meanB, se, df), nil
+}
+
+// tTest turns a mean difference and its standard error into a two-sided t-test.
+func tTest(diff, se, df float64) TestResult {
+	if se == 0 {
+		if diff == 0 {
+			return TestResult{DF: df, PValue: 1}
+		}
+		return TestResult{Statistic: math.Copysign(math.Inf(1), diff), DF: df, PValue: 0}
+	}
+	t := diff / se
+	if math.IsNaN(df) || df <= 0 {
+		df = 1
+	}
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}
+	return TestResult{Statistic: t, DF: df, PValue: 2 * dist.Survival(math.Abs(t))}
+}
+
+// ConfidenceInterval is a two-sided interval estimate.
+type ConfidenceInterval struct {
+	Lower, Upper float64
+	Level        float64 // Coverage, e.g. 0.95
+}
+
+// Contains reports whether x lies within the interval.
+func (ci ConfidenceInterval) Contains(x float64) bool {
+	return ci.Lower <= x && x <= ci.Upper
+}
+
+// BootstrapMeanCI estimates a confidence interval of the mean with the percentile
+// bootstrap: the sample is resampled with replacement and the interval is read off
+// the distribution of the resampled means. It makes no normality assumption, which
+// matters for skewed error distributions. rng makes the interval reproducible.
+func BootstrapMeanCI(xs []float64, level float64, resamples int, rng *rand.Rand) (ConfidenceInterval, error) {
+	if len(xs) == 0 {
+		return ConfidenceInterval{}, fmt.Errorf("bootstrap needs a non-empty sample")
+	}
+	if level <= 0 || level >= 1 {
+		return ConfidenceInterval{}, fmt.Errorf("confidence level must be in (0, 1), got %f", level)
+	}
+	if resamples < 1 {
+		return ConfidenceInterval{}, fmt.Errorf("bootstrap needs at least one resample, got %d", resamples)
+	}
+	means := make([]float64, resamples)
+	for r := range means {
+		sum := 0.0
+		for range xs {
+			sum += xs[rng.Intn(len(xs))]
+		}
+		means[r] = sum / float64(len(xs))
+	}
+	sort.Float64s(means)
+	alpha := (1 - level) / 2
+	return ConfidenceInterval{
+		Lower: stat.Quantile(alpha, stat.Empirical, means, nil),
+		Upper: stat.Quantile(1-alpha, stat.Empirical, means, nil),
+		Level: level,
+	}, nil
+}
+
+// wilcoxonExactLimit is the largest number of non-zero differences for which the
+// exact null distribution of the signed-rank statistic is computed.
+const wilcoxonExactLimit = 25
+
+// WilcoxonSignedRank tests whether the pairwise differences a[i]-b[i] are symmetric
+// around zero. Unlike the paired t-test it does not assume normal differences, so a
+// few diverging runs cannot dominate the result. Zero differences are dropped; the
+// p-value is exact for small samples without ties and uses the normal approximation
+// (with tie and continuity correction) otherwise. Statistic is W+, the rank sum of
+// the positive differences.
+func WilcoxonSignedRank(a, b []float64) (TestResult, error) {
+	if len(a) != len(b) {
+		return TestResult{}, fmt.Errorf("paired samples must have the same length, got %d and %d", len(a), len(b))
+	}
+	diffs := make([]float64, 0, len(a))
+	for i := range a {
+		if d := a[i] - b[i]; d != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	n := len(diffs)
+	if n == 0 {
+		return TestResult{PValue: 1}, nil
+	}
+	sort.Slice(diffs, func(i, j int) bool { return math.Abs(diffs[i]) < math.Abs(diffs[j]) })
+
+	// Average ranks of tied absolute differences
+	ranks := make([]float64, n)
+	tieCorrection := 0.0
+	ties := false
+	for i := 0; i < n; {
+		j := i
+		for j+1 < n && math.Abs(diffs[j+1]) == math.Abs(diffs[i]) {
+			j++
+		}
+		for k := i; k <= j; k++ {
+			ranks[k] = float64(i+j+2) / 2
+		}
+		if t := float64(j - i + 1); t > 1 {
+			ties = true
+			tieCorrection += t*t*t - t
+		}
+		i = j + 1
+	}
+	wPlus := 0.0
+	for i, d := range diffs {
+		if d > 0 {
+			wPlus += ranks[i]
+		}
+	}
+
+	if !ties && n <= wilcoxonExactLimit {
+		return TestResult{Statistic: wPlus, PValue: wilcoxonExactP(n, wPlus)}, nil
+	}
+	mean := float64(n*(n+1)) / 4
+	variance := float64(n*(n+1)*(2*n+1))/24 - tieCorrection/48
+	if variance <= 0 {
+		return TestResult{Statistic: wPlus, PValue: 1}, nil
+	}
+	z := math.Max(math.Abs(wPlus-mean)-0.5, 0) / math.Sqrt(variance)
+	p := 2 * distuv.UnitNormal.Survival(z)
+	return TestResult{Statistic: wPlus, PValue: math.Min(p, 1)}, nil
+}
+
+// wilcoxonExactP returns the exact two-sided p-value of W+ for n untied differences,
+// counting the subsets of {1..n} by rank sum.
+func wilcoxonExactP(n int, wPlus float64) float64 {
+	maxSum := n * (n + 1) / 2
+	counts := make([]float64, maxSum+1)
+	counts[0] = 1
+	for r := 1; r <= n; r++ {
+		for sum := maxSum; sum >= r; sum-- {
+			counts[sum] += counts[sum-r]
+		}
+	}
+	total := math.Pow(2, float64(n))
+	w := int(math.Round(wPlus))
+	if other := maxSum - w; other < w {
+		w = other // Use the smaller tail, the distribution is symmetric
+	}
+	tail := 0.0
+	for sum := 0; sum <= w; sum++ {
+		tail += counts[sum]
+	}
+	return math.Min(2*tail/total, 1)
+}
@@ -0,0 +1,236 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Alignment selects how an estimated trajectory is registered onto the true one
+// before the absolute trajectory error is computed. Auto-surveyed anchors and
+// range-only SLAM only recover the geometry up to a global frame, so comparing them
+// without alignment measures the frame offset rather than the localization quality.
+type Alignment int
+
+const (
+	AlignNone        Alignment = iota // Compare in the world frame as is
+	AlignTranslation                  // Remove the mean offset
+	AlignRigid                        // Best-fit rotation and translation (Kabsch/Umeyama without scale)
+)
+
+// String returns the name of the alignment mode.
+func (a Alignment) String() string {
+	switch a {
+	case AlignNone:
+		return "none"
+	case AlignTranslation:
+		return "translation"
+	case AlignRigid:
+		return "rigid"
+	}
+	return fmt.Sprintf("Alignment(%d)", int(a))
+}
+
+// TrajectoryError summarizes the per-pose errors of an estimated trajectory.
+type TrajectoryError struct {
+	N      int
+	RMSE   float64
+	Mean   float64
+	Median float64
+	Max    float64
+	Errors []float64 // Per-pair errors in trajectory order
+
+	// Alignment maps estimates into the true frame (x_true ~ Rotation*x_est + Translation).
+	// Identity for AlignNone and for the relative pose error.
+	Alignment common.Transform
+}
+
+// AbsoluteTrajectoryError (ATE) compares the estimated and true positions pose by
+// pose after registering the estimate with the given alignment. Pairs with a nil
+// estimate (steps without a fix) are skipped.
+func AbsoluteTrajectoryError(truth, estimate []common.Vector, align Alignment) (TrajectoryError, error) {
+	t, e, err := pairTrajectories(truth, estimate)
+	if err != nil {
+		return TrajectoryError{}, err
+	}
+	if len(t) == 0 {
+		return TrajectoryError{}, fmt.Errorf("no poses with an estimate to compare")
+	}
+
+	var transform common.Transform
+	switch align {
+	case AlignNone:
+	case AlignTranslation:
+		transform = common.NewTranslation(difference(centroid(t), centroid(e)))
+	case AlignRigid:
+		if transform, err = rigidAlignment(t, e); err != nil {
+			return TrajectoryError{}, err
+		}
+	default:
+		return TrajectoryError{}, fmt.Errorf("unknown alignment %v", align)
+	}
+
+	errs := make([]float64, len(t))
+	for i := range t {
+		aligned, err := transform.Apply(e[i])
+		if err != nil {
+			return TrajectoryError{}, fmt.Errorf("pose %d: %w", i, err)
+		}
+		if errs[i], err = t[i].Distance(aligned); err != nil {
+			return TrajectoryError{}, fmt.Errorf("pose %d: %w", i, err)
+		}
+	}
+	result := summarizeErrors(errs)
+	result.Alignment = transform
+	return result, nil
+}
+
+// RelativePoseError (RPE) compares the displacement over delta poses of the estimate
+// with that of the truth, ||(e[i+delta]-e[i]) - (t[i+delta]-t[i])||. It measures local
+// drift and ignores a constant translation of the frame; if the frame may also be
+// rotated, map the estimate through the Alignment of a rigid ATE first. Evaluate several
+// deltas to see how the drift grows with the horizon. Pairs missing an estimate at
+// either end are skipped.
+func RelativePoseError(truth, estimate []common.Vector, delta int) (TrajectoryError, error) {
+	if len(truth) != len(estimate) {
+		return TrajectoryError{}, fmt.Errorf("trajectories must have the same length, got %d and %d", len(truth), len(estimate))
+	}
+	if delta < 1 {
+		return TrajectoryError{}, fmt.Errorf("delta must be at least 1, got %d", delta)
+	}
+	var errs []float64
+	for i := 0; i+delta < len(truth); i++ {
+		j := i + delta
+		if estimate[i] == nil || estimate[j] == nil || truth[i] == nil || truth[j] == nil {
+			continue
+		}
+		trueStep, err := truth[j].Subtract(truth[i])
+		if err != nil {
+			return TrajectoryError{}, fmt.Errorf("pose %d: %w", i, err)
+		}
+		estStep, err := estimate[j].Subtract(estimate[i])
+		if err != nil {
+			return TrajectoryError{}, fmt.Errorf("pose %d: %w", i, err)
+		}
+		d, err := trueStep.Distance(estStep)
+		if err != nil {
+			return TrajectoryError{}, fmt.Errorf("pose %d: %w", i, err)
+		}
+		errs = append(errs, d)
+	}
+	if len(errs) == 0 {
+		return TrajectoryError{}, fmt.Errorf("no pose pairs %d apart with estimates to compare", delta)
+	}
+	return summarizeErrors(errs), nil
+}
+
+// pairTrajectories drops the poses without an estimate and checks the dimensions.
+func pairTrajectories(truth, estimate []common.Vector) ([]common.Vector, []common.Vector, error) {
+	if len(truth) != len(estimate) {
+		return nil, nil, fmt.Errorf("trajectories must have the same length, got %d and %d", len(truth), len(estimate))
+	}
+	var t, e []common.Vector
+	for i := range truth {
+		if estimate[i] == nil || truth[i] == nil {
+			continue
+		}
+		if estimate[i].Dimension() != truth[i].Dimension() {
+			return nil, nil, fmt.Errorf("pose %d: dimension mismatch %d != %d", i, estimate[i].Dimension(), truth[i].Dimension())
+		}
+		if len(t) > 0 && truth[i].Dimension() != t[0].Dimension() {
+			return nil, nil, fmt.Errorf("pose %d: dimension %d differs from %d", i, truth[i].Dimension(), t[0].Dimension())
+		}
+		t, e = append(t, truth[i]), append(e, estimate[i])
+	}
+	return t, e, nil
+}
+
+func centroid(points []common.Vector) common.Vector {
+	c := common.NewVector(points[0].Dimension())
+	for _, p := range points {
+		_ = c.AddInPlace(p) // Dimensions were checked by the caller
+	}
+	c.ScaleInPlace(1 / float64(len(points)))
+	return c
+}
+
+// difference returns a - b for vectors of equal dimension.
+func difference(a, b common.Vector) common.Vector {
+	d, _ := a.Subtract(b) // Same dimension, checked by pairTrajectories
+	return d
+}
+
+// rigidAlignment finds the rotation R (det R = +1) and translation T minimizing
+// sum ||t_i - (R e_i + T)||^2 via the SVD of the cross-covariance (Kabsch).
+func rigidAlignment(truth, estimate []common.Vector) (common.Transform, error) {
+	n := truth[0].Dimension()
+	ct, ce := centroid(truth), centroid(estimate)
+
+	// H = sum (e_i - ce)(t_i - ct)^T
+	h := mat.NewDense(n, n, nil)
+	for i := range truth {
+		for r := 0; r < n; r++ {
+			for c := 0; c < n; c++ {
+				h.Set(r, c, h.At(r, c)+(estimate[i][r]-ce[r])*(truth[i][c]-ct[c]))
+			}
+		}
+	}
+	var svd mat.SVD
+	if !svd.Factorize(h, mat.SVDFull) {
+		return common.Transform{}, fmt.Errorf("rigid alignment: SVD did not converge")
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+
+	// R = V D U^T with D = diag(1, ..., 1, det(V U^T)) to exclude reflections
+	var vut mat.Dense
+	vut.Mul(&v, u.T())
+	d := mat.NewDiagDense(n, nil)
+	for k := 0; k < n; k++ {
+		d.SetDiag(k, 1)
+	}
+	if mat.Det(&vut) < 0 {
+		d.SetDiag(n-1, -1)
+	}
+	var vd, rot mat.Dense
+	vd.Mul(&v, d)
+	rot.Mul(&vd, u.T())
+
+	transform := common.Transform{Rotation: make([]float64, n*n)}
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			transform.Rotation[r*n+c] = rot.At(r, c)
+		}
+	}
+	rotated, err := transform.Apply(ce)
+	if err != nil {
+		return common.Transform{}, err
+	}
+	transform.Translation = difference(ct, rotated)
+	return transform, nil
+}
+
+func summarizeErrors(errs []float64) TrajectoryError {
+	result := TrajectoryError{N: len(errs), Errors: errs}
+	sumSq := 0.0
+	for _, e := range errs {
+		result.Mean += e
+		sumSq += e * e
+		result.Max = math.Max(result.Max, e)
+	}
+	result.Mean /= float64(len(errs))
+	result.RMSE = math.Sqrt(sumSq / float64(len(errs)))
+
+	sorted := append([]float64(nil), errs...)
+	sort.Float64s(sorted)
+	if mid := len(sorted) / 2; len(sorted)%2 == 1 {
+		result.Median = sorted[mid]
+	} else {
+		result.Median = (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package multilateration_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"testing"
+)
+
+// benchmarkDimensions spans the default scenes up to high-dimensional research setups.
+var benchmarkDimensions = []int{2, 3, 10, 25, 50}
+
+// randomMeasurements places 2*(dim+1) sensors in [-100, 100]^dim around a random target.
+func randomMeasurements(rng *rand.Rand, dim int) ([]multilateration.Measurement, common.Vector) {
+	target := randomPoint(rng, dim)
+	measurements := make([]multilateration.Measurement, 2*(dim+1))
+	for i := range measurements {
+		pos := randomPoint(rng, dim)
+		dist, _ := pos.Distance(target)
+		measurements[i] = multilateration.Measurement{SensorPosition: pos, Distance: dist + rng.NormFloat64()*0.5, Variance: 0.25}
+	}
+	return measurements, target
+}
+
+// randomScene is randomMeasurements with a given number of sensors whose range noise
+// sigma is drawn from [0.25, 1], so weighting has something to do.
+func randomScene(rng *rand.Rand, dim, sensors int) ([]multilateration.Measurement, common.Vector) {
+	target := randomPoint(rng, dim)
+	measurements := make([]multilateration.Measurement, sensors)
+	for i := range measurements {
+		pos := randomPoint(rng, dim)
+		dist, _ := pos.Distance(target)
+		sigma := 0.25 + 0.75*rng.Float64()
+		measurements[i] = multilateration.Measurement{SensorPosition: pos, Distance: dist + rng.NormFloat64()*sigma, Variance: sigma * sigma}
+	}
+	return measurements, target
+}
+
+func randomPoint(rng *rand.Rand, dim int) common.Vector {
+	v := common.NewVector(dim)
+	for i := range v {
+		v[i] = rng.Float64()*200 - 100
+	}
+	return v
+}
+
+// The linearized solve is O(m d^2); GDOP and covariance add two d x d inversions, O(d^3).
+func BenchmarkSolveLeastSquares(b *testing.B) {
+	for _, dim := range benchmarkDimensions {
+		b.Run(fmt.Sprintf("dim=%d", dim), func(b *testing.B) {
+			measurements, _ := randomMeasurements(rand.New(rand.NewSource(1)), dim)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := multilateration.SolveLeastSquares(measurements, dim); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSolverSolveInto is BenchmarkSolveLeastSquares on preallocated workspaces.
+func BenchmarkSolverSolveInto(b *testing.B) {
+	for _, dim := range benchmarkDimensions {
+		b.Run(fmt.Sprintf("dim=%d", dim), func(b *testing.B) {
+			measurements, _ := randomMeasurements(rand.New(rand.NewSource(1)), dim)
+			solver, err := multilateration.NewSolver(len(measurements), dim)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var solution multilateration.Solution
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := solver.SolveInto(&solution, measurements); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSolveRobust(b *testing.B) {
+	for _, dim := range benchmarkDimensions {
+		b.Run(fmt.Sprintf("dim=%d", dim), func(b *testing.B) {
+			measurements, _ := randomMeasurements(rand.New(rand.NewSource(1)), dim)
+			opts := multilateration.DefaultRobustOptions()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := multilateration.SolveRobust(measurements, dim, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkComputeDOP(b *testing.B) {
+	for _, dim := range benchmarkDimensions {
+		b.Run(fmt.Sprintf("dim=%d", dim), func(b *testing.B) {
+			measurements, target := randomMeasurements(rand.New(rand.NewSource(1)), dim)
+			sensors := make([]common.Vector, len(measurements))
+			for i, m := range measurements {
+				sensors[i] = m.SensorPosition
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := multilateration.ComputeDOP(sensors, target); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkSolvers are the solvers compared by BenchmarkSolvers, named as in cmd/bench.
+var benchmarkSolvers = []struct {
+	name  string
+	solve multilateration.SolveFunc
+}{
+	{"ls", multilateration.SolveLeastSquares},
+	{"wls", multilateration.SolveWeightedLeastSquares},
+	{"nonlinear", multilateration.RobustSolver(multilateration.RobustOptions{HuberThreshold: math.Inf(1)})},
+	{"robust", multilateration.RobustSolver(multilateration.DefaultRobustOptions())},
+}
+
+// BenchmarkSolvers sweeps every solver over dimensions and sensor counts and reports the
+// mean localization error over the scenes next to ns/op, so a speed-up that costs
+// accuracy shows up in the same run.
+func BenchmarkSolvers(b *testing.B) {
+	const scenes = 64
+	for _, solver := range benchmarkSolvers {
+		for _, dim := range []int{2, 3, 5, 10} {
+			for _, sensors := range []int{4, 10, 30, 100} {
+				if sensors < dim+1 {
+					continue
+				}
+				b.Run(fmt.Sprintf("%s/dim=%d/sensors=%d", solver.name, dim, sensors), func(b *testing.B) {
+					rng := rand.New(rand.NewSource(1))
+					measurements := make([][]multilateration.Measurement, scenes)
+					targets := make([]common.Vector, scenes)
+					for i := range measurements {
+						measurements[i], targets[i] = randomScene(rng, dim, sensors)
+					}
+					b.ReportAllocs()
+					b.ResetTimer()
+					sumErr, solved := 0.0, 0
+					for i := 0; i < b.N; i++ {
+						k := i % scenes
+						solution, err := solver.solve(measurements[k], dim)
+						if err != nil {
+							continue // Random scenes with few sensors can be degenerate
+						}
+						e, _ := solution.Position.Distance(targets[k])
+						sumErr += e
+						solved++
+					}
+					if solved > 0 {
+						b.ReportMetric(sumErr/float64(solved), "err/op")
+					}
+				})
+			}
+		}
+	}
+}
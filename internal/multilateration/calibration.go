@@ -0,0 +1,143 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// ReferenceRange is a range measured by an anchor to a tag placed at a known reference
+// point, the raw material of anchor self-calibration.
+type ReferenceRange struct {
+	Anchor   int // Index into CalibrationProblem.Anchors
+	Point    common.Vector
+	Distance float64
+}
+
+// CalibrationProblem describes the refinement of surveyed anchor positions from ranges
+// to known reference points.
+type CalibrationProblem struct {
+	Anchors     []common.Vector // Surveyed positions, also the initial guess
+	AnchorSigma float64         // Survey error (units), 0 means no prior: ranges alone decide
+	RangeSigma  float64         // Standard deviation of the ranges (units), 0 means 1
+	Ranges      []ReferenceRange
+}
+
+// CalibrationResult holds the refined anchors.
+type CalibrationResult struct {
+	Anchors     []common.Vector
+	Covariances [][][]float64 // Per anchor, nil if it had too few ranges to be refined
+	Residuals   []float64     // RMS range residual per anchor after refinement, 0 without ranges
+}
+
+// CalibrateAnchors refines every anchor independently with Gauss-Newton on its ranges
+// to the reference points, regularized towards its surveyed position by the survey
+// error. Without a prior an anchor needs ranges to at least dimension+1 points in
+// general position; anchors without any range keep their surveyed position.
+func CalibrateAnchors(problem CalibrationProblem, maxIterations int) (CalibrationResult, error) {
+	if len(problem.Anchors) == 0 {
+		return CalibrationResult{}, fmt.Errorf("calibration needs at least one anchor")
+	}
+	if problem.AnchorSigma < 0 || problem.RangeSigma < 0 {
+		return CalibrationResult{}, fmt.Errorf("%w: calibration sigmas must be non-negative", ErrOutOfRange)
+	}
+	if maxIterations <= 0 {
+		maxIterations = 20
+	}
+	rangeSigma := problem.RangeSigma
+	if rangeSigma == 0 {
+		rangeSigma = 1
+	}
+	dim := problem.Anchors[0].Dimension()
+	byAnchor := make([][]ReferenceRange, len(problem.Anchors))
+	for _, r := range problem.Ranges {
+		if r.Anchor < 0 || r.Anchor >= len(problem.Anchors) {
+			return CalibrationResult{}, fmt.Errorf("range references unknown anchor %d", r.Anchor)
+		}
+		if r.Point.Dimension() != dim {
+			return CalibrationResult{}, fmt.Errorf("%w: reference point has dimension %d, expected %d", ErrDimensionMismatch, r.Point.Dimension(), dim)
+		}
+		byAnchor[r.Anchor] = append(byAnchor[r.Anchor], r)
+	}
+
+	result := CalibrationResult{
+		Anchors:     make([]common.Vector, len(problem.Anchors)),
+		Covariances: make([][][]float64, len(problem.Anchors)),
+		Residuals:   make([]float64, len(problem.Anchors)),
+	}
+	for i, surveyed := range problem.Anchors {
+		if surveyed.Dimension() != dim {
+			return CalibrationResult{}, fmt.Errorf("%w: anchor %d has dimension %d, expected %d", ErrDimensionMismatch, i, surveyed.Dimension(), dim)
+		}
+		ranges := byAnchor[i]
+		if len(ranges) == 0 || (problem.AnchorSigma == 0 && len(ranges) <= dim) {
+			result.Anchors[i] = surveyed.Clone()
+			continue
+		}
+		anchor, cov, rms, err := calibrateAnchor(surveyed, ranges, problem.AnchorSigma, rangeSigma, maxIterations)
+		if err != nil {
+			return CalibrationResult{}, fmt.Errorf("anchor %d: %w", i, err)
+		}
+		result.Anchors[i], result.Covariances[i], result.Residuals[i] = anchor, cov, rms
+	}
+	return result, nil
+}
+
+// calibrateAnchor minimizes sum((d - |a - p|)/rangeSigma)^2 + |a - surveyed|^2/anchorSigma^2
+// and returns the anchor, its covariance and the RMS range residual.
+func calibrateAnchor(surveyed common.Vector, ranges []ReferenceRange, anchorSigma, rangeSigma float64, maxIterations int) (common.Vector, [][]float64, float64, error) {
+	dim := surveyed.Dimension()
+	a := surveyed.Clone()
+	var normal []float64
+	for iter := 0; iter < maxIterations; iter++ {
+		jData := make([]float64, 0, len(ranges)*dim)
+		residuals := make([]float64, 0, len(ranges))
+		for _, r := range ranges {
+			diff, _ := a.Subtract(r.Point)
+			dist := diff.Norm()
+			for j := 0; j < dim; j++ {
+				g := 0.0
+				if dist > 0 {
+					g = diff[j] / dist
+				}
+				jData = append(jData, g/rangeSigma)
+			}
+			residuals = append(residuals, (r.Distance-dist)/rangeSigma)
+		}
+		var rhs []float64
+		normal, rhs = normalEquations(jData, residuals, len(ranges), dim)
+		if anchorSigma > 0 { // Prior factor pulling towards the survey
+			w := 1 / (anchorSigma * anchorSigma)
+			for j := 0; j < dim; j++ {
+				normal[j*dim+j] += w
+				rhs[j] += w * (surveyed[j] - a[j])
+			}
+		}
+		step, err := solveSquare(normal, rhs, dim)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("reference points do not constrain the anchor: %w", err)
+		}
+		stepNorm := 0.0
+		for j := range a {
+			a[j] += step[j]
+			stepNorm += step[j] * step[j]
+		}
+		if math.Sqrt(stepNorm) < 1e-9 {
+			break
+		}
+	}
+
+	sumSq := 0.0
+	for _, r := range ranges {
+		dist, _ := a.Distance(r.Point)
+		sumSq += (r.Distance - dist) * (r.Distance - dist)
+	}
+	var cov [][]float64
+	if inv, err := invertSquare(normal, dim); err == nil {
+		cov = make([][]float64, dim)
+		for i := range cov {
+			cov[i] = append([]float64(nil), inv[i*dim:(i+1)*dim]...)
+		}
+	}
+	return a, cov, math.Sqrt(sumSq / float64(len(ranges))), nil
+}
@@ -0,0 +1,278 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// PeerMeasurement is a range measured between two nodes of a cooperative problem.
+type PeerMeasurement struct {
+	A, B     int // Indices into CooperativeProblem.Anchors
+	Distance float64
+}
+
+// CooperativeProblem describes the joint localization of several nodes (targets) from
+// ranges to anchors with known positions and ranges between the nodes themselves.
+type CooperativeProblem struct {
+	Dimension  int
+	Anchors    [][]Measurement // Anchor ranges of each node, may be empty
+	Peers      []PeerMeasurement
+	Initial    []common.Vector // Initial guesses per node; nil or missing entries are derived from the anchors
+	RangeSigma float64         // Standard deviation of the anchor ranges
+	PeerSigma  float64         // Standard deviation of the peer ranges
+}
+
+// CooperativeResult holds the jointly estimated node positions.
+type CooperativeResult struct {
+	Positions  []common.Vector
+	Localized  []bool // Whether the node is constrained well enough to trust its position
+	Iterations int
+	Cost       float64 // Final sum of squared normalized residuals
+}
+
+// SolveCooperative jointly estimates all node positions with damped Gauss-Newton
+// (Levenberg-Marquardt) on anchor-to-node and node-to-node range factors. A node with
+// fewer than Dimension+1 anchors can still be localized through its peers. A node counts
+// as localized when it has at least Dimension+1 ranges in total and is linked by peer
+// ranges to a node with anchors; the others are reported but not trustworthy.
+func SolveCooperative(problem CooperativeProblem, maxIterations int) (CooperativeResult, error) {
+	dim, numNodes := problem.Dimension, len(problem.Anchors)
+	if dim < 1 {
+		return CooperativeResult{}, fmt.Errorf("%w: dimension must be positive, got %d", ErrOutOfRange, dim)
+	}
+	if numNodes == 0 {
+		return CooperativeResult{}, fmt.Errorf("%w: cooperative localization needs at least one node", ErrInsufficientMeasurements)
+	}
+	if problem.RangeSigma <= 0 || problem.PeerSigma <= 0 {
+		return CooperativeResult{}, fmt.Errorf("%w: cooperative sigmas must be positive", ErrOutOfRange)
+	}
+	for _, p := range problem.Peers {
+		if p.A < 0 || p.A >= numNodes || p.B < 0 || p.B >= numNodes || p.A == p.B {
+			return CooperativeResult{}, fmt.Errorf("peer measurement references invalid nodes %d and %d", p.A, p.B)
+		}
+	}
+	for i, anchors := range problem.Anchors {
+		for _, m := range anchors {
+			if m.SensorPosition.Dimension() != dim {
+				return CooperativeResult{}, fmt.Errorf("%w: anchor of node %d has dimension %d, expected %d", ErrDimensionMismatch, i, m.SensorPosition.Dimension(), dim)
+			}
+		}
+	}
+
+	state, err := cooperativeInitial(problem)
+	if err != nil {
+		return CooperativeResult{}, err
+	}
+	numVars := numNodes * dim
+	cost := cooperativeCost(problem, state)
+	lambda := 1e-3
+	iter, converged := 0, false
+	for ; iter < maxIterations && !converged; iter++ {
+		hessian := make([]float64, numVars*numVars)
+		gradient := make([]float64, numVars)
+		diff := make([]float64, dim)
+
+		// Anchor factors: r = (d - |s - p|) / sigma, dr/dp = (s - p)/|s - p|/sigma
+		for node, anchors := range problem.Anchors {
+			p := state[node*dim : (node+1)*dim]
+			for _, m := range anchors {
+				norm := 0.0
+				for k := range dim {
+					diff[k] = m.SensorPosition[k] - p[k]
+					norm += diff[k] * diff[k]
+				}
+				if norm = math.Sqrt(norm); norm == 0 {
+					continue // Direction undefined, skip this factor for the iteration
+				}
+				r := (m.Distance - norm) / problem.RangeSigma
+				for a := range dim {
+					ga := diff[a] / norm / problem.RangeSigma
+					gradient[node*dim+a] += ga * r
+					for b := range dim {
+						hessian[(node*dim+a)*numVars+node*dim+b] += ga * diff[b] / norm / problem.RangeSigma
+					}
+				}
+			}
+		}
+		// Peer factors: r = (d - |pa - pb|) / sigma, dr/dpa = -(pa - pb)/|pa - pb|/sigma
+		for _, peer := range problem.Peers {
+			norm := 0.0
+			for k := range dim {
+				diff[k] = state[peer.A*dim+k] - state[peer.B*dim+k]
+				norm += diff[k] * diff[k]
+			}
+			if norm = math.Sqrt(norm); norm == 0 {
+				continue
+			}
+			r := (peer.Distance - norm) / problem.PeerSigma
+			idx := make([]int, 0, 2*dim)
+			jac := make([]float64, 0, 2*dim)
+			for k := range dim {
+				g := diff[k] / norm / problem.PeerSigma
+				idx = append(idx, peer.A*dim+k, peer.B*dim+k)
+				jac = append(jac, -g, g)
+			}
+			for a, i := range idx {
+				gradient[i] += jac[a] * r
+				for b, j := range idx {
+					hessian[i*numVars+j] += jac[a] * jac[b]
+				}
+			}
+		}
+
+		// Damped step: (H + lambda*diag(H)) delta = -g
+		accepted := false
+		for attempt := 0; attempt < 10 && !accepted; attempt++ {
+			damped := make([]float64, len(hessian))
+			copy(damped, hessian)
+			for i := range numVars {
+				damped[i*numVars+i] += lambda*hessian[i*numVars+i] + 1e-12
+			}
+			negGrad := make([]float64, numVars)
+			for i, g := range gradient {
+				negGrad[i] = -g
+			}
+			delta, err := solveSquare(damped, negGrad, numVars)
+			if err != nil {
+				lambda *= 10
+				continue
+			}
+			candidate := make([]float64, numVars)
+			for i := range state {
+				candidate[i] = state[i] + delta[i]
+			}
+			if newCost := cooperativeCost(problem, candidate); newCost <= cost {
+				stepNorm := 0.0
+				for _, d := range delta {
+					stepNorm += d * d
+				}
+				state, cost = candidate, newCost
+				lambda = math.Max(lambda/10, 1e-9)
+				accepted = true
+				converged = math.Sqrt(stepNorm) < 1e-9
+			} else {
+				lambda *= 10
+			}
+		}
+		if !accepted {
+			break // No descent direction found, we are at a (local) minimum
+		}
+	}
+
+	result := CooperativeResult{Iterations: iter, Cost: cost, Localized: cooperativeLocalized(problem)}
+	for i := range numNodes {
+		result.Positions = append(result.Positions, common.Vector(state[i*dim:(i+1)*dim]).Clone())
+	}
+	return result, nil
+}
+
+// cooperativeInitial builds the initial state: the given guesses, else the least squares
+// fix of nodes with enough anchors, else the centroid of the anchors (or of all anchors
+// when the node has none) shifted per node, so peers never start on top of each other.
+func cooperativeInitial(problem CooperativeProblem) ([]float64, error) {
+	dim := problem.Dimension
+	var all []common.Vector
+	for _, anchors := range problem.Anchors {
+		for _, m := range anchors {
+			all = append(all, m.SensorPosition)
+		}
+	}
+	state := make([]float64, 0, len(problem.Anchors)*dim)
+	for node, anchors := range problem.Anchors {
+		if node < len(problem.Initial) && problem.Initial[node] != nil {
+			if problem.Initial[node].Dimension() != dim {
+				return nil, fmt.Errorf("%w: initial guess of node %d has dimension %d, expected %d", ErrDimensionMismatch, node, problem.Initial[node].Dimension(), dim)
+			}
+			state = append(state, problem.Initial[node]...)
+			continue
+		}
+		if sol, err := SolveLeastSquares(anchors, dim); err == nil {
+			state = append(state, sol.Position...)
+			continue
+		}
+		points := all
+		if len(anchors) > 0 {
+			points = points[:0:0]
+			for _, m := range anchors {
+				points = append(points, m.SensorPosition)
+			}
+		}
+		guess := common.NewVector(dim)
+		for _, p := range points {
+			guess.AddInPlace(p)
+		}
+		if len(points) > 0 {
+			guess.ScaleInPlace(1 / float64(len(points)))
+		}
+		guess[node%dim] += float64(node + 1)
+		state = append(state, guess...)
+	}
+	return state, nil
+}
+
+// cooperativeLocalized reports which nodes have at least Dimension+1 ranges and are
+// linked through peer ranges to a node with anchors.
+func cooperativeLocalized(problem CooperativeProblem) []bool {
+	numNodes := len(problem.Anchors)
+	counts := make([]int, numNodes)
+	neighbours := make([][]int, numNodes)
+	for _, p := range problem.Peers {
+		counts[p.A]++
+		counts[p.B]++
+		neighbours[p.A] = append(neighbours[p.A], p.B)
+		neighbours[p.B] = append(neighbours[p.B], p.A)
+	}
+	// Breadth-first search from every node with anchors
+	anchored := make([]bool, numNodes)
+	var queue []int
+	for i, anchors := range problem.Anchors {
+		counts[i] += len(anchors)
+		if len(anchors) > 0 {
+			anchored[i] = true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, n := range neighbours[node] {
+			if !anchored[n] {
+				anchored[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	localized := make([]bool, numNodes)
+	for i := range localized {
+		localized[i] = anchored[i] && counts[i] >= problem.Dimension+1
+	}
+	return localized
+}
+
+// cooperativeCost returns the sum of squared normalized residuals of all factors.
+func cooperativeCost(problem CooperativeProblem, state []float64) float64 {
+	dim := problem.Dimension
+	cost := 0.0
+	for node, anchors := range problem.Anchors {
+		for _, m := range anchors {
+			norm := 0.0
+			for k := range dim {
+				d := m.SensorPosition[k] - state[node*dim+k]
+				norm += d * d
+			}
+			r := (m.Distance - math.Sqrt(norm)) / problem.RangeSigma
+			cost += r * r
+		}
+	}
+	for _, peer := range problem.Peers {
+		norm := 0.0
+		for k := range dim {
+			d := state[peer.A*dim+k] - state[peer.B*dim+k]
+			norm += d * d
+		}
+		r := (peer.Distance - math.Sqrt(norm)) / problem.PeerSigma
+		cost += r * r
+	}
+	return cost
+}
@@ -0,0 +1,50 @@
+package multilateration
+
+import (
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// estimateCovariance returns the position covariance s^2 (H^T W H)^-1, where H holds the
+// range Jacobian of the metric at x (unit line-of-sight rows in Euclidean space), W the
+// measurement weights (nil = all 1) and s^2 the weighted residual variance of the
+// ranges with m - n degrees of freedom.
+// Returns nil for degenerate geometry or when there is no redundancy (m <= n).
+func estimateCovariance(measurements []Measurement, x common.Vector, metric common.Metric, weights []float64) [][]float64 {
+	dimension, m := x.Dimension(), len(measurements)
+	if m <= dimension {
+		return nil
+	}
+	residuals := make([]float64, m)
+	jData := make([]float64, m*dimension)
+	if err := rangeJacobian(measurements, x, metric, residuals, jData); err != nil {
+		return nil
+	}
+	sumWR := 0.0
+	for i := range residuals {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		sumWR += w * residuals[i] * residuals[i]
+		sw := math.Sqrt(w)
+		for j := 0; j < dimension; j++ {
+			jData[i*dimension+j] *= sw
+		}
+	}
+	variance := sumWR / float64(m-dimension)
+
+	normal, _ := normalEquations(jData, make([]float64, m), m, dimension) // H^T W H
+	inv, err := invertSquare(normal, dimension)
+	if err != nil {
+		return nil
+	}
+	cov := make([][]float64, dimension)
+	for i := range cov {
+		cov[i] = make([]float64, dimension)
+		for j := range cov[i] {
+			cov[i][j] = variance * inv[i*dimension+j]
+		}
+	}
+	return cov
+}
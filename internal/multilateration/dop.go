@@ -0,0 +1,93 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// DOP holds the dilution of precision values for a given sensor geometry.
+// For range-only multilateration there is no clock term, so GDOP equals PDOP.
+type DOP struct {
+	GDOP float64 // sqrt(trace(Q)) over all dimensions
+	HDOP float64 // Horizontal: first two axes (first axis only in 1D)
+	VDOP float64 // Vertical: third axis, 0 if dimension < 3
+}
+
+// ComputeDOP calculates the dilution of precision for a position estimate
+// given the positions of the sensors that were used to obtain it.
+// The geometry matrix H has one row per sensor: the unit line-of-sight vector
+// from the sensor to the position. Q = (H^T H)^-1.
+func ComputeDOP(sensorPositions []common.Vector, position common.Vector) (DOP, error) {
+	dimension := position.Dimension()
+	if len(sensorPositions) < dimension {
+		return DOP{}, fmt.Errorf("%w for DOP: got %d, need at least %d", ErrInsufficientMeasurements, len(sensorPositions), dimension)
+	}
+
+	hData := make([]float64, 0, len(sensorPositions)*dimension)
+	for _, sensorPos := range sensorPositions {
+		diff, err := position.Subtract(sensorPos)
+		if err != nil {
+			return DOP{}, fmt.Errorf("dimension mismatch calculating DOP: %w", err)
+		}
+		if diff.Norm() == 0 {
+			return DOP{}, fmt.Errorf("position coincides with a sensor, line of sight undefined")
+		}
+		hData = append(hData, diff.Normalize()...)
+	}
+	return geometryDOP(hData, len(sensorPositions), dimension)
+}
+
+// metricDOP is ComputeDOP with the geometry matrix built from the range gradients of
+// the metric, which are the unit line-of-sight vectors only in Euclidean space.
+func metricDOP(measurements []Measurement, position common.Vector, metric common.Metric) (DOP, error) {
+	sensorPositions := make([]common.Vector, len(measurements))
+	for i, m := range measurements {
+		sensorPositions[i] = m.SensorPosition
+	}
+	if _, euclidean := metric.(common.Euclidean); euclidean {
+		return ComputeDOP(sensorPositions, position)
+	}
+	dimension := position.Dimension()
+	if len(sensorPositions) < dimension {
+		return DOP{}, fmt.Errorf("%w for DOP: got %d, need at least %d", ErrInsufficientMeasurements, len(sensorPositions), dimension)
+	}
+	hData := make([]float64, 0, len(sensorPositions)*dimension)
+	for _, sensorPos := range sensorPositions {
+		gradient, err := metric.Gradient(position, sensorPos)
+		if err != nil {
+			return DOP{}, fmt.Errorf("dimension mismatch calculating DOP: %w", err)
+		}
+		hData = append(hData, gradient...)
+	}
+	return geometryDOP(hData, len(sensorPositions), dimension)
+}
+
+// geometryDOP computes the DOP values from the geometry matrix H (row-major).
+func geometryDOP(hData []float64, rows, dimension int) (DOP, error) {
+	normal, _ := normalEquations(hData, make([]float64, rows), rows, dimension) // H^T H
+	q, err := invertSquare(normal, dimension)
+	if err != nil {
+		return DOP{}, fmt.Errorf("geometry matrix is singular: %w", err)
+	}
+	return cofactorDOP(q, dimension), nil
+}
+
+// cofactorDOP computes the DOP values from the cofactor matrix Q = (H^T H)^-1.
+func cofactorDOP(q []float64, dimension int) DOP {
+	trace := 0.0
+	for i := 0; i < dimension; i++ {
+		trace += q[i*dimension+i]
+	}
+	dop := DOP{GDOP: math.Sqrt(trace)}
+	switch {
+	case dimension >= 2:
+		dop.HDOP = math.Sqrt(q[0] + q[dimension+1])
+	case dimension == 1:
+		dop.HDOP = math.Sqrt(q[0])
+	}
+	if dimension >= 3 {
+		dop.VDOP = math.Sqrt(q[2*dimension+2])
+	}
+	return dop
+}
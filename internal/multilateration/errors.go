@@ -0,0 +1,18 @@
+package multilateration
+
+import (
+	"errors"
+	"multilateration-sim/internal/common"
+)
+
+// Errors returned (wrapped) by the solvers; check for them with errors.Is.
+var (
+	ErrDimensionMismatch        = common.ErrDimensionMismatch // A measurement or vector of another dimension
+	ErrOutOfRange               = common.ErrOutOfRange        // A parameter outside its valid range
+	ErrInsufficientMeasurements = errors.New("insufficient measurements")
+
+	// ErrDegenerateGeometry is returned when the sensors do not determine a unique
+	// position, e.g. all of them lie on a line in 2D or on a plane in 3D, or two of only
+	// dimension+1 sensors coincide.
+	ErrDegenerateGeometry = errors.New("degenerate sensor geometry")
+)
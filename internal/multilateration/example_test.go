@@ -0,0 +1,374 @@
+package multilateration_test
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"testing"
+)
+
+// Solve for a position directly from raw sensor positions and measured ranges.
+func ExampleSolveLeastSquares() {
+	target := common.Vector{3, 4}
+	sensors := []common.Vector{{0, 0}, {10, 0}, {0, 10}, {10, 10}}
+
+	measurements := make([]multilateration.Measurement, 0, len(sensors))
+	for _, pos := range sensors {
+		dist, _ := pos.Distance(target)
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: dist})
+	}
+
+	solution, err := multilateration.SolveLeastSquares(measurements, 2)
+	if err != nil {
+		fmt.Println("solve failed:", err)
+		return
+	}
+	locErr, _ := multilateration.CalculateLocalizationError(target, solution.Position)
+	fmt.Println("Position:", solution.Position)
+	fmt.Printf("Error: %.3f, GDOP: %.2f\n", locErr, solution.GDOP)
+	// Output:
+	// Position: [3.000, 4.000]
+	// Error: 0.000, GDOP: 1.00
+}
+
+// The unit of a format applies to the position only: the residual is in squared units.
+func ExampleSolution_FormatWith() {
+	solution := multilateration.Solution{Position: common.Vector{3, 4}, ResidualError: 0.25, GDOP: 1}
+	fmt.Println(solution.FormatWith(common.Format{Precision: 2, Unit: "m"}))
+	// Output:
+	// [3.00, 4.00] m (Resid: 0.25, GDOP: 1.00)
+}
+
+// On a line two sensors are enough, wherever the target is relative to them.
+func ExampleSolveLeastSquares_line() {
+	sensors := []common.Vector{{-50}, {50}}
+	for _, target := range []common.Vector{{10}, {80}} {
+		measurements := make([]multilateration.Measurement, 0, len(sensors))
+		for _, pos := range sensors {
+			dist, _ := pos.Distance(target)
+			measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: dist})
+		}
+		solution, err := multilateration.SolveLeastSquares(measurements, 1)
+		if err != nil {
+			fmt.Println("solve failed:", err)
+			return
+		}
+		fmt.Printf("%v GDOP: %.2f\n", solution.Position, solution.GDOP)
+	}
+	// Output:
+	// [10.000] GDOP: 0.71
+	// [80.000] GDOP: 0.71
+}
+
+// Judge the sensor geometry before trusting an estimate.
+func ExampleComputeDOP() {
+	sensors := []common.Vector{{-10, 0}, {10, 0}, {0, 10}}
+	dop, err := multilateration.ComputeDOP(sensors, common.Vector{0, 0})
+	if err != nil {
+		fmt.Println("degenerate geometry:", err)
+		return
+	}
+	fmt.Printf("GDOP: %.3f, HDOP: %.3f\n", dop.GDOP, dop.HDOP)
+	// Output:
+	// GDOP: 1.225, HDOP: 1.225
+}
+
+// Anchors known only to a few units are refined together with the poses that ranged
+// them. The priors fix the frame, so the shared part of their errors remains.
+func ExampleSolveRangeOnlySLAM() {
+	anchors := []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}}
+	offsets := []common.Vector{{3, -2}, {-2, 3}, {2, 2}, {-3, -2}}
+	problem := multilateration.SLAMProblem{AnchorSigma: 3, RangeSigma: 0.1}
+	for i, a := range anchors {
+		prior, _ := a.Add(offsets[i])
+		problem.AnchorPriors = append(problem.AnchorPriors, prior)
+	}
+	for x := 20.0; x <= 80; x += 30 {
+		for y := 20.0; y <= 80; y += 30 {
+			pose := common.Vector{x, y}
+			for a, anchor := range anchors {
+				dist, _ := anchor.Distance(pose)
+				problem.Observations = append(problem.Observations,
+					multilateration.RangeObservation{Anchor: a, Pose: len(problem.InitialPoses), Distance: dist})
+			}
+			problem.InitialPoses = append(problem.InitialPoses, common.Vector{x + 2, y - 1})
+		}
+	}
+	problem.InitialPoses[0] = problem.AnchorPriors[0].Clone() // On top of an anchor: its range is skipped at first
+
+	anchorRMS := func(estimates []common.Vector) float64 {
+		sum := 0.0
+		for i, e := range estimates {
+			d, _ := e.Distance(anchors[i])
+			sum += d * d
+		}
+		return math.Sqrt(sum / float64(len(estimates)))
+	}
+	result, err := multilateration.SolveRangeOnlySLAM(problem, 50)
+	if err != nil {
+		fmt.Println("solve failed:", err)
+		return
+	}
+	fmt.Printf("anchor error: prior %.2f, refined %.2f\n", anchorRMS(problem.AnchorPriors), anchorRMS(result.Anchors))
+	fmt.Printf("first pose %.2f\n", result.Poses[0])
+
+	bad := problem
+	bad.InitialPoses = append([]common.Vector{{1, 2, 3}}, problem.InitialPoses[1:]...)
+	_, err = multilateration.SolveRangeOnlySLAM(bad, 50)
+	fmt.Println(err)
+	bad = problem
+	bad.Observations = append([]multilateration.RangeObservation{{Anchor: 4, Pose: 0, Distance: 1}}, problem.Observations...)
+	_, err = multilateration.SolveRangeOnlySLAM(bad, 50)
+	fmt.Println(err)
+	// Output:
+	// anchor error: prior 3.43, refined 0.60
+	// first pose [20.23 20.02]
+	// dimension mismatch: pose dimension 3, anchor dimension 2
+	// observation references unknown anchor 4 or pose 0
+}
+
+// The covariance is the residual variance of the ranges times the cofactor matrix of the
+// geometry, so its trace is that variance times GDOP squared. Six sensors on a hexagon,
+// every range 1 unit long, leave the estimate in the middle with residuals of 1:
+// variance 6/(6-2) = 1.5, cofactor I/3.
+func ExampleSolution_covariance() {
+	var sensors []common.Vector
+	var measurements []multilateration.Measurement
+	for k := range 6 {
+		sin, cos := math.Sincos(float64(k) * math.Pi / 3)
+		pos := common.Vector{10 * cos, 10 * sin}
+		sensors = append(sensors, pos)
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: 11})
+	}
+	solution, err := multilateration.SolveLeastSquares(measurements, 2)
+	if err != nil {
+		fmt.Println("solve failed:", err)
+		return
+	}
+	dop, _ := multilateration.ComputeDOP(sensors, solution.Position)
+	cov := solution.Covariance
+	fmt.Printf("covariance diagonal %.3f %.3f, uncorrelated: %v\n", cov[0][0], cov[1][1], math.Abs(cov[0][1]) < 1e-9)
+	fmt.Printf("GDOP %.3f, sqrt(trace / variance) %.3f\n", dop.GDOP, math.Sqrt((cov[0][0]+cov[1][1])/1.5))
+	// Output:
+	// covariance diagonal 0.500 0.500, uncorrelated: true
+	// GDOP 0.816, sqrt(trace / variance) 0.816
+}
+
+// Reject a non-line-of-sight range with the robust solver.
+func ExampleSolveRobust() {
+	target := common.Vector{20, 30}
+	sensors := []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}, {50, -20}, {-20, 50}}
+
+	measurements := make([]multilateration.Measurement, 0, len(sensors))
+	for i, pos := range sensors {
+		dist, _ := pos.Distance(target)
+		if i == 1 {
+			dist += 40 // NLOS: the signal took a detour
+		}
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: dist})
+	}
+
+	plain, _ := multilateration.SolveLeastSquares(measurements, 2)
+	robust, _ := multilateration.SolveRobust(measurements, 2, multilateration.DefaultRobustOptions())
+	plainErr, _ := multilateration.CalculateLocalizationError(target, plain.Position)
+	robustErr, _ := multilateration.CalculateLocalizationError(target, robust.Position)
+	fmt.Printf("least squares error > 5: %t\n", plainErr > 5)
+	fmt.Printf("robust error < 1: %t, outlier weight < 0.1: %t\n", robustErr < 1, robust.Weights[1] < 0.1)
+	// Output:
+	// least squares error > 5: true
+	// robust error < 1: true, outlier weight < 0.1: true
+}
+
+// Recover the target velocity from Doppler range-rates alongside the position.
+func ExampleEstimateVelocity() {
+	target, velocity := common.Vector{30, 40}, common.Vector{3, -4}
+	sensors := []common.Vector{{0, 0}, {100, 0}, {0, 100}}
+
+	measurements := make([]multilateration.Measurement, 0, len(sensors))
+	for _, pos := range sensors {
+		dist, _ := pos.Distance(target)
+		los, _ := target.Subtract(pos)
+		rate := (los[0]*velocity[0] + los[1]*velocity[1]) / dist
+		measurements = append(measurements, multilateration.Measurement{
+			SensorPosition: pos, Distance: dist, RangeRate: rate, HasRangeRate: true,
+		})
+	}
+
+	solution, _ := multilateration.SolveLeastSquares(measurements, 2)
+	fmt.Println(solution.Position, solution.Velocity)
+	// Output:
+	// [30.000, 40.000] [3.000, -4.000]
+}
+
+// Trust a precise sensor more than a noisy one.
+func ExampleSolveWeightedLeastSquares() {
+	target := common.Vector{40, 60}
+	sensors := []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}}
+	variances := []float64{0.01, 0.01, 0.01, 100}
+
+	measurements := make([]multilateration.Measurement, 0, len(sensors))
+	for i, pos := range sensors {
+		dist, _ := pos.Distance(target)
+		if i == 3 {
+			dist += 15 // The noisy sensor is far off
+		}
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: dist, Variance: variances[i]})
+	}
+
+	plain, _ := multilateration.SolveLeastSquares(measurements, 2)
+	weighted, _ := multilateration.SolveWeightedLeastSquares(measurements, 2)
+	plainErr, _ := multilateration.CalculateLocalizationError(target, plain.Position)
+	weightedErr, _ := multilateration.CalculateLocalizationError(target, weighted.Position)
+	fmt.Printf("weighted is better: %t, weighted error < 0.5: %t\n", weightedErr < plainErr, weightedErr < 0.5)
+	// Output:
+	// weighted is better: true, weighted error < 0.5: true
+}
+
+// Locate a receiver from ranges along the Earth's surface to three beacons given by
+// latitude and longitude; the linear least-squares solver cannot use such ranges.
+func ExampleSolveRobust_greatCircle() {
+	metric := common.GreatCircle{Radius: common.EarthRadius}
+	receiver := common.Vector{55.75, 37.62}
+	beacons := []common.Vector{{55.70, 37.50}, {55.80, 37.55}, {55.72, 37.75}}
+
+	measurements := make([]multilateration.Measurement, 0, len(beacons))
+	for _, pos := range beacons {
+		meters, _ := metric.Distance(receiver, pos)
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: meters})
+	}
+	opts := multilateration.DefaultRobustOptions()
+	opts.Metric = metric
+	solution, err := multilateration.SolveRobust(measurements, 2, opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("lat %.4f, lon %.4f\n", solution.Position[0], solution.Position[1])
+	// Output:
+	// lat 55.7500, lon 37.6200
+}
+
+// Time-of-arrival ranges from a transmitter whose clock runs 100 ns ahead: all of them
+// are about 30 m too short. Least squares is pulled off; SolvePseudoranges estimates the
+// bias as a fourth unknown.
+func ExampleSolvePseudoranges() {
+	const speedOfLight = 299792458.0
+	target := common.Vector{30, 40}
+	bias := -speedOfLight * 100e-9
+	var measurements []multilateration.Measurement
+	for _, pos := range []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}} {
+		d, _ := target.Distance(pos)
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: d + bias})
+	}
+
+	ls, _ := multilateration.SolveLeastSquares(measurements, 2)
+	fmt.Println("least squares:", ls.Position)
+	toa, _ := multilateration.SolvePseudoranges(measurements, 2)
+	fmt.Printf("pseudoranges: %v, bias %.2f m\n", toa.Position, toa.ClockBias)
+	// Output:
+	// least squares: [38.079, 44.020]
+	// pseudoranges: [30.000, 40.000], bias -29.98 m
+}
+
+// An anchor surveyed 3.6 m off its true position at {50, 50}: ranges from a tag placed
+// at four known reference points pull it back.
+func ExampleCalibrateAnchors() {
+	anchor := common.Vector{50, 50}
+	problem := multilateration.CalibrationProblem{
+		Anchors:     []common.Vector{{52, 47}},
+		AnchorSigma: 5,
+		RangeSigma:  0.1,
+	}
+	for _, p := range []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}} {
+		d, _ := anchor.Distance(p)
+		problem.Ranges = append(problem.Ranges, multilateration.ReferenceRange{Anchor: 0, Point: p, Distance: d})
+	}
+	result, _ := multilateration.CalibrateAnchors(problem, 0)
+	fmt.Printf("refined: %v, residual %.3f\n", result.Anchors[0], result.Residuals[0])
+	// Output:
+	// refined: [50.000, 49.999], residual 0.001
+}
+
+// A Solver reuses its workspaces and the destination solution, so a tick that solves
+// for many targets does not feed the garbage collector.
+func ExampleSolver_SolveInto() {
+	target := common.Vector{30, 40}
+	var measurements []multilateration.Measurement
+	for _, pos := range []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}, {50, 0}} {
+		d, _ := target.Distance(pos)
+		measurements = append(measurements, multilateration.Measurement{SensorPosition: pos, Distance: d + 0.1})
+	}
+
+	solver, _ := multilateration.NewSolver(16, 2)
+	var solution multilateration.Solution
+	_ = solver.SolveInto(&solution, measurements)
+	reference, _ := multilateration.SolveLeastSquares(measurements, 2)
+	fmt.Println(solution)
+	fmt.Println(reference)
+	allocs := testing.AllocsPerRun(100, func() { _ = solver.SolveInto(&solution, measurements) })
+	fmt.Println("allocations per solve:", allocs)
+	// Output:
+	// [29.972, 39.965] (Resid: 2.925, GDOP: 0.91)
+	// [29.972, 39.965] (Resid: 2.925, GDOP: 0.91)
+	// allocations per solve: 0
+}
+
+// Two nodes that each hear only two anchors are placed by the range between them.
+func ExampleSolveCooperative() {
+	anchors := []common.Vector{{0, 0}, {10, 0}, {0, 10}}
+	nodes := []common.Vector{{2, 3}, {8, 6}}
+	heard := [][]int{{0, 1}, {1, 2}} // Anchor indices each node hears
+
+	problem := multilateration.CooperativeProblem{Dimension: 2, RangeSigma: 0.1, PeerSigma: 0.1,
+		Initial: []common.Vector{{3, 3}, {7, 7}}}
+	for n, node := range nodes {
+		var ms []multilateration.Measurement
+		for _, a := range heard[n] {
+			dist, _ := anchors[a].Distance(node)
+			ms = append(ms, multilateration.Measurement{SensorPosition: anchors[a], Distance: dist})
+		}
+		problem.Anchors = append(problem.Anchors, ms)
+	}
+	peer, _ := nodes[0].Distance(nodes[1])
+	problem.Peers = []multilateration.PeerMeasurement{{A: 0, B: 1, Distance: peer}}
+
+	result, err := multilateration.SolveCooperative(problem, 50)
+	if err != nil {
+		fmt.Println("solve failed:", err)
+		return
+	}
+	for n, pos := range result.Positions {
+		fmt.Println(pos, result.Localized[n])
+	}
+	// Output:
+	// [2.000, 3.000] true
+	// [8.000, 6.000] true
+}
+
+// Nodes that only know the ranges to their neighbours reconstruct their layout. The
+// relative map comes out rotated or mirrored; Procrustes alignment with the true
+// positions shows how well its shape matches.
+func ExampleSolveMDSMAP() {
+	truth := []common.Vector{{0, 0}, {6, 0}, {12, 1}, {1, 6}, {7, 5}, {12, 7}, {0, 12}, {6, 11}, {12, 12}}
+	problem := multilateration.RelativeProblem{Dimension: 2, Nodes: len(truth)}
+	for a := range truth {
+		for b := a + 1; b < len(truth); b++ {
+			if d, _ := truth[a].Distance(truth[b]); d < 9 { // Only neighbours range
+				problem.Ranges = append(problem.Ranges, multilateration.PeerMeasurement{A: a, B: b, Distance: d})
+			}
+		}
+	}
+	for _, iterations := range []int{0, 100} {
+		result, err := multilateration.SolveMDSMAP(problem, iterations)
+		if err != nil {
+			fmt.Println("solve failed:", err)
+			return
+		}
+		_, rmse, _ := multilateration.AlignProcrustes(result.Positions, truth, false)
+		fmt.Printf("SMACOF iterations %d: stress %.3f, aligned RMSE %.3f\n", result.Iterations, result.Stress, rmse)
+	}
+	// Output:
+	// SMACOF iterations 0: stress 0.105, aligned RMSE 0.674
+	// SMACOF iterations 100: stress 0.000, aligned RMSE 0.000
+}
@@ -0,0 +1,252 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Small dense linear algebra helpers that do not depend on gonum.
+// Matrices are row-major []float64. They are intended for n x n systems with
+// n around the simulation dimension, where the overhead of gonum is not justified.
+
+// singularTolerance is the pivot magnitude below which a matrix is treated as singular.
+const singularTolerance = 1e-12
+
+// solveSquare solves a * x = b for a square n x n matrix using Gaussian
+// elimination with partial pivoting. a and b are not modified.
+func solveSquare(a, b []float64, n int) ([]float64, error) {
+	x := make([]float64, n)
+	if err := solveSquareInto(a, b, n, make([]float64, n*n), x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// solveSquareInto is solveSquare writing the solution to x, with m (n*n) as scratch.
+func solveSquareInto(a, b []float64, n int, m, x []float64) error {
+	copy(m, a)
+	copy(x, b)
+
+	for col := 0; col < n; col++ {
+		// Find the pivot row
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row*n+col]) > math.Abs(m[pivot*n+col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot*n+col]) < singularTolerance {
+			return fmt.Errorf("matrix is singular or near-singular (pivot %d)", col)
+		}
+		if pivot != col {
+			for j := 0; j < n; j++ {
+				m[col*n+j], m[pivot*n+j] = m[pivot*n+j], m[col*n+j]
+			}
+			x[col], x[pivot] = x[pivot], x[col]
+		}
+		// Eliminate below the pivot
+		for row := col + 1; row < n; row++ {
+			factor := m[row*n+col] / m[col*n+col]
+			for j := col; j < n; j++ {
+				m[row*n+j] -= factor * m[col*n+j]
+			}
+			x[row] -= factor * x[col]
+		}
+	}
+
+	// Back substitution
+	for row := n - 1; row >= 0; row-- {
+		sum := x[row]
+		for j := row + 1; j < n; j++ {
+			sum -= m[row*n+j] * x[j]
+		}
+		x[row] = sum / m[row*n+row]
+	}
+	return nil
+}
+
+// invertSquare returns the inverse of a square n x n matrix by Gauss-Jordan elimination
+// with partial pivoting. A single elimination keeps it O(n^3), which matters for the
+// DOP and covariance of high-dimensional scenes (solving n systems separately is O(n^4)).
+func invertSquare(a []float64, n int) ([]float64, error) {
+	inv := make([]float64, n*n)
+	if err := invertSquareInto(a, n, make([]float64, n*n), inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// invertSquareInto is invertSquare writing the inverse to inv, with m (n*n) as scratch.
+func invertSquareInto(a []float64, n int, m, inv []float64) error {
+	copy(m, a)
+	for i := range inv[:n*n] {
+		inv[i] = 0
+	}
+	for i := 0; i < n; i++ {
+		inv[i*n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row*n+col]) > math.Abs(m[pivot*n+col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot*n+col]) < singularTolerance {
+			return fmt.Errorf("matrix is singular or near-singular (pivot %d)", col)
+		}
+		if pivot != col {
+			for j := 0; j < n; j++ {
+				m[col*n+j], m[pivot*n+j] = m[pivot*n+j], m[col*n+j]
+				inv[col*n+j], inv[pivot*n+j] = inv[pivot*n+j], inv[col*n+j]
+			}
+		}
+		// Scale the pivot row to 1, then clear the column in every other row
+		scale := 1 / m[col*n+col]
+		for j := 0; j < n; j++ {
+			m[col*n+j] *= scale
+			inv[col*n+j] *= scale
+		}
+		for row := 0; row < n; row++ {
+			factor := m[row*n+col]
+			if row == col || factor == 0 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				m[row*n+j] -= factor * m[col*n+j]
+				inv[row*n+j] -= factor * inv[col*n+j]
+			}
+		}
+	}
+	return nil
+}
+
+// conditionNumberInto returns the 1-norm condition number ||A||_1 ||A^-1||_1 of a square
+// n x n matrix, +Inf if it is singular. m and inv (n*n each) are scratch.
+func conditionNumberInto(a []float64, n int, m, inv []float64) float64 {
+	if invertSquareInto(a, n, m, inv) != nil {
+		return math.Inf(1)
+	}
+	return norm1(a, n) * norm1(inv, n)
+}
+
+// norm1 returns the largest absolute column sum of a square n x n matrix.
+func norm1(a []float64, n int) float64 {
+	largest := 0.0
+	for j := 0; j < n; j++ {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += math.Abs(a[i*n+j])
+		}
+		largest = math.Max(largest, sum)
+	}
+	return largest
+}
+
+// normalEquations computes A^T A (cols x cols) and A^T b (cols) for row-major A (rows x cols).
+func normalEquations(aData, bData []float64, rows, cols int) ([]float64, []float64) {
+	ata := make([]float64, cols*cols)
+	atb := make([]float64, cols)
+	normalEquationsInto(aData, bData, rows, cols, ata, atb)
+	return ata, atb
+}
+
+// normalEquationsInto is normalEquations writing to ata and atb; bData may be nil when
+// only A^T A is needed.
+func normalEquationsInto(aData, bData []float64, rows, cols int, ata, atb []float64) {
+	for i := range ata[:cols*cols] {
+		ata[i] = 0
+	}
+	for i := range atb[:cols] {
+		atb[i] = 0
+	}
+	for r := 0; r < rows; r++ {
+		row := aData[r*cols : (r+1)*cols]
+		for i := 0; i < cols; i++ {
+			if bData != nil {
+				atb[i] += row[i] * bData[r]
+			}
+			for j := i; j < cols; j++ {
+				ata[i*cols+j] += row[i] * row[j]
+			}
+		}
+	}
+	// Mirror the upper triangle
+	for i := 0; i < cols; i++ {
+		for j := 0; j < i; j++ {
+			ata[i*cols+j] = ata[j*cols+i]
+		}
+	}
+}
+
+// jacobiSweeps bounds the sweeps of symmetricEigen; it converges quadratically, so a
+// few suffice in practice.
+const jacobiSweeps = 50
+
+// symmetricEigen returns the eigenvalues of a symmetric n x n matrix, largest first, and
+// the eigenvectors as the columns of a row-major n x n matrix, by cyclic Jacobi rotations.
+// a is not modified.
+func symmetricEigen(a []float64, n int) (values, vectors []float64) {
+	m := append([]float64(nil), a[:n*n]...)
+	v := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		v[i*n+i] = 1
+	}
+	scale := 0.0
+	for _, x := range m {
+		scale += x * x
+	}
+	for sweep := 0; sweep < jacobiSweeps; sweep++ {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += m[i*n+j] * m[i*n+j]
+			}
+		}
+		if off <= 1e-30*scale || off == 0 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := m[p*n+q]
+				if apq == 0 {
+					continue
+				}
+				// Rotation that zeroes m[p][q]
+				theta := (m[q*n+q] - m[p*n+p]) / (2 * apq)
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					mkp, mkq := m[k*n+p], m[k*n+q]
+					m[k*n+p], m[k*n+q] = c*mkp-s*mkq, s*mkp+c*mkq
+				}
+				for k := 0; k < n; k++ {
+					mpk, mqk := m[p*n+k], m[q*n+k]
+					m[p*n+k], m[q*n+k] = c*mpk-s*mqk, s*mpk+c*mqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k*n+p], v[k*n+q]
+					v[k*n+p], v[k*n+q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(x, y int) bool { return m[order[x]*n+order[x]] > m[order[y]*n+order[y]] })
+	values = make([]float64, n)
+	vectors = make([]float64, n*n)
+	for k, col := range order {
+		values[k] = m[col*n+col]
+		for i := 0; i < n; i++ {
+			vectors[i*n+k] = v[i*n+col]
+		}
+	}
+	return values, vectors
+}
@@ -0,0 +1,368 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// RelativeProblem describes anchor-free positioning: ranges measured between nodes none
+// of which knows its position.
+type RelativeProblem struct {
+	Dimension int
+	Nodes     int
+	Ranges    []PeerMeasurement // Between node indices; repeated pairs are averaged
+}
+
+// RelativeResult is a relative map of the nodes. It is centered at the origin and
+// determined only up to rotation, reflection and translation; see AlignProcrustes.
+type RelativeResult struct {
+	Positions  []common.Vector
+	Stress     float64 // sqrt(sum (range - map distance)^2 / sum range^2) over the measured ranges
+	Iterations int     // SMACOF iterations performed
+}
+
+// smacofTolerance is the relative decrease of the stress below which SMACOF stops.
+const smacofTolerance = 1e-9
+
+// SolveMDSMAP builds a relative map with MDS-MAP: the distances between nodes that did
+// not range to each other are estimated by the shortest paths through the measured
+// ranges, classical multidimensional scaling of the completed matrix gives an initial
+// map, and up to maxIterations of SMACOF refine it against the measured ranges alone
+// (0 keeps the classical solution). The ranges must connect all nodes.
+func SolveMDSMAP(problem RelativeProblem, maxIterations int) (RelativeResult, error) {
+	dim, n := problem.Dimension, problem.Nodes
+	if dim < 1 {
+		return RelativeResult{}, fmt.Errorf("%w: dimension must be positive, got %d", ErrOutOfRange, dim)
+	}
+	if n < 2 {
+		return RelativeResult{}, fmt.Errorf("%w: a relative map needs at least 2 nodes, got %d", ErrInsufficientMeasurements, n)
+	}
+
+	// Measured ranges, averaged per pair
+	measured := make([]float64, n*n)
+	counts := make([]int, n*n)
+	for _, r := range problem.Ranges {
+		if r.A < 0 || r.A >= n || r.B < 0 || r.B >= n || r.A == r.B {
+			return RelativeResult{}, fmt.Errorf("range references invalid nodes %d and %d", r.A, r.B)
+		}
+		if r.Distance < 0 || math.IsNaN(r.Distance) {
+			return RelativeResult{}, fmt.Errorf("%w: range between nodes %d and %d is %f", ErrOutOfRange, r.A, r.B, r.Distance)
+		}
+		for _, k := range [2]int{r.A*n + r.B, r.B*n + r.A} {
+			measured[k] += r.Distance
+			counts[k]++
+		}
+	}
+	dist := make([]float64, n*n) // Completed by shortest paths
+	for k := range dist {
+		switch {
+		case counts[k] > 0:
+			measured[k] /= float64(counts[k])
+			dist[k] = measured[k]
+		case k/n != k%n:
+			dist[k] = math.Inf(1)
+		}
+	}
+	for via := 0; via < n; via++ { // Floyd-Warshall
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if d := dist[i*n+via] + dist[via*n+j]; d < dist[i*n+j] {
+					dist[i*n+j] = d
+				}
+			}
+		}
+	}
+	for j := 1; j < n; j++ {
+		if math.IsInf(dist[j], 1) {
+			return RelativeResult{}, fmt.Errorf("%w: node %d is not connected to node 0 by ranges", ErrInsufficientMeasurements, j)
+		}
+	}
+
+	x := classicalMDS(dist, n, dim)
+	result := RelativeResult{Stress: rangeStress(x, measured, counts, n, dim)}
+	if maxIterations > 0 {
+		result.Iterations, result.Stress = smacof(x, measured, counts, n, dim, maxIterations, result.Stress)
+	}
+	result.Positions = make([]common.Vector, n)
+	for i := range result.Positions {
+		result.Positions[i] = common.Vector(x[i*dim : (i+1)*dim]).Clone()
+	}
+	return result, nil
+}
+
+// classicalMDS places n points in dim dimensions (row-major n x dim) so that their
+// distances match dist: the leading eigenvectors of B = -1/2 J D² J, scaled by the
+// square roots of their eigenvalues. Axes without a positive eigenvalue stay zero.
+func classicalMDS(dist []float64, n, dim int) []float64 {
+	meanSq := make([]float64, n)
+	grand := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			meanSq[j] += dist[i*n+j] * dist[i*n+j] / float64(n)
+		}
+	}
+	for _, m := range meanSq {
+		grand += m / float64(n)
+	}
+	gram := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			gram[i*n+j] = -0.5 * (dist[i*n+j]*dist[i*n+j] - meanSq[i] - meanSq[j] + grand)
+		}
+	}
+	values, vectors := symmetricEigen(gram, n)
+	x := make([]float64, n*dim)
+	for k := 0; k < dim && k < n; k++ {
+		if values[k] <= 0 {
+			break
+		}
+		scale := math.Sqrt(values[k])
+		for i := 0; i < n; i++ {
+			x[i*dim+k] = vectors[i*n+k] * scale
+		}
+	}
+	return x
+}
+
+// smacof refines the map x in place by majorization (Guttman transforms) of the stress
+// of the measured ranges, weight 1 for measured pairs and 0 for the others. It returns
+// the iterations performed and the final stress.
+func smacof(x, measured []float64, counts []int, n, dim, maxIterations int, stress float64) (int, float64) {
+	// V+ = (V + 11^T/n)^-1 - 11^T/n, the pseudo-inverse of the weighted Laplacian V
+	laplacian := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && counts[i*n+j] > 0 {
+				laplacian[i*n+j] -= 1
+				laplacian[i*n+i] += 1
+			}
+		}
+	}
+	for k := range laplacian {
+		laplacian[k] += 1 / float64(n)
+	}
+	pinv, err := invertSquare(laplacian, n)
+	if err != nil {
+		return 0, stress // Not connected by measured ranges alone
+	}
+	for k := range pinv {
+		pinv[k] -= 1 / float64(n)
+	}
+
+	b := make([]float64, n*n)
+	bx := make([]float64, n*dim)
+	iter := 0
+	for iter < maxIterations {
+		iter++
+		// B(X): -delta_ij / d_ij(X) for measured pairs, rows summing to zero
+		for i := 0; i < n; i++ {
+			b[i*n+i] = 0
+			for j := 0; j < n; j++ {
+				if j == i {
+					continue
+				}
+				b[i*n+j] = 0
+				if counts[i*n+j] > 0 {
+					if d := rowDistance(x, i, j, dim); d > 0 {
+						b[i*n+j] = -measured[i*n+j] / d
+					}
+				}
+				b[i*n+i] -= b[i*n+j]
+			}
+		}
+		for i := range bx {
+			bx[i] = 0
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if w := b[i*n+j]; w != 0 {
+					for k := 0; k < dim; k++ {
+						bx[i*dim+k] += w * x[j*dim+k]
+					}
+				}
+			}
+		}
+		for i := 0; i < n; i++ {
+			for k := 0; k < dim; k++ {
+				sum := 0.0
+				for j := 0; j < n; j++ {
+					sum += pinv[i*n+j] * bx[j*dim+k]
+				}
+				x[i*dim+k] = sum
+			}
+		}
+		next := rangeStress(x, measured, counts, n, dim)
+		converged := stress-next <= smacofTolerance*stress
+		stress = next
+		if converged {
+			break
+		}
+	}
+	return iter, stress
+}
+
+// rangeStress is the normalized stress of the map x against the measured ranges.
+func rangeStress(x, measured []float64, counts []int, n, dim int) float64 {
+	var sumSq, sumRange float64
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if counts[i*n+j] > 0 {
+				r := measured[i*n+j] - rowDistance(x, i, j, dim)
+				sumSq += r * r
+				sumRange += measured[i*n+j] * measured[i*n+j]
+			}
+		}
+	}
+	if sumRange == 0 {
+		return 0
+	}
+	return math.Sqrt(sumSq / sumRange)
+}
+
+// rowDistance is the Euclidean distance between rows i and j of x (n x dim).
+func rowDistance(x []float64, i, j, dim int) float64 {
+	sum := 0.0
+	for k := 0; k < dim; k++ {
+		d := x[i*dim+k] - x[j*dim+k]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// Alignment is a similarity transform p -> Scale * Rotation * p + Translation, fitted by
+// AlignProcrustes. The rotation may include a reflection.
+type Alignment struct {
+	Rotation    [][]float64
+	Scale       float64
+	Translation common.Vector
+}
+
+// Apply maps a point with the transform.
+func (a Alignment) Apply(p common.Vector) common.Vector {
+	out := a.Translation.Clone()
+	for i, row := range a.Rotation {
+		for j, r := range row {
+			out[i] += a.Scale * r * p[j]
+		}
+	}
+	return out
+}
+
+// AlignProcrustes fits the rotation or reflection, translation and, if scaling, scale
+// that map points onto reference (matched by index) with the least squared error
+// (orthogonal Procrustes analysis), e.g. to compare a relative map with the ground
+// truth. It returns the transform and the RMS distance that remains.
+func AlignProcrustes(points, reference []common.Vector, scaling bool) (Alignment, float64, error) {
+	n := len(points)
+	if n == 0 || n != len(reference) {
+		return Alignment{}, 0, fmt.Errorf("%w: %d points and %d reference points", ErrDimensionMismatch, n, len(reference))
+	}
+	dim := points[0].Dimension()
+	for i := range points {
+		if points[i].Dimension() != dim || reference[i].Dimension() != dim {
+			return Alignment{}, 0, fmt.Errorf("%w: point %d has dimension %d and reference %d, expected %d", ErrDimensionMismatch, i, points[i].Dimension(), reference[i].Dimension(), dim)
+		}
+	}
+	centroid := func(vs []common.Vector) common.Vector {
+		c := common.NewVector(dim)
+		for _, v := range vs {
+			for k := range c {
+				c[k] += v[k] / float64(n)
+			}
+		}
+		return c
+	}
+	ca, cb := centroid(points), centroid(reference)
+
+	// H = sum a_i b_i^T of the centered points, and the spread of the points
+	h := make([]float64, dim*dim)
+	spread := 0.0
+	for i := range points {
+		for r := 0; r < dim; r++ {
+			ar := points[i][r] - ca[r]
+			spread += ar * ar
+			for c := 0; c < dim; c++ {
+				h[r*dim+c] += ar * (reference[i][c] - cb[c])
+			}
+		}
+	}
+	// H = U S V^T from the eigenvectors V of H^T H; the rotation is V U^T
+	hth := make([]float64, dim*dim)
+	for r := 0; r < dim; r++ {
+		for c := 0; c < dim; c++ {
+			for k := 0; k < dim; k++ {
+				hth[r*dim+c] += h[k*dim+r] * h[k*dim+c]
+			}
+		}
+	}
+	values, v := symmetricEigen(hth, dim)
+	u := make([]common.Vector, dim)
+	sigmaSum := 0.0
+	for k := 0; k < dim; k++ {
+		sigma := math.Sqrt(math.Max(values[k], 0))
+		if sigma <= singularTolerance*math.Max(math.Sqrt(math.Max(values[0], 0)), 1) {
+			break // Degenerate, e.g. collinear points: completed below
+		}
+		sigmaSum += sigma
+		u[k] = common.NewVector(dim)
+		for r := 0; r < dim; r++ {
+			for c := 0; c < dim; c++ {
+				u[k][r] += h[r*dim+c] * v[c*dim+k] / sigma
+			}
+		}
+	}
+	completeBasis(u)
+
+	a := Alignment{Rotation: make([][]float64, dim), Scale: 1}
+	for r := 0; r < dim; r++ {
+		a.Rotation[r] = make([]float64, dim)
+		for c := 0; c < dim; c++ {
+			for k := 0; k < dim; k++ {
+				a.Rotation[r][c] += v[r*dim+k] * u[k][c]
+			}
+		}
+	}
+	if scaling && spread > 0 {
+		a.Scale = sigmaSum / spread
+	}
+	a.Translation = cb.Clone()
+	for r, row := range a.Rotation {
+		for c, rot := range row {
+			a.Translation[r] -= a.Scale * rot * ca[c]
+		}
+	}
+
+	sumSq := 0.0
+	for i, p := range points {
+		d, _ := a.Apply(p).Distance(reference[i])
+		sumSq += d * d
+	}
+	return a, math.Sqrt(sumSq / float64(n)), nil
+}
+
+// completeBasis fills the nil vectors of an orthonormal set with unit vectors
+// orthogonal to the others (Gram-Schmidt on the coordinate axes).
+func completeBasis(basis []common.Vector) {
+	dim := len(basis)
+	for k := range basis {
+		if basis[k] != nil {
+			continue
+		}
+		for axis := 0; axis < dim; axis++ {
+			candidate := common.NewVector(dim)
+			candidate[axis] = 1
+			for _, b := range basis {
+				if b == nil {
+					continue
+				}
+				dot, _ := candidate.DotProduct(b)
+				_ = candidate.AddScaledInPlace(b, -dot)
+			}
+			if norm := candidate.Norm(); norm > 1e-6 {
+				basis[k] = candidate.MultiplyByScalar(1 / norm)
+				break
+			}
+		}
+	}
+}
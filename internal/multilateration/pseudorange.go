@@ -0,0 +1,143 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// pseudorangeIterations bounds the Gauss-Newton refinement of SolvePseudoranges.
+const pseudorangeIterations = 20
+
+// SolvePseudoranges estimates the position together with a range bias common to all
+// measurements, GPS-style: every measured range is a pseudorange ||x - s_i|| + b, where b
+// is the signal speed times the clock error of the transmitter (or its unknown transmit
+// time) in time-of-arrival systems. The bias costs one more unknown, so it needs at least
+// dimension + 2 measurements. Solution.ClockBias holds b, and GDOP includes the clock
+// term; errors of the individual sensor clocks are not modeled and remain in the
+// residuals.
+func SolvePseudoranges(measurements []Measurement, dimension int) (Solution, error) {
+	m, n := len(measurements), dimension+1 // Unknowns: position and bias
+	if m < n+1 {
+		return Solution{}, fmt.Errorf("%w: got %d, need at least %d for dimension %d with a clock bias", ErrInsufficientMeasurements, m, n+1, dimension)
+	}
+	for _, meas := range measurements {
+		if meas.SensorPosition.Dimension() != dimension {
+			return Solution{}, fmt.Errorf("%w: sensor position has dimension %d, expected %d", ErrDimensionMismatch, meas.SensorPosition.Dimension(), dimension)
+		}
+	}
+
+	x, err := pseudorangeInitialGuess(measurements, dimension)
+	if err != nil {
+		return Solution{}, fmt.Errorf("pseudorange initialization failed: %w", err)
+	}
+	residuals := make([]float64, m)
+	hData := make([]float64, m*n)
+	for iter := 0; iter < pseudorangeIterations; iter++ {
+		pseudorangeJacobian(measurements, x, residuals, hData)
+		normal, rhs := normalEquations(hData, residuals, m, n)
+		step, err := solveSquare(normal, rhs, n)
+		if err != nil {
+			return Solution{}, fmt.Errorf("pseudorange geometry is degenerate: %w", err)
+		}
+		stepNorm := 0.0
+		for i := range x {
+			x[i] += step[i]
+			stepNorm += step[i] * step[i]
+		}
+		if math.Sqrt(stepNorm) < 1e-9 {
+			break
+		}
+	}
+
+	pseudorangeJacobian(measurements, x, residuals, hData)
+	sumSq := 0.0
+	for _, r := range residuals {
+		sumSq += r * r
+	}
+	solution := Solution{
+		Position:      common.Vector(x[:dimension]).Clone(),
+		ClockBias:     x[dimension],
+		ResidualError: math.Sqrt(sumSq / float64(m)),
+	}
+
+	normal, _ := normalEquations(hData, residuals, m, n)
+	q, err := invertSquare(normal, n)
+	if err != nil {
+		solution.GDOP, solution.HDOP, solution.VDOP = math.Inf(1), math.Inf(1), math.Inf(1)
+		return solution, nil
+	}
+	trace := 0.0
+	for i := 0; i < n; i++ {
+		trace += q[i*n+i]
+	}
+	solution.GDOP = math.Sqrt(trace)
+	switch {
+	case dimension >= 2:
+		solution.HDOP = math.Sqrt(q[0] + q[n+1])
+	case dimension == 1:
+		solution.HDOP = math.Sqrt(q[0])
+	}
+	if dimension >= 3 {
+		solution.VDOP = math.Sqrt(q[2*n+2])
+	}
+	if m > n {
+		variance := sumSq / float64(m-n)
+		solution.Covariance = make([][]float64, dimension)
+		for i := range solution.Covariance {
+			solution.Covariance[i] = make([]float64, dimension)
+			for j := range solution.Covariance[i] {
+				solution.Covariance[i][j] = variance * q[i*n+j]
+			}
+		}
+	}
+	attachVelocity(&solution, measurements)
+	return solution, nil
+}
+
+// pseudorangeInitialGuess solves the pseudorange equations linearized by differencing
+// against the first measurement, which is exact for noiseless ranges:
+// 2(s_i - s_0)·x - 2(p_i - p_0)b = |s_i|² - |s_0|² - p_i² + p_0².
+// It returns the position followed by the bias.
+func pseudorangeInitialGuess(measurements []Measurement, dimension int) ([]float64, error) {
+	n := dimension + 1
+	rows := len(measurements) - 1
+	aData := make([]float64, 0, rows*n)
+	bData := make([]float64, 0, rows)
+	ref := measurements[0]
+	refNormSq := ref.SensorPosition.NormSq()
+	for _, meas := range measurements[1:] {
+		for j := 0; j < dimension; j++ {
+			aData = append(aData, 2*(meas.SensorPosition[j]-ref.SensorPosition[j]))
+		}
+		aData = append(aData, -2*(meas.Distance-ref.Distance))
+		bData = append(bData, meas.SensorPosition.NormSq()-refNormSq-meas.Distance*meas.Distance+ref.Distance*ref.Distance)
+	}
+	normal, rhs := normalEquations(aData, bData, rows, n)
+	return solveSquare(normal, rhs, n)
+}
+
+// pseudorangeJacobian fills the pseudorange residuals p_i - (||x - s_i|| + b) and the
+// Jacobian rows [unit line-of-sight, 1] at x = (position, bias).
+func pseudorangeJacobian(measurements []Measurement, x []float64, residuals, hData []float64) {
+	n := len(x)
+	dimension := n - 1
+	for i, meas := range measurements {
+		row := hData[i*n : (i+1)*n]
+		dist := 0.0
+		for j := 0; j < dimension; j++ {
+			row[j] = x[j] - meas.SensorPosition[j]
+			dist += row[j] * row[j]
+		}
+		dist = math.Sqrt(dist)
+		for j := 0; j < dimension; j++ {
+			if dist > 0 {
+				row[j] /= dist
+			} else {
+				row[j] = 0
+			}
+		}
+		row[dimension] = 1
+		residuals[i] = meas.Distance - (dist + x[dimension])
+	}
+}
@@ -0,0 +1,207 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"sort"
+)
+
+// SolveFunc is the common signature of the position solvers in this package.
+type SolveFunc func(measurements []Measurement, dimension int) (Solution, error)
+
+// RobustOptions configures SolveRobust.
+type RobustOptions struct {
+	// HuberThreshold is the range residual beyond which a measurement is down-weighted.
+	// 0 derives it from the data: 1.345 * robust sigma (MAD) of the residuals;
+	// math.Inf(1) never down-weights, leaving plain nonlinear least squares.
+	HuberThreshold float64
+	MaxIterations  int     // Gauss-Newton / reweighting iterations
+	Tolerance      float64 // Stop when the position update is shorter than this
+
+	// Metric in which the ranges were measured, nil means Euclidean. With another
+	// metric the solver starts from the centroid of the sensors, since the linear
+	// initialization assumes straight-line ranges.
+	Metric common.Metric
+}
+
+// DefaultRobustOptions returns options suitable for ranges with occasional NLOS outliers.
+func DefaultRobustOptions() RobustOptions {
+	return RobustOptions{HuberThreshold: 0, MaxIterations: 20, Tolerance: 1e-6}
+}
+
+// RobustSolver returns a SolveFunc that runs SolveRobust with the given options.
+func RobustSolver(opts RobustOptions) SolveFunc {
+	return func(measurements []Measurement, dimension int) (Solution, error) {
+		return SolveRobust(measurements, dimension, opts)
+	}
+}
+
+// SolveRobust estimates the position with iteratively reweighted least squares on the
+// nonlinear range equations using the Huber loss, so that a few grossly wrong ranges
+// (e.g. non-line-of-sight) have bounded influence. It starts from SolveLeastSquares.
+// Solution.Weights holds the final weight of every measurement (1 = inlier).
+func SolveRobust(measurements []Measurement, dimension int, opts RobustOptions) (Solution, error) {
+	x, err := robustInitialGuess(measurements, dimension, opts.Metric)
+	if err != nil {
+		return Solution{}, fmt.Errorf("robust solver initialization failed: %w", err)
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = DefaultRobustOptions().MaxIterations
+	}
+	metric := common.MetricOrDefault(opts.Metric)
+
+	weights := make([]float64, len(measurements))
+	residuals := make([]float64, len(measurements))
+	jData := make([]float64, len(measurements)*dimension)
+
+	for iter := 0; iter < opts.MaxIterations; iter++ {
+		if err := rangeJacobian(measurements, x, metric, residuals, jData); err != nil {
+			return Solution{}, fmt.Errorf("dimension mismatch in robust solver: %w", err)
+		}
+		huberWeights(residuals, opts.HuberThreshold, weights)
+		next, stepNorm, ok := weightedGaussNewtonStep(measurements, x, metric, residuals, jData, weights)
+		if !ok {
+			break // Degenerate weighted geometry or no descent, keep the current estimate
+		}
+		x = next
+		if stepNorm < opts.Tolerance {
+			break
+		}
+	}
+
+	// Final weighted RMS range residual
+	sumW, sumWR := 0.0, 0.0
+	for i, m := range measurements {
+		predicted, _ := metric.Distance(x, m.SensorPosition)
+		r := m.Distance - predicted
+		sumW += weights[i]
+		sumWR += weights[i] * r * r
+	}
+	solution := Solution{Position: x, ResidualError: math.Sqrt(sumWR / math.Max(sumW, 1e-12)), Weights: weights}
+
+	if dop, dopErr := metricDOP(measurements, x, metric); dopErr == nil {
+		solution.GDOP, solution.HDOP, solution.VDOP = dop.GDOP, dop.HDOP, dop.VDOP
+	} else {
+		solution.GDOP, solution.HDOP, solution.VDOP = math.Inf(1), math.Inf(1), math.Inf(1)
+	}
+	solution.Covariance = estimateCovariance(measurements, x, metric, weights)
+	attachVelocity(&solution, measurements)
+	return solution, nil
+}
+
+// robustInitialGuess is the linear least-squares solution for Euclidean ranges and the
+// centroid of the sensors otherwise.
+func robustInitialGuess(measurements []Measurement, dimension int, metric common.Metric) (common.Vector, error) {
+	if _, euclidean := common.MetricOrDefault(metric).(common.Euclidean); euclidean {
+		initial, err := SolveLeastSquares(measurements, dimension)
+		if err != nil {
+			return nil, err
+		}
+		return initial.Position.Clone(), nil
+	}
+	if len(measurements) < dimension+1 {
+		return nil, fmt.Errorf("%w: got %d, need at least %d", ErrInsufficientMeasurements, len(measurements), dimension+1)
+	}
+	centroid := common.NewVector(dimension)
+	for _, m := range measurements {
+		if m.SensorPosition.Dimension() != dimension {
+			return nil, fmt.Errorf("%w: sensor position has dimension %d, expected %d", ErrDimensionMismatch, m.SensorPosition.Dimension(), dimension)
+		}
+		for j := range centroid {
+			centroid[j] += m.SensorPosition[j] / float64(len(measurements))
+		}
+	}
+	return centroid, nil
+}
+
+// rangeJacobian fills the range residuals d_i - dist(x, s_i) and the Jacobian of the
+// predicted ranges (row-major) at x; in Euclidean space its rows are the unit
+// line-of-sight vectors.
+func rangeJacobian(measurements []Measurement, x common.Vector, metric common.Metric, residuals, jData []float64) error {
+	dimension := x.Dimension()
+	for i, m := range measurements {
+		predicted, err := metric.Distance(x, m.SensorPosition)
+		if err != nil {
+			return err
+		}
+		residuals[i] = m.Distance - predicted
+		gradient, err := metric.Gradient(x, m.SensorPosition) // Zero on top of the sensor, where the direction is undefined
+		if err != nil {
+			return err
+		}
+		copy(jData[i*dimension:(i+1)*dimension], gradient)
+	}
+	return nil
+}
+
+// weightedGaussNewtonStep solves (J^T W J) delta = J^T W r and halves the step until the
+// weighted cost decreases, since full steps can overshoot to the mirror solution when
+// the geometry is poor. Returns the new position, the length of the accepted step and
+// false if the system is singular or no step decreased the cost.
+func weightedGaussNewtonStep(measurements []Measurement, x common.Vector, metric common.Metric, residuals, jData, weights []float64) (common.Vector, float64, bool) {
+	dimension := x.Dimension()
+	wj := make([]float64, len(jData))
+	wr := make([]float64, len(residuals))
+	for i := range residuals {
+		sw := math.Sqrt(weights[i])
+		wr[i] = sw * residuals[i]
+		for j := 0; j < dimension; j++ {
+			wj[i*dimension+j] = sw * jData[i*dimension+j]
+		}
+	}
+	jtj, jtr := normalEquations(wj, wr, len(residuals), dimension)
+	delta, err := solveSquare(jtj, jtr, dimension)
+	if err != nil {
+		return x, 0, false
+	}
+
+	step := common.Vector(delta)
+	cost := weightedCost(measurements, x, metric, weights)
+	for halvings := 0; halvings < 10; halvings++ {
+		candidate, _ := x.Add(step)
+		if weightedCost(measurements, candidate, metric, weights) <= cost {
+			return candidate, step.Norm(), true
+		}
+		step = step.MultiplyByScalar(0.5)
+	}
+	return x, 0, false
+}
+
+// weightedCost returns the weighted sum of squared range residuals at x.
+func weightedCost(measurements []Measurement, x common.Vector, metric common.Metric, weights []float64) float64 {
+	cost := 0.0
+	for i, m := range measurements {
+		predicted, _ := metric.Distance(x, m.SensorPosition)
+		r := m.Distance - predicted
+		cost += weights[i] * r * r
+	}
+	return cost
+}
+
+// huberWeights fills weights for the residuals using the Huber function.
+// With threshold 0 the threshold is 1.345 times the MAD-based sigma estimate.
+func huberWeights(residuals []float64, threshold float64, weights []float64) {
+	if threshold <= 0 {
+		abs := make([]float64, len(residuals))
+		for i, r := range residuals {
+			abs[i] = math.Abs(r)
+		}
+		sort.Float64s(abs)
+		median := abs[len(abs)/2]
+		if len(abs)%2 == 0 {
+			median = (abs[len(abs)/2-1] + abs[len(abs)/2]) / 2
+		}
+		threshold = 1.345 * 1.4826 * median
+		if threshold < 1e-9 {
+			threshold = 1e-9 // Perfect data: every residual is effectively zero
+		}
+	}
+	for i, r := range residuals {
+		if a := math.Abs(r); a > threshold {
+			weights[i] = threshold / a
+		} else {
+			weights[i] = 1
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// RangeObservation is a measured range between an anchor and one pose of the trajectory.
+type RangeObservation struct {
+	Anchor   int // Index into SLAMProblem.AnchorPriors
+	Pose     int // Index into SLAMProblem.InitialPoses
+	Distance float64
+}
+
+// SLAMProblem describes a range-only SLAM batch: anchors are only coarsely known
+// (Gaussian prior), target poses are unknown, and both are linked by range observations.
+type SLAMProblem struct {
+	AnchorPriors []common.Vector // Coarse anchor positions, also used as the initial guess
+	AnchorSigma  float64         // Standard deviation of the anchor priors
+	RangeSigma   float64         // Standard deviation of the range observations
+	InitialPoses []common.Vector // Initial guesses of the poses (e.g. from per-pose least squares)
+	Observations []RangeObservation
+}
+
+// SLAMResult holds the jointly refined anchors and poses.
+type SLAMResult struct {
+	Anchors    []common.Vector
+	Poses      []common.Vector
+	Iterations int
+	Cost       float64 // Final sum of squared normalized residuals
+}
+
+// SolveRangeOnlySLAM jointly refines anchor positions and poses with damped Gauss-Newton
+// (Levenberg-Marquardt) on a factor graph of range factors and anchor prior factors.
+// The priors fix the gauge (global translation/rotation) of the solution.
+func SolveRangeOnlySLAM(problem SLAMProblem, maxIterations int) (SLAMResult, error) {
+	numAnchors, numPoses := len(problem.AnchorPriors), len(problem.InitialPoses)
+	if numAnchors == 0 || numPoses == 0 {
+		return SLAMResult{}, fmt.Errorf("SLAM needs at least one anchor and one pose, got %d and %d", numAnchors, numPoses)
+	}
+	if problem.AnchorSigma <= 0 || problem.RangeSigma <= 0 {
+		return SLAMResult{}, fmt.Errorf("%w: SLAM sigmas must be positive", ErrOutOfRange)
+	}
+	dim := problem.AnchorPriors[0].Dimension()
+	for i, a := range problem.AnchorPriors {
+		if a.Dimension() != dim || dim == 0 {
+			return SLAMResult{}, fmt.Errorf("%w: anchor %d has dimension %d, anchor 0 has %d", ErrDimensionMismatch, i, a.Dimension(), dim)
+		}
+	}
+	for _, obs := range problem.Observations {
+		if obs.Anchor < 0 || obs.Anchor >= numAnchors || obs.Pose < 0 || obs.Pose >= numPoses {
+			return SLAMResult{}, fmt.Errorf("observation references unknown anchor %d or pose %d", obs.Anchor, obs.Pose)
+		}
+	}
+
+	// State vector: all anchors followed by all poses
+	numVars := (numAnchors + numPoses) * dim
+	state := make([]float64, 0, numVars)
+	for _, a := range problem.AnchorPriors {
+		state = append(state, a...)
+	}
+	for _, p := range problem.InitialPoses {
+		if p.Dimension() != dim {
+			return SLAMResult{}, fmt.Errorf("%w: pose dimension %d, anchor dimension %d", ErrDimensionMismatch, p.Dimension(), dim)
+		}
+		state = append(state, p...)
+	}
+	poseOffset := numAnchors * dim
+
+	cost := slamCost(problem, state, dim, poseOffset)
+	lambda := 1e-3
+	iter, converged := 0, false
+	for ; iter < maxIterations && !converged; iter++ {
+		hessian := make([]float64, numVars*numVars)
+		gradient := make([]float64, numVars)
+
+		// Range factors: r = (d - |a - p|) / sigma
+		for _, obs := range problem.Observations {
+			a := state[obs.Anchor*dim : (obs.Anchor+1)*dim]
+			p := state[poseOffset+obs.Pose*dim : poseOffset+(obs.Pose+1)*dim]
+			diff := make([]float64, dim)
+			norm := 0.0
+			for k := 0; k < dim; k++ {
+				diff[k] = a[k] - p[k]
+				norm += diff[k] * diff[k]
+			}
+			norm = math.Sqrt(norm)
+			if norm == 0 {
+				continue // Direction undefined, skip this factor for the iteration
+			}
+			r := (obs.Distance - norm) / problem.RangeSigma
+			// dr/da = -diff/norm/sigma, dr/dp = +diff/norm/sigma
+			idx := make([]int, 0, 2*dim)
+			jac := make([]float64, 0, 2*dim)
+			for k := 0; k < dim; k++ {
+				g := diff[k] / norm / problem.RangeSigma
+				idx = append(idx, obs.Anchor*dim+k, poseOffset+obs.Pose*dim+k)
+				jac = append(jac, -g, g)
+			}
+			for a, i := range idx {
+				gradient[i] += jac[a] * r // J^T r
+				for b, j := range idx {
+					hessian[i*numVars+j] += jac[a] * jac[b]
+				}
+			}
+		}
+		// Anchor prior factors: r = (prior - a) / sigma, dr/da = -1/sigma
+		inv := 1 / (problem.AnchorSigma * problem.AnchorSigma)
+		for ai, prior := range problem.AnchorPriors {
+			for k := 0; k < dim; k++ {
+				idx := ai*dim + k
+				hessian[idx*numVars+idx] += inv
+				gradient[idx] -= (prior[k] - state[idx]) * inv // J^T r
+			}
+		}
+
+		// Damped step: (H + lambda*diag(H)) delta = -g
+		accepted := false
+		for attempt := 0; attempt < 10 && !accepted; attempt++ {
+			damped := make([]float64, len(hessian))
+			copy(damped, hessian)
+			for i := 0; i < numVars; i++ {
+				damped[i*numVars+i] += lambda*hessian[i*numVars+i] + 1e-12
+			}
+			negGrad := make([]float64, numVars)
+			for i, g := range gradient {
+				negGrad[i] = -g
+			}
+			delta, err := solveSquare(damped, negGrad, numVars)
+			if err != nil {
+				lambda *= 10
+				continue
+			}
+			candidate := make([]float64, numVars)
+			for i := range state {
+				candidate[i] = state[i] + delta[i]
+			}
+			if newCost := slamCost(problem, candidate, dim, poseOffset); newCost <= cost {
+				stepNorm := 0.0
+				for _, d := range delta {
+					stepNorm += d * d
+				}
+				state, cost = candidate, newCost
+				lambda = math.Max(lambda/10, 1e-9)
+				accepted = true
+				converged = math.Sqrt(stepNorm) < 1e-9
+			} else {
+				lambda *= 10
+			}
+		}
+		if !accepted {
+			break // No descent direction found, we are at a (local) minimum
+		}
+	}
+
+	result := SLAMResult{Iterations: iter, Cost: cost}
+	for i := 0; i < numAnchors; i++ {
+		result.Anchors = append(result.Anchors, common.Vector(state[i*dim:(i+1)*dim]).Clone())
+	}
+	for i := 0; i < numPoses; i++ {
+		result.Poses = append(result.Poses, common.Vector(state[poseOffset+i*dim:poseOffset+(i+1)*dim]).Clone())
+	}
+	return result, nil
+}
+
+// slamCost returns the sum of squared normalized residuals of all factors.
+func slamCost(problem SLAMProblem, state []float64, dim, poseOffset int) float64 {
+	cost := 0.0
+	for _, obs := range problem.Observations {
+		norm := 0.0
+		for k := 0; k < dim; k++ {
+			d := state[obs.Anchor*dim+k] - state[poseOffset+obs.Pose*dim+k]
+			norm += d * d
+		}
+		r := (obs.Distance - math.Sqrt(norm)) / problem.RangeSigma
+		cost += r * r
+	}
+	for ai, prior := range problem.AnchorPriors {
+		for k := 0; k < dim; k++ {
+			r := (prior[k] - state[ai*dim+k]) / problem.AnchorSigma
+			cost += r * r
+		}
+	}
+	return cost
+}
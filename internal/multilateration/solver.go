@@ -4,26 +4,61 @@ import (
 	"fmt"
 	"math"
 	"multilateration-sim/internal/common" // Замените на ваше имя модуля
-
-	"gonum.org/v1/gonum/blas/blas64" // For vector norm calculation
-	"gonum.org/v1/gonum/mat"         // Import the gonum matrix package
 )
 
+// maxConditionNumber is the condition number of the linearized system above which its
+// solution is dominated by rounding and the geometry is treated as degenerate.
+const maxConditionNumber = 1e10
+
 // Measurement represents a single distance measurement from a sensor.
 type Measurement struct {
+	SensorID       string // Optional, identifies the sensor that produced the measurement
 	SensorPosition common.Vector
 	Distance       float64
+	Timestamp      float64 // Time the range was taken (simulation seconds), may lag the solve time
+	Variance       float64 // Range noise variance (units^2), 0 if unknown; used by SolveWeightedLeastSquares
+
+	RangeRate      float64       // Doppler range-rate (units/s, positive = receding), valid if HasRangeRate
+	HasRangeRate   bool          // Whether the sensor also measured the range-rate
+	SensorVelocity common.Vector // Sensor velocity at measurement time, nil for static sensors
 }
 
 // Solution contains the estimated position and a measure of the solution quality.
 type Solution struct {
 	Position      common.Vector
-	ResidualError float64 // Lower is better. Represents ||Ax - b|| / sqrt(m)
+	ResidualError float64       // Lower is better. Represents ||Ax - b|| / sqrt(m)
+	GDOP          float64       // Geometric dilution of precision of the sensors used, +Inf if degenerate
+	HDOP          float64       // Horizontal dilution of precision
+	VDOP          float64       // Vertical dilution of precision (0 for dimension < 3)
+	Weights       []float64     // Per-measurement weights of robust solvers (1 = inlier), nil otherwise
+	Velocity      common.Vector // Estimated from range-rates, nil if fewer than dimension were available
+	Covariance    [][]float64   // Position covariance (units^2) from the normal equations and residual variance, nil if unavailable
+	ClockBias     float64       // Common range bias (units) estimated by SolvePseudoranges, 0 for other solvers
+
+	// ConditionNumber of the linearized system solved by SolveLeastSquares (an estimate
+	// in the 1- or infinity-norm): how much relative range errors may be amplified in
+	// the position. 0 if the solver does not compute it.
+	ConditionNumber float64
+}
+
+// String returns a human-readable representation of the solution using the default format.
+func (s Solution) String() string {
+	return s.FormatWith(common.DefaultFormat())
+}
+
+// FormatWith returns a human-readable representation of the solution using the given format.
+func (s Solution) FormatWith(f common.Format) string {
+	if s.Position == nil {
+		return "no estimate"
+	}
+	return fmt.Sprintf("%s (Resid: %s, GDOP: %.2f)", f.Vector(s.Position), f.Number(s.ResidualError), s.GDOP)
 }
 
 // SolveLeastSquares attempts to find the target position using the least squares method.
 // It requires at least dimension + 1 measurements for this linearized approach.
 // Returns the estimated position and the normalized residual error.
+// The linear system is solved with gonum QR by default, or with a hand-rolled
+// small-matrix solver when built with the "lite" tag (see solver_lite.go).
 func SolveLeastSquares(measurements []Measurement, dimension int) (Solution, error) {
 	numMeasurements := len(measurements)
 	var emptySolution Solution // Solution to return on error
@@ -31,11 +66,92 @@ func SolveLeastSquares(measurements []Measurement, dimension int) (Solution, err
 	// We need at least n+1 measurements for n dimensions for the linearized system
 	// to potentially have a unique solution via A^T A.
 	if numMeasurements < dimension+1 {
-		return emptySolution, fmt.Errorf("insufficient measurements: got %d, need at least %d for dimension %d for this LS method", numMeasurements, dimension+1, dimension)
+		return emptySolution, fmt.Errorf("%w: got %d, need at least %d for dimension %d for this LS method", ErrInsufficientMeasurements, numMeasurements, dimension+1, dimension)
+	}
+
+	aData, bData, err := buildLinearSystem(measurements, dimension)
+	if err != nil {
+		return emptySolution, err
 	}
+	numEquations := numMeasurements - 1
+
+	// --- Solve the least squares problem A * x = b ---
+	x, residualNorm, cond, err := solveLinearSystem(aData, bData, numEquations, dimension)
+	if err != nil {
+		return emptySolution, err
+	}
+
+	// Normalize the residual by sqrt(number of equations) for scale invariance
+	normalizedResidual := residualNorm / math.Sqrt(float64(numEquations))
+
+	solution := Solution{
+		Position:        common.Vector(x),
+		ResidualError:   normalizedResidual,
+		ConditionNumber: cond,
+	}
+
+	// --- Geometry quality ---
+	sensorPositions := make([]common.Vector, numMeasurements)
+	for i, m := range measurements {
+		sensorPositions[i] = m.SensorPosition
+	}
+	if dop, dopErr := ComputeDOP(sensorPositions, solution.Position); dopErr == nil {
+		solution.GDOP, solution.HDOP, solution.VDOP = dop.GDOP, dop.HDOP, dop.VDOP
+	} else {
+		// Bad geometry does not invalidate the estimate itself, but it cannot be trusted
+		solution.GDOP, solution.HDOP, solution.VDOP = math.Inf(1), math.Inf(1), math.Inf(1)
+	}
+	solution.Covariance = estimateCovariance(measurements, solution.Position, common.Euclidean{}, nil)
+	attachVelocity(&solution, measurements)
+
+	return solution, nil
+}
+
+// checkConditioning returns ErrDegenerateGeometry if the linearized system is rank
+// deficient or too ill-conditioned to solve. diagonal is that of the triangular factor
+// R of A = QR; the numerical rank counts its entries that are not negligible. nil
+// leaves the rank out of the error.
+func checkConditioning(diagonal []float64, cond float64) error {
+	if cond <= maxConditionNumber && !math.IsNaN(cond) {
+		return nil
+	}
+	if diagonal == nil {
+		return fmt.Errorf("%w: condition number %.3g", ErrDegenerateGeometry, cond)
+	}
+	largest := 0.0
+	for _, d := range diagonal {
+		largest = math.Max(largest, math.Abs(d))
+	}
+	rank := 0
+	for _, d := range diagonal {
+		if math.Abs(d) > largest/maxConditionNumber {
+			rank++
+		}
+	}
+	return fmt.Errorf("%w: rank %d < dimension %d (condition number %.3g)", ErrDegenerateGeometry, rank, len(diagonal), cond)
+}
+
+// buildLinearSystem linearizes the range equations by subtracting the reference
+// (last) measurement from all others. Returns row-major A ((m-1) x n) and b (m-1).
+func buildLinearSystem(measurements []Measurement, dimension int) ([]float64, []float64, error) {
+	numEquations := len(measurements) - 1
+	aData := make([]float64, numEquations*dimension)
+	bData := make([]float64, numEquations)
+	if err := buildLinearSystemInto(measurements, dimension, aData, bData); err != nil {
+		return nil, nil, err
+	}
+	return aData, bData, nil
+}
+
+// buildLinearSystemInto is buildLinearSystem writing A and b to the given buffers.
+func buildLinearSystemInto(measurements []Measurement, dimension int, aData, bData []float64) error {
+	numMeasurements := len(measurements)
 
 	// Use the last measurement's sensor as the reference sensor (k in the equations)
 	refSensorPos := measurements[numMeasurements-1].SensorPosition
+	if refSensorPos.Dimension() != dimension {
+		return fmt.Errorf("%w calculating A: reference sensor has dimension %d, expected %d", ErrDimensionMismatch, refSensorPos.Dimension(), dimension)
+	}
 	refDist := measurements[numMeasurements-1].Distance
 	if refDist < 0 {
 		refDist = 0
@@ -43,13 +159,13 @@ func SolveLeastSquares(measurements []Measurement, dimension int) (Solution, err
 	refDistSq := refDist * refDist           // d_k^2
 	refSensorNormSq := refSensorPos.NormSq() // ||S_k||^2 (Using our new method)
 
-	// Create the matrix A (size (m-1) x n) and vector b (size (m-1) x 1)
-	numEquations := numMeasurements - 1
-	aData := make([]float64, numEquations*dimension)
-	bData := make([]float64, numEquations)
-
-	for i := 0; i < numEquations; i++ {
+	// Fill the matrix A (size (m-1) x n) and vector b (size (m-1) x 1)
+	for i := 0; i < numMeasurements-1; i++ {
 		sensorPos := measurements[i].SensorPosition // S_i
+		if sensorPos.Dimension() != dimension {
+			// This should not happen if dimensions are consistent
+			return fmt.Errorf("%w calculating A: sensor has dimension %d, expected %d", ErrDimensionMismatch, sensorPos.Dimension(), dimension)
+		}
 		dist := measurements[i].Distance
 		if dist < 0 {
 			dist = 0
@@ -58,66 +174,14 @@ func SolveLeastSquares(measurements []Measurement, dimension int) (Solution, err
 		sensorNormSq := sensorPos.NormSq() // ||S_i||^2 (Using our new method)
 
 		// Calculate row i of matrix A: 2 * (S_k - S_i)
-		diffVec, err := refSensorPos.Subtract(sensorPos)
-		if err != nil {
-			// This should not happen if dimensions are consistent
-			return emptySolution, fmt.Errorf("dimension mismatch calculating A: %w", err)
-		}
-		scaledDiff := diffVec.MultiplyByScalar(2.0)
 		for j := 0; j < dimension; j++ {
-			aData[i*dimension+j] = scaledDiff[j]
+			aData[i*dimension+j] = (refSensorPos[j] - sensorPos[j]) * 2.0
 		}
 
 		// Calculate element i of vector b: d_i^2 - d_k^2 - ||S_i||^2 + ||S_k||^2
 		bData[i] = distSq - refDistSq - sensorNormSq + refSensorNormSq
 	}
-
-	// Create gonum matrix objects
-	A := mat.NewDense(numEquations, dimension, aData)
-	b := mat.NewVecDense(numEquations, bData)
-
-	// --- Solve the least squares problem A * x = b ---
-	// We use QR decomposition directly as it's generally more robust for LS problems
-	// than forming A^T A explicitly (which can worsen conditioning).
-	var qr mat.QR
-	qr.Factorize(A)
-
-	// Check if the system might be rank-deficient (more likely with few sensors or poor geometry)
-	// rank, _ := qr.Rank(1e-10) // Estimate rank with a tolerance
-	rank := dimension
-	if rank < dimension {
-		fmt.Printf("Warning: System may be rank-deficient (rank %d < dimension %d). Solution might not be unique or reliable.\n", rank, dimension)
-		// Continue solving, but the result's reliability is questionable.
-	}
-
-	var x mat.VecDense
-	err := qr.SolveVecTo(&x, false, b) // Solves min ||Ax - b||_2
-	if err != nil {
-		// This might happen if A is severely ill-conditioned or has zero columns etc.
-		return emptySolution, fmt.Errorf("QR least squares solve failed: %w", err)
-	}
-
-	// --- Calculate Residual Error ---
-	var residualVec mat.VecDense
-	residualVec.MulVec(A, &x)           // residualVec = A*x
-	residualVec.SubVec(b, &residualVec) // residualVec = b - A*x
-	// Use blas64 directly for norm calculation
-	residualNorm := blas64.Nrm2(residualVec.RawVector())
-	// Normalize the residual by sqrt(number of equations) for scale invariance
-	normalizedResidual := residualNorm / math.Sqrt(float64(numEquations))
-
-	// Extract the result into our common.Vector type
-	resultVector := common.NewVector(dimension)
-	for i := 0; i < dimension; i++ {
-		resultVector[i] = x.AtVec(i)
-	}
-
-	solution := Solution{
-		Position:      resultVector,
-		ResidualError: normalizedResidual,
-	}
-
-	return solution, nil
+	return nil
 }
 
 // CalculateLocalizationError calculates the Euclidean distance between the true and estimated positions.
@@ -0,0 +1,103 @@
+//go:build !lite
+
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64" // For vector norm calculation
+	"gonum.org/v1/gonum/lapack/lapack64"
+	"gonum.org/v1/gonum/mat" // Import the gonum matrix package
+)
+
+// solveLinearSystem solves min ||Ax - b||_2 for row-major A (rows x cols).
+// Returns x, the residual norm ||Ax - b|| and the condition number of A.
+func solveLinearSystem(aData, bData []float64, rows, cols int) ([]float64, float64, float64, error) {
+	// Create gonum matrix objects
+	A := mat.NewDense(rows, cols, aData)
+	b := mat.NewVecDense(rows, bData)
+
+	// We use QR decomposition directly as it's generally more robust for LS problems
+	// than forming A^T A explicitly (which can worsen conditioning).
+	var qr mat.QR
+	qr.Factorize(A)
+
+	// Rank-deficient systems (collinear sensors, duplicates) have no unique solution
+	cond := qr.Cond()
+	var r mat.Dense
+	qr.RTo(&r)
+	diagonal := make([]float64, cols)
+	for i := range diagonal {
+		diagonal[i] = r.At(i, i)
+	}
+	if err := checkConditioning(diagonal, cond); err != nil {
+		return nil, 0, cond, err
+	}
+
+	var x mat.VecDense
+	err := qr.SolveVecTo(&x, false, b) // Solves min ||Ax - b||_2
+	if err != nil {
+		// This might happen if A is severely ill-conditioned or has zero columns etc.
+		return nil, 0, cond, fmt.Errorf("QR least squares solve failed: %w", err)
+	}
+
+	// --- Calculate Residual Error ---
+	var residualVec mat.VecDense
+	residualVec.MulVec(A, &x)           // residualVec = A*x
+	residualVec.SubVec(b, &residualVec) // residualVec = b - A*x
+	// Use blas64 directly for norm calculation
+	residualNorm := blas64.Nrm2(residualVec.RawVector())
+
+	return common.FromVecDense(&x), residualNorm, cond, nil
+}
+
+// linearWorkspace holds the QR buffers of Solver, so repeated solves of systems up to
+// the size it was made for do not allocate.
+type linearWorkspace struct {
+	tau      []float64
+	work     []float64
+	condWork []float64
+	iwork    []int
+	diagonal []float64 // Diagonal of R, for the rank
+}
+
+// newLinearWorkspace sizes the LAPACK workspaces for systems up to rows x cols.
+func newLinearWorkspace(rows, cols int) linearWorkspace {
+	w := linearWorkspace{tau: make([]float64, cols), condWork: make([]float64, 3*cols), iwork: make([]int, cols), diagonal: make([]float64, cols)}
+	a := blas64.General{Rows: rows, Cols: cols, Stride: cols, Data: make([]float64, rows*cols)}
+	c := blas64.General{Rows: rows, Cols: 1, Stride: 1, Data: make([]float64, rows)}
+	query := []float64{0}
+	lapack64.Geqrf(a, w.tau, query, -1)
+	size := int(query[0])
+	lapack64.Ormqr(blas.Left, blas.Trans, a, w.tau, c, query, -1)
+	w.work = make([]float64, max(size, int(query[0]), 1))
+	return w
+}
+
+// solve is solveLinearSystem without allocations: it factorizes aData in place,
+// overwrites bData and writes the solution to x. It returns the residual norm and the
+// condition number.
+func (w *linearWorkspace) solve(aData, bData []float64, rows, cols int, x []float64) (float64, float64, error) {
+	a := blas64.General{Rows: rows, Cols: cols, Stride: cols, Data: aData[:rows*cols]}
+	lapack64.Geqrf(a, w.tau, w.work, len(w.work))
+	r := blas64.Triangular{Uplo: blas.Upper, Diag: blas.NonUnit, N: cols, Stride: cols, Data: aData[:cols*cols]}
+	cond := 1 / lapack64.Trcon(mat.CondNorm, r, w.condWork, w.iwork)
+	for i := range w.diagonal {
+		w.diagonal[i] = aData[i*cols+i]
+	}
+	if err := checkConditioning(w.diagonal[:cols], cond); err != nil {
+		return 0, cond, err
+	}
+
+	// Q^T b: the first cols entries feed R x, the rest is the residual
+	c := blas64.General{Rows: rows, Cols: 1, Stride: 1, Data: bData[:rows]}
+	lapack64.Ormqr(blas.Left, blas.Trans, a, w.tau, c, w.work, len(w.work))
+	if !lapack64.Trtrs(blas.NoTrans, r, blas64.General{Rows: cols, Cols: 1, Stride: 1, Data: bData[:cols]}) {
+		return 0, math.Inf(1), fmt.Errorf("%w: triangular factor is singular", ErrDegenerateGeometry)
+	}
+	copy(x, bData[:cols])
+	return blas64.Nrm2(blas64.Vector{N: rows - cols, Inc: 1, Data: bData[cols:rows]}), cond, nil
+}
@@ -0,0 +1,75 @@
+//go:build lite
+
+// The "lite" build tag swaps the gonum QR backend for a minimal hand-rolled
+// solver so the localization core can be embedded into small binaries or
+// TinyGo targets:
+//
+//	go build -tags lite ./...
+//
+// Square systems (exactly dimension+1 measurements) are solved in closed form,
+// overdetermined ones through the normal equations A^T A x = A^T b.
+
+package multilateration
+
+import (
+	"fmt"
+	"math"
+)
+
+// solveLinearSystem solves min ||Ax - b||_2 for row-major A (rows x cols).
+// Returns x, the residual norm ||Ax - b|| and the condition number of A.
+func solveLinearSystem(aData, bData []float64, rows, cols int) ([]float64, float64, float64, error) {
+	w := newLinearWorkspace(rows, cols)
+	x := make([]float64, cols)
+	residualNorm, cond, err := w.solve(aData, bData, rows, cols, x)
+	if err != nil {
+		return nil, 0, cond, err
+	}
+	return x, residualNorm, cond, nil
+}
+
+// linearWorkspace holds the normal-equation buffers of Solver, so repeated solves do
+// not allocate.
+type linearWorkspace struct {
+	ata, atb, scratch, inverse []float64
+}
+
+// newLinearWorkspace sizes the buffers for systems with cols unknowns.
+func newLinearWorkspace(_, cols int) linearWorkspace {
+	return linearWorkspace{
+		ata:     make([]float64, cols*cols),
+		atb:     make([]float64, cols),
+		scratch: make([]float64, cols*cols),
+		inverse: make([]float64, cols*cols),
+	}
+}
+
+// solve is solveLinearSystem writing the solution to x. It returns the residual norm
+// and the condition number.
+func (w *linearWorkspace) solve(aData, bData []float64, rows, cols int, x []float64) (float64, float64, error) {
+	a, b := aData, bData // Closed-form trilateration: the linearized system is square
+	var cond float64
+	if rows == cols {
+		cond = conditionNumberInto(a, cols, w.scratch, w.inverse)
+	} else {
+		// cond(A^T A) = cond(A)^2 in the 2-norm, about so in the 1-norm
+		normalEquationsInto(aData, bData, rows, cols, w.ata, w.atb)
+		a, b = w.ata, w.atb
+		cond = math.Sqrt(conditionNumberInto(a, cols, w.scratch, w.inverse))
+	}
+	if err := checkConditioning(nil, cond); err != nil {
+		return 0, cond, err
+	}
+	if err := solveSquareInto(a, b, cols, w.scratch, x); err != nil {
+		return 0, cond, fmt.Errorf("least squares solve failed: %w", err)
+	}
+	sumSq := 0.0
+	for r := 0; r < rows; r++ {
+		res := bData[r]
+		for c := 0; c < cols; c++ {
+			res -= aData[r*cols+c] * x[c]
+		}
+		sumSq += res * res
+	}
+	return math.Sqrt(sumSq), cond, nil
+}
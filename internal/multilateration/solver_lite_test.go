@@ -0,0 +1,54 @@
+//go:build lite
+
+package multilateration_test
+
+import (
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"testing"
+)
+
+// TestSolveLeastSquaresLiteMatchesGonum checks the hand-rolled solver of the lite build
+// against positions and residuals the gonum build computes for the same noisy ranges.
+func TestSolveLeastSquaresLiteMatchesGonum(t *testing.T) {
+	tests := []struct {
+		name         string
+		measurements []multilateration.Measurement
+		position     common.Vector
+		residual     float64
+	}{
+		{"2D minimal", []multilateration.Measurement{
+			{SensorPosition: common.Vector{0, 0}, Distance: 5.02},
+			{SensorPosition: common.Vector{10, 0}, Distance: 8.05},
+			{SensorPosition: common.Vector{0, 10}, Distance: 6.69},
+		}, common.Vector{3.019895, 4.022215}, 0},
+		{"2D overdetermined", []multilateration.Measurement{
+			{SensorPosition: common.Vector{0, 0}, Distance: 5.02},
+			{SensorPosition: common.Vector{10, 0}, Distance: 8.05},
+			{SensorPosition: common.Vector{0, 10}, Distance: 6.69},
+			{SensorPosition: common.Vector{10, 10}, Distance: 9.18},
+		}, common.Vector{3.022755, 4.025075}, 0.0286},
+		{"3D overdetermined", []multilateration.Measurement{
+			{SensorPosition: common.Vector{0, 0, 0}, Distance: 8.80},
+			{SensorPosition: common.Vector{20, 0, 0}, Distance: 18.06},
+			{SensorPosition: common.Vector{0, 20, 0}, Distance: 17.13},
+			{SensorPosition: common.Vector{0, 0, 20}, Distance: 16.31},
+			{SensorPosition: common.Vector{20, 20, 20}, Distance: 27.1},
+		}, common.Vector{3.742552142857, 4.560719642857, 5.246239642857}, 0.694207347576},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sol, err := multilateration.SolveLeastSquares(tt.measurements, tt.position.Dimension())
+			if err != nil {
+				t.Fatalf("SolveLeastSquares: %v", err)
+			}
+			if d, _ := sol.Position.Distance(tt.position); d > 1e-9 {
+				t.Errorf("position %v is %g from the gonum solution %v", sol.Position, d, tt.position)
+			}
+			if math.Abs(sol.ResidualError-tt.residual) > 1e-9 {
+				t.Errorf("residual %g, gonum gives %g", sol.ResidualError, tt.residual)
+			}
+		})
+	}
+}
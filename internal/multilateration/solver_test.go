@@ -0,0 +1,198 @@
+package multilateration_test
+
+import (
+	"errors"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"testing"
+)
+
+// exactMeasurements returns noiseless ranges from every sensor to target.
+func exactMeasurements(t *testing.T, sensors []common.Vector, target common.Vector) []multilateration.Measurement {
+	t.Helper()
+	measurements := make([]multilateration.Measurement, len(sensors))
+	for i, s := range sensors {
+		d, err := s.Distance(target)
+		if err != nil {
+			t.Fatalf("distance of sensor %d: %v", i, err)
+		}
+		measurements[i] = multilateration.Measurement{SensorPosition: s, Distance: d}
+	}
+	return measurements
+}
+
+// simplex returns the origin and the unit vectors scaled by size: dim+1 sensors in
+// general position.
+func simplex(dim int, size float64) []common.Vector {
+	sensors := []common.Vector{common.NewVector(dim)}
+	for i := 0; i < dim; i++ {
+		s := common.NewVector(dim)
+		s[i] = size
+		sensors = append(sensors, s)
+	}
+	return sensors
+}
+
+func TestSolveLeastSquaresExact(t *testing.T) {
+	tests := []struct {
+		name    string
+		sensors []common.Vector
+		target  common.Vector
+	}{
+		{"2D minimal", []common.Vector{{0, 0}, {10, 0}, {0, 10}}, common.Vector{3, 4}},
+		{"2D overdetermined", []common.Vector{{0, 0}, {10, 0}, {0, 10}, {10, 10}, {5, -5}}, common.Vector{3, 4}},
+		{"2D target outside the hull", []common.Vector{{0, 0}, {10, 0}, {0, 10}, {10, 10}}, common.Vector{-25, 40}},
+		{"2D target on a sensor", []common.Vector{{0, 0}, {10, 0}, {0, 10}, {10, 10}}, common.Vector{10, 0}},
+		{"3D minimal", simplex(3, 20), common.Vector{4, 5, 6}},
+		{"3D cube corners", []common.Vector{
+			{0, 0, 0}, {10, 0, 0}, {0, 10, 0}, {0, 0, 10}, {10, 10, 0}, {10, 0, 10}, {0, 10, 10}, {10, 10, 10},
+		}, common.Vector{2, 7, 3}},
+		{"3D large coordinates", []common.Vector{
+			{1e4, 1e4, 0}, {1e4 + 500, 1e4, 0}, {1e4, 1e4 + 500, 0}, {1e4, 1e4, 500}, {1e4 + 500, 1e4 + 500, 500},
+		}, common.Vector{1e4 + 120, 1e4 + 340, 60}},
+		{"5D minimal", simplex(5, 10), common.Vector{1, 2, 3, 4, 5}},
+		{"5D overdetermined", append(simplex(5, 10), simplex(5, -10)[1:]...), common.Vector{-2, 0.5, 7, 1, -3}},
+		{"duplicate sensor with enough others", []common.Vector{{0, 0}, {0, 0}, {10, 0}, {0, 10}}, common.Vector{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dim := tt.target.Dimension()
+			sol, err := multilateration.SolveLeastSquares(exactMeasurements(t, tt.sensors, tt.target), dim)
+			if err != nil {
+				t.Fatalf("SolveLeastSquares: %v", err)
+			}
+			if sol.Position.Dimension() != dim {
+				t.Fatalf("position has dimension %d, want %d", sol.Position.Dimension(), dim)
+			}
+			// Relative to the scene size, the squared ranges lose digits far from the origin
+			tolerance := 1e-6 * math.Max(1, math.Sqrt(tt.target.NormSq()))
+			if d, _ := sol.Position.Distance(tt.target); d > tolerance {
+				t.Errorf("position %v, want %v (off by %g)", sol.Position, tt.target, d)
+			}
+			if sol.ResidualError > tolerance {
+				t.Errorf("residual %g of noiseless ranges, want about 0", sol.ResidualError)
+			}
+		})
+	}
+}
+
+func TestSolveLeastSquaresDOP(t *testing.T) {
+	// Symmetric square around the target: GDOP = sqrt(trace((H^T H)^-1)) = sqrt(2/4 + 2/4) = 1
+	sensors := []common.Vector{{-10, 0}, {10, 0}, {0, -10}, {0, 10}}
+	target := common.Vector{0, 0}
+	sol, err := multilateration.SolveLeastSquares(exactMeasurements(t, sensors, target), 2)
+	if err != nil {
+		t.Fatalf("SolveLeastSquares: %v", err)
+	}
+	if math.Abs(sol.GDOP-1) > 1e-9 || math.Abs(sol.HDOP-1) > 1e-9 || sol.VDOP != 0 {
+		t.Errorf("DOP %g/%g/%g, want 1/1/0", sol.GDOP, sol.HDOP, sol.VDOP)
+	}
+}
+
+func TestSolveLeastSquaresNoise(t *testing.T) {
+	// Ranges off by at most 0.01 move a well-conditioned estimate by about as much
+	sensors := []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}}
+	target := common.Vector{40, 60}
+	measurements := exactMeasurements(t, sensors, target)
+	for i := range measurements {
+		measurements[i].Distance += 0.01 * float64(1-2*(i%2))
+	}
+	sol, err := multilateration.SolveLeastSquares(measurements, 2)
+	if err != nil {
+		t.Fatalf("SolveLeastSquares: %v", err)
+	}
+	if d, _ := sol.Position.Distance(target); d > 0.05 {
+		t.Errorf("position %v is %g from %v, want within 0.05", sol.Position, d, target)
+	}
+	if sol.ResidualError == 0 {
+		t.Error("residual of inconsistent ranges is 0")
+	}
+}
+
+func TestSolveLeastSquaresErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		sensors    []common.Vector
+		target     common.Vector
+		dimension  int
+		degenerate bool // The error is ErrDegenerateGeometry, otherwise ErrInsufficientMeasurements
+	}{
+		{"no measurements", nil, common.Vector{1, 1}, 2, false},
+		{"too few in 2D", []common.Vector{{0, 0}, {10, 0}}, common.Vector{3, 4}, 2, false},
+		{"too few in 5D", simplex(5, 10)[:5], common.Vector{1, 2, 3, 4, 5}, 5, false},
+		{"collinear in 2D", []common.Vector{{0, 0}, {10, 0}, {20, 0}, {30, 0}}, common.Vector{3, 4}, 2, true},
+		{"coplanar in 3D", []common.Vector{{0, 0, 0}, {10, 0, 0}, {0, 10, 0}, {10, 10, 0}}, common.Vector{3, 4, 5}, 3, true},
+		{"duplicate sensors in 2D", []common.Vector{{0, 0}, {0, 0}, {10, 0}}, common.Vector{3, 4}, 2, true},
+		{"all sensors in one place", []common.Vector{{5, 5}, {5, 5}, {5, 5}, {5, 5}}, common.Vector{3, 4}, 2, true},
+		{"nearly collinear in 2D", []common.Vector{{0, 0}, {10, 1e-11}, {20, 0}, {30, -1e-11}}, common.Vector{3, 4}, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sol, err := multilateration.SolveLeastSquares(exactMeasurements(t, tt.sensors, tt.target), tt.dimension)
+			if err == nil {
+				t.Fatalf("SolveLeastSquares returned %v, want an error", sol.Position)
+			}
+			if sol.Position != nil {
+				t.Errorf("position %v returned with error %v", sol.Position, err)
+			}
+			if got := errors.Is(err, multilateration.ErrDegenerateGeometry); got != tt.degenerate {
+				t.Errorf("errors.Is(%v, ErrDegenerateGeometry) = %t, want %t", err, got, tt.degenerate)
+			}
+			if got := errors.Is(err, multilateration.ErrInsufficientMeasurements); got == tt.degenerate {
+				t.Errorf("errors.Is(%v, ErrInsufficientMeasurements) = %t, want %t", err, got, !tt.degenerate)
+			}
+			if !tt.degenerate || tt.dimension+1 > len(tt.sensors) {
+				return
+			}
+			solver, _ := multilateration.NewSolver(len(tt.sensors), tt.dimension)
+			var dst multilateration.Solution
+			if err := solver.SolveInto(&dst, exactMeasurements(t, tt.sensors, tt.target)); !errors.Is(err, multilateration.ErrDegenerateGeometry) {
+				t.Errorf("Solver.SolveInto: %v, want ErrDegenerateGeometry", err)
+			}
+		})
+	}
+}
+
+func TestSolveLeastSquaresConditionNumber(t *testing.T) {
+	target := common.Vector{3, 4}
+	wide := []common.Vector{{0, 0}, {10, 0}, {0, 10}, {10, 10}}
+	narrow := []common.Vector{{0, 0}, {10, 0}, {20, 0.5}, {30, 0}}
+	wideSol, err := multilateration.SolveLeastSquares(exactMeasurements(t, wide, target), 2)
+	if err != nil {
+		t.Fatalf("wide geometry: %v", err)
+	}
+	narrowSol, err := multilateration.SolveLeastSquares(exactMeasurements(t, narrow, target), 2)
+	if err != nil {
+		t.Fatalf("narrow geometry: %v", err)
+	}
+	if wideSol.ConditionNumber < 1 || wideSol.ConditionNumber > 10 {
+		t.Errorf("condition number of a square layout %g, want within [1, 10]", wideSol.ConditionNumber)
+	}
+	if narrowSol.ConditionNumber < 10*wideSol.ConditionNumber {
+		t.Errorf("condition number of nearly collinear sensors %g, not well above %g", narrowSol.ConditionNumber, wideSol.ConditionNumber)
+	}
+
+	solver, _ := multilateration.NewSolver(4, 2)
+	var dst multilateration.Solution
+	if err := solver.SolveInto(&dst, exactMeasurements(t, narrow, target)); err != nil {
+		t.Fatalf("Solver.SolveInto: %v", err)
+	}
+	if dst.ConditionNumber != narrowSol.ConditionNumber {
+		t.Errorf("Solver condition number %g, SolveLeastSquares %g", dst.ConditionNumber, narrowSol.ConditionNumber)
+	}
+}
+
+func TestSolveLeastSquaresDimensionMismatch(t *testing.T) {
+	measurements := []multilateration.Measurement{
+		{SensorPosition: common.Vector{0, 0}, Distance: 5},
+		{SensorPosition: common.Vector{10, 0, 0}, Distance: 5},
+		{SensorPosition: common.Vector{0, 10}, Distance: 5},
+	}
+	if _, err := multilateration.SolveLeastSquares(measurements, 2); !errors.Is(err, multilateration.ErrDimensionMismatch) {
+		t.Errorf("sensor of the wrong dimension: %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := multilateration.SolveLeastSquares(measurements[:0:0], 0); err == nil {
+		t.Error("no measurements accepted in 0D")
+	}
+}
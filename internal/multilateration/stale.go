@@ -0,0 +1,48 @@
+package multilateration
+
+// Helpers for asynchronous measurement sets: sensors measure at different rates and
+// with different latencies, so a solver input may mix fresh and stale ranges.
+
+// DiscardStale returns the measurements taken no earlier than maxAge seconds before now.
+func DiscardStale(measurements []Measurement, now, maxAge float64) []Measurement {
+	fresh := make([]Measurement, 0, len(measurements))
+	for _, m := range measurements {
+		if now-m.Timestamp <= maxAge {
+			fresh = append(fresh, m)
+		}
+	}
+	return fresh
+}
+
+// LatestPerSensor keeps only the newest measurement of each sensor, preserving the
+// order of first appearance. Measurements without a SensorID are all kept.
+func LatestPerSensor(measurements []Measurement) []Measurement {
+	index := make(map[string]int, len(measurements))
+	latest := make([]Measurement, 0, len(measurements))
+	for _, m := range measurements {
+		if m.SensorID == "" {
+			latest = append(latest, m)
+			continue
+		}
+		if i, seen := index[m.SensorID]; seen {
+			if m.Timestamp > latest[i].Timestamp {
+				latest[i] = m
+			}
+			continue
+		}
+		index[m.SensorID] = len(latest)
+		latest = append(latest, m)
+	}
+	return latest
+}
+
+// MaxAge returns the age of the oldest measurement relative to now.
+func MaxAge(measurements []Measurement, now float64) float64 {
+	maxAge := 0.0
+	for _, m := range measurements {
+		if age := now - m.Timestamp; age > maxAge {
+			maxAge = age
+		}
+	}
+	return maxAge
+}
@@ -0,0 +1,55 @@
+package multilateration
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+)
+
+// EstimateVelocity estimates the target velocity from the range-rate (Doppler) part of the
+// measurements, given the target position. Each range-rate constrains the velocity along
+// the line of sight: u_i . (v - v_si) = rr_i, with u_i the unit vector from sensor i to
+// the target. Needs at least dimension measurements with HasRangeRate set.
+func EstimateVelocity(measurements []Measurement, position common.Vector) (common.Vector, error) {
+	dimension := position.Dimension()
+	aData := make([]float64, 0, len(measurements)*dimension)
+	bData := make([]float64, 0, len(measurements))
+	for _, m := range measurements {
+		if !m.HasRangeRate {
+			continue
+		}
+		los, err := position.Subtract(m.SensorPosition)
+		if err != nil {
+			return nil, fmt.Errorf("dimension mismatch estimating velocity: %w", err)
+		}
+		norm := los.Norm()
+		if norm == 0 {
+			continue // Line of sight undefined
+		}
+		rhs := m.RangeRate
+		for k := 0; k < dimension; k++ {
+			u := los[k] / norm
+			aData = append(aData, u)
+			if k < len(m.SensorVelocity) {
+				rhs += u * m.SensorVelocity[k]
+			}
+		}
+		bData = append(bData, rhs)
+	}
+	rows := len(bData)
+	if rows < dimension {
+		return nil, fmt.Errorf("%w of range-rate: got %d, need at least %d", ErrInsufficientMeasurements, rows, dimension)
+	}
+	ata, atb := normalEquations(aData, bData, rows, dimension)
+	v, err := solveSquare(ata, atb, dimension)
+	if err != nil {
+		return nil, fmt.Errorf("velocity geometry is singular: %w", err)
+	}
+	return common.Vector(v), nil
+}
+
+// attachVelocity fills Solution.Velocity when the measurements carry enough range-rates.
+func attachVelocity(solution *Solution, measurements []Measurement) {
+	if velocity, err := EstimateVelocity(measurements, solution.Position); err == nil {
+		solution.Velocity = velocity
+	}
+}
@@ -0,0 +1,121 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// SolveWeightedLeastSquares estimates the position weighting every range by the inverse
+// of its Measurement.Variance, so precise sensors dominate noisy ones. Measurements
+// without a variance get the mean variance of the others (all equal if none is known).
+//
+// The linearized system is referenced to the most precise measurement and its rows are
+// weighted by the propagated variance of the squared ranges; the result is then refined
+// with weighted Gauss-Newton on the nonlinear range equations.
+func SolveWeightedLeastSquares(measurements []Measurement, dimension int) (Solution, error) {
+	numMeasurements := len(measurements)
+	if numMeasurements < dimension+1 {
+		return Solution{}, fmt.Errorf("%w: got %d, need at least %d for dimension %d for this LS method", ErrInsufficientMeasurements, numMeasurements, dimension+1, dimension)
+	}
+	variances := measurementVariances(measurements)
+
+	// Reference = most precise measurement, moved to the end as buildLinearSystem expects
+	ordered := make([]Measurement, numMeasurements)
+	copy(ordered, measurements)
+	orderedVar := make([]float64, numMeasurements)
+	copy(orderedVar, variances)
+	ref := 0
+	for i, v := range variances {
+		if v < variances[ref] {
+			ref = i
+		}
+	}
+	last := numMeasurements - 1
+	ordered[ref], ordered[last] = ordered[last], ordered[ref]
+	orderedVar[ref], orderedVar[last] = orderedVar[last], orderedVar[ref]
+
+	aData, bData, err := buildLinearSystem(ordered, dimension)
+	if err != nil {
+		return Solution{}, err
+	}
+	// Var(d^2) ~ 4 d^2 sigma^2, and every row is the difference of two squared ranges
+	refDist := ordered[last].Distance
+	for i := 0; i < last; i++ {
+		d := ordered[i].Distance
+		rowVar := 4 * (d*d*orderedVar[i] + refDist*refDist*orderedVar[last])
+		scale := 1 / math.Sqrt(math.Max(rowVar, 1e-12))
+		bData[i] *= scale
+		for j := 0; j < dimension; j++ {
+			aData[i*dimension+j] *= scale
+		}
+	}
+	x0, _, _, err := solveLinearSystem(aData, bData, last, dimension)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	// Nonlinear refinement with weights 1/variance
+	weights := make([]float64, numMeasurements)
+	for i, v := range variances {
+		weights[i] = 1 / v
+	}
+	x := common.Vector(x0)
+	residuals := make([]float64, numMeasurements)
+	jData := make([]float64, numMeasurements*dimension)
+	for iter := 0; iter < DefaultRobustOptions().MaxIterations; iter++ {
+		if err := rangeJacobian(measurements, x, common.Euclidean{}, residuals, jData); err != nil {
+			return Solution{}, fmt.Errorf("dimension mismatch in weighted solver: %w", err)
+		}
+		next, stepNorm, ok := weightedGaussNewtonStep(measurements, x, common.Euclidean{}, residuals, jData, weights)
+		if !ok {
+			break
+		}
+		x = next
+		if stepNorm < DefaultRobustOptions().Tolerance {
+			break
+		}
+	}
+
+	// Normalized residual: RMS of the range residuals in units of their standard deviation
+	solution := Solution{
+		Position:      x,
+		ResidualError: math.Sqrt(weightedCost(measurements, x, common.Euclidean{}, weights) / float64(numMeasurements)),
+	}
+	sensorPositions := make([]common.Vector, numMeasurements)
+	for i, m := range measurements {
+		sensorPositions[i] = m.SensorPosition
+	}
+	if dop, dopErr := ComputeDOP(sensorPositions, x); dopErr == nil {
+		solution.GDOP, solution.HDOP, solution.VDOP = dop.GDOP, dop.HDOP, dop.VDOP
+	} else {
+		solution.GDOP, solution.HDOP, solution.VDOP = math.Inf(1), math.Inf(1), math.Inf(1)
+	}
+	solution.Covariance = estimateCovariance(measurements, x, common.Euclidean{}, weights)
+	attachVelocity(&solution, measurements)
+	return solution, nil
+}
+
+// measurementVariances returns the range variance of every measurement, filling
+// unknown (non-positive) variances with the mean of the known ones, or 1.
+func measurementVariances(measurements []Measurement) []float64 {
+	sum, known := 0.0, 0
+	for _, m := range measurements {
+		if m.Variance > 0 {
+			sum += m.Variance
+			known++
+		}
+	}
+	fallback := 1.0
+	if known > 0 {
+		fallback = sum / float64(known)
+	}
+	variances := make([]float64, len(measurements))
+	for i, m := range measurements {
+		variances[i] = fallback
+		if m.Variance > 0 {
+			variances[i] = m.Variance
+		}
+	}
+	return variances
+}
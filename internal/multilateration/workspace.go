@@ -0,0 +1,136 @@
+package multilateration
+
+import (
+	"fmt"
+	"math"
+)
+
+// Solver is SolveLeastSquares with preallocated workspaces, for callers that solve for
+// many targets every tick. SolveInto does not allocate once the workspaces fit the
+// measurement count and dst has been used before; more measurements than maxSensors
+// grow them. A Solver is not safe for concurrent use.
+type Solver struct {
+	dimension  int
+	maxSensors int
+
+	aData, bData []float64 // Linearized system, (m-1) x n
+	hData        []float64 // Range Jacobian (unit lines of sight), m x n
+	normal       []float64 // H^T H
+	unused       []float64 // A^T b slot of normalEquationsInto
+	cofactor     []float64 // (H^T H)^-1
+	scratch      []float64
+	linear       linearWorkspace
+}
+
+// NewSolver creates a solver for the given dimension with workspaces sized for up to
+// maxSensors measurements per solve.
+func NewSolver(maxSensors, dimension int) (*Solver, error) {
+	if dimension < 1 {
+		return nil, fmt.Errorf("%w: dimension must be at least 1, got %d", ErrOutOfRange, dimension)
+	}
+	if maxSensors < dimension+1 {
+		return nil, fmt.Errorf("%w: maxSensors must be at least dimension + 1 = %d, got %d", ErrOutOfRange, dimension+1, maxSensors)
+	}
+	s := &Solver{
+		dimension: dimension,
+		normal:    make([]float64, dimension*dimension),
+		unused:    make([]float64, dimension),
+		cofactor:  make([]float64, dimension*dimension),
+		scratch:   make([]float64, dimension*dimension),
+	}
+	s.grow(maxSensors)
+	return s, nil
+}
+
+// Dimension returns the dimension the solver was made for.
+func (s *Solver) Dimension() int {
+	return s.dimension
+}
+
+// grow sizes the per-measurement workspaces for m measurements.
+func (s *Solver) grow(m int) {
+	s.maxSensors = m
+	s.aData = make([]float64, (m-1)*s.dimension)
+	s.bData = make([]float64, m-1)
+	s.hData = make([]float64, m*s.dimension)
+	s.linear = newLinearWorkspace(m-1, s.dimension)
+}
+
+// SolveInto computes what SolveLeastSquares would and stores it in dst. The Position and
+// Covariance of dst are overwritten in place when their sizes fit, so copy them if they
+// must outlive the next call. Velocity from range-rates is estimated (and allocated)
+// only when measurements carry them.
+func (s *Solver) SolveInto(dst *Solution, measurements []Measurement) error {
+	m, n := len(measurements), s.dimension
+	if m < n+1 {
+		return fmt.Errorf("%w: got %d, need at least %d for dimension %d for this LS method", ErrInsufficientMeasurements, m, n+1, n)
+	}
+	if m > s.maxSensors {
+		s.grow(m)
+	}
+	if err := buildLinearSystemInto(measurements, n, s.aData, s.bData); err != nil {
+		return err
+	}
+	if cap(dst.Position) < n {
+		dst.Position = make([]float64, n)
+	}
+	position := dst.Position[:n]
+	residualNorm, cond, err := s.linear.solve(s.aData, s.bData, m-1, n, position)
+	if err != nil {
+		return err
+	}
+	dst.Position = position
+	dst.ResidualError = residualNorm / math.Sqrt(float64(m-1))
+	dst.ConditionNumber = cond
+	dst.Weights, dst.Velocity, dst.ClockBias = nil, nil, 0
+
+	// Geometry: DOP and covariance share Q = (H^T H)^-1 of the unit lines of sight
+	coincident, sumSq, hasRangeRate := false, 0.0, false
+	for i, meas := range measurements {
+		row := s.hData[i*n : (i+1)*n]
+		dist := 0.0
+		for j := range row {
+			row[j] = position[j] - meas.SensorPosition[j]
+			dist += row[j] * row[j]
+		}
+		dist = math.Sqrt(dist)
+		for j := range row {
+			if dist > 0 {
+				row[j] /= dist
+			} else {
+				row[j] = 0 // On top of the sensor, where the direction is undefined
+			}
+		}
+		coincident = coincident || dist == 0
+		sumSq += (meas.Distance - dist) * (meas.Distance - dist)
+		hasRangeRate = hasRangeRate || meas.HasRangeRate
+	}
+	normalEquationsInto(s.hData, nil, m, n, s.normal, s.unused)
+	singular := invertSquareInto(s.normal, n, s.scratch, s.cofactor) != nil
+	if coincident || singular {
+		dst.GDOP, dst.HDOP, dst.VDOP = math.Inf(1), math.Inf(1), math.Inf(1)
+	} else {
+		dop := cofactorDOP(s.cofactor, n)
+		dst.GDOP, dst.HDOP, dst.VDOP = dop.GDOP, dop.HDOP, dop.VDOP
+	}
+	if singular || m <= n {
+		dst.Covariance = nil
+	} else {
+		if len(dst.Covariance) != n {
+			dst.Covariance = make([][]float64, n)
+		}
+		variance := sumSq / float64(m-n)
+		for i := range dst.Covariance {
+			if len(dst.Covariance[i]) != n {
+				dst.Covariance[i] = make([]float64, n)
+			}
+			for j := range dst.Covariance[i] {
+				dst.Covariance[i][j] = variance * s.cofactor[i*n+j]
+			}
+		}
+	}
+	if hasRangeRate {
+		attachVelocity(dst, measurements)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package network_test
+
+import (
+	"fmt"
+	"math/rand"
+	"multilateration-sim/internal/network"
+)
+
+// A sensor on a 100 byte/s uplink sends three 50 byte reports at once: they queue and
+// arrive half a second apart after the latency.
+func ExampleNetwork_Send() {
+	n, _ := network.New(network.Config{Bandwidth: 100, Latency: 0.2}, rand.New(rand.NewSource(1)))
+	for i := range 3 {
+		arrival, ok := n.Send(network.Message{From: "sensor", To: network.FusionCenter, Size: 50, Payload: i})
+		fmt.Printf("report %d arrives at %.1fs (sent %t)\n", i, arrival, ok)
+	}
+	for _, msg := range n.Deliver(1) {
+		fmt.Println("delivered by 1s:", msg.Payload)
+	}
+	fmt.Println("in flight:", n.InFlight())
+	// Output:
+	// report 0 arrives at 0.7s (sent true)
+	// report 1 arrives at 1.2s (sent true)
+	// report 2 arrives at 1.7s (sent true)
+	// delivered by 1s: 0
+	// in flight: 2
+}
+
+// A full uplink queue drops new messages.
+func ExampleConfig_queueLimit() {
+	n, _ := network.New(network.Config{Bandwidth: 10, QueueLimit: 2}, rand.New(rand.NewSource(1)))
+	for range 4 {
+		n.Send(network.Message{From: "sensor", Size: 10})
+	}
+	fmt.Printf("%+v\n", n.Stats())
+	// Output:
+	// {Sent:4 Dropped:2 Delivered:0 Bytes:40}
+}
@@ -0,0 +1,188 @@
+// Package network models message passing between the nodes of a sensor network, e.g.
+// sensors reporting to a fusion center. Every sender has an uplink of limited
+// bandwidth on which its messages queue; once transmitted, a message travels for the
+// latency plus a random jitter, unless it is dropped. Time is simulation time in
+// seconds, supplied by the caller, so the network runs in lockstep with a simulation.
+package network
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"sort"
+)
+
+// FusionCenter is the conventional node ID of the central solver.
+const FusionCenter = "fusion-center"
+
+// ErrOutOfRange is returned (wrapped) for invalid link parameters. It is the sentinel of
+// the other packages, so callers can check for it with the one of the simulation.
+var ErrOutOfRange = common.ErrOutOfRange
+
+// Config describes a link. The zero Config is a perfect, instantaneous link.
+type Config struct {
+	Bandwidth  float64 `json:"bandwidth,omitempty"`   // Bytes per second of a sender's uplink, 0 = unlimited
+	Latency    float64 `json:"latency,omitempty"`     // Seconds a transmitted message travels
+	Jitter     float64 `json:"jitter,omitempty"`      // Largest extra delay in seconds, drawn uniformly per message
+	DropRate   float64 `json:"drop_rate,omitempty"`   // Probability that a message is lost
+	QueueLimit int     `json:"queue_limit,omitempty"` // Messages waiting on an uplink beyond which new ones are dropped, 0 = unlimited
+}
+
+// Validate checks the parameters.
+func (c Config) Validate() error {
+	switch {
+	case c.Bandwidth < 0:
+		return fmt.Errorf("%w: bandwidth must be non-negative, got %f", ErrOutOfRange, c.Bandwidth)
+	case c.Latency < 0:
+		return fmt.Errorf("%w: latency must be non-negative, got %f", ErrOutOfRange, c.Latency)
+	case c.Jitter < 0:
+		return fmt.Errorf("%w: jitter must be non-negative, got %f", ErrOutOfRange, c.Jitter)
+	case c.DropRate < 0 || c.DropRate > 1:
+		return fmt.Errorf("%w: drop rate must be within [0, 1], got %f", ErrOutOfRange, c.DropRate)
+	case c.QueueLimit < 0:
+		return fmt.Errorf("%w: queue limit must be non-negative, got %d", ErrOutOfRange, c.QueueLimit)
+	}
+	return nil
+}
+
+// Message is a unit of communication. Payload is carried as is.
+type Message struct {
+	From, To string
+	Size     int     // Bytes, sets the transmission time on a limited uplink
+	Sent     float64 // Time the sender hands the message to its uplink
+	Payload  any
+}
+
+// Stats counts the traffic of a network.
+type Stats struct {
+	Sent      int // Messages handed to the network
+	Dropped   int // Lost on a link or to a full queue
+	Delivered int // Returned by Deliver
+	Bytes     int // Bytes of the messages handed to the network
+}
+
+// inFlight is a message with its arrival time.
+type inFlight struct {
+	msg     Message
+	arrival float64
+	seq     int64 // Send order, breaks ties between equal arrivals
+}
+
+// Network carries messages between nodes. It is not safe for concurrent use.
+type Network struct {
+	config  Config
+	links   map[string]Config    // Sender -> link overriding config
+	uplinks map[string][]float64 // Sender -> transmission end times of the queued messages
+	flight  []inFlight
+	nextSeq int64
+	rng     *rand.Rand
+	stats   Stats
+}
+
+// New creates a network whose links all use config. rng draws the jitter and drops.
+func New(config Config, rng *rand.Rand) (*Network, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if rng == nil {
+		return nil, errors.New("network needs a random generator")
+	}
+	return &Network{config: config, links: make(map[string]Config), uplinks: make(map[string][]float64), rng: rng}, nil
+}
+
+// Config returns the default link configuration.
+func (n *Network) Config() Config {
+	return n.config
+}
+
+// SetLink overrides the link of one sender, e.g. a sensor on a slow radio.
+func (n *Network) SetLink(from string, config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	n.links[from] = config
+	return nil
+}
+
+// Link returns the link configuration of a sender.
+func (n *Network) Link(from string) Config {
+	if c, ok := n.links[from]; ok {
+		return c
+	}
+	return n.config
+}
+
+// SetRand replaces the generator of jitter and drops, e.g. after reseeding.
+func (n *Network) SetRand(rng *rand.Rand) {
+	n.rng = rng
+}
+
+// Send hands a message to the uplink of its sender at msg.Sent. It returns the time the
+// message will arrive, or false if it was dropped. Messages of one sender are
+// transmitted one after another in the order they are sent.
+func (n *Network) Send(msg Message) (float64, bool) {
+	link := n.Link(msg.From)
+	n.stats.Sent++
+	n.stats.Bytes += msg.Size
+
+	// Forget the messages that have left the uplink by now
+	queue := n.uplinks[msg.From]
+	for len(queue) > 0 && queue[0] <= msg.Sent {
+		queue = queue[1:]
+	}
+	if link.QueueLimit > 0 && len(queue) >= link.QueueLimit {
+		n.uplinks[msg.From] = queue
+		n.stats.Dropped++
+		return 0, false
+	}
+	start := msg.Sent
+	if len(queue) > 0 {
+		start = max(start, queue[len(queue)-1])
+	}
+	end := start
+	if link.Bandwidth > 0 {
+		end += float64(msg.Size) / link.Bandwidth
+	}
+	n.uplinks[msg.From] = append(queue, end)
+
+	if link.DropRate > 0 && n.rng.Float64() < link.DropRate {
+		n.stats.Dropped++
+		return 0, false
+	}
+	arrival := end + link.Latency
+	if link.Jitter > 0 {
+		arrival += n.rng.Float64() * link.Jitter
+	}
+	n.flight = append(n.flight, inFlight{msg: msg, arrival: arrival, seq: n.nextSeq})
+	n.nextSeq++
+	return arrival, true
+}
+
+// Deliver removes and returns the messages that have arrived by now, in arrival order.
+func (n *Network) Deliver(now float64) []Message {
+	sort.SliceStable(n.flight, func(i, j int) bool {
+		if n.flight[i].arrival != n.flight[j].arrival {
+			return n.flight[i].arrival < n.flight[j].arrival
+		}
+		return n.flight[i].seq < n.flight[j].seq
+	})
+	count := sort.Search(len(n.flight), func(i int) bool { return n.flight[i].arrival > now })
+	delivered := make([]Message, count)
+	for i := range count {
+		delivered[i] = n.flight[i].msg
+	}
+	n.flight = append(n.flight[:0], n.flight[count:]...)
+	n.stats.Delivered += count
+	return delivered
+}
+
+// InFlight returns the number of messages on their way.
+func (n *Network) InFlight() int {
+	return len(n.flight)
+}
+
+// Stats returns the traffic counters.
+func (n *Network) Stats() Stats {
+	return n.stats
+}
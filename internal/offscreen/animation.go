@@ -0,0 +1,175 @@
+package offscreen
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// frameQueueSize is how many frames FrameWriter buffers before Write blocks.
+const frameQueueSize = 16
+
+// FrameWriter saves images as numbered PNG files (frame_00000.png, ...) in the
+// background, so a renderer can hand over every frame without waiting for the encoder.
+// Write blocks only while the queue is full, so no frame is dropped.
+type FrameWriter struct {
+	dir    string
+	queue  chan *image.RGBA
+	done   chan struct{}
+	mu     sync.Mutex
+	paths  []string
+	err    error // First write error; later frames are discarded
+	closed bool
+}
+
+// NewFrameWriter creates dir if needed and starts the background encoder.
+func NewFrameWriter(dir string) (*FrameWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create frame directory: %w", err)
+	}
+	w := &FrameWriter{dir: dir, queue: make(chan *image.RGBA, frameQueueSize), done: make(chan struct{})}
+	go w.run()
+	return w, nil
+}
+
+func (w *FrameWriter) run() {
+	defer close(w.done)
+	for img := range w.queue {
+		w.mu.Lock()
+		failed, index := w.err != nil, len(w.paths)
+		w.mu.Unlock()
+		if failed {
+			continue
+		}
+		path := filepath.Join(w.dir, fmt.Sprintf("frame_%05d.png", index))
+		err := WritePNG(path, img)
+		w.mu.Lock()
+		if err != nil {
+			w.err = err
+		} else {
+			w.paths = append(w.paths, path)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// Write queues a frame. The image is owned by the writer afterwards and must not be
+// modified. It returns the first error of an earlier frame, if any. Write must not be
+// called concurrently with Close.
+func (w *FrameWriter) Write(img *image.RGBA) error {
+	w.mu.Lock()
+	err, closed := w.err, w.closed
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if closed {
+		return fmt.Errorf("frame writer is closed")
+	}
+	w.queue <- img
+	return nil
+}
+
+// Count returns how many frames have been written so far.
+func (w *FrameWriter) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.paths)
+}
+
+// Close waits for the queued frames to be written and returns the paths of all frames,
+// oldest first, with the first write error.
+func (w *FrameWriter) Close() ([]string, error) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.done
+	return w.paths, w.err
+}
+
+// Animate renders every step-th frame of the recording as an animated GIF showing each
+// for delay (in steps of 10 ms, at least one). Colours are mapped to the nearest of the
+// Plan 9 palette without dithering, which keeps the flat plot colours clean.
+func Animate(frames []Frame, step int, opts Options, delay time.Duration) (*gif.GIF, error) {
+	if step < 1 {
+		return nil, fmt.Errorf("frame step must be at least 1, got %d", step)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames recorded")
+	}
+	v, err := newView(frames, opts)
+	if err != nil {
+		return nil, err
+	}
+	anim := &gif.GIF{}
+	for i := 0; i < len(frames); i += step {
+		anim.Image = append(anim.Image, paletted(v.render(frames, i, float64(i+1)/float64(len(frames)))))
+		anim.Delay = append(anim.Delay, gifDelay(delay))
+	}
+	return anim, nil
+}
+
+// AssembleGIF reads PNG frames (e.g. from FrameWriter or WriteFrames) one at a time and
+// writes them to path as an animated GIF, see Animate.
+func AssembleGIF(path string, framePaths []string, delay time.Duration) error {
+	if len(framePaths) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+	anim := &gif.GIF{}
+	for _, framePath := range framePaths {
+		img, err := readPNG(framePath)
+		if err != nil {
+			return err
+		}
+		anim.Image = append(anim.Image, paletted(img))
+		anim.Delay = append(anim.Delay, gifDelay(delay))
+	}
+	return WriteGIF(path, anim)
+}
+
+// WriteGIF encodes an animation into a file.
+func WriteGIF(path string, anim *gif.GIF) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func paletted(img image.Image) *image.Paletted {
+	p := image.NewPaletted(img.Bounds(), palette.Plan9)
+	draw.Draw(p, p.Rect, img, img.Bounds().Min, draw.Src)
+	return p
+}
+
+// gifDelay converts a frame duration to GIF delay units of 10 ms.
+func gifDelay(delay time.Duration) int {
+	return max(1, int(delay/(10*time.Millisecond)))
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,66 @@
+package offscreen_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/offscreen"
+	"multilateration-sim/internal/simulation"
+	"time"
+)
+
+// Render a short run into a contact sheet without opening a window.
+func ExampleContactSheet() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+
+	recorder := offscreen.NewRecorder(sim)
+	for i := 0; i < 20; i++ {
+		sim.Step(0.1)
+	}
+	opts := offscreen.Options{Width: 160, Height: 120, TrailLength: 10}
+	sheet, err := offscreen.ContactSheet(recorder.Frames(), 6, 3, opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(len(recorder.Frames()), "frames")
+	fmt.Println("sheet size", sheet.Bounds().Dx(), "x", sheet.Bounds().Dy())
+
+	first, _ := offscreen.RenderFrame(recorder.Frames(), 0, opts)
+	fmt.Println("sensor pixel", first.RGBAAt(40, 20)) // The view is fitted to the sensor corners, (-40, -40) lands here
+	// Output:
+	// 20 frames
+	// sheet size 480 x 240
+	// sensor pixel {0 0 255 255}
+}
+
+// Turn a recorded run into an animated GIF for a report.
+func ExampleAnimate() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+
+	recorder := offscreen.NewRecorder(sim)
+	for i := 0; i < 20; i++ {
+		sim.Step(0.1)
+	}
+	anim, err := offscreen.Animate(recorder.Frames(), 2, offscreen.Options{Width: 160, Height: 120}, 200*time.Millisecond)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(len(anim.Image), "frames of", anim.Image[0].Bounds().Dx(), "x", anim.Image[0].Bounds().Dy())
+	fmt.Println("delay", anim.Delay[0]*10, "ms")
+	// Output:
+	// 10 frames of 160 x 120
+	// delay 200 ms
+}
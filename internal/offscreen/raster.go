@@ -0,0 +1,58 @@
+package offscreen
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// blend draws c over the pixel at (x, y) with alpha blending.
+func blend(img *image.RGBA, x, y int, c color.RGBA) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	i := img.PixOffset(x, y)
+	a := uint32(c.A)
+	for k, v := range [3]uint8{c.R, c.G, c.B} {
+		img.Pix[i+k] = uint8((uint32(v)*a + uint32(img.Pix[i+k])*(255-a)) / 255)
+	}
+	img.Pix[i+3] = uint8(a + uint32(img.Pix[i+3])*(255-a)/255)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			blend(img, x, y, c)
+		}
+	}
+}
+
+func fillCircle(img *image.RGBA, cx, cy, radius float64, c color.RGBA) {
+	minX, maxX := int(math.Floor(cx-radius)), int(math.Ceil(cx+radius))
+	minY, maxY := int(math.Floor(cy-radius)), int(math.Ceil(cy+radius))
+	r := image.Rect(minX, minY, maxX+1, maxY+1).Intersect(img.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if dx*dx+dy*dy <= radius*radius {
+				blend(img, x, y, c)
+			}
+		}
+	}
+}
+
+// drawLine draws a one-pixel line by sampling it at pixel spacing.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+	if steps > 4*(img.Rect.Dx()+img.Rect.Dy()) {
+		return // Far off-screen, not worth sampling
+	}
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		blend(img, int(x0+(x1-x0)*t), int(y0+(y1-y0)*t), c)
+	}
+}
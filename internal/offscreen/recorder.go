@@ -0,0 +1,59 @@
+// Package offscreen renders recorded simulation runs to PNG images without opening a
+// window or needing a GPU, e.g. to generate figures on headless CI machines.
+// It draws with the standard image package only, so it does not depend on Ebiten.
+package offscreen
+
+import (
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+)
+
+// SensorSnapshot is the state of a sensor in one frame.
+type SensorSnapshot struct {
+	ID       string
+	Position common.Vector
+	Radius   float64 // Detection radius, 0 means unlimited
+}
+
+// TargetSnapshot is the state of a target in one frame.
+type TargetSnapshot struct {
+	ID       string
+	Truth    common.Vector
+	Estimate common.Vector // nil if the target was not localized in this step
+}
+
+// Frame is the scene at the end of one simulation step.
+type Frame struct {
+	Time    float64
+	Sensors []SensorSnapshot
+	Targets []TargetSnapshot
+}
+
+// Recorder captures a frame after every simulation step.
+type Recorder struct {
+	frames []Frame
+}
+
+// NewRecorder creates a recorder and subscribes it to the simulation's steps.
+func NewRecorder(sim *simulation.Simulation) *Recorder {
+	rec := &Recorder{}
+	sim.OnStep(rec.HandleStep)
+	return rec
+}
+
+// HandleStep captures the current scene.
+func (rec *Recorder) HandleStep(report simulation.StepReport) {
+	frame := Frame{Time: report.Time}
+	for _, sen := range report.State.Sensors {
+		frame.Sensors = append(frame.Sensors, SensorSnapshot{ID: sen.ID, Position: sen.Position, Radius: sen.DetectionRadius})
+	}
+	for _, tar := range report.State.Targets {
+		frame.Targets = append(frame.Targets, TargetSnapshot{ID: tar.ID, Truth: tar.Position, Estimate: tar.Estimate.Position})
+	}
+	rec.frames = append(rec.frames, frame)
+}
+
+// Frames returns the recorded frames, oldest first.
+func (rec *Recorder) Frames() []Frame {
+	return rec.frames
+}
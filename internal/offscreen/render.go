@@ -0,0 +1,262 @@
+package offscreen
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+var (
+	backgroundColor   = color.RGBA{230, 230, 230, 255}
+	sensorColor       = color.RGBA{0, 0, 255, 255}
+	sensorRadiusColor = color.RGBA{0, 0, 200, 40}
+	targetColor       = color.RGBA{255, 0, 0, 255}
+	estimateColor     = color.RGBA{255, 0, 0, 110}
+	trailColor        = color.RGBA{200, 0, 0, 160}
+	progressColor     = color.RGBA{80, 80, 80, 255}
+)
+
+// Options control how frames are drawn. The scene is shown along two axes of the
+// simulation space (a slice, like visualization.SliceProjector), which keeps the view
+// identical across frames and runs.
+type Options struct {
+	Width, Height int       // Image size in pixels
+	AxisX, AxisY  int       // Simulation axes shown horizontally and vertically
+	Bounds        []float64 // minX, maxX, minY, maxY of the view; nil fits all recorded positions
+	TrailLength   int       // Frames of target history drawn as a trail, 0 for none
+}
+
+// DefaultOptions returns 800x600 images of axes 0/1 with 50-frame trails.
+func DefaultOptions() Options {
+	return Options{Width: 800, Height: 600, AxisX: 0, AxisY: 1, TrailLength: 50}
+}
+
+// view maps simulation coordinates to pixels.
+type view struct {
+	opts             Options
+	scale            float64
+	offsetX, offsetY float64
+}
+
+func newView(frames []Frame, opts Options) (view, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return view{}, fmt.Errorf("image size must be positive, got %dx%d", opts.Width, opts.Height)
+	}
+	bounds := opts.Bounds
+	if bounds == nil {
+		bounds = fitBounds(frames, opts.AxisX, opts.AxisY)
+	}
+	if len(bounds) != 4 || bounds[1] <= bounds[0] || bounds[3] <= bounds[2] {
+		return view{}, fmt.Errorf("invalid view bounds %v", bounds)
+	}
+	const margin = 20.0
+	scale := math.Min((float64(opts.Width)-2*margin)/(bounds[1]-bounds[0]), (float64(opts.Height)-2*margin)/(bounds[3]-bounds[2]))
+	return view{
+		opts:    opts,
+		scale:   scale,
+		offsetX: float64(opts.Width)/2 - (bounds[0]+bounds[1])/2*scale,
+		offsetY: float64(opts.Height)/2 - (bounds[2]+bounds[3])/2*scale,
+	}, nil
+}
+
+// fitBounds returns the bounding box of all recorded positions along the two axes.
+func fitBounds(frames []Frame, ax, ay int) []float64 {
+	b := []float64{math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)}
+	grow := func(p []float64) {
+		x, okX := coordinate(p, ax)
+		y, okY := coordinate(p, ay)
+		if !okX || !okY {
+			return
+		}
+		b[0], b[1] = math.Min(b[0], x), math.Max(b[1], x)
+		b[2], b[3] = math.Min(b[2], y), math.Max(b[3], y)
+	}
+	for _, f := range frames {
+		for _, s := range f.Sensors {
+			grow(s.Position)
+		}
+		for _, t := range f.Targets {
+			grow(t.Truth)
+		}
+	}
+	if math.IsInf(b[0], 0) {
+		return []float64{-1, 1, -1, 1}
+	}
+	for i := 0; i < 4; i += 2 { // Avoid a zero-size view
+		if b[i+1]-b[i] < 1e-9 {
+			b[i], b[i+1] = b[i]-1, b[i+1]+1
+		}
+	}
+	return b
+}
+
+func (v view) toPixel(p []float64) (float64, float64, bool) {
+	x, okX := coordinate(p, v.opts.AxisX)
+	y, okY := coordinate(p, v.opts.AxisY)
+	if !okX || !okY {
+		return 0, 0, false
+	}
+	return x*v.scale + v.offsetX, y*v.scale + v.offsetY, true
+}
+
+// coordinate returns the position along axis. 1D recordings have no second axis
+// and are drawn on a horizontal line through y = 0.
+func coordinate(p []float64, axis int) (float64, bool) {
+	switch {
+	case axis >= 0 && axis < len(p):
+		return p[axis], true
+	case len(p) == 1:
+		return 0, true
+	}
+	return 0, false
+}
+
+// RenderFrame draws frame index of the recording, with trails from the preceding frames.
+func RenderFrame(frames []Frame, index int, opts Options) (*image.RGBA, error) {
+	if index < 0 || index >= len(frames) {
+		return nil, fmt.Errorf("frame %d out of range [0, %d)", index, len(frames))
+	}
+	v, err := newView(frames, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.render(frames, index, float64(index+1)/float64(len(frames))), nil
+}
+
+func (v view) render(frames []Frame, index int, progress float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, v.opts.Width, v.opts.Height))
+	fillRect(img, img.Bounds(), backgroundColor)
+	frame := frames[index]
+
+	for _, s := range frame.Sensors {
+		x, y, ok := v.toPixel(s.Position)
+		if !ok {
+			continue
+		}
+		switch {
+		case s.Radius > 0 && len(s.Position) == 1: // The range of a 1D sensor is a segment
+			r := s.Radius * v.scale
+			fillRect(img, image.Rect(int(x-r), int(y)-3, int(x+r), int(y)+3), sensorRadiusColor)
+		case s.Radius > 0:
+			fillCircle(img, x, y, s.Radius*v.scale, sensorRadiusColor)
+		}
+		fillCircle(img, x, y, 5, sensorColor)
+	}
+
+	if v.opts.TrailLength > 0 {
+		first := max(0, index-v.opts.TrailLength)
+		for _, t := range frame.Targets {
+			var prevX, prevY float64
+			havePrev := false
+			for i := first; i <= index; i++ {
+				truth, ok := findTarget(frames[i], t.ID)
+				if !ok {
+					havePrev = false
+					continue
+				}
+				x, y, ok := v.toPixel(truth)
+				if ok && havePrev {
+					drawLine(img, prevX, prevY, x, y, trailColor)
+				}
+				prevX, prevY, havePrev = x, y, ok
+			}
+		}
+	}
+
+	for _, t := range frame.Targets {
+		if t.Estimate != nil {
+			if x, y, ok := v.toPixel(t.Estimate); ok {
+				fillCircle(img, x, y, 6, estimateColor)
+			}
+		}
+		if x, y, ok := v.toPixel(t.Truth); ok {
+			fillCircle(img, x, y, 4, targetColor)
+		}
+	}
+
+	// Progress bar instead of a time label (the standard library has no fonts)
+	barWidth := int(progress * float64(v.opts.Width))
+	fillRect(img, image.Rect(0, v.opts.Height-3, barWidth, v.opts.Height), progressColor)
+	return img
+}
+
+func findTarget(f Frame, id string) ([]float64, bool) {
+	for _, t := range f.Targets {
+		if t.ID == id {
+			return t.Truth, true
+		}
+	}
+	return nil, false
+}
+
+// WriteFrames renders every step-th frame into dir as frame_00000.png, frame_00001.png, ...
+// and returns the written paths.
+func WriteFrames(dir string, frames []Frame, step int, opts Options) ([]string, error) {
+	if step < 1 {
+		return nil, fmt.Errorf("frame step must be at least 1, got %d", step)
+	}
+	v, err := newView(frames, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create frame directory: %w", err)
+	}
+	var paths []string
+	for i := 0; i < len(frames); i += step {
+		path := filepath.Join(dir, fmt.Sprintf("frame_%05d.png", len(paths)))
+		if err := WritePNG(path, v.render(frames, i, float64(i+1)/float64(len(frames)))); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ContactSheet renders count frames evenly spaced over the run (always including the
+// last one) as tiles of opts.Width x opts.Height, arranged in the given number of columns.
+func ContactSheet(frames []Frame, count, columns int, opts Options) (*image.RGBA, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames recorded")
+	}
+	if count < 1 || columns < 1 {
+		return nil, fmt.Errorf("count and columns must be positive, got %d and %d", count, columns)
+	}
+	count = min(count, len(frames))
+	v, err := newView(frames, opts)
+	if err != nil {
+		return nil, err
+	}
+	rows := (count + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*opts.Width, rows*opts.Height))
+	fillRect(sheet, sheet.Bounds(), color.RGBA{255, 255, 255, 255})
+	for k := 0; k < count; k++ {
+		index := len(frames) - 1
+		if count > 1 {
+			index = k * (len(frames) - 1) / (count - 1)
+		}
+		tile := v.render(frames, index, float64(index+1)/float64(len(frames)))
+		origin := image.Pt((k%columns)*opts.Width, (k/columns)*opts.Height)
+		for y := 0; y < opts.Height; y++ {
+			copy(sheet.Pix[sheet.PixOffset(origin.X, origin.Y+y):], tile.Pix[tile.PixOffset(0, y):tile.PixOffset(opts.Width, y)])
+		}
+	}
+	return sheet, nil
+}
+
+// WritePNG encodes an image into a file.
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// plotMargin is the space around the plot area in pixels.
+const plotMargin = 12
+
+var (
+	plotBackground = color.RGBA{255, 255, 255, 255}
+	plotGrid       = color.RGBA{225, 225, 225, 255}
+	plotAxes       = color.RGBA{60, 60, 60, 255}
+)
+
+// seriesColors are the colors of the configurations in the plots, in order; the names
+// are the legend of the markdown report.
+var seriesColors = []struct {
+	name  string
+	color color.RGBA
+}{
+	{"blue", color.RGBA{31, 119, 180, 255}},
+	{"orange", color.RGBA{255, 127, 14, 255}},
+	{"green", color.RGBA{44, 160, 44, 255}},
+	{"red", color.RGBA{214, 39, 40, 255}},
+	{"purple", color.RGBA{148, 103, 189, 255}},
+	{"brown", color.RGBA{140, 86, 75, 255}},
+	{"pink", color.RGBA{227, 119, 194, 255}},
+	{"gray", color.RGBA{127, 127, 127, 255}},
+}
+
+// SeriesColor returns the name and color of the i-th series; colors repeat after eight.
+func SeriesColor(i int) (string, color.RGBA) {
+	c := seriesColors[i%len(seriesColors)]
+	return c.name, c.color
+}
+
+// CDFErrors returns the errors of every configuration on a scenario, "" for all
+// scenarios, in the order of Configs; nil for a configuration not run on it.
+func (r *Report) CDFErrors(scenario string) [][]float64 {
+	series := make([][]float64, len(r.Configs))
+	for i, config := range r.Configs {
+		if e, ok := r.Entry(scenario, config); ok {
+			series[i] = e.Error.Errors
+		}
+	}
+	return series
+}
+
+// MaxError returns the largest error of any configuration on a scenario, "" for all
+// scenarios: the end of the error axis of its plot.
+func (r *Report) MaxError(scenario string) float64 {
+	maxError := 0.0
+	for _, errs := range r.CDFErrors(scenario) {
+		for _, e := range errs {
+			maxError = math.Max(maxError, e)
+		}
+	}
+	return maxError
+}
+
+// RenderCDF plots the empirical cumulative distribution of every series of errors, the
+// i-th in SeriesColor(i). The error axis runs from 0 to maxError (0 fits the largest
+// error), the probability axis from 0 to 1; grid lines divide both into tenths.
+func RenderCDF(series [][]float64, maxError float64, width, height int) (*image.RGBA, error) {
+	if width <= 2*plotMargin || height <= 2*plotMargin {
+		return nil, fmt.Errorf("plot size must exceed %d pixels, got %dx%d", 2*plotMargin, width, height)
+	}
+	if maxError <= 0 {
+		for _, errs := range series {
+			for _, e := range errs {
+				maxError = math.Max(maxError, e)
+			}
+		}
+	}
+	if maxError <= 0 {
+		maxError = 1 // Only exact estimates, or none
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Rect, plotBackground)
+	left, bottom := float64(plotMargin), float64(height-plotMargin)
+	plotW, plotH := float64(width-2*plotMargin), float64(height-2*plotMargin)
+	toPixel := func(e, p float64) (float64, float64) {
+		return left + math.Min(e/maxError, 1)*plotW, bottom - p*plotH
+	}
+	for k := 1; k <= 10; k++ {
+		x, y := toPixel(maxError*float64(k)/10, float64(k)/10)
+		drawLine(img, x, bottom, x, bottom-plotH, plotGrid)
+		drawLine(img, left, y, left+plotW, y, plotGrid)
+	}
+	drawLine(img, left, bottom, left+plotW, bottom, plotAxes)
+	drawLine(img, left, bottom, left, bottom-plotH, plotAxes)
+
+	for i, errs := range series {
+		if len(errs) == 0 {
+			continue
+		}
+		_, c := SeriesColor(i)
+		sorted := append([]float64(nil), errs...)
+		sort.Float64s(sorted)
+		x0, y0 := toPixel(0, 0)
+		for k, e := range sorted {
+			x1, _ := toPixel(e, 0)
+			_, y1 := toPixel(0, float64(k+1)/float64(len(sorted)))
+			drawThickLine(img, x0, y0, x1, y0, c) // Flat up to the next error
+			drawThickLine(img, x1, y0, x1, y1, c) // Then a step
+			x0, y0 = x1, y1
+		}
+		drawThickLine(img, x0, y0, left+plotW, y0, c)
+	}
+	return img, nil
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a one-pixel line by sampling it at pixel spacing.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		img.SetRGBA(int(x0+(x1-x0)*t), int(y0+(y1-y0)*t), c)
+	}
+}
+
+// drawThickLine draws a two-pixel line, so curves stand out from the grid.
+func drawThickLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	drawLine(img, x0, y0, x1, y1, c)
+	drawLine(img, x0+1, y0-1, x1+1, y1-1, c)
+}
@@ -0,0 +1,79 @@
+package report_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/report"
+	"os"
+)
+
+// Compare two solvers on two scenarios from their recorded tracks.
+func ExampleWriteMarkdown() {
+	track := func(offsets ...float64) map[string][]export.TrackPoint {
+		points := make([]export.TrackPoint, len(offsets))
+		for i, off := range offsets {
+			points[i] = export.TrackPoint{Time: float64(i), Truth: common.Vector{float64(i), 0}}
+			if off >= 0 {
+				points[i].Estimate = common.Vector{float64(i), off}
+			}
+		}
+		return map[string][]export.TrackPoint{"target-1": points}
+	}
+	rep, err := report.Build([]report.Run{
+		{Scenario: "open", Config: "ls", Tracks: track(1, 2, 2, 3)},
+		{Scenario: "open", Config: "robust", Tracks: track(1, 1, 1, 1)},
+		{Scenario: "urban", Config: "ls", Tracks: track(4, 8, -1, 6)},
+		{Scenario: "urban", Config: "robust", Tracks: track(2, 3, 2, -1)},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	_ = report.WriteMarkdown(os.Stdout, rep, map[string]string{"": "cdf.png"})
+
+	// 176x76 pixels of plot area inside the margins, errors up to 8
+	img, _ := report.RenderCDF(rep.CDFErrors(""), rep.MaxError(""), 200, 100)
+	name, c := report.SeriesColor(1)
+	fmt.Printf("\nrobust (%s) reaches 1 by error 5: %v\n", name, img.RGBAAt(12+176*5/8, 12) == c)
+	// Output:
+	// # Experiment comparison
+	//
+	// 2 scenarios, 2 configurations. Errors are distances between the estimated and true positions over the localized target-steps.
+	//
+	// ## All scenarios
+	//
+	// | config | localized | RMSE | mean | median | p95 | max |
+	// | --- | --- | --- | --- | --- | --- | --- |
+	// | ls | 87.5% | 4.375 | 3.714 | 3.000 | 8.000 | 8.000 |
+	// | robust | 87.5% | 1.732 | 1.571 | 1.000 | 3.000 | 3.000 |
+	//
+	// ![Error CDF](cdf.png)
+	//
+	// Error CDF from 0 to 8.000, grid lines every tenth. Colors: ls blue, robust orange.
+	//
+	// ## RMSE per scenario
+	//
+	// The lowest RMSE of every scenario is in bold.
+	//
+	// | scenario | ls | robust |
+	// | --- | --- | --- |
+	// | open | 2.121 | **1.000** |
+	// | urban | 6.218 | **2.380** |
+	//
+	// ## Scenario open
+	//
+	// | config | localized | RMSE | mean | median | p95 | max |
+	// | --- | --- | --- | --- | --- | --- | --- |
+	// | ls | 100.0% | 2.121 | 2.000 | 2.000 | 3.000 | 3.000 |
+	// | robust | 100.0% | 1.000 | 1.000 | 1.000 | 1.000 | 1.000 |
+	//
+	// ## Scenario urban
+	//
+	// | config | localized | RMSE | mean | median | p95 | max |
+	// | --- | --- | --- | --- | --- | --- | --- |
+	// | ls | 75.0% | 6.218 | 6.000 | 6.000 | 8.000 | 8.000 |
+	// | robust | 75.0% | 2.380 | 2.333 | 2.000 | 3.000 | 3.000 |
+	//
+	// robust (orange) reaches 1 by error 5: true
+}
@@ -0,0 +1,172 @@
+// Package report compares recorded runs, e.g. of different solvers or noise levels on
+// the same scenarios, and writes the comparison as a markdown or HTML report with error
+// tables and CDF-of-error plots.
+package report
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/metrics"
+	"sort"
+)
+
+// Run is one recorded run: the tracks of a configuration on a scenario.
+type Run struct {
+	Scenario string
+	Config   string                         // E.g. the solver or the noise level
+	Tracks   map[string][]export.TrackPoint // targetID -> points, as export.ReadTracksCSV returns them
+}
+
+// Entry is the accuracy of a configuration on a scenario, or on all of them together.
+type Entry struct {
+	Scenario  string // Empty for the entries over all scenarios
+	Config    string
+	Records   int                     // Target-steps
+	Localized int                     // Target-steps with an estimate
+	Error     metrics.TrajectoryError // Over the localized target-steps, zero if there were none
+	P95       float64                 // 95th percentile of the error
+}
+
+// LocalizedFraction is the share of target-steps that have an estimate.
+func (e Entry) LocalizedFraction() float64 {
+	if e.Records == 0 {
+		return 0
+	}
+	return float64(e.Localized) / float64(e.Records)
+}
+
+// Report is the comparison of all runs.
+type Report struct {
+	Scenarios []string // In the order they first appear in the runs
+	Configs   []string // Likewise
+	Entries   []Entry  // Per scenario and configuration, ordered like Scenarios and Configs
+	Overall   []Entry  // Per configuration, pooling the target-steps of all its scenarios
+}
+
+// Build scores every run against its true positions. Every run needs a scenario name,
+// and each combination of scenario and configuration may appear once.
+func Build(runs []Run) (*Report, error) {
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("report needs at least one run")
+	}
+	r := &Report{}
+	seenScenario, seenConfig := make(map[string]bool), make(map[string]bool)
+	byKey := make(map[[2]string]Run, len(runs))
+	for _, run := range runs {
+		if run.Scenario == "" {
+			return nil, fmt.Errorf("run %q has no scenario name", run.Config)
+		}
+		key := [2]string{run.Scenario, run.Config}
+		if _, dup := byKey[key]; dup {
+			return nil, fmt.Errorf("duplicate run %q of scenario %q", run.Config, run.Scenario)
+		}
+		byKey[key] = run
+		if !seenScenario[run.Scenario] {
+			seenScenario[run.Scenario] = true
+			r.Scenarios = append(r.Scenarios, run.Scenario)
+		}
+		if !seenConfig[run.Config] {
+			seenConfig[run.Config] = true
+			r.Configs = append(r.Configs, run.Config)
+		}
+	}
+
+	for _, config := range r.Configs {
+		overall := score{}
+		for _, scenario := range r.Scenarios {
+			run, ok := byKey[[2]string{scenario, config}]
+			if !ok {
+				continue
+			}
+			s := scoreTracks(run.Tracks)
+			entry, err := s.entry(scenario, config)
+			if err != nil {
+				return nil, fmt.Errorf("scenario %q, config %q: %w", scenario, config, err)
+			}
+			r.Entries = append(r.Entries, entry)
+			overall.records += s.records
+			overall.truths = append(overall.truths, s.truths...)
+			overall.estimates = append(overall.estimates, s.estimates...)
+		}
+		entry, err := overall.entry("", config)
+		if err != nil {
+			return nil, fmt.Errorf("config %q: %w", config, err)
+		}
+		r.Overall = append(r.Overall, entry)
+	}
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		return indexOf(r.Scenarios, r.Entries[i].Scenario) < indexOf(r.Scenarios, r.Entries[j].Scenario)
+	})
+	return r, nil
+}
+
+// Entry returns the entry of a configuration on a scenario, "" for all scenarios; false
+// if the configuration was not run on it.
+func (r *Report) Entry(scenario, config string) (Entry, bool) {
+	entries := r.Entries
+	if scenario == "" {
+		entries = r.Overall
+	}
+	for _, e := range entries {
+		if e.Scenario == scenario && e.Config == config {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// score collects the localized target-steps of runs.
+type score struct {
+	records           int
+	truths, estimates []common.Vector
+}
+
+func scoreTracks(tracks map[string][]export.TrackPoint) score {
+	ids := make([]string, 0, len(tracks))
+	for id := range tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // Errors in a stable order
+	s := score{}
+	for _, id := range ids {
+		for _, p := range tracks[id] {
+			s.records++
+			if p.Estimate != nil {
+				s.truths, s.estimates = append(s.truths, p.Truth), append(s.estimates, p.Estimate)
+			}
+		}
+	}
+	return s
+}
+
+func (s score) entry(scenario, config string) (Entry, error) {
+	e := Entry{Scenario: scenario, Config: config, Records: s.records, Localized: len(s.estimates)}
+	if len(s.estimates) == 0 {
+		return e, nil
+	}
+	ate, err := metrics.AbsoluteTrajectoryError(s.truths, s.estimates, metrics.AlignNone)
+	if err != nil {
+		return Entry{}, err
+	}
+	e.Error, e.P95 = ate, percentile(ate.Errors, 0.95)
+	return e, nil
+}
+
+// percentile returns the nearest-rank p-quantile of xs.
+func percentile(xs []float64, p float64) float64 {
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
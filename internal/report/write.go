@@ -0,0 +1,212 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+// section is a part of the report: the entries of all scenarios or of one.
+type section struct {
+	Title    string
+	Entries  []Entry
+	Plot     string // Image path, empty without a plot
+	MaxError float64
+}
+
+// sections returns the overall section followed by one per scenario.
+func (r *Report) sections(plots map[string]string) []section {
+	sections := []section{{Title: "All scenarios", Entries: r.Overall, Plot: plots[""], MaxError: r.MaxError("")}}
+	for _, scenario := range r.Scenarios {
+		s := section{Title: "Scenario " + scenario, Plot: plots[scenario], MaxError: r.MaxError(scenario)}
+		for _, config := range r.Configs {
+			if e, ok := r.Entry(scenario, config); ok {
+				s.Entries = append(s.Entries, e)
+			}
+		}
+		sections = append(sections, s)
+	}
+	return sections
+}
+
+// rmseRow is a row of the scenario by configuration RMSE table.
+type rmseRow struct {
+	Scenario string
+	Cells    []string
+	Best     int // Column of the lowest RMSE, -1 if none
+}
+
+// rmseRows returns the RMSE of every configuration on every scenario.
+func (r *Report) rmseRows() []rmseRow {
+	rows := make([]rmseRow, 0, len(r.Scenarios))
+	for _, scenario := range r.Scenarios {
+		row := rmseRow{Scenario: scenario, Best: -1}
+		best := math.Inf(1)
+		for i, config := range r.Configs {
+			e, ok := r.Entry(scenario, config)
+			if !ok || e.Localized == 0 {
+				row.Cells = append(row.Cells, "-")
+				continue
+			}
+			if e.Error.RMSE < best {
+				row.Best, best = i, e.Error.RMSE
+			}
+			row.Cells = append(row.Cells, fmt.Sprintf("%.3f", e.Error.RMSE))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// cells returns the table cells of an entry after the configuration name.
+func (e Entry) cells() []string {
+	localized := fmt.Sprintf("%.1f%%", 100*e.LocalizedFraction())
+	if e.Localized == 0 {
+		return []string{localized, "-", "-", "-", "-", "-"}
+	}
+	return []string{
+		localized,
+		fmt.Sprintf("%.3f", e.Error.RMSE),
+		fmt.Sprintf("%.3f", e.Error.Mean),
+		fmt.Sprintf("%.3f", e.Error.Median),
+		fmt.Sprintf("%.3f", e.P95),
+		fmt.Sprintf("%.3f", e.Error.Max),
+	}
+}
+
+// entryHeader is the header of the entry tables.
+var entryHeader = []string{"config", "localized", "RMSE", "mean", "median", "p95", "max"}
+
+// legend names the color of every configuration.
+func (r *Report) legend() string {
+	parts := make([]string, len(r.Configs))
+	for i, config := range r.Configs {
+		name, _ := SeriesColor(i)
+		parts[i] = config + " " + name
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteMarkdown writes the report as markdown: the accuracy of every configuration over
+// all scenarios, the RMSE of every configuration per scenario, then a section per
+// scenario. plots maps a scenario, "" for all of them, to the path of its CDF image
+// (see RenderCDF) as the report links it; scenarios without one get no plot.
+func WriteMarkdown(w io.Writer, r *Report, plots map[string]string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Experiment comparison\n\n%d scenarios, %d configurations. Errors are distances between the estimated and true positions over the localized target-steps.\n",
+		len(r.Scenarios), len(r.Configs))
+	for k, s := range r.sections(plots) {
+		if k == 1 {
+			b.WriteString("\n## RMSE per scenario\n\nThe lowest RMSE of every scenario is in bold.\n\n")
+			var rows [][]string
+			for _, row := range r.rmseRows() {
+				cells := append([]string{row.Scenario}, row.Cells...)
+				if row.Best >= 0 {
+					cells[row.Best+1] = "**" + cells[row.Best+1] + "**"
+				}
+				rows = append(rows, cells)
+			}
+			writeMarkdownTable(&b, append([]string{"scenario"}, r.Configs...), rows)
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Title)
+		rows := make([][]string, len(s.Entries))
+		for i, e := range s.Entries {
+			rows[i] = append([]string{e.Config}, e.cells()...)
+		}
+		writeMarkdownTable(&b, entryHeader, rows)
+		if s.Plot != "" {
+			fmt.Fprintf(&b, "\n![Error CDF](%s)\n\nError CDF from 0 to %.3f, grid lines every tenth. Colors: %s.\n", s.Plot, s.MaxError, r.legend())
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownTable writes a table with a header row.
+func writeMarkdownTable(b *strings.Builder, header []string, rows [][]string) {
+	fmt.Fprintf(b, "| %s |\n|%s\n", strings.Join(header, " | "), strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		fmt.Fprintf(b, "| %s |\n", strings.Join(row, " | "))
+	}
+}
+
+// htmlReport is the template of WriteHTML.
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Experiment comparison</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.swatch { display: inline-block; width: 1em; height: 1em; vertical-align: middle; margin: 0 0.3em 0 1em; }
+</style>
+</head>
+<body>
+<h1>Experiment comparison</h1>
+<p>{{len .Report.Scenarios}} scenarios, {{len .Report.Configs}} configurations. Errors are distances between the estimated and true positions over the localized target-steps.</p>
+{{range $k, $s := .Sections}}
+{{if eq $k 1}}
+<h2>RMSE per scenario</h2>
+<p>The lowest RMSE of every scenario is in bold.</p>
+<table>
+<tr><th>scenario</th>{{range $.Report.Configs}}<th>{{.}}</th>{{end}}</tr>
+{{range $.RMSE}}<tr><td>{{.Scenario}}</td>{{$best := .Best}}{{range $i, $c := .Cells}}<td>{{if eq $i $best}}<b>{{$c}}</b>{{else}}{{$c}}{{end}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+<h2>{{$s.Title}}</h2>
+<table>
+<tr>{{range $.Header}}<th>{{.}}</th>{{end}}</tr>
+{{range $s.Entries}}<tr><td>{{.Config}}</td>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{if $s.Plot}}<figure>
+<img src="{{$s.Plot}}" alt="Error CDF">
+<figcaption>Error CDF from 0 to {{printf "%.3f" $s.MaxError}}, grid lines every tenth.{{range $.Legend}}<span class="swatch" style="background: {{.Color}}"></span>{{.Config}}{{end}}</figcaption>
+</figure>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// htmlEntry is an entry with its cells, for the template.
+type htmlEntry struct {
+	Config string
+	Cells  []string
+}
+
+// WriteHTML writes the report of WriteMarkdown as a standalone HTML page.
+func WriteHTML(w io.Writer, r *Report, plots map[string]string) error {
+	type legendItem struct {
+		Config string
+		Color  template.CSS
+	}
+	type htmlSection struct {
+		Title    string
+		Entries  []htmlEntry
+		Plot     string
+		MaxError float64
+	}
+	data := struct {
+		Report   *Report
+		Sections []htmlSection
+		RMSE     []rmseRow
+		Header   []string
+		Legend   []legendItem
+	}{Report: r, RMSE: r.rmseRows(), Header: entryHeader}
+	for _, s := range r.sections(plots) {
+		hs := htmlSection{Title: s.Title, Plot: s.Plot, MaxError: s.MaxError}
+		for _, e := range s.Entries {
+			hs.Entries = append(hs.Entries, htmlEntry{Config: e.Config, Cells: e.cells()})
+		}
+		data.Sections = append(data.Sections, hs)
+	}
+	for i, config := range r.Configs {
+		_, c := SeriesColor(i)
+		data.Legend = append(data.Legend, legendItem{Config: config, Color: template.CSS(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))})
+	}
+	return htmlReport.Execute(w, data)
+}
@@ -0,0 +1,203 @@
+package scenario_test
+
+import (
+	"errors"
+	"fmt"
+	"multilateration-sim/internal/scenario"
+	"multilateration-sim/internal/simulation"
+	"os"
+	"strings"
+)
+
+func ExampleSession_Capture() {
+	sc := &scenario.Scenario{
+		Dimension:   2,
+		Bounds:      []float64{-100, 100, -100, 100},
+		TickSeconds: 0.1,
+		Sensors: []scenario.SensorSpec{
+			{Position: []float64{-50, -50}, Radius: 200, Noise: &scenario.NoiseSpec{Type: "gaussian", StdDev: 0.5}},
+			{Position: []float64{50, -50}, Radius: 200},
+			{Position: []float64{0, 60}, Radius: 200},
+		},
+		Targets: []scenario.TargetSpec{
+			{Position: []float64{0, 0}, Velocity: []float64{2, 0}, Motion: &scenario.MotionSpec{Type: "constant_velocity"}},
+		},
+	}
+	session, err := sc.NewSession()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	for i := 0; i < 10; i++ {
+		session.Simulation().Step(0.1)
+	}
+
+	captured, err := session.Capture()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	target := captured.Targets[0]
+	fmt.Printf("target at %.1f, motion %s\n", target.Position, target.Motion.Type)
+	fmt.Printf("sensor 0 noise: %s %.1f\n", captured.Sensors[0].Noise.Type, captured.Sensors[0].Noise.StdDev)
+	fmt.Println("sensor 1 noise:", captured.Sensors[1].Noise)
+	// Output:
+	// target at [2.0 0.0], motion constant_velocity
+	// sensor 0 noise: gaussian 0.5
+	// sensor 1 noise: <nil>
+}
+
+// Drop a deployment survey into a scenario.
+func ExampleReadSurveyCSV() {
+	survey := `id,x,y,z,accuracy
+# Warehouse anchors, total station survey
+A1, 0, 0, , 0.02
+A2, 40.5, 0, , 0.02
+A3, 40.5, 30
+A4, 0, 30, , 0.05
+`
+	points, err := scenario.ReadSurveyCSV(strings.NewReader(survey))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	sc := &scenario.Scenario{Dimension: 2, Bounds: []float64{0, 40.5, 0, 30}, TickSeconds: 0.1}
+	if err := sc.AddSurvey(points, 100); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	for i, s := range sc.Sensors {
+		fmt.Printf("%s at %v, range variance %.4g\n", points[i].ID, s.Position, s.RangeVariance)
+	}
+	_ = scenario.WriteSurveyCSV(os.Stdout, points[2:])
+	// Output:
+	// A1 at [0 0], range variance 0.0004
+	// A2 at [40.5 0], range variance 0.0004
+	// A3 at [40.5 30], range variance 0
+	// A4 at [0 30], range variance 0.0025
+	// id,x,y,z,accuracy
+	// A3,40.5,30,,
+	// A4,0,30,,0.05
+}
+
+// Script an experiment: a relay sensor comes online, a sensor degrades and a target leaves.
+func ExampleParseTimeline() {
+	sc := &scenario.Scenario{
+		Dimension:   2,
+		Bounds:      []float64{-100, 100, -100, 100},
+		TickSeconds: 0.5,
+		Sensors: []scenario.SensorSpec{
+			{Position: []float64{-50, -50}, Radius: 200},
+			{Position: []float64{50, -50}, Radius: 200},
+		},
+		Targets: []scenario.TargetSpec{{Position: []float64{0, 0}}, {Position: []float64{10, 10}}},
+		Timeline: []string{
+			`at 1s: add sensor as relay {"position": [0, 60], "radius": 200}`,
+			`at 2s: set noise of sensor-1 to gaussian(2.0) -> bias(0.5)`,
+			`at 2s: set radius of relay to 80`,
+			`at 3s: remove target-0`,
+		},
+	}
+	session, err := sc.NewSession()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	sim := session.Simulation()
+	for sim.GetCurrentTime() < 2.5 {
+		sim.Step(0.5)
+	}
+	noise, _ := session.SensorNoise(sim.GetSensors()[1].GetID())
+	fmt.Println("sensors:", len(sim.GetSensors()), "relay radius:", sim.GetSensors()[2].DetectionRadius())
+	fmt.Println("sensor-1 noise:", noise)
+	fmt.Println("still to come:", session.Timeline())
+	sim.Step(0.5)
+	fmt.Println("targets:", len(sim.GetTargets()))
+
+	_, err = scenario.ParseTimeline([]string{"at 5s: set colour of sensor-0 to red"})
+	fmt.Println(err)
+	// Output:
+	// sensors: 3 relay radius: 80
+	// sensor-1 noise: gaussian(σ=2) -> bias(+0.5)
+	// still to come: [at 3s: remove target-0]
+	// targets: 1
+	// timeline line 1: unknown property "colour", expected noise, radius, dropout, latency, variance, position or velocity
+}
+
+// Targets of a class share its motion and range noise unless they set their own.
+func ExampleScenario_classes() {
+	sc := &scenario.Scenario{
+		Dimension:   2,
+		Bounds:      []float64{-100, 100, -100, 100},
+		TickSeconds: 1,
+		Classes: map[string]scenario.ClassSpec{
+			"pedestrian": {Motion: &scenario.MotionSpec{Type: "random_walk", AccelerationScale: 0.5, MaxSpeed: 1.5}},
+			"drone": {
+				Motion: &scenario.MotionSpec{Type: "constant_velocity"},
+				Noise:  &scenario.NoiseSpec{Type: "gaussian", StdDev: 3},
+			},
+		},
+		Targets: []scenario.TargetSpec{
+			{Position: []float64{0, 0}, Class: "pedestrian"},
+			{Position: []float64{10, 0}, Velocity: []float64{8, 0}, Class: "drone"},
+			{Position: []float64{20, 0}, Class: "drone", Motion: &scenario.MotionSpec{Type: "static"}},
+		},
+	}
+	session, err := sc.NewSession()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	for _, tar := range session.Simulation().GetTargets() {
+		noise, _ := session.TargetNoise(tar.GetID())
+		fmt.Printf("%s: %T, range noise %s\n", tar.Class(), tar.MotionModel(), noise)
+	}
+	// Output:
+	// pedestrian: *simulation.RandomWalkMotion, range noise none
+	// drone: *simulation.ConstantVelocityMotion, range noise gaussian(σ=3)
+	// drone: <nil>, range noise gaussian(σ=3)
+}
+
+// Objects with designated IDs can be referred to by them, e.g. from the timeline; labels
+// are names for display.
+func ExampleScenario_ids() {
+	sc := &scenario.Scenario{
+		Dimension:   2,
+		Bounds:      []float64{-100, 100, -100, 100},
+		TickSeconds: 1,
+		Sensors: []scenario.SensorSpec{
+			{ID: "anchor-NE", Label: "roof NE", Position: []float64{50, 50}, Radius: 200},
+			{ID: "anchor-SW", Label: "roof SW", Position: []float64{-50, -50}, Radius: 200},
+			{Position: []float64{50, -50}, Radius: 200},
+		},
+		Targets:  []scenario.TargetSpec{{ID: "courier", Label: "courier #7", Position: []float64{0, 0}}},
+		Timeline: []string{`at 1s: set radius of anchor-NE to 10`, `at 2s: remove courier`},
+	}
+	session, err := sc.NewSession()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	sim := session.Simulation()
+	state := sim.Snapshot()
+	for _, sen := range state.Sensors[:2] {
+		fmt.Printf("%s %q\n", sen.ID, sen.Label)
+	}
+	fmt.Printf("%s %q\n", state.Targets[0].ID, state.Targets[0].Label)
+	sim.Step(1)
+	anchor, _ := sim.GetObject("anchor-NE")
+	fmt.Println("anchor-NE radius:", anchor.(*simulation.Sensor).DetectionRadius())
+	sim.Step(1)
+	fmt.Println("targets:", len(sim.GetTargets()))
+
+	sc.Sensors[1].ID = "anchor-NE"
+	_, err = sc.NewSession()
+	fmt.Println(errors.Is(err, simulation.ErrDuplicateID), err)
+	// Output:
+	// anchor-NE "roof NE"
+	// anchor-SW "roof SW"
+	// courier "courier #7"
+	// anchor-NE radius: 10
+	// targets: 0
+	// true sensor 1: duplicate ID: object with ID anchor-NE already exists
+}
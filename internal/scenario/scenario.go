@@ -0,0 +1,651 @@
+// Package scenario stores simulation setups as JSON files, so that a configuration
+// designed interactively can be reproduced in headless runs.
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/network"
+	"multilateration-sim/internal/simulation"
+	"os"
+	"sync"
+	"time"
+)
+
+// Scenario is the serializable description of a simulation.
+type Scenario struct {
+	Dimension   int               `json:"dimension"`
+	Bounds      []float64         `json:"bounds"`
+	TickSeconds float64           `json:"tick_seconds"`
+	Seeds       *simulation.Seeds `json:"seeds,omitempty"` // nil means time-seeded
+	Sensors     []SensorSpec      `json:"sensors"`
+	Targets     []TargetSpec      `json:"targets"`
+	Obstacles   []ObstacleSpec    `json:"obstacles,omitempty"`
+	Annotations []Annotation      `json:"annotations,omitempty"`
+
+	RegionOfInterest *RegionSpec                         `json:"region_of_interest,omitempty"` // nil solves targets everywhere
+	World            *RegionSpec                         `json:"world,omitempty"`              // Space the objects are confined to, nil = the bounds box
+	Boundary         string                              `json:"boundary,omitempty"`           // bounce (default), wrap, clamp or absorb
+	AdaptiveStepping *simulation.AdaptiveStepping        `json:"adaptive_stepping,omitempty"`  // nil steps by TickSeconds
+	PeerRanging      *simulation.PeerRanging             `json:"peer_ranging,omitempty"`       // nil localizes targets independently
+	Distributed      *simulation.DistributedLocalization `json:"distributed,omitempty"`        // nil runs the central solver only
+	Network          *network.Config                     `json:"network,omitempty"`            // nil delivers reports after the sensor latency alone
+	Fusion           *simulation.FusionConfig            `json:"fusion,omitempty"`             // nil solves every target every step
+	Smoothing        *simulation.FixedLagSmoothing       `json:"smoothing,omitempty"`          // nil keeps the real-time estimates only
+	Gating           *simulation.MeasurementGating       `json:"gating,omitempty"`             // nil solves with every range
+	Relative         *simulation.RelativePositioning     `json:"relative,omitempty"`           // nil builds no anchor-free relative map
+
+	// Metric is the distance ranges are measured in: "euclidean" (default), "manhattan"
+	// or "great-circle" for positions given as latitude and longitude in degrees and
+	// ranges in meters. Non-Euclidean scenes are solved with the robust iterative solver.
+	Metric string `json:"metric,omitempty"`
+
+	// Association hides which target a range belongs to and matches detections to
+	// targets with "nn", "gnn" or "jpda" (default options); empty keeps labeled ranges.
+	Association string `json:"association,omitempty"`
+
+	// SignalSpeed enables time-of-arrival ranging, where the sensor and target clocks
+	// bias the ranges; EstimateClockBias then solves for the targets' common bias too.
+	SignalSpeed       float64 `json:"signal_speed,omitempty"`
+	EstimateClockBias bool    `json:"estimate_clock_bias,omitempty"`
+
+	// Timeline scripts changes during the run, e.g. "at 30s: remove target-0"; see
+	// ParseTimeline for the commands.
+	Timeline []string `json:"timeline,omitempty"`
+
+	// Classes maps target class names (e.g. "pedestrian", "drone") to the defaults of
+	// their targets. Targets may name classes that are not listed here.
+	Classes map[string]ClassSpec `json:"classes,omitempty"`
+}
+
+// SensorSpec describes one sensor.
+type SensorSpec struct {
+	ID                  string                   `json:"id,omitempty"`    // Designated ID, empty for a random one
+	Label               string                   `json:"label,omitempty"` // Name shown next to the sensor
+	Position            []float64                `json:"position"`
+	Radius              float64                  `json:"radius"`
+	Noise               *NoiseSpec               `json:"noise,omitempty"` // nil means noiseless
+	Motion              *MotionSpec              `json:"motion,omitempty"`
+	MeasurementInterval int                      `json:"measurement_interval,omitempty"`
+	Latency             float64                  `json:"latency,omitempty"`
+	Dropout             float64                  `json:"dropout,omitempty"`
+	RangeVariance       float64                  `json:"range_variance,omitempty"`
+	FalseAlarmRate      float64                  `json:"false_alarm_rate,omitempty"`   // Spurious ranges per measurement round
+	Clock               *simulation.Clock        `json:"clock,omitempty"`              // Time-of-arrival mode, nil means a perfect clock
+	CalibrationOffset   []float64                `json:"calibration_offset,omitempty"` // Error of the position handed to the solver
+	Failure             *simulation.FailureModel `json:"failure,omitempty"`            // nil never fails
+}
+
+// TargetSpec describes one target.
+type TargetSpec struct {
+	ID        string                       `json:"id,omitempty"`    // Designated ID, empty for a random one
+	Label     string                       `json:"label,omitempty"` // Name shown next to the target
+	Position  []float64                    `json:"position"`
+	Velocity  []float64                    `json:"velocity,omitempty"`
+	Motion    *MotionSpec                  `json:"motion,omitempty"` // nil means static
+	Emission  *simulation.EmissionSchedule `json:"emission,omitempty"`
+	SpawnAt   float64                      `json:"spawn_at,omitempty"`   // Simulation time the target appears, 0 = from the start
+	DespawnAt float64                      `json:"despawn_at,omitempty"` // Simulation time the target disappears, 0 = never
+	Clock     *simulation.Clock            `json:"clock,omitempty"`      // Time-of-arrival mode, nil means a perfect clock
+	Class     string                       `json:"class,omitempty"`      // Target class, whose defaults apply to Motion and Noise
+	Noise     *NoiseSpec                   `json:"noise,omitempty"`      // Extra range noise, nil means the class default
+}
+
+// Annotation kinds.
+const (
+	AnnotationArrow  = "arrow"  // From -> To
+	AnnotationCircle = "circle" // Around From with Radius
+	AnnotationText   = "text"   // Text at From
+)
+
+// Annotation is a presentation mark pinned to world coordinates.
+type Annotation struct {
+	Kind   string    `json:"kind"`
+	From   []float64 `json:"from"`
+	To     []float64 `json:"to,omitempty"`
+	Radius float64   `json:"radius,omitempty"`
+	Text   string    `json:"text,omitempty"`
+}
+
+// Load reads a scenario from a JSON file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read scenario: %w", err)
+	}
+	var sc Scenario
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// Save writes the scenario as indented JSON.
+func (sc *Scenario) Save(path string) error {
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode scenario: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write scenario: %w", err)
+	}
+	return nil
+}
+
+// Session ties a simulation to the scenario it was built from. Noise functions cannot be
+// inspected, so the session remembers the noise specification of every sensor to be
+// able to write the simulation back to a scenario later.
+type Session struct {
+	mu          sync.Mutex // Guards noise and annotations, the session may be edited from several goroutines
+	sim         *simulation.Simulation
+	noise       map[string]*NoiseSpec // sensorID or targetID -> noise (extra range noise of targets), absent means noiseless
+	classes     map[string]ClassSpec  // Target class defaults
+	annotations []Annotation
+	timeline    timeline // Scripted events still to happen
+
+	estimateClockBias bool // The scenario chose SolvePseudoranges
+}
+
+// NewSession builds the simulation described by the scenario.
+func (sc *Scenario) NewSession() (*Session, error) {
+	events, err := ParseTimeline(sc.Timeline)
+	if err != nil {
+		return nil, err
+	}
+	tick := time.Duration(sc.TickSeconds * float64(time.Second))
+	sim, err := simulation.NewSimulation(sc.Dimension, sc.Bounds, tick)
+	if err != nil {
+		return nil, err
+	}
+	if sc.Seeds != nil {
+		sim.SetSeeds(*sc.Seeds)
+	}
+	session := &Session{sim: sim, noise: make(map[string]*NoiseSpec), annotations: append([]Annotation(nil), sc.Annotations...)}
+	session.SetClasses(sc.Classes)
+	if err := sim.SetAdaptiveStepping(sc.AdaptiveStepping); err != nil {
+		return nil, fmt.Errorf("adaptive stepping: %w", err)
+	}
+	if err := sim.SetPeerRanging(sc.PeerRanging); err != nil {
+		return nil, fmt.Errorf("peer ranging: %w", err)
+	}
+	if err := sim.SetDistributedLocalization(sc.Distributed); err != nil {
+		return nil, fmt.Errorf("distributed localization: %w", err)
+	}
+	if err := sim.SetNetwork(sc.Network); err != nil {
+		return nil, fmt.Errorf("network: %w", err)
+	}
+	if sc.Fusion != nil {
+		if err := sim.SetFusionConfig(*sc.Fusion); err != nil {
+			return nil, fmt.Errorf("fusion center: %w", err)
+		}
+	}
+	if err := sim.SetFixedLagSmoothing(sc.Smoothing); err != nil {
+		return nil, fmt.Errorf("smoothing: %w", err)
+	}
+	if err := sim.SetMeasurementGating(sc.Gating); err != nil {
+		return nil, fmt.Errorf("gating: %w", err)
+	}
+	if err := sim.SetRelativePositioning(sc.Relative); err != nil {
+		return nil, fmt.Errorf("relative positioning: %w", err)
+	}
+	boundary, err := simulation.ParseBoundaryMode(sc.Boundary)
+	if err != nil {
+		return nil, err
+	}
+	if err := sim.SetBoundaryMode(boundary); err != nil {
+		return nil, err
+	}
+	if sc.World != nil {
+		region, err := sc.World.Build()
+		if err != nil {
+			return nil, fmt.Errorf("world: %w", err)
+		}
+		sim.SetWorld(region.(simulation.World)) // Every built-in region is a world
+	}
+	if sc.RegionOfInterest != nil {
+		region, err := sc.RegionOfInterest.Build()
+		if err != nil {
+			return nil, fmt.Errorf("region of interest: %w", err)
+		}
+		sim.SetRegionOfInterest(region)
+	}
+	if sc.Metric != "" {
+		metric, err := common.ParseMetric(sc.Metric)
+		if err != nil {
+			return nil, err
+		}
+		sim.SetMetric(metric)
+		if _, euclidean := metric.(common.Euclidean); !euclidean {
+			opts := multilateration.DefaultRobustOptions()
+			opts.Metric = metric
+			sim.SetSolver(multilateration.RobustSolver(opts))
+		}
+	}
+	if err := sim.SetSignalSpeed(sc.SignalSpeed); err != nil {
+		return nil, err
+	}
+	if sc.EstimateClockBias {
+		sim.SetSolver(multilateration.SolvePseudoranges)
+		session.estimateClockBias = true
+	}
+	if sc.Association != "" {
+		associator, err := association.Parse(sc.Association, association.DefaultOptions())
+		if err != nil {
+			return nil, err
+		}
+		sim.SetAssociator(associator)
+	}
+
+	for i, spec := range sc.Sensors {
+		id, err := session.AddSensor(spec)
+		if err != nil {
+			return nil, fmt.Errorf("sensor %d: %w", i, err)
+		}
+		session.nameObject(fmt.Sprintf("sensor-%d", i), id)
+	}
+	for i, spec := range sc.Targets {
+		id, err := session.AddTarget(spec)
+		if err != nil {
+			return nil, fmt.Errorf("target %d: %w", i, err)
+		}
+		session.nameObject(fmt.Sprintf("target-%d", i), id)
+	}
+	for i, spec := range sc.Obstacles {
+		obs, err := spec.Build()
+		if err == nil {
+			err = sim.AddObject(obs)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("obstacle %d: %w", i, err)
+		}
+	}
+	if len(events) > 0 {
+		session.SetTimeline(events)
+	}
+	return session, nil
+}
+
+// WrapSession starts a session for a simulation that was built in code. noise maps
+// sensor IDs to the specification of their noise functions; sensors that are missing
+// are saved as noiseless.
+func WrapSession(sim *simulation.Simulation, noise map[string]NoiseSpec) *Session {
+	session := &Session{sim: sim, noise: make(map[string]*NoiseSpec)}
+	for id, spec := range noise {
+		session.SetSensorNoise(id, spec)
+	}
+	return session
+}
+
+// Simulation returns the simulation of the session.
+func (ss *Session) Simulation() *simulation.Simulation {
+	return ss.sim
+}
+
+// SetSensorNoise records the noise specification of a sensor, e.g. one added interactively.
+func (ss *Session) SetSensorNoise(sensorID string, spec NoiseSpec) {
+	ss.recordNoise(sensorID, spec)
+}
+
+// SetTargetNoise records the extra range noise specification of a target.
+func (ss *Session) SetTargetNoise(targetID string, spec NoiseSpec) {
+	ss.recordNoise(targetID, spec)
+}
+
+func (ss *Session) recordNoise(id string, spec NoiseSpec) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.noise[id] = &spec
+}
+
+// Annotations returns the annotations of the session.
+func (ss *Session) Annotations() []Annotation {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return append([]Annotation(nil), ss.annotations...)
+}
+
+// SetAnnotations replaces the annotations saved with the session.
+func (ss *Session) SetAnnotations(annotations []Annotation) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.annotations = append([]Annotation(nil), annotations...)
+}
+
+// SensorNoise returns the recorded noise specification of a sensor.
+func (ss *Session) SensorNoise(sensorID string) (NoiseSpec, bool) {
+	return ss.recordedNoise(sensorID)
+}
+
+// TargetNoise returns the recorded extra range noise specification of a target.
+func (ss *Session) TargetNoise(targetID string) (NoiseSpec, bool) {
+	return ss.recordedNoise(targetID)
+}
+
+func (ss *Session) recordedNoise(id string) (NoiseSpec, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	spec, ok := ss.noise[id]
+	if !ok {
+		return NoiseSpec{}, false
+	}
+	return *spec, true
+}
+
+// Classes returns the target class defaults of the session.
+func (ss *Session) Classes() map[string]ClassSpec {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return maps.Clone(ss.classes)
+}
+
+// SetClasses replaces the target class defaults used by targets added from now on.
+func (ss *Session) SetClasses(classes map[string]ClassSpec) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.classes = maps.Clone(classes)
+}
+
+// AddSensor adds a sensor described by spec to the running simulation and returns its ID.
+func (ss *Session) AddSensor(spec SensorSpec) (string, error) {
+	sensor, err := spec.build()
+	if err != nil {
+		return "", err
+	}
+	if err := ss.sim.AddObject(sensor); err != nil {
+		return "", err
+	}
+	if spec.Noise != nil {
+		ss.SetSensorNoise(sensor.GetID(), *spec.Noise)
+	}
+	return sensor.GetID(), nil
+}
+
+// AddTarget adds a target described by spec to the running simulation and returns its ID.
+// A target with SpawnAt in the future is scheduled to appear then; DespawnAt schedules
+// its removal.
+func (ss *Session) AddTarget(spec TargetSpec) (string, error) {
+	ss.mu.Lock()
+	class, ok := ss.classes[spec.Class]
+	ss.mu.Unlock()
+	if ok {
+		if spec.Motion == nil {
+			spec.Motion = class.Motion
+		}
+		if spec.Noise == nil {
+			spec.Noise = class.Noise
+		}
+	}
+	target, err := spec.build()
+	if err != nil {
+		return "", err
+	}
+	if spec.SpawnAt > ss.sim.GetCurrentTime() {
+		err = ss.sim.ScheduleSpawn(target, spec.SpawnAt)
+	} else {
+		err = ss.sim.AddObject(target)
+	}
+	if err != nil {
+		return "", err
+	}
+	if spec.DespawnAt > 0 {
+		if err := ss.sim.ScheduleDespawn(target.GetID(), spec.DespawnAt); err != nil {
+			return "", err
+		}
+	}
+	if spec.Noise != nil {
+		ss.SetTargetNoise(target.GetID(), *spec.Noise)
+	}
+	return target.GetID(), nil
+}
+
+// RemoveObject removes a sensor or target and forgets its noise specification.
+func (ss *Session) RemoveObject(id string) error {
+	if err := ss.sim.RemoveObject(id); err != nil {
+		return err
+	}
+	ss.mu.Lock()
+	delete(ss.noise, id)
+	ss.mu.Unlock()
+	return nil
+}
+
+func (spec SensorSpec) build() (*simulation.Sensor, error) {
+	var noise simulation.NoiseFunction
+	if spec.Noise != nil {
+		var err error
+		if noise, err = spec.Noise.Build(); err != nil {
+			return nil, err
+		}
+	}
+	sensor := simulation.NewSensorWithID(spec.ID, common.Vector(spec.Position), spec.Radius, noise)
+	sensor.SetLabel(spec.Label)
+	if spec.Motion != nil {
+		motion, err := spec.Motion.Build()
+		if err != nil {
+			return nil, err
+		}
+		sensor.SetMotionModel(motion)
+	}
+	if spec.MeasurementInterval > 0 {
+		if err := sensor.SetMeasurementInterval(spec.MeasurementInterval); err != nil {
+			return nil, err
+		}
+	}
+	if err := sensor.SetLatency(spec.Latency); err != nil {
+		return nil, err
+	}
+	if err := sensor.SetDropoutProbability(spec.Dropout); err != nil {
+		return nil, err
+	}
+	if err := sensor.SetFalseAlarmRate(spec.FalseAlarmRate); err != nil {
+		return nil, err
+	}
+	if spec.Clock != nil {
+		sensor.SetClock(*spec.Clock)
+	}
+	if spec.CalibrationOffset != nil {
+		if err := sensor.SetCalibrationOffset(common.Vector(spec.CalibrationOffset)); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Failure != nil {
+		if err := sensor.SetFailureModel(*spec.Failure); err != nil {
+			return nil, err
+		}
+	}
+	sensor.SetRangeVariance(spec.RangeVariance)
+	return sensor, nil
+}
+
+func (spec TargetSpec) build() (*simulation.Target, error) {
+	var motion simulation.MotionModel
+	if spec.Motion != nil {
+		var err error
+		if motion, err = spec.Motion.Build(); err != nil {
+			return nil, err
+		}
+	}
+	target := simulation.NewTargetWithID(spec.ID, common.Vector(spec.Position), motion)
+	target.SetLabel(spec.Label)
+	if spec.Velocity != nil {
+		if err := target.SetVelocity(common.Vector(spec.Velocity)); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Emission != nil {
+		if err := target.SetEmissionSchedule(*spec.Emission); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Clock != nil {
+		target.SetClock(*spec.Clock)
+	}
+	if spec.Noise != nil {
+		noise, err := spec.Noise.Build()
+		if err != nil {
+			return nil, err
+		}
+		target.SetRangeNoise(noise)
+	}
+	target.SetClass(spec.Class)
+	return target, nil
+}
+
+// Capture describes the current state of the simulation: objects at their current
+// positions and velocities with their current settings. Motion models and regions that
+// cannot be described are returned as errors; they are left out of the scenario.
+func (ss *Session) Capture() (*Scenario, error) {
+	seeds := ss.sim.Seeds()
+	sc := &Scenario{
+		Dimension:   ss.sim.GetDimension(),
+		Bounds:      ss.sim.GetBounds(),
+		TickSeconds: ss.sim.GetTickDuration().Seconds(),
+		Seeds:       &seeds,
+		Sensors:     []SensorSpec{},
+		Targets:     []TargetSpec{},
+		Annotations: ss.Annotations(),
+		Classes:     ss.Classes(),
+
+		AdaptiveStepping: ss.sim.AdaptiveStepping(),
+		PeerRanging:      ss.sim.PeerRanging(),
+		Distributed:      ss.sim.DistributedLocalization(),
+		Network:          ss.sim.NetworkConfig(),
+		Smoothing:        ss.sim.FixedLagSmoothing(),
+		Gating:           ss.sim.MeasurementGating(),
+		Relative:         ss.sim.RelativePositioning(),
+	}
+	if metric := ss.sim.Metric(); metric != (common.Euclidean{}) {
+		sc.Metric = metric.String()
+	}
+	if associator := ss.sim.Associator(); associator != nil {
+		sc.Association = associator.String()
+	}
+	if fusion := ss.sim.FusionConfig(); fusion != (simulation.FusionConfig{}) {
+		sc.Fusion = &fusion
+	}
+	sc.SignalSpeed = ss.sim.SignalSpeed()
+	sc.EstimateClockBias = ss.estimateClockBias
+	var errs []error
+	if boundary := ss.sim.BoundaryMode(); boundary != simulation.BoundaryBounce {
+		sc.Boundary = boundary.String()
+	}
+	if world := ss.sim.World(); world != nil {
+		spec, err := DescribeRegion(world)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("world: %w", err))
+		} else {
+			sc.World = &spec
+		}
+	}
+	if region := ss.sim.RegionOfInterest(); region != nil {
+		spec, err := DescribeRegion(region)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			sc.RegionOfInterest = &spec
+		}
+	}
+	for _, sen := range ss.sim.GetSensors() {
+		spec := SensorSpec{
+			ID:                  sen.GetID(),
+			Label:               sen.Label(),
+			Position:            sen.GetPosition(),
+			Radius:              sen.DetectionRadius(),
+			MeasurementInterval: sen.MeasurementInterval(),
+			Latency:             sen.Latency(),
+			Dropout:             sen.DropoutProbability(),
+			RangeVariance:       sen.RangeVariance(),
+			FalseAlarmRate:      sen.FalseAlarmRate(),
+			CalibrationOffset:   sen.CalibrationOffset(),
+		}
+		if clock := sen.Clock(); clock != (simulation.Clock{}) {
+			spec.Clock = &clock
+		}
+		if failure := sen.FailureModel(); failure != (simulation.FailureModel{}) {
+			spec.Failure = &failure
+		}
+		if noise, ok := ss.SensorNoise(sen.GetID()); ok {
+			spec.Noise = &noise
+		}
+		if sen.MotionModel() != nil {
+			motion, err := DescribeMotion(sen.MotionModel())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sensor %s: %w", sen.GetID(), err))
+			} else {
+				spec.Motion = &motion
+			}
+		}
+		sc.Sensors = append(sc.Sensors, spec)
+	}
+	// Spawn and despawn times are relative to now, when the captured scenario starts
+	now := ss.sim.GetCurrentTime()
+	var targets []*simulation.Target
+	targets = append(targets, ss.sim.GetTargets()...)
+	for _, obj := range ss.sim.PendingSpawns() {
+		if tar, ok := obj.(*simulation.Target); ok {
+			targets = append(targets, tar)
+		}
+	}
+	for _, tar := range targets {
+		spec := TargetSpec{ID: tar.GetID(), Label: tar.Label(), Position: tar.GetPosition(), Velocity: tar.GetVelocity(), Class: tar.Class()}
+		if tar.MotionModel() != nil {
+			motion, err := DescribeMotion(tar.MotionModel())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("target %s: %w", tar.GetID(), err))
+			} else {
+				spec.Motion = &motion
+			}
+		} else if sc.Classes[spec.Class].Motion != nil {
+			spec.Motion = &MotionSpec{Type: "static"} // Not the moving class default
+		}
+		if noise, ok := ss.TargetNoise(tar.GetID()); ok {
+			spec.Noise = &noise
+		} else if sc.Classes[spec.Class].Noise != nil {
+			spec.Noise = &NoiseSpec{Type: "none"}
+		}
+		if schedule, ok := tar.EmissionSchedule(); ok {
+			spec.Emission = &schedule
+		}
+		if clock := tar.Clock(); clock != (simulation.Clock{}) {
+			spec.Clock = &clock
+		}
+		if at, ok := ss.sim.SpawnTime(tar.GetID()); ok {
+			spec.SpawnAt = math.Max(at-now, 0)
+		}
+		if at, ok := ss.sim.DespawnTime(tar.GetID()); ok {
+			spec.DespawnAt = math.Max(at-now, 0)
+		}
+		sc.Targets = append(sc.Targets, spec)
+	}
+	for _, obs := range ss.sim.GetObstacles() {
+		shape, err := DescribeRegion(obs.Shape())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("obstacle %s: %w", obs.GetID(), err))
+			continue
+		}
+		sc.Obstacles = append(sc.Obstacles, ObstacleSpec{Shape: shape, NLOSBias: obs.NLOSBias()})
+	}
+	// Events still to happen keep their text, so sensor-N and target-N then count in
+	// the captured order and names given by earlier events are lost
+	for _, event := range ss.Timeline() {
+		event.At = math.Max(event.At-now, 0)
+		sc.Timeline = append(sc.Timeline, event.String())
+	}
+	return sc, errors.Join(errs...)
+}
+
+// Save captures the simulation and writes it to path. The file is written even if
+// some motion models could not be described; that error is returned afterwards.
+func (ss *Session) Save(path string) error {
+	sc, captureErr := ss.Capture()
+	if err := sc.Save(path); err != nil {
+		return err
+	}
+	return captureErr
+}
@@ -0,0 +1,243 @@
+package scenario
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"strings"
+)
+
+// NoiseSpec describes a range noise function. Only the fields of the given Type are used.
+type NoiseSpec struct {
+	Type        string      `json:"type"` // none, gaussian, uniform, percentage, nlos, bias, quantization, chain
+	StdDev      float64     `json:"std_dev,omitempty"`
+	MaxDelta    float64     `json:"max_delta,omitempty"`
+	Percentage  float64     `json:"percentage,omitempty"`
+	Probability float64     `json:"probability,omitempty"` // nlos
+	MeanBias    float64     `json:"mean_bias,omitempty"`   // nlos
+	Offset      float64     `json:"offset,omitempty"`      // bias
+	Step        float64     `json:"step,omitempty"`        // quantization
+	Chain       []NoiseSpec `json:"chain,omitempty"`       // Applied in order
+}
+
+// Build creates the noise function.
+func (n NoiseSpec) Build() (simulation.NoiseFunction, error) {
+	switch n.Type {
+	case "", "none":
+		return simulation.NoNoise, nil
+	case "gaussian":
+		return simulation.GaussianNoise(n.StdDev), nil
+	case "uniform":
+		return simulation.UniformNoise(n.MaxDelta), nil
+	case "percentage":
+		return simulation.PercentageNoise(n.Percentage), nil
+	case "nlos":
+		return simulation.NLOSNoise(n.Probability, n.MeanBias), nil
+	case "bias":
+		return simulation.BiasNoise(n.Offset), nil
+	case "quantization":
+		return simulation.QuantizationNoise(n.Step), nil
+	case "chain":
+		fns := make([]simulation.NoiseFunction, len(n.Chain))
+		for i, link := range n.Chain {
+			fn, err := link.Build()
+			if err != nil {
+				return nil, fmt.Errorf("chain element %d: %w", i, err)
+			}
+			fns[i] = fn
+		}
+		return simulation.ChainNoise(fns...), nil
+	default:
+		return nil, fmt.Errorf("unknown noise type %q", n.Type)
+	}
+}
+
+// String returns a short description, e.g. "gaussian(σ=0.5)".
+func (n NoiseSpec) String() string {
+	switch n.Type {
+	case "", "none":
+		return "none"
+	case "gaussian":
+		return fmt.Sprintf("gaussian(σ=%g)", n.StdDev)
+	case "uniform":
+		return fmt.Sprintf("uniform(±%g)", n.MaxDelta)
+	case "percentage":
+		return fmt.Sprintf("percentage(%g%%)", n.Percentage*100)
+	case "nlos":
+		return fmt.Sprintf("nlos(p=%g, bias=%g)", n.Probability, n.MeanBias)
+	case "bias":
+		return fmt.Sprintf("bias(%+g)", n.Offset)
+	case "quantization":
+		return fmt.Sprintf("quantization(%g)", n.Step)
+	case "chain":
+		links := make([]string, len(n.Chain))
+		for i, link := range n.Chain {
+			links[i] = link.String()
+		}
+		return strings.Join(links, " -> ")
+	default:
+		return n.Type
+	}
+}
+
+// ClassSpec holds the defaults of a target class, used by targets of the class that do
+// not set them themselves.
+type ClassSpec struct {
+	Motion *MotionSpec `json:"motion,omitempty"` // nil means static
+	Noise  *NoiseSpec  `json:"noise,omitempty"`  // Extra range noise, see simulation.Target.SetRangeNoise
+}
+
+// MotionSpec describes a motion model. Only the fields of the given Type are used.
+type MotionSpec struct {
+	Type string `json:"type"` // static, random_walk, constant_velocity, ornstein_uhlenbeck, waypoint, circular
+
+	AccelerationScale float64 `json:"acceleration_scale,omitempty"` // random_walk
+	MaxSpeed          float64 `json:"max_speed,omitempty"`          // random_walk
+
+	Theta        float64   `json:"theta,omitempty"` // ornstein_uhlenbeck
+	Sigma        float64   `json:"sigma,omitempty"`
+	MeanVelocity []float64 `json:"mean_velocity,omitempty"`
+
+	Waypoints [][]float64 `json:"waypoints,omitempty"` // waypoint
+	Speed     float64     `json:"speed,omitempty"`
+	Loop      bool        `json:"loop,omitempty"`
+
+	Center       []float64 `json:"center,omitempty"` // circular
+	Radius       float64   `json:"radius,omitempty"`
+	AngularSpeed float64   `json:"angular_speed,omitempty"`
+	Axes         []int     `json:"axes,omitempty"` // Two axes spanning the orbital plane, default [0, 1]
+}
+
+// Build creates the motion model. "static" returns nil, i.e. an object that does not move.
+func (m MotionSpec) Build() (simulation.MotionModel, error) {
+	switch m.Type {
+	case "", "static":
+		return nil, nil
+	case "random_walk":
+		return &simulation.RandomWalkMotion{AccelerationScale: m.AccelerationScale, MaxSpeed: m.MaxSpeed}, nil
+	case "constant_velocity":
+		return simulation.NewConstantVelocityMotion(), nil
+	case "ornstein_uhlenbeck":
+		ou := simulation.NewOrnsteinUhlenbeckMotion(m.Theta, m.Sigma)
+		if m.MeanVelocity != nil {
+			ou.MeanVelocity = common.Vector(m.MeanVelocity)
+		}
+		return ou, nil
+	case "waypoint":
+		waypoints := make([]common.Vector, len(m.Waypoints))
+		for i, wp := range m.Waypoints {
+			waypoints[i] = common.Vector(wp)
+		}
+		return simulation.NewWaypointMotion(waypoints, m.Speed, m.Loop)
+	case "circular":
+		axes := m.Axes
+		if axes == nil {
+			axes = []int{0, 1}
+		}
+		if len(axes) != 2 {
+			return nil, fmt.Errorf("circular motion needs exactly 2 axes, got %d", len(axes))
+		}
+		return simulation.NewCircularMotion(common.Vector(m.Center), m.Radius, m.AngularSpeed, axes[0], axes[1])
+	default:
+		return nil, fmt.Errorf("unknown motion type %q", m.Type)
+	}
+}
+
+// DescribeMotion returns the specification of one of the built-in motion models.
+// Waypoint and circular models restart from their beginning when rebuilt.
+func DescribeMotion(motion simulation.MotionModel) (MotionSpec, error) {
+	switch m := motion.(type) {
+	case nil:
+		return MotionSpec{Type: "static"}, nil
+	case *simulation.RandomWalkMotion:
+		return MotionSpec{Type: "random_walk", AccelerationScale: m.AccelerationScale, MaxSpeed: m.MaxSpeed}, nil
+	case *simulation.ConstantVelocityMotion:
+		return MotionSpec{Type: "constant_velocity"}, nil
+	case *simulation.OrnsteinUhlenbeckMotion:
+		return MotionSpec{Type: "ornstein_uhlenbeck", Theta: m.Theta, Sigma: m.Sigma, MeanVelocity: m.MeanVelocity.Clone()}, nil
+	case *simulation.WaypointMotion:
+		spec := MotionSpec{Type: "waypoint", Speed: m.Speed(), Loop: m.Loop()}
+		for _, wp := range m.Waypoints() {
+			spec.Waypoints = append(spec.Waypoints, wp)
+		}
+		return spec, nil
+	case *simulation.CircularMotion:
+		u, v := m.Axes()
+		return MotionSpec{Type: "circular", Center: m.Center(), Radius: m.Radius(), AngularSpeed: m.AngularSpeed(), Axes: []int{u, v}}, nil
+	default:
+		return MotionSpec{}, fmt.Errorf("motion model %T cannot be saved", motion)
+	}
+}
+
+// ObstacleSpec describes an obstacle.
+type ObstacleSpec struct {
+	Shape    RegionSpec `json:"shape"`               // box or ball
+	NLOSBias float64    `json:"nlos_bias,omitempty"` // 0 blocks measurements through the obstacle
+}
+
+// Build creates the obstacle.
+func (o ObstacleSpec) Build() (*simulation.Obstacle, error) {
+	shape, err := o.Shape.Build()
+	if err != nil {
+		return nil, err
+	}
+	obs, err := simulation.NewObstacle(shape)
+	if err != nil {
+		return nil, err
+	}
+	if o.NLOSBias < 0 {
+		return nil, fmt.Errorf("nlos_bias must not be negative, got %g", o.NLOSBias)
+	}
+	obs.SetNLOSBias(o.NLOSBias)
+	return obs, nil
+}
+
+// RegionSpec describes a region of interest or the shape of an obstacle.
+type RegionSpec struct {
+	Type string `json:"type"` // box, ball, polytope
+
+	Min []float64 `json:"min,omitempty"` // box
+	Max []float64 `json:"max,omitempty"`
+
+	Center []float64 `json:"center,omitempty"` // ball
+	Radius float64   `json:"radius,omitempty"`
+
+	Normals [][]float64 `json:"normals,omitempty"` // polytope: normal·x <= offset per face
+	Offsets []float64   `json:"offsets,omitempty"`
+}
+
+// Build creates the region.
+func (r RegionSpec) Build() (simulation.Region, error) {
+	switch r.Type {
+	case "box":
+		return simulation.NewBoxRegion(common.Vector(r.Min), common.Vector(r.Max))
+	case "ball":
+		return simulation.NewBallRegion(common.Vector(r.Center), r.Radius)
+	case "polytope":
+		normals := make([]common.Vector, len(r.Normals))
+		for i, n := range r.Normals {
+			normals[i] = n
+		}
+		return simulation.NewPolytopeRegion(normals, r.Offsets)
+	default:
+		return nil, fmt.Errorf("unknown region type %q", r.Type)
+	}
+}
+
+// DescribeRegion returns the specification of one of the built-in regions.
+func DescribeRegion(region simulation.Region) (RegionSpec, error) {
+	switch r := region.(type) {
+	case *simulation.BoxRegion:
+		return RegionSpec{Type: "box", Min: r.Min(), Max: r.Max()}, nil
+	case *simulation.BallRegion:
+		return RegionSpec{Type: "ball", Center: r.Center(), Radius: r.Radius()}, nil
+	case *simulation.PolytopeRegion:
+		spec := RegionSpec{Type: "polytope", Offsets: r.Offsets()}
+		for _, n := range r.Normals() {
+			spec.Normals = append(spec.Normals, n)
+		}
+		return spec, nil
+	default:
+		return RegionSpec{}, fmt.Errorf("region %T cannot be saved", region)
+	}
+}
@@ -0,0 +1,159 @@
+package scenario
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// surveyHeader is the column layout of survey CSV files.
+var surveyHeader = []string{"id", "x", "y", "z", "accuracy"}
+
+// SurveyPoint is one surveyed anchor as found in deployment survey sheets.
+type SurveyPoint struct {
+	ID       string
+	Position []float64 // x, y and, for 3D surveys, z
+	Accuracy float64   // 1-sigma position accuracy (units), 0 if unknown
+}
+
+// ReadSurveyCSV reads anchors in the simple surveying layout "id,x,y,z,accuracy".
+// z and accuracy may be empty or missing, e.g. for 2D surveys; a header row and lines
+// starting with # are skipped.
+func ReadSurveyCSV(r io.Reader) ([]SurveyPoint, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var points []SurveyPoint
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read survey: %w", err)
+		}
+		if line == 1 && len(record) > 1 && strings.EqualFold(strings.TrimSpace(record[1]), "x") {
+			continue // Header
+		}
+		point, err := parseSurveyRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("survey record %d: %w", line, err)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+func parseSurveyRecord(record []string) (SurveyPoint, error) {
+	if len(record) < 3 || len(record) > len(surveyHeader) {
+		return SurveyPoint{}, fmt.Errorf("expected 3 to %d fields, got %d", len(surveyHeader), len(record))
+	}
+	point := SurveyPoint{ID: strings.TrimSpace(record[0])}
+	if point.ID == "" {
+		return SurveyPoint{}, fmt.Errorf("missing id")
+	}
+	for i := 1; i < len(record) && i <= 3; i++ {
+		field := strings.TrimSpace(record[i])
+		if field == "" && i == 3 {
+			break // 2D survey
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return SurveyPoint{}, fmt.Errorf("%s: %w", surveyHeader[i], err)
+		}
+		point.Position = append(point.Position, v)
+	}
+	if len(record) == 5 && strings.TrimSpace(record[4]) != "" {
+		acc, err := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+		if err != nil {
+			return SurveyPoint{}, fmt.Errorf("accuracy: %w", err)
+		}
+		if acc < 0 {
+			return SurveyPoint{}, fmt.Errorf("accuracy must not be negative, got %g", acc)
+		}
+		point.Accuracy = acc
+	}
+	return point, nil
+}
+
+// WriteSurveyCSV writes anchors in the layout read by ReadSurveyCSV. Unknown
+// accuracies and the z of 2D points are left empty.
+func WriteSurveyCSV(w io.Writer, points []SurveyPoint) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(surveyHeader); err != nil {
+		return err
+	}
+	for _, p := range points {
+		if len(p.Position) < 2 || len(p.Position) > 3 {
+			return fmt.Errorf("anchor %s: surveys hold 2D or 3D positions, got dimension %d", p.ID, len(p.Position))
+		}
+		record := []string{p.ID, "", "", "", ""}
+		for i, v := range p.Position {
+			record[i+1] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		if p.Accuracy > 0 {
+			record[4] = strconv.FormatFloat(p.Accuracy, 'f', -1, 64)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// SurveySensors turns surveyed anchors into sensor specifications of the given
+// dimension (2 or 3) and detection radius. The survey accuracy is declared as the
+// range variance (accuracy²), since an anchor misplaced by sigma shifts its ranges by
+// up to sigma; weighted solvers then trust well-surveyed anchors more.
+func SurveySensors(points []SurveyPoint, dimension int, radius float64) ([]SensorSpec, error) {
+	if dimension != 2 && dimension != 3 {
+		return nil, fmt.Errorf("surveys describe 2D or 3D layouts, got dimension %d", dimension)
+	}
+	specs := make([]SensorSpec, 0, len(points))
+	for _, p := range points {
+		if len(p.Position) < dimension {
+			return nil, fmt.Errorf("anchor %s has %d coordinates, need %d", p.ID, len(p.Position), dimension)
+		}
+		specs = append(specs, SensorSpec{
+			Position:      append([]float64(nil), p.Position[:dimension]...),
+			Radius:        radius,
+			RangeVariance: p.Accuracy * p.Accuracy,
+		})
+	}
+	return specs, nil
+}
+
+// AddSurvey appends the surveyed anchors to the sensors of the scenario.
+func (sc *Scenario) AddSurvey(points []SurveyPoint, radius float64) error {
+	specs, err := SurveySensors(points, sc.Dimension, radius)
+	if err != nil {
+		return err
+	}
+	sc.Sensors = append(sc.Sensors, specs...)
+	return nil
+}
+
+// Survey describes the current sensor layout as survey points, using the sensor IDs
+// and the square root of the declared range variance as accuracy. Only 2D and 3D
+// simulations can be expressed as surveys.
+func (ss *Session) Survey() ([]SurveyPoint, error) {
+	if dim := ss.sim.GetDimension(); dim != 2 && dim != 3 {
+		return nil, fmt.Errorf("surveys describe 2D or 3D layouts, got dimension %d", dim)
+	}
+	var points []SurveyPoint
+	for _, sen := range ss.sim.GetSensors() {
+		points = append(points, SurveyPoint{
+			ID:       sen.GetID(),
+			Position: sen.GetPosition(),
+			Accuracy: math.Sqrt(sen.RangeVariance()),
+		})
+	}
+	return points, nil
+}
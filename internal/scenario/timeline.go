@@ -0,0 +1,454 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A timeline scripts changes to a running scenario, one event per line:
+//
+//	at 10s: add sensor as relay {"position": [0, 50], "radius": 80}
+//	at 30s: set noise of sensor-2 to gaussian(2.0) -> bias(0.5)
+//	at 45s: set radius of relay to 40
+//	at 50s: maneuver target-1 turn(90, 2)
+//	at 60s: remove target-0
+//
+// Times are Go durations or plain seconds. Objects are referred to by ID (designated IDs
+// from the scenario file take precedence), by the name given with "add ... as NAME", or
+// as sensor-N / target-N, the N-th (from 0) sensor or target of the scenario file.
+// Commands:
+//
+//	add sensor [as NAME] SENSOR_JSON     add target [as NAME] TARGET_JSON
+//	remove REF
+//	set noise of REF to NOISE            none, gaussian(σ), uniform(max), percentage(p),
+//	                                     nlos(p, bias), bias(offset), quantization(step),
+//	                                     joined with -> to chain them, or a noise JSON object;
+//	                                     for a target, the extra noise of its ranges
+//	set radius|dropout|latency|variance of REF to NUMBER
+//	set position|velocity of REF to [x, y, ...]
+//	maneuver REF MANEUVER                turn(degrees[, seconds]), burst(accel, seconds),
+//	                                     stop(seconds); see simulation.Maneuver
+
+// TimelineEvent is one parsed line of a timeline.
+type TimelineEvent struct {
+	At      float64 // Simulation time in seconds
+	Command string  // The line after "at TIME:", as written
+
+	verb     string // add, remove, set or maneuver
+	kind     string // add: sensor or target
+	name     string // add: optional name
+	ref      string // remove, set, maneuver: object reference
+	property string // set
+	sensor   SensorSpec
+	target   TargetSpec
+	noise    NoiseSpec
+	number   float64
+	vector   common.Vector
+	maneuver simulation.Maneuver
+}
+
+// ParseTimeline parses timeline lines; blank lines and lines starting with # are
+// skipped. Events are returned ordered by time, keeping the order of equal times.
+func ParseTimeline(lines []string) ([]TimelineEvent, error) {
+	var events []TimelineEvent
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		event, err := parseEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("timeline line %d: %w", i+1, err)
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+	return events, nil
+}
+
+// String returns the event as a timeline line.
+func (e TimelineEvent) String() string {
+	return fmt.Sprintf("at %gs: %s", e.At, e.Command)
+}
+
+func parseEvent(line string) (TimelineEvent, error) {
+	rest, ok := strings.CutPrefix(line, "at ")
+	if !ok {
+		return TimelineEvent{}, fmt.Errorf("expected \"at TIME: COMMAND\", got %q", line)
+	}
+	timeText, command, ok := strings.Cut(rest, ":")
+	if !ok {
+		return TimelineEvent{}, fmt.Errorf("missing ':' after the time in %q", line)
+	}
+	at, err := parseTime(strings.TrimSpace(timeText))
+	if err != nil {
+		return TimelineEvent{}, err
+	}
+	e := TimelineEvent{At: at, Command: strings.TrimSpace(command)}
+	verb, args, _ := strings.Cut(e.Command, " ")
+	args = strings.TrimSpace(args)
+	switch verb {
+	case "add":
+		err = e.parseAdd(args)
+	case "remove":
+		e.verb, e.ref = verb, args
+		if e.ref == "" || strings.ContainsAny(e.ref, " \t") {
+			err = fmt.Errorf("expected \"remove REF\", got %q", e.Command)
+		}
+	case "set":
+		err = e.parseSet(args)
+	case "maneuver":
+		e.verb = verb
+		ref, spec, _ := strings.Cut(args, " ")
+		if e.ref = ref; ref == "" {
+			err = fmt.Errorf("expected \"maneuver REF MANEUVER\", got %q", e.Command)
+		} else {
+			e.maneuver, err = ParseManeuver(spec)
+		}
+	default:
+		err = fmt.Errorf("unknown command %q, expected add, remove, set or maneuver", verb)
+	}
+	return e, err
+}
+
+// parseTime accepts a Go duration ("1m30s") or seconds ("90").
+func parseTime(text string) (float64, error) {
+	if seconds, err := strconv.ParseFloat(text, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("negative time %q", text)
+		}
+		return seconds, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", text)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative time %q", text)
+	}
+	return d.Seconds(), nil
+}
+
+func (e *TimelineEvent) parseAdd(args string) error {
+	e.verb = "add"
+	kind, spec, _ := strings.Cut(args, " ")
+	spec = strings.TrimSpace(spec)
+	if name, ok := strings.CutPrefix(spec, "as "); ok {
+		name = strings.TrimSpace(name)
+		e.name, spec, _ = strings.Cut(name, " ")
+		spec = strings.TrimSpace(spec)
+	}
+	e.kind = kind
+	var err error
+	switch kind {
+	case "sensor":
+		err = json.Unmarshal([]byte(spec), &e.sensor)
+	case "target":
+		err = json.Unmarshal([]byte(spec), &e.target)
+	default:
+		return fmt.Errorf("expected \"add sensor\" or \"add target\", got %q", e.Command)
+	}
+	if err != nil {
+		return fmt.Errorf("%s specification: %w", kind, err)
+	}
+	return nil
+}
+
+func (e *TimelineEvent) parseSet(args string) error {
+	e.verb = "set"
+	property, rest, _ := strings.Cut(args, " of ")
+	ref, value, ok := strings.Cut(rest, " to ")
+	e.property, e.ref, value = strings.TrimSpace(property), strings.TrimSpace(ref), strings.TrimSpace(value)
+	if !ok || e.ref == "" || value == "" {
+		return fmt.Errorf("expected \"set PROPERTY of REF to VALUE\", got %q", e.Command)
+	}
+	var err error
+	switch e.property {
+	case "noise":
+		e.noise, err = ParseNoise(value)
+	case "radius", "dropout", "latency", "variance":
+		e.number, err = strconv.ParseFloat(value, 64)
+	case "position", "velocity":
+		err = json.Unmarshal([]byte(value), &e.vector)
+	default:
+		return fmt.Errorf("unknown property %q, expected noise, radius, dropout, latency, variance, position or velocity", e.property)
+	}
+	if err != nil {
+		return fmt.Errorf("%s value %q: %w", e.property, value, err)
+	}
+	return nil
+}
+
+// noiseParameters lists the NoiseSpec fields set by the arguments of each noise type.
+var noiseParameters = map[string]func(n *NoiseSpec) []*float64{
+	"none":         func(n *NoiseSpec) []*float64 { return nil },
+	"gaussian":     func(n *NoiseSpec) []*float64 { return []*float64{&n.StdDev} },
+	"uniform":      func(n *NoiseSpec) []*float64 { return []*float64{&n.MaxDelta} },
+	"percentage":   func(n *NoiseSpec) []*float64 { return []*float64{&n.Percentage} },
+	"nlos":         func(n *NoiseSpec) []*float64 { return []*float64{&n.Probability, &n.MeanBias} },
+	"bias":         func(n *NoiseSpec) []*float64 { return []*float64{&n.Offset} },
+	"quantization": func(n *NoiseSpec) []*float64 { return []*float64{&n.Step} },
+}
+
+// ParseNoise parses a noise expression like "gaussian(2.0)" or "nlos(0.1, 5) -> bias(1)"
+// (a chain), or a NoiseSpec JSON object.
+func ParseNoise(text string) (NoiseSpec, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "{") {
+		var spec NoiseSpec
+		if err := json.Unmarshal([]byte(text), &spec); err != nil {
+			return NoiseSpec{}, err
+		}
+		_, err := spec.Build()
+		return spec, err
+	}
+	if links := strings.Split(text, "->"); len(links) > 1 {
+		spec := NoiseSpec{Type: "chain"}
+		for _, link := range links {
+			n, err := ParseNoise(link)
+			if err != nil {
+				return NoiseSpec{}, err
+			}
+			spec.Chain = append(spec.Chain, n)
+		}
+		return spec, nil
+	}
+	name, args, hasArgs := strings.Cut(text, "(")
+	name = strings.TrimSpace(name)
+	parameters, ok := noiseParameters[name]
+	if !ok {
+		return NoiseSpec{}, fmt.Errorf("unknown noise type %q", name)
+	}
+	spec := NoiseSpec{Type: name}
+	fields := parameters(&spec)
+	var values []string
+	if hasArgs {
+		inner, ok := strings.CutSuffix(strings.TrimSpace(args), ")")
+		if !ok {
+			return NoiseSpec{}, fmt.Errorf("missing ')' in %q", text)
+		}
+		if inner = strings.TrimSpace(inner); inner != "" {
+			values = strings.Split(inner, ",")
+		}
+	}
+	if len(values) != len(fields) {
+		return NoiseSpec{}, fmt.Errorf("%s takes %d arguments, got %d", name, len(fields), len(values))
+	}
+	for i, v := range values {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return NoiseSpec{}, fmt.Errorf("%s argument %d: %w", name, i+1, err)
+		}
+		*fields[i] = f
+	}
+	return spec, nil
+}
+
+// maneuverArguments is the number of arguments of each maneuver, required and in all.
+var maneuverArguments = map[simulation.ManeuverKind][2]int{
+	simulation.ManeuverTurn:      {1, 2},
+	simulation.ManeuverBurst:     {2, 2},
+	simulation.ManeuverStopAndGo: {1, 1},
+}
+
+// ParseManeuver parses a maneuver like "turn(90)" (degrees, at once), "turn(90, 2)" (over
+// 2 seconds), "burst(5, 3)" (5 units/s^2 for 3 seconds) or "stop(4)". The start is left 0.
+func ParseManeuver(text string) (simulation.Maneuver, error) {
+	text = strings.TrimSpace(text)
+	name, args, _ := strings.Cut(text, "(")
+	kind, err := simulation.ParseManeuverKind(strings.TrimSpace(name))
+	if err != nil {
+		return simulation.Maneuver{}, err
+	}
+	inner, ok := strings.CutSuffix(strings.TrimSpace(args), ")")
+	if !ok {
+		return simulation.Maneuver{}, fmt.Errorf("expected %s(...), got %q", kind, text)
+	}
+	var values []float64
+	if inner = strings.TrimSpace(inner); inner != "" {
+		for i, v := range strings.Split(inner, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return simulation.Maneuver{}, fmt.Errorf("%s argument %d: %w", kind, i+1, err)
+			}
+			values = append(values, f)
+		}
+	}
+	count := maneuverArguments[kind]
+	if len(values) < count[0] || len(values) > count[1] {
+		if count[0] == count[1] {
+			return simulation.Maneuver{}, fmt.Errorf("%s takes %d arguments, got %d", kind, count[0], len(values))
+		}
+		return simulation.Maneuver{}, fmt.Errorf("%s takes %d or %d arguments, got %d", kind, count[0], count[1], len(values))
+	}
+	m := simulation.Maneuver{Kind: kind}
+	switch kind {
+	case simulation.ManeuverTurn:
+		m.Angle = values[0] * math.Pi / 180
+		if len(values) > 1 {
+			m.Duration = values[1]
+		}
+	case simulation.ManeuverBurst:
+		m.Acceleration, m.Duration = values[0], values[1]
+	default:
+		m.Duration = values[0]
+	}
+	return m, m.Validate()
+}
+
+// timeline holds the events of a session that have not happened yet.
+type timeline struct {
+	pending []TimelineEvent
+	names   map[string]string // Reference -> object ID
+	hooked  bool              // handleTimelineStep is subscribed to the simulation
+}
+
+// resolve returns the object ID a reference stands for.
+func (t *timeline) resolve(ref string) string {
+	if id, ok := t.names[ref]; ok {
+		return id
+	}
+	return ref
+}
+
+// SetTimeline replaces the events still to happen. Events at or before the current
+// simulation time run immediately, the others after the first step that reaches them.
+func (ss *Session) SetTimeline(events []TimelineEvent) {
+	ss.mu.Lock()
+	ss.timeline.pending = append([]TimelineEvent(nil), events...)
+	sort.SliceStable(ss.timeline.pending, func(i, j int) bool { return ss.timeline.pending[i].At < ss.timeline.pending[j].At })
+	hook := !ss.timeline.hooked && len(events) > 0
+	ss.timeline.hooked = ss.timeline.hooked || hook
+	ss.mu.Unlock()
+	if hook {
+		ss.sim.OnStep(ss.handleTimelineStep)
+	}
+	ss.runTimeline(ss.sim.GetCurrentTime())
+}
+
+// Timeline returns the events that have not happened yet.
+func (ss *Session) Timeline() []TimelineEvent {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return append([]TimelineEvent(nil), ss.timeline.pending...)
+}
+
+// nameObject makes ref refer to the object id in timeline commands.
+func (ss *Session) nameObject(ref, id string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.timeline.names == nil {
+		ss.timeline.names = make(map[string]string)
+	}
+	ss.timeline.names[ref] = id
+}
+
+// handleTimelineStep runs the events reached by a step.
+func (ss *Session) handleTimelineStep(report simulation.StepReport) {
+	ss.runTimeline(report.Time)
+}
+
+// runTimeline runs the events due at time now, in order. A failing event is logged and
+// dropped, like a failing scheduled spawn.
+func (ss *Session) runTimeline(now float64) {
+	for {
+		ss.mu.Lock()
+		if len(ss.timeline.pending) == 0 || ss.timeline.pending[0].At > now+1e-9 {
+			ss.mu.Unlock()
+			return
+		}
+		event := ss.timeline.pending[0]
+		ss.timeline.pending = ss.timeline.pending[1:]
+		ss.mu.Unlock()
+		if err := ss.apply(event); err != nil {
+			ss.sim.Logger().Warn("timeline event failed", "event", event.String(), "err", err)
+		} else {
+			ss.sim.Logger().Info("timeline event", "sim_time", now, "event", event.String())
+		}
+	}
+}
+
+// apply performs one event on the simulation.
+func (ss *Session) apply(e TimelineEvent) error {
+	if e.verb == "add" {
+		var id string
+		var err error
+		if e.kind == "sensor" {
+			id, err = ss.AddSensor(e.sensor)
+		} else {
+			id, err = ss.AddTarget(e.target)
+		}
+		if err == nil && e.name != "" {
+			ss.nameObject(e.name, id)
+		}
+		return err
+	}
+
+	id := e.ref
+	if _, ok := ss.sim.GetObject(id); !ok {
+		ss.mu.Lock()
+		id = ss.timeline.resolve(e.ref)
+		ss.mu.Unlock()
+	}
+	if e.verb == "remove" {
+		return ss.RemoveObject(id)
+	}
+	if e.verb == "maneuver" {
+		m := e.maneuver
+		m.Start = e.At
+		return ss.sim.InjectManeuver(id, m)
+	}
+	obj, ok := ss.sim.GetObject(id)
+	if !ok {
+		return fmt.Errorf("object %s does not exist", e.ref)
+	}
+	switch e.property {
+	case "position":
+		return obj.SetPosition(e.vector)
+	case "velocity":
+		moving, ok := obj.(interface{ SetVelocity(common.Vector) error })
+		if !ok {
+			return fmt.Errorf("%s has no velocity", e.ref)
+		}
+		return moving.SetVelocity(e.vector)
+	}
+	if target, ok := obj.(*simulation.Target); ok && e.property == "noise" {
+		noise, err := e.noise.Build()
+		if err != nil {
+			return err
+		}
+		target.SetRangeNoise(noise)
+		ss.SetTargetNoise(id, e.noise)
+		return nil
+	}
+	sensor, ok := obj.(*simulation.Sensor)
+	if !ok {
+		return fmt.Errorf("%s of %s: only sensors have this property", e.property, e.ref)
+	}
+	switch e.property {
+	case "noise":
+		noise, err := e.noise.Build()
+		if err != nil {
+			return err
+		}
+		sensor.SetNoiseFunction(noise)
+		ss.SetSensorNoise(id, e.noise)
+		return nil
+	case "radius":
+		return sensor.SetDetectionRadius(e.number)
+	case "dropout":
+		return sensor.SetDropoutProbability(e.number)
+	case "latency":
+		return sensor.SetLatency(e.number)
+	default: // variance
+		sensor.SetRangeVariance(e.number)
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+)
+
+// Metric names maintained by Step for the stepping rate.
+const (
+	MetricStepDuration = "step_duration" // Gauge: seconds simulated by the last step
+	MetricStepRate     = "step_rate"     // Gauge: steps (measurement and solve rounds) per simulated second
+)
+
+// maxStepGrowth limits how fast the adaptive step may lengthen, so that one quiet step
+// after a burst does not jump straight to the longest step.
+const maxStepGrowth = 1.5
+
+// AdaptiveStepping chooses the step length from the scene: short steps (a higher
+// measurement and solve rate) while targets move fast or estimates are uncertain, long
+// steps while the scene is quiet.
+type AdaptiveStepping struct {
+	MinStep float64 // Shortest step in seconds
+	MaxStep float64 // Longest step in seconds
+
+	// MaxDisplacement is the distance (units) the fastest target may travel in one step.
+	MaxDisplacement float64
+	// UncertaintyThreshold is the position standard deviation (units, sqrt of the
+	// covariance trace) above which the shortest step is used. Targets without an
+	// estimate count as uncertain. 0 disables the uncertainty criterion.
+	UncertaintyThreshold float64
+}
+
+// DefaultAdaptiveStepping steps between a quarter and four times the nominal tick,
+// allowing 1 unit of target travel per step and 5 units of position uncertainty.
+func DefaultAdaptiveStepping(tick float64) AdaptiveStepping {
+	return AdaptiveStepping{MinStep: tick / 4, MaxStep: tick * 4, MaxDisplacement: 1, UncertaintyThreshold: 5}
+}
+
+// Validate checks that the configuration is usable.
+func (a AdaptiveStepping) Validate() error {
+	if a.MinStep <= 0 || a.MaxStep < a.MinStep {
+		return fmt.Errorf("adaptive steps must satisfy 0 < min <= max, got [%f, %f]", a.MinStep, a.MaxStep)
+	}
+	if a.MaxDisplacement <= 0 {
+		return fmt.Errorf("%w: max displacement must be positive, got %f", ErrOutOfRange, a.MaxDisplacement)
+	}
+	if a.UncertaintyThreshold < 0 {
+		return fmt.Errorf("%w: uncertainty threshold must be non-negative, got %f", ErrOutOfRange, a.UncertaintyThreshold)
+	}
+	return nil
+}
+
+// SetAdaptiveStepping enables adaptive stepping, nil returns to the fixed tick.
+// Drivers of the simulation ask NextStepDuration for the length of each step.
+func (s *Simulation) SetAdaptiveStepping(config *AdaptiveStepping) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config == nil {
+		s.adaptive = nil
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	c := *config
+	s.adaptive = &c
+	return nil
+}
+
+// AdaptiveStepping returns the adaptive stepping configuration, nil if disabled.
+func (s *Simulation) AdaptiveStepping() *AdaptiveStepping {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.adaptive == nil {
+		return nil
+	}
+	c := *s.adaptive
+	return &c
+}
+
+// NextStepDuration returns the length in seconds of the next step: the tick duration,
+// or with adaptive stepping the longest step that keeps every target within
+// MaxDisplacement, shortened to MinStep while an estimate is uncertain.
+func (s *Simulation) NextStepDuration() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a := s.adaptive
+	if a == nil {
+		return s.tickDuration.Seconds()
+	}
+	step := a.MaxStep
+	for _, tar := range s.sortedTargets() {
+		if speed := tar.GetVelocity().Norm(); speed > 0 {
+			step = math.Min(step, a.MaxDisplacement/speed)
+		}
+		if a.UncertaintyThreshold > 0 && !s.outsideRegion(tar) && s.uncertainty(tar.GetID()) > a.UncertaintyThreshold {
+			step = a.MinStep
+		}
+	}
+	if s.lastStep > 0 {
+		step = math.Min(step, s.lastStep*maxStepGrowth)
+	}
+	return math.Min(math.Max(step, a.MinStep), a.MaxStep)
+}
+
+// uncertainty returns the position standard deviation of the last estimate of a target,
+// +Inf if there is none. Estimates without a covariance are trusted.
+func (s *Simulation) uncertainty(targetID string) float64 {
+	solution, ok := s.lastEstimates[targetID]
+	if !ok || solution.Position == nil {
+		return math.Inf(1)
+	}
+	trace := 0.0
+	for i, row := range solution.Covariance {
+		if i < len(row) {
+			trace += row[i]
+		}
+	}
+	return math.Sqrt(trace)
+}
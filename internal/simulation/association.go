@@ -0,0 +1,163 @@
+package simulation
+
+import (
+	"math"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"sort"
+)
+
+// Metric names maintained by the data association mode.
+const (
+	MetricDetections      = "detections"      // Anonymous detections offered to the associator
+	MetricMisassociations = "misassociations" // Detections given (with probability >= 0.5) to the wrong target or taken for one though clutter
+	MetricUnassociated    = "unassociated"    // Detections no track claimed
+)
+
+// assocTrack is the association's belief about a target, built only from estimates.
+type assocTrack struct {
+	position  common.Vector
+	velocity  common.Vector // nil if unknown
+	sigma     float64       // Position uncertainty (units)
+	estimated bool          // position comes from an estimate, not from the cue
+}
+
+// SetAssociator switches the sensors to anonymous detections: every step the delivered
+// ranges of all targets are pooled without their target identity, and the associator
+// decides which of them belong to which target before solving. Tracks are cued with
+// the position of each target when association starts or the target is added (a
+// handover from a track initiation system); after that only the estimates are used,
+// predicted one step ahead with the estimated velocity or, if the solver gives none,
+// one smoothed from successive estimates. A target without an estimate keeps its last
+// one. nil restores direct, labeled measurements.
+func (s *Simulation) SetAssociator(a association.Associator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.associator = a
+	s.assocTracks = nil
+	if a == nil {
+		return
+	}
+	s.assocTracks = make(map[string]assocTrack, len(s.targets))
+	for id, tar := range s.targets {
+		s.cueTrack(id, tar)
+	}
+}
+
+// Associator returns the data association in use, nil if measurements are labeled.
+func (s *Simulation) Associator() association.Associator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.associator
+}
+
+// cueTrack starts the track of a target at its true position.
+func (s *Simulation) cueTrack(id string, tar *Target) {
+	s.assocTracks[id] = assocTrack{position: tar.GetPosition().Clone()}
+}
+
+// associate pools the available measurements of all targets and the false alarms of
+// the sensors as anonymous detections, lets the associator match them to the tracks and returns each target's share.
+func (s *Simulation) associate() map[string][]multilateration.Measurement {
+	var detections []multilateration.Measurement
+	var sources []string // True target of every detection, for scoring only
+	targets := s.sortedTargets()
+	for _, tar := range targets {
+		for _, m := range s.availableMeasurements(tar.GetID()) {
+			detections = append(detections, m)
+			sources = append(sources, tar.GetID())
+		}
+	}
+	for _, m := range s.availableClutter() {
+		detections = append(detections, m)
+		sources = append(sources, "") // Belongs to no target
+	}
+	// Report detections as a sensor would, by sensor and range, so their order says
+	// nothing about the target
+	order := make([]int, len(detections))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := detections[order[i]], detections[order[j]]
+		if s.order[a.SensorID] != s.order[b.SensorID] {
+			return s.order[a.SensorID] < s.order[b.SensorID]
+		}
+		return a.Distance < b.Distance
+	})
+	anonymous := make([]multilateration.Measurement, len(order))
+	truth := make([]string, len(order))
+	for i, j := range order {
+		anonymous[i], truth[i] = detections[j], sources[j]
+	}
+
+	tracks := make([]association.Track, 0, len(targets))
+	for _, tar := range targets {
+		track, ok := s.assocTracks[tar.GetID()]
+		if !ok {
+			continue
+		}
+		predicted := track.position
+		if track.velocity != nil {
+			if moved, err := track.position.Add(track.velocity.MultiplyByScalar(s.lastStep)); err == nil {
+				predicted = moved
+			}
+		}
+		tracks = append(tracks, association.Track{ID: tar.GetID(), Position: predicted, Sigma: track.sigma})
+	}
+
+	assignments := s.associator.Associate(tracks, anonymous)
+	s.metrics.Add(MetricDetections, float64(len(anonymous)))
+	claimed := make([]float64, len(anonymous))
+	for _, a := range assignments {
+		claimed[a.Detection] += a.Weight
+		if a.Weight >= 0.5 && truth[a.Detection] != a.TrackID {
+			s.metrics.Inc(MetricMisassociations)
+		}
+	}
+	for _, w := range claimed {
+		if w < 0.5 {
+			s.metrics.Inc(MetricUnassociated)
+		}
+	}
+
+	associated := make(map[string][]multilateration.Measurement, len(tracks))
+	for _, t := range tracks {
+		associated[t.ID] = association.TrackMeasurements(t.ID, assignments, anonymous)
+	}
+	return associated
+}
+
+// trackVelocitySmoothing is the weight of the newest finite-difference velocity in a
+// track's velocity when the estimates carry none.
+const trackVelocitySmoothing = 0.3
+
+// updateAssocTrack moves the track of a target to its latest estimate, if it has one.
+// Without a velocity estimate, the velocity is smoothed from successive positions.
+func (s *Simulation) updateAssocTrack(targetID string) {
+	solution := s.lastEstimates[targetID]
+	if solution.Position == nil {
+		return
+	}
+	previous := s.assocTracks[targetID]
+	track := assocTrack{position: solution.Position.Clone(), velocity: solution.Velocity, estimated: true}
+	if track.velocity == nil && previous.estimated && s.lastStep > 0 {
+		if moved, err := solution.Position.Subtract(previous.position); err == nil {
+			track.velocity = moved.MultiplyByScalar(1 / s.lastStep)
+			if previous.velocity != nil {
+				for i := range track.velocity {
+					track.velocity[i] = trackVelocitySmoothing*track.velocity[i] + (1-trackVelocitySmoothing)*previous.velocity[i]
+				}
+			}
+		}
+	}
+	if solution.Covariance != nil {
+		trace := 0.0
+		for i := range solution.Covariance {
+			trace += solution.Covariance[i][i]
+		}
+		track.sigma = math.Sqrt(math.Max(trace, 0))
+	}
+	s.assocTracks[targetID] = track
+}
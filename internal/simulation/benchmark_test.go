@@ -0,0 +1,93 @@
+package simulation_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+// A step measures every target from every sensor and solves each target, so it grows
+// with targets * sensors * d for the ranges plus targets * the solver cost.
+func BenchmarkStep(b *testing.B) {
+	for _, dim := range []int{2, 3, 10, 25, 50} {
+		b.Run(fmt.Sprintf("dim=%d", dim), func(b *testing.B) {
+			bounds := make([]float64, 0, 2*dim)
+			for i := 0; i < dim; i++ {
+				bounds = append(bounds, -100, 100)
+			}
+			sim, err := simulation.NewSimulation(dim, bounds, time.Second/30)
+			if err != nil {
+				b.Fatal(err)
+			}
+			sim.SetSeeds(simulation.NewSeeds(1))
+			for i := 0; i < 2*(dim+1); i++ {
+				_ = sim.AddRandomSensor(0, simulation.GaussianNoise(0.5))
+			}
+			for i := 0; i < 10; i++ {
+				_ = sim.AddRandomTarget()
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sim.Step(1.0 / 30)
+			}
+		})
+	}
+}
+
+// The default solver keeps its workspaces across steps, so a step allocates less than
+// with the same solve through SetSolver, which allocates them on every call.
+func TestStepSolverAllocations(t *testing.T) {
+	allocs := func(custom bool) float64 {
+		sim, err := simulation.NewSimulation(3, []float64{-100, 100, -100, 100, -100, 100}, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sim.SetSeeds(simulation.NewSeeds(1))
+		if custom {
+			sim.SetSolver(multilateration.SolveLeastSquares)
+		}
+		for i := 0; i < 8; i++ {
+			_ = sim.AddRandomSensor(0, nil)
+		}
+		for i := 0; i < 10; i++ {
+			_ = sim.AddRandomTarget()
+		}
+		sim.Step(1) // Size the workspaces
+		return testing.AllocsPerRun(20, func() { sim.Step(1) })
+	}
+	reused, plain := allocs(false), allocs(true)
+	if reused >= plain {
+		t.Errorf("step allocates %.0f times with the reusable solver, %.0f without, want fewer", reused, plain)
+	}
+	t.Logf("allocations per step: %.0f with the reusable solver, %.0f without", reused, plain)
+}
+
+// BenchmarkStepConcurrency runs a scene with many targets serially and on every core;
+// the speed-up is bounded by the serial merge of the results.
+func BenchmarkStepConcurrency(b *testing.B) {
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			sim, err := simulation.NewSimulation(3, []float64{-100, 100, -100, 100, -100, 100}, time.Second/30)
+			if err != nil {
+				b.Fatal(err)
+			}
+			sim.SetSeeds(simulation.NewSeeds(1))
+			if err := sim.SetConcurrency(workers); err != nil {
+				b.Fatal(err)
+			}
+			for i := 0; i < 20; i++ {
+				_ = sim.AddRandomSensor(0, simulation.GaussianNoise(0.5))
+			}
+			for i := 0; i < 500; i++ {
+				_ = sim.AddRandomTarget()
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sim.Step(1.0 / 30)
+			}
+		})
+	}
+}
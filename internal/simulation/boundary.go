@@ -0,0 +1,136 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// MetricAbsorbed counts the objects removed for leaving the world in BoundaryAbsorb mode.
+const MetricAbsorbed = "objects_absorbed"
+
+// BoundaryMode decides what happens to a moving object that reaches the edge of the
+// simulation bounds.
+type BoundaryMode int
+
+const (
+	BoundaryBounce BoundaryMode = iota // Reflect, reversing and damping the normal velocity (the default)
+	BoundaryWrap                       // Re-enter at the opposite edge with the same velocity (a torus)
+	BoundaryClamp                      // Stop at the edge: the normal velocity is dropped
+	BoundaryAbsorb                     // Leave the simulation: the object is removed
+)
+
+// String returns the name ParseBoundaryMode accepts.
+func (m BoundaryMode) String() string {
+	switch m {
+	case BoundaryBounce:
+		return "bounce"
+	case BoundaryWrap:
+		return "wrap"
+	case BoundaryClamp:
+		return "clamp"
+	case BoundaryAbsorb:
+		return "absorb"
+	default:
+		return fmt.Sprintf("boundary(%d)", int(m))
+	}
+}
+
+// ParseBoundaryMode returns the mode named "bounce", "wrap", "clamp" or "absorb"; ""
+// means bounce.
+func ParseBoundaryMode(name string) (BoundaryMode, error) {
+	if name == "" {
+		return BoundaryBounce, nil
+	}
+	for m := BoundaryBounce; m <= BoundaryAbsorb; m++ {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown boundary mode %q, expected bounce, wrap, clamp or absorb", name)
+}
+
+// applyBoundary keeps a position that left the bounds in them according to the mode,
+// adjusting pos and vel in place. Absorb leaves both unchanged; the simulation removes
+// the object.
+func applyBoundary(mode BoundaryMode, pos, vel common.Vector, bounds []float64) {
+	switch mode {
+	case BoundaryBounce:
+		bounceOffBounds(pos, vel, bounds)
+	case BoundaryWrap:
+		for i := 0; i < pos.Dimension() && i*2+1 < len(bounds); i++ {
+			lo, width := bounds[i*2], bounds[i*2+1]-bounds[i*2]
+			if width > 0 && (pos[i] < lo || pos[i] > lo+width) {
+				pos[i] = lo + math.Mod(math.Mod(pos[i]-lo, width)+width, width)
+			}
+		}
+	case BoundaryClamp:
+		for i := 0; i < pos.Dimension() && i*2+1 < len(bounds); i++ {
+			if pos[i] < bounds[i*2] || pos[i] > bounds[i*2+1] {
+				pos[i] = math.Max(bounds[i*2], math.Min(pos[i], bounds[i*2+1]))
+				vel[i] = 0
+			}
+		}
+	}
+}
+
+// outsideBounds reports whether a position lies outside the bounds.
+func outsideBounds(pos common.Vector, bounds []float64) bool {
+	for i := 0; i < pos.Dimension() && i*2+1 < len(bounds); i++ {
+		if pos[i] < bounds[i*2] || pos[i] > bounds[i*2+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBoundaryMode sets what happens to moving sensors and targets at the edge of the
+// bounds. The boundary of a world (see SetWorld) bounces in Wrap mode, since a world
+// has no opposite edge; the other modes apply to it as to the bounds.
+func (s *Simulation) SetBoundaryMode(mode BoundaryMode) error {
+	if mode < BoundaryBounce || mode > BoundaryAbsorb {
+		return fmt.Errorf("%w: unknown boundary mode %d", ErrOutOfRange, int(mode))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.boundary = mode
+	for _, sen := range s.sensors {
+		sen.boundary = mode
+	}
+	for _, tar := range s.targets {
+		tar.boundary = mode
+	}
+	return nil
+}
+
+// BoundaryMode returns what happens to moving objects at the edge of the bounds.
+func (s *Simulation) BoundaryMode() BoundaryMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.boundary
+}
+
+// absorbEscaped removes the sensors and targets outside the bounds or the world in
+// Absorb mode. The caller holds the lock.
+func (s *Simulation) absorbEscaped() {
+	var escaped []string
+	for _, sen := range s.sortedSensors() {
+		if s.escaped(sen.GetPosition()) {
+			escaped = append(escaped, sen.GetID())
+		}
+	}
+	for _, tar := range s.sortedTargets() {
+		if s.escaped(tar.GetPosition()) {
+			escaped = append(escaped, tar.GetID())
+		}
+	}
+	for _, id := range escaped {
+		_ = s.removeObject(id)
+		s.metrics.Inc(MetricAbsorbed)
+	}
+}
+
+// escaped reports whether a position is outside the bounds or the world.
+func (s *Simulation) escaped(pos common.Vector) bool {
+	return outsideBounds(pos, s.bounds) || s.world != nil && !s.world.Contains(pos)
+}
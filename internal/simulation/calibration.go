@@ -0,0 +1,156 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// calibrationStream is the placement stream base reserved for sensor calibration errors;
+// each sensor draws from calibrationStream plus its insertion sequence number.
+const calibrationStream = 1 << 61
+
+// KnownPosition returns where the sensor is believed to be: its true position plus the
+// calibration offset. This is the position handed to the solvers with its measurements.
+func (s *Sensor) KnownPosition() common.Vector {
+	if s.calibrationOffset == nil {
+		return s.GetPosition()
+	}
+	known, err := s.position.Add(s.calibrationOffset)
+	if err != nil {
+		return s.GetPosition()
+	}
+	return known
+}
+
+// CalibrationOffset returns the error of the sensor's known position, nil if it is
+// known exactly.
+func (s *Sensor) CalibrationOffset() common.Vector {
+	if s.calibrationOffset == nil {
+		return nil
+	}
+	return s.calibrationOffset.Clone()
+}
+
+// SetCalibrationOffset sets the error of the sensor's known position (a survey or
+// installation error), distinct from measurement noise: every measurement of the
+// sensor reports its position off by offset. nil means the position is known exactly.
+func (s *Sensor) SetCalibrationOffset(offset common.Vector) error {
+	if offset == nil {
+		s.calibrationOffset = nil
+		return nil
+	}
+	if offset.Dimension() != s.position.Dimension() {
+		return fmt.Errorf("%w: calibration offset has dimension %d, sensor %d", ErrDimensionMismatch, offset.Dimension(), s.position.Dimension())
+	}
+	s.calibrationOffset = offset.Clone()
+	return nil
+}
+
+// PerturbSensorPositions gives every sensor a calibration offset drawn from a Gaussian
+// with standard deviation sigma per axis, reproducibly from the placement seed. 0 makes
+// all sensor positions exact again.
+func (s *Simulation) PerturbSensorPositions(sigma float64) error {
+	if sigma < 0 {
+		return fmt.Errorf("%w: calibration sigma must be non-negative, got %f", ErrOutOfRange, sigma)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sen := range s.sensors {
+		if sigma == 0 {
+			sen.calibrationOffset = nil
+			continue
+		}
+		rng := newStream(s.seeds.Placement, calibrationStream+uint64(s.order[id]))
+		offset := common.NewVector(s.dimension)
+		for i := range offset {
+			offset[i] = rng.NormFloat64() * sigma
+		}
+		sen.calibrationOffset = offset
+	}
+	s.calibrationSigma = sigma
+	return nil
+}
+
+// SensorCalibration reports how self-calibration changed the known position of a sensor.
+type SensorCalibration struct {
+	SensorID string
+	Ranges   int     // Ranges to reference points used
+	Before   float64 // Error of the known position before calibration (units)
+	After    float64 // Error after calibration
+	Residual float64 // RMS range residual of the refined position
+}
+
+// CalibrateSensors runs a calibration campaign: a tag is placed at every reference
+// point in turn and each sensor in range measures it samples times, with its noise and
+// biased or blocked by obstacles and clocks like any target. The ranges refine the
+// known sensor positions with multilateration.CalibrateAnchors, using the error set by
+// PerturbSensorPositions as the prior, and the refined positions replace the known
+// ones. Calibration assumes Euclidean ranges. True positions and the random streams of
+// the running simulation are not affected.
+func (s *Simulation) CalibrateSensors(points []common.Vector, samples int) ([]SensorCalibration, error) {
+	if samples < 1 {
+		return nil, fmt.Errorf("%w: samples must be at least 1, got %d", ErrOutOfRange, samples)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range points {
+		if p.Dimension() != s.dimension {
+			return nil, fmt.Errorf("%w: reference point %d has dimension %d, expected %d", ErrDimensionMismatch, i, p.Dimension(), s.dimension)
+		}
+	}
+
+	sensors := s.sortedSensors()
+	problem := multilateration.CalibrationProblem{AnchorSigma: s.calibrationSigma}
+	varianceSum, varianceCount := 0.0, 0
+	for i, sen := range sensors {
+		problem.Anchors = append(problem.Anchors, sen.KnownPosition())
+		if v := sen.RangeVariance(); v > 0 {
+			varianceSum += v
+			varianceCount++
+		}
+		// The campaign draws its own noise, so the simulation's streams are not advanced
+		saved := sen.noiseRand
+		sen.noiseRand = newStream(s.seeds.Noise, calibrationStream+uint64(s.order[sen.GetID()]))
+		for _, p := range points {
+			tag := NewTargetWithMotion(p, nil)
+			clear, bias := s.lineOfSight(sen.GetPosition(), p)
+			if !clear {
+				continue
+			}
+			if s.signalSpeed > 0 {
+				bias += s.clockBias(sen, tag)
+			}
+			for k := 0; k < samples; k++ {
+				dist, inRange, err := sen.MeasureDistance(tag)
+				if err != nil || !inRange {
+					break
+				}
+				dist += bias
+				problem.Ranges = append(problem.Ranges, multilateration.ReferenceRange{Anchor: i, Point: p.Clone(), Distance: dist})
+			}
+		}
+		sen.noiseRand = saved
+	}
+	if varianceCount > 0 {
+		problem.RangeSigma = math.Sqrt(varianceSum / float64(varianceCount))
+	}
+
+	result, err := multilateration.CalibrateAnchors(problem, 0)
+	if err != nil {
+		return nil, fmt.Errorf("sensor calibration failed: %w", err)
+	}
+	report := make([]SensorCalibration, len(sensors))
+	for i, sen := range sensors {
+		before, _ := sen.KnownPosition().Distance(sen.position)
+		offset, _ := result.Anchors[i].Subtract(sen.position)
+		sen.calibrationOffset = offset
+		after, _ := result.Anchors[i].Distance(sen.position)
+		report[i] = SensorCalibration{SensorID: sen.GetID(), Before: before, After: after, Residual: result.Residuals[i]}
+	}
+	for _, r := range problem.Ranges {
+		report[r.Anchor].Ranges++
+	}
+	return report, nil
+}
@@ -0,0 +1,367 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+)
+
+// checkpointVersion is increased when the checkpoint format changes incompatibly.
+const checkpointVersion = 1
+
+// checkpoint is the dynamic state of a simulation. Configuration that cannot be
+// serialized (noise functions, motion models, solver, trackers) stays with the objects.
+type checkpoint struct {
+	Version   int
+	Dimension int
+	Time      float64
+	Tick      int64
+	LastStep  float64
+
+	Seeds     Seeds
+	Placement streamState
+	Clutter   streamState
+	Peer      streamState
+	NextSeq   int64
+
+	Sensors []objectCheckpoint
+	Targets []objectCheckpoint
+
+	Estimates     map[string]multilateration.Solution
+	Errors        map[string]float64
+	ErrorExceeded map[string]bool
+	InRange       [][2]string // sensorID, targetID
+	Latest        map[string]map[string]multilateration.Measurement
+	LastSolve     map[string]float64 // Fusion center bookkeeping
+	NewestSolved  map[string]float64
+	LatestClutter map[string]clutterCheckpoint
+	Pending       []pendingCheckpoint
+	AssocTracks   map[string]assocTrackCheckpoint
+	Metrics       metrics.Snapshot
+}
+
+// objectCheckpoint is the state of a sensor or target.
+type objectCheckpoint struct {
+	ID       string
+	Seq      int64 // Insertion sequence number, which derives the streams after SetSeeds
+	Position common.Vector
+	Velocity common.Vector
+	Motion   []float64     // State of a statefulMotion, nil if none
+	Streams  []streamState // Motion, noise and, for sensors, failure streams
+
+	Status        SensorStatus // Sensors only
+	OutageLeft    float64
+	OperatingTime float64
+
+	NextEmission    float64 // Targets with an emission schedule only
+	EmissionStarted bool
+}
+
+type clutterCheckpoint struct {
+	Taken  float64
+	Ranges []multilateration.Measurement
+}
+
+type pendingCheckpoint struct {
+	TargetID, SensorID string
+	DeliverAt          float64
+	InRange            bool
+	Measurement        multilateration.Measurement
+	Clutter            *clutterCheckpoint
+}
+
+type assocTrackCheckpoint struct {
+	Position, Velocity common.Vector
+	Sigma              float64
+	Estimated          bool
+}
+
+// statefulMotion is implemented by motion models that keep state besides the position
+// and velocity of their object, so that it can be checkpointed.
+type statefulMotion interface {
+	motionState() []float64
+	restoreMotionState(state []float64)
+}
+
+func (m *WaypointMotion) motionState() []float64 {
+	return []float64{float64(m.next)}
+}
+
+func (m *WaypointMotion) restoreMotionState(state []float64) {
+	if len(state) == 1 {
+		m.next = int(state[0])
+	}
+}
+
+func (m *CircularMotion) motionState() []float64 {
+	started := 0.0
+	if m.started {
+		started = 1
+	}
+	return []float64{m.angle, started}
+}
+
+func (m *CircularMotion) restoreMotionState(state []float64) {
+	if len(state) == 2 {
+		m.angle, m.started = state[0], state[1] != 0
+	}
+}
+
+// Checkpoint saves the dynamic state of the simulation: time, object positions,
+// velocities and failure states, estimates, measurements in flight, association tracks,
+// metrics and the state of every random stream. Restore on a simulation built the same
+// way (e.g. from the same scenario) continues exactly where this one stands, so long runs
+// can be persisted and resumed, or branched for what-if experiments. Trackers, the
+// selection policy and the configuration of the objects are not saved; SLAM mode,
+// distributed localization and the simulated network are not supported.
+func (s *Simulation) Checkpoint() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.slam != nil {
+		return nil, fmt.Errorf("checkpoints do not support SLAM mode")
+	}
+	if s.distributed != nil {
+		return nil, fmt.Errorf("checkpoints do not support distributed localization")
+	}
+	if s.network != nil {
+		return nil, fmt.Errorf("checkpoints do not support the simulated network")
+	}
+	cp := checkpoint{
+		Version:   checkpointVersion,
+		Dimension: s.dimension,
+		Time:      s.simulationTime,
+		Tick:      s.tick,
+		LastStep:  s.lastStep,
+		Seeds:     s.seeds,
+		Placement: s.placementRand.state(),
+		Clutter:   s.clutterRand.state(),
+		Peer:      s.peerRand.state(),
+		NextSeq:   s.nextSeq,
+
+		Estimates:     make(map[string]multilateration.Solution, len(s.lastEstimates)),
+		Errors:        s.lastErrors,
+		ErrorExceeded: s.errorExceeded,
+		Latest:        s.fusion.latest,
+		LastSolve:     s.fusion.lastSolve,
+		NewestSolved:  s.fusion.newest,
+		LatestClutter: make(map[string]clutterCheckpoint, len(s.latestClutter)),
+		Metrics:       s.metrics.Snapshot(),
+	}
+	for _, sen := range s.sortedSensors() {
+		obj := s.objectCheckpoint(sen, sen.position, sen.velocity, sen.motion, sen.motionRand, sen.noiseRand, sen.failureRand)
+		obj.Status, obj.OutageLeft, obj.OperatingTime = sen.status, sen.outageLeft, sen.operatingTime
+		cp.Sensors = append(cp.Sensors, obj)
+	}
+	for _, tar := range s.sortedTargets() {
+		obj := s.objectCheckpoint(tar, tar.position, tar.velocity, tar.motion, tar.motionRand, tar.noiseRand)
+		if tar.emitter != nil {
+			obj.NextEmission, obj.EmissionStarted = tar.emitter.nextEmission, tar.emitter.started
+		}
+		cp.Targets = append(cp.Targets, obj)
+	}
+	for id, sol := range s.lastEstimates {
+		cp.Estimates[id] = sol
+	}
+	for key, in := range s.inRange {
+		if in {
+			cp.InRange = append(cp.InRange, [2]string{key.sensorID, key.targetID})
+		}
+	}
+	for id, batch := range s.latestClutter {
+		cp.LatestClutter[id] = clutterCheckpoint{Taken: batch.taken, Ranges: batch.ranges}
+	}
+	for _, p := range s.pending {
+		pc := pendingCheckpoint{TargetID: p.targetID, SensorID: p.sensorID, DeliverAt: p.deliverAt, InRange: p.inRange, Measurement: p.measurement}
+		if p.clutter != nil {
+			pc.Clutter = &clutterCheckpoint{Taken: p.clutter.taken, Ranges: p.clutter.ranges}
+		}
+		cp.Pending = append(cp.Pending, pc)
+	}
+	if s.assocTracks != nil {
+		cp.AssocTracks = make(map[string]assocTrackCheckpoint, len(s.assocTracks))
+		for id, tr := range s.assocTracks {
+			cp.AssocTracks[id] = assocTrackCheckpoint{Position: tr.position, Velocity: tr.velocity, Sigma: tr.sigma, Estimated: tr.estimated}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+		return nil, fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// objectCheckpoint saves the state shared by sensors and targets. The caller holds the lock.
+func (s *Simulation) objectCheckpoint(obj SimulationObject, pos, vel common.Vector, motion MotionModel, streams ...*randStream) objectCheckpoint {
+	id := obj.GetID()
+	cp := objectCheckpoint{ID: id, Seq: s.order[id], Position: pos, Velocity: vel}
+	if m, ok := motion.(statefulMotion); ok {
+		cp.Motion = m.motionState()
+	}
+	for _, r := range streams {
+		cp.Streams = append(cp.Streams, r.state())
+	}
+	return cp
+}
+
+// Restore continues from a checkpoint taken by Checkpoint. The simulation must contain
+// the sensors and targets of the checkpoint (by ID), or have them scheduled to spawn;
+// objects missing from the checkpoint are removed, as are scheduled changes that were
+// due before it. Trackers start afresh. On error the simulation is left unchanged.
+func (s *Simulation) Restore(data []byte) error {
+	var cp checkpoint
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cp); err != nil {
+		return fmt.Errorf("decoding checkpoint: %w", err)
+	}
+	if cp.Version != checkpointVersion {
+		return fmt.Errorf("checkpoint version %d is not supported, expected %d", cp.Version, checkpointVersion)
+	}
+
+	defer s.events.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cp.Dimension != s.dimension {
+		return fmt.Errorf("%w: checkpoint dimension %d does not match simulation dimension %d", ErrDimensionMismatch, cp.Dimension, s.dimension)
+	}
+	if s.slam != nil {
+		return fmt.Errorf("checkpoints do not support SLAM mode")
+	}
+	if s.distributed != nil {
+		return fmt.Errorf("checkpoints do not support distributed localization")
+	}
+	if s.network != nil {
+		return fmt.Errorf("checkpoints do not support the simulated network")
+	}
+	saved := make(map[string]bool, len(cp.Sensors)+len(cp.Targets))
+	for i, objects := range [][]objectCheckpoint{cp.Sensors, cp.Targets} {
+		for _, obj := range objects {
+			saved[obj.ID] = true
+			current, exists := s.objects[obj.ID]
+			if c, pending := s.pendingSpawn(obj.ID); !exists && pending {
+				current, exists = c.spawn, true
+			}
+			if !exists {
+				return fmt.Errorf("%w: checkpoint object %s is not in the simulation", ErrNotFound, obj.ID)
+			}
+			if _, isSensor := current.(*Sensor); isSensor != (i == 0) {
+				return fmt.Errorf("%w: checkpoint object %s is of another kind in the simulation", ErrNotFound, obj.ID)
+			}
+		}
+	}
+
+	// Bring the set of objects in line with the checkpoint
+	s.simulationTime = cp.Time
+	for id := range s.objects {
+		if _, obstacle := s.obstacles[id]; !obstacle && !saved[id] {
+			_ = s.removeObject(id) // Exists
+		}
+	}
+	due := 0
+	for due < len(s.scheduled) && s.scheduled[due].at <= cp.Time+deliveryEpsilon {
+		due++
+	}
+	changes := append([]scheduledChange(nil), s.scheduled[:due]...)
+	s.scheduled = append(s.scheduled[:0], s.scheduled[due:]...)
+	for _, c := range changes {
+		if !c.despawn && saved[c.id] {
+			_ = s.addObject(c.spawn) // Checked above
+		}
+	}
+	for id := range saved {
+		if _, exists := s.objects[id]; !exists {
+			c, _ := s.pendingSpawn(id)
+			s.unschedule(id, true, false)
+			_ = s.addObject(c.spawn)
+		}
+	}
+
+	s.tick, s.lastStep = cp.Tick, cp.LastStep
+	s.seeds = cp.Seeds
+	s.placementRand, s.clutterRand = restoreStream(cp.Placement), restoreStream(cp.Clutter)
+	s.peerRand = restoreStream(cp.Peer)
+	s.nextSeq = cp.NextSeq
+	for _, obj := range cp.Sensors {
+		sen := s.sensors[obj.ID]
+		s.restoreObject(obj, &sen.position, &sen.velocity, sen.motion, &sen.motionRand, &sen.noiseRand, &sen.failureRand)
+		sen.status, sen.outageLeft, sen.operatingTime = obj.Status, obj.OutageLeft, obj.OperatingTime
+	}
+	for _, obj := range cp.Targets {
+		tar := s.targets[obj.ID]
+		s.restoreObject(obj, &tar.position, &tar.velocity, tar.motion, &tar.motionRand, &tar.noiseRand)
+		if tar.emitter != nil {
+			tar.emitter.nextEmission, tar.emitter.started = obj.NextEmission, obj.EmissionStarted
+		}
+	}
+
+	s.lastEstimates = make(map[string]multilateration.Solution, len(cp.Estimates))
+	for id, sol := range cp.Estimates {
+		s.lastEstimates[id] = cloneSolution(sol)
+	}
+	s.lastErrors = nonNilMap(cp.Errors)
+	s.errorExceeded = nonNilMap(cp.ErrorExceeded)
+	s.inRange = make(map[rangeKey]bool, len(cp.InRange))
+	for _, pair := range cp.InRange {
+		s.inRange[rangeKey{sensorID: pair[0], targetID: pair[1]}] = true
+	}
+	s.fusion.latest = nonNilMap(cp.Latest)
+	s.fusion.lastSolve, s.fusion.newest = nonNilMap(cp.LastSolve), nonNilMap(cp.NewestSolved)
+	s.latestClutter = make(map[string]*clutterBatch, len(cp.LatestClutter))
+	for id, batch := range cp.LatestClutter {
+		s.latestClutter[id] = &clutterBatch{taken: batch.Taken, ranges: batch.Ranges}
+	}
+	s.pending = s.pending[:0]
+	for _, p := range cp.Pending {
+		pm := pendingMeasurement{targetID: p.TargetID, sensorID: p.SensorID, deliverAt: p.DeliverAt, inRange: p.InRange, measurement: p.Measurement}
+		if p.Clutter != nil {
+			pm.clutter = &clutterBatch{taken: p.Clutter.Taken, ranges: p.Clutter.Ranges}
+		}
+		s.pending = append(s.pending, pm)
+	}
+	if s.assocTracks != nil {
+		s.assocTracks = make(map[string]assocTrack, len(cp.AssocTracks))
+		for id, tr := range cp.AssocTracks {
+			s.assocTracks[id] = assocTrack{position: tr.Position, velocity: tr.Velocity, sigma: tr.Sigma, estimated: tr.Estimated}
+		}
+		for id, tar := range s.targets {
+			if _, ok := s.assocTracks[id]; !ok {
+				s.cueTrack(id, tar) // Association was off when the checkpoint was taken
+			}
+		}
+	}
+	if s.trackers != nil {
+		s.trackers = make(map[string]tracking.Tracker)
+	}
+	s.history.clear() // The retained steps may lie in the future of the checkpoint
+	s.tracks = make(map[string]*trackRecord)
+	if s.smoothing != nil {
+		s.smoothing.tracks = make(map[string][]HistorySample)
+	}
+	s.metrics.Restore(cp.Metrics)
+	return nil
+}
+
+// restoreObject restores the state shared by sensors and targets. The caller holds the lock.
+func (s *Simulation) restoreObject(cp objectCheckpoint, pos, vel *common.Vector, motion MotionModel, streams ...**randStream) {
+	s.order[cp.ID] = cp.Seq
+	*pos, *vel = cp.Position.Clone(), cp.Velocity.Clone()
+	if m, ok := motion.(statefulMotion); ok && cp.Motion != nil {
+		m.restoreMotionState(cp.Motion)
+	}
+	for i, r := range streams {
+		if i < len(cp.Streams) {
+			*r = restoreStream(cp.Streams[i])
+		}
+	}
+}
+
+// nonNilMap returns m, or an empty map if gob decoded an empty map as nil.
+func nonNilMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return make(map[K]V)
+	}
+	return m
+}
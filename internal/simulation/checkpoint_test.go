@@ -0,0 +1,153 @@
+package simulation_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/network"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []string
+		build   func(*testing.T) *simulation.Simulation
+	}{
+		{"noise, latency and motion", []string{"drone", "patrol"}, func(t *testing.T) *simulation.Simulation {
+			sim := newTestSimulation(t, time.Second/10, simulation.GaussianNoise(0.5), cornerAnchors...)
+			for _, sensor := range sim.GetSensors() {
+				_ = sensor.SetLatency(0.15)
+			}
+			waypoints, _ := simulation.NewWaypointMotion([]common.Vector{{20, 0}, {20, 20}, {0, 20}}, 5, true)
+			addObjects(t, sim,
+				simulation.NewTargetWithID("drone", common.Vector{0, 0}, simulation.NewRandomWalkMotion()),
+				simulation.NewTargetWithID("patrol", common.Vector{0, 0}, waypoints))
+			return sim
+		}},
+		{"emission schedule and dropout", []string{"tag"}, func(t *testing.T) *simulation.Simulation {
+			sim := newTestSimulation(t, time.Second/10, simulation.GaussianNoise(0.2), cornerAnchors...)
+			for _, sensor := range sim.GetSensors() {
+				_ = sensor.SetDropoutProbability(0.2)
+			}
+			tag := simulation.NewTargetWithID("tag", common.Vector{5, 5}, simulation.NewRandomWalkMotion())
+			_ = tag.SetEmissionSchedule(simulation.EmissionSchedule{Interval: 0.3, Jitter: 0.1, Duration: 0.01})
+			addObjects(t, sim, tag)
+			return sim
+		}},
+		{"association with clutter", []string{"east", "west"}, func(t *testing.T) *simulation.Simulation {
+			sim := newTestSimulation(t, time.Second/10, simulation.GaussianNoise(0.2), cornerAnchors...)
+			for _, sensor := range sim.GetSensors() {
+				_ = sensor.SetFalseAlarmRate(1)
+			}
+			associator, _ := association.Parse("gnn", association.DefaultOptions())
+			sim.SetAssociator(associator)
+			east := simulation.NewTargetWithID("east", common.Vector{-30, -10}, simulation.NewConstantVelocityMotion())
+			_ = east.SetVelocity(common.Vector{10, 0})
+			west := simulation.NewTargetWithID("west", common.Vector{30, 10}, simulation.NewConstantVelocityMotion())
+			_ = west.SetVelocity(common.Vector{-10, 0})
+			addObjects(t, sim, east, west)
+			return sim
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.build(t)
+			original.SetSeeds(simulation.NewSeeds(7))
+			stepN(original, 20, 0.1)
+			data, err := original.Checkpoint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stepN(original, 30, 0.1)
+
+			resumed := tt.build(t)
+			resumed.SetSeeds(simulation.NewSeeds(7))
+			if err := resumed.Restore(data); err != nil {
+				t.Fatal(err)
+			}
+			if now := resumed.GetCurrentTime(); now < 2-1e-9 || now > 2+1e-9 {
+				t.Fatalf("resumed at %v, want 2", now)
+			}
+			stepN(resumed, 30, 0.1)
+			for _, id := range tt.targets {
+				a, okA := original.GetLastEstimate(id)
+				b, okB := resumed.GetLastEstimate(id)
+				if okA != okB || okA && !a.Position.Equal(b.Position, 0) {
+					t.Errorf("%s: estimate %v (%t) after resuming, want %v (%t)", id, b.Position, okB, a.Position, okA)
+				}
+			}
+			for _, name := range []string{simulation.MetricSolverInvocations, simulation.MetricMeasurementsAttempted} {
+				if a, b := original.Metrics().Counter(name), resumed.Metrics().Counter(name); a != b {
+					t.Errorf("%s: %v after resuming, want %v", name, b, a)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreUnsupportedModes(t *testing.T) {
+	build := func(t *testing.T) *simulation.Simulation {
+		sim := newTestSimulation(t, time.Second, nil, cornerAnchors...)
+		addObjects(t, sim, simulation.NewTargetWithID("beacon", common.Vector{5, 5}, nil))
+		return sim
+	}
+	saved := build(t)
+	saved.Step(1)
+	data, err := saved.Checkpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		enable func(*simulation.Simulation) error
+	}{
+		{"SLAM", func(sim *simulation.Simulation) error {
+			return sim.EnableRangeOnlySLAM(simulation.SLAMConfig{AnchorSigma: 1, RangeSigma: 0.1, Window: 5})
+		}},
+		{"distributed", func(sim *simulation.Simulation) error {
+			config := simulation.DefaultDistributedLocalization()
+			return sim.SetDistributedLocalization(&config)
+		}},
+		{"network", func(sim *simulation.Simulation) error {
+			return sim.SetNetwork(&network.Config{})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := build(t)
+			if err := tt.enable(sim); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sim.Checkpoint(); err == nil {
+				t.Error("Checkpoint succeeded")
+			}
+			if err := sim.Restore(data); err == nil {
+				t.Error("Restore succeeded")
+			}
+			if now := sim.GetCurrentTime(); now != 0 {
+				t.Errorf("Restore moved the time to %v", now)
+			}
+		})
+	}
+}
+
+func TestRestoreVersionMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(struct{ Version, Dimension int }{Version: 99, Dimension: 2}); err != nil {
+		t.Fatal(err)
+	}
+	sim := newTestSimulation(t, time.Second, nil)
+	err := sim.Restore(buf.Bytes())
+	if err == nil {
+		t.Fatal("Restore accepted checkpoint version 99")
+	}
+	if errors.Is(err, simulation.ErrOutOfRange) {
+		t.Errorf("version mismatch reported as a parameter out of range: %v", err)
+	}
+}
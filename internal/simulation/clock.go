@@ -0,0 +1,64 @@
+package simulation
+
+import "fmt"
+
+// Clock is the imperfect clock of a sensor or target: Offset seconds ahead of true time
+// at simulation time 0, gaining Drift seconds per second. The zero Clock is perfect.
+type Clock struct {
+	Offset float64 `json:"offset"`
+	Drift  float64 `json:"drift,omitempty"`
+}
+
+// Error returns how far the clock is ahead of true time at simulation time t.
+func (c Clock) Error(t float64) float64 {
+	return c.Offset + c.Drift*t
+}
+
+// Clock returns the clock of the sensor.
+func (s *Sensor) Clock() Clock {
+	return s.clock
+}
+
+// SetClock sets the clock of the sensor, used in time-of-arrival mode.
+func (s *Sensor) SetClock(c Clock) {
+	s.clock = c
+}
+
+// Clock returns the clock of the target.
+func (t *Target) Clock() Clock {
+	return t.clock
+}
+
+// SetClock sets the clock the target stamps its transmissions with, used in
+// time-of-arrival mode.
+func (t *Target) SetClock(c Clock) {
+	t.clock = c
+}
+
+// SetSignalSpeed switches to time-of-arrival ranging: sensors derive ranges from the
+// travel time of the target's signal, measured between the target's transmit stamp and
+// the sensor's clock, so every range is off by speed * (sensor clock error - target
+// clock error). The target's part is common to all sensors and can be estimated with
+// multilateration.SolvePseudoranges; the sensors' parts cannot, which is the cost of
+// unsynchronized anchors. 0 (the default) ignores the clocks.
+func (s *Simulation) SetSignalSpeed(speed float64) error {
+	if speed < 0 {
+		return fmt.Errorf("%w: signal speed must be non-negative, got %f", ErrOutOfRange, speed)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signalSpeed = speed
+	return nil
+}
+
+// SignalSpeed returns the signal speed of time-of-arrival mode, 0 if it is off.
+func (s *Simulation) SignalSpeed() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signalSpeed
+}
+
+// clockBias is the range error of a time-of-arrival measurement by sen of tar.
+func (s *Simulation) clockBias(sen *Sensor, tar *Target) float64 {
+	return s.signalSpeed * (sen.clock.Error(s.simulationTime) - tar.clock.Error(s.simulationTime))
+}
@@ -0,0 +1,101 @@
+package simulation
+
+import (
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/multilateration"
+)
+
+// MetricFalseAlarms counts spurious detections generated by sensors (per sensor with a
+// sensor label, see metrics.Key).
+const MetricFalseAlarms = "false_alarms"
+
+// clutterBatch is the false alarms of one measurement round of a sensor.
+type clutterBatch struct {
+	taken  float64 // Simulation time of the measurement round
+	ranges []multilateration.Measurement
+}
+
+// generateClutter draws the false alarms of one measurement round of a sensor and queues
+// them with its latency. A round without false alarms is queued too, so that the
+// delivered batch replaces the previous one.
+func (s *Simulation) generateClutter(sen *Sensor) {
+	if sen.FalseAlarmRate() <= 0 || sen.Status() != SensorOperational {
+		return
+	}
+	maxRange := sen.DetectionRadius()
+	if maxRange <= 0 {
+		maxRange = s.boundsDiagonal()
+	}
+	count := poisson(sen.FalseAlarmRate(), s.clutterRand.Float64)
+	batch := make([]multilateration.Measurement, count)
+	for i := range batch {
+		batch[i] = multilateration.Measurement{
+			SensorID:       sen.GetID(),
+			SensorPosition: sen.KnownPosition(),
+			Distance:       s.clutterRand.Float64() * maxRange,
+			Timestamp:      s.simulationTime,
+			Variance:       sen.RangeVariance(),
+			SensorVelocity: sensorVelocity(sen),
+		}
+	}
+	s.metrics.Add(MetricFalseAlarms, float64(count))
+	s.metrics.Add(metrics.Key(MetricFalseAlarms, "sensor", sen.GetID()), float64(count))
+	s.enqueue(pendingMeasurement{
+		sensorID:  sen.GetID(),
+		deliverAt: s.simulationTime + sen.Latency(),
+		clutter:   &clutterBatch{taken: s.simulationTime, ranges: batch},
+	})
+}
+
+// deliverClutter makes a delivered batch of false alarms the current one of its sensor.
+func (s *Simulation) deliverClutter(p pendingMeasurement) {
+	if prev, ok := s.latestClutter[p.sensorID]; ok && prev.taken > p.clutter.taken {
+		return // Out-of-order arrival
+	}
+	if s.latestClutter == nil {
+		s.latestClutter = make(map[string]*clutterBatch)
+	}
+	s.latestClutter[p.sensorID] = p.clutter
+}
+
+// availableClutter returns the current false alarms of all sensors that are not older
+// than the maximum measurement age, in sensor insertion order.
+func (s *Simulation) availableClutter() []multilateration.Measurement {
+	var clutter []multilateration.Measurement
+	for _, sen := range s.sortedSensors() {
+		if batch, ok := s.latestClutter[sen.GetID()]; ok {
+			clutter = append(clutter, batch.ranges...)
+		}
+	}
+	if s.maxMeasurementAge > 0 {
+		clutter = multilateration.DiscardStale(clutter, s.simulationTime, s.maxMeasurementAge)
+	}
+	return clutter
+}
+
+// boundsDiagonal is the distance between opposite corners of the simulation bounds.
+func (s *Simulation) boundsDiagonal() float64 {
+	lo, hi := common.NewVector(s.dimension), common.NewVector(s.dimension)
+	for i := 0; i < s.dimension; i++ {
+		lo[i], hi[i] = s.bounds[2*i], s.bounds[2*i+1]
+	}
+	d, err := lo.DistanceIn(hi, s.metric)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// poisson draws a Poisson distributed count with the given mean (Knuth's method, fine
+// for the small means of false alarm rates).
+func poisson(mean float64, uniform func() float64) int {
+	limit := math.Exp(-mean)
+	count, product := 0, uniform()
+	for product > limit {
+		count++
+		product *= uniform()
+	}
+	return count
+}
@@ -0,0 +1,72 @@
+package simulation_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+// runCrossing steps three targets crossing five sensors with the given false alarm rate
+// for six seconds and returns the simulation and the worst localization error seen.
+func runCrossing(t *testing.T, associatorName string, falseAlarmRate float64) (*simulation.Simulation, float64) {
+	t.Helper()
+	sim := newTestSimulation(t, time.Second/10, simulation.GaussianNoise(0.2),
+		common.Vector{-40, -40}, common.Vector{40, -35}, common.Vector{35, 45}, common.Vector{-45, 30}, common.Vector{5, -5})
+	sim.SetSeeds(simulation.NewSeeds(7))
+	associator, err := association.Parse(associatorName, association.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.SetAssociator(associator)
+	for _, sensor := range sim.GetSensors() {
+		if err := sensor.SetFalseAlarmRate(falseAlarmRate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	velocities := []common.Vector{{10, 0}, {-10, 0}, {0, 10}}
+	var targets []*simulation.Target
+	for i, start := range []common.Vector{{-30, -10}, {30, 10}, {15, -40}} {
+		target := simulation.NewTargetWithMotion(start, simulation.NewConstantVelocityMotion())
+		_ = target.SetVelocity(velocities[i])
+		addObjects(t, sim, target)
+		targets = append(targets, target)
+	}
+	worst := 0.0
+	for range 60 {
+		sim.Step(0.1)
+		for _, target := range targets {
+			if e, ok := sim.GetLastLocalizationError(target.GetID()); ok && e > worst {
+				worst = e
+			}
+		}
+	}
+	return sim, worst
+}
+
+func TestFalseAlarmsWithAssociators(t *testing.T) {
+	tests := []struct {
+		associator string
+		rate       float64
+	}{
+		{"nn", 0},
+		{"nn", 2},
+		{"gnn", 2},
+		{"jpda", 2},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/rate=%v", tt.associator, tt.rate), func(t *testing.T) {
+			sim, worst := runCrossing(t, tt.associator, tt.rate)
+			// Five sensors measure 60 times: the count is Poisson with mean 300 per unit of rate
+			falseAlarms, want := sim.Metrics().Counter(simulation.MetricFalseAlarms), 300*tt.rate
+			if falseAlarms < want*0.85 || falseAlarms > want*1.15 {
+				t.Errorf("%v false alarms, want about %v", falseAlarms, want)
+			}
+			if worst > 1 {
+				t.Errorf("worst localization error %.2f", worst)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// Metric names of cooperative localization.
+const (
+	MetricPeerRanges             = "peer_ranges"             // Ranges measured between targets
+	MetricCooperativeSolves      = "cooperative_solves"      // Joint solves of all targets
+	MetricCooperativeFailures    = "cooperative_failures"    // Joint solves that returned an error
+	MetricCooperativeLocalized   = "cooperative_localized"   // Targets localized only thanks to peer ranges
+	MetricCooperativeUnlocalized = "cooperative_unlocalized" // Targets the joint solve could not constrain
+)
+
+// peerStream is the noise stream number reserved for peer ranges.
+const peerStream = 1 << 59
+
+// cooperativeIterations bounds the Levenberg-Marquardt iterations of the joint solve.
+const cooperativeIterations = 50
+
+// PeerRanging lets targets range against each other. Every step the targets are then
+// localized jointly from the sensor ranges and the peer ranges, so a target that hears
+// fewer than dimension+1 sensors can still be placed through its neighbours. Peer ranges
+// and the joint solve are Euclidean.
+type PeerRanging struct {
+	Radius      float64 `json:"radius,omitempty"`       // Largest distance two targets range over, 0 = unlimited
+	Sigma       float64 `json:"sigma"`                  // Gaussian noise of the peer ranges
+	AnchorSigma float64 `json:"anchor_sigma,omitempty"` // Assumed noise of the sensor ranges for weighting, 0 = Sigma
+}
+
+// Validate checks the parameters.
+func (p PeerRanging) Validate() error {
+	switch {
+	case p.Radius < 0:
+		return fmt.Errorf("%w: peer radius must be non-negative, got %f", ErrOutOfRange, p.Radius)
+	case p.Sigma <= 0:
+		return fmt.Errorf("%w: peer sigma must be positive, got %f", ErrOutOfRange, p.Sigma)
+	case p.AnchorSigma < 0:
+		return fmt.Errorf("%w: anchor sigma must be non-negative, got %f", ErrOutOfRange, p.AnchorSigma)
+	}
+	return nil
+}
+
+// SetPeerRanging enables ranging between targets and their joint localization, nil
+// localizes every target on its own.
+func (s *Simulation) SetPeerRanging(config *PeerRanging) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config == nil {
+		s.peerRanging = nil
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	c := *config
+	s.peerRanging = &c
+	return nil
+}
+
+// PeerRanging returns the peer ranging configuration, nil if disabled.
+func (s *Simulation) PeerRanging() *PeerRanging {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.peerRanging == nil {
+		return nil
+	}
+	c := *s.peerRanging
+	return &c
+}
+
+// localizeCooperatively measures the peer ranges between the targets of the jobs and
+// replaces the per-target solutions by a joint solve. Targets the joint solve cannot
+// constrain keep their own result. Joint solutions carry no DOP or covariance. The
+// caller holds the lock.
+func (s *Simulation) localizeCooperatively(jobs []localizationJob) {
+	cfg := s.peerRanging
+	var nodes []int // Indices into jobs
+	for i, job := range jobs {
+		if !job.outside {
+			nodes = append(nodes, i)
+		}
+	}
+	problem := multilateration.CooperativeProblem{
+		Dimension:  s.dimension,
+		Anchors:    make([][]multilateration.Measurement, len(nodes)),
+		Initial:    make([]common.Vector, len(nodes)),
+		RangeSigma: cfg.AnchorSigma,
+		PeerSigma:  cfg.Sigma,
+	}
+	if problem.RangeSigma == 0 {
+		problem.RangeSigma = cfg.Sigma
+	}
+	for n, i := range nodes {
+		job := jobs[i]
+		problem.Anchors[n] = job.measurements
+		if job.solve && job.err == nil {
+			problem.Initial[n] = job.solution.Position
+		} else if last := s.lastEstimates[job.target.GetID()].Position; len(last) == s.dimension {
+			problem.Initial[n] = last
+		}
+		for m := n + 1; m < len(nodes); m++ {
+			a, b := job.target.GetPosition(), jobs[nodes[m]].target.GetPosition()
+			dist, err := a.Distance(b)
+			if err != nil || (cfg.Radius > 0 && dist > cfg.Radius) {
+				continue
+			}
+			clear, bias := s.lineOfSight(a, b)
+			if !clear {
+				continue
+			}
+			dist = math.Max(0, dist+bias+s.peerRand.NormFloat64()*cfg.Sigma)
+			problem.Peers = append(problem.Peers, multilateration.PeerMeasurement{A: n, B: m, Distance: dist})
+		}
+	}
+	s.metrics.Add(MetricPeerRanges, float64(len(problem.Peers)))
+	if len(problem.Peers) == 0 {
+		return
+	}
+
+	s.metrics.Inc(MetricCooperativeSolves)
+	result, err := multilateration.SolveCooperative(problem, cooperativeIterations)
+	if err != nil {
+		s.log().Warn("cooperative localization failed", "tick", s.tick, "targets", len(nodes), "err", err)
+		s.metrics.Inc(MetricCooperativeFailures)
+		return
+	}
+	for n, i := range nodes {
+		job := &jobs[i]
+		if job.deferred {
+			continue // Not due at the fusion center, its ranges only helped the others
+		}
+		if !result.Localized[n] {
+			s.metrics.Inc(MetricCooperativeUnlocalized)
+			continue
+		}
+		if !job.solve || job.err != nil {
+			s.metrics.Inc(MetricCooperativeLocalized)
+		}
+		job.solve, job.err = true, nil
+		job.solution = multilateration.Solution{
+			Position:      result.Positions[n],
+			ResidualError: anchorResidual(job.measurements, result.Positions[n]),
+		}
+	}
+}
+
+// anchorResidual returns the RMS range residual of the measurements at pos, 0 without
+// measurements.
+func anchorResidual(measurements []multilateration.Measurement, pos common.Vector) float64 {
+	if len(measurements) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, m := range measurements {
+		dist, err := m.SensorPosition.Distance(pos)
+		if err != nil {
+			continue
+		}
+		sum += (m.Distance - dist) * (m.Distance - dist)
+	}
+	return math.Sqrt(sum / float64(len(measurements)))
+}
@@ -0,0 +1,218 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"strconv"
+	"strings"
+)
+
+// Limits of the deployment helpers, so a typo cannot allocate millions of sensors.
+const (
+	maxDeployment       = 100000 // Positions of one layout
+	maxVertexDimensions = 16     // 2^16 corners
+)
+
+// boundsDimension checks bounds given as [min0, max0, min1, max1, ...] and returns their
+// dimension.
+func boundsDimension(bounds []float64) (int, error) {
+	if len(bounds) == 0 || len(bounds)%2 != 0 {
+		return 0, fmt.Errorf("%w: bounds need a min and max per axis, got %d values", ErrDimensionMismatch, len(bounds))
+	}
+	for i := 0; i < len(bounds); i += 2 {
+		if bounds[i] > bounds[i+1] {
+			return 0, fmt.Errorf("%w: axis %d has min %f above max %f", ErrOutOfRange, i/2, bounds[i], bounds[i+1])
+		}
+	}
+	return len(bounds) / 2, nil
+}
+
+// boundsCenter returns the center of the bounds and the half of their smallest extent.
+func boundsCenter(bounds []float64) (common.Vector, float64) {
+	center := common.NewVector(len(bounds) / 2)
+	inscribed := math.Inf(1)
+	for i := range center {
+		center[i] = (bounds[2*i] + bounds[2*i+1]) / 2
+		inscribed = math.Min(inscribed, (bounds[2*i+1]-bounds[2*i])/2)
+	}
+	return center, inscribed
+}
+
+// DeployGrid returns a regular grid of nPerAxis points per axis spanning the bounds
+// edge to edge (the center for nPerAxis 1), nPerAxis^dimension points in all.
+func DeployGrid(bounds []float64, nPerAxis int) ([]common.Vector, error) {
+	dim, err := boundsDimension(bounds)
+	if err != nil {
+		return nil, err
+	}
+	if nPerAxis < 1 {
+		return nil, fmt.Errorf("%w: points per axis must be positive, got %d", ErrOutOfRange, nPerAxis)
+	}
+	total := 1
+	for range dim {
+		if total *= nPerAxis; total > maxDeployment {
+			return nil, fmt.Errorf("%w: a grid of %d points per axis in %d dimensions exceeds %d points", ErrOutOfRange, nPerAxis, dim, maxDeployment)
+		}
+	}
+	positions := make([]common.Vector, total)
+	for k := range positions {
+		pos := common.NewVector(dim)
+		rest := k
+		for axis := range dim {
+			i := rest % nPerAxis
+			rest /= nPerAxis
+			lo, hi := bounds[2*axis], bounds[2*axis+1]
+			if nPerAxis == 1 {
+				pos[axis] = (lo + hi) / 2
+			} else {
+				pos[axis] = lo + (hi-lo)*float64(i)/float64(nPerAxis-1)
+			}
+		}
+		positions[k] = pos
+	}
+	return positions, nil
+}
+
+// DeployCircle returns count points evenly spaced on a circle of the given radius around
+// the center of the bounds, in the plane of the first two axes.
+func DeployCircle(bounds []float64, radius float64, count int) ([]common.Vector, error) {
+	dim, err := boundsDimension(bounds)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case dim < 2:
+		return nil, fmt.Errorf("%w: a circle needs at least 2 dimensions, got %d", ErrDimensionMismatch, dim)
+	case radius <= 0:
+		return nil, fmt.Errorf("%w: circle radius must be positive, got %f", ErrOutOfRange, radius)
+	case count < 1 || count > maxDeployment:
+		return nil, fmt.Errorf("%w: point count must be within [1, %d], got %d", ErrOutOfRange, maxDeployment, count)
+	}
+	center, _ := boundsCenter(bounds)
+	positions := make([]common.Vector, count)
+	for k := range positions {
+		angle := 2 * math.Pi * float64(k) / float64(count)
+		pos := center.Clone()
+		pos[0] += radius * math.Cos(angle)
+		pos[1] += radius * math.Sin(angle)
+		positions[k] = pos
+	}
+	return positions, nil
+}
+
+// DeploySphereSurface returns count points spread over the sphere inscribed in the
+// bounds: a circle in 2D, a Fibonacci lattice (nearly even spacing) in 3D. In more
+// dimensions the points are uniform on the sphere but drawn from a fixed-seed
+// generator, so they are reproducible but not evenly spaced.
+func DeploySphereSurface(bounds []float64, count int) ([]common.Vector, error) {
+	dim, err := boundsDimension(bounds)
+	if err != nil {
+		return nil, err
+	}
+	center, radius := boundsCenter(bounds)
+	switch {
+	case dim < 2:
+		return nil, fmt.Errorf("%w: a sphere needs at least 2 dimensions, got %d", ErrDimensionMismatch, dim)
+	case radius <= 0:
+		return nil, fmt.Errorf("%w: bounds have no extent along some axis", ErrOutOfRange)
+	case dim == 2:
+		return DeployCircle(bounds, radius, count)
+	case count < 1 || count > maxDeployment:
+		return nil, fmt.Errorf("%w: point count must be within [1, %d], got %d", ErrOutOfRange, maxDeployment, count)
+	}
+	positions := make([]common.Vector, count)
+	if dim == 3 {
+		golden := math.Pi * (3 - math.Sqrt(5)) // Golden angle
+		for k := range positions {
+			z := 1 - 2*(float64(k)+0.5)/float64(count)
+			r := math.Sqrt(1 - z*z)
+			angle := golden * float64(k)
+			positions[k] = common.Vector{
+				center[0] + radius*r*math.Cos(angle),
+				center[1] + radius*r*math.Sin(angle),
+				center[2] + radius*z,
+			}
+		}
+		return positions, nil
+	}
+	rng := rand.New(rand.NewSource(1))
+	for k := range positions {
+		dir := common.NewVector(dim)
+		for dir.Norm() == 0 {
+			for i := range dir {
+				dir[i] = rng.NormFloat64()
+			}
+		}
+		pos := center.Clone()
+		_ = pos.AddScaledInPlace(dir.Normalize(), radius)
+		positions[k] = pos
+	}
+	return positions, nil
+}
+
+// DeployVertices returns the 2^dimension corners of the bounds.
+func DeployVertices(bounds []float64) ([]common.Vector, error) {
+	dim, err := boundsDimension(bounds)
+	if err != nil {
+		return nil, err
+	}
+	if dim > maxVertexDimensions {
+		return nil, fmt.Errorf("%w: the corners of %d dimensions exceed %d points", ErrOutOfRange, dim, 1<<maxVertexDimensions)
+	}
+	positions := make([]common.Vector, 1<<dim)
+	for k := range positions {
+		pos := common.NewVector(dim)
+		for axis := range dim {
+			pos[axis] = bounds[2*axis+((k>>axis)&1)]
+		}
+		positions[k] = pos
+	}
+	return positions, nil
+}
+
+// ParseDeployment returns the layout named "grid:N" (N points per axis), "circle:N" (N
+// points on the circle inscribed in the bounds), "sphere:N" or "vertices" within bounds.
+func ParseDeployment(name string, bounds []float64) ([]common.Vector, error) {
+	kind, count, hasCount := strings.Cut(name, ":")
+	n := 0
+	if hasCount {
+		var err error
+		if n, err = strconv.Atoi(count); err != nil {
+			return nil, fmt.Errorf("deployment %q: %w", name, err)
+		}
+	}
+	switch {
+	case kind == "vertices" && !hasCount:
+		return DeployVertices(bounds)
+	case kind == "grid" && hasCount:
+		return DeployGrid(bounds, n)
+	case kind == "circle" && hasCount:
+		if _, err := boundsDimension(bounds); err != nil {
+			return nil, err
+		}
+		_, radius := boundsCenter(bounds)
+		return DeployCircle(bounds, radius, n)
+	case kind == "sphere" && hasCount:
+		return DeploySphereSurface(bounds, n)
+	}
+	return nil, fmt.Errorf("unknown deployment %q, expected grid:N, circle:N, sphere:N or vertices", name)
+}
+
+// AddSensors adds a sensor at each position, e.g. of a deployment layout, and returns
+// their IDs. It stops at the first position that cannot be added.
+func (s *Simulation) AddSensors(positions []common.Vector, radius float64, noise NoiseFunction) ([]string, error) {
+	defer s.events.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(positions))
+	for i, pos := range positions {
+		sensor := NewSensor(pos.Clone(), radius, noise)
+		if err := s.addObject(sensor); err != nil {
+			return ids, fmt.Errorf("sensor %d: %w", i, err)
+		}
+		ids = append(ids, sensor.GetID())
+	}
+	return ids, nil
+}
@@ -0,0 +1,340 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/metrics"
+)
+
+// Metric names of distributed localization. The error gauges cover the targets
+// estimated by both the sensors and the central solver in the last step.
+const (
+	MetricDistributedRounds          = "distributed_rounds"           // Consensus rounds performed
+	MetricDistributedMessages        = "distributed_messages"         // Estimates sent between sensors
+	MetricDistributedDropped         = "distributed_dropped"          // Messages lost by the network
+	MetricDistributedConvergences    = "distributed_convergences"     // Times the sensors of a target reached consensus
+	MetricDistributedConvergenceTime = "distributed_convergence_time" // Gauge: seconds the last consensus took; per target with a target label
+	MetricDistributedError           = "distributed_error"            // Gauge: mean error of the consensus estimates
+	MetricDistributedDisagreement    = "distributed_disagreement"     // Gauge: mean largest distance of a local estimate from the consensus
+	MetricCentralizedError           = "centralized_error"            // Gauge: mean error of the central estimates of the same targets
+)
+
+// distributedStream is the noise stream number reserved for message loss.
+const distributedStream = 1 << 58
+
+// DistributedLocalization configures the decentralized mode: every sensor that measures
+// a target keeps its own estimate of it and improves it by distributed gradient descent,
+// averaging with the estimates its neighbours send over a lossy, delayed network
+// (x_i <- mean(x_i, x_j received) - StepSize * grad f_i, where f_i is the squared range
+// residual of sensor i). The central solve still runs, so both can be compared.
+type DistributedLocalization struct {
+	RoundInterval float64 `json:"round_interval"`        // Seconds between consensus rounds
+	StepSize      float64 `json:"step_size"`             // Gradient step of the local update
+	CommRadius    float64 `json:"comm_radius,omitempty"` // Largest sensor-to-sensor link, 0 = all sensors are linked
+	Latency       float64 `json:"latency,omitempty"`     // Seconds a message is in flight
+	Loss          float64 `json:"loss,omitempty"`        // Probability that a message is dropped
+	Tolerance     float64 `json:"tolerance"`             // Disagreement (units) below which the sensors agree
+}
+
+// DefaultDistributedLocalization runs ten rounds per second over a perfect network.
+func DefaultDistributedLocalization() DistributedLocalization {
+	return DistributedLocalization{RoundInterval: 0.1, StepSize: 0.3, Tolerance: 0.5}
+}
+
+// Validate checks the parameters.
+func (d DistributedLocalization) Validate() error {
+	switch {
+	case d.RoundInterval <= 0:
+		return fmt.Errorf("%w: round interval must be positive, got %f", ErrOutOfRange, d.RoundInterval)
+	case d.StepSize <= 0 || d.StepSize > 1:
+		return fmt.Errorf("%w: step size must be within (0, 1], got %f", ErrOutOfRange, d.StepSize)
+	case d.CommRadius < 0:
+		return fmt.Errorf("%w: communication radius must be non-negative, got %f", ErrOutOfRange, d.CommRadius)
+	case d.Latency < 0:
+		return fmt.Errorf("%w: latency must be non-negative, got %f", ErrOutOfRange, d.Latency)
+	case d.Loss < 0 || d.Loss >= 1:
+		return fmt.Errorf("%w: loss must be within [0, 1), got %f", ErrOutOfRange, d.Loss)
+	case d.Tolerance <= 0:
+		return fmt.Errorf("%w: tolerance must be positive, got %f", ErrOutOfRange, d.Tolerance)
+	}
+	return nil
+}
+
+// DistributedEstimate is the state of the sensors estimating one target.
+type DistributedEstimate struct {
+	Position     common.Vector // Consensus: mean of the local estimates
+	Disagreement float64       // Largest distance of a local estimate from Position
+	Participants int           // Sensors holding an estimate
+	Converged    bool          // Disagreement is below the tolerance
+}
+
+// distributedState holds the local estimates and the messages in flight.
+type distributedState struct {
+	config   DistributedLocalization
+	clock    float64                                        // Time of the last round
+	local    map[string]map[string]common.Vector            // targetID -> sensorID -> local estimate
+	inbox    map[string]map[string]map[string]common.Vector // targetID -> receiver -> sender -> newest estimate received
+	inFlight []consensusMessage
+	started  map[string]float64 // targetID -> time the sensors began to disagree
+	result   map[string]DistributedEstimate
+}
+
+// consensusMessage is a local estimate on its way to a neighbour.
+type consensusMessage struct {
+	targetID, from, to string
+	estimate           common.Vector
+	arrival            float64
+}
+
+// SetDistributedLocalization enables the decentralized mode, nil turns it off and drops
+// the local estimates.
+func (s *Simulation) SetDistributedLocalization(config *DistributedLocalization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config == nil {
+		s.distributed = nil
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	s.distributed = &distributedState{
+		config:  *config,
+		clock:   s.simulationTime,
+		local:   make(map[string]map[string]common.Vector),
+		inbox:   make(map[string]map[string]map[string]common.Vector),
+		started: make(map[string]float64),
+		result:  make(map[string]DistributedEstimate),
+	}
+	return nil
+}
+
+// DistributedLocalization returns the decentralized mode configuration, nil if disabled.
+func (s *Simulation) DistributedLocalization() *DistributedLocalization {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.distributed == nil {
+		return nil
+	}
+	c := s.distributed.config
+	return &c
+}
+
+// GetDistributedEstimate returns the consensus of the sensors about a target after the
+// last step; false if the mode is off or no sensor estimates the target.
+func (s *Simulation) GetDistributedEstimate(targetID string) (DistributedEstimate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.distributed == nil {
+		return DistributedEstimate{}, false
+	}
+	est, ok := s.distributed.result[targetID]
+	if ok {
+		est.Position = est.Position.Clone()
+	}
+	return est, ok
+}
+
+// runDistributed performs the consensus rounds due until the current time on the
+// measurements of the step and records the comparison with the central estimates.
+// The caller holds the lock.
+func (s *Simulation) runDistributed(report *StepReport) {
+	d := s.distributed
+	sensors := s.sortedSensors()
+	targets := s.sortedTargets()
+	for d.clock+d.config.RoundInterval <= s.simulationTime+1e-9 { // Tolerates the rounding of summed step lengths
+		d.clock += d.config.RoundInterval
+		s.consensusRound(sensors, targets)
+	}
+
+	var distributedErr, centralErr, disagreement float64
+	compared, estimated := 0, 0
+	for i := range report.Targets {
+		targetID := report.Targets[i].TargetID
+		est, ok := s.consensus(targetID)
+		if !ok {
+			delete(d.result, targetID)
+			continue
+		}
+		d.result[targetID] = est
+		report.Targets[i].Distributed = est.Position.Clone()
+		estimated++
+		disagreement += est.Disagreement
+		s.recordConvergence(targetID, est)
+		truePos := s.targets[targetID].GetPosition()
+		if central := s.lastErrors[targetID]; central >= 0 {
+			if e, err := s.localizationError(truePos, est.Position); err == nil {
+				distributedErr += e
+				centralErr += central
+				compared++
+			}
+		}
+	}
+	if estimated > 0 {
+		s.metrics.Set(MetricDistributedDisagreement, disagreement/float64(estimated))
+	}
+	if compared > 0 {
+		s.metrics.Set(MetricDistributedError, distributedErr/float64(compared))
+		s.metrics.Set(MetricCentralizedError, centralErr/float64(compared))
+	}
+}
+
+// consensusRound delivers the messages that have arrived, updates every local estimate
+// and sends it to the neighbours. The caller holds the lock.
+func (s *Simulation) consensusRound(sensors []*Sensor, targets []*Target) {
+	d := s.distributed
+	s.metrics.Inc(MetricDistributedRounds)
+	pending := d.inFlight[:0]
+	for _, msg := range d.inFlight {
+		if msg.arrival > d.clock {
+			pending = append(pending, msg)
+			continue
+		}
+		if d.inbox[msg.targetID] == nil {
+			d.inbox[msg.targetID] = make(map[string]map[string]common.Vector)
+		}
+		if d.inbox[msg.targetID][msg.to] == nil {
+			d.inbox[msg.targetID][msg.to] = make(map[string]common.Vector)
+		}
+		d.inbox[msg.targetID][msg.to][msg.from] = msg.estimate
+	}
+	d.inFlight = pending
+
+	for _, tar := range targets {
+		targetID := tar.GetID()
+		latest := s.availableMeasurements(targetID)
+		if len(latest) == 0 {
+			delete(d.local, targetID)
+			delete(d.inbox, targetID)
+			continue
+		}
+		if d.local[targetID] == nil {
+			d.local[targetID] = make(map[string]common.Vector)
+		}
+		local := d.local[targetID]
+		measuring := make(map[string]bool, len(latest))
+		updated := make(map[string]common.Vector, len(latest))
+		for _, m := range latest {
+			measuring[m.SensorID] = true
+			updated[m.SensorID] = s.localUpdate(targetID, m.SensorID, m.SensorPosition, m.Distance)
+		}
+		for id := range local {
+			if !measuring[id] {
+				delete(local, id) // The sensor lost the target
+			}
+		}
+		for id, est := range updated {
+			local[id] = est
+		}
+		// Broadcast the new estimates in sensor order, so the loss draws are reproducible
+		for _, from := range sensors {
+			est, ok := local[from.GetID()]
+			if !ok {
+				continue
+			}
+			for _, to := range sensors {
+				if to == from || !measuring[to.GetID()] || !s.linked(from, to) {
+					continue
+				}
+				s.metrics.Inc(MetricDistributedMessages)
+				if d.config.Loss > 0 && s.distributedRand.Float64() < d.config.Loss {
+					s.metrics.Inc(MetricDistributedDropped)
+					continue
+				}
+				d.inFlight = append(d.inFlight, consensusMessage{
+					targetID: targetID, from: from.GetID(), to: to.GetID(),
+					estimate: est.Clone(), arrival: d.clock + d.config.Latency,
+				})
+			}
+		}
+	}
+}
+
+// localUpdate returns the next estimate of a sensor: the mean of its own estimate and
+// the ones received from its neighbours, moved down the gradient of its squared range
+// residual. A sensor without an estimate starts from its neighbours' mean, or on its
+// range circle along the first axis. The caller holds the lock.
+func (s *Simulation) localUpdate(targetID, sensorID string, sensorPos common.Vector, dist float64) common.Vector {
+	d := s.distributed
+	own, ok := d.local[targetID][sensorID]
+	sum, count := common.NewVector(s.dimension), 0
+	if ok {
+		sum.AddInPlace(own)
+		count++
+	}
+	for from, est := range d.inbox[targetID][sensorID] {
+		if _, alive := d.local[targetID][from]; alive {
+			sum.AddInPlace(est)
+			count++
+		}
+	}
+	if count == 0 {
+		start := sensorPos.Clone()
+		start[0] += dist
+		return start
+	}
+	x := sum.MultiplyByScalar(1 / float64(count))
+	diff, err := x.Subtract(sensorPos)
+	if err != nil {
+		return x
+	}
+	norm := diff.Norm()
+	if norm == 0 {
+		return x
+	}
+	// grad of (|x - s| - d)^2 / 2 is (|x - s| - d) (x - s) / |x - s|
+	_ = x.AddScaledInPlace(diff, -d.config.StepSize*(norm-dist)/norm)
+	return x
+}
+
+// linked reports whether two sensors can exchange messages.
+func (s *Simulation) linked(a, b *Sensor) bool {
+	r := s.distributed.config.CommRadius
+	if r == 0 {
+		return true
+	}
+	dist, err := a.GetPosition().Distance(b.GetPosition())
+	return err == nil && dist <= r
+}
+
+// consensus summarizes the local estimates of a target. The caller holds the lock.
+func (s *Simulation) consensus(targetID string) (DistributedEstimate, bool) {
+	local := s.distributed.local[targetID]
+	if len(local) == 0 {
+		return DistributedEstimate{}, false
+	}
+	mean := common.NewVector(s.dimension)
+	for _, est := range local {
+		mean.AddInPlace(est)
+	}
+	mean.ScaleInPlace(1 / float64(len(local)))
+	spread := 0.0
+	for _, est := range local {
+		if dist, err := est.Distance(mean); err == nil {
+			spread = math.Max(spread, dist)
+		}
+	}
+	return DistributedEstimate{
+		Position:     mean,
+		Disagreement: spread,
+		Participants: len(local),
+		Converged:    spread < s.distributed.config.Tolerance,
+	}, true
+}
+
+// recordConvergence measures how long the sensors of a target took from disagreeing to
+// reaching consensus. The caller holds the lock.
+func (s *Simulation) recordConvergence(targetID string, est DistributedEstimate) {
+	d := s.distributed
+	since, disagreeing := d.started[targetID]
+	switch {
+	case !est.Converged && !disagreeing:
+		d.started[targetID] = s.simulationTime
+	case est.Converged && disagreeing:
+		delete(d.started, targetID)
+		s.metrics.Inc(MetricDistributedConvergences)
+		s.metrics.Set(MetricDistributedConvergenceTime, s.simulationTime-since)
+		s.metrics.Set(metrics.Key(MetricDistributedConvergenceTime, "target", targetID), s.simulationTime-since)
+	}
+}
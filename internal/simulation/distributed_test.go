@@ -0,0 +1,78 @@
+package simulation_test
+
+import (
+	"errors"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+func TestDistributedLocalizationValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*simulation.DistributedLocalization)
+	}{
+		{"round interval 0", func(d *simulation.DistributedLocalization) { d.RoundInterval = 0 }},
+		{"step size 0", func(d *simulation.DistributedLocalization) { d.StepSize = 0 }},
+		{"step size above 1", func(d *simulation.DistributedLocalization) { d.StepSize = 1.5 }},
+		{"negative communication radius", func(d *simulation.DistributedLocalization) { d.CommRadius = -1 }},
+		{"negative latency", func(d *simulation.DistributedLocalization) { d.Latency = -0.1 }},
+		{"loss 1", func(d *simulation.DistributedLocalization) { d.Loss = 1 }},
+		{"tolerance 0", func(d *simulation.DistributedLocalization) { d.Tolerance = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := simulation.DefaultDistributedLocalization()
+			tt.change(&config)
+			sim := newTestSimulation(t, time.Second, nil, cornerAnchors...)
+			if err := sim.SetDistributedLocalization(&config); !errors.Is(err, simulation.ErrOutOfRange) {
+				t.Errorf("got %v, want ErrOutOfRange", err)
+			}
+			if sim.DistributedLocalization() != nil {
+				t.Error("the mode was enabled")
+			}
+		})
+	}
+}
+
+func TestDistributedLocalizationConverges(t *testing.T) {
+	tests := []struct {
+		name          string
+		latency, loss float64
+		commRadius    float64
+	}{
+		{"perfect network", 0, 0, 0},
+		{"latency", 0.5, 0, 0},
+		{"lossy network", 0.2, 0.3, 0},
+		{"neighbours only", 0, 0, 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := newTestSimulation(t, time.Second, simulation.GaussianNoise(0.1), cornerAnchors...)
+			sim.SetSeeds(simulation.NewSeeds(5))
+			addObjects(t, sim, simulation.NewTargetWithID("beacon", common.Vector{10, -5}, nil))
+			config := simulation.DefaultDistributedLocalization()
+			config.Latency, config.Loss, config.CommRadius = tt.latency, tt.loss, tt.commRadius
+			if err := sim.SetDistributedLocalization(&config); err != nil {
+				t.Fatal(err)
+			}
+			stepN(sim, 20, 1)
+
+			est, ok := sim.GetDistributedEstimate("beacon")
+			if !ok || est.Participants != 4 || !est.Converged {
+				t.Fatalf("estimate %+v (%t), want 4 converged participants", est, ok)
+			}
+			if d, _ := est.Position.Distance(common.Vector{10, -5}); d > 1 {
+				t.Errorf("consensus %v is %.2f from the target", est.Position, d)
+			}
+			m := sim.Metrics()
+			if rounds := m.Counter(simulation.MetricDistributedRounds); rounds != 200 {
+				t.Errorf("%v rounds, want 200", rounds)
+			}
+			if dropped := m.Counter(simulation.MetricDistributedDropped); (dropped > 0) != (tt.loss > 0) {
+				t.Errorf("%v messages dropped at loss %v", dropped, tt.loss)
+			}
+		})
+	}
+}
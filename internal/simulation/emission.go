@@ -0,0 +1,116 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+)
+
+// EmissionSchedule models how a tag-like target transmits ("blinks").
+// Sensors can only range a scheduled target in steps where it blinked, and two
+// blinks that overlap in time at the same sensor collide and are both lost.
+type EmissionSchedule struct {
+	Interval float64 // Nominal seconds between blinks
+	Jitter   float64 // Each interval is randomized uniformly by +/- Jitter seconds
+	Duration float64 // On-air time of one blink in seconds, used for collision detection
+}
+
+// Validate checks that the schedule is usable.
+func (e EmissionSchedule) Validate() error {
+	if e.Interval <= 0 {
+		return fmt.Errorf("%w: emission interval must be positive, got %f", ErrOutOfRange, e.Interval)
+	}
+	if e.Jitter < 0 || e.Jitter >= e.Interval {
+		return fmt.Errorf("%w: emission jitter must be within [0, interval), got %f", ErrOutOfRange, e.Jitter)
+	}
+	if e.Duration < 0 {
+		return fmt.Errorf("%w: emission duration must be non-negative, got %f", ErrOutOfRange, e.Duration)
+	}
+	return nil
+}
+
+// Metric names maintained by the emission model.
+const (
+	MetricEmissions          = "emissions"           // Blinks transmitted by scheduled targets
+	MetricEmissionCollisions = "emission_collisions" // Blinks lost at a sensor due to overlap with another tag
+)
+
+// emitterState tracks the blink timing of one scheduled target.
+type emitterState struct {
+	schedule     EmissionSchedule
+	nextEmission float64
+	started      bool
+}
+
+// advanceEmissions returns the blink times within (from, to] of every scheduled target.
+// Targets without a schedule are absent from the map and treated as always transmitting.
+func (s *Simulation) advanceEmissions(from, to float64) map[string][]float64 {
+	emissions := make(map[string][]float64)
+	for _, tar := range s.sortedTargets() {
+		state := tar.emitter
+		if state == nil {
+			continue
+		}
+		if !state.started {
+			// Random phase so that tags switched on together do not blink in lockstep
+			state.nextEmission = from + tar.noiseRand.Float64()*state.schedule.Interval
+			state.started = true
+		}
+		blinks := []float64{}
+		for state.nextEmission <= to {
+			blinks = append(blinks, state.nextEmission)
+			jitter := (tar.noiseRand.Float64()*2 - 1) * state.schedule.Jitter
+			state.nextEmission += state.schedule.Interval + jitter
+		}
+		s.metrics.Add(MetricEmissions, float64(len(blinks)))
+		emissions[tar.GetID()] = blinks
+	}
+	return emissions
+}
+
+// collides reports whether every blink in own overlaps a blink of one of the others,
+// i.e. no blink of this target could be received cleanly.
+func collides(own []float64, others [][]float64, duration float64) bool {
+	if len(own) == 0 {
+		return false
+	}
+	for _, t := range own {
+		clean := true
+		for _, other := range others {
+			for _, u := range other {
+				if math.Abs(t-u) < duration {
+					clean = false
+					break
+				}
+			}
+			if !clean {
+				break
+			}
+		}
+		if clean {
+			return false
+		}
+	}
+	return true
+}
+
+// SetEmissionSchedule makes the target transmit on the given schedule instead of continuously.
+func (t *Target) SetEmissionSchedule(schedule EmissionSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	t.emitter = &emitterState{schedule: schedule}
+	return nil
+}
+
+// ClearEmissionSchedule makes the target transmit continuously again.
+func (t *Target) ClearEmissionSchedule() {
+	t.emitter = nil
+}
+
+// EmissionSchedule returns the emission schedule of the target, false if it transmits continuously.
+func (t *Target) EmissionSchedule() (EmissionSchedule, bool) {
+	if t.emitter == nil {
+		return EmissionSchedule{}, false
+	}
+	return t.emitter.schedule, true
+}
@@ -0,0 +1,38 @@
+package simulation_test
+
+import (
+	"errors"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+)
+
+func TestEmissionScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule simulation.EmissionSchedule
+		valid    bool
+	}{
+		{"blink every second", simulation.EmissionSchedule{Interval: 1, Jitter: 0.5, Duration: 0.01}, true},
+		{"longer than the interval", simulation.EmissionSchedule{Interval: 1, Duration: 1.5}, true},
+		{"interval 0", simulation.EmissionSchedule{}, false},
+		{"jitter of a whole interval", simulation.EmissionSchedule{Interval: 1, Jitter: 1}, false},
+		{"negative jitter", simulation.EmissionSchedule{Interval: 1, Jitter: -0.1}, false},
+		{"negative duration", simulation.EmissionSchedule{Interval: 1, Duration: -0.1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, simulation.ErrOutOfRange) {
+				t.Errorf("got %v, want ErrOutOfRange", err)
+			}
+			tag := simulation.NewTarget(common.Vector{0, 0})
+			if err := tag.SetEmissionSchedule(tt.schedule); (err == nil) != tt.valid {
+				t.Errorf("SetEmissionSchedule: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package simulation
+
+import (
+	"errors"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// Errors returned (wrapped) by the simulation API; check for them with errors.Is. The
+// solver errors reach callers through TargetReport.Err.
+var (
+	ErrDimensionMismatch = common.ErrDimensionMismatch // An object, vector or setting of another dimension
+	ErrOutOfRange        = common.ErrOutOfRange        // A parameter outside its valid range
+	ErrDuplicateID       = errors.New("duplicate ID")  // An object with the ID is already in the simulation or scheduled
+	ErrNotFound          = errors.New("not found")     // No object with the ID
+
+	ErrInsufficientMeasurements = multilateration.ErrInsufficientMeasurements
+	ErrDegenerateGeometry       = multilateration.ErrDegenerateGeometry
+)
@@ -0,0 +1,216 @@
+package simulation
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EventKind identifies the type of a simulation event.
+type EventKind int
+
+const (
+	EventObjectAdded        EventKind = iota // A sensor or target was added
+	EventObjectRemoved                       // A sensor or target was removed
+	EventTargetEnteredRange                  // A sensor started detecting a target
+	EventTargetLeftRange                     // A sensor stopped detecting a target
+	EventLocalized                           // A target was localized in this step
+	EventLocalizationFailed                  // A target could not be localized in this step
+	EventErrorExceeded                       // The localization error rose above the threshold
+	EventSensorFailed                        // A sensor failed permanently
+	EventSensorOutage                        // A sensor went down temporarily
+	EventSensorRestored                      // A sensor came back from an outage
+	EventManeuver                            // An injected maneuver of a target started
+)
+
+// String returns a short name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventObjectAdded:
+		return "object added"
+	case EventObjectRemoved:
+		return "object removed"
+	case EventTargetEnteredRange:
+		return "target entered range"
+	case EventTargetLeftRange:
+		return "target left range"
+	case EventLocalized:
+		return "localized"
+	case EventLocalizationFailed:
+		return "localization failed"
+	case EventErrorExceeded:
+		return "error exceeded"
+	case EventSensorFailed:
+		return "sensor failed"
+	case EventSensorOutage:
+		return "sensor outage"
+	case EventSensorRestored:
+		return "sensor restored"
+	case EventManeuver:
+		return "maneuver"
+	default:
+		return fmt.Sprintf("event(%d)", int(k))
+	}
+}
+
+// Event is something that happened in the simulation. Only the fields relevant to
+// the kind are set.
+type Event struct {
+	Kind     EventKind
+	Time     float64             // Simulation time
+	ObjectID string              // The added or removed object or the failing sensor, otherwise the target
+	SensorID string              // Range and sensor failure events
+	Outcome  LocalizationOutcome // Localization events
+	Error    float64             // Localization error for EventLocalized and EventErrorExceeded, -1 if unavailable
+	Err      error               // Solver error of EventLocalizationFailed, nil for insufficient measurements
+}
+
+// EventHandler receives simulation events.
+type EventHandler func(event Event)
+
+// EventBus distributes simulation events to subscribers, so alerting and custom
+// logging can be built without touching Step. Events raised while the simulation
+// is locked are queued and delivered synchronously once it is released, on the
+// goroutine that caused them, so handlers may call back into the simulation.
+type EventBus struct {
+	mu             sync.Mutex
+	subscribers    map[int]eventSubscriber
+	nextID         int
+	queue          []Event
+	errorThreshold float64 // Meters, 0 disables EventErrorExceeded
+}
+
+type eventSubscriber struct {
+	handler EventHandler
+	kinds   map[EventKind]bool // nil means all kinds
+}
+
+func newEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]eventSubscriber)}
+}
+
+// Events returns the event bus of the simulation.
+func (s *Simulation) Events() *EventBus {
+	return s.events
+}
+
+// Subscribe registers a handler for the given kinds of events, all kinds if none
+// are given. The returned function removes the subscription.
+func (b *EventBus) Subscribe(handler EventHandler, kinds ...EventKind) (unsubscribe func()) {
+	sub := eventSubscriber{handler: handler}
+	if len(kinds) > 0 {
+		sub.kinds = make(map[EventKind]bool, len(kinds))
+		for _, k := range kinds {
+			sub.kinds[k] = true
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	return func() {
+		b.mu.Lock()
+		delete(b.subscribers, id)
+		b.mu.Unlock()
+	}
+}
+
+// SetErrorThreshold sets the localization error (meters) above which
+// EventErrorExceeded is raised, once per excursion. 0 disables the event.
+func (b *EventBus) SetErrorThreshold(threshold float64) error {
+	if threshold < 0 {
+		return fmt.Errorf("%w: error threshold must be non-negative, got %f", ErrOutOfRange, threshold)
+	}
+	b.mu.Lock()
+	b.errorThreshold = threshold
+	b.mu.Unlock()
+	return nil
+}
+
+// ErrorThreshold returns the threshold of EventErrorExceeded, 0 if disabled.
+func (b *EventBus) ErrorThreshold() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.errorThreshold
+}
+
+// emit queues an event for delivery by flush. Without subscribers it is dropped.
+func (b *EventBus) emit(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subscribers) > 0 {
+		b.queue = append(b.queue, event)
+	}
+}
+
+// flush delivers the queued events. The caller must not hold the simulation lock.
+func (b *EventBus) flush() {
+	b.mu.Lock()
+	queue := b.queue
+	b.queue = nil
+	subscribers := make([]eventSubscriber, 0, len(b.subscribers))
+	for id := 0; id < b.nextID; id++ { // In subscription order
+		if sub, ok := b.subscribers[id]; ok {
+			subscribers = append(subscribers, sub)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, event := range queue {
+		for _, sub := range subscribers {
+			if sub.kinds == nil || sub.kinds[event.Kind] {
+				sub.handler(event)
+			}
+		}
+	}
+}
+
+// rangeKey identifies a sensor-target pair for range transitions.
+type rangeKey struct {
+	sensorID, targetID string
+}
+
+// updateRange records whether a sensor detects a target and raises an event on change.
+func (s *Simulation) updateRange(sensorID, targetID string, inRange bool) {
+	key := rangeKey{sensorID, targetID}
+	if s.inRange[key] == inRange {
+		return
+	}
+	kind := EventTargetLeftRange
+	if inRange {
+		kind = EventTargetEnteredRange
+		s.inRange[key] = true
+	} else {
+		delete(s.inRange, key)
+	}
+	s.events.emit(Event{Kind: kind, Time: s.simulationTime, ObjectID: targetID, SensorID: sensorID})
+}
+
+// emitLocalization raises the localization events of a target after it was solved.
+func (s *Simulation) emitLocalization(report TargetReport) {
+	if report.Outcome == OutcomeOutsideRegion {
+		return
+	}
+	id := report.TargetID
+	event := Event{Time: s.simulationTime, ObjectID: id, Outcome: report.Outcome, Error: s.lastErrors[id]}
+	if report.Outcome != OutcomeLocalized {
+		event.Kind = EventLocalizationFailed
+		event.Err = report.Err
+		s.events.emit(event)
+		return
+	}
+	event.Kind = EventLocalized
+	s.events.emit(event)
+
+	threshold := s.events.ErrorThreshold()
+	above := threshold > 0 && event.Error > threshold
+	if above && !s.errorExceeded[id] {
+		event.Kind = EventErrorExceeded
+		s.events.emit(event)
+	}
+	if above {
+		s.errorExceeded[id] = true
+	} else {
+		delete(s.errorExceeded, id)
+	}
+}
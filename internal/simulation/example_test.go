@@ -0,0 +1,1110 @@
+package simulation_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/association"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/network"
+	"multilateration-sim/internal/simulation"
+	"os"
+	"strings"
+	"time"
+)
+
+// Build a scenario programmatically, run it headless and read back the metrics.
+func Example() {
+	sim, err := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil)) // Radius 0 means unlimited range
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{5, -12}, nil) // Static target
+	_ = sim.AddObject(target)
+
+	for i := 0; i < 10; i++ {
+		sim.Step(0.1)
+	}
+
+	estimate, _ := sim.GetLastEstimate(target.GetID())
+	locErr, _ := sim.GetLastLocalizationError(target.GetID())
+	fmt.Printf("Time: %.1fs\n", sim.GetCurrentTime())
+	fmt.Println("Estimate:", estimate.Position)
+	fmt.Printf("Localization error: %.3f\n", locErr)
+	// Output:
+	// Time: 1.0s
+	// Estimate: [5.000, -12.000]
+	// Localization error: 0.000
+}
+
+// Attach a custom noise model to a sensor: any func(float64) float64 works.
+func ExampleNoiseFunction() {
+	constantBias := func(trueDistance float64, _ *rand.Rand) float64 {
+		return trueDistance + 0.25
+	}
+	sensor := simulation.NewSensor(common.Vector{0, 0}, 100, constantBias)
+	target := simulation.NewTargetWithMotion(common.Vector{3, 4}, nil)
+
+	dist, inRange, _ := sensor.MeasureDistance(target)
+	fmt.Printf("In range: %t, measured: %.2f\n", inRange, dist)
+	// Output:
+	// In range: true, measured: 5.25
+}
+
+// Compose a deterministic error model from primitives.
+func ExampleChainNoise() {
+	noise := simulation.ChainNoise(simulation.BiasNoise(0.3), simulation.QuantizationNoise(0.5))
+	fmt.Printf("%.2f\n", noise(5.0, nil)) // Deterministic models ignore the random stream
+	// Output:
+	// 5.50
+}
+
+// Drive a target along a scripted path instead of a random walk.
+func ExampleNewWaypointMotion() {
+	path, _ := simulation.NewWaypointMotion([]common.Vector{{10, 0}, {10, 10}}, 5, false)
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, path)
+
+	bounds := []float64{-100, 100, -100, 100}
+	for i := 0; i < 3; i++ {
+		target.Update(1.0, bounds)
+		fmt.Println(target.GetPosition())
+	}
+	// Output:
+	// [5.000, 0.000]
+	// [10.000, 0.000]
+	// [10.000, 5.000]
+}
+
+// Sensors on a line cannot place a target in the plane: the solve fails, the step is
+// degraded, and Err wraps the solver's error with the target, joined with any sensor
+// errors.
+func ExampleStepReport_Err() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second)
+	for _, x := range []float64{-20, 0, 20} {
+		_ = sim.AddObject(simulation.NewSensor(common.Vector{x, 0}, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{5, 10}, nil)
+	_ = sim.AddObject(target)
+
+	report := sim.Step(1)
+	solve := report.Targets[0]
+	fmt.Printf("%s with %d measurements\n", solve.Outcome, solve.NumMeasurements)
+	fmt.Println("degraded:", report.Degraded(), "wraps the solver error:", errors.Is(report.Err(), solve.Err))
+
+	errOffline := errors.New("sensor offline")
+	report.SensorErrors = append(report.SensorErrors, simulation.SensorError{SensorID: "S1", TargetID: target.GetID(), Err: errOffline})
+	fmt.Println("sensor error joined:", errors.Is(report.Err(), errOffline), errors.Is(report.Err(), solve.Err))
+	// Output:
+	// solver failed with 3 measurements
+	// degraded: true wraps the solver error: true
+	// sensor error joined: true true
+}
+
+// A sensor that loses every measurement, then none: the dropout counters and rates show
+// how much it lost.
+func ExampleSensor_SetDropoutProbability() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second)
+	lossy := simulation.NewSensor(common.Vector{-40, -40}, 0, nil)
+	_ = sim.AddObject(lossy)
+	for _, pos := range []common.Vector{{40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	_ = sim.AddObject(simulation.NewTargetWithMotion(common.Vector{5, 5}, nil))
+
+	m := sim.Metrics()
+	_ = lossy.SetDropoutProbability(1)
+	for i := 0; i < 5; i++ {
+		sim.Step(1)
+	}
+	fmt.Printf("dropped %.0f of %.0f, rate %.2f, lossy %.2f\n", m.Counter(simulation.MetricMeasurementsDropped),
+		m.Counter(simulation.MetricMeasurementsAttempted), sim.DropoutRate(""), sim.DropoutRate(lossy.GetID()))
+
+	_ = lossy.SetDropoutProbability(0)
+	for i := 0; i < 5; i++ {
+		sim.Step(1)
+	}
+	fmt.Printf("dropped %.0f of %.0f, rate %.2f, lossy %.2f\n", m.Counter(simulation.MetricMeasurementsDropped),
+		m.Counter(simulation.MetricMeasurementsAttempted), sim.DropoutRate(""), sim.DropoutRate(lossy.GetID()))
+	// Output:
+	// dropped 5 of 20, rate 0.25, lossy 1.00
+	// dropped 5 of 40, rate 0.12, lossy 0.50
+}
+
+// Tags blink on a schedule: a sensor only ranges them in the steps they transmit, and
+// blinks of two tags that overlap at a sensor are both lost. The near pair blinks every
+// second and each blink lasts longer than that, so the pair always collides; the far tag
+// is alone at its sensor and blinks every other second.
+func ExampleTarget_SetEmissionSchedule() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second)
+	_ = sim.AddObject(simulation.NewSensor(common.Vector{0, 0}, 20, nil))
+	_ = sim.AddObject(simulation.NewSensor(common.Vector{40, 40}, 20, nil))
+	for _, pos := range []common.Vector{{10, 0}, {0, 10}} {
+		tag := simulation.NewTargetWithMotion(pos, nil)
+		_ = tag.SetEmissionSchedule(simulation.EmissionSchedule{Interval: 1, Duration: 1.5})
+		_ = sim.AddObject(tag)
+	}
+	far := simulation.NewTargetWithMotion(common.Vector{40, 30}, nil)
+	_ = far.SetEmissionSchedule(simulation.EmissionSchedule{Interval: 2, Duration: 0.1})
+	_ = sim.AddObject(far)
+
+	m := sim.Metrics()
+	silent := 0
+	for i := 0; i < 6; i++ {
+		attempted, collisions := m.Counter(simulation.MetricMeasurementsAttempted), m.Counter(simulation.MetricEmissionCollisions)
+		sim.Step(1)
+		ranged := m.Counter(simulation.MetricMeasurementsAttempted) - attempted
+		collided := m.Counter(simulation.MetricEmissionCollisions) - collisions
+		if ranged-collided == 0 {
+			silent++ // The far tag did not blink: nothing got through
+		}
+	}
+	fmt.Printf("blinks %.0f, ranges %.0f, collided %.0f\n", m.Counter(simulation.MetricEmissions),
+		m.Counter(simulation.MetricMeasurementsAttempted), m.Counter(simulation.MetricEmissionCollisions))
+	fmt.Println("steps without a clean range:", silent)
+	// Output:
+	// blinks 15, ranges 15, collided 12
+	// steps without a clean range: 3
+}
+
+// With only coarse sensor positions, range-only SLAM refines them from the ranges of a
+// moving target; the joint estimate covers a sliding window of ticks.
+func ExampleSimulation_EnableRangeOnlySLAM() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second)
+	var sensors []*simulation.Sensor
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		sensor := simulation.NewSensor(pos, 0, nil)
+		sensors = append(sensors, sensor)
+		_ = sim.AddObject(sensor)
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{-30, -20}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{6, 4})
+	_ = sim.AddObject(target)
+
+	_ = sim.EnableRangeOnlySLAM(simulation.SLAMConfig{AnchorSigma: 3, RangeSigma: 0.1, Window: 5})
+	_, ok := sim.GetAnchorEstimate(sensors[0].GetID())
+	fmt.Println("estimate before any range:", ok)
+
+	for i := 0; i < 10; i++ {
+		sim.Step(1)
+	}
+	_, ok = sim.GetAnchorEstimate(sensors[0].GetID())
+	fmt.Println("estimate after 10 steps:", ok)
+	fmt.Println("poses in the window:", len(sim.GetSLAMTrajectory(target.GetID())))
+	// Output:
+	// estimate before any range: false
+	// estimate after 10 steps: true
+	// poses in the window: 5
+}
+
+// Select a motion model when constructing a target.
+func ExampleNewTargetWithMotion() {
+	bounds := []float64{-100, 100, -100, 100}
+
+	straight := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = straight.SetVelocity(common.Vector{2, 1})
+
+	orbit, _ := simulation.NewCircularMotion(common.Vector{0, 0}, 10, math.Pi/2, 0, 1)
+	orbiting := simulation.NewTargetWithMotion(common.Vector{10, 0}, orbit)
+
+	for i := 0; i < 2; i++ {
+		straight.Update(1.0, bounds)
+		orbiting.Update(1.0, bounds)
+	}
+	fmt.Println(straight.GetPosition())
+	fmt.Println(orbiting.GetPosition())
+	// Output:
+	// [4.000, 2.000]
+	// [-10.000, 0.000]
+}
+
+// Use a mobile anchor that patrols a square; measurements follow its position.
+func ExampleNewSensorWithMotion() {
+	patrol, _ := simulation.NewWaypointMotion([]common.Vector{{10, 0}, {10, 10}, {0, 10}, {0, 0}}, 10, true)
+	drone := simulation.NewSensorWithMotion(common.Vector{0, 0}, 0, nil, patrol)
+	target := simulation.NewTargetWithMotion(common.Vector{10, 10}, nil)
+
+	bounds := []float64{-100, 100, -100, 100}
+	for i := 0; i < 2; i++ {
+		drone.Update(1.0, bounds)
+		dist, _, _ := drone.MeasureDistance(target)
+		fmt.Printf("%s -> %.1f\n", drone.GetPosition(), dist)
+	}
+	// Output:
+	// [10.000, 0.000] -> 10.0
+	// [10.000, 10.000] -> 0.0
+}
+
+// Sensors with a slower measurement rate and a delivery latency produce
+// timestamped, possibly stale measurements.
+func ExampleSensor_SetLatency() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	sensor := simulation.NewSensor(common.Vector{0, 0}, 0, nil)
+	_ = sensor.SetMeasurementInterval(2) // Every second step
+	_ = sensor.SetLatency(0.1)           // Arrives one step later
+	_ = sim.AddObject(sensor)
+	target := simulation.NewTargetWithMotion(common.Vector{3, 4}, nil)
+	_ = sim.AddObject(target)
+
+	for i := 0; i < 4; i++ {
+		sim.Step(0.1)
+		for _, m := range sim.GetLatestMeasurements(target.GetID()) {
+			fmt.Printf("t=%.1f: d=%.1f taken at %.1f\n", sim.GetCurrentTime(), m.Distance, m.Timestamp)
+		}
+	}
+	// Output:
+	// t=0.3: d=5.0 taken at 0.2
+	// t=0.4: d=5.0 taken at 0.2
+}
+
+// Remove a sensor while the simulation is running: with only three left the 2D target
+// can still be localized, with two it cannot.
+// Failures wrap sentinel errors, so callers can branch on the kind of failure.
+func ExampleSimulation_AddObject() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	sensor := simulation.NewSensor(common.Vector{0, 0}, 0, nil)
+	for _, obj := range []simulation.SimulationObject{
+		sensor,
+		sensor,
+		simulation.NewSensor(common.Vector{0, 0, 0}, 0, nil),
+	} {
+		err := sim.AddObject(obj)
+		switch {
+		case err == nil:
+			fmt.Println("added")
+		case errors.Is(err, simulation.ErrDuplicateID):
+			fmt.Println("already added")
+		case errors.Is(err, simulation.ErrDimensionMismatch):
+			fmt.Println("wrong dimension")
+		}
+	}
+	err := sim.RemoveObject("missing")
+	fmt.Println("not found:", errors.Is(err, simulation.ErrNotFound))
+	_, err = simulation.NewBestGDOPSelection(0)
+	fmt.Println("out of range:", errors.Is(err, simulation.ErrOutOfRange))
+	// Output:
+	// added
+	// already added
+	// wrong dimension
+	// not found: true
+	// out of range: true
+}
+
+func ExampleSimulation_RemoveObject() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	var sensors []*simulation.Sensor
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		sensor := simulation.NewSensor(pos, 0, nil)
+		sensors = append(sensors, sensor)
+		_ = sim.AddObject(sensor)
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{5, -12}, nil)
+	_ = sim.AddObject(target)
+
+	for _, sensor := range sensors[:2] {
+		if err := sim.RemoveObject(sensor.GetID()); err != nil {
+			fmt.Println(err)
+			return
+		}
+		sim.Step(0.1)
+		locErr, _ := sim.GetLastLocalizationError(target.GetID()) // -1 when no estimate
+		fmt.Printf("%d sensors, located: %t\n", len(sim.GetSensors()), locErr >= 0)
+	}
+	// Output:
+	// 3 sensors, located: true
+	// 2 sensors, located: false
+}
+
+// Only targets inside the monitored area are localized; a target crossing into it gets
+// a track from the moment it enters.
+func ExampleSimulation_SetRegionOfInterest() {
+	sim, _ := simulation.NewSimulation(2, []float64{-100, 100, -100, 100}, time.Second)
+	for _, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}, {100, 100}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{-60, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{20, 0})
+	_ = sim.AddObject(target)
+
+	area, _ := simulation.NewBoxRegion(common.Vector{-20, -50}, common.Vector{50, 50})
+	sim.SetRegionOfInterest(area)
+	for i := 0; i < 4; i++ {
+		report := sim.Step(1)
+		fmt.Printf("x=%.0f: %s\n", target.GetPosition()[0], report.Targets[0].Outcome)
+	}
+	fmt.Println("skipped:", sim.Metrics().Counter(simulation.MetricOutsideRegion))
+	// Output:
+	// x=-40: outside region
+	// x=-20: localized
+	// x=0: localized
+	// x=20: localized
+	// skipped: 1
+}
+
+// With adaptive stepping a fast target is measured more often than a slow one; before
+// the first fix the shortest step is used.
+func ExampleSimulation_NextStepDuration() {
+	sim, _ := simulation.NewSimulation(2, []float64{-500, 500, -500, 500}, time.Second/10)
+	for _, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}, {100, 100}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{2, 0})
+	_ = sim.AddObject(target)
+
+	config := simulation.DefaultAdaptiveStepping(0.1) // Steps of 0.025 to 0.4 s, 1 unit of travel per step
+	_ = sim.SetAdaptiveStepping(&config)
+	for _, speed := range []float64{2, 2, 2, 2, 2, 40, 40} {
+		_ = target.SetVelocity(common.Vector{speed, 0})
+		dt := sim.NextStepDuration()
+		sim.Step(dt)
+		fmt.Printf("speed %2.0f: step %.4f s\n", speed, dt)
+	}
+	rate, _ := sim.Metrics().Gauge(simulation.MetricStepRate)
+	fmt.Printf("rate %.0f Hz\n", rate)
+	// Output:
+	// speed  2: step 0.0250 s
+	// speed  2: step 0.0375 s
+	// speed  2: step 0.0563 s
+	// speed  2: step 0.0844 s
+	// speed  2: step 0.1266 s
+	// speed 40: step 0.0250 s
+	// speed 40: step 0.0250 s
+	// rate 40 Hz
+}
+
+// Step on one goroutine while another reads consistent snapshots, as a renderer would.
+func ExampleSimulation_Snapshot() {
+	sim, _ := simulation.NewSimulation(2, []float64{-100, 100, -100, 100}, time.Second/10)
+	for _, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}, {100, 100}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			sim.Step(0.1)
+		}
+	}()
+
+	consistent := true
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		state := sim.Snapshot()
+		if len(state.Targets) == 1 {
+			// The target moves 1 unit per step, never half a step behind the clock
+			consistent = consistent && math.Abs(state.Targets[0].Position[0]-float64(state.Tick)) < 1e-9
+		}
+	}
+	final := sim.Snapshot()
+	fmt.Println("consistent:", consistent)
+	fmt.Printf("tick %d, t=%.1f s, x=%.1f, estimated x=%.1f\n", final.Tick, final.Time, final.Targets[0].Position[0], final.Targets[0].Estimate.Position[0])
+	// Output:
+	// consistent: true
+	// tick 100, t=10.0 s, x=100.0, estimated x=100.0
+}
+
+func ExampleSimulation_Subscribe() {
+	sim, _ := simulation.NewSimulation(2, []float64{-100, 100, -100, 100}, time.Second/10)
+	for _, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}, {100, 100}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+
+	// A consumer on its own goroutine, e.g. a network publisher
+	sub := sim.Subscribe(16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for state := range sub.C() {
+			t := state.Targets[0]
+			fmt.Printf("tick %d: x=%.1f, estimated x=%.1f\n", state.Tick, t.Position[0], t.Estimate.Position[0])
+		}
+	}()
+
+	for i := 0; i < 3; i++ {
+		sim.Step(0.1)
+	}
+	sub.Close()
+	<-done
+	fmt.Println("dropped:", sub.Dropped())
+	// Output:
+	// tick 1: x=1.0, estimated x=1.0
+	// tick 2: x=2.0, estimated x=2.0
+	// tick 3: x=3.0, estimated x=3.0
+	// dropped: 0
+}
+
+func ExampleEventBus_Subscribe() {
+	sim, _ := simulation.NewSimulation(2, []float64{-200, 200, -200, 200}, time.Second/10)
+	for _, pos := range []common.Vector{{-200, -200}, {200, -200}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	short := simulation.NewSensor(common.Vector{100, 100}, 110, nil)
+	_ = sim.AddObject(short)
+
+	// Alert when the target enters or leaves the coverage of the short-range sensor
+	sim.Events().Subscribe(func(e simulation.Event) {
+		if e.SensorID == short.GetID() {
+			fmt.Printf("t=%.1f s: %s\n", e.Time, e.Kind)
+		}
+	}, simulation.EventTargetEnteredRange, simulation.EventTargetLeftRange)
+	failures := 0
+	sim.Events().Subscribe(func(simulation.Event) { failures++ }, simulation.EventLocalizationFailed)
+
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{100, 0})
+	_ = sim.AddObject(target)
+	for i := 0; i < 20; i++ {
+		sim.Step(0.1)
+	}
+	fmt.Println("steps without a fix:", failures)
+	// Output:
+	// t=0.6 s: target entered range
+	// t=1.5 s: target left range
+	// steps without a fix: 11
+}
+
+func ExampleSimulation_SetLogger() {
+	sim, _ := simulation.NewSimulation(2, []float64{-100, 100, -100, 100}, time.Second/10)
+	_ = sim.AddObject(simulation.NewSensor(common.Vector{0, 0}, 0, nil))
+	_ = sim.AddObject(simulation.NewTarget(common.Vector{10, 10}))
+
+	// Warnings only, without the volatile time and ID fields
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "target" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	sim.SetLogger(slog.New(handler))
+	sim.Step(0.1)
+	sim.LogCurrentState()
+	// Output:
+	// level=WARN msg="insufficient measurements" tick=1 sim_time=0.1 measurements=1 required=3
+}
+
+// A wall between a target and one sensor: first it blocks the range, then, as a
+// non-line-of-sight obstacle, it lets the range through 4 units too long.
+func ExampleNewObstacle() {
+	sim, _ := simulation.NewSimulation(2, []float64{-60, 60, -60, 60}, time.Second)
+	for _, pos := range []common.Vector{{-50, 0}, {50, 0}, {0, 50}, {0, -50}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{10, 0}, nil)
+	_ = sim.AddObject(target)
+	wallShape, _ := simulation.NewBoxRegion(common.Vector{20, -5}, common.Vector{30, 5})
+	wall, _ := simulation.NewObstacle(wallShape)
+	_ = sim.AddObject(wall)
+
+	show := func() {
+		var ranges []string
+		for _, m := range sim.GetLatestMeasurements(target.GetID()) {
+			ranges = append(ranges, fmt.Sprintf("%.0f", m.Distance))
+		}
+		fmt.Println(strings.Join(ranges, " "))
+	}
+	sim.Step(1)
+	show()
+	wall.SetNLOSBias(4)
+	sim.Step(1)
+	show()
+	// Output:
+	// 60 51 51
+	// 60 44 51 51
+}
+
+// Three targets crossing a field of sensors that cannot tell them apart: each associator
+// works out which range belongs to which target from the predicted tracks alone.
+func ExampleSimulation_SetAssociator() {
+	for _, name := range []string{"nn", "gnn", "jpda"} {
+		sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+		sim.SetSeeds(simulation.NewSeeds(7))
+		associator, _ := association.Parse(name, association.DefaultOptions())
+		sim.SetAssociator(associator)
+		for _, pos := range []common.Vector{{-40, -40}, {40, -35}, {35, 45}, {-45, 30}, {5, -5}} {
+			_ = sim.AddObject(simulation.NewSensor(pos, 0, simulation.GaussianNoise(0.2)))
+		}
+		var targets []*simulation.Target
+		velocities := []common.Vector{{10, 0}, {-10, 0}, {0, 10}}
+		for i, start := range []common.Vector{{-30, -10}, {30, 10}, {15, -40}} {
+			target := simulation.NewTargetWithMotion(start, simulation.NewConstantVelocityMotion())
+			_ = target.SetVelocity(velocities[i])
+			_ = sim.AddObject(target)
+			targets = append(targets, target)
+		}
+		worst := 0.0
+		for i := 0; i < 60; i++ {
+			sim.Step(0.1)
+			for _, target := range targets {
+				if e, ok := sim.GetLastLocalizationError(target.GetID()); ok && e > worst {
+					worst = e
+				}
+			}
+		}
+		fmt.Printf("%s: %.0f misassociations, worst error %.1f\n", name,
+			sim.Metrics().Counter(simulation.MetricMisassociations), worst)
+	}
+	// Output:
+	// nn: 12 misassociations, worst error 0.6
+	// gnn: 12 misassociations, worst error 0.6
+	// jpda: 14 misassociations, worst error 0.7
+}
+
+// A target that enters the scene at t=0.3 s and leaves at t=0.6 s.
+func ExampleSimulation_ScheduleSpawn() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {0, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	visitor := simulation.NewTargetWithMotion(common.Vector{5, 5}, nil)
+	_ = sim.ScheduleSpawn(visitor, 0.3)
+	_ = sim.ScheduleDespawn(visitor.GetID(), 0.6)
+	sim.Events().Subscribe(func(e simulation.Event) {
+		fmt.Printf("t=%.1f %s\n", e.Time, e.Kind)
+	}, simulation.EventObjectAdded, simulation.EventObjectRemoved)
+
+	for i := 0; i < 8; i++ {
+		sim.Step(0.1)
+		if i == 3 {
+			estimate, _ := sim.GetLastEstimate(visitor.GetID())
+			fmt.Println("estimate:", estimate.Position)
+		}
+	}
+	fmt.Println("targets:", len(sim.GetTargets()))
+	// Output:
+	// t=0.3 object added
+	// estimate: [5.000, 5.000]
+	// t=0.6 object removed
+	// targets: 0
+}
+
+// Time-of-arrival ranging with a tag whose clock is 100 ns fast: estimating the common
+// bias removes it, but anchors that are themselves a few nanoseconds apart still cost
+// accuracy.
+func ExampleSimulation_SetSignalSpeed() {
+	const speedOfLight = 299792458.0
+	run := func(solver multilateration.SolveFunc, anchorSpread float64) float64 {
+		sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+		_ = sim.SetSignalSpeed(speedOfLight)
+		sim.SetSolver(solver)
+		for i, pos := range []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}, {50, 0}} {
+			sensor := simulation.NewSensor(pos, 0, nil)
+			sensor.SetClock(simulation.Clock{Offset: anchorSpread * float64(i%3-1)})
+			_ = sim.AddObject(sensor)
+		}
+		tag := simulation.NewTargetWithMotion(common.Vector{30, 40}, nil)
+		tag.SetClock(simulation.Clock{Offset: 100e-9, Drift: 1e-9})
+		_ = sim.AddObject(tag)
+		sim.Step(1)
+		e, _ := sim.GetLastLocalizationError(tag.GetID())
+		return e
+	}
+	fmt.Printf("least squares: %.2f m\n", run(multilateration.SolveLeastSquares, 0))
+	fmt.Printf("clock bias estimated: %.2f m\n", run(multilateration.SolvePseudoranges, 0))
+	fmt.Printf("anchors 3 ns apart: %.2f m\n", run(multilateration.SolvePseudoranges, 3e-9))
+	// Output:
+	// least squares: 13.53 m
+	// clock bias estimated: 0.00 m
+	// anchors 3 ns apart: 0.33 m
+}
+
+// Sensors surveyed with a 2 m error mislead the solver even with exact ranges; a
+// calibration campaign with a tag at nine reference points recovers their positions.
+func ExampleSimulation_CalibrateSensors() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+	sim.SetSeeds(simulation.NewSeeds(7))
+	for _, pos := range []common.Vector{{0, 0}, {100, 0}, {0, 100}, {100, 100}, {50, 0}} {
+		sensor := simulation.NewSensor(pos, 0, simulation.GaussianNoise(0.05))
+		_ = sim.AddObject(sensor)
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{30, 40}, nil)
+	_ = sim.AddObject(target)
+	_ = sim.PerturbSensorPositions(2)
+
+	sim.Step(1)
+	e, _ := sim.GetLastLocalizationError(target.GetID())
+	fmt.Printf("before calibration: %.2f m\n", e)
+	var points []common.Vector
+	for _, x := range []float64{10, 50, 90} {
+		for _, y := range []float64{10, 50, 90} {
+			points = append(points, common.Vector{x, y})
+		}
+	}
+	report, _ := sim.CalibrateSensors(points, 10)
+	for _, r := range report {
+		fmt.Printf("%d ranges: %.2f -> %.2f m\n", r.Ranges, r.Before, r.After)
+	}
+	sim.Step(1)
+	e, _ = sim.GetLastLocalizationError(target.GetID())
+	fmt.Printf("after calibration: %.2f m\n", e)
+	// Output:
+	// before calibration: 3.00 m
+	// 90 ranges: 2.83 -> 0.02 m
+	// 90 ranges: 0.75 -> 0.00 m
+	// 90 ranges: 3.12 -> 0.04 m
+	// 90 ranges: 2.97 -> 0.02 m
+	// 90 ranges: 3.00 -> 0.02 m
+	// after calibration: 0.04 m
+}
+
+// The runner turns elapsed wall time into fixed steps: at 2x a 0.25 s frame owes
+// 0.5 s, five steps of 0.1 s; leftovers carry over to the next frame.
+func ExampleSimulationRunner() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, 100*time.Millisecond)
+	runner := simulation.NewSimulationRunner(sim)
+	runner.SetSpeed(2)
+	fmt.Println("steps:", runner.Advance(250*time.Millisecond))
+	fmt.Println("steps:", runner.Advance(30*time.Millisecond))
+	fmt.Println("steps:", runner.Advance(30*time.Millisecond))
+	runner.SetSpeed(1000)
+	fmt.Println("speed:", runner.Speed())
+	runner.SetPaused(true)
+	fmt.Println("paused steps:", runner.Advance(time.Second))
+	runner.RunTicks(10)
+	fmt.Printf("time: %.1f s\n", sim.GetCurrentTime())
+	// Output:
+	// steps: 5
+	// steps: 0
+	// steps: 1
+	// speed: 100
+	// paused steps: 0
+	// time: 1.6 s
+}
+
+// Let sensors age, drop out for a while and fail over two simulated hours.
+func ExampleSensor_SetFailureModel() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Minute)
+	sim.SetSeeds(simulation.NewSeeds(1))
+	var sensors []*simulation.Sensor
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		sensor := simulation.NewSensor(pos, 0, nil)
+		_ = sensor.SetFailureModel(simulation.FailureModel{NoiseGrowth: 0.5, OutageRate: 2, OutageDuration: 300})
+		_ = sim.AddObject(sensor)
+		sensors = append(sensors, sensor)
+	}
+	_ = sensors[3].SetFailureModel(simulation.FailureModel{FailureProbability: 0.9})
+	_ = sim.AddObject(simulation.NewTargetWithMotion(common.Vector{5, 5}, nil))
+
+	counts := make(map[simulation.EventKind]int)
+	sim.Events().Subscribe(func(e simulation.Event) { counts[e.Kind]++ },
+		simulation.EventSensorFailed, simulation.EventSensorOutage, simulation.EventSensorRestored)
+	for i := 0; i < 120; i++ {
+		sim.Step(60)
+	}
+	fmt.Println("failures:", counts[simulation.EventSensorFailed], "outages:", counts[simulation.EventSensorOutage],
+		"restored:", counts[simulation.EventSensorRestored])
+	for _, s := range sensors {
+		fmt.Printf("%s, aging sigma %.2f\n", s.Status(), s.DegradationSigma())
+	}
+	// Output:
+	// failures: 1 outages: 10 restored: 9
+	// operational, aging sigma 0.88
+	// operational, aging sigma 0.88
+	// outage, aging sigma 0.97
+	// failed, aging sigma 0.00
+}
+
+// Targets that hear too few sensors are localized through the ranges to their peers.
+func ExampleSimulation_SetPeerRanging() {
+	for _, peers := range []bool{false, true} {
+		sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second)
+		sim.SetSeeds(simulation.NewSeeds(3))
+		for i, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}} {
+			_ = sim.AddObject(simulation.NewSensorWithID(fmt.Sprint("anchor-", i), pos, 70, simulation.GaussianNoise(0.1)))
+		}
+		for i, pos := range []common.Vector{{-20, -20}, {10, -30}, {-30, 10}, {20, 20}} {
+			_ = sim.AddObject(simulation.NewTargetWithID(string(rune('a'+i)), pos, nil))
+		}
+		if peers {
+			_ = sim.SetPeerRanging(&simulation.PeerRanging{Sigma: 0.1})
+		}
+		report := sim.Step(1)
+		fmt.Println("peer ranging:", peers)
+		for _, t := range report.Targets {
+			locErr, _ := sim.GetLastLocalizationError(t.TargetID)
+			fmt.Printf("  %s: %d sensors, %v, within 1 unit %t\n", t.TargetID, t.NumMeasurements, t.Outcome, locErr >= 0 && locErr < 1)
+		}
+	}
+	// Output:
+	// peer ranging: false
+	//   a: 3 sensors, localized, within 1 unit true
+	//   b: 2 sensors, insufficient measurements, within 1 unit false
+	//   c: 2 sensors, insufficient measurements, within 1 unit false
+	//   d: 2 sensors, insufficient measurements, within 1 unit false
+	// peer ranging: true
+	//   a: 3 sensors, localized, within 1 unit true
+	//   b: 2 sensors, localized, within 1 unit true
+	//   c: 2 sensors, localized, within 1 unit true
+	//   d: 2 sensors, localized, within 1 unit true
+}
+
+// Compare the consensus of the sensors with the central solve over a lossy network.
+func ExampleSimulation_SetDistributedLocalization() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second)
+	sim.SetSeeds(simulation.NewSeeds(5))
+	for i, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensorWithID(fmt.Sprint("anchor-", i), pos, 0, simulation.GaussianNoise(0.1)))
+	}
+	_ = sim.AddObject(simulation.NewTargetWithID("beacon", common.Vector{10, -5}, nil))
+	config := simulation.DefaultDistributedLocalization()
+	config.Latency, config.Loss = 0.2, 0.1
+	_ = sim.SetDistributedLocalization(&config)
+
+	for range 10 {
+		sim.Step(1)
+	}
+	est, _ := sim.GetDistributedEstimate("beacon")
+	m := sim.Metrics()
+	fmt.Println("participants:", est.Participants, "converged:", est.Converged)
+	distributedErr, _ := m.Gauge(simulation.MetricDistributedError)
+	centralErr, _ := m.Gauge(simulation.MetricCentralizedError)
+	fmt.Printf("distributed error below 1: %t, central error below 1: %t\n", distributedErr < 1, centralErr < 1)
+	fmt.Println("rounds:", m.Counter(simulation.MetricDistributedRounds))
+	took, _ := m.Gauge(simulation.MetricDistributedConvergenceTime)
+	fmt.Printf("consensus reached after %.0fs\n", took)
+	// Output:
+	// participants: 4 converged: true
+	// distributed error below 1: true, central error below 1: true
+	// rounds: 100
+	// consensus reached after 4s
+}
+
+// A congested network decides which measurements reach the solver in time.
+func ExampleSimulation_SetNetwork() {
+	for _, bandwidth := range []float64{0, 2500} {
+		sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+		sim.SetSeeds(simulation.NewSeeds(2))
+		for i, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+			_ = sim.AddObject(simulation.NewSensorWithID(fmt.Sprint("anchor-", i), pos, 0, simulation.GaussianNoise(0.1)))
+		}
+		for i := range 5 {
+			_ = sim.AddObject(simulation.NewTargetWithID(fmt.Sprint("t", i), common.Vector{float64(5 * i), 0}, nil))
+		}
+		sim.SetMaxMeasurementAge(0.5)
+		_ = sim.SetNetwork(&network.Config{Bandwidth: bandwidth, Latency: 0.05, DropRate: 0.05, QueueLimit: 10})
+		for range 50 {
+			sim.Step(0.1)
+		}
+		stats, _ := sim.NetworkStats()
+		m := sim.Metrics()
+		fmt.Printf("bandwidth %v: %d reports, %d dropped, %.0f localized, %.0f without enough measurements\n",
+			bandwidth, stats.Sent, stats.Dropped, m.Counter(simulation.MetricSolverInvocations), m.Counter(simulation.MetricInsufficientMeasurements))
+	}
+	// Output:
+	// bandwidth 0: 1000 reports, 47 dropped, 245 localized, 5 without enough measurements
+	// bandwidth 2500: 1000 reports, 233 dropped, 201 localized, 49 without enough measurements
+}
+
+// The fusion center solves each target once a second, or earlier when three fresh
+// measurements have arrived over a jittery network; in between the estimate stands.
+func ExampleSimulation_SetFusionConfig() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	sim.SetSeeds(simulation.NewSeeds(4))
+	for i, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		sensor := simulation.NewSensorWithID(fmt.Sprint("anchor-", i), pos, 0, simulation.GaussianNoise(0.1))
+		_ = sensor.SetMeasurementInterval(5) // Every half second
+		_ = sim.AddObject(sensor)
+	}
+	_ = sim.AddObject(simulation.NewTargetWithID("beacon", common.Vector{5, 5}, nil))
+	_ = sim.SetNetwork(&network.Config{Latency: 0.05, Jitter: 0.8})
+	_ = sim.SetFusionConfig(simulation.FusionConfig{Interval: 1, MinFresh: 3})
+
+	outcomes := map[simulation.LocalizationOutcome]int{}
+	for range 50 {
+		report := sim.Step(0.1)
+		outcomes[report.Targets[0].Outcome]++
+	}
+	m := sim.Metrics()
+	fmt.Println("localized:", outcomes[simulation.OutcomeLocalized], "deferred:", outcomes[simulation.OutcomeDeferred])
+	fmt.Println("scheduled:", m.Counter(simulation.MetricFusionScheduled), "triggered:", m.Counter(simulation.MetricFusionTriggered))
+	fmt.Println("late reports:", m.Counter(simulation.MetricFusionLate))
+	// Output:
+	// localized: 6 deferred: 34
+	// scheduled: 2 triggered: 4
+	// late reports: 1
+}
+
+// Standard anchor layouts over the bounds, added as sensors in one call.
+func ExampleDeployGrid() {
+	bounds := []float64{0, 100, 0, 100}
+	grid, _ := simulation.DeployGrid(bounds, 3)
+	circle, _ := simulation.DeployCircle(bounds, 50, 4)
+	corners, _ := simulation.DeployVertices(bounds)
+	fmt.Println("grid:", len(grid), grid[0], grid[4], grid[8])
+	fmt.Printf("circle: %.0f %.0f\n", circle[0], circle[1])
+	fmt.Println("corners:", corners)
+
+	sim, _ := simulation.NewSimulation(2, bounds, time.Second)
+	ids, err := sim.AddSensors(grid, 0, nil)
+	fmt.Println("sensors:", len(ids), err)
+	// Output:
+	// grid: 9 [0.000, 0.000] [50.000, 50.000] [100.000, 100.000]
+	// circle: [100 50] [50 100]
+	// corners: [[0.000, 0.000] [100.000, 0.000] [0.000, 100.000] [100.000, 100.000]]
+	// sensors: 9 <nil>
+}
+
+// Parameters of all sensors and targets can be changed between steps, e.g. from a UI.
+func ExampleSimulation_SetSensorNoiseSigma() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+	positions, _ := simulation.DeployVertices(sim.GetBounds())
+	_, _ = sim.AddSensors(positions, 0, nil)
+	_ = sim.AddObject(simulation.NewTargetWithID("walker", common.Vector{50, 50}, simulation.NewRandomWalkMotion()))
+
+	_ = sim.SetSensorNoiseSigma(0.5)
+	_ = sim.SetSensorDetectionRadius(80)
+	changed, _ := sim.SetTargetMaxSpeed(5)
+	sensor := sim.GetSensors()[0]
+	fmt.Println("variance:", sensor.RangeVariance(), "radius:", sensor.DetectionRadius(), "targets:", changed)
+	fmt.Println(sim.SetSensorNoiseSigma(-1))
+	// Output:
+	// variance: 0.25 radius: 80 targets: 1
+	// out of range: noise sigma must be non-negative, got -1.000000
+}
+
+// The simulation keeps the last steps of every object, so lag or trails need no
+// buffering of their own.
+func ExampleSimulation_History() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+	positions, _ := simulation.DeployVertices(sim.GetBounds())
+	_, _ = sim.AddSensors(positions, 0, nil)
+	mover := simulation.NewTargetWithID("mover", common.Vector{10, 50}, simulation.NewConstantVelocityMotion())
+	_ = mover.SetVelocity(common.Vector{5, 0})
+	_ = sim.AddObject(mover)
+	_ = sim.SetHistoryLength(3)
+
+	for range 5 {
+		sim.Step(1)
+	}
+	for _, sample := range sim.History()["mover"] {
+		fmt.Printf("t=%.0f true %.0f estimate %.0f\n", sample.Time, sample.Position, sample.Estimate)
+	}
+	// Output:
+	// t=3 true [25 50] estimate [25 50]
+	// t=4 true [30 50] estimate [30 50]
+	// t=5 true [35 50] estimate [35 50]
+}
+
+// Estimates revised five steps later are more accurate than the real-time ones made at
+// the same instants.
+func ExampleSimulation_SetFixedLagSmoothing() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+	sim.SetSeeds(simulation.NewSeeds(2))
+	positions, _ := simulation.DeployVertices(sim.GetBounds())
+	_, _ = sim.AddSensors(positions, 0, simulation.GaussianNoise(2))
+	mover := simulation.NewTargetWithID("mover", common.Vector{10, 30}, simulation.NewConstantVelocityMotion())
+	_ = mover.SetVelocity(common.Vector{2, 1})
+	_ = sim.AddObject(mover)
+	smoothing := simulation.DefaultFixedLagSmoothing()
+	smoothing.ProcessSigma = 0.1
+	_ = sim.SetFixedLagSmoothing(&smoothing)
+
+	var realtime, smoothed float64
+	for range 30 {
+		sim.Step(1)
+		r, _ := sim.Metrics().Gauge(simulation.MetricRealtimeError)
+		s, _ := sim.Metrics().Gauge(simulation.MetricSmoothedError)
+		realtime += r
+		smoothed += s
+	}
+	track := sim.SmoothedTrack("mover")
+	last := track[len(track)-1]
+	fmt.Println("history:", sim.HistoryLength(), "smoothed samples:", len(track), "latest at t =", last.Time)
+	fmt.Printf("mean error: real-time %.2f, smoothed %.2f\n", realtime/25, smoothed/25) // The first 5 steps have no revision
+	// Output:
+	// history: 12 smoothed samples: 12 latest at t = 25
+	// mean error: real-time 2.08, smoothed 0.80
+}
+
+// A triangular room inside the 100 x 100 bounds: random targets are placed in it and a
+// target walking into its slanted wall bounces back inside.
+func ExampleSimulation_SetWorld() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+	sim.SetSeeds(simulation.NewSeeds(3))
+	room, _ := simulation.NewPolytopeRegion(
+		[]common.Vector{{0, -1}, {-1, 0}, {1, 1}}, // y >= 0, x >= 0, x + y <= 100
+		[]float64{0, 0, 100},
+	)
+	sim.SetWorld(room)
+	for range 20 {
+		_ = sim.AddRandomTarget()
+	}
+	walker := simulation.NewTargetWithID("walker", common.Vector{40, 40}, simulation.NewConstantVelocityMotion())
+	_ = walker.SetVelocity(common.Vector{10, 10})
+	_ = sim.AddObject(walker)
+
+	inside := true
+	for range 20 {
+		sim.Step(1)
+		for _, t := range sim.GetTargets() {
+			inside = inside && room.Contains(t.GetPosition())
+		}
+	}
+	fmt.Println("all targets inside:", inside)
+	fmt.Printf("walker velocity after its bounces: %.0f\n", walker.GetVelocity())
+	// Output:
+	// all targets inside: true
+	// walker velocity after its bounces: [-5 -5]
+}
+
+// The same target crossing the right edge under each boundary mode.
+func ExampleSimulation_SetBoundaryMode() {
+	for _, mode := range []simulation.BoundaryMode{simulation.BoundaryBounce, simulation.BoundaryWrap, simulation.BoundaryClamp, simulation.BoundaryAbsorb} {
+		sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+		_ = sim.SetBoundaryMode(mode)
+		target := simulation.NewTargetWithID("runner", common.Vector{90, 50}, simulation.NewConstantVelocityMotion())
+		_ = target.SetVelocity(common.Vector{20, 0})
+		_ = sim.AddObject(target)
+		sim.Step(1)
+		if _, ok := sim.GetObject("runner"); !ok {
+			fmt.Printf("%s: removed, absorbed %.0f\n", mode, sim.Metrics().Counter(simulation.MetricAbsorbed))
+			continue
+		}
+		fmt.Printf("%s: position %.0f velocity %.0f\n", mode, target.GetPosition(), target.GetVelocity())
+	}
+	// Output:
+	// bounce: position [90 50] velocity [-16 0]
+	// wrap: position [10 50] velocity [20 0]
+	// clamp: position [100 50] velocity [0 0]
+	// absorb: removed, absorbed 1
+}
+
+// A target flying east turns north, stops for a while and then speeds up.
+func ExampleSimulation_InjectManeuver() {
+	sim, _ := simulation.NewSimulation(2, []float64{-1000, 1000, -1000, 1000}, time.Second)
+	target := simulation.NewTargetWithID("plane", common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+	_ = sim.InjectManeuver("plane", simulation.Maneuver{Kind: simulation.ManeuverTurn, Start: 2, Duration: 2, Angle: math.Pi / 2})
+	_ = sim.InjectManeuver("plane", simulation.Maneuver{Kind: simulation.ManeuverStopAndGo, Start: 5, Duration: 2})
+	_ = sim.InjectManeuver("plane", simulation.Maneuver{Kind: simulation.ManeuverBurst, Start: 8, Duration: 2, Acceleration: 5})
+	for range 10 {
+		sim.Step(1)
+		fmt.Printf("t=%.0f position %.0f velocity %.0f\n", sim.GetCurrentTime(), target.GetPosition(), target.GetVelocity())
+	}
+	fmt.Println("maneuvers:", sim.Metrics().Counter(simulation.MetricManeuvers))
+	// Output:
+	// t=1 position [10 0] velocity [10 0]
+	// t=2 position [20 0] velocity [10 0]
+	// t=3 position [27 7] velocity [7 7]
+	// t=4 position [27 17] velocity [0 10]
+	// t=5 position [27 27] velocity [0 10]
+	// t=6 position [27 27] velocity [0 0]
+	// t=7 position [27 27] velocity [0 0]
+	// t=8 position [27 37] velocity [0 10]
+	// t=9 position [27 52] velocity [0 15]
+	// t=10 position [27 72] velocity [0 20]
+	// maneuvers: 3
+}
+
+// A target is confirmed after three fixes, coasts when it leaves the sensors' range and
+// is lost when it stays out of range.
+func ExampleSimulation_GetTrackStatus() {
+	sim, _ := simulation.NewSimulation(2, []float64{-200, 200, -200, 200}, time.Second)
+	for _, pos := range []common.Vector{{-50, -50}, {50, -50}, {-50, 50}, {50, 50}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 130, nil))
+	}
+	_ = sim.SetTrackQuality(&simulation.TrackQuality{ConfirmUpdates: 3, MaxMisses: 2, PositionSigma: 5, MaxInnovation: 4})
+	target := simulation.NewTargetWithID("runner", common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 0})
+	_ = sim.AddObject(target)
+	for range 11 {
+		sim.Step(1)
+		status, _ := sim.GetTrackStatus("runner")
+		fmt.Printf("x=%.0f: %s (quality %.2f)\n", target.GetPosition()[0], status.State, status.Quality)
+	}
+	fmt.Println("lost:", sim.Metrics().Counter(simulation.MetricTracksLost))
+	// Output:
+	// x=10: initializing (quality 0.20)
+	// x=20: initializing (quality 0.36)
+	// x=30: tracking (quality 0.49)
+	// x=40: tracking (quality 0.59)
+	// x=50: tracking (quality 0.67)
+	// x=60: tracking (quality 0.74)
+	// x=70: tracking (quality 0.79)
+	// x=80: coasting (quality 0.63)
+	// x=90: coasting (quality 0.51)
+	// x=100: lost (quality 0.40)
+	// x=110: lost (quality 0.32)
+	// lost: 1
+}
+
+// A sensor starts reporting ranges 40 units too long. Gating against the track
+// prediction drops them, so the estimate stays on the target.
+func ExampleSimulation_SetMeasurementGating() {
+	for _, gating := range []bool{false, true} {
+		sim, _ := simulation.NewSimulation(2, []float64{-200, 200, -200, 200}, time.Second)
+		var faulty *simulation.Sensor
+		for i, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}, {100, 100}, {0, 120}} {
+			sensor := simulation.NewSensor(pos, 0, nil)
+			if i == 0 {
+				faulty = sensor
+			}
+			_ = sim.AddObject(sensor)
+		}
+		if gating {
+			config := simulation.DefaultMeasurementGating()
+			_ = sim.SetMeasurementGating(&config)
+		}
+		target := simulation.NewTargetWithID("car", common.Vector{-50, 0}, simulation.NewConstantVelocityMotion())
+		_ = target.SetVelocity(common.Vector{10, 0})
+		_ = sim.AddObject(target)
+
+		var sum float64
+		for i := range 10 {
+			if i == 5 {
+				faulty.SetNoiseFunction(simulation.BiasNoise(40))
+			}
+			sim.Step(1)
+			if i >= 5 {
+				e, _ := sim.GetLastLocalizationError("car")
+				sum += e
+			}
+		}
+		fmt.Printf("gating %v: mean error %.1f, gated %.0f\n", gating, sum/5, sim.Metrics().Counter(simulation.MetricMeasurementsGated))
+	}
+	// Output:
+	// gating false: mean error 24.8, gated 0
+	// gating true: mean error 0.0, gated 5
+}
+
+// Without known sensor positions, the sensors and the target are mapped relative to each
+// other from the ranges between them alone.
+func ExampleSimulation_SetRelativePositioning() {
+	sim, _ := simulation.NewSimulation(2, []float64{0, 100, 0, 100}, time.Second)
+	sim.SetSeeds(simulation.NewSeeds(1))
+	for _, pos := range []common.Vector{{10, 10}, {50, 5}, {90, 10}, {10, 90}, {50, 95}, {90, 90}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 100, simulation.GaussianNoise(0.5)))
+	}
+	_ = sim.AddObject(simulation.NewTargetWithID("tag", common.Vector{40, 60}, nil))
+	config := simulation.DefaultRelativePositioning()
+	_ = sim.SetRelativePositioning(&config)
+	sim.Step(1)
+
+	m, _ := sim.LastRelativeMap()
+	fmt.Printf("nodes: %d, stress %.3f, RMSE %.1f\n", len(m.Relative), m.Stress, m.RMSE)
+	fmt.Printf("tag mapped to %.0f\n", m.Aligned["tag"])
+	// Output:
+	// nodes: 7, stress 0.004, RMSE 0.2
+	// tag mapped to [40 60]
+}
@@ -0,0 +1,134 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/metrics"
+)
+
+// Metric names of sensor failures. Per-sensor variants carry a sensor label.
+const (
+	MetricSensorFailures = "sensor_failures" // Permanent failures
+	MetricSensorOutages  = "sensor_outages"  // Temporary outages started
+)
+
+// failureStream is the noise stream base reserved for sensor failures; each sensor draws
+// from failureStream plus its stream number, so failures do not shift the range noise.
+const failureStream = 1 << 60
+
+// FailureModel describes how a sensor breaks down over simulation time. The zero
+// FailureModel never fails.
+type FailureModel struct {
+	FailureProbability float64 `json:"failure_probability,omitempty"` // Chance of a permanent failure per hour
+	NoiseGrowth        float64 `json:"noise_growth,omitempty"`        // Gaussian range sigma gained per hour of operation (aging)
+	OutageRate         float64 `json:"outage_rate,omitempty"`         // Temporary outages per hour
+	OutageDuration     float64 `json:"outage_duration,omitempty"`     // Mean outage length in seconds (exponentially distributed)
+}
+
+// Validate checks the parameters.
+func (m FailureModel) Validate() error {
+	switch {
+	case m.FailureProbability < 0 || m.FailureProbability > 1:
+		return fmt.Errorf("%w: failure probability must be within [0, 1], got %f", ErrOutOfRange, m.FailureProbability)
+	case m.NoiseGrowth < 0:
+		return fmt.Errorf("%w: noise growth must be non-negative, got %f", ErrOutOfRange, m.NoiseGrowth)
+	case m.OutageRate < 0:
+		return fmt.Errorf("%w: outage rate must be non-negative, got %f", ErrOutOfRange, m.OutageRate)
+	case m.OutageRate > 0 && m.OutageDuration <= 0:
+		return fmt.Errorf("%w: outage duration must be positive, got %f", ErrOutOfRange, m.OutageDuration)
+	}
+	return nil
+}
+
+// SensorStatus is the operating state of a sensor.
+type SensorStatus int
+
+const (
+	SensorOperational SensorStatus = iota
+	SensorOutage                   // Temporarily down, comes back by itself
+	SensorFailed                   // Permanently down
+)
+
+// String returns a short name of the status.
+func (st SensorStatus) String() string {
+	switch st {
+	case SensorOperational:
+		return "operational"
+	case SensorOutage:
+		return "outage"
+	case SensorFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("status(%d)", int(st))
+	}
+}
+
+// FailureModel returns the failure model of the sensor.
+func (s *Sensor) FailureModel() FailureModel {
+	return s.failure
+}
+
+// SetFailureModel sets how the sensor breaks down. Its state (failed, in an outage,
+// aged) is kept; Repair resets it.
+func (s *Sensor) SetFailureModel(m FailureModel) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	s.failure = m
+	return nil
+}
+
+// Status returns whether the sensor is operational, in an outage or failed. Sensors that
+// are down detect nothing.
+func (s *Sensor) Status() SensorStatus {
+	return s.status
+}
+
+// DegradationSigma returns the extra Gaussian range noise the sensor has gained by aging.
+func (s *Sensor) DegradationSigma() float64 {
+	return s.failure.NoiseGrowth * s.operatingTime / 3600
+}
+
+// Repair brings the sensor back into operation as new: it ends a failure or outage and
+// resets its age.
+func (s *Sensor) Repair() {
+	s.status = SensorOperational
+	s.outageLeft = 0
+	s.operatingTime = 0
+}
+
+// updateFailures ages the sensors by deltaTime and draws failures, outages and
+// recoveries, raising events for the changes. The caller holds the lock.
+func (s *Simulation) updateFailures(deltaTime float64) {
+	hours := deltaTime / 3600
+	for _, sen := range s.sortedSensors() {
+		m := sen.failure
+		if m == (FailureModel{}) || sen.status == SensorFailed {
+			continue
+		}
+		id := sen.GetID()
+		if sen.status == SensorOutage {
+			if sen.outageLeft -= deltaTime; sen.outageLeft > 0 {
+				continue
+			}
+			sen.status, sen.outageLeft = SensorOperational, 0
+			s.events.emit(Event{Kind: EventSensorRestored, Time: s.simulationTime, ObjectID: id, SensorID: id})
+		}
+		sen.operatingTime += deltaTime
+		// Per hour probabilities scaled to the step, so the step length does not matter
+		if m.FailureProbability > 0 && sen.failureRand.Float64() < 1-math.Pow(1-m.FailureProbability, hours) {
+			sen.status = SensorFailed
+			s.metrics.Inc(MetricSensorFailures)
+			s.metrics.Inc(metrics.Key(MetricSensorFailures, "sensor", id))
+			s.events.emit(Event{Kind: EventSensorFailed, Time: s.simulationTime, ObjectID: id, SensorID: id})
+			continue
+		}
+		if m.OutageRate > 0 && sen.failureRand.Float64() < 1-math.Exp(-m.OutageRate*hours) {
+			sen.status = SensorOutage
+			sen.outageLeft = sen.failureRand.ExpFloat64() * m.OutageDuration
+			s.metrics.Inc(MetricSensorOutages)
+			s.metrics.Inc(metrics.Key(MetricSensorOutages, "sensor", id))
+			s.events.emit(Event{Kind: EventSensorOutage, Time: s.simulationTime, ObjectID: id, SensorID: id})
+		}
+	}
+}
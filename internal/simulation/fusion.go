@@ -0,0 +1,164 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/multilateration"
+)
+
+// Metric names of the fusion center.
+const (
+	MetricFusionScheduled  = "fusion_scheduled"    // Solves started by the schedule
+	MetricFusionTriggered  = "fusion_triggered"    // Solves started early by fresh measurements
+	MetricFusionDeferred   = "fusion_deferred"     // Targets left unsolved in a step, keeping their previous estimate
+	MetricFusionLate       = "fusion_late"         // Reports taken before the newest measurement of the last solve of their target
+	MetricFusionOutOfOrder = "fusion_out_of_order" // Reports discarded because a newer one of their sensor was buffered
+)
+
+// FusionConfig decides when the fusion center solves a target. The zero FusionConfig
+// solves every target in every step.
+type FusionConfig struct {
+	// Interval is the time in seconds between the scheduled solves of a target; 0 has
+	// no schedule, so only MinFresh triggers solves (or every step, if that is 0 too).
+	Interval float64 `json:"interval,omitempty"`
+	// MinFresh is the number of measurements taken since the last solve of a target that
+	// triggers a solve before its schedule, 0 = only the schedule.
+	MinFresh int `json:"min_fresh,omitempty"`
+}
+
+// Validate checks the parameters.
+func (c FusionConfig) Validate() error {
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("%w: fusion interval must be non-negative, got %f", ErrOutOfRange, c.Interval)
+	case c.MinFresh < 0:
+		return fmt.Errorf("%w: fresh measurement count must be non-negative, got %d", ErrOutOfRange, c.MinFresh)
+	}
+	return nil
+}
+
+// fusionCenter collects the time-stamped measurement reports as they arrive, possibly
+// late and out of order, buffers the newest one per target and sensor and decides which
+// targets are solved in a step. The simulation lock guards it.
+type fusionCenter struct {
+	config    FusionConfig
+	latest    map[string]map[string]multilateration.Measurement // targetID -> sensorID -> newest delivered measurement
+	lastSolve map[string]float64                                // targetID -> time of the last solve
+	newest    map[string]float64                                // targetID -> newest measurement timestamp used by the last solve
+}
+
+func newFusionCenter() *fusionCenter {
+	return &fusionCenter{
+		latest:    make(map[string]map[string]multilateration.Measurement),
+		lastSolve: make(map[string]float64),
+		newest:    make(map[string]float64),
+	}
+}
+
+// receive buffers a delivered report. It returns false if the report was discarded
+// because a newer measurement of the same sensor and target is already buffered.
+func (f *fusionCenter) receive(p pendingMeasurement) bool {
+	latest, ok := f.latest[p.targetID]
+	if !ok {
+		latest = make(map[string]multilateration.Measurement)
+		f.latest[p.targetID] = latest
+	}
+	if prev, exists := latest[p.sensorID]; exists && prev.Timestamp > p.measurement.Timestamp {
+		return false
+	}
+	if p.inRange {
+		latest[p.sensorID] = p.measurement
+	} else {
+		delete(latest, p.sensorID) // The sensor lost the target
+	}
+	return true
+}
+
+// late reports whether a measurement was taken before the newest one used by the last
+// solve of its target, i.e. arrived too late for it.
+func (f *fusionCenter) late(targetID string, m multilateration.Measurement) bool {
+	newest, solved := f.newest[targetID]
+	return solved && m.Timestamp < newest
+}
+
+// fresh counts the measurements taken since the last solve of a target.
+func (f *fusionCenter) fresh(targetID string, measurements []multilateration.Measurement) int {
+	newest, solved := f.newest[targetID]
+	if !solved {
+		return len(measurements)
+	}
+	count := 0
+	for _, m := range measurements {
+		if m.Timestamp > newest {
+			count++
+		}
+	}
+	return count
+}
+
+// due decides whether a target is solved now. A target that was never solved always is.
+// It returns whether the solve is due and whether fresh measurements triggered it early.
+func (f *fusionCenter) due(targetID string, measurements []multilateration.Measurement, now float64) (due, triggered bool) {
+	c := f.config
+	if c == (FusionConfig{}) {
+		return true, false
+	}
+	last, solved := f.lastSolve[targetID]
+	if !solved || (c.Interval > 0 && now-last >= c.Interval-deliveryEpsilon) {
+		return true, false
+	}
+	if c.MinFresh > 0 && f.fresh(targetID, measurements) >= c.MinFresh {
+		return true, true
+	}
+	return false, false
+}
+
+// solved records a solve of a target from measurements.
+func (f *fusionCenter) solved(targetID string, measurements []multilateration.Measurement, now float64) {
+	f.lastSolve[targetID] = now
+	newest := f.newest[targetID]
+	for _, m := range measurements {
+		newest = max(newest, m.Timestamp)
+	}
+	f.newest[targetID] = newest
+}
+
+// forget drops everything about an object that left the simulation.
+func (f *fusionCenter) forget(id string) {
+	delete(f.latest, id)
+	for _, latest := range f.latest {
+		delete(latest, id)
+	}
+	delete(f.lastSolve, id)
+	delete(f.newest, id)
+}
+
+// recordFusionSolve tells the fusion center that a job was solved. The caller holds
+// the lock.
+func (s *Simulation) recordFusionSolve(job localizationJob) {
+	s.fusion.solved(job.target.GetID(), job.measurements, s.simulationTime)
+	switch {
+	case job.triggered:
+		s.metrics.Inc(MetricFusionTriggered)
+	case s.fusion.config != (FusionConfig{}):
+		s.metrics.Inc(MetricFusionScheduled)
+	}
+}
+
+// SetFusionConfig sets when the fusion center solves the targets. Targets that are not
+// due in a step get OutcomeDeferred and keep their previous estimate.
+func (s *Simulation) SetFusionConfig(config FusionConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fusion.config = config
+	return nil
+}
+
+// FusionConfig returns when the fusion center solves the targets.
+func (s *Simulation) FusionConfig() FusionConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fusion.config
+}
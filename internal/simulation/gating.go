@@ -0,0 +1,96 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/multilateration"
+)
+
+// MetricMeasurementsGated counts the measurements rejected by gating.
+const MetricMeasurementsGated = "measurements_gated"
+
+// MeasurementGating rejects, before solving, the ranges of a target that disagree with
+// the position its track predicts, so a single bad range does not pull the estimate
+// away. Only confirmed tracks gate (see GetTrackStatus).
+type MeasurementGating struct {
+	Threshold  float64 `json:"threshold"`   // Mahalanobis distance (standard deviations) beyond which a range is rejected
+	RangeSigma float64 `json:"range_sigma"` // Range noise of measurements without a variance
+}
+
+// DefaultMeasurementGating rejects ranges more than 4 standard deviations off.
+func DefaultMeasurementGating() MeasurementGating {
+	return MeasurementGating{Threshold: 4, RangeSigma: 2}
+}
+
+// Validate checks the parameters.
+func (c MeasurementGating) Validate() error {
+	if c.Threshold <= 0 || c.RangeSigma <= 0 {
+		return fmt.Errorf("%w: gating threshold and range sigma must be positive", ErrOutOfRange)
+	}
+	return nil
+}
+
+// SetMeasurementGating enables measurement gating, nil (the default) disables it. The
+// variance of a range residual is the range variance plus the squared
+// TrackQuality.PositionSigma of the prediction. If gating would leave too few ranges
+// to solve, none are rejected: the track may be wrong, e.g. after a maneuver. Gating is
+// skipped in time-of-arrival mode, whose ranges share a clock error.
+func (s *Simulation) SetMeasurementGating(config *MeasurementGating) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		c := *config
+		config = &c
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gating = config
+	return nil
+}
+
+// MeasurementGating returns the gating configuration, nil if it is off.
+func (s *Simulation) MeasurementGating() *MeasurementGating {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.gating == nil {
+		return nil
+	}
+	c := *s.gating
+	return &c
+}
+
+// gateMeasurements returns the measurements of a target consistent with its track and
+// the number rejected. The caller holds the lock.
+func (s *Simulation) gateMeasurements(targetID string, measurements []multilateration.Measurement) ([]multilateration.Measurement, int) {
+	t, ok := s.tracks[targetID]
+	if !ok || !t.confirmed || s.signalSpeed > 0 {
+		return measurements, 0
+	}
+	predictionVariance := s.trackQuality.PositionSigma * s.trackQuality.PositionSigma
+	kept := make([]multilateration.Measurement, 0, len(measurements))
+	for _, m := range measurements {
+		predicted, ok := t.predict(m.Timestamp)
+		if !ok {
+			return measurements, 0
+		}
+		expected, err := predicted.DistanceIn(m.SensorPosition, s.metric)
+		if err != nil {
+			kept = append(kept, m)
+			continue
+		}
+		variance := m.Variance
+		if variance <= 0 {
+			variance = s.gating.RangeSigma * s.gating.RangeSigma
+		}
+		if math.Abs(m.Distance-expected) <= s.gating.Threshold*math.Sqrt(variance+predictionVariance) {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) < s.dimension+1 {
+		return measurements, 0
+	}
+	rejected := len(measurements) - len(kept)
+	s.metrics.Add(MetricMeasurementsGated, float64(rejected))
+	return kept, rejected
+}
@@ -0,0 +1,70 @@
+package simulation_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+// cornerAnchors are sensor positions near the corners of the test bounds.
+var cornerAnchors = []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}}
+
+// newTestSimulation returns a 2D simulation over [-50, 50]² with the given tick and a
+// sensor of unlimited range and the given noise at each position, with IDs anchor-0,
+// anchor-1, ... in order.
+func newTestSimulation(t *testing.T, tick time.Duration, noise simulation.NoiseFunction, sensors ...common.Vector) *simulation.Simulation {
+	t.Helper()
+	sim, err := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, tick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pos := range sensors {
+		addObjects(t, sim, simulation.NewSensorWithID(fmt.Sprint("anchor-", i), pos, 0, noise))
+	}
+	return sim
+}
+
+// addObjects adds the objects to the simulation, failing the test on error.
+func addObjects(t *testing.T, sim *simulation.Simulation, objects ...simulation.SimulationObject) {
+	t.Helper()
+	for _, obj := range objects {
+		if err := sim.AddObject(obj); err != nil {
+			t.Fatalf("adding %s: %v", obj.GetID(), err)
+		}
+	}
+}
+
+// stepN steps the simulation n times by dt seconds.
+func stepN(sim *simulation.Simulation, n int, dt float64) {
+	for range n {
+		sim.Step(dt)
+	}
+}
+
+// randomScene steps 20 random targets among 8 random sensors ten times and returns the
+// true positions and the estimates of every step.
+func randomScene(t *testing.T, seeds simulation.Seeds, configure func(*simulation.Simulation)) (truth, estimates []string) {
+	t.Helper()
+	sim := newTestSimulation(t, time.Second, nil)
+	sim.SetSeeds(seeds)
+	configure(sim)
+	for range 8 {
+		if err := sim.AddRandomSensor(0, simulation.GaussianNoise(0.5)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 20 {
+		if err := sim.AddRandomTarget(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 10 {
+		for _, target := range sim.Step(1).State.Targets {
+			truth = append(truth, fmt.Sprintf("%.9f", target.Position))
+			estimates = append(estimates, fmt.Sprintf("%.9f", target.Estimate.Position))
+		}
+	}
+	return truth, estimates
+}
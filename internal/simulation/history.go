@@ -0,0 +1,104 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+)
+
+// HistorySample is the state of an object at the end of one step.
+type HistorySample struct {
+	Time     float64
+	Tick     int64
+	Position common.Vector // True position
+	Estimate common.Vector // Estimated position, nil for sensors and unlocalized targets
+	Error    float64       // Localization error, -1 if unavailable or a sensor
+}
+
+// History holds the recent samples of every object by ID, oldest first. An object that
+// joined or left during the retention has fewer samples than the others.
+type History map[string][]HistorySample
+
+// history keeps the states of the last steps in a ring. The step states are never
+// modified, so they are stored as they are.
+type history struct {
+	length int        // Steps retained, 0 = off
+	states []SimState // Ring of at most length states
+	start  int        // Index of the oldest state
+}
+
+// record appends the state of a step, dropping the oldest beyond the retention.
+func (h *history) record(state SimState) {
+	switch {
+	case h.length == 0:
+		return
+	case len(h.states) < h.length:
+		h.states = append(h.states, state)
+	default:
+		h.states[h.start] = state
+		h.start = (h.start + 1) % len(h.states)
+	}
+}
+
+// ordered returns the retained states oldest first.
+func (h *history) ordered() []SimState {
+	states := make([]SimState, 0, len(h.states))
+	states = append(states, h.states[h.start:]...)
+	return append(states, h.states[:h.start]...)
+}
+
+// resize changes the retention, keeping the newest states.
+func (h *history) resize(length int) {
+	states := h.ordered()
+	if len(states) > length {
+		states = states[len(states)-length:]
+	}
+	h.length, h.states, h.start = length, states, 0
+}
+
+// clear forgets the retained states, e.g. when the simulation time jumps.
+func (h *history) clear() {
+	h.states, h.start = nil, 0
+}
+
+// SetHistoryLength sets how many steps History retains, 0 (the default) turns it off.
+// Shrinking keeps the newest steps.
+func (s *Simulation) SetHistoryLength(ticks int) error {
+	if ticks < 0 {
+		return fmt.Errorf("%w: history length must be non-negative, got %d", ErrOutOfRange, ticks)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.resize(ticks)
+	return nil
+}
+
+// HistoryLength returns how many steps History retains.
+func (s *Simulation) HistoryLength() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.history.length
+}
+
+// History returns the true positions and estimates of every sensor and target over the
+// retained steps (see SetHistoryLength), e.g. for trails, lag analysis or smoothing. The
+// samples are copies. Restoring a checkpoint clears the history.
+func (s *Simulation) History() History {
+	s.mu.RLock()
+	states := s.history.ordered()
+	s.mu.RUnlock()
+
+	h := make(History)
+	for _, st := range states {
+		for _, sen := range st.Sensors {
+			h[sen.ID] = append(h[sen.ID], HistorySample{Time: st.Time, Tick: st.Tick, Position: sen.Position.Clone(), Error: -1})
+		}
+		for _, tar := range st.Targets {
+			sample := HistorySample{Time: st.Time, Tick: st.Tick, Position: tar.Position.Clone(), Error: tar.Error}
+			if tar.Estimate.Position != nil {
+				sample.Estimate = tar.Estimate.Position.Clone()
+			}
+			h[tar.ID] = append(h[tar.ID], sample)
+		}
+	}
+	return h
+}
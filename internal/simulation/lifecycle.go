@@ -0,0 +1,149 @@
+package simulation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// scheduledChange is an object that enters or leaves the simulation at a given time.
+type scheduledChange struct {
+	at      float64          // Simulation time
+	spawn   SimulationObject // Object to add, nil for a despawn
+	id      string           // Object to remove, or the ID of spawn
+	seq     int64            // Scheduling order, breaks ties between equal times
+	despawn bool
+}
+
+// ScheduleSpawn adds obj to the simulation in the first step that reaches simulation
+// time at (the next step if at has passed). It appears at its current position after
+// the other objects have moved, so it is measured there in that step, and
+// EventObjectAdded is emitted then.
+func (s *Simulation) ScheduleSpawn(obj SimulationObject, at float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if obj.GetPosition().Dimension() != s.dimension {
+		return fmt.Errorf("%w: object dimension %d does not match simulation dimension %d", ErrDimensionMismatch, obj.GetPosition().Dimension(), s.dimension)
+	}
+	id := obj.GetID()
+	if _, exists := s.objects[id]; exists {
+		return fmt.Errorf("%w: object with ID %s already exists", ErrDuplicateID, id)
+	}
+	if _, pending := s.pendingSpawn(id); pending {
+		return fmt.Errorf("%w: object with ID %s is already scheduled to spawn", ErrDuplicateID, id)
+	}
+	s.schedule(scheduledChange{at: at, spawn: obj, id: id})
+	return nil
+}
+
+// ScheduleDespawn removes the object in the first step that reaches simulation time at,
+// like RemoveObject: its estimates, tracker and measurements in flight go with it and
+// EventObjectRemoved is emitted. The object may still be waiting to spawn. A later call
+// replaces the previous despawn time.
+func (s *Simulation) ScheduleDespawn(id string, at float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.objects[id]
+	if _, pending := s.pendingSpawn(id); !exists && !pending {
+		return fmt.Errorf("%w: object with ID %s does not exist", ErrNotFound, id)
+	}
+	s.unschedule(id, false, true)
+	s.schedule(scheduledChange{at: at, id: id, despawn: true})
+	return nil
+}
+
+// PendingSpawns returns the objects scheduled to spawn that have not appeared yet, in
+// the order they will.
+func (s *Simulation) PendingSpawns() []SimulationObject {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var objects []SimulationObject
+	for _, c := range s.scheduled {
+		if !c.despawn {
+			objects = append(objects, c.spawn)
+		}
+	}
+	return objects
+}
+
+// SpawnTime returns when a pending object will spawn; false if it is not waiting to.
+func (s *Simulation) SpawnTime(id string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.pendingSpawn(id)
+	return c.at, ok
+}
+
+// DespawnTime returns when an object is scheduled to be removed; false if it is not.
+func (s *Simulation) DespawnTime(id string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.scheduled {
+		if c.despawn && c.id == id {
+			return c.at, true
+		}
+	}
+	return 0, false
+}
+
+// schedule inserts a change, keeping the schedule ordered by time and scheduling order.
+func (s *Simulation) schedule(c scheduledChange) {
+	c.seq = s.nextScheduleSeq
+	s.nextScheduleSeq++
+	s.scheduled = append(s.scheduled, c)
+	sort.SliceStable(s.scheduled, func(i, j int) bool {
+		if s.scheduled[i].at != s.scheduled[j].at {
+			return s.scheduled[i].at < s.scheduled[j].at
+		}
+		return s.scheduled[i].seq < s.scheduled[j].seq
+	})
+}
+
+// pendingSpawn finds the scheduled spawn of an object.
+func (s *Simulation) pendingSpawn(id string) (scheduledChange, bool) {
+	for _, c := range s.scheduled {
+		if !c.despawn && c.id == id {
+			return c, true
+		}
+	}
+	return scheduledChange{}, false
+}
+
+// unschedule drops the scheduled spawn and/or despawn of an object.
+func (s *Simulation) unschedule(id string, spawn, despawn bool) {
+	kept := s.scheduled[:0]
+	for _, c := range s.scheduled {
+		if c.id != id || (c.despawn && !despawn) || (!c.despawn && !spawn) {
+			kept = append(kept, c)
+		}
+	}
+	s.scheduled = kept
+}
+
+// applySchedule performs the spawns and despawns that are due at the current time.
+// A failing spawn (e.g. an ID added meanwhile) is dropped and logged.
+func (s *Simulation) applySchedule() {
+	due := 0
+	for due < len(s.scheduled) && s.scheduled[due].at <= s.simulationTime+deliveryEpsilon {
+		due++
+	}
+	if due == 0 {
+		return
+	}
+	changes := append([]scheduledChange(nil), s.scheduled[:due]...)
+	s.scheduled = append(s.scheduled[:0], s.scheduled[due:]...)
+	for _, c := range changes {
+		var err error
+		if c.despawn {
+			if _, exists := s.objects[c.id]; exists {
+				err = s.removeObject(c.id)
+			} else if _, pending := s.pendingSpawn(c.id); pending {
+				s.unschedule(c.id, true, false) // Leaves before it ever appeared
+			}
+		} else {
+			err = s.addObject(c.spawn)
+		}
+		if err != nil {
+			s.log().Warn("scheduled change failed", "object", c.id, "tick", s.tick, "err", err)
+		}
+	}
+}
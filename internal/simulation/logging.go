@@ -0,0 +1,37 @@
+package simulation
+
+import "log/slog"
+
+// SetLogger sets the structured logger of the simulation and its objects; its
+// handler decides the format and verbosity. nil means slog.Default(), looked up
+// on every record so that a later slog.SetDefault still applies.
+func (s *Simulation) SetLogger(logger *slog.Logger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logger = logger
+	for _, sen := range s.sensors {
+		sen.logger = logger
+	}
+	for _, tar := range s.targets {
+		tar.logger = logger
+	}
+}
+
+// Logger returns the logger the simulation writes to.
+func (s *Simulation) Logger() *slog.Logger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log()
+}
+
+// log returns the logger without locking.
+func (s *Simulation) log() *slog.Logger {
+	return loggerOrDefault(s.logger)
+}
+
+func loggerOrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
@@ -0,0 +1,183 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// MetricManeuvers counts the maneuvers that have started.
+const MetricManeuvers = "maneuvers_started"
+
+// ManeuverKind is the type of a maneuver injected into a target.
+type ManeuverKind int
+
+const (
+	ManeuverTurn      ManeuverKind = iota // Rotate the velocity by Angle over Duration
+	ManeuverBurst                         // Accelerate along the velocity by Acceleration for Duration
+	ManeuverStopAndGo                     // Stand still for Duration, then resume the previous velocity
+)
+
+// String returns the name ParseManeuverKind accepts.
+func (k ManeuverKind) String() string {
+	switch k {
+	case ManeuverTurn:
+		return "turn"
+	case ManeuverBurst:
+		return "burst"
+	case ManeuverStopAndGo:
+		return "stop"
+	default:
+		return fmt.Sprintf("maneuver(%d)", int(k))
+	}
+}
+
+// ParseManeuverKind returns the kind named "turn", "burst" or "stop".
+func ParseManeuverKind(name string) (ManeuverKind, error) {
+	for k := ManeuverTurn; k <= ManeuverStopAndGo; k++ {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown maneuver %q, expected turn, burst or stop", name)
+}
+
+// Maneuver is a sudden change of a target's motion, injected on top of its motion model
+// so trackers can be tested against motion they do not expect. Turns and bursts change
+// the velocity, so they act on models that carry it from step to step (constant
+// velocity, random walk, Ornstein-Uhlenbeck); a stop holds any target in place.
+type Maneuver struct {
+	Kind         ManeuverKind
+	Start        float64 // Simulation time it begins
+	Duration     float64 // Seconds; 0 turns at once, bursts and stops need a duration
+	Angle        float64 // Turn: radians, counterclockwise in the plane of axes 0 and 1
+	Acceleration float64 // Burst: units/s^2 along the velocity, negative brakes
+}
+
+// Validate checks the parameters.
+func (m Maneuver) Validate() error {
+	switch {
+	case m.Kind < ManeuverTurn || m.Kind > ManeuverStopAndGo:
+		return fmt.Errorf("%w: unknown maneuver kind %d", ErrOutOfRange, int(m.Kind))
+	case m.Duration < 0 || math.IsNaN(m.Duration):
+		return fmt.Errorf("%w: maneuver duration must be non-negative, got %f", ErrOutOfRange, m.Duration)
+	case m.Kind != ManeuverTurn && m.Duration == 0:
+		return fmt.Errorf("%w: a %s needs a positive duration", ErrOutOfRange, m.Kind)
+	}
+	return nil
+}
+
+// end is the simulation time the maneuver is over.
+func (m Maneuver) end() float64 {
+	return m.Start + m.Duration
+}
+
+// activeManeuver is a maneuver waiting for or during its time.
+type activeManeuver struct {
+	targetID string
+	maneuver Maneuver
+	started  bool
+	held     common.Vector // Stop: the position the target stands at
+	resume   common.Vector // Stop: the velocity it had
+}
+
+// InjectManeuver makes a target maneuver from m.Start on, beginning with the step after
+// it (the next step if it has passed). A target can have several maneuvers, also at the
+// same time; they are dropped if it is removed, and are not part of checkpoints.
+// EventManeuver is emitted when one starts.
+func (s *Simulation) InjectManeuver(targetID string, m Maneuver) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.targets[targetID]; !ok {
+		return fmt.Errorf("%w: target with ID %s does not exist", ErrNotFound, targetID)
+	}
+	if m.Kind == ManeuverTurn && s.dimension < 2 {
+		return fmt.Errorf("%w: a turn needs at least 2 dimensions, the simulation has %d", ErrDimensionMismatch, s.dimension)
+	}
+	s.maneuvers = append(s.maneuvers, &activeManeuver{targetID: targetID, maneuver: m})
+	return nil
+}
+
+// Maneuvers returns the maneuvers of a target that have not finished, in the order they
+// were injected. A maneuver injected in the past starts at its first step.
+func (s *Simulation) Maneuvers(targetID string) []Maneuver {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var maneuvers []Maneuver
+	for _, a := range s.maneuvers {
+		if a.targetID == targetID {
+			maneuvers = append(maneuvers, a.maneuver)
+		}
+	}
+	return maneuvers
+}
+
+// steerManeuvers changes the velocities of the maneuvering targets for the step from
+// from to to, before they move, and drops the finished maneuvers. The caller holds the
+// lock.
+func (s *Simulation) steerManeuvers(from, to float64) {
+	kept := s.maneuvers[:0]
+	for _, a := range s.maneuvers {
+		tar, ok := s.targets[a.targetID]
+		if !ok {
+			continue // Removed
+		}
+		m := &a.maneuver
+		if m.Start >= to-deliveryEpsilon {
+			kept = append(kept, a)
+			continue
+		}
+		if !a.started {
+			a.started = true
+			m.Start = math.Max(m.Start, from)
+			s.metrics.Inc(MetricManeuvers)
+			s.events.emit(Event{Kind: EventManeuver, Time: s.simulationTime, ObjectID: a.targetID})
+			if m.Kind == ManeuverStopAndGo {
+				a.held, a.resume = tar.GetPosition(), tar.GetVelocity()
+			}
+		}
+		share := 1.0 // Of the maneuver falling into this step
+		if m.Duration > 0 {
+			share = math.Max(math.Min(to, m.end())-math.Max(from, m.Start), 0) / m.Duration
+		}
+		vel := tar.GetVelocity()
+		going := to < m.end()-deliveryEpsilon // The maneuver continues after this step
+		switch m.Kind {
+		case ManeuverTurn:
+			sin, cos := math.Sincos(m.Angle * share)
+			vel[0], vel[1] = cos*vel[0]-sin*vel[1], sin*vel[0]+cos*vel[1]
+		case ManeuverBurst:
+			if speed := vel.Norm(); speed > 0 {
+				vel = vel.MultiplyByScalar(math.Max(speed+m.Acceleration*m.Duration*share, 0) / speed)
+			}
+		case ManeuverStopAndGo:
+			// Stands through the step ending at the end, goes in the one after
+			going = to <= m.end()+deliveryEpsilon
+			if going {
+				vel = common.NewVector(len(vel))
+			} else {
+				vel = a.resume
+			}
+		}
+		_ = tar.SetVelocity(vel)
+		if going {
+			kept = append(kept, a)
+		}
+	}
+	s.maneuvers = kept
+}
+
+// holdManeuvers puts the stopped targets back where they stand after they moved. The
+// caller holds the lock.
+func (s *Simulation) holdManeuvers() {
+	for _, a := range s.maneuvers {
+		tar, ok := s.targets[a.targetID]
+		if ok && a.started && a.maneuver.Kind == ManeuverStopAndGo {
+			_ = tar.SetPosition(a.held)
+			_ = tar.SetVelocity(common.NewVector(len(a.held)))
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package simulation
+
+import (
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/multilateration"
+	"sort"
+)
+
+// Metric names maintained by the measurement pipeline. Per-sensor variants carry a
+// sensor label, see metrics.Key.
+const (
+	MetricMeasurementsAttempted = "measurements_attempted" // In-range measurements taken
+	MetricMeasurementsDropped   = "measurements_dropped"   // Measurements lost to dropout
+)
+
+// deliveryEpsilon absorbs floating point drift when comparing delivery times.
+const deliveryEpsilon = 1e-9
+
+// pendingMeasurement is a measurement that was taken but has not reached the solver yet.
+type pendingMeasurement struct {
+	targetID    string
+	sensorID    string
+	deliverAt   float64 // Simulation time at which the measurement becomes available
+	inRange     bool    // false means "target not detected", which clears the previous range
+	measurement multilateration.Measurement
+	clutter     *clutterBatch // Non-nil for the false alarms of a sensor, which have no target
+}
+
+// sensorReading is one sensor's raw result for one target before delivery.
+type sensorReading struct {
+	target  *Target
+	dist    float64
+	inRange bool
+	blinks  []float64 // Blink times of a scheduled target, nil for continuous transmitters
+
+	rangeRate    float64
+	hasRangeRate bool
+	occluded     bool // Blocked by an obstacle, reported as not detected
+	nlos         bool // Lengthened by an obstacle
+}
+
+// collectMeasurements lets every sensor whose measurement interval is due measure every
+// target that transmitted during this step. Results are queued with the sensor latency
+// instead of being used immediately. emissions comes from advanceEmissions.
+// The sensors measure in parallel, each with its own random stream, and their readings
+// are queued in insertion order.
+func (s *Simulation) collectMeasurements(report *StepReport, emissions map[string][]float64) {
+	targets := s.sortedTargets()
+	var due []*Sensor
+	for _, sen := range s.sortedSensors() {
+		if s.tick%int64(sen.MeasurementInterval()) == 0 {
+			due = append(due, sen)
+		}
+	}
+	readings := make([][]sensorReading, len(due))
+	sensorErrors := make([][]SensorError, len(due))
+	parallelFor(len(due), s.workers(), func(i int) {
+		readings[i], sensorErrors[i] = s.readSensor(due[i], targets, emissions)
+	})
+	for i, sen := range due {
+		s.generateClutter(sen)
+		report.SensorErrors = append(report.SensorErrors, sensorErrors[i]...)
+		s.queueReadings(sen, readings[i])
+	}
+}
+
+// readSensor lets one sensor measure every target that transmitted during this step. It
+// only draws from the sensor's own random stream, so sensors can read concurrently.
+func (s *Simulation) readSensor(sen *Sensor, targets []*Target, emissions map[string][]float64) ([]sensorReading, []SensorError) {
+	readings := make([]sensorReading, 0, len(targets))
+	var sensorErrors []SensorError
+	if sen.Status() != SensorOperational { // Detects nothing, which clears its ranges
+		for _, tar := range targets {
+			readings = append(readings, sensorReading{target: tar})
+		}
+		return readings, nil
+	}
+	degradation := sen.DegradationSigma()
+	for _, tar := range targets {
+		targetID := tar.GetID()
+		blinks, scheduled := emissions[targetID]
+		if scheduled && len(blinks) == 0 {
+			continue // The tag was silent during this step
+		}
+		dist, inRange, err := sen.MeasureDistance(tar)
+		if err != nil {
+			// Skip this measurement, the caller decides how to react
+			sensorErrors = append(sensorErrors, SensorError{SensorID: sen.GetID(), TargetID: targetID, Err: err})
+			continue
+		}
+		if inRange && degradation > 0 {
+			dist += sen.noiseRand.NormFloat64() * degradation
+		}
+		if inRange && tar.rangeNoise != nil {
+			dist = tar.rangeNoise(dist, sen.noiseRand.Rand)
+		}
+		reading := sensorReading{target: tar, blinks: blinks}
+		if inRange && len(s.obstacles) > 0 {
+			clear, bias := s.lineOfSight(sen.GetPosition(), tar.GetPosition())
+			switch {
+			case !clear:
+				reading.occluded = true
+				inRange = false // Detected as missing, like a target out of range
+			case bias > 0:
+				reading.nlos = true
+				dist += bias
+			}
+		}
+		if inRange && s.signalSpeed > 0 {
+			dist += s.clockBias(sen, tar)
+		}
+		reading.dist, reading.inRange = dist, inRange
+		if inRange && sen.MeasuresRangeRate() {
+			if reading.rangeRate, err = sen.MeasureRangeRate(tar); err == nil {
+				reading.hasRangeRate = true
+			}
+		}
+		readings = append(readings, reading)
+	}
+	return readings, sensorErrors
+}
+
+// queueReadings counts the readings of a sensor, applies collisions and dropouts and
+// queues the rest with the sensor latency (and over the network, if any).
+func (s *Simulation) queueReadings(sen *Sensor, readings []sensorReading) {
+	for i, reading := range readings {
+		targetID := reading.target.GetID()
+		switch {
+		case reading.occluded:
+			s.metrics.Inc(MetricMeasurementsOccluded)
+			s.metrics.Inc(metrics.Key(MetricMeasurementsOccluded, "sensor", sen.GetID()))
+		case reading.nlos:
+			s.metrics.Inc(MetricMeasurementsNLOS)
+			s.metrics.Inc(metrics.Key(MetricMeasurementsNLOS, "sensor", sen.GetID()))
+		}
+		s.updateRange(sen.GetID(), targetID, reading.inRange)
+		if reading.inRange {
+			s.metrics.Inc(MetricMeasurementsAttempted)
+			s.metrics.Inc(metrics.Key(MetricMeasurementsAttempted, "sensor", sen.GetID()))
+			if s.collidedAt(readings, i) {
+				s.metrics.Inc(MetricEmissionCollisions)
+				s.metrics.Inc(metrics.Key(MetricEmissionCollisions, "sensor", sen.GetID()))
+				continue
+			}
+			if p := sen.DropoutProbability(); p > 0 && sen.noiseRand.Float64() < p {
+				// Lost packet: the solver keeps whatever it had from this sensor before
+				s.metrics.Inc(MetricMeasurementsDropped)
+				s.metrics.Inc(metrics.Key(MetricMeasurementsDropped, "sensor", sen.GetID()))
+				continue
+			}
+		}
+		s.enqueue(pendingMeasurement{
+			targetID:  targetID,
+			sensorID:  sen.GetID(),
+			deliverAt: s.simulationTime + sen.Latency(),
+			inRange:   reading.inRange,
+			measurement: multilateration.Measurement{
+				SensorID:       sen.GetID(),
+				SensorPosition: sen.KnownPosition(), // Believed position at measurement time, not at delivery
+				Distance:       reading.dist,
+				Timestamp:      s.simulationTime,
+				Variance:       sen.RangeVariance(),
+				RangeRate:      reading.rangeRate,
+				HasRangeRate:   reading.hasRangeRate,
+				SensorVelocity: sensorVelocity(sen),
+			},
+		})
+	}
+}
+
+// sensorVelocity returns the velocity of a mobile sensor, nil for static ones.
+func sensorVelocity(sen *Sensor) common.Vector {
+	if sen.MotionModel() == nil {
+		return nil
+	}
+	return sen.GetVelocity()
+}
+
+// collidedAt reports whether the blinks of readings[i] were all lost at this sensor
+// because they overlapped with blinks of other in-range scheduled targets.
+func (s *Simulation) collidedAt(readings []sensorReading, i int) bool {
+	own := readings[i]
+	if own.blinks == nil {
+		return false
+	}
+	schedule, _ := own.target.EmissionSchedule()
+	others := make([][]float64, 0, len(readings))
+	for j, other := range readings {
+		if j != i && other.inRange && other.blinks != nil {
+			others = append(others, other.blinks)
+		}
+	}
+	return collides(own.blinks, others, schedule.Duration)
+}
+
+// deliverMeasurements moves every pending measurement whose latency has elapsed into
+// the per-target set of latest measurements, and the reports that arrived over the
+// network. Older measurements never replace newer ones.
+func (s *Simulation) deliverMeasurements() {
+	remaining := s.pending[:0]
+	for _, p := range s.pending {
+		if p.deliverAt > s.simulationTime+deliveryEpsilon {
+			remaining = append(remaining, p)
+			continue
+		}
+		s.deliver(p)
+	}
+	s.pending = remaining
+	if s.network != nil {
+		s.receiveReports()
+	}
+}
+
+// deliver hands a measurement that reached the solver to the fusion center.
+func (s *Simulation) deliver(p pendingMeasurement) {
+	if p.clutter != nil {
+		s.deliverClutter(p)
+		return
+	}
+	if p.inRange && s.fusion.late(p.targetID, p.measurement) {
+		s.metrics.Inc(MetricFusionLate)
+	}
+	if !s.fusion.receive(p) {
+		s.metrics.Inc(MetricFusionOutOfOrder) // Out-of-order arrival, the newer one is kept
+	}
+}
+
+// availableMeasurements returns the delivered measurements of a target that are
+// not older than the maximum measurement age, in sensor insertion order.
+func (s *Simulation) availableMeasurements(targetID string) []multilateration.Measurement {
+	latest := s.fusion.latest[targetID]
+	measurements := make([]multilateration.Measurement, 0, len(latest))
+	for _, m := range latest {
+		measurements = append(measurements, m)
+	}
+	sort.Slice(measurements, func(i, j int) bool { return s.order[measurements[i].SensorID] < s.order[measurements[j].SensorID] })
+	if s.maxMeasurementAge > 0 {
+		measurements = multilateration.DiscardStale(measurements, s.simulationTime, s.maxMeasurementAge)
+	}
+	return measurements
+}
+
+// SetMaxMeasurementAge sets how old (in seconds of simulation time) a measurement may be
+// and still be used for localization. 0 disables the limit.
+func (s *Simulation) SetMaxMeasurementAge(maxAge float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	s.maxMeasurementAge = maxAge
+}
+
+// GetLatestMeasurements returns the measurements of a target currently available to the solver.
+func (s *Simulation) GetLatestMeasurements(targetID string) []multilateration.Measurement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.availableMeasurements(targetID)
+}
+
+// DropoutRate returns the observed fraction of lost measurements of a sensor
+// (or of all sensors if sensorID is empty), 0 if nothing was measured yet.
+func (s *Simulation) DropoutRate(sensorID string) float64 {
+	attempted, dropped := MetricMeasurementsAttempted, MetricMeasurementsDropped
+	if sensorID != "" {
+		attempted = metrics.Key(attempted, "sensor", sensorID)
+		dropped = metrics.Key(dropped, "sensor", sensorID)
+	}
+	total := s.metrics.Counter(attempted)
+	if total == 0 {
+		return 0
+	}
+	return s.metrics.Counter(dropped) / total
+}
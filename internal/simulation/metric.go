@@ -0,0 +1,30 @@
+package simulation
+
+import "multilateration-sim/internal/common"
+
+// SetMetric sets the distance in which sensors measure ranges, their detection radius
+// applies and localization errors are scored; nil means Euclidean. The solver is not
+// changed: pair a non-Euclidean metric with one that fits it, e.g.
+// multilateration.RobustSolver with RobustOptions.Metric. Doppler range-rates still
+// assume straight lines of sight.
+func (s *Simulation) SetMetric(metric common.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metric = metric
+	for _, sen := range s.sensors {
+		sen.metric = metric
+	}
+}
+
+// Metric returns the distance the simulation measures in.
+func (s *Simulation) Metric() common.Metric {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return common.MetricOrDefault(s.metric)
+}
+
+// localizationError is the distance between the true and the estimated position in
+// the simulation's metric.
+func (s *Simulation) localizationError(truePosition, estimate common.Vector) (float64, error) {
+	return truePosition.DistanceIn(estimate, s.metric)
+}
@@ -0,0 +1,275 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/common"
+)
+
+// MotionModel defines how a moving object advances its state each simulation step.
+// Boundary handling is done by the owning object after Step.
+type MotionModel interface {
+	// Step advances the state by deltaTime seconds and returns the new position and velocity.
+	// Random draws must come from rng, the owning object's motion stream (see Seeds).
+	Step(position, velocity common.Vector, deltaTime float64, rng *rand.Rand) (common.Vector, common.Vector)
+}
+
+// bounceOffBounds reflects the position back into bounds in place and reverses
+// (with damping) the velocity components that hit a wall.
+func bounceOffBounds(pos, vel common.Vector, bounds []float64) {
+	for i := 0; i < pos.Dimension() && i*2+1 < len(bounds); i++ {
+		minBound := bounds[i*2]
+		maxBound := bounds[i*2+1]
+		if pos[i] < minBound {
+			pos[i] = minBound + (minBound - pos[i]) // Reflect position
+			vel[i] *= -0.8                          // Reverse and dampen velocity component
+		} else if pos[i] > maxBound {
+			pos[i] = maxBound - (pos[i] - maxBound) // Reflect position
+			vel[i] *= -0.8                          // Reverse and dampen velocity component
+		}
+	}
+}
+
+// RandomWalkMotion randomly perturbs the velocity each step, with a speed limit.
+// This is the default motion model of targets.
+type RandomWalkMotion struct {
+	AccelerationScale float64 // How much velocity can change per second
+	MaxSpeed          float64 // Maximum units per second, 0 means unlimited
+}
+
+// NewRandomWalkMotion creates a random walk model with the default parameters.
+func NewRandomWalkMotion() *RandomWalkMotion {
+	return &RandomWalkMotion{AccelerationScale: 50.0, MaxSpeed: 300.0}
+}
+
+// Step implements MotionModel.
+func (m *RandomWalkMotion) Step(position, velocity common.Vector, deltaTime float64, rng *rand.Rand) (common.Vector, common.Vector) {
+	vel := velocity.Clone()
+	// Adjust velocity slightly randomly
+	for i := range vel {
+		vel[i] += (rng.Float64()*2 - 1) * m.AccelerationScale * deltaTime
+	}
+
+	// --- Limit Velocity ---
+	if m.MaxSpeed > 0 {
+		if speed := vel.Norm(); speed > m.MaxSpeed {
+			vel.ScaleInPlace(m.MaxSpeed / speed)
+		}
+	}
+
+	newPos := position.Clone()
+	if err := newPos.AddScaledInPlace(vel, deltaTime); err != nil {
+		return position, velocity // Dimensions mismatch, keep the old state (shouldn't happen)
+	}
+	return newPos, vel
+}
+
+// WaypointMotion moves along a list of N-D waypoints at constant speed.
+type WaypointMotion struct {
+	waypoints []common.Vector
+	speed     float64 // Units per second
+	loop      bool    // Start over from the first waypoint after reaching the last one
+	next      int     // Index of the waypoint currently being approached
+}
+
+// NewWaypointMotion creates a waypoint-following model. All waypoints must share a dimension.
+func NewWaypointMotion(waypoints []common.Vector, speed float64, loop bool) (*WaypointMotion, error) {
+	if len(waypoints) == 0 {
+		return nil, fmt.Errorf("at least one waypoint is required")
+	}
+	if speed <= 0 {
+		return nil, fmt.Errorf("%w: speed must be positive, got %f", ErrOutOfRange, speed)
+	}
+	dim := waypoints[0].Dimension()
+	cloned := make([]common.Vector, len(waypoints))
+	for i, wp := range waypoints {
+		if wp.Dimension() != dim {
+			return nil, fmt.Errorf("%w: waypoint %d has dimension %d, expected %d", ErrDimensionMismatch, i, wp.Dimension(), dim)
+		}
+		cloned[i] = wp.Clone()
+	}
+	return &WaypointMotion{waypoints: cloned, speed: speed, loop: loop}, nil
+}
+
+// Waypoints returns a copy of the path.
+func (m *WaypointMotion) Waypoints() []common.Vector {
+	wps := make([]common.Vector, len(m.waypoints))
+	for i, wp := range m.waypoints {
+		wps[i] = wp.Clone()
+	}
+	return wps
+}
+
+// Speed returns the travel speed in units per second.
+func (m *WaypointMotion) Speed() float64 {
+	return m.speed
+}
+
+// Loop reports whether the path restarts after the last waypoint.
+func (m *WaypointMotion) Loop() bool {
+	return m.loop
+}
+
+// Done reports whether a non-looping path has been completed.
+func (m *WaypointMotion) Done() bool {
+	return !m.loop && m.next >= len(m.waypoints)
+}
+
+// Step implements MotionModel. Several waypoints may be passed within one step.
+func (m *WaypointMotion) Step(position, velocity common.Vector, deltaTime float64, rng *rand.Rand) (common.Vector, common.Vector) {
+	pos := position.Clone()
+	vel := common.NewVector(position.Dimension())
+	remaining := m.speed * deltaTime // Distance that can still be travelled this step
+	stalled := 0                     // Consecutive waypoints reached without moving
+
+	for remaining > 0 && !m.Done() && stalled <= len(m.waypoints) {
+		if m.next >= len(m.waypoints) { // Looping: wrap around
+			m.next = 0
+		}
+		toTarget, err := m.waypoints[m.next].Subtract(pos)
+		if err != nil {
+			return position, velocity
+		}
+		dist := toTarget.Norm()
+		if dist <= remaining {
+			pos = m.waypoints[m.next].Clone()
+			remaining -= dist
+			m.next++
+			if dist > 0 {
+				vel = toTarget.MultiplyByScalar(m.speed / dist)
+				stalled = 0
+			} else {
+				stalled++ // Guards against looping forever over coincident waypoints
+			}
+			continue
+		}
+		vel = toTarget.MultiplyByScalar(m.speed / dist)
+		_ = pos.AddScaledInPlace(toTarget, remaining/dist)
+		remaining = 0
+	}
+	if m.Done() {
+		vel = common.NewVector(position.Dimension()) // Parked at the final waypoint
+	}
+	return pos, vel
+}
+
+// ConstantVelocityMotion keeps the current velocity unchanged.
+// Set the initial velocity with Target.SetVelocity.
+type ConstantVelocityMotion struct{}
+
+// NewConstantVelocityMotion creates a constant-velocity model.
+func NewConstantVelocityMotion() *ConstantVelocityMotion {
+	return &ConstantVelocityMotion{}
+}
+
+// Step implements MotionModel.
+func (m *ConstantVelocityMotion) Step(position, velocity common.Vector, deltaTime float64, rng *rand.Rand) (common.Vector, common.Vector) {
+	newPos := position.Clone()
+	if err := newPos.AddScaledInPlace(velocity, deltaTime); err != nil {
+		return position, velocity
+	}
+	return newPos, velocity.Clone()
+}
+
+// CircularMotion orbits a center point in the plane spanned by two axes.
+// Coordinates along the remaining axes are left unchanged.
+type CircularMotion struct {
+	center       common.Vector
+	radius       float64
+	angularSpeed float64 // Radians per second, negative for clockwise
+	axisU, axisV int     // Axes spanning the orbital plane
+	angle        float64 // Current phase
+	started      bool    // Phase is derived from the position on the first step
+}
+
+// NewCircularMotion creates an orbital model around center in the (axisU, axisV) plane.
+func NewCircularMotion(center common.Vector, radius, angularSpeed float64, axisU, axisV int) (*CircularMotion, error) {
+	dim := center.Dimension()
+	if axisU < 0 || axisU >= dim || axisV < 0 || axisV >= dim || axisU == axisV {
+		return nil, fmt.Errorf("%w: invalid orbital plane axes (%d, %d) for dimension %d", ErrOutOfRange, axisU, axisV, dim)
+	}
+	if radius <= 0 {
+		return nil, fmt.Errorf("%w: radius must be positive, got %f", ErrOutOfRange, radius)
+	}
+	return &CircularMotion{center: center.Clone(), radius: radius, angularSpeed: angularSpeed, axisU: axisU, axisV: axisV}, nil
+}
+
+// Center returns the orbit center.
+func (m *CircularMotion) Center() common.Vector {
+	return m.center.Clone()
+}
+
+// Radius returns the orbit radius.
+func (m *CircularMotion) Radius() float64 {
+	return m.radius
+}
+
+// AngularSpeed returns the angular speed in radians per second.
+func (m *CircularMotion) AngularSpeed() float64 {
+	return m.angularSpeed
+}
+
+// Axes returns the axes spanning the orbital plane.
+func (m *CircularMotion) Axes() (int, int) {
+	return m.axisU, m.axisV
+}
+
+// Step implements MotionModel. The object snaps onto the orbit on the first step.
+func (m *CircularMotion) Step(position, velocity common.Vector, deltaTime float64, rng *rand.Rand) (common.Vector, common.Vector) {
+	if position.Dimension() != m.center.Dimension() {
+		return position, velocity
+	}
+	if !m.started {
+		m.angle = math.Atan2(position[m.axisV]-m.center[m.axisV], position[m.axisU]-m.center[m.axisU])
+		m.started = true
+	}
+	m.angle += m.angularSpeed * deltaTime
+
+	pos := position.Clone()
+	pos[m.axisU] = m.center[m.axisU] + m.radius*math.Cos(m.angle)
+	pos[m.axisV] = m.center[m.axisV] + m.radius*math.Sin(m.angle)
+
+	vel := common.NewVector(position.Dimension())
+	vel[m.axisU] = -m.radius * m.angularSpeed * math.Sin(m.angle)
+	vel[m.axisV] = m.radius * m.angularSpeed * math.Cos(m.angle)
+	return pos, vel
+}
+
+// OrnsteinUhlenbeckMotion is a mean-reverting random walk on the velocity:
+// dv = theta * (mean - v) dt + sigma dW. Unlike the plain random walk it has
+// a stationary speed distribution, so targets neither stall nor run away.
+type OrnsteinUhlenbeckMotion struct {
+	Theta        float64       // Mean reversion rate, 1/s
+	Sigma        float64       // Velocity diffusion, units/s per sqrt(s)
+	MeanVelocity common.Vector // Long-term mean velocity, nil means zero
+}
+
+// NewOrnsteinUhlenbeckMotion creates an OU model reverting to zero mean velocity.
+func NewOrnsteinUhlenbeckMotion(theta, sigma float64) *OrnsteinUhlenbeckMotion {
+	return &OrnsteinUhlenbeckMotion{Theta: theta, Sigma: sigma}
+}
+
+// Step implements MotionModel using the exact discretization of the OU process.
+func (m *OrnsteinUhlenbeckMotion) Step(position, velocity common.Vector, deltaTime float64, rng *rand.Rand) (common.Vector, common.Vector) {
+	decay := math.Exp(-m.Theta * deltaTime)
+	stdDev := m.Sigma * math.Sqrt(deltaTime) // Limit for theta -> 0
+	if m.Theta > 0 {
+		stdDev = m.Sigma * math.Sqrt((1-decay*decay)/(2*m.Theta))
+	}
+
+	vel := common.NewVector(velocity.Dimension())
+	for i := range vel {
+		mean := 0.0
+		if i < len(m.MeanVelocity) {
+			mean = m.MeanVelocity[i]
+		}
+		vel[i] = mean + (velocity[i]-mean)*decay + stdDev*rng.NormFloat64()
+	}
+
+	newPos := position.Clone()
+	if err := newPos.AddScaledInPlace(vel, deltaTime); err != nil {
+		return position, velocity
+	}
+	return newPos, vel
+}
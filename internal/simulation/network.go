@@ -0,0 +1,118 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/network"
+)
+
+// Metric names of the simulated network between the sensors and the solver.
+const (
+	MetricNetworkSent     = "network_sent"      // Measurement reports sent; per sensor with a sensor label
+	MetricNetworkDropped  = "network_dropped"   // Reports lost on a link or to a full queue; per sensor with a sensor label
+	MetricNetworkDelay    = "network_delay"     // Gauge: mean seconds from measurement to arrival of the reports delivered in the last step
+	MetricNetworkInFlight = "network_in_flight" // Gauge: reports on their way
+)
+
+// networkStream is the noise stream number reserved for network jitter and drops.
+const networkStream = 1 << 57
+
+// SetNetwork routes the measurement reports of the sensors to the solver over a
+// simulated network with the given link parameters; nil delivers every report after the
+// sensor latency alone. Reports leave a sensor after its latency and then queue on its
+// uplink, so congestion, jitter and drops decide which measurements reach the solver in
+// time. Reports in flight are lost when the network is replaced.
+func (s *Simulation) SetNetwork(config *network.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config == nil {
+		s.network = nil
+		return nil
+	}
+	n, err := network.New(*config, s.networkRand.Rand)
+	if err != nil {
+		return err
+	}
+	s.network = n
+	return nil
+}
+
+// NetworkConfig returns the default link of the network, nil if there is none.
+func (s *Simulation) NetworkConfig() *network.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.network == nil {
+		return nil
+	}
+	c := s.network.Config()
+	return &c
+}
+
+// SetNetworkLink gives one sensor its own uplink, e.g. a slower radio.
+func (s *Simulation) SetNetworkLink(sensorID string, config network.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.network == nil {
+		return fmt.Errorf("no network configured")
+	}
+	if _, ok := s.sensors[sensorID]; !ok {
+		return fmt.Errorf("%w: sensor with ID %s does not exist", ErrNotFound, sensorID)
+	}
+	return s.network.SetLink(sensorID, config)
+}
+
+// NetworkStats returns the traffic counters of the network, false if there is none.
+func (s *Simulation) NetworkStats() (network.Stats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.network == nil {
+		return network.Stats{}, false
+	}
+	return s.network.Stats(), true
+}
+
+// reportSize is the size in bytes of a measurement report: IDs, range, timestamp,
+// variance and range-rate, and the sensor position.
+func (s *Simulation) reportSize() int {
+	return 48 + 8*s.dimension
+}
+
+// enqueue queues a measurement report for delivery: directly with its latency, or over
+// the network. The caller holds the lock.
+func (s *Simulation) enqueue(p pendingMeasurement) {
+	if s.network == nil {
+		s.pending = append(s.pending, p)
+		return
+	}
+	size := s.reportSize()
+	if p.clutter != nil {
+		size += 8 * len(p.clutter.ranges)
+	}
+	s.metrics.Inc(MetricNetworkSent)
+	s.metrics.Inc(metrics.Key(MetricNetworkSent, "sensor", p.sensorID))
+	msg := network.Message{From: p.sensorID, To: network.FusionCenter, Size: size, Sent: p.deliverAt, Payload: p}
+	if _, ok := s.network.Send(msg); !ok {
+		s.metrics.Inc(MetricNetworkDropped)
+		s.metrics.Inc(metrics.Key(MetricNetworkDropped, "sensor", p.sensorID))
+	}
+}
+
+// receiveReports delivers the reports that arrived over the network by now. The caller
+// holds the lock.
+func (s *Simulation) receiveReports() {
+	delay, count := 0.0, 0
+	for _, msg := range s.network.Deliver(s.simulationTime + deliveryEpsilon) {
+		p := msg.Payload.(pendingMeasurement)
+		taken := p.measurement.Timestamp
+		if p.clutter != nil {
+			taken = p.clutter.taken
+		}
+		delay += s.simulationTime - taken
+		count++
+		s.deliver(p)
+	}
+	if count > 0 {
+		s.metrics.Set(MetricNetworkDelay, delay/float64(count))
+	}
+	s.metrics.Set(MetricNetworkInFlight, float64(s.network.InFlight()))
+}
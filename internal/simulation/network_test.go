@@ -0,0 +1,38 @@
+package simulation_test
+
+import (
+	"errors"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/network"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+func TestSetNetworkInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config network.Config
+	}{
+		{"negative bandwidth", network.Config{Bandwidth: -1}},
+		{"negative latency", network.Config{Latency: -0.1}},
+		{"negative jitter", network.Config{Jitter: -0.1}},
+		{"drop rate above 1", network.Config{DropRate: 2}},
+		{"negative queue limit", network.Config{QueueLimit: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := newTestSimulation(t, time.Second, nil, common.Vector{0, 0})
+			config := tt.config
+			if err := sim.SetNetwork(&config); !errors.Is(err, simulation.ErrOutOfRange) {
+				t.Errorf("SetNetwork: got %v, want ErrOutOfRange", err)
+			}
+			if err := sim.SetNetwork(&network.Config{}); err != nil {
+				t.Fatalf("SetNetwork with a perfect link: %v", err)
+			}
+			if err := sim.SetNetworkLink("anchor-0", tt.config); !errors.Is(err, simulation.ErrOutOfRange) {
+				t.Errorf("SetNetworkLink: got %v, want ErrOutOfRange", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Metric names maintained by the occlusion model.
+const (
+	MetricMeasurementsOccluded = "measurements_occluded" // In-range measurements lost behind an obstacle
+	MetricMeasurementsNLOS     = "measurements_nlos"     // Measurements lengthened by an obstacle (non-line-of-sight)
+)
+
+// occluder is a region that can intersect the line of sight between two points.
+type occluder interface {
+	Region
+	// chord returns the length of the part of segment a-b inside the region.
+	chord(a, b common.Vector) float64
+	// centeredAt returns a copy of the region moved to a new center.
+	centeredAt(center common.Vector) (occluder, error)
+	// midpoint returns the center of the region.
+	midpoint() common.Vector
+}
+
+// Obstacle is a static object, an axis-aligned box or a ball (hypersphere), that blocks
+// the line of sight between sensors and targets. A sensor whose ray to a target passes
+// through it misses the measurement, or, with an NLOS bias, measures a range that is
+// too long, like a signal reflected around or slowed down by a wall.
+type Obstacle struct {
+	id       string
+	shape    occluder
+	nlosBias float64 // Extra range of measurements through the obstacle, 0 blocks them
+}
+
+// NewObstacle creates an obstacle of the given shape, a *BoxRegion or a *BallRegion.
+func NewObstacle(shape Region) (*Obstacle, error) {
+	occ, ok := shape.(occluder)
+	if !ok {
+		return nil, fmt.Errorf("obstacle shape must be a box or a ball, got %T", shape)
+	}
+	return &Obstacle{id: fmt.Sprintf("obstacle-%s", uuid.NewString()[:8]), shape: occ}, nil
+}
+
+// GetID implements SimulationObject.
+func (o *Obstacle) GetID() string {
+	return o.id
+}
+
+// GetPosition implements SimulationObject: the center of the obstacle.
+func (o *Obstacle) GetPosition() common.Vector {
+	return o.shape.midpoint()
+}
+
+// SetPosition implements SimulationObject: moves the obstacle to a new center.
+func (o *Obstacle) SetPosition(pos common.Vector) error {
+	moved, err := o.shape.centeredAt(pos)
+	if err != nil {
+		return fmt.Errorf("move obstacle %s: %w", o.id, err)
+	}
+	o.shape = moved
+	return nil
+}
+
+// Update implements SimulationObject. Obstacles do not move on their own.
+func (o *Obstacle) Update(float64, []float64) {}
+
+// Shape returns the region the obstacle occupies, a *BoxRegion or a *BallRegion.
+func (o *Obstacle) Shape() Region {
+	return o.shape
+}
+
+// NLOSBias returns the extra range of measurements through the obstacle, 0 if it
+// blocks them.
+func (o *Obstacle) NLOSBias() float64 {
+	return o.nlosBias
+}
+
+// SetNLOSBias makes measurements through the obstacle arrive lengthened by bias
+// (units) instead of being lost. 0 (the default) blocks them again.
+func (o *Obstacle) SetNLOSBias(bias float64) {
+	o.nlosBias = math.Max(bias, 0)
+}
+
+// String returns a short description of the obstacle.
+func (o *Obstacle) String() string {
+	switch shape := o.shape.(type) {
+	case *BoxRegion:
+		return fmt.Sprintf("Obstacle %s: box %v - %v", o.id, shape.min, shape.max)
+	case *BallRegion:
+		return fmt.Sprintf("Obstacle %s: ball %v, radius %g", o.id, shape.center, shape.radius)
+	default:
+		return fmt.Sprintf("Obstacle %s", o.id)
+	}
+}
+
+// GetObstacles returns all obstacles in the order they were added.
+func (s *Simulation) GetObstacles() []*Obstacle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedObstacles()
+}
+
+// sortedObstacles returns obstacles in insertion order.
+func (s *Simulation) sortedObstacles() []*Obstacle {
+	obstacles := make([]*Obstacle, 0, len(s.obstacles))
+	for _, obs := range s.obstacles {
+		obstacles = append(obstacles, obs)
+	}
+	sort.Slice(obstacles, func(i, j int) bool { return s.order[obstacles[i].GetID()] < s.order[obstacles[j].GetID()] })
+	return obstacles
+}
+
+// lineOfSight checks the segment between a sensor and a target against all obstacles.
+// It returns false if a blocking obstacle is in the way, otherwise the summed NLOS
+// bias of the obstacles crossed.
+func (s *Simulation) lineOfSight(from, to common.Vector) (clear bool, bias float64) {
+	for _, obs := range s.sortedObstacles() {
+		if obs.shape.chord(from, to) <= 0 {
+			continue
+		}
+		if obs.nlosBias == 0 {
+			return false, 0
+		}
+		bias += obs.nlosBias
+	}
+	return true, bias
+}
+
+// chord implements occluder with the slab method.
+func (b *BoxRegion) chord(p, q common.Vector) float64 {
+	if p.Dimension() != b.min.Dimension() || q.Dimension() != b.min.Dimension() {
+		return 0
+	}
+	enter, exit := 0.0, 1.0 // Segment parameters p + t(q-p)
+	for i := range p {
+		d := q[i] - p[i]
+		if d == 0 {
+			if p[i] < b.min[i] || p[i] > b.max[i] {
+				return 0
+			}
+			continue
+		}
+		t1, t2 := (b.min[i]-p[i])/d, (b.max[i]-p[i])/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		enter, exit = math.Max(enter, t1), math.Min(exit, t2)
+		if enter >= exit {
+			return 0 // Missed, or only grazing an edge
+		}
+	}
+	length, _ := p.Distance(q)
+	return (exit - enter) * length
+}
+
+// centeredAt implements occluder.
+func (b *BoxRegion) centeredAt(center common.Vector) (occluder, error) {
+	if center.Dimension() != b.min.Dimension() {
+		return nil, fmt.Errorf("%w: center has dimension %d, the box %d", ErrDimensionMismatch, center.Dimension(), b.min.Dimension())
+	}
+	moved := &BoxRegion{min: b.min.Clone(), max: b.max.Clone()}
+	for i := range center {
+		offset := center[i] - (b.min[i]+b.max[i])/2
+		moved.min[i] += offset
+		moved.max[i] += offset
+	}
+	return moved, nil
+}
+
+// midpoint implements occluder.
+func (b *BoxRegion) midpoint() common.Vector {
+	c := common.NewVector(b.min.Dimension())
+	for i := range c {
+		c[i] = (b.min[i] + b.max[i]) / 2
+	}
+	return c
+}
+
+// chord implements occluder by intersecting the segment's line with the sphere.
+func (b *BallRegion) chord(p, q common.Vector) float64 {
+	d, err := q.Subtract(p)
+	if err != nil {
+		return 0
+	}
+	f, err := p.Subtract(b.center)
+	if err != nil {
+		return 0
+	}
+	a := d.NormSq()
+	if a == 0 {
+		return 0
+	}
+	half := 0.0 // Half of the linear coefficient, f·d
+	for i := range d {
+		half += f[i] * d[i]
+	}
+	disc := half*half - a*(f.NormSq()-b.radius*b.radius)
+	if disc <= 0 {
+		return 0 // Missed, or tangent
+	}
+	root := math.Sqrt(disc)
+	enter, exit := math.Max((-half-root)/a, 0), math.Min((-half+root)/a, 1)
+	if enter >= exit {
+		return 0
+	}
+	return (exit - enter) * math.Sqrt(a)
+}
+
+// centeredAt implements occluder.
+func (b *BallRegion) centeredAt(center common.Vector) (occluder, error) {
+	if center.Dimension() != b.center.Dimension() {
+		return nil, fmt.Errorf("%w: center has dimension %d, the ball %d", ErrDimensionMismatch, center.Dimension(), b.center.Dimension())
+	}
+	return &BallRegion{center: center.Clone(), radius: b.radius}, nil
+}
+
+// midpoint implements occluder.
+func (b *BallRegion) midpoint() common.Vector {
+	return b.Center()
+}
@@ -0,0 +1,119 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/multilateration"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SetConcurrency sets how many goroutines take measurements (one sensor each) and solve
+// positions (one target each) during a step. 0, the default, uses runtime.GOMAXPROCS;
+// 1 runs serially. Results do not depend on it: every sensor draws from its own random
+// stream and the results are merged in insertion order. With more than one worker the
+// solver and the noise functions must be safe for concurrent use, which all of those in
+// this repository are.
+func (s *Simulation) SetConcurrency(workers int) error {
+	if workers < 0 {
+		return fmt.Errorf("%w: concurrency must be non-negative, got %d", ErrOutOfRange, workers)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.concurrency = workers
+	return nil
+}
+
+// Concurrency returns the number of goroutines a step uses.
+func (s *Simulation) Concurrency() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.workers()
+}
+
+// workers resolves the configured concurrency.
+func (s *Simulation) workers() int {
+	if s.concurrency > 0 {
+		return s.concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelFor calls fn for every index in [0, n) on up to workers goroutines and returns
+// when all calls are done. fn must only write state owned by its index.
+func parallelFor(n, workers int, fn func(i int)) {
+	parallelForWorkers(n, workers, func(_, i int) { fn(i) })
+}
+
+// parallelForWorkers is parallelFor that also passes the number of the goroutine, in
+// [0, workers), so fn can use state owned by it, e.g. a workspace.
+func parallelForWorkers(n, workers int, fn func(worker, i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(0, i)
+		}
+		return
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				fn(w, i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// solverForWorkers returns the position solve of a step on up to workers goroutines. The
+// default solver runs on a multilateration.Solver per worker, whose workspaces are kept
+// across steps, so solving only allocates its results. The caller holds the lock.
+func (s *Simulation) solverForWorkers(workers int) func(worker int, measurements []multilateration.Measurement) (multilateration.Solution, error) {
+	solver, dimension := s.solver, s.dimension
+	plain := func(_ int, measurements []multilateration.Measurement) (multilateration.Solution, error) {
+		return solver(measurements, dimension)
+	}
+	if s.customSolver {
+		return plain
+	}
+	for len(s.workerSolvers) < workers {
+		ws, err := multilateration.NewSolver(max(len(s.sensors), dimension+1), dimension)
+		if err != nil {
+			return plain // 0-D simulation
+		}
+		s.workerSolvers = append(s.workerSolvers, ws)
+	}
+	solvers := s.workerSolvers
+	return func(worker int, measurements []multilateration.Measurement) (multilateration.Solution, error) {
+		var solution multilateration.Solution
+		if err := solvers[worker].SolveInto(&solution, measurements); err != nil {
+			return multilateration.Solution{}, err
+		}
+		return solution, nil
+	}
+}
+
+// localizationJob is the solve of one target in a step, prepared in order, run in
+// parallel and merged in order.
+type localizationJob struct {
+	target       *Target
+	measurements []multilateration.Measurement
+	outside      bool // Outside the region of interest, not solved
+	solve        bool // Enough measurements to call the solver
+	deferred     bool // Enough measurements, but the fusion center does not solve the target now
+	triggered    bool // Solved before its schedule because of fresh measurements
+	gated        int  // Measurements rejected by gating
+
+	solution multilateration.Solution
+	err      error
+
+	all         []multilateration.Measurement // Before sensor selection, nil if nothing was left out
+	allSolution multilateration.Solution      // Solve with all, for the selection metrics
+	allErr      error
+}
@@ -0,0 +1,34 @@
+package simulation_test
+
+import (
+	"errors"
+	"fmt"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+func TestSetConcurrencyMatchesSerial(t *testing.T) {
+	_, serial := randomScene(t, simulation.NewSeeds(3), func(sim *simulation.Simulation) { _ = sim.SetConcurrency(1) })
+	for _, workers := range []int{0, 2, 4, 16} {
+		t.Run(fmt.Sprint("workers=", workers), func(t *testing.T) {
+			_, got := randomScene(t, simulation.NewSeeds(3), func(sim *simulation.Simulation) {
+				if err := sim.SetConcurrency(workers); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if len(got) != len(serial) {
+				t.Fatalf("%d estimates, want %d", len(got), len(serial))
+			}
+			for i := range got {
+				if got[i] != serial[i] {
+					t.Fatalf("estimate %d: %s, serial %s", i, got[i], serial[i])
+				}
+			}
+		})
+	}
+	sim := newTestSimulation(t, time.Second, nil)
+	if err := sim.SetConcurrency(-1); !errors.Is(err, simulation.ErrOutOfRange) {
+		t.Errorf("concurrency -1: got %v, want ErrOutOfRange", err)
+	}
+}
@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+)
+
+// Region is a part of the simulation space, e.g. the area a system monitors.
+type Region interface {
+	// Contains reports whether a point lies inside the region.
+	Contains(pos common.Vector) bool
+}
+
+// BoxRegion is an axis-aligned box.
+type BoxRegion struct {
+	min, max common.Vector
+}
+
+// NewBoxRegion creates a box spanning min to max on every axis.
+func NewBoxRegion(min, max common.Vector) (*BoxRegion, error) {
+	if min.Dimension() != max.Dimension() {
+		return nil, fmt.Errorf("%w: box corners must have the same dimension: %d != %d", ErrDimensionMismatch, min.Dimension(), max.Dimension())
+	}
+	for i := range min {
+		if min[i] > max[i] {
+			return nil, fmt.Errorf("box minimum exceeds maximum on axis %d: %g > %g", i, min[i], max[i])
+		}
+	}
+	return &BoxRegion{min: min.Clone(), max: max.Clone()}, nil
+}
+
+// Min returns the lower corner.
+func (b *BoxRegion) Min() common.Vector {
+	return b.min.Clone()
+}
+
+// Max returns the upper corner.
+func (b *BoxRegion) Max() common.Vector {
+	return b.max.Clone()
+}
+
+// Contains implements Region. Points of another dimension are outside.
+func (b *BoxRegion) Contains(pos common.Vector) bool {
+	if pos.Dimension() != b.min.Dimension() {
+		return false
+	}
+	for i := range pos {
+		if pos[i] < b.min[i] || pos[i] > b.max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BallRegion is a ball (a disc in 2D) around a center point.
+type BallRegion struct {
+	center common.Vector
+	radius float64
+}
+
+// NewBallRegion creates a ball of the given radius.
+func NewBallRegion(center common.Vector, radius float64) (*BallRegion, error) {
+	if radius <= 0 {
+		return nil, fmt.Errorf("%w: radius must be positive, got %f", ErrOutOfRange, radius)
+	}
+	return &BallRegion{center: center.Clone(), radius: radius}, nil
+}
+
+// Center returns the center of the ball.
+func (b *BallRegion) Center() common.Vector {
+	return b.center.Clone()
+}
+
+// Radius returns the radius of the ball.
+func (b *BallRegion) Radius() float64 {
+	return b.radius
+}
+
+// Contains implements Region. Points of another dimension are outside.
+func (b *BallRegion) Contains(pos common.Vector) bool {
+	d, err := pos.Distance(b.center)
+	return err == nil && d <= b.radius
+}
+
+// SetRegionOfInterest restricts solving and tracking to targets inside the region, as
+// in systems that only care about a monitored area. Targets outside are still moved
+// and measured, but get OutcomeOutsideRegion, no estimate and no tracker; their track
+// is initiated afresh when they enter. Membership is decided by the true position,
+// standing in for a cheap presence detector. nil (the default) solves everywhere.
+func (s *Simulation) SetRegionOfInterest(region Region) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regionOfInterest = region
+}
+
+// RegionOfInterest returns the region set with SetRegionOfInterest, nil if none.
+func (s *Simulation) RegionOfInterest() Region {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.regionOfInterest
+}
+
+// outsideRegion reports whether a target is skipped by the region of interest.
+func (s *Simulation) outsideRegion(tar *Target) bool {
+	return s.regionOfInterest != nil && !s.regionOfInterest.Contains(tar.GetPosition())
+}
@@ -0,0 +1,185 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// Metric names of anchor-free relative positioning.
+const (
+	MetricRelativeMaps     = "relative_maps"     // Relative maps built
+	MetricRelativeFailures = "relative_failures" // Steps whose ranges did not connect all nodes
+	MetricRelativeRMSE     = "relative_rmse"     // Gauge: RMS error of the last map aligned with the true positions
+	MetricRelativeStress   = "relative_stress"   // Gauge: stress of the last map against its ranges
+)
+
+// relativeStream is the noise stream number reserved for the ranges between nodes.
+const relativeStream = 1 << 56
+
+// RelativePositioning configures the anchor-free mode: no sensor knows its position.
+// Every step each operational sensor ranges, with its own noise and detection radius,
+// to the other sensors and to the targets, and MDS-MAP (see multilateration.SolveMDSMAP)
+// builds a relative map of all of them. Since the map has no absolute frame, it is
+// aligned with the true positions by Procrustes analysis to score it. The regular
+// solve with known sensor positions still runs, so both can be compared. Ranges are
+// treated as Euclidean.
+type RelativePositioning struct {
+	Iterations int `json:"iterations"` // SMACOF refinement iterations, 0 = classical MDS only
+}
+
+// DefaultRelativePositioning refines the classical map with up to 100 SMACOF iterations.
+func DefaultRelativePositioning() RelativePositioning {
+	return RelativePositioning{Iterations: 100}
+}
+
+// Validate checks the parameters.
+func (c RelativePositioning) Validate() error {
+	if c.Iterations < 0 {
+		return fmt.Errorf("%w: SMACOF iterations must be non-negative, got %d", ErrOutOfRange, c.Iterations)
+	}
+	return nil
+}
+
+// RelativeMap is the relative map of a step.
+type RelativeMap struct {
+	Time     float64
+	Relative map[string]common.Vector // Object ID -> position in the map, up to rotation, reflection and translation
+	Aligned  map[string]common.Vector // The map rotated and moved onto the true positions
+	Stress   float64                  // Mismatch of the map distances and the ranges, relative to the ranges
+	RMSE     float64                  // RMS distance of the aligned map from the true positions
+}
+
+// relativeState holds the configuration and the last map.
+type relativeState struct {
+	config RelativePositioning
+	last   *RelativeMap
+}
+
+// SetRelativePositioning enables the anchor-free mode, nil disables it.
+func (s *Simulation) SetRelativePositioning(config *RelativePositioning) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config == nil {
+		s.relative = nil
+		return nil
+	}
+	s.relative = &relativeState{config: *config}
+	return nil
+}
+
+// RelativePositioning returns the anchor-free configuration, nil if the mode is off.
+func (s *Simulation) RelativePositioning() *RelativePositioning {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.relative == nil {
+		return nil
+	}
+	c := s.relative.config
+	return &c
+}
+
+// LastRelativeMap returns the newest relative map; false if none was built, e.g. the mode
+// is off or the ranges did not connect all nodes in any step.
+func (s *Simulation) LastRelativeMap() (RelativeMap, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.relative == nil || s.relative.last == nil {
+		return RelativeMap{}, false
+	}
+	last := *s.relative.last
+	last.Relative, last.Aligned = cloneVectors(last.Relative), cloneVectors(last.Aligned)
+	return last, true
+}
+
+// cloneVectors deep-copies a map of vectors.
+func cloneVectors(vs map[string]common.Vector) map[string]common.Vector {
+	out := make(map[string]common.Vector, len(vs))
+	for id, v := range vs {
+		out[id] = v.Clone()
+	}
+	return out
+}
+
+// buildRelativeMap measures the ranges between the nodes and solves the relative map.
+// The caller holds the lock.
+func (s *Simulation) buildRelativeMap() {
+	var nodes []SimulationObject
+	for _, sen := range s.sortedSensors() {
+		nodes = append(nodes, sen)
+	}
+	for _, tar := range s.sortedTargets() {
+		nodes = append(nodes, tar)
+	}
+	problem := multilateration.RelativeProblem{Dimension: s.dimension, Nodes: len(nodes)}
+	for a, node := range nodes {
+		sen, ok := node.(*Sensor)
+		if !ok || sen.status != SensorOperational {
+			continue // Targets only answer
+		}
+		for b := range nodes {
+			if peer, ok := nodes[b].(*Sensor); b == a || ok && b < a && peer.status == SensorOperational {
+				continue // Pairs of operational sensors range once
+			}
+			if d, ok := s.relativeRange(sen, nodes[b]); ok {
+				problem.Ranges = append(problem.Ranges, multilateration.PeerMeasurement{A: a, B: b, Distance: d})
+			}
+		}
+	}
+
+	result, err := multilateration.SolveMDSMAP(problem, s.relative.config.Iterations)
+	if err != nil {
+		s.log().Warn("relative map failed", "tick", s.tick, "nodes", len(nodes), "ranges", len(problem.Ranges), "err", err)
+		s.metrics.Inc(MetricRelativeFailures)
+		return
+	}
+	truth := make([]common.Vector, len(nodes))
+	for i, node := range nodes {
+		truth[i] = node.GetPosition()
+	}
+	alignment, rmse, err := multilateration.AlignProcrustes(result.Positions, truth, false)
+	if err != nil {
+		s.metrics.Inc(MetricRelativeFailures)
+		return
+	}
+	m := &RelativeMap{
+		Time:     s.simulationTime,
+		Relative: make(map[string]common.Vector, len(nodes)),
+		Aligned:  make(map[string]common.Vector, len(nodes)),
+		Stress:   result.Stress,
+		RMSE:     rmse,
+	}
+	for i, node := range nodes {
+		m.Relative[node.GetID()] = result.Positions[i]
+		m.Aligned[node.GetID()] = alignment.Apply(result.Positions[i])
+	}
+	s.relative.last = m
+	s.metrics.Inc(MetricRelativeMaps)
+	s.metrics.Set(MetricRelativeRMSE, rmse)
+	s.metrics.Set(MetricRelativeStress, result.Stress)
+}
+
+// relativeRange is the range a sensor measures to another node for the relative map,
+// false if it is out of range or out of sight.
+func (s *Simulation) relativeRange(sen *Sensor, node SimulationObject) (float64, bool) {
+	pos := node.GetPosition()
+	d, err := sen.position.DistanceIn(pos, s.metric)
+	if err != nil || (sen.detectionRadius > 0 && d > sen.detectionRadius) {
+		return 0, false
+	}
+	clear, bias := s.lineOfSight(sen.position, pos)
+	if !clear {
+		return 0, false
+	}
+	d += bias
+	if sen.noiseFunc != nil {
+		d = sen.noiseFunc(d, s.relativeRand.Rand)
+	}
+	return math.Max(d, 0), true
+}
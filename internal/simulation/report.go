@@ -0,0 +1,120 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// LocalizationOutcome describes what happened to a target during a step.
+type LocalizationOutcome int
+
+const (
+	OutcomeLocalized                LocalizationOutcome = iota // A new estimate was produced
+	OutcomeInsufficientMeasurements                            // Not enough sensors had the target in range
+	OutcomeSolverFailed                                        // The solver returned an error
+	OutcomeOutsideRegion                                       // Not solved, the target is outside the region of interest
+	OutcomeDeferred                                            // Not solved, the fusion center waits for its schedule or fresh measurements
+)
+
+// String returns a short name of the outcome.
+func (o LocalizationOutcome) String() string {
+	switch o {
+	case OutcomeLocalized:
+		return "localized"
+	case OutcomeInsufficientMeasurements:
+		return "insufficient measurements"
+	case OutcomeSolverFailed:
+		return "solver failed"
+	case OutcomeOutsideRegion:
+		return "outside region"
+	case OutcomeDeferred:
+		return "deferred"
+	default:
+		return fmt.Sprintf("outcome(%d)", int(o))
+	}
+}
+
+// TargetReport is the per-target result of a step.
+type TargetReport struct {
+	TargetID        string
+	Outcome         LocalizationOutcome
+	NumMeasurements int   // In-range measurements that reached the solver
+	Gated           int   // Measurements rejected by gating, not among NumMeasurements
+	Err             error // Solver error, set only for OutcomeSolverFailed
+	TrackerErr      error // Tracker update error; not counted by Degraded/Err, trackers may still be initializing
+
+	// Distributed is the consensus of the sensors in distributed localization mode, nil
+	// if the mode is off or no sensor estimates the target.
+	Distributed common.Vector
+
+	// Measurements is the input of the solver and tracker, e.g. for measurement logs.
+	// Shared with the simulation, must not be modified.
+	Measurements []multilateration.Measurement
+}
+
+// SensorError records a measurement that failed with an error (not merely out of range).
+type SensorError struct {
+	SensorID string
+	TargetID string
+	Err      error
+}
+
+// StepReport summarizes one simulation step so that embedding applications can
+// react to degraded operation instead of parsing log output.
+type StepReport struct {
+	Time         float64 // Simulation time at the end of the step
+	Targets      []TargetReport
+	SensorErrors []SensorError
+	SLAMErr      error    // Failure of the joint estimator in range-only SLAM mode
+	State        SimState // The scene at the end of the step, taken atomically with it
+}
+
+// Degraded reports whether any target failed to localize or any measurement errored.
+// Targets outside the region of interest or deferred by the fusion center are skipped
+// on purpose and do not count.
+func (r StepReport) Degraded() bool {
+	if len(r.SensorErrors) > 0 || r.SLAMErr != nil {
+		return true
+	}
+	for _, t := range r.Targets {
+		if t.Outcome != OutcomeLocalized && t.Outcome != OutcomeOutsideRegion && t.Outcome != OutcomeDeferred {
+			return true
+		}
+	}
+	return false
+}
+
+// Err joins all solver and sensor errors of the step, nil if there were none.
+// Targets with insufficient measurements are not considered errors.
+func (r StepReport) Err() error {
+	var errs []error
+	for _, t := range r.Targets {
+		if t.Err != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", t.TargetID, t.Err))
+		}
+	}
+	for _, se := range r.SensorErrors {
+		errs = append(errs, fmt.Errorf("sensor %s measuring %s: %w", se.SensorID, se.TargetID, se.Err))
+	}
+	if r.SLAMErr != nil {
+		errs = append(errs, r.SLAMErr)
+	}
+	return errors.Join(errs...)
+}
+
+// StepHandler is called with the report of every completed step.
+type StepHandler func(report StepReport)
+
+// OnStep registers a handler that receives the report of every step.
+// Handlers run synchronously at the end of Step, on the stepping goroutine, after the
+// simulation lock is released, so they may query the simulation. Prefer report.State
+// over such queries: another goroutine may have stepped again in the meantime.
+func (s *Simulation) OnStep(handler StepHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if handler != nil {
+		s.stepHandlers = append(s.stepHandlers, handler)
+	}
+}
@@ -0,0 +1,163 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Speed limits of SimulationRunner, as multipliers of real time.
+const (
+	MinRunnerSpeed = 0.1
+	MaxRunnerSpeed = 100.0
+)
+
+// defaultMaxCatchUp bounds the steps of one Advance call: when stepping falls behind
+// real time the owed simulation time is dropped rather than freezing the caller.
+const defaultMaxCatchUp = 200
+
+// SimulationRunner drives a simulation with a clock decoupled from wall time: callers
+// report elapsed wall time to Advance, which accumulates it scaled by the speed and
+// takes as many fixed steps of Simulation.NextStepDuration as are owed. RunTicks steps
+// as fast as possible, so a run of N ticks is reproducible whatever the wall clock does.
+// The methods are safe for concurrent use, e.g. a UI loop and the control API.
+type SimulationRunner struct {
+	sim *Simulation
+
+	mu          sync.Mutex
+	paused      bool
+	speed       float64
+	accumulated float64 // Simulation seconds owed but not stepped yet
+	maxCatchUp  int
+	dropped     float64 // Simulation seconds given up by catch-up limits
+}
+
+// NewSimulationRunner creates a running (not paused) runner at real-time speed.
+func NewSimulationRunner(sim *Simulation) *SimulationRunner {
+	return &SimulationRunner{sim: sim, speed: 1, maxCatchUp: defaultMaxCatchUp}
+}
+
+// Simulation returns the driven simulation.
+func (r *SimulationRunner) Simulation() *Simulation {
+	return r.sim
+}
+
+// Advance accounts for wall elapsed since the last call and takes the steps that are due,
+// returning how many. Nothing happens while paused. Time left over (less than a step) is
+// carried to the next call; if more than the catch-up limit of steps are owed, the rest
+// is dropped.
+func (r *SimulationRunner) Advance(wall time.Duration) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.paused || wall <= 0 {
+		return 0
+	}
+	tick := r.sim.NextStepDuration()
+	if tick <= 0 {
+		return 0
+	}
+	r.accumulated += wall.Seconds() * r.speed
+	steps := 0
+	for r.accumulated >= tick {
+		if steps == r.maxCatchUp {
+			r.dropped += r.accumulated
+			r.accumulated = 0
+			break
+		}
+		r.sim.Step(tick)
+		r.accumulated -= tick
+		steps++
+		tick = r.sim.NextStepDuration()
+	}
+	return steps
+}
+
+// RunTicks takes n steps as fast as possible, ignoring pause and speed, and returns the
+// report of the last one (the zero report if n < 1).
+func (r *SimulationRunner) RunTicks(n int) StepReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var report StepReport
+	for i := 0; i < n; i++ {
+		report = r.sim.Step(r.sim.NextStepDuration())
+	}
+	return report
+}
+
+// StepOnce pauses the runner and takes a single step, for frame-by-frame inspection.
+func (r *SimulationRunner) StepOnce() StepReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = true
+	r.accumulated = 0
+	return r.sim.Step(r.sim.NextStepDuration())
+}
+
+// Run advances the simulation in real time, every frame of wall time, until ctx is done.
+// It returns ctx.Err().
+func (r *SimulationRunner) Run(ctx context.Context, frame time.Duration) error {
+	if frame <= 0 {
+		return fmt.Errorf("%w: frame duration must be positive, got %s", ErrOutOfRange, frame)
+	}
+	ticker := time.NewTicker(frame)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			r.Advance(now.Sub(last))
+			last = now
+		}
+	}
+}
+
+// SetPaused pauses or resumes the runner; time owed at that moment is forgotten.
+func (r *SimulationRunner) SetPaused(paused bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = paused
+	r.accumulated = 0
+}
+
+// Paused reports whether the runner is paused.
+func (r *SimulationRunner) Paused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.paused
+}
+
+// SetSpeed sets the speed multiplier relative to real time, clamped to
+// [MinRunnerSpeed, MaxRunnerSpeed].
+func (r *SimulationRunner) SetSpeed(speed float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.speed = min(max(speed, MinRunnerSpeed), MaxRunnerSpeed)
+}
+
+// Speed returns the speed multiplier relative to real time.
+func (r *SimulationRunner) Speed() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.speed
+}
+
+// SetMaxCatchUp sets how many steps one Advance call may take at most (200 by default).
+func (r *SimulationRunner) SetMaxCatchUp(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("catch-up limit must be at least 1 step, got %d", steps)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxCatchUp = steps
+	return nil
+}
+
+// Dropped returns the simulation seconds given up so far because stepping fell behind.
+func (r *SimulationRunner) Dropped() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
@@ -0,0 +1,162 @@
+package simulation
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Seeds selects the random streams of the individual subsystems, so that one source
+// of randomness can be varied while the others are held fixed across Monte Carlo runs.
+// Every object gets its own stream per subsystem, derived from the subsystem seed and
+// the order in which the object was added, so adding draws in one place (e.g. a
+// noisier sensor) never shifts the random numbers seen by the others.
+type Seeds struct {
+	Placement int64 // Random object positions (AddRandomSensor, AddRandomTarget) and SLAM anchor priors
+	Motion    int64 // Motion models of targets and mobile sensors
+	Noise     int64 // Range noise, dropout, blink jitter and odometry noise
+	Clutter   int64 // Spurious detections that do not correspond to any target
+}
+
+// NewSeeds derives distinct subsystem seeds from a single master seed.
+func NewSeeds(master int64) Seeds {
+	return Seeds{
+		Placement: streamSeed(master, 1),
+		Motion:    streamSeed(master, 2),
+		Noise:     streamSeed(master, 3),
+		Clutter:   streamSeed(master, 4),
+	}
+}
+
+// streamSeed mixes a seed with a stream number (SplitMix64 finalizer), so that
+// neighbouring streams are uncorrelated.
+func streamSeed(seed int64, stream uint64) int64 {
+	z := uint64(seed) + (stream+1)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return int64(z ^ (z >> 31))
+}
+
+// newStream creates an independent generator for the given seed and stream number.
+func newStream(seed int64, stream uint64) *randStream {
+	return newSeededStream(streamSeed(seed, stream))
+}
+
+// countingSource is a math/rand source that counts its draws. Every Int63 or Uint64 call
+// advances the underlying generator by one step, so the seed and the draw count are
+// its complete state.
+type countingSource struct {
+	src   rand.Source64
+	seed  int64
+	draws uint64
+}
+
+func (c *countingSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.draws++
+	return c.src.Uint64()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.seed, c.draws = seed, 0
+}
+
+// randStream is a random generator whose state can be saved in a checkpoint.
+type randStream struct {
+	*rand.Rand
+	src *countingSource
+}
+
+// streamState is the state of a random stream: its seed and the number of draws taken.
+type streamState struct {
+	Seed  int64
+	Draws uint64
+}
+
+func newSeededStream(seed int64) *randStream {
+	src := &countingSource{src: rand.NewSource(seed).(rand.Source64), seed: seed}
+	return &randStream{Rand: rand.New(src), src: src}
+}
+
+// state returns the seed and draw count of the stream.
+func (r *randStream) state() streamState {
+	return streamState{Seed: r.src.seed, Draws: r.src.draws}
+}
+
+// restoreStream recreates a stream by replaying its draws, which takes a few
+// nanoseconds per draw.
+func restoreStream(st streamState) *randStream {
+	r := newSeededStream(st.Seed)
+	for range st.Draws {
+		r.src.src.Int63()
+	}
+	r.src.draws = st.Draws
+	return r
+}
+
+// timeSeeds counts the seeds taken from the clock, so that simulations and objects
+// created in the same clock tick (e.g. by parallel Monte Carlo workers) still differ.
+var timeSeeds atomic.Uint64
+
+// timeSeed returns a seed from the clock that is unique within the process.
+func timeSeed() int64 {
+	return streamSeed(time.Now().UnixNano(), timeSeeds.Add(1))
+}
+
+// newUnseededStream creates a generator for objects that are used outside of a simulation.
+func newUnseededStream() *randStream {
+	return newSeededStream(timeSeed())
+}
+
+// SetSeeds reseeds all subsystems. Objects that were already added get fresh streams
+// derived from the new seeds, so the call may happen before or after building the scene.
+func (s *Simulation) SetSeeds(seeds Seeds) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seeds = seeds
+	s.placementRand = newStream(seeds.Placement, 0)
+	s.clutterRand = newStream(seeds.Clutter, 0)
+	s.peerRand = newStream(seeds.Noise, peerStream)
+	s.distributedRand = newStream(seeds.Noise, distributedStream)
+	s.relativeRand = newStream(seeds.Noise, relativeStream)
+	s.networkRand = newStream(seeds.Noise, networkStream)
+	if s.network != nil {
+		s.network.SetRand(s.networkRand.Rand)
+	}
+	for id, obj := range s.objects {
+		s.assignStreams(obj, s.order[id])
+	}
+	if s.slam != nil {
+		s.slam.rng = newStream(seeds.Placement, slamStream)
+	}
+}
+
+// Seeds returns the seeds currently in use.
+func (s *Simulation) Seeds() Seeds {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.seeds
+}
+
+// slamStream is the placement stream number reserved for the SLAM anchor priors;
+// object streams use their insertion sequence number plus one.
+const slamStream = 1 << 62
+
+// assignStreams gives an object its own motion and noise streams.
+func (s *Simulation) assignStreams(obj SimulationObject, seq int64) {
+	stream := uint64(seq) + 1
+	switch v := obj.(type) {
+	case *Sensor:
+		v.motionRand = newStream(s.seeds.Motion, stream)
+		v.noiseRand = newStream(s.seeds.Noise, stream)
+		v.failureRand = newStream(s.seeds.Noise, failureStream+stream)
+	case *Target:
+		v.motionRand = newStream(s.seeds.Motion, stream)
+		v.noiseRand = newStream(s.seeds.Noise, stream)
+	}
+}
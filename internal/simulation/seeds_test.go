@@ -0,0 +1,38 @@
+package simulation_test
+
+import (
+	"multilateration-sim/internal/simulation"
+	"slices"
+	"testing"
+)
+
+func TestSeedsSeparateSubsystems(t *testing.T) {
+	base := simulation.NewSeeds(42)
+	tests := []struct {
+		name          string
+		change        func(*simulation.Seeds)
+		sameTruth     bool
+		sameEstimates bool
+	}{
+		{"same seeds", func(*simulation.Seeds) {}, true, true},
+		{"noise", func(s *simulation.Seeds) { s.Noise = 7 }, true, false},
+		{"motion", func(s *simulation.Seeds) { s.Motion = 7 }, false, false},
+		{"placement", func(s *simulation.Seeds) { s.Placement = 7 }, false, false},
+		{"clutter without false alarms", func(s *simulation.Seeds) { s.Clutter = 7 }, true, true},
+	}
+	noop := func(*simulation.Simulation) {}
+	truth, estimates := randomScene(t, base, noop)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seeds := base
+			tt.change(&seeds)
+			gotTruth, gotEstimates := randomScene(t, seeds, noop)
+			if same := slices.Equal(gotTruth, truth); same != tt.sameTruth {
+				t.Errorf("same trajectories: %t, want %t", same, tt.sameTruth)
+			}
+			if same := slices.Equal(gotEstimates, estimates); same != tt.sameEstimates {
+				t.Errorf("same estimates: %t, want %t", same, tt.sameEstimates)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/multilateration"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metric names of sensor selection. The accuracy impact is measured by solving every
+// selected target a second time with all of its measurements.
+const (
+	MetricMeasurementsSelected   = "measurements_selected"    // Measurements kept by the policy; per sensor with a sensor label
+	MetricMeasurementsDeselected = "measurements_deselected"  // Measurements left out by the policy
+	MetricSelectionComparisons   = "selection_comparisons"    // Targets solved both with the selection and with all measurements
+	MetricSelectionError         = "selection_error"          // Sum of the errors of the compared estimates using the selection
+	MetricSelectionBaselineError = "selection_baseline_error" // Sum of the errors of the compared estimates using all measurements
+	MetricSelectionErrorIncrease = "selection_error_increase" // Gauge: mean error added by the selection over the comparisons
+)
+
+// SelectionContext describes the target a SelectionPolicy chooses measurements for.
+type SelectionContext struct {
+	TargetID  string
+	Tick      int64         // Step being performed
+	Dimension int           // At least Dimension+1 measurements are needed to solve
+	Estimate  common.Vector // Estimate of the previous step, nil if the target was not localized
+}
+
+// SelectionPolicy chooses which in-range sensors are used to localize a target, e.g. to
+// save energy or bandwidth. Select is called once per target and step, in target order
+// and with the lock held, so a policy may keep state between calls but must not call back
+// into the simulation. It returns a subset of measurements; the slice must not be modified.
+type SelectionPolicy interface {
+	Select(ctx SelectionContext, measurements []multilateration.Measurement) []multilateration.Measurement
+	// String returns the name accepted by ParseSelectionPolicy.
+	String() string
+}
+
+// ParseSelectionPolicy parses "gdop:K" (NewBestGDOPSelection) or "roundrobin:K"
+// (NewRoundRobinSelection); an empty name returns nil, which uses all sensors.
+func ParseSelectionPolicy(name string) (SelectionPolicy, error) {
+	if name == "" {
+		return nil, nil
+	}
+	kind, count, ok := strings.Cut(name, ":")
+	if !ok {
+		return nil, fmt.Errorf("selection policy %q: expected KIND:COUNT", name)
+	}
+	k, err := strconv.Atoi(count)
+	if err != nil {
+		return nil, fmt.Errorf("selection policy %q: %w", name, err)
+	}
+	var policy SelectionPolicy
+	switch kind {
+	case "gdop":
+		policy, err = NewBestGDOPSelection(k)
+	case "roundrobin":
+		policy, err = NewRoundRobinSelection(k)
+	default:
+		return nil, fmt.Errorf("unknown selection policy %q", kind)
+	}
+	if err != nil {
+		return nil, err // Not a nil pointer in a non-nil interface
+	}
+	return policy, nil
+}
+
+// selectionCount is the number of measurements a policy keeping k keeps for a target:
+// k, but never fewer than the solver needs.
+func selectionCount(k int, ctx SelectionContext) int {
+	return max(k, ctx.Dimension+1)
+}
+
+// BestGDOPSelection keeps the k sensors with the best geometry around the previous
+// estimate, chosen greedily by GDOP. Without an estimate it keeps the k nearest sensors.
+type BestGDOPSelection struct {
+	k int
+}
+
+// NewBestGDOPSelection creates a policy keeping k sensors per target (at least
+// dimension+1).
+func NewBestGDOPSelection(k int) (*BestGDOPSelection, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("%w: selected sensor count must be positive, got %d", ErrOutOfRange, k)
+	}
+	return &BestGDOPSelection{k: k}, nil
+}
+
+// Select implements SelectionPolicy. The geometry uses straight lines of sight, which
+// approximates non-Euclidean metrics well enough for ranking.
+func (p *BestGDOPSelection) Select(ctx SelectionContext, measurements []multilateration.Measurement) []multilateration.Measurement {
+	keep := selectionCount(p.k, ctx)
+	if len(measurements) <= keep {
+		return measurements
+	}
+	if len(ctx.Estimate) != ctx.Dimension {
+		nearest := append([]multilateration.Measurement(nil), measurements...)
+		sort.SliceStable(nearest, func(i, j int) bool { return nearest[i].Distance < nearest[j].Distance })
+		return nearest[:keep]
+	}
+
+	// Line-of-sight unit vectors; sensors on top of the estimate carry no direction
+	los := make([]common.Vector, len(measurements))
+	for i, m := range measurements {
+		diff, err := ctx.Estimate.Subtract(m.SensorPosition)
+		if err != nil {
+			diff = common.NewVector(ctx.Dimension)
+		}
+		los[i] = diff.Normalize()
+	}
+	// Greedy: add the sensor that most lowers trace((H^T H + eps I)^-1), i.e. GDOP^2 once
+	// the geometry has full rank. The regularization makes incomplete sets comparable and
+	// favours sensors that add a new direction.
+	const eps = 1e-6
+	info := make([][]float64, ctx.Dimension)
+	for i := range info {
+		info[i] = make([]float64, ctx.Dimension)
+		info[i][i] = eps
+	}
+	used := make([]bool, len(measurements))
+	for range keep {
+		best, bestScore := -1, math.Inf(1)
+		for i := range measurements {
+			if used[i] {
+				continue
+			}
+			if score := traceInverse(info, los[i]); score < bestScore {
+				best, bestScore = i, score
+			}
+		}
+		used[best] = true
+		addOuter(info, los[best])
+	}
+	selected := make([]multilateration.Measurement, 0, keep)
+	for i, m := range measurements {
+		if used[i] {
+			selected = append(selected, m)
+		}
+	}
+	return selected
+}
+
+// String implements SelectionPolicy.
+func (p *BestGDOPSelection) String() string {
+	return fmt.Sprintf("gdop:%d", p.k)
+}
+
+// addOuter adds u u^T to m.
+func addOuter(m [][]float64, u common.Vector) {
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] += u[i] * u[j]
+		}
+	}
+}
+
+// traceInverse returns trace((m + u u^T)^-1) by Gauss-Jordan elimination, +Inf if the
+// matrix is singular. m is not modified.
+func traceInverse(m [][]float64, u common.Vector) float64 {
+	n := len(m)
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, 2*n)
+		for j := range n {
+			a[i][j] = m[i][j] + u[i]*u[j]
+		}
+		a[i][n+i] = 1
+	}
+	for col := range n {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if a[pivot][col] == 0 {
+			return math.Inf(1)
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := range n {
+			if row == col {
+				continue
+			}
+			f := a[row][col] / a[col][col]
+			for j := col; j < 2*n; j++ {
+				a[row][j] -= f * a[col][j]
+			}
+		}
+	}
+	trace := 0.0
+	for i := range n {
+		trace += a[i][n+i] / a[i][i]
+	}
+	return trace
+}
+
+// RoundRobinSelection spreads the work evenly: each target gets the k sensors that have
+// been selected least often so far (ties go to the earlier measurement), so over time
+// every sensor spends about the same energy on measurements that are used.
+type RoundRobinSelection struct {
+	k    int
+	uses map[string]int // sensorID -> times selected
+}
+
+// NewRoundRobinSelection creates a policy keeping k sensors per target (at least
+// dimension+1).
+func NewRoundRobinSelection(k int) (*RoundRobinSelection, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("%w: selected sensor count must be positive, got %d", ErrOutOfRange, k)
+	}
+	return &RoundRobinSelection{k: k, uses: make(map[string]int)}, nil
+}
+
+// Select implements SelectionPolicy.
+func (p *RoundRobinSelection) Select(ctx SelectionContext, measurements []multilateration.Measurement) []multilateration.Measurement {
+	keep := selectionCount(p.k, ctx)
+	if len(measurements) <= keep {
+		for _, m := range measurements {
+			p.uses[m.SensorID]++
+		}
+		return measurements
+	}
+	order := make([]int, len(measurements))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return p.uses[measurements[order[i]].SensorID] < p.uses[measurements[order[j]].SensorID]
+	})
+	order = order[:keep]
+	sort.Ints(order) // Keep the original measurement order
+	selected := make([]multilateration.Measurement, len(order))
+	for i, idx := range order {
+		selected[i] = measurements[idx]
+		p.uses[selected[i].SensorID]++
+	}
+	return selected
+}
+
+// String implements SelectionPolicy.
+func (p *RoundRobinSelection) String() string {
+	return fmt.Sprintf("roundrobin:%d", p.k)
+}
+
+// SetSelectionPolicy sets the policy choosing the sensors used per target, nil uses
+// every sensor in range.
+func (s *Simulation) SetSelectionPolicy(policy SelectionPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.selection = policy
+}
+
+// SelectionPolicy returns the sensor selection policy, nil if all sensors are used.
+func (s *Simulation) SelectionPolicy() SelectionPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.selection
+}
+
+// selectMeasurements applies the selection policy to the measurements of a job and keeps
+// the full set for the comparison solve. The caller holds the lock.
+func (s *Simulation) selectMeasurements(job *localizationJob) {
+	if s.selection == nil || len(job.measurements) == 0 {
+		return
+	}
+	targetID := job.target.GetID()
+	ctx := SelectionContext{TargetID: targetID, Tick: s.tick, Dimension: s.dimension, Estimate: s.lastEstimates[targetID].Position}
+	all := job.measurements
+	job.measurements = s.selection.Select(ctx, all)
+	if len(job.measurements) < len(all) {
+		job.all = all
+	}
+	for _, m := range job.measurements {
+		s.metrics.Inc(MetricMeasurementsSelected)
+		s.metrics.Inc(metrics.Key(MetricMeasurementsSelected, "sensor", m.SensorID))
+	}
+	s.metrics.Add(MetricMeasurementsDeselected, float64(len(all)-len(job.measurements)))
+}
+
+// recordSelectionImpact compares the estimate from the selected measurements with the
+// one from all of them. The caller holds the lock.
+func (s *Simulation) recordSelectionImpact(job localizationJob) {
+	if job.all == nil || job.err != nil || job.allErr != nil {
+		return
+	}
+	truePos := job.target.GetPosition()
+	selectedErr, err := s.localizationError(truePos, job.solution.Position)
+	if err != nil {
+		return
+	}
+	baselineErr, err := s.localizationError(truePos, job.allSolution.Position)
+	if err != nil {
+		return
+	}
+	s.metrics.Inc(MetricSelectionComparisons)
+	s.metrics.Add(MetricSelectionError, selectedErr)
+	s.metrics.Add(MetricSelectionBaselineError, baselineErr)
+	n := s.metrics.Counter(MetricSelectionComparisons)
+	s.metrics.Set(MetricSelectionErrorIncrease, (s.metrics.Counter(MetricSelectionError)-s.metrics.Counter(MetricSelectionBaselineError))/n)
+}
@@ -0,0 +1,118 @@
+package simulation_test
+
+import (
+	"errors"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+// circleAnchors are eight sensors on a circle of radius 40 around the origin.
+func circleAnchors() []common.Vector {
+	positions := make([]common.Vector, 8)
+	for i := range positions {
+		angle := float64(i) * math.Pi / 4
+		positions[i] = common.Vector{40 * math.Cos(angle), 40 * math.Sin(angle)}
+	}
+	return positions
+}
+
+// runSelection solves a static target for 40 steps with the policy and returns the metrics.
+func runSelection(t *testing.T, policy simulation.SelectionPolicy) *simulation.Simulation {
+	t.Helper()
+	sim := newTestSimulation(t, time.Second, simulation.GaussianNoise(1), circleAnchors()...)
+	sim.SetSeeds(simulation.NewSeeds(1))
+	addObjects(t, sim, simulation.NewTargetWithID("beacon", common.Vector{10, 5}, nil))
+	sim.SetSelectionPolicy(policy)
+	stepN(sim, 40, 1)
+	return sim
+}
+
+func TestParseSelectionPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string // String of the policy, "" for nil
+		wantErr bool
+	}{
+		{"", "", false},
+		{"gdop:4", "gdop:4", false},
+		{"roundrobin:3", "roundrobin:3", false},
+		{"gdop", "", true},
+		{"gdop:four", "", true},
+		{"gdop:0", "", true},
+		{"nearest:3", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := simulation.ParseSelectionPolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %t", err, tt.wantErr)
+			}
+			got := ""
+			if policy != nil {
+				got = policy.String()
+			}
+			if got != tt.want {
+				t.Errorf("got policy %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := simulation.ParseSelectionPolicy("roundrobin:-1"); !errors.Is(err, simulation.ErrOutOfRange) {
+		t.Errorf("negative count: got %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestSelectionPolicyCounts(t *testing.T) {
+	tests := []struct {
+		policy       string
+		wantSelected float64 // Per solve, out of 8 measurements
+	}{
+		{"gdop:4", 4},
+		{"roundrobin:4", 4},
+		{"gdop:2", 3}, // Never fewer than the solver needs
+		{"roundrobin:2", 3},
+		{"gdop:10", 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			policy, err := simulation.ParseSelectionPolicy(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := runSelection(t, policy).Metrics()
+			const solves = 40
+			wantComparisons := float64(solves)
+			if tt.wantSelected == 8 {
+				wantComparisons = 0 // Nothing left out, nothing to compare
+			}
+			if got := m.Counter(simulation.MetricSelectionComparisons); got != wantComparisons {
+				t.Errorf("%v comparisons, want %v", got, wantComparisons)
+			}
+			if got := m.Counter(simulation.MetricMeasurementsSelected); got != tt.wantSelected*solves {
+				t.Errorf("selected %v, want %v", got, tt.wantSelected*solves)
+			}
+			if got := m.Counter(simulation.MetricMeasurementsDeselected); got != (8-tt.wantSelected)*solves {
+				t.Errorf("left out %v, want %v", got, (8-tt.wantSelected)*solves)
+			}
+		})
+	}
+}
+
+func TestBestGDOPSelectionAddsLessError(t *testing.T) {
+	increase := make(map[string]float64)
+	for _, name := range []string{"gdop:4", "roundrobin:4"} {
+		policy, err := simulation.ParseSelectionPolicy(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		increase[name], _ = runSelection(t, policy).Metrics().Gauge(simulation.MetricSelectionErrorIncrease)
+		if increase[name] <= 0 {
+			t.Errorf("%s: solving with half of the sensors added error %v", name, increase[name])
+		}
+	}
+	if increase["gdop:4"] >= increase["roundrobin:4"] {
+		t.Errorf("gdop:4 added %v, roundrobin:4 %v", increase["gdop:4"], increase["roundrobin:4"])
+	}
+}
@@ -2,6 +2,8 @@ package simulation
 
 import (
 	"fmt"
+	"log/slog"
+	"math"
 	"math/rand"
 	"multilateration-sim/internal/common" // Замените на ваше имя модуля
 
@@ -9,19 +11,44 @@ import (
 )
 
 // NoiseFunction defines a function signature for adding noise to measurements.
-// It takes the true distance and returns the noisy distance.
-type NoiseFunction func(trueDistance float64) float64
+// It takes the true distance and returns the noisy distance. Random draws must come
+// from rng (the sensor's noise stream, see Seeds) so that runs are reproducible.
+type NoiseFunction func(trueDistance float64, rng *rand.Rand) float64
 
 // Sensor represents a sensor object in the simulation.
 type Sensor struct {
 	id              string
+	label           string // Human-readable name for display, empty if none
 	position        common.Vector
 	detectionRadius float64       // Maximum distance the sensor can detect
 	noiseFunc       NoiseFunction // Function to add noise to measurements
+	velocity        common.Vector // Current velocity, zero for static sensors
+	motion          MotionModel   // nil keeps the sensor static
+
+	measurementInterval int           // Measure every k-th simulation step (1 = every step)
+	latency             float64       // Seconds between taking a measurement and it reaching the solver
+	dropoutProbability  float64       // Probability that an in-range measurement is lost
+	falseAlarmRate      float64       // Expected spurious detections per measurement round
+	clock               Clock         // Time-of-arrival mode only
+	calibrationOffset   common.Vector // Error of the position reported with measurements, nil = exact
+	rangeVariance       float64       // Declared range noise variance reported with measurements, 0 = unknown
+	rangeRateNoise      NoiseFunction // Doppler range-rate noise, nil = no range-rate measurements
+	measuresRangeRate   bool
+	failure             FailureModel
+	status              SensorStatus
+	outageLeft          float64      // Seconds until an outage ends
+	operatingTime       float64      // Seconds in operation, drives the noise growth of aging
+	boundary            BoundaryMode // Set by the simulation
+
+	motionRand  *randStream   // Random stream of the motion model
+	noiseRand   *randStream   // Random stream of range noise and dropout
+	failureRand *randStream   // Random stream of failures and outages
+	logger      *slog.Logger  // Set by the simulation, nil means slog.Default()
+	metric      common.Metric // Set by the simulation, nil means Euclidean
 	// Add other sensor-specific properties if needed
 }
 
-// NewSensor creates a new sensor at a given position.
+// NewSensor creates a new sensor at a given position with a random ID.
 func NewSensor(pos common.Vector, radius float64, noise NoiseFunction) *Sensor {
 	// if noise == nil {
 	// 	noise = func(d float64) float64 { return d } // Default: no noise
@@ -32,14 +59,49 @@ func NewSensor(pos common.Vector, radius float64, noise NoiseFunction) *Sensor {
 		position:        pos.Clone(),
 		detectionRadius: radius,
 		noiseFunc:       noise,
+		velocity:        common.NewVector(pos.Dimension()),
+
+		measurementInterval: 1,
+		motionRand:          newUnseededStream(),
+		noiseRand:           newUnseededStream(),
+		failureRand:         newUnseededStream(),
 	}
 }
 
+// NewSensorWithID creates a sensor with a designated ID, e.g. "anchor-NE", so that
+// configuration and scripts can refer to it. An empty id picks a random one.
+func NewSensorWithID(id string, pos common.Vector, radius float64, noise NoiseFunction) *Sensor {
+	s := NewSensor(pos, radius, noise)
+	if id != "" {
+		s.id = id
+	}
+	return s
+}
+
+// NewSensorWithMotion creates a mobile sensor (e.g. a drone acting as an anchor)
+// driven by the given motion model.
+func NewSensorWithMotion(pos common.Vector, radius float64, noise NoiseFunction, motion MotionModel) *Sensor {
+	s := NewSensor(pos, radius, noise)
+	s.motion = motion
+	return s
+}
+
 // GetID returns the unique identifier of the sensor.
 func (s *Sensor) GetID() string {
 	return s.id
 }
 
+// Label returns the human-readable name of the sensor, empty if it has none.
+func (s *Sensor) Label() string {
+	return s.label
+}
+
+// SetLabel sets a human-readable name shown next to the sensor. Unlike the ID it need
+// not be unique and can change.
+func (s *Sensor) SetLabel(label string) {
+	s.label = label
+}
+
 // GetPosition returns the current position of the sensor.
 func (s *Sensor) GetPosition() common.Vector {
 	return s.position.Clone()
@@ -48,23 +110,63 @@ func (s *Sensor) GetPosition() common.Vector {
 // SetPosition sets the position of the sensor.
 func (s *Sensor) SetPosition(pos common.Vector) error {
 	if pos.Dimension() != s.position.Dimension() {
-		return fmt.Errorf("dimension mismatch: expected %d, got %d", s.position.Dimension(), pos.Dimension())
+		return fmt.Errorf("%w: expected %d, got %d", ErrDimensionMismatch, s.position.Dimension(), pos.Dimension())
 	}
 	s.position = pos.Clone()
 	return nil
 }
 
-// Update for Sensor is currently empty as sensors are static in this version.
-// If sensors could move, logic would go here.
+// GetVelocity returns the current velocity of the sensor.
+func (s *Sensor) GetVelocity() common.Vector {
+	return s.velocity.Clone()
+}
+
+// SetVelocity sets the current velocity of the sensor, e.g. for a constant-velocity model.
+func (s *Sensor) SetVelocity(vel common.Vector) error {
+	if vel.Dimension() != s.position.Dimension() {
+		return fmt.Errorf("%w: expected %d, got %d", ErrDimensionMismatch, s.position.Dimension(), vel.Dimension())
+	}
+	s.velocity = vel.Clone()
+	return nil
+}
+
+// MotionModel returns the motion model driving the sensor (nil if static).
+func (s *Sensor) MotionModel() MotionModel {
+	return s.motion
+}
+
+// SetMotionModel makes the sensor mobile. nil makes it static again.
+func (s *Sensor) SetMotionModel(motion MotionModel) {
+	s.motion = motion
+	if motion == nil {
+		s.velocity = common.NewVector(s.position.Dimension())
+	}
+}
+
+// Update moves the sensor if it has a motion model; static sensors are left untouched.
+// Measurements taken later in the same step use the updated position.
 func (s *Sensor) Update(deltaTime float64, bounds []float64) {
-	// Sensors are static for now
+	if s.motion == nil {
+		return // Static sensor
+	}
+	dim := s.position.Dimension()
+	newPos, newVel := s.motion.Step(s.position, s.velocity, deltaTime, s.motionRand.Rand)
+	if newPos.Dimension() != dim || newVel.Dimension() != dim {
+		loggerOrDefault(s.logger).Error("motion model changed the dimension, update skipped", "sensor", s.id, "dimension", dim)
+		return
+	}
+	s.velocity = newVel
+	if len(bounds) == dim*2 {
+		applyBoundary(s.boundary, newPos, s.velocity, bounds)
+	}
+	s.position = newPos
 }
 
 // MeasureDistance measures the distance to a target object.
 // Returns the measured distance (potentially with noise) and true if successful (within radius), false otherwise.
 func (s *Sensor) MeasureDistance(target SimulationObject) (float64, bool, error) {
 	targetPos := target.GetPosition()
-	trueDist, err := s.position.Distance(targetPos)
+	trueDist, err := s.position.DistanceIn(targetPos, s.metric)
 	if err != nil {
 		return 0, false, fmt.Errorf("error calculating distance for sensor %s: %w", s.id, err)
 	}
@@ -78,7 +180,7 @@ func (s *Sensor) MeasureDistance(target SimulationObject) (float64, bool, error)
 	if s.noiseFunc == nil {
 		noisyDist = trueDist
 	} else {
-		noisyDist = s.noiseFunc(trueDist)
+		noisyDist = s.noiseFunc(trueDist, s.noiseRand.Rand)
 	}
 
 	if noisyDist < 0 {
@@ -88,23 +190,88 @@ func (s *Sensor) MeasureDistance(target SimulationObject) (float64, bool, error)
 	return noisyDist, true, nil
 }
 
+// RangeVariance returns the declared range noise variance, 0 if unknown.
+func (s *Sensor) RangeVariance() float64 {
+	return s.rangeVariance
+}
+
+// SetRangeVariance declares the variance of the sensor's range noise (e.g. stdDev^2 for
+// GaussianNoise). It is attached to every measurement for weighted solvers; the noise
+// function itself is not affected.
+func (s *Sensor) SetRangeVariance(variance float64) {
+	if variance < 0 {
+		variance = 0
+	}
+	s.rangeVariance = variance
+}
+
+// EnableRangeRate makes the sensor also measure the Doppler range-rate of targets,
+// perturbed by its own noise function (nil means noiseless).
+func (s *Sensor) EnableRangeRate(noise NoiseFunction) {
+	s.measuresRangeRate = true
+	s.rangeRateNoise = noise
+}
+
+// DisableRangeRate stops range-rate measurements.
+func (s *Sensor) DisableRangeRate() {
+	s.measuresRangeRate = false
+	s.rangeRateNoise = nil
+}
+
+// MeasuresRangeRate reports whether the sensor measures range-rates.
+func (s *Sensor) MeasuresRangeRate() bool {
+	return s.measuresRangeRate
+}
+
+// MeasureRangeRate measures the rate of change of the distance to a target (positive when
+// it recedes), taking the velocities of both the target and the sensor into account.
+// Objects without a GetVelocity method are treated as static.
+func (s *Sensor) MeasureRangeRate(target SimulationObject) (float64, error) {
+	los, err := target.GetPosition().Subtract(s.position)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating range-rate for sensor %s: %w", s.id, err)
+	}
+	norm := los.Norm()
+	if norm == 0 {
+		return 0, fmt.Errorf("sensor %s: target coincides with the sensor, range-rate undefined", s.id)
+	}
+	relVel := s.velocity.MultiplyByScalar(-1)
+	if moving, ok := target.(interface{ GetVelocity() common.Vector }); ok {
+		if relVel, err = moving.GetVelocity().Subtract(s.velocity); err != nil {
+			return 0, fmt.Errorf("error calculating range-rate for sensor %s: %w", s.id, err)
+		}
+	}
+	rate, err := los.DotProduct(relVel)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating range-rate for sensor %s: %w", s.id, err)
+	}
+	rate /= norm
+	if s.rangeRateNoise != nil {
+		rate = s.rangeRateNoise(rate, s.noiseRand.Rand)
+	}
+	return rate, nil
+}
+
 // String representation for logging
 func (s *Sensor) String() string {
 	noiseDesc := "no"
 	if s.noiseFunc != nil {
 		// Basic check, won't work for complex closures but ok for now
 		ptrVal := fmt.Sprintf("%p", s.noiseFunc)
-		if ptrVal != fmt.Sprintf("%p", func(d float64) float64 { return d }) {
+		if ptrVal != fmt.Sprintf("%p", func(d float64, _ *rand.Rand) float64 { return d }) {
 			noiseDesc = "yes"
 		}
 	}
+	if s.motion != nil {
+		return fmt.Sprintf("Sensor[%s] Pos: %s Vel: %s Radius: %.2f Noise: %s", s.id, s.position, s.velocity, s.detectionRadius, noiseDesc)
+	}
 	return fmt.Sprintf("Sensor[%s] Pos: %s Radius: %.2f Noise: %s", s.id, s.position, s.detectionRadius, noiseDesc)
 }
 
 // --- Example Noise Functions ---
 
 // NoNoise is a NoiseFunction that adds no noise.
-func NoNoise(trueDistance float64) float64 {
+func NoNoise(trueDistance float64, _ *rand.Rand) float64 {
 	return trueDistance
 }
 
@@ -113,8 +280,8 @@ func GaussianNoise(stdDev float64) NoiseFunction {
 	if stdDev < 0 {
 		stdDev = 0
 	}
-	return func(trueDistance float64) float64 {
-		noise := rand.NormFloat64() * stdDev
+	return func(trueDistance float64, rng *rand.Rand) float64 {
+		noise := rng.NormFloat64() * stdDev
 		return trueDistance + noise
 	}
 }
@@ -124,8 +291,8 @@ func UniformNoise(maxDelta float64) NoiseFunction {
 	if maxDelta < 0 {
 		maxDelta = 0
 	}
-	return func(trueDistance float64) float64 {
-		noise := (rand.Float64()*2 - 1) * maxDelta // Noise between -maxDelta and +maxDelta
+	return func(trueDistance float64, rng *rand.Rand) float64 {
+		noise := (rng.Float64()*2 - 1) * maxDelta // Noise between -maxDelta and +maxDelta
 		return trueDistance + noise
 	}
 }
@@ -136,9 +303,9 @@ func PercentageNoise(percentage float64) NoiseFunction {
 	if percentage < 0 {
 		percentage = 0
 	}
-	return func(trueDistance float64) float64 {
+	return func(trueDistance float64, rng *rand.Rand) float64 {
 		noiseMagnitude := trueDistance * percentage
-		noise := (rand.Float64()*2 - 1) * noiseMagnitude // Noise between -noiseMagnitude and +noiseMagnitude
+		noise := (rng.Float64()*2 - 1) * noiseMagnitude // Noise between -noiseMagnitude and +noiseMagnitude
 		return trueDistance + noise
 	}
 }
@@ -146,3 +313,132 @@ func PercentageNoise(percentage float64) NoiseFunction {
 func (s *Sensor) DetectionRadius() float64 {
 	return s.detectionRadius
 }
+
+// SetDetectionRadius changes the maximum detection distance, 0 means unlimited.
+func (s *Sensor) SetDetectionRadius(radius float64) error {
+	if radius < 0 {
+		return fmt.Errorf("%w: detection radius must be non-negative, got %f", ErrOutOfRange, radius)
+	}
+	s.detectionRadius = radius
+	return nil
+}
+
+// SetNoiseFunction replaces the range noise of the sensor, nil means noiseless. The
+// declared RangeVariance is not changed.
+func (s *Sensor) SetNoiseFunction(noise NoiseFunction) {
+	s.noiseFunc = noise
+}
+
+// MeasurementInterval returns how many simulation steps pass between two measurements.
+func (s *Sensor) MeasurementInterval() int {
+	return s.measurementInterval
+}
+
+// SetMeasurementInterval makes the sensor measure only every k-th simulation step.
+func (s *Sensor) SetMeasurementInterval(k int) error {
+	if k < 1 {
+		return fmt.Errorf("%w: measurement interval must be at least 1 tick, got %d", ErrOutOfRange, k)
+	}
+	s.measurementInterval = k
+	return nil
+}
+
+// DropoutProbability returns the probability that an in-range measurement is lost.
+func (s *Sensor) DropoutProbability() float64 {
+	return s.dropoutProbability
+}
+
+// SetDropoutProbability sets the fraction of in-range measurements that are randomly lost (packet loss).
+func (s *Sensor) SetDropoutProbability(p float64) error {
+	if p < 0 || p > 1 {
+		return fmt.Errorf("%w: dropout probability must be within [0, 1], got %f", ErrOutOfRange, p)
+	}
+	s.dropoutProbability = p
+	return nil
+}
+
+// FalseAlarmRate returns the expected number of spurious detections per measurement round.
+func (s *Sensor) FalseAlarmRate() float64 {
+	return s.falseAlarmRate
+}
+
+// SetFalseAlarmRate makes the sensor report, on average, rate spurious ranges (clutter)
+// every time it measures, uniform between 0 and its detection radius. They do not
+// belong to any target, so only the data association (Simulation.SetAssociator) sees
+// them; labeled measurements are unaffected.
+func (s *Sensor) SetFalseAlarmRate(rate float64) error {
+	if rate < 0 {
+		return fmt.Errorf("%w: false alarm rate must be non-negative, got %f", ErrOutOfRange, rate)
+	}
+	s.falseAlarmRate = rate
+	return nil
+}
+
+// Latency returns the delay in seconds before a measurement reaches the solver.
+func (s *Sensor) Latency() float64 {
+	return s.latency
+}
+
+// SetLatency sets the delay in seconds before a measurement reaches the solver.
+func (s *Sensor) SetLatency(latency float64) error {
+	if latency < 0 {
+		return fmt.Errorf("%w: latency must be non-negative, got %f", ErrOutOfRange, latency)
+	}
+	s.latency = latency
+	return nil
+}
+
+// NLOSNoise creates a NoiseFunction modeling non-line-of-sight propagation: with the given
+// probability the range gets an additional positive bias drawn from an exponential
+// distribution with mean meanBias. Otherwise the distance is returned unchanged.
+func NLOSNoise(probability, meanBias float64) NoiseFunction {
+	if probability < 0 {
+		probability = 0
+	}
+	if meanBias < 0 {
+		meanBias = 0
+	}
+	return func(trueDistance float64, rng *rand.Rand) float64 {
+		if rng.Float64() >= probability {
+			return trueDistance
+		}
+		return trueDistance + rng.ExpFloat64()*meanBias
+	}
+}
+
+// ChainNoise composes noise functions: each one is applied to the output of the previous,
+// e.g. ChainNoise(BiasNoise(2), GaussianNoise(1), QuantizationNoise(0.5)).
+// nil entries are skipped, an empty chain behaves like NoNoise.
+func ChainNoise(fns ...NoiseFunction) NoiseFunction {
+	chain := make([]NoiseFunction, 0, len(fns))
+	for _, fn := range fns {
+		if fn != nil {
+			chain = append(chain, fn)
+		}
+	}
+	return func(trueDistance float64, rng *rand.Rand) float64 {
+		distance := trueDistance
+		for _, fn := range chain {
+			distance = fn(distance, rng)
+		}
+		return distance
+	}
+}
+
+// BiasNoise creates a NoiseFunction that adds a constant offset, e.g. an uncalibrated cable delay.
+func BiasNoise(offset float64) NoiseFunction {
+	return func(trueDistance float64, rng *rand.Rand) float64 {
+		return trueDistance + offset
+	}
+}
+
+// QuantizationNoise creates a NoiseFunction that rounds the distance to the nearest
+// multiple of step, modeling a finite timer resolution. step <= 0 disables rounding.
+func QuantizationNoise(step float64) NoiseFunction {
+	return func(trueDistance float64, rng *rand.Rand) float64 {
+		if step <= 0 {
+			return trueDistance
+		}
+		return math.Round(trueDistance/step) * step
+	}
+}
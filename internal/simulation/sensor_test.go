@@ -0,0 +1,41 @@
+package simulation_test
+
+import (
+	"errors"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+)
+
+func TestSensorSettersRejectOutOfRange(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(*simulation.Sensor) error
+	}{
+		{"negative detection radius", func(s *simulation.Sensor) error { return s.SetDetectionRadius(-1) }},
+		{"measurement interval 0", func(s *simulation.Sensor) error { return s.SetMeasurementInterval(0) }},
+		{"dropout probability 1.5", func(s *simulation.Sensor) error { return s.SetDropoutProbability(1.5) }},
+		{"negative dropout probability", func(s *simulation.Sensor) error { return s.SetDropoutProbability(-0.1) }},
+		{"negative false alarm rate", func(s *simulation.Sensor) error { return s.SetFalseAlarmRate(-1) }},
+		{"negative latency", func(s *simulation.Sensor) error { return s.SetLatency(-0.1) }},
+		{"failure probability 2", func(s *simulation.Sensor) error {
+			return s.SetFailureModel(simulation.FailureModel{FailureProbability: 2})
+		}},
+		{"outages without duration", func(s *simulation.Sensor) error {
+			return s.SetFailureModel(simulation.FailureModel{OutageRate: 1})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sensor := simulation.NewSensor(common.Vector{0, 0}, 10, nil)
+			if err := tt.set(sensor); !errors.Is(err, simulation.ErrOutOfRange) {
+				t.Errorf("got %v, want ErrOutOfRange", err)
+			}
+			// The sensor keeps its previous settings
+			if sensor.DetectionRadius() != 10 || sensor.MeasurementInterval() != 1 || sensor.DropoutProbability() != 0 ||
+				sensor.FalseAlarmRate() != 0 || sensor.Latency() != 0 || sensor.FailureModel() != (simulation.FailureModel{}) {
+				t.Error("rejected setting was applied")
+			}
+		})
+	}
+}
@@ -1,48 +1,169 @@
+// Package simulation moves sensors and targets through an n-dimensional space, measures
+// ranges between them and localizes the targets by multilateration every step.
+//
+// All state, including every random stream, belongs to a Simulation and the objects
+// added to it; the package has no global generator. Independent simulations can
+// therefore run concurrently in one process, e.g. one per worker of a Monte Carlo
+// sweep, and each reproduces its results from its Seeds.
 package simulation
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"log/slog"
+	"multilateration-sim/internal/association"
 	"multilateration-sim/internal/common" // Замените на ваше имя модуля
+	"multilateration-sim/internal/metrics"
 	"multilateration-sim/internal/multilateration"
-	"strings"
+	"multilateration-sim/internal/network"
+	"multilateration-sim/internal/tracking"
+	"sort"
+	"sync"
 	"time"
 )
 
+// Metric names maintained by Step.
+const (
+	MetricSteps                    = "steps"                     // Simulation steps performed
+	MetricSolverInvocations        = "solver_invocations"        // Position solves attempted
+	MetricSolverFailures           = "solver_failures"           // Solves that returned an error
+	MetricDegenerateGeometry       = "degenerate_geometry"       // Failures because the sensors do not determine a position
+	MetricInsufficientMeasurements = "insufficient_measurements" // Targets skipped for lack of measurements
+	MetricOutsideRegion            = "outside_region"            // Targets skipped outside the region of interest
+	MetricSimulationTime           = "simulation_time"           // Gauge: simulation seconds
+	MetricSensors                  = "sensors"                   // Gauge: sensors in the simulation
+	MetricTargets                  = "targets"                   // Gauge: targets in the simulation
+)
+
 // Simulation holds the state of the n-dimensional simulation.
+//
+// Concurrency: the methods of Simulation are safe for concurrent use, so one goroutine
+// may drive Step while others (a renderer, a debug endpoint) query it. Step holds the
+// lock while it mutates the scene and releases it before calling the OnStep handlers,
+// which may therefore call back into the simulation; solvers, tracker factories and
+// motion models run with the lock held and must not. The objects returned by GetObject,
+// GetSensors, GetTargets and GetAllObjects are live and not synchronized: only use them
+// on the goroutine that drives Step, and read a Snapshot everywhere else.
 type Simulation struct {
+	mu sync.RWMutex // Guards everything below except metrics, which has its own lock
+
 	dimension      int
 	bounds         []float64
+	world          World        // Space the objects move in, nil = the box of the bounds
+	boundary       BoundaryMode // What moving objects do at the edge of the bounds
 	objects        map[string]SimulationObject
 	sensors        map[string]*Sensor
 	targets        map[string]*Target
+	obstacles      map[string]*Obstacle
 	simulationTime float64
-	tickDuration   time.Duration // Not directly used by Step, but kept for context
+	tickDuration   time.Duration // Nominal step length returned by NextStepDuration
 
 	lastEstimates map[string]multilateration.Solution
 	lastErrors    map[string]float64
+
+	format *common.Format // Output format for logging, nil means common.DefaultFormat()
+	logger *slog.Logger   // Structured log output, nil means slog.Default()
+	metric common.Metric  // Distance of ranges and errors, nil means Euclidean
+
+	signalSpeed      float64 // Time-of-arrival mode: ranges include clock errors, 0 = off
+	calibrationSigma float64 // Sensor position error set by PerturbSensorPositions
+
+	stepHandlers []StepHandler
+
+	subMu       sync.Mutex // Guards subscribers; taken after mu is released, never together
+	subscribers []*Subscription
+
+	events        *EventBus         // Has its own lock
+	inRange       map[rangeKey]bool // Sensor-target pairs detected at the last measurement
+	errorExceeded map[string]bool   // Targets whose error is above the event threshold
+
+	tick              int64                    // Number of steps performed
+	pending           []pendingMeasurement     // Measurements still in flight (latency)
+	network           *network.Network         // Carries the reports to the solver, nil = latency only
+	fusion            *fusionCenter            // Buffers the delivered measurements, decides the solves
+	maxMeasurementAge float64                  // Seconds, 0 = unlimited
+	latestClutter     map[string]*clutterBatch // sensorID -> newest delivered false alarms
+
+	metrics *metrics.Registry
+	solver  multilateration.SolveFunc // Position solver, SolveLeastSquares by default
+
+	customSolver  bool                      // SetSolver replaced SolveLeastSquares
+	workerSolvers []*multilateration.Solver // Workspaces of the default solver, one per worker
+
+	slam *slamState // Range-only SLAM mode, nil when disabled
+
+	associator  association.Associator // Anonymous detections matched to targets, nil = labeled measurements
+	assocTracks map[string]assocTrack  // targetID -> association track, nil when association is off
+
+	trackerFactory TrackerFactory              // Temporal estimation, nil when disabled
+	trackers       map[string]tracking.Tracker // targetID -> tracker
+	odometrySigma  float64                     // Odometry noise reported to trackers, negative = no odometry
+
+	regionOfInterest Region                  // Targets outside are not solved, nil = everywhere
+	adaptive         *AdaptiveStepping       // Step length chosen from the scene, nil = fixed tick
+	selection        SelectionPolicy         // Sensors used per target, nil = all in range
+	peerRanging      *PeerRanging            // Ranging between targets and joint solve, nil = off
+	distributed      *distributedState       // Decentralized estimation by the sensors, nil = off
+	lastStep         float64                 // Seconds simulated by the previous step
+	history          history                 // States of the last steps, see SetHistoryLength
+	smoothing        *smoothingState         // Fixed-lag smoothing of the estimates, nil = off
+	maneuvers        []*activeManeuver       // Injected maneuvers that have not finished
+	trackQuality     TrackQuality            // How track status is decided
+	tracks           map[string]*trackRecord // targetID -> track status
+	gating           *MeasurementGating      // Ranges checked against the track prediction, nil = off
+	relative         *relativeState          // Anchor-free relative positioning, nil = off
+
+	seeds           Seeds
+	placementRand   *randStream      // Random object placement
+	clutterRand     *randStream      // Spurious detections
+	peerRand        *randStream      // Noise of the ranges between targets
+	distributedRand *randStream      // Message loss of distributed localization
+	relativeRand    *randStream      // Noise of the ranges between nodes in relative positioning
+	networkRand     *randStream      // Network jitter and drops
+	order           map[string]int64 // objectID -> insertion sequence number
+	nextSeq         int64
+
+	scheduled       []scheduledChange // Pending spawns and despawns, by time
+	nextScheduleSeq int64
+
+	concurrency int // Step workers, 0 = GOMAXPROCS
 }
 
 // NewSimulation creates a new simulation environment.
 func NewSimulation(dimension int, bounds []float64, tickDuration time.Duration) (*Simulation, error) {
 	if len(bounds) != dimension*2 && dimension > 0 { // Allow empty bounds for 0-dim (though unlikely)
-		return nil, fmt.Errorf("bounds length must be dimension * 2, got %d, expected %d for dim %d", len(bounds), dimension*2, dimension)
+		return nil, fmt.Errorf("%w: bounds length must be dimension * 2, got %d, expected %d for dim %d", ErrDimensionMismatch, len(bounds), dimension*2, dimension)
 	}
 	if dimension < 0 { // Allow 0 dimension if it makes sense for some edge case, but typically >= 1
-		return nil, fmt.Errorf("dimension must be non-negative, got %d", dimension)
+		return nil, fmt.Errorf("%w: dimension must be non-negative, got %d", ErrOutOfRange, dimension)
 	}
 
-	return &Simulation{
+	s := &Simulation{
 		dimension:      dimension,
 		bounds:         bounds,
 		objects:        make(map[string]SimulationObject),
 		sensors:        make(map[string]*Sensor),
 		targets:        make(map[string]*Target),
+		obstacles:      make(map[string]*Obstacle),
 		simulationTime: 0.0,
 		tickDuration:   tickDuration,
 		lastEstimates:  make(map[string]multilateration.Solution),
 		lastErrors:     make(map[string]float64),
-	}, nil
+
+		fusion:        newFusionCenter(),
+		metrics:       metrics.NewRegistry(),
+		solver:        multilateration.SolveLeastSquares,
+		odometrySigma: -1,
+		order:         make(map[string]int64),
+		events:        newEventBus(),
+		inRange:       make(map[rangeKey]bool),
+		errorExceeded: make(map[string]bool),
+		trackQuality:  DefaultTrackQuality(),
+		tracks:        make(map[string]*trackRecord),
+	}
+	s.SetSeeds(NewSeeds(timeSeed()))
+	return s, nil
 }
 
 // AddObject, AddRandomSensor, AddRandomTarget, GetObject, GetSensors, GetTargets,
@@ -50,84 +171,167 @@ func NewSimulation(dimension int, bounds []float64, tickDuration time.Duration)
 
 // AddObject adds a simulation object to the simulation.
 func (s *Simulation) AddObject(obj SimulationObject) error {
+	defer s.events.flush() // Deferred first, so it runs after the unlock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addObject(obj)
+}
+
+func (s *Simulation) addObject(obj SimulationObject) error {
 	if obj.GetPosition().Dimension() != s.dimension {
-		return fmt.Errorf("object dimension %d does not match simulation dimension %d", obj.GetPosition().Dimension(), s.dimension)
+		return fmt.Errorf("%w: object dimension %d does not match simulation dimension %d", ErrDimensionMismatch, obj.GetPosition().Dimension(), s.dimension)
 	}
 	id := obj.GetID()
 	if _, exists := s.objects[id]; exists {
-		return fmt.Errorf("object with ID %s already exists", id)
+		return fmt.Errorf("%w: object with ID %s already exists", ErrDuplicateID, id)
 	}
 	s.objects[id] = obj
+	s.order[id] = s.nextSeq
+	s.assignStreams(obj, s.nextSeq)
+	s.nextSeq++
 
 	switch v := obj.(type) {
 	case *Sensor:
 		s.sensors[id] = v
+		v.logger = s.logger
+		v.metric = s.metric
+		v.boundary = s.boundary
 	case *Target:
 		s.targets[id] = v
+		v.logger = s.logger
+		v.boundary = s.boundary
 		s.lastEstimates[id] = multilateration.Solution{Position: nil, ResidualError: -1}
 		s.lastErrors[id] = -1.0
+		if s.assocTracks != nil {
+			s.cueTrack(id, v)
+		}
+	case *Obstacle:
+		s.obstacles[id] = v
+	}
+	s.events.emit(Event{Kind: EventObjectAdded, Time: s.simulationTime, ObjectID: id})
+	return nil
+}
+
+// RemoveObject removes a sensor or target together with its estimates, tracker and
+// measurements still in flight. Metrics already recorded are kept.
+func (s *Simulation) RemoveObject(id string) error {
+	defer s.events.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeObject(id)
+}
+
+func (s *Simulation) removeObject(id string) error {
+	if _, exists := s.objects[id]; !exists {
+		return fmt.Errorf("%w: object with ID %s does not exist", ErrNotFound, id)
+	}
+	delete(s.objects, id)
+	delete(s.order, id)
+	delete(s.sensors, id)
+	delete(s.targets, id)
+	delete(s.obstacles, id)
+	delete(s.lastEstimates, id)
+	delete(s.lastErrors, id)
+	delete(s.errorExceeded, id)
+	for key := range s.inRange {
+		if key.sensorID == id || key.targetID == id {
+			delete(s.inRange, key)
+		}
+	}
+	s.fusion.forget(id)
+	delete(s.latestClutter, id)
+	if s.trackers != nil {
+		delete(s.trackers, id)
+	}
+	delete(s.assocTracks, id)
+	if s.smoothing != nil {
+		delete(s.smoothing.tracks, id)
+	}
+	delete(s.tracks, id)
+
+	kept := s.pending[:0]
+	for _, p := range s.pending {
+		if p.targetID != id && p.sensorID != id {
+			kept = append(kept, p)
+		}
+	}
+	s.pending = kept
+	if s.slam != nil {
+		s.slam.forget(id)
 	}
+	s.unschedule(id, true, true)
+	s.events.emit(Event{Kind: EventObjectRemoved, Time: s.simulationTime, ObjectID: id})
 	return nil
 }
 
-// AddRandomSensor adds a sensor at a random position within bounds.
+// AddRandomSensor adds a sensor at a random position within the world (see SetWorld).
 func (s *Simulation) AddRandomSensor(radius float64, noise NoiseFunction) error {
-	pos, err := common.NewRandomVector(s.dimension, s.bounds)
+	defer s.events.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pos, err := s.randomPosition()
 	if err != nil {
 		return fmt.Errorf("failed to generate random position for sensor: %w", err)
 	}
 	sensor := NewSensor(pos, radius, noise) // NewSensor handles nil noise
-	return s.AddObject(sensor)
+	return s.addObject(sensor)
 }
 
-// AddRandomTarget adds a target at a random position within bounds.
+// AddRandomTarget adds a target at a random position within the world (see SetWorld).
 func (s *Simulation) AddRandomTarget() error {
-	pos, err := common.NewRandomVector(s.dimension, s.bounds)
+	defer s.events.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pos, err := s.randomPosition()
 	if err != nil {
 		return fmt.Errorf("failed to generate random position for target: %w", err)
 	}
 	target := NewTarget(pos)
-	return s.AddObject(target)
+	return s.addObject(target)
 }
 
 // GetObject returns an object by its ID.
 func (s *Simulation) GetObject(id string) (SimulationObject, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	obj, exists := s.objects[id]
 	return obj, exists
 }
 
-// GetSensors returns a slice of all sensors.
+// GetSensors returns a slice of all sensors in the order they were added.
 func (s *Simulation) GetSensors() []*Sensor {
-	sensors := make([]*Sensor, 0, len(s.sensors))
-	for _, sen := range s.sensors {
-		sensors = append(sensors, sen)
-	}
-	return sensors
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedSensors()
 }
 
-// GetTargets returns a slice of all targets.
+// GetTargets returns a slice of all targets in the order they were added.
 func (s *Simulation) GetTargets() []*Target {
-	targets := make([]*Target, 0, len(s.targets))
-	for _, tar := range s.targets {
-		targets = append(targets, tar)
-	}
-	return targets
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedTargets()
 }
 
 // GetLastEstimate returns the last calculated position estimate and residual for a target.
 func (s *Simulation) GetLastEstimate(targetID string) (multilateration.Solution, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	sol, ok := s.lastEstimates[targetID]
 	return sol, ok
 }
 
 // GetLastLocalizationError returns the last calculated localization error distance for a target.
 func (s *Simulation) GetLastLocalizationError(targetID string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	errVal, ok := s.lastErrors[targetID]
 	return errVal, ok
 }
 
 // GetAllObjects returns a slice of all simulation objects.
 func (s *Simulation) GetAllObjects() []SimulationObject {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	all := make([]SimulationObject, 0, len(s.objects))
 	for _, obj := range s.objects {
 		all = append(all, obj)
@@ -135,169 +339,383 @@ func (s *Simulation) GetAllObjects() []SimulationObject {
 	return all
 }
 
+// SetOutputFormat overrides the number format used by LogCurrentState and PrintState.
+func (s *Simulation) SetOutputFormat(f common.Format) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.format = &f
+}
+
+// outputFormat returns the format for this simulation's log output.
+func (s *Simulation) outputFormat() common.Format {
+	if s.format != nil {
+		return *s.format
+	}
+	return common.DefaultFormat()
+}
+
+// SetSolver replaces the position solver used for every target, e.g.
+// multilateration.RobustSolver(...) when outliers are expected. nil restores the default,
+// which solves on reusable workspaces (see multilateration.Solver).
+func (s *Simulation) SetSolver(solver multilateration.SolveFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.customSolver = solver != nil
+	if solver == nil {
+		solver = multilateration.SolveLeastSquares
+	}
+	s.solver = solver
+}
+
+// Metrics returns the registry with the runtime counters of this simulation.
+func (s *Simulation) Metrics() *metrics.Registry {
+	return s.metrics
+}
+
 // GetCurrentTime returns the current simulation time.
 func (s *Simulation) GetCurrentTime() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.simulationTime
 }
 
 // Step performs one step of the simulation: updates objects and attempts localization.
-func (s *Simulation) Step(deltaTime float64) {
+// The returned report lists per-target outcomes and measurement errors; it is also
+// passed to all handlers registered with OnStep.
+func (s *Simulation) Step(deltaTime float64) StepReport {
+	report, handlers := s.step(deltaTime)
+	s.publish(report.State)
+	s.events.flush()
+	for _, handler := range handlers {
+		handler(report)
+	}
+	return report
+}
+
+// step advances the scene under the lock and returns the report together with the
+// handlers to notify once the lock is released.
+func (s *Simulation) step(deltaTime float64) (StepReport, []StepHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.simulationTime += deltaTime
+	s.tick++
+	s.lastStep = deltaTime
+
+	var previousPositions map[string]common.Vector
+	if s.odometryEnabled() {
+		previousPositions = make(map[string]common.Vector, len(s.targets))
+		for id, tar := range s.targets {
+			previousPositions[id] = tar.GetPosition().Clone()
+		}
+	}
 
-	// 1. Update all objects (move targets, etc.)
+	// 1. Update all objects (move targets, etc.), then let scheduled objects enter or leave
+	s.steerManeuvers(s.simulationTime-deltaTime, s.simulationTime)
 	for _, obj := range s.objects {
+		var previous common.Vector
+		if s.world != nil {
+			previous = obj.GetPosition().Clone()
+		}
 		obj.Update(deltaTime, s.bounds)
+		if s.world != nil {
+			s.confine(obj, previous)
+		}
+	}
+	s.holdManeuvers()
+	if s.boundary == BoundaryAbsorb {
+		s.absorbEscaped()
 	}
+	s.applySchedule()
+	s.updateFailures(deltaTime)
 
-	// 2. Measurement Phase & Multilateration Phase (for each target)
-	for _, tar := range s.targets {
+	report := StepReport{Time: s.simulationTime, Targets: make([]TargetReport, 0, len(s.targets))}
+
+	// 2. Measurement Phase: sensors measure on their own schedule, results arrive after their latency
+	emissions := s.advanceEmissions(s.simulationTime-deltaTime, s.simulationTime)
+	s.collectMeasurements(&report, emissions)
+	s.deliverMeasurements()
+
+	// 3. Multilateration Phase (for each target), using the latest delivered measurements.
+	// Inputs are gathered in order, the solves run in parallel, the results are merged in order.
+	var associated map[string][]multilateration.Measurement
+	if s.associator != nil {
+		associated = s.associate()
+	}
+	jobs := make([]localizationJob, 0, len(s.targets))
+	for _, tar := range s.sortedTargets() {
 		targetID := tar.GetID()
-		targetMeasurements := make([]multilateration.Measurement, 0, len(s.sensors))
-
-		for _, sen := range s.sensors {
-			dist, inRange, err := sen.MeasureDistance(tar)
-			if err != nil {
-				// Log error internally or decide how to handle; for now, skip this measurement
-				fmt.Printf("    [Internal Log - Target %s] Error measuring from %s: %v\n", targetID, sen.GetID(), err)
-				continue
+		targetMeasurements := s.availableMeasurements(targetID)
+		if associated != nil {
+			targetMeasurements = associated[targetID]
+		}
+		if s.slam != nil {
+			targetMeasurements = s.slam.believedMeasurements(targetMeasurements)
+		}
+		gated := 0
+		if s.gating != nil {
+			targetMeasurements, gated = s.gateMeasurements(targetID, targetMeasurements)
+		}
+		job := localizationJob{target: tar, measurements: targetMeasurements, outside: s.outsideRegion(tar), gated: gated}
+		enough := !job.outside && len(targetMeasurements) >= s.dimension+1
+		if enough {
+			due, triggered := s.fusion.due(targetID, targetMeasurements, s.simulationTime)
+			job.deferred, job.triggered = !due, triggered
+		}
+		if !job.outside && !job.deferred {
+			s.selectMeasurements(&job)
+		}
+		job.solve = enough && !job.deferred
+		jobs = append(jobs, job)
+	}
+	workers := s.workers()
+	solve := s.solverForWorkers(workers)
+	parallelForWorkers(len(jobs), workers, func(worker, i int) {
+		if job := &jobs[i]; job.solve {
+			job.solution, job.err = solve(worker, job.measurements)
+			if job.all != nil {
+				job.allSolution, job.allErr = solve(worker, job.all)
 			}
-			if inRange {
-				targetMeasurements = append(targetMeasurements, multilateration.Measurement{
-					SensorPosition: sen.GetPosition(),
-					Distance:       dist,
-				})
+		}
+	})
+	if s.peerRanging != nil {
+		s.localizeCooperatively(jobs)
+	}
+	for _, job := range jobs {
+		tar, targetMeasurements := job.target, job.measurements
+		targetID := tar.GetID()
+		targetReport := TargetReport{TargetID: targetID, NumMeasurements: len(targetMeasurements), Gated: job.gated, Measurements: targetMeasurements}
+		if job.outside {
+			s.metrics.Inc(MetricOutsideRegion)
+			targetReport.Outcome = OutcomeOutsideRegion
+			s.lastEstimates[targetID] = multilateration.Solution{Position: nil, ResidualError: -1}
+			s.lastErrors[targetID] = -1.0
+			delete(s.trackers, targetID) // A new track is initiated on entry
+			s.updateTrackStatus(targetID, OutcomeOutsideRegion, job.solution)
+			report.Targets = append(report.Targets, targetReport)
+			continue
+		}
+		if job.deferred {
+			// Not due at the fusion center: the previous estimate stands
+			s.metrics.Inc(MetricFusionDeferred)
+			targetReport.Outcome = OutcomeDeferred
+			if estimate := s.lastEstimates[targetID].Position; estimate != nil {
+				if localizationErr, err := s.localizationError(tar.GetPosition(), estimate); err == nil {
+					s.lastErrors[targetID] = localizationErr
+				}
 			}
+			report.Targets = append(report.Targets, targetReport)
+			continue
 		}
-
-		requiredMeasurements := s.dimension + 1
-		if len(targetMeasurements) >= requiredMeasurements {
-			solution, err := multilateration.SolveLeastSquares(targetMeasurements, s.dimension)
+		if job.solve {
+			s.metrics.Inc(MetricSolverInvocations)
+			s.recordFusionSolve(job)
+			solution, err := job.solution, job.err
 			if err == nil {
+				targetReport.Outcome = OutcomeLocalized
 				s.lastEstimates[targetID] = solution
+				if s.slam != nil {
+					s.slam.record(targetID, s.tick, solution.Position, targetMeasurements)
+				}
 				truePos := tar.GetPosition()
-				localizationErr, distErr := multilateration.CalculateLocalizationError(truePos, solution.Position)
+				localizationErr, distErr := s.localizationError(truePos, solution.Position)
 				if distErr == nil {
 					s.lastErrors[targetID] = localizationErr
+					s.recordSelectionImpact(job)
 				} else {
 					s.lastErrors[targetID] = -1.0 // Error calculating error
 				}
 			} else {
 				// Localization failed
+				s.log().Warn("localization failed", "target", targetID, "tick", s.tick, "measurements", len(targetMeasurements), "err", err)
+				s.metrics.Inc(MetricSolverFailures)
+				if errors.Is(err, multilateration.ErrDegenerateGeometry) {
+					s.metrics.Inc(MetricDegenerateGeometry)
+				}
+				targetReport.Outcome = OutcomeSolverFailed
+				targetReport.Err = err
 				s.lastEstimates[targetID] = multilateration.Solution{Position: nil, ResidualError: -1}
 				s.lastErrors[targetID] = -1.0
-				// fmt.Printf("    [Internal Log - Target %s] Localization failed: %v\n", targetID, err)
 			}
 		} else {
 			// Insufficient measurements
+			s.metrics.Inc(MetricInsufficientMeasurements)
+			targetReport.Outcome = OutcomeInsufficientMeasurements
 			s.lastEstimates[targetID] = multilateration.Solution{Position: nil, ResidualError: -1}
 			s.lastErrors[targetID] = -1.0
 		}
+		if s.trackerFactory != nil {
+			targetReport.TrackerErr = s.updateTracker(tar, targetMeasurements, previousPositions[targetID])
+		}
+		if s.assocTracks != nil {
+			s.updateAssocTrack(targetID)
+		}
+		s.updateTrackStatus(targetID, targetReport.Outcome, job.solution)
+		s.emitLocalization(targetReport)
+		report.Targets = append(report.Targets, targetReport)
+	}
+	if s.distributed != nil {
+		s.runDistributed(&report)
+	}
+	if s.slam != nil {
+		report.SLAMErr = s.refineSLAM()
+	}
+	if s.relative != nil {
+		s.buildRelativeMap()
 	}
+	s.metrics.Inc(MetricSteps)
+	s.metrics.Set(MetricSimulationTime, s.simulationTime)
+	s.metrics.Set(MetricStepDuration, deltaTime)
+	if deltaTime > 0 {
+		s.metrics.Set(MetricStepRate, 1/deltaTime)
+	}
+	s.metrics.Set(MetricSensors, float64(len(s.sensors)))
+	s.metrics.Set(MetricTargets, float64(len(s.targets)))
+	s.countTracking()
+	report.State = s.snapshot()
+	s.history.record(report.State)
+	if s.smoothing != nil {
+		s.smooth()
+	}
+
+	handlers := make([]StepHandler, len(s.stepHandlers))
+	copy(handlers, s.stepHandlers)
+	return report, handlers
 }
 
-// LogCurrentState prints the current state of object positions and localization attempts.
+// sortedTargets returns targets in insertion order so that step results are reproducible
+// (IDs are random, so ordering by ID would differ between runs with the same seeds).
+func (s *Simulation) sortedTargets() []*Target {
+	targets := make([]*Target, 0, len(s.targets))
+	for _, tar := range s.targets {
+		targets = append(targets, tar)
+	}
+	sort.Slice(targets, func(i, j int) bool { return s.order[targets[i].GetID()] < s.order[targets[j].GetID()] })
+	return targets
+}
+
+// sortedSensors returns sensors in insertion order so that step results are reproducible.
+func (s *Simulation) sortedSensors() []*Sensor {
+	sensors := make([]*Sensor, 0, len(s.sensors))
+	for _, sen := range s.sensors {
+		sensors = append(sensors, sen)
+	}
+	sort.Slice(sensors, func(i, j int) bool { return s.order[sensors[i].GetID()] < s.order[sensors[j].GetID()] })
+	return sensors
+}
+
+// LogCurrentState logs the positions of all objects (debug level) and the latest
+// localization result of every target: info when localized, warning otherwise.
 func (s *Simulation) LogCurrentState() {
-	fmt.Println("  Updated Positions:")
-	for _, sen := range s.sensors { // Log sensors first
-		fmt.Printf("    %s\n", sen)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f := s.outputFormat()
+	logger := s.log().With("tick", s.tick, "sim_time", s.simulationTime)
+	for _, sen := range s.sortedSensors() {
+		logger.Debug("sensor", "sensor", sen.GetID(), "position", f.Vector(sen.GetPosition()), "radius", sen.DetectionRadius())
 	}
-	for _, tar := range s.targets { // Then targets
-		fmt.Printf("    %s\n", tar)
+	for _, tar := range s.sortedTargets() {
+		logger.Debug("target", "target", tar.GetID(), "position", f.Vector(tar.GetPosition()), "velocity", f.Vector(tar.GetVelocity()))
 	}
-	fmt.Println("  ---")
-	fmt.Println("  Localization Results:")
-	for _, tar := range s.targets {
+	for _, tar := range s.sortedTargets() {
 		targetID := tar.GetID()
 		truePos := tar.GetPosition()
 		solution, estOk := s.lastEstimates[targetID]
 		locErr, errOk := s.lastErrors[targetID]
 
-		// Reconstruct measurement details for logging (optional, can be verbose)
-		measurementDetails := []string{}
-		numActualMeasurements := 0
-		for _, sen := range s.sensors {
-			dist, inRange, _ := sen.MeasureDistance(tar) // Ignoring error here for brevity
-			if inRange {
-				numActualMeasurements++
-				trueDist, _ := sen.GetPosition().Distance(tar.GetPosition())
-				measurementDetails = append(measurementDetails, fmt.Sprintf("%s(d=%.2f|t=%.2f)", sen.GetID(), dist, trueDist))
+		// Measurement details as seen by the solver (verbose, debug level only)
+		available := s.availableMeasurements(targetID)
+		if logger.Enabled(context.Background(), slog.LevelDebug) {
+			for _, m := range available {
+				trueDist, _ := m.SensorPosition.Distance(truePos)
+				logger.Debug("measurement", "target", targetID, "sensor", m.SensorID,
+					"distance", f.Number(m.Distance), "true_distance", f.Number(trueDist), "age", s.simulationTime-m.Timestamp)
 			}
 		}
-		logPrefix := fmt.Sprintf("    Target %s (%d measurements [%s]):", targetID, numActualMeasurements, strings.Join(measurementDetails, ", "))
 
+		targetLog := logger.With("target", targetID, "measurements", len(available))
 		if estOk && solution.Position != nil {
-			errorStr := "N/A"
+			attrs := []any{"true", f.Vector(truePos), "estimate", f.Vector(solution.Position),
+				"residual", f.Number(solution.ResidualError), "gdop", solution.GDOP}
 			if errOk && locErr >= 0 {
-				errorStr = fmt.Sprintf("%.3f", locErr)
+				attrs = append(attrs, "error", f.Float(locErr))
 			}
-			fmt.Printf("%s True Pos: %s -> Est Pos: %s (Error: %s, Residual: %.3f)\n",
-				logPrefix, truePos, solution.Position, errorStr, solution.ResidualError)
+			targetLog.Info("localized", attrs...)
+		} else if required := s.dimension + 1; len(available) < required {
+			targetLog.Warn("insufficient measurements", "required", required)
 		} else {
-			requiredMeasurements := s.dimension + 1
-			if numActualMeasurements < requiredMeasurements {
-				fmt.Printf("%s Insufficient measurements (%d/%d) for localization.\n",
-					logPrefix, numActualMeasurements, requiredMeasurements)
-			} else {
-				fmt.Printf("%s Localization failed or no estimate available.\n", logPrefix)
-			}
+			targetLog.Warn("no estimate available")
 		}
 	}
 }
 
-// PrintState prints the initial/final summary state of the simulation.
+// PrintState logs a summary of the simulation: every object and the last estimate
+// of every target, at info level.
 func (s *Simulation) PrintState() {
-	fmt.Println("--- Simulation State Summary ---")
-	fmt.Printf("Time: %.2fs, Dimension: %d\n", s.simulationTime, s.dimension)
-	fmt.Println("Sensors:")
-	if len(s.sensors) == 0 {
-		fmt.Println("  None")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f := s.outputFormat()
+	logger := s.log()
+	logger.Info("simulation state", "sim_time", s.simulationTime, "dimension", s.dimension,
+		"sensors", len(s.sensors), "targets", len(s.targets))
+	for _, sen := range s.sortedSensors() {
+		logger.Info("sensor", "sensor", sen.GetID(), "position", f.Vector(sen.GetPosition()), "radius", sen.DetectionRadius())
 	}
-	for _, sen := range s.sensors {
-		fmt.Printf("  %s\n", sen)
-	}
-	fmt.Println("Targets:")
-	if len(s.targets) == 0 {
-		fmt.Println("  None")
-	}
-	for _, tar := range s.targets {
-		lastEst, okEst := s.GetLastEstimate(tar.GetID())
-		lastErr, okErr := s.GetLastLocalizationError(tar.GetID())
-		estimateStr := "No estimate yet."
-		if okEst && lastEst.Position != nil {
-			errStr := "N/A"
-			if okErr && lastErr >= 0 {
-				errStr = fmt.Sprintf("%.3f", lastErr)
+	for _, tar := range s.sortedTargets() {
+		attrs := []any{"target", tar.GetID(), "position", f.Vector(tar.GetPosition())}
+		if lastEst, ok := s.lastEstimates[tar.GetID()]; ok && lastEst.Position != nil {
+			attrs = append(attrs, "estimate", f.Vector(lastEst.Position), "residual", f.Number(lastEst.ResidualError))
+			if lastErr, ok := s.lastErrors[tar.GetID()]; ok && lastErr >= 0 {
+				attrs = append(attrs, "error", f.Float(lastErr))
 			}
-			estimateStr = fmt.Sprintf("Last Est: %s (Err: %s, Resid: %.3f)", lastEst.Position, errStr, lastEst.ResidualError)
 		}
-		fmt.Printf("  %s | %s\n", tar, estimateStr)
+		logger.Info("target", attrs...)
 	}
-	fmt.Println("-----------------------------")
 }
 
 // Run (old version, kept for reference or if needed for non-Ebiten runs)
 func (s *Simulation) RunLegacy(numSteps int) {
-	fmt.Printf("Starting simulation: Dimension=%d, Bounds=%v, TickDuration=%s\n", s.dimension, s.bounds, s.tickDuration)
-	fmt.Println("Initial State:")
+	logger := s.Logger()
+	logger.Info("starting simulation", "dimension", s.dimension, "bounds", s.bounds, "tick", s.GetTickDuration())
 	s.PrintState()
 
-	deltaTime := s.tickDuration.Seconds()
+	deltaTime := s.GetTickDuration().Seconds()
 
 	for i := 0; i < numSteps; i++ {
-		fmt.Printf("\n--- Simulation Step %d (Time: %.2fs) ---\n", i+1, s.GetCurrentTime())
 		s.Step(deltaTime)
 		s.LogCurrentState()
 		// time.Sleep(50 * time.Millisecond) // Optional delay
 	}
 
-	fmt.Println("\n--- Simulation Finished ---")
+	logger.Info("simulation finished", "steps", numSteps)
 	s.PrintState()
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 func (s *Simulation) GetDimension() int {
 	return s.dimension
 }
+
+// GetBounds returns a copy of the simulation bounds [min0, max0, min1, max1, ...].
+func (s *Simulation) GetBounds() []float64 {
+	bounds := make([]float64, len(s.bounds))
+	copy(bounds, s.bounds)
+	return bounds
+}
+
+// GetTickDuration returns the nominal step duration.
+func (s *Simulation) GetTickDuration() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tickDuration
+}
+
+// SetTickDuration changes the nominal step duration, e.g. to trade accuracy for speed
+// while running. It is used by NextStepDuration unless adaptive stepping is enabled.
+func (s *Simulation) SetTickDuration(tick time.Duration) error {
+	if tick <= 0 {
+		return fmt.Errorf("%w: tick duration must be positive, got %s", ErrOutOfRange, tick)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickDuration = tick
+	return nil
+}
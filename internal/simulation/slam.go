@@ -0,0 +1,217 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"sort"
+)
+
+// SLAMConfig configures the experimental range-only SLAM mode.
+type SLAMConfig struct {
+	AnchorSigma   float64 // Error of the coarse anchor positions known to the estimator (units)
+	RangeSigma    float64 // Assumed standard deviation of the range measurements (units)
+	Window        int     // Number of most recent ticks kept for the joint estimate
+	MaxIterations int     // Gauss-Newton iterations per step, 0 means 20
+}
+
+// slamState keeps the estimator's belief about the anchors and the sliding window of poses.
+type slamState struct {
+	config  SLAMConfig
+	priors  map[string]common.Vector // sensorID -> coarse position (never changes)
+	anchors map[string]common.Vector // sensorID -> current refined estimate
+	batches []slamBatch              // Oldest first
+	rng     *randStream              // Placement stream for the anchor priors
+}
+
+// slamBatch is one localized pose of a target together with the ranges that produced it.
+type slamBatch struct {
+	targetID string
+	tick     int64
+	pose     common.Vector
+	ranges   map[string]float64 // sensorID -> distance
+}
+
+// EnableRangeOnlySLAM switches the simulation into range-only SLAM mode: the sensor
+// (anchor) positions are only known up to Gaussian noise of AnchorSigma, targets are
+// localized against the believed anchor positions, and every step a joint estimator
+// refines both the anchors and the target trajectories over the last Window ticks.
+// Anchors are assumed static; mobile sensors are treated as if they did not move.
+func (s *Simulation) EnableRangeOnlySLAM(config SLAMConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config.AnchorSigma <= 0 || config.RangeSigma <= 0 {
+		return fmt.Errorf("%w: SLAM sigmas must be positive, got anchor %f, range %f", ErrOutOfRange, config.AnchorSigma, config.RangeSigma)
+	}
+	if config.Window < 1 {
+		return fmt.Errorf("%w: SLAM window must be at least 1 tick, got %d", ErrOutOfRange, config.Window)
+	}
+	if config.MaxIterations == 0 {
+		config.MaxIterations = 20
+	}
+	s.slam = &slamState{
+		config:  config,
+		priors:  make(map[string]common.Vector),
+		anchors: make(map[string]common.Vector),
+		rng:     newStream(s.seeds.Placement, slamStream),
+	}
+	return nil
+}
+
+// DisableRangeOnlySLAM returns to localization against the true sensor positions.
+func (s *Simulation) DisableRangeOnlySLAM() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slam = nil
+}
+
+// GetAnchorEstimate returns the SLAM estimate of a sensor position.
+// ok is false when SLAM is disabled or the sensor has not produced measurements yet.
+func (s *Simulation) GetAnchorEstimate(sensorID string) (common.Vector, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.slam == nil {
+		return nil, false
+	}
+	pos, ok := s.slam.anchors[sensorID]
+	if !ok {
+		return nil, false
+	}
+	return pos.Clone(), true
+}
+
+// GetSLAMTrajectory returns the refined poses of a target within the current window, oldest first.
+func (s *Simulation) GetSLAMTrajectory(targetID string) []common.Vector {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.slam == nil {
+		return nil
+	}
+	var poses []common.Vector
+	for _, b := range s.slam.batches {
+		if b.targetID == targetID {
+			poses = append(poses, b.pose.Clone())
+		}
+	}
+	return poses
+}
+
+// forget drops a removed sensor or target from the estimator.
+func (st *slamState) forget(id string) {
+	delete(st.priors, id)
+	delete(st.anchors, id)
+	kept := st.batches[:0]
+	for _, b := range st.batches {
+		if b.targetID == id {
+			continue
+		}
+		delete(b.ranges, id)
+		kept = append(kept, b)
+	}
+	st.batches = kept
+}
+
+// believedMeasurements replaces the true sensor positions by the estimator's anchor beliefs.
+// New sensors get a coarse prior around their true position on first sight.
+func (st *slamState) believedMeasurements(measurements []multilateration.Measurement) []multilateration.Measurement {
+	believed := make([]multilateration.Measurement, len(measurements))
+	for i, m := range measurements {
+		anchor, ok := st.anchors[m.SensorID]
+		if !ok {
+			prior := m.SensorPosition.Clone()
+			for k := range prior {
+				prior[k] += st.rng.NormFloat64() * st.config.AnchorSigma
+			}
+			st.priors[m.SensorID] = prior
+			anchor = prior.Clone()
+			st.anchors[m.SensorID] = anchor
+		}
+		believed[i] = m
+		believed[i].SensorPosition = anchor.Clone()
+	}
+	return believed
+}
+
+// record adds a localized pose to the window and drops batches older than the window.
+func (st *slamState) record(targetID string, tick int64, pose common.Vector, measurements []multilateration.Measurement) {
+	ranges := make(map[string]float64, len(measurements))
+	for _, m := range measurements {
+		ranges[m.SensorID] = m.Distance
+	}
+	st.batches = append(st.batches, slamBatch{targetID: targetID, tick: tick, pose: pose.Clone(), ranges: ranges})
+
+	oldest := tick - int64(st.config.Window) + 1
+	keep := 0
+	for keep < len(st.batches) && st.batches[keep].tick < oldest {
+		keep++
+	}
+	st.batches = st.batches[keep:]
+}
+
+// refine runs the joint estimator over the window and updates anchors and poses in place.
+func (st *slamState) refine() error {
+	if len(st.batches) == 0 {
+		return nil
+	}
+	sensorIDs := make([]string, 0, len(st.priors))
+	for id := range st.priors {
+		sensorIDs = append(sensorIDs, id)
+	}
+	sort.Strings(sensorIDs)
+	anchorIndex := make(map[string]int, len(sensorIDs))
+
+	problem := multilateration.SLAMProblem{AnchorSigma: st.config.AnchorSigma, RangeSigma: st.config.RangeSigma}
+	initialAnchors := make([]common.Vector, len(sensorIDs))
+	for i, id := range sensorIDs {
+		anchorIndex[id] = i
+		problem.AnchorPriors = append(problem.AnchorPriors, st.priors[id])
+		initialAnchors[i] = st.anchors[id]
+	}
+	for poseIdx, b := range st.batches {
+		problem.InitialPoses = append(problem.InitialPoses, b.pose)
+		ids := make([]string, 0, len(b.ranges))
+		for id := range b.ranges {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			problem.Observations = append(problem.Observations, multilateration.RangeObservation{
+				Anchor: anchorIndex[id], Pose: poseIdx, Distance: b.ranges[id],
+			})
+		}
+	}
+
+	result, err := multilateration.SolveRangeOnlySLAM(problem, st.config.MaxIterations)
+	if err != nil {
+		return err
+	}
+	for i, id := range sensorIDs {
+		st.anchors[id] = result.Anchors[i]
+	}
+	for i := range st.batches {
+		st.batches[i].pose = result.Poses[i]
+	}
+	return nil
+}
+
+// refineSLAM updates the SLAM estimate and replaces the per-step estimates of the
+// targets localized in this tick by their jointly refined poses.
+func (s *Simulation) refineSLAM() error {
+	if err := s.slam.refine(); err != nil {
+		return fmt.Errorf("range-only SLAM: %w", err)
+	}
+	for _, b := range s.slam.batches {
+		if b.tick != s.tick {
+			continue
+		}
+		solution := s.lastEstimates[b.targetID]
+		solution.Position = b.pose.Clone()
+		s.lastEstimates[b.targetID] = solution
+		if tar, ok := s.targets[b.targetID]; ok {
+			if locErr, err := multilateration.CalculateLocalizationError(tar.GetPosition(), b.pose); err == nil {
+				s.lastErrors[b.targetID] = locErr
+			}
+		}
+	}
+	return nil
+}
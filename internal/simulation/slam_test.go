@@ -0,0 +1,68 @@
+package simulation_test
+
+import (
+	"errors"
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"testing"
+	"time"
+)
+
+func TestEnableRangeOnlySLAMInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config simulation.SLAMConfig
+	}{
+		{"anchor sigma 0", simulation.SLAMConfig{RangeSigma: 0.1, Window: 5}},
+		{"negative range sigma", simulation.SLAMConfig{AnchorSigma: 3, RangeSigma: -0.1, Window: 5}},
+		{"window 0", simulation.SLAMConfig{AnchorSigma: 3, RangeSigma: 0.1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := newTestSimulation(t, time.Second, nil, cornerAnchors...)
+			if err := sim.EnableRangeOnlySLAM(tt.config); !errors.Is(err, simulation.ErrOutOfRange) {
+				t.Errorf("got %v, want ErrOutOfRange", err)
+			}
+			if _, ok := sim.GetAnchorEstimate("anchor-0"); ok || sim.GetSLAMTrajectory("mover") != nil {
+				t.Error("SLAM mode was enabled")
+			}
+		})
+	}
+}
+
+func TestRangeOnlySLAMRefinesAnchors(t *testing.T) {
+	sim := newTestSimulation(t, time.Second, simulation.GaussianNoise(0.05), cornerAnchors...)
+	sim.SetSeeds(simulation.NewSeeds(4))
+	mover := simulation.NewTargetWithID("mover", common.Vector{-30, -20}, simulation.NewConstantVelocityMotion())
+	_ = mover.SetVelocity(common.Vector{6, 4})
+	addObjects(t, sim, mover)
+	if err := sim.EnableRangeOnlySLAM(simulation.SLAMConfig{AnchorSigma: 3, RangeSigma: 0.05, Window: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sim.GetAnchorEstimate("anchor-0"); ok {
+		t.Error("anchor estimate before any range")
+	}
+	anchorError := func() float64 {
+		sum := 0.0
+		for i, pos := range cornerAnchors {
+			est, ok := sim.GetAnchorEstimate(fmt.Sprint("anchor-", i))
+			if !ok {
+				t.Fatalf("no estimate of anchor-%d", i)
+			}
+			d, _ := est.Distance(pos)
+			sum += d
+		}
+		return sum / float64(len(cornerAnchors))
+	}
+
+	sim.Step(1)
+	first := anchorError()
+	stepN(sim, 9, 1)
+	if last := anchorError(); last >= first {
+		t.Errorf("mean anchor error %.3f after 10 steps, %.3f after the first", last, first)
+	}
+	if poses := len(sim.GetSLAMTrajectory("mover")); poses != 5 {
+		t.Errorf("%d poses in the window, want 5", poses)
+	}
+}
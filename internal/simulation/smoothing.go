@@ -0,0 +1,169 @@
+package simulation
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/tracking"
+)
+
+// Metric names of fixed-lag smoothing. Both errors are taken at the step Lag steps ago,
+// so they compare the two tracks on the same instants.
+const (
+	MetricSmoothedError     = "smoothed_error"     // Gauge: mean error of the smoothed estimates
+	MetricRealtimeError     = "realtime_error"     // Gauge: mean error of the real-time estimates of the same step
+	MetricSmoothedEstimates = "smoothed_estimates" // Estimates revised by the smoother
+)
+
+// FixedLagSmoothing revises the estimate of every target Lag steps after it was made,
+// using the estimates of the steps since (Rauch-Tung-Striebel smoothing with a
+// constant-velocity model, see tracking.SmoothRTS).
+type FixedLagSmoothing struct {
+	Lag              int     `json:"lag"`               // Steps between an estimate and its revision
+	ProcessSigma     float64 `json:"process_sigma"`     // White acceleration noise (units/s^2 per sqrt(Hz))
+	MeasurementSigma float64 `json:"measurement_sigma"` // Position noise per axis of estimates without a covariance
+	InitialSpeed     float64 `json:"initial_speed"`     // Standard deviation of the unknown initial velocity per axis
+}
+
+// DefaultFixedLagSmoothing revises estimates 5 steps later with tracking.DefaultRTSConfig.
+func DefaultFixedLagSmoothing() FixedLagSmoothing {
+	c := tracking.DefaultRTSConfig()
+	return FixedLagSmoothing{Lag: 5, ProcessSigma: c.ProcessSigma, MeasurementSigma: c.MeasurementSigma, InitialSpeed: c.InitialSpeed}
+}
+
+// Validate checks the parameters.
+func (c FixedLagSmoothing) Validate() error {
+	if c.Lag < 1 {
+		return fmt.Errorf("%w: smoothing lag must be at least 1 step, got %d", ErrOutOfRange, c.Lag)
+	}
+	return c.rtsConfig().Validate()
+}
+
+func (c FixedLagSmoothing) rtsConfig() tracking.RTSConfig {
+	return tracking.RTSConfig{ProcessSigma: c.ProcessSigma, MeasurementSigma: c.MeasurementSigma, InitialSpeed: c.InitialSpeed}
+}
+
+// smoothingWindow is the history retention smoothing needs: the lag plus as many steps
+// before the revised one, so the filter has settled when it reaches it.
+func (c FixedLagSmoothing) smoothingWindow() int {
+	return 2 * (c.Lag + 1)
+}
+
+// smoothingState holds the configuration and the smoothed tracks.
+type smoothingState struct {
+	config FixedLagSmoothing
+	tracks map[string][]HistorySample // targetID -> smoothed estimates, oldest first
+}
+
+// SetFixedLagSmoothing enables fixed-lag smoothing, nil disables it. It is built on the
+// history (see SetHistoryLength), whose retention it raises to 2*(Lag+1) steps if
+// shorter; the forward filter starts at the oldest retained step.
+func (s *Simulation) SetFixedLagSmoothing(config *FixedLagSmoothing) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if config == nil {
+		s.smoothing = nil
+		return nil
+	}
+	if window := config.smoothingWindow(); s.history.length < window {
+		s.history.resize(window)
+	}
+	s.smoothing = &smoothingState{config: *config, tracks: make(map[string][]HistorySample)}
+	return nil
+}
+
+// FixedLagSmoothing returns the smoothing configuration, nil if it is off.
+func (s *Simulation) FixedLagSmoothing() *FixedLagSmoothing {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.smoothing == nil {
+		return nil
+	}
+	c := s.smoothing.config
+	return &c
+}
+
+// SmoothedTrack returns the smoothed estimates of a target, oldest first, each Lag steps
+// behind the real-time one of History. It keeps as many steps as the history.
+func (s *Simulation) SmoothedTrack(targetID string) []HistorySample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.smoothing == nil {
+		return nil
+	}
+	track := make([]HistorySample, len(s.smoothing.tracks[targetID]))
+	for i, sample := range s.smoothing.tracks[targetID] {
+		sample.Position, sample.Estimate = sample.Position.Clone(), sample.Estimate.Clone()
+		track[i] = sample
+	}
+	return track
+}
+
+// smooth revises the estimates of the step Lag steps ago from the history. The caller
+// holds the lock.
+func (s *Simulation) smooth() {
+	c := s.smoothing.config
+	states := s.history.ordered()
+	at := len(states) - 1 - c.Lag
+	if at < 0 {
+		return
+	}
+	revised := states[at]
+	var smoothedSum, realtimeSum float64
+	count := 0
+	for _, target := range revised.Targets {
+		var fixes []tracking.PositionFix
+		index := -1 // Of the revised step among the fixes
+		for i, st := range states {
+			tar, ok := st.Target(target.ID)
+			if !ok {
+				continue
+			}
+			if i == at {
+				index = len(fixes)
+			}
+			fixes = append(fixes, positionFix(st.Time, tar))
+		}
+		_, track, err := tracking.SmoothRTS(fixes, c.rtsConfig())
+		if err != nil || track[index] == nil {
+			continue // Not localized yet
+		}
+		smoothed := track[index]
+		sample := HistorySample{Time: revised.Time, Tick: revised.Tick, Position: target.Position.Clone(), Estimate: smoothed, Error: -1}
+		if e, err := s.localizationError(target.Position, smoothed); err == nil {
+			sample.Error = e
+			if target.Error >= 0 {
+				smoothedSum += e
+				realtimeSum += target.Error
+				count++
+			}
+		}
+		samples := append(s.smoothing.tracks[target.ID], sample)
+		if len(samples) > s.history.length {
+			samples = samples[len(samples)-s.history.length:]
+		}
+		s.smoothing.tracks[target.ID] = samples
+		s.metrics.Inc(MetricSmoothedEstimates)
+	}
+	if count > 0 {
+		s.metrics.Set(MetricSmoothedError, smoothedSum/float64(count))
+		s.metrics.Set(MetricRealtimeError, realtimeSum/float64(count))
+	}
+}
+
+// positionFix turns the estimate of a target in a step into a fix for the smoother, with
+// the variances of its covariance if known.
+func positionFix(time float64, tar TargetState) tracking.PositionFix {
+	fix := tracking.PositionFix{Time: time, Position: tar.Estimate.Position}
+	if cov := tar.Estimate.Covariance; len(cov) == len(fix.Position) && fix.Position != nil {
+		fix.Variance = common.NewVector(len(cov))
+		for i := range cov {
+			fix.Variance[i] = cov[i][i]
+		}
+	}
+	return fix
+}
@@ -0,0 +1,116 @@
+package simulation
+
+import (
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// SensorState is a copy of a sensor's state.
+type SensorState struct {
+	ID              string
+	Label           string // Empty if none
+	Position        common.Vector
+	Velocity        common.Vector
+	DetectionRadius float64 // 0 means unlimited
+	Status          SensorStatus
+}
+
+// TargetState is a copy of a target's state and its latest localization result.
+type TargetState struct {
+	ID       string
+	Label    string // Empty if none
+	Class    string // Empty if unclassified
+	Position common.Vector
+	Velocity common.Vector
+	Estimate multilateration.Solution // Position is nil if the target is not localized
+	Error    float64                  // Localization error, -1 if unavailable
+}
+
+// ObstacleState is a copy of an obstacle's state.
+type ObstacleState struct {
+	ID       string
+	Shape    Region  // *BoxRegion or *BallRegion; regions are never modified, so this is safe to share
+	NLOSBias float64 // 0 means the obstacle blocks measurements
+}
+
+// SimState is a consistent copy of the whole simulation at one instant. It shares no
+// memory with the simulation, so it can be read from any goroutine while stepping goes on.
+// The state of a step is shared by all handlers and subscribers and must not be modified.
+type SimState struct {
+	Time      float64
+	Tick      int64 // Number of steps performed
+	Dimension int
+	Sensors   []SensorState // In insertion order
+	Targets   []TargetState // In insertion order
+	Obstacles []ObstacleState
+}
+
+// Snapshot copies the current state under the simulation lock, so it never
+// observes a half-finished step.
+func (s *Simulation) Snapshot() SimState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot()
+}
+
+// snapshot copies the current state, the caller holds the lock.
+func (s *Simulation) snapshot() SimState {
+	state := SimState{Time: s.simulationTime, Tick: s.tick, Dimension: s.dimension}
+	for _, sen := range s.sortedSensors() {
+		state.Sensors = append(state.Sensors, SensorState{
+			ID:              sen.GetID(),
+			Label:           sen.Label(),
+			Position:        sen.GetPosition(),
+			Velocity:        sen.GetVelocity(),
+			DetectionRadius: sen.DetectionRadius(),
+			Status:          sen.Status(),
+		})
+	}
+	for _, tar := range s.sortedTargets() {
+		id := tar.GetID()
+		state.Targets = append(state.Targets, TargetState{
+			ID:       id,
+			Label:    tar.Label(),
+			Class:    tar.Class(),
+			Position: tar.GetPosition(),
+			Velocity: tar.GetVelocity(),
+			Estimate: cloneSolution(s.lastEstimates[id]),
+			Error:    s.lastErrors[id],
+		})
+	}
+	for _, obs := range s.sortedObstacles() {
+		state.Obstacles = append(state.Obstacles, ObstacleState{ID: obs.GetID(), Shape: obs.Shape(), NLOSBias: obs.NLOSBias()})
+	}
+	return state
+}
+
+// Target returns the state of a target by ID.
+func (st SimState) Target(id string) (TargetState, bool) {
+	for _, t := range st.Targets {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return TargetState{}, false
+}
+
+// cloneSolution deep-copies the slices of a solution.
+func cloneSolution(sol multilateration.Solution) multilateration.Solution {
+	if sol.Position != nil { // Clone would turn nil into an empty vector
+		sol.Position = sol.Position.Clone()
+	}
+	if sol.Velocity != nil {
+		sol.Velocity = sol.Velocity.Clone()
+	}
+	if sol.Weights != nil {
+		sol.Weights = append([]float64(nil), sol.Weights...)
+	}
+	if sol.Covariance != nil {
+		cov := make([][]float64, len(sol.Covariance))
+		for i, row := range sol.Covariance {
+			cov[i] = append([]float64(nil), row...)
+		}
+		sol.Covariance = cov
+	}
+	return sol
+}
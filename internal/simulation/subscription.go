@@ -0,0 +1,78 @@
+package simulation
+
+import "sync/atomic"
+
+// MetricStatesDropped counts states a subscriber missed because it fell behind.
+const MetricStatesDropped = "states_dropped"
+
+// Subscription delivers the SimState of every step over a channel, for consumers
+// (renderers, recorders, network publishers) that run on their own goroutine.
+//
+// Step never waits for a subscriber: when its buffer is full the oldest queued state
+// is discarded in favour of the newest one, so a slow consumer skips frames instead
+// of slowing the simulation down, and always catches up to the latest step.
+type Subscription struct {
+	sim     *Simulation
+	ch      chan SimState
+	dropped atomic.Int64
+}
+
+// Subscribe returns a subscription that receives the state of every following step.
+// buffer is the number of states queued for a slow consumer, at least 1.
+// Close the subscription when it is no longer read.
+func (s *Simulation) Subscribe(buffer int) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+	sub := &Subscription{sim: s, ch: make(chan SimState, buffer)}
+	s.subMu.Lock()
+	s.subscribers = append(s.subscribers, sub)
+	s.subMu.Unlock()
+	return sub
+}
+
+// C returns the channel of states; it is closed by Close.
+func (sub *Subscription) C() <-chan SimState {
+	return sub.ch
+}
+
+// Dropped returns how many states were discarded because the consumer fell behind.
+func (sub *Subscription) Dropped() int64 {
+	return sub.dropped.Load()
+}
+
+// Close stops the delivery and closes the channel. Calling it again has no effect.
+func (sub *Subscription) Close() {
+	s := sub.sim
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for i, other := range s.subscribers {
+		if other == sub {
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+// publish hands a state to every subscriber without blocking. It runs after the
+// simulation lock is released; subMu keeps Close from closing a channel mid-send.
+func (s *Simulation) publish(state SimState) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for _, sub := range s.subscribers {
+		for sent := false; !sent; {
+			select {
+			case sub.ch <- state:
+				sent = true
+			default:
+				select { // Full: make room by dropping the oldest state
+				case <-sub.ch:
+					sub.dropped.Add(1)
+					s.metrics.Inc(MetricStatesDropped)
+				default: // The consumer just took one
+				}
+			}
+		}
+	}
+}
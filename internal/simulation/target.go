@@ -2,10 +2,8 @@ package simulation
 
 import (
 	"fmt"
-	"math"
-	"math/rand"
+	"log/slog"
 	"multilateration-sim/internal/common" // Замените на ваше имя модуля
-	"time"
 
 	"github.com/google/uuid" // Для генерации уникальных ID
 )
@@ -13,13 +11,41 @@ import (
 // Target represents a target object in the simulation.
 type Target struct {
 	id       string
+	label    string // Human-readable name for display, empty if none
 	position common.Vector
 	velocity common.Vector // Current velocity for movement
+	motion   MotionModel   // Strategy that advances position and velocity
+	emitter  *emitterState // Blink schedule, nil means transmitting continuously
+	clock    Clock         // Stamps transmissions in time-of-arrival mode
+	class    string        // Kind of target (e.g. "pedestrian", "drone"), empty if unclassified
+
+	rangeNoise NoiseFunction // Extra noise of every range to the target, nil = none
+	boundary   BoundaryMode  // Set by the simulation
+
+	motionRand *randStream  // Random stream of the motion model
+	noiseRand  *randStream  // Random stream of blink jitter and odometry noise
+	logger     *slog.Logger // Set by the simulation, nil means slog.Default()
 	// Add other target-specific properties if needed
 }
 
-// NewTarget creates a new target at a given position.
+// NewTarget creates a new target at a given position that moves by random walk.
 func NewTarget(pos common.Vector) *Target {
+	return NewTargetWithMotion(pos, NewRandomWalkMotion())
+}
+
+// NewTargetWithID creates a target with a designated ID driven by the given motion model,
+// so that configuration and scripts can refer to it. An empty id picks a random one.
+func NewTargetWithID(id string, pos common.Vector, motion MotionModel) *Target {
+	t := NewTargetWithMotion(pos, motion)
+	if id != "" {
+		t.id = id
+	}
+	return t
+}
+
+// NewTargetWithMotion creates a new target at a given position driven by the given motion model.
+// A nil model keeps the target static.
+func NewTargetWithMotion(pos common.Vector, motion MotionModel) *Target {
 	dim := pos.Dimension()
 	// Start with zero velocity initially
 	vel := common.NewVector(dim)
@@ -27,6 +53,10 @@ func NewTarget(pos common.Vector) *Target {
 		id:       fmt.Sprintf("target-%s", uuid.NewString()[:8]), // Shorter unique ID
 		position: pos.Clone(),                                    // Clone to avoid external modification
 		velocity: vel,
+		motion:   motion,
+
+		motionRand: newUnseededStream(),
+		noiseRand:  newUnseededStream(),
 	}
 }
 
@@ -35,6 +65,17 @@ func (t *Target) GetID() string {
 	return t.id
 }
 
+// Label returns the human-readable name of the target, empty if it has none.
+func (t *Target) Label() string {
+	return t.label
+}
+
+// SetLabel sets a human-readable name shown next to the target. Unlike the ID it need
+// not be unique and can change.
+func (t *Target) SetLabel(label string) {
+	t.label = label
+}
+
 // GetPosition returns the current position of the target.
 func (t *Target) GetPosition() common.Vector {
 	// Return a clone to prevent modification of the internal state
@@ -44,59 +85,79 @@ func (t *Target) GetPosition() common.Vector {
 // SetPosition sets the position of the target.
 func (t *Target) SetPosition(pos common.Vector) error {
 	if pos.Dimension() != t.position.Dimension() {
-		return fmt.Errorf("dimension mismatch: expected %d, got %d", t.position.Dimension(), pos.Dimension())
+		return fmt.Errorf("%w: expected %d, got %d", ErrDimensionMismatch, t.position.Dimension(), pos.Dimension())
 	}
 	t.position = pos.Clone() // Store a clone
 	return nil
 }
 
-// Update implements the random walk movement and boundary checks.
+// GetVelocity returns the current velocity of the target.
+func (t *Target) GetVelocity() common.Vector {
+	return t.velocity.Clone()
+}
+
+// SetVelocity sets the current velocity of the target, e.g. the initial velocity
+// for a constant-velocity model.
+func (t *Target) SetVelocity(vel common.Vector) error {
+	if vel.Dimension() != t.position.Dimension() {
+		return fmt.Errorf("%w: expected %d, got %d", ErrDimensionMismatch, t.position.Dimension(), vel.Dimension())
+	}
+	t.velocity = vel.Clone()
+	return nil
+}
+
+// MotionModel returns the motion model driving the target (nil if static).
+func (t *Target) MotionModel() MotionModel {
+	return t.motion
+}
+
+// SetMotionModel replaces the motion model driving the target. nil makes it static.
+func (t *Target) SetMotionModel(motion MotionModel) {
+	t.motion = motion
+}
+
+// Class returns the kind of target, empty if it is unclassified.
+func (t *Target) Class() string {
+	return t.class
+}
+
+// SetClass sets the kind of target, e.g. "pedestrian", "vehicle" or "drone". The class is
+// a label for display and analysis; behaviour comes from the motion model and range noise.
+func (t *Target) SetClass(class string) {
+	t.class = class
+}
+
+// RangeNoise returns the extra range noise of the target, nil if none.
+func (t *Target) RangeNoise() NoiseFunction {
+	return t.rangeNoise
+}
+
+// SetRangeNoise adds noise to every range measured to the target on top of the sensor
+// noise, e.g. for a small drone that reflects poorly. nil removes it.
+func (t *Target) SetRangeNoise(noise NoiseFunction) {
+	t.rangeNoise = noise
+}
+
+// Update advances the target using its motion model and applies boundary checks.
 func (t *Target) Update(deltaTime float64, bounds []float64) {
 	dim := t.position.Dimension()
 	if len(bounds) != dim*2 {
-		fmt.Printf("Warning: Target %s received invalid bounds length\n", t.id)
+		loggerOrDefault(t.logger).Warn("invalid bounds length", "target", t.id, "bounds", len(bounds), "dimension", dim)
 		return // Or handle error more gracefully
 	}
-
-	// --- Simple Random Walk Logic ---
-	// Adjust velocity slightly randomly
-	accelerationScale := 50.0 // How much velocity can change per second
-	for i := 0; i < dim; i++ {
-		// Add a small random change to velocity
-		t.velocity[i] += (rand.Float64()*2 - 1) * accelerationScale * deltaTime
+	if t.motion == nil {
+		return // Static target
 	}
 
-	// --- Limit Velocity (Optional) ---
-	maxSpeed := 300.0 // Maximum units per second
-	currentSpeedSq := 0.0
-	for _, v := range t.velocity {
-		currentSpeedSq += v * v
-	}
-	if currentSpeedSq > maxSpeed*maxSpeed {
-		scale := maxSpeed / math.Sqrt(currentSpeedSq)
-		t.velocity = t.velocity.MultiplyByScalar(scale)
-	}
-
-	// --- Update Position ---
-	deltaPos := t.velocity.MultiplyByScalar(deltaTime)
-	newPos, err := t.position.Add(deltaPos)
-	if err != nil {
-		fmt.Printf("Error updating target %s position: %v\n", t.id, err)
+	newPos, newVel := t.motion.Step(t.position, t.velocity, deltaTime, t.motionRand.Rand)
+	if newPos.Dimension() != dim || newVel.Dimension() != dim {
+		loggerOrDefault(t.logger).Error("motion model changed the dimension, update skipped", "target", t.id, "dimension", dim)
 		return // Skip update if dimensions mismatch (shouldn't happen here)
 	}
+	t.velocity = newVel
 
-	// --- Boundary Collision Check (Bounce) ---
-	for i := 0; i < dim; i++ {
-		minBound := bounds[i*2]
-		maxBound := bounds[i*2+1]
-		if newPos[i] < minBound {
-			newPos[i] = minBound + (minBound - newPos[i]) // Reflect position
-			t.velocity[i] *= -0.8                         // Reverse and dampen velocity component
-		} else if newPos[i] > maxBound {
-			newPos[i] = maxBound - (newPos[i] - maxBound) // Reflect position
-			t.velocity[i] *= -0.8                         // Reverse and dampen velocity component
-		}
-	}
+	// --- Boundary Check (bounce by default, see BoundaryMode) ---
+	applyBoundary(t.boundary, newPos, t.velocity, bounds)
 
 	t.position = newPos // Update the position
 }
@@ -105,8 +166,3 @@ func (t *Target) Update(deltaTime float64, bounds []float64) {
 func (t *Target) String() string {
 	return fmt.Sprintf("Target[%s] Pos: %s Vel: %s", t.id, t.position, t.velocity)
 }
-
-// Initialize random seed
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
@@ -0,0 +1,76 @@
+package simulation
+
+import (
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+)
+
+// TrackerFactory creates the tracker of a newly seen target.
+type TrackerFactory func(targetID string) tracking.Tracker
+
+// SetTrackerFactory enables temporal estimation: every step each target's measurements are
+// also fed to its own tracker, and the tracker output replaces the snapshot estimate.
+// A tracker keeps producing estimates while fewer than dimension+1 sensors see the
+// target; the TargetReport outcome still describes the snapshot solve. nil disables tracking.
+func (s *Simulation) SetTrackerFactory(factory TrackerFactory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trackerFactory = factory
+	s.trackers = make(map[string]tracking.Tracker)
+}
+
+// SetOdometrySigma makes targets report their displacement since the previous step to
+// their trackers, perturbed by Gaussian noise with the given standard deviation per axis.
+// A negative value disables odometry.
+func (s *Simulation) SetOdometrySigma(sigma float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.odometrySigma = sigma
+}
+
+// odometryEnabled reports whether targets report odometry to their trackers.
+func (s *Simulation) odometryEnabled() bool {
+	return s.trackerFactory != nil && s.odometrySigma >= 0
+}
+
+// updateTracker feeds one target's measurements to its tracker and, on success,
+// replaces the target's estimate by the tracker output.
+func (s *Simulation) updateTracker(tar *Target, measurements []multilateration.Measurement, previous common.Vector) error {
+	targetID := tar.GetID()
+	tracker, ok := s.trackers[targetID]
+	if !ok {
+		if tracker = s.trackerFactory(targetID); tracker == nil {
+			return nil
+		}
+		s.trackers[targetID] = tracker
+	}
+
+	var odometry common.Vector
+	if previous != nil {
+		delta, err := tar.GetPosition().Subtract(previous)
+		if err == nil {
+			for i := range delta {
+				delta[i] += tar.noiseRand.NormFloat64() * s.odometrySigma
+			}
+			odometry = delta
+		}
+	}
+
+	position, err := tracker.Update(s.simulationTime, measurements, odometry)
+	if err != nil {
+		return err
+	}
+	solution := s.lastEstimates[targetID] // Keeps the snapshot quality figures, if any
+	solution.Position = position
+	if vt, ok := tracker.(tracking.VelocityTracker); ok {
+		if velocity := vt.Velocity(); velocity != nil {
+			solution.Velocity = velocity
+		}
+	}
+	s.lastEstimates[targetID] = solution
+	if locErr, distErr := s.localizationError(tar.GetPosition(), position); distErr == nil {
+		s.lastErrors[targetID] = locErr
+	}
+	return nil
+}
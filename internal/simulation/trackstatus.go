@@ -0,0 +1,229 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// Metric names of track status.
+const (
+	MetricTracksLost     = "tracks_lost"     // Tracks that turned Lost
+	MetricTrackMisses    = "track_misses"    // Steps without an acceptable estimate of a target
+	MetricTracksTracking = "tracks_tracking" // Gauge: targets whose track is confirmed and updated
+)
+
+// qualityMemory is the weight of the newest update in the track quality score.
+const qualityMemory = 0.2
+
+// TrackState is the stage of a target's track.
+type TrackState int
+
+const (
+	TrackInitializing TrackState = iota // Not yet confirmed by enough consecutive good estimates
+	TrackTracking                       // Confirmed, and the last step produced a good estimate
+	TrackCoasting                       // Confirmed, but the recent steps produced no good estimate
+	TrackLost                           // Too many steps without a good estimate
+)
+
+// String returns a short name of the state.
+func (t TrackState) String() string {
+	switch t {
+	case TrackInitializing:
+		return "initializing"
+	case TrackTracking:
+		return "tracking"
+	case TrackCoasting:
+		return "coasting"
+	case TrackLost:
+		return "lost"
+	default:
+		return fmt.Sprintf("track(%d)", int(t))
+	}
+}
+
+// TrackQuality configures how estimates move a track between the states. A step is a
+// miss if the target is not localized, or its estimate has a residual above MaxResidual
+// or lies more than MaxInnovation standard deviations from the position predicted from
+// the previous good estimates.
+type TrackQuality struct {
+	ConfirmUpdates int     `json:"confirm_updates"` // Consecutive good estimates that confirm a track
+	MaxMisses      int     `json:"max_misses"`      // Consecutive misses coasted before the track is lost
+	MaxResidual    float64 `json:"max_residual"`    // Residual above which an estimate is a miss, 0 = any
+	PositionSigma  float64 `json:"position_sigma"`  // Position error of the prediction, added to the estimate covariance
+	MaxInnovation  float64 `json:"max_innovation"`  // Normalized innovation above which an estimate is a miss
+}
+
+// DefaultTrackQuality confirms a track after 3 good estimates and loses it after 5 misses.
+func DefaultTrackQuality() TrackQuality {
+	return TrackQuality{ConfirmUpdates: 3, MaxMisses: 5, PositionSigma: 5, MaxInnovation: 4}
+}
+
+// Validate checks the parameters.
+func (c TrackQuality) Validate() error {
+	switch {
+	case c.ConfirmUpdates < 1:
+		return fmt.Errorf("%w: confirming a track needs at least 1 update, got %d", ErrOutOfRange, c.ConfirmUpdates)
+	case c.MaxMisses < 0:
+		return fmt.Errorf("%w: max misses must be non-negative, got %d", ErrOutOfRange, c.MaxMisses)
+	case c.MaxResidual < 0:
+		return fmt.Errorf("%w: max residual must be non-negative, got %f", ErrOutOfRange, c.MaxResidual)
+	case c.PositionSigma <= 0 || c.MaxInnovation <= 0:
+		return fmt.Errorf("%w: position sigma and max innovation must be positive", ErrOutOfRange)
+	}
+	return nil
+}
+
+// TrackStatus is the state of a target's track with the figures it was decided on.
+type TrackStatus struct {
+	State      TrackState
+	Quality    float64 // Exponentially weighted share of good estimates, 0 to 1
+	Updates    int     // Consecutive good estimates
+	Misses     int     // Consecutive misses
+	Innovation float64 // Normalized innovation of the last estimate, -1 if not tested
+}
+
+// trackRecord is the status of a track with the last good estimates it predicts from.
+type trackRecord struct {
+	status    TrackStatus
+	confirmed bool
+	fixes     [2]positionAt // Last good estimates, newest first
+	count     int           // Valid entries of fixes
+}
+
+// positionAt is an estimate at a simulation time.
+type positionAt struct {
+	time     float64
+	position common.Vector
+}
+
+// predict extrapolates the last two good estimates to time now; false with fewer.
+func (t *trackRecord) predict(now float64) (common.Vector, bool) {
+	if t.count < 2 || t.fixes[0].time <= t.fixes[1].time {
+		return nil, false
+	}
+	last, prev := t.fixes[0], t.fixes[1]
+	predicted := last.position.Clone()
+	scale := (now - last.time) / (last.time - prev.time)
+	for i := range predicted {
+		predicted[i] += (last.position[i] - prev.position[i]) * scale
+	}
+	return predicted, true
+}
+
+// SetTrackQuality sets how track status is decided, nil restores DefaultTrackQuality.
+func (s *Simulation) SetTrackQuality(config *TrackQuality) error {
+	c := DefaultTrackQuality()
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		c = *config
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trackQuality = c
+	return nil
+}
+
+// TrackQuality returns how track status is decided.
+func (s *Simulation) TrackQuality() *TrackQuality {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := s.trackQuality
+	return &c
+}
+
+// GetTrackStatus returns the track status of a target; false if it has not been through
+// a step yet.
+func (s *Simulation) GetTrackStatus(targetID string) (TrackStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tracks[targetID]
+	if !ok {
+		return TrackStatus{}, false
+	}
+	return t.status, true
+}
+
+// updateTrackStatus scores the snapshot estimate of a target in this step, solution
+// if the outcome is OutcomeLocalized. The caller holds the lock.
+func (s *Simulation) updateTrackStatus(targetID string, outcome LocalizationOutcome, solution multilateration.Solution) {
+	if outcome == OutcomeDeferred {
+		return // No new estimate to judge
+	}
+	t, ok := s.tracks[targetID]
+	if !ok {
+		t = &trackRecord{}
+		s.tracks[targetID] = t
+	}
+	c := s.trackQuality
+	st := &t.status
+	st.Innovation = -1
+	good := outcome == OutcomeLocalized && solution.Position != nil
+	if good && c.MaxResidual > 0 && solution.ResidualError > c.MaxResidual {
+		good = false
+	}
+	if predicted, ok := t.predict(s.simulationTime); good && ok {
+		if d, err := solution.Position.Distance(predicted); err == nil {
+			st.Innovation = d / innovationSigma(solution, c.PositionSigma)
+			good = st.Innovation <= c.MaxInnovation
+		}
+	}
+
+	if good {
+		st.Updates++
+		st.Misses = 0
+		st.Quality += qualityMemory * (1 - st.Quality)
+		t.fixes[1], t.fixes[0] = t.fixes[0], positionAt{time: s.simulationTime, position: solution.Position.Clone()}
+		t.count = min(t.count+1, len(t.fixes))
+		t.confirmed = t.confirmed || st.Updates >= c.ConfirmUpdates
+	} else {
+		st.Updates = 0
+		st.Misses++
+		st.Quality -= qualityMemory * st.Quality
+		s.metrics.Inc(MetricTrackMisses)
+	}
+
+	previous := st.State
+	switch {
+	case st.State == TrackLost && !good, st.Misses > c.MaxMisses:
+		st.State = TrackLost
+		*t = trackRecord{status: *st} // Start over with the next good estimate
+	case !t.confirmed:
+		st.State = TrackInitializing
+	case good:
+		st.State = TrackTracking
+	default:
+		st.State = TrackCoasting
+	}
+	if st.State == TrackLost && previous != TrackLost {
+		s.metrics.Inc(MetricTracksLost)
+	}
+}
+
+// innovationSigma is the standard deviation of the distance between an estimate and
+// the prediction: the prediction error combined with the estimate covariance, if any.
+func innovationSigma(solution multilateration.Solution, predictionSigma float64) float64 {
+	variance := predictionSigma * predictionSigma
+	if len(solution.Covariance) == solution.Position.Dimension() {
+		for i := range solution.Covariance {
+			if v := solution.Covariance[i][i]; v > 0 && !math.IsInf(v, 1) {
+				variance += v
+			}
+		}
+	}
+	return math.Sqrt(variance)
+}
+
+// countTracking updates the gauge of tracking targets. The caller holds the lock.
+func (s *Simulation) countTracking() {
+	tracking := 0
+	for _, t := range s.tracks {
+		if t.status.State == TrackTracking {
+			tracking++
+		}
+	}
+	s.metrics.Set(MetricTracksTracking, float64(tracking))
+}
@@ -0,0 +1,62 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+)
+
+// Runtime tuning: setters that change a parameter of every sensor or target at once, for
+// interactive sensitivity exploration. They take the simulation lock, so they are safe
+// to call from a UI goroutine while the simulation is stepping.
+
+// SetSensorNoiseSigma gives every sensor Gaussian range noise with the given standard
+// deviation (0 = noiseless) and declares the matching range variance. It replaces any
+// other noise model of the sensors.
+func (s *Simulation) SetSensorNoiseSigma(sigma float64) error {
+	if sigma < 0 || math.IsNaN(sigma) {
+		return fmt.Errorf("%w: noise sigma must be non-negative, got %f", ErrOutOfRange, sigma)
+	}
+	var noise NoiseFunction
+	if sigma > 0 {
+		noise = GaussianNoise(sigma)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sensor := range s.sensors {
+		sensor.SetNoiseFunction(noise)
+		sensor.SetRangeVariance(sigma * sigma)
+	}
+	return nil
+}
+
+// SetSensorDetectionRadius sets the detection radius of every sensor, 0 means unlimited.
+func (s *Simulation) SetSensorDetectionRadius(radius float64) error {
+	if radius < 0 || math.IsNaN(radius) {
+		return fmt.Errorf("%w: detection radius must be non-negative, got %f", ErrOutOfRange, radius)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sensor := range s.sensors {
+		_ = sensor.SetDetectionRadius(radius)
+	}
+	return nil
+}
+
+// SetTargetMaxSpeed sets the speed limit of every target moving by random walk, 0 means
+// unlimited. Other motion models keep their own speed. It returns the number of targets
+// changed.
+func (s *Simulation) SetTargetMaxSpeed(speed float64) (int, error) {
+	if speed < 0 || math.IsNaN(speed) {
+		return 0, fmt.Errorf("%w: maximum speed must be non-negative, got %f", ErrOutOfRange, speed)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := 0
+	for _, target := range s.targets {
+		if walk, ok := target.motion.(*RandomWalkMotion); ok {
+			walk.MaxSpeed = speed // Shared models, e.g. of a target class, change once per target
+			changed++
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,215 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+)
+
+// Limits of confining objects to a world.
+const (
+	maxPlacementAttempts = 10000 // Random positions drawn in the bounds before giving up
+	gradientStep         = 1e-6  // Relative step of the numerical boundary normal
+	bounceDamping        = 0.8   // Share of the normal speed kept by a bounce, as at the bounds
+	boundaryTolerance    = 1e-9  // Signed distance still counted as inside after a bounce
+)
+
+// World is the space the objects of a simulation move in when it is not the box of the
+// simulation bounds, e.g. a room of irregular shape. The bounds remain its bounding box:
+// random positions are drawn in them and kept if inside the world.
+type World interface {
+	Region
+	// SignedDistance returns the distance of a point to the boundary of the world,
+	// negative inside. Outside it may underestimate the distance, but it must be
+	// positive and grow away from the world.
+	SignedDistance(pos common.Vector) float64
+}
+
+// SignedDistance implements World.
+func (b *BoxRegion) SignedDistance(pos common.Vector) float64 {
+	if pos.Dimension() != b.min.Dimension() {
+		return math.Inf(1)
+	}
+	outside, inside := 0.0, math.Inf(-1)
+	for i := range b.min {
+		d := math.Max(b.min[i]-pos[i], pos[i]-b.max[i]) // Positive beyond a face
+		outside += math.Max(d, 0) * math.Max(d, 0)
+		inside = math.Max(inside, d)
+	}
+	if outside > 0 {
+		return math.Sqrt(outside)
+	}
+	return inside
+}
+
+// SignedDistance implements World.
+func (b *BallRegion) SignedDistance(pos common.Vector) float64 {
+	d, err := pos.Distance(b.center)
+	if err != nil {
+		return math.Inf(1)
+	}
+	return d - b.radius
+}
+
+// PolytopeRegion is a convex polytope, the intersection of the half-spaces
+// normal·x <= offset.
+type PolytopeRegion struct {
+	normals []common.Vector
+	offsets []float64
+}
+
+// NewPolytopeRegion creates the polytope of the given half-spaces. The normals need not
+// be unit vectors.
+func NewPolytopeRegion(normals []common.Vector, offsets []float64) (*PolytopeRegion, error) {
+	if len(normals) == 0 || len(normals) != len(offsets) {
+		return nil, fmt.Errorf("%w: a polytope needs one offset per normal, got %d normals and %d offsets", ErrDimensionMismatch, len(normals), len(offsets))
+	}
+	p := &PolytopeRegion{offsets: append([]float64(nil), offsets...)}
+	for i, n := range normals {
+		if n.Dimension() != normals[0].Dimension() {
+			return nil, fmt.Errorf("%w: normal %d has dimension %d, expected %d", ErrDimensionMismatch, i, n.Dimension(), normals[0].Dimension())
+		}
+		norm := n.Norm()
+		if norm == 0 {
+			return nil, fmt.Errorf("%w: normal %d is zero", ErrOutOfRange, i)
+		}
+		// Store unit normals, so that the signed distance is in units
+		p.normals = append(p.normals, n.MultiplyByScalar(1/norm))
+		p.offsets[i] /= norm
+	}
+	return p, nil
+}
+
+// Normals returns the unit normals of the faces.
+func (p *PolytopeRegion) Normals() []common.Vector {
+	normals := make([]common.Vector, len(p.normals))
+	for i, n := range p.normals {
+		normals[i] = n.Clone()
+	}
+	return normals
+}
+
+// Offsets returns the offsets of the faces along their unit normals.
+func (p *PolytopeRegion) Offsets() []float64 {
+	return append([]float64(nil), p.offsets...)
+}
+
+// Contains implements Region. Points of another dimension are outside.
+func (p *PolytopeRegion) Contains(pos common.Vector) bool {
+	return pos.Dimension() == p.normals[0].Dimension() && p.SignedDistance(pos) <= 0
+}
+
+// SignedDistance implements World: the largest distance beyond a face, which is exact
+// inside and near the faces and underestimates the distance from outer corners.
+func (p *PolytopeRegion) SignedDistance(pos common.Vector) float64 {
+	d := math.Inf(-1)
+	for i, n := range p.normals {
+		dot, err := n.DotProduct(pos)
+		if err != nil {
+			return math.Inf(1)
+		}
+		d = math.Max(d, dot-p.offsets[i])
+	}
+	return d
+}
+
+// SDFWorld is a world given by a signed-distance function, negative inside.
+type SDFWorld func(pos common.Vector) float64
+
+// Contains implements Region.
+func (f SDFWorld) Contains(pos common.Vector) bool {
+	return f(pos) <= 0
+}
+
+// SignedDistance implements World.
+func (f SDFWorld) SignedDistance(pos common.Vector) float64 {
+	return f(pos)
+}
+
+// SetWorld confines the objects to a world: random positions are drawn inside it, and
+// moving objects that leave it bounce off its boundary, mirrored along its normal with
+// the normal speed damped (see SetBoundaryMode for the alternatives). The bounds stay its bounding box. nil (the default) uses the
+// box of the bounds.
+func (s *Simulation) SetWorld(world World) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.world = world
+}
+
+// World returns the world set with SetWorld, nil if it is the box of the bounds.
+func (s *Simulation) World() World {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.world
+}
+
+// randomPosition draws a position uniformly in the world. The caller holds the lock.
+func (s *Simulation) randomPosition() (common.Vector, error) {
+	for range maxPlacementAttempts {
+		pos, err := common.NewRandomVectorFrom(s.dimension, s.bounds, s.placementRand.Rand)
+		if err != nil || s.world == nil || s.world.Contains(pos) {
+			return pos, err
+		}
+	}
+	return nil, fmt.Errorf("%w: no position inside the world among %d drawn in the bounds", ErrOutOfRange, maxPlacementAttempts)
+}
+
+// velocityObject is implemented by objects that move with a velocity.
+type velocityObject interface {
+	GetVelocity() common.Vector
+	SetVelocity(vel common.Vector) error
+}
+
+// confine brings an object that left the world during a step back inside according to
+// the boundary mode, from previous, its position before the step: it bounces, or stops
+// at the boundary in Clamp mode. In Absorb mode it is left outside to be removed. The
+// caller holds the lock.
+func (s *Simulation) confine(obj SimulationObject, previous common.Vector) {
+	pos := obj.GetPosition()
+	d := s.world.SignedDistance(pos)
+	if d <= 0 || s.boundary == BoundaryAbsorb {
+		return
+	}
+	normal := s.worldNormal(pos)
+	bounced := pos.Clone()
+	damping := bounceDamping
+	if s.boundary == BoundaryClamp {
+		_ = bounced.AddScaledInPlace(normal, -d) // Onto the boundary
+		damping = 0
+	} else {
+		_ = bounced.AddScaledInPlace(normal, -2*d) // Mirror at the boundary
+	}
+	if s.world.SignedDistance(bounced) > boundaryTolerance {
+		bounced = previous // Around a corner the mirror image can be outside too
+	}
+	if s.world.SignedDistance(bounced) > boundaryTolerance {
+		return // Placed outside, e.g. by hand; leave it until it moves in
+	}
+	_ = obj.SetPosition(bounced)
+	if moving, ok := obj.(velocityObject); ok {
+		vel := moving.GetVelocity()
+		if along, err := vel.DotProduct(normal); err == nil && along > 0 {
+			_ = vel.AddScaledInPlace(normal, -(1+damping)*along)
+			_ = moving.SetVelocity(vel)
+		}
+	}
+}
+
+// worldNormal is the outward unit normal of the world boundary near pos, by central
+// differences of the signed distance.
+func (s *Simulation) worldNormal(pos common.Vector) common.Vector {
+	h := gradientStep * math.Max(s.boundsDiagonal(), 1)
+	grad := common.NewVector(len(pos))
+	probe := pos.Clone()
+	for i := range grad {
+		probe[i] = pos[i] + h
+		ahead := s.world.SignedDistance(probe)
+		probe[i] = pos[i] - h
+		grad[i] = (ahead - s.world.SignedDistance(probe)) / (2 * h)
+		probe[i] = pos[i]
+	}
+	if grad.Norm() == 0 {
+		return grad
+	}
+	return grad.Normalize()
+}
@@ -0,0 +1,39 @@
+package terminal_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"multilateration-sim/internal/terminal"
+	"strings"
+	"time"
+)
+
+// Draw a 2D scene as text, e.g. to watch a headless run over SSH.
+func ExampleRender() {
+	sim, _ := simulation.NewSimulation(2, []float64{-50, 50, -50, 50}, time.Second/10)
+	sim.SetSeeds(simulation.NewSeeds(1))
+	for _, pos := range []common.Vector{{-40, -40}, {40, -40}, {-40, 40}, {40, 40}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	_ = sim.AddObject(simulation.NewTargetWithMotion(common.Vector{10, 0}, nil))
+	sim.Step(0.1)
+
+	opts := terminal.Options{Width: 20, Height: 5, AxisX: 0, AxisY: 1, Bounds: []float64{-50, 50, -50, 50}}
+	text, err := terminal.Render(sim.Snapshot(), opts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	lines := strings.Split(text, "\n")
+	fmt.Println(strings.Join(lines[:8], "\n")) // The map and legend without the table, whose IDs are random
+	// Output:
+	// +--------------------+
+	// |      S       S     |
+	// |                    |
+	// |           *        |
+	// |                    |
+	// |      S       S     |
+	// +--------------------+
+	// S датчик  x не работает  T цель  o оценка  * цель на оценке  # препятствие
+}
@@ -0,0 +1,258 @@
+// Package terminal shows a running simulation as text: a coarse character map of the
+// sensors, targets and estimates and a table of the targets, redrawn in place with ANSI
+// escape codes. It needs neither a window nor a GPU, so a simulation on a headless
+// server can be watched over SSH.
+package terminal
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"strings"
+	"text/tabwriter"
+)
+
+// Map symbols. Where several objects share a cell the one listed last wins.
+const (
+	obstacleSymbol = '#'
+	sensorSymbol   = 'S'
+	downSymbol     = 'x' // A failed sensor or one in an outage
+	estimateSymbol = 'o'
+	targetSymbol   = 'T'
+	matchSymbol    = '*' // A target and its estimate in the same cell
+)
+
+// ANSI colours of the symbols, used with Options.Color.
+var symbolColors = map[rune]string{
+	obstacleSymbol: "\x1b[90m",
+	sensorSymbol:   "\x1b[34m",
+	downSymbol:     "\x1b[90m",
+	estimateSymbol: "\x1b[33m",
+	targetSymbol:   "\x1b[31m",
+	matchSymbol:    "\x1b[32m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// cellAspect is how many times taller than wide a terminal character cell is.
+const cellAspect = 2.0
+
+// Options control the text view. Like offscreen.Options the map is a slice along two
+// axes of the simulation space.
+type Options struct {
+	Width, Height int       // Map size in characters, without the border
+	AxisX, AxisY  int       // Simulation axes shown horizontally and vertically
+	Bounds        []float64 // minX, maxX, minY, maxY of the view; nil fits the objects
+	MaxRows       int       // Targets listed in the table, 0 for all
+	Color         bool      // Colour the symbols with ANSI escape codes
+}
+
+// DefaultOptions returns a 72x20 map of axes 0/1 (fits an 80x24 terminal with the
+// legend) listing up to 10 targets, in colour.
+func DefaultOptions() Options {
+	return Options{Width: 72, Height: 20, AxisX: 0, AxisY: 1, MaxRows: 10, Color: true}
+}
+
+// grid maps simulation coordinates to character cells.
+type grid struct {
+	opts             Options
+	scale            float64 // Columns per unit; rows per unit is scale / cellAspect
+	offsetX, offsetY float64
+}
+
+func newGrid(state simulation.SimState, opts Options) (grid, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return grid{}, fmt.Errorf("map size must be positive, got %dx%d", opts.Width, opts.Height)
+	}
+	bounds := opts.Bounds
+	if bounds == nil {
+		bounds = fitBounds(state, opts.AxisX, opts.AxisY)
+	}
+	if len(bounds) != 4 || bounds[1] <= bounds[0] || bounds[3] <= bounds[2] {
+		return grid{}, fmt.Errorf("invalid view bounds %v", bounds)
+	}
+	scale := math.Min(float64(opts.Width)/(bounds[1]-bounds[0]), float64(opts.Height)*cellAspect/(bounds[3]-bounds[2]))
+	return grid{
+		opts:    opts,
+		scale:   scale,
+		offsetX: float64(opts.Width)/2 - (bounds[0]+bounds[1])/2*scale,
+		offsetY: float64(opts.Height)/2 - (bounds[2]+bounds[3])/2*scale/cellAspect,
+	}, nil
+}
+
+// fitBounds returns the bounding box of the sensors and targets along the two axes.
+func fitBounds(state simulation.SimState, ax, ay int) []float64 {
+	b := []float64{math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)}
+	grow := func(p common.Vector) {
+		x, okX := coordinate(p, ax)
+		y, okY := coordinate(p, ay)
+		if !okX || !okY {
+			return
+		}
+		b[0], b[1] = math.Min(b[0], x), math.Max(b[1], x)
+		b[2], b[3] = math.Min(b[2], y), math.Max(b[3], y)
+	}
+	for _, s := range state.Sensors {
+		grow(s.Position)
+	}
+	for _, t := range state.Targets {
+		grow(t.Position)
+	}
+	if math.IsInf(b[0], 0) {
+		return []float64{-1, 1, -1, 1}
+	}
+	for i := 0; i < 4; i += 2 { // Avoid a zero-size view
+		if b[i+1]-b[i] < 1e-9 {
+			b[i], b[i+1] = b[i]-1, b[i+1]+1
+		}
+	}
+	return b
+}
+
+// coordinate returns the position along axis. 1D scenes are drawn on the middle row.
+func coordinate(p common.Vector, axis int) (float64, bool) {
+	switch {
+	case axis >= 0 && axis < len(p):
+		return p[axis], true
+	case len(p) == 1:
+		return 0, true
+	}
+	return 0, false
+}
+
+// toCell returns the column and row of a position, false if it lies off the map.
+func (g grid) toCell(p common.Vector) (int, int, bool) {
+	x, okX := coordinate(p, g.opts.AxisX)
+	y, okY := coordinate(p, g.opts.AxisY)
+	if !okX || !okY {
+		return 0, 0, false
+	}
+	col := int(math.Floor(x*g.scale + g.offsetX))
+	row := int(math.Floor(y*g.scale/cellAspect + g.offsetY))
+	if col < 0 || col >= g.opts.Width || row < 0 || row >= g.opts.Height {
+		return 0, 0, false
+	}
+	return col, row, true
+}
+
+// toWorld returns the simulation coordinates of the centre of a cell.
+func (g grid) toWorld(col, row int) (float64, float64) {
+	return (float64(col) + 0.5 - g.offsetX) / g.scale, (float64(row) + 0.5 - g.offsetY) * cellAspect / g.scale
+}
+
+// Render draws the scene as a bordered character map followed by a legend and a table of
+// the targets with their true position, estimate, error and GDOP. Obstacles are only
+// drawn for 1D and 2D scenes, where the map is the whole simulation space.
+func Render(state simulation.SimState, opts Options) (string, error) {
+	g, err := newGrid(state, opts)
+	if err != nil {
+		return "", err
+	}
+	cells := make([][]rune, opts.Height)
+	for i := range cells {
+		cells[i] = []rune(strings.Repeat(" ", opts.Width))
+	}
+	put := func(p common.Vector, symbol rune) {
+		if col, row, ok := g.toCell(p); ok {
+			cells[row][col] = symbol
+		}
+	}
+
+	if state.Dimension <= 2 && len(state.Obstacles) > 0 && opts.AxisX >= 0 && opts.AxisY >= 0 {
+		for row := range cells {
+			for col := range cells[row] {
+				x, y := g.toWorld(col, row)
+				pos := common.NewVector(state.Dimension)
+				if opts.AxisX < len(pos) {
+					pos[opts.AxisX] = x
+				}
+				if opts.AxisY < len(pos) {
+					pos[opts.AxisY] = y
+				}
+				for _, obs := range state.Obstacles {
+					if obs.Shape.Contains(pos) {
+						cells[row][col] = obstacleSymbol
+						break
+					}
+				}
+			}
+		}
+	}
+	for _, s := range state.Sensors {
+		if s.Status != simulation.SensorOperational {
+			put(s.Position, downSymbol)
+		} else {
+			put(s.Position, sensorSymbol)
+		}
+	}
+	for _, t := range state.Targets {
+		if t.Estimate.Position != nil {
+			put(t.Estimate.Position, estimateSymbol)
+		}
+	}
+	for _, t := range state.Targets {
+		col, row, ok := g.toCell(t.Position)
+		if !ok {
+			continue
+		}
+		cells[row][col] = targetSymbol
+		if t.Estimate.Position != nil {
+			if ecol, erow, ok := g.toCell(t.Estimate.Position); ok && ecol == col && erow == row {
+				cells[row][col] = matchSymbol
+			}
+		}
+	}
+
+	var b strings.Builder
+	border := "+" + strings.Repeat("-", opts.Width) + "+\n"
+	b.WriteString(border)
+	for _, line := range cells {
+		b.WriteByte('|')
+		for _, c := range line {
+			if color, ok := symbolColors[c]; ok && opts.Color {
+				b.WriteString(color + string(c) + ansiReset)
+			} else {
+				b.WriteRune(c)
+			}
+		}
+		b.WriteString("|\n")
+	}
+	b.WriteString(border)
+	fmt.Fprintf(&b, "%c датчик  %c не работает  %c цель  %c оценка  %c цель на оценке  %c препятствие\n",
+		sensorSymbol, downSymbol, targetSymbol, estimateSymbol, matchSymbol, obstacleSymbol)
+	writeTargetTable(&b, state, opts.MaxRows)
+	return b.String(), nil
+}
+
+// writeTargetTable lists the targets by label or ID, at most maxRows of them unless it
+// is 0.
+func writeTargetTable(b *strings.Builder, state simulation.SimState, maxRows int) {
+	f := common.DefaultFormat()
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Цель\tПоложение\tОценка\tОшибка\tGDOP")
+	for i, t := range state.Targets {
+		if maxRows > 0 && i == maxRows {
+			tw.Flush()
+			fmt.Fprintf(b, "... и ещё %d\n", len(state.Targets)-maxRows)
+			return
+		}
+		id := t.Label // Shown in full; random IDs are cut to their prefix
+		if id == "" {
+			id = t.ID
+			if len(id) > 8 {
+				id = id[:8]
+			}
+		}
+		if t.Estimate.Position == nil {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\n", id, f.Vector(t.Position))
+			continue
+		}
+		errText := "-"
+		if t.Error >= 0 {
+			errText = f.Float(t.Error)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f\n", id, f.Vector(t.Position), f.Vector(t.Estimate.Position), errText, t.Estimate.GDOP)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,126 @@
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"multilateration-sim/internal/simulation"
+	"strings"
+	"time"
+)
+
+// ANSI sequences of the full-screen view.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+)
+
+// Viewer runs a simulation in real time and redraws it on a terminal every frame.
+// Commands are read line by line (a terminal in its normal mode sends a line on Enter):
+// p (or an empty line) pauses/resumes, . single-steps, + and - change the speed, q quits.
+type Viewer struct {
+	runner *simulation.SimulationRunner
+	out    io.Writer
+	opts   Options
+	status string // Outcome of the last command, shown in the header
+}
+
+// NewViewer creates a viewer of the runner's simulation writing to out. Unless opts sets
+// the view bounds, the map shows the simulation bounds along the two axes, so it does
+// not jump around as targets move.
+func NewViewer(runner *simulation.SimulationRunner, out io.Writer, opts Options) *Viewer {
+	if opts.Bounds == nil {
+		bounds := runner.Simulation().GetBounds()
+		if opts.AxisX >= 0 && opts.AxisY >= 0 && 2*opts.AxisX+1 < len(bounds) && 2*opts.AxisY+1 < len(bounds) {
+			opts.Bounds = []float64{bounds[2*opts.AxisX], bounds[2*opts.AxisX+1], bounds[2*opts.AxisY], bounds[2*opts.AxisY+1]}
+		}
+	}
+	return &Viewer{runner: runner, out: out, opts: opts}
+}
+
+// Frame returns the current view: a status line followed by the map and target table.
+func (v *Viewer) Frame() (string, error) {
+	state := v.runner.Simulation().Snapshot()
+	body, err := Render(state, v.opts)
+	if err != nil {
+		return "", err
+	}
+	header := fmt.Sprintf("Время: %.2f с  Шаг: %d  Скорость: x%g", state.Time, state.Tick, v.runner.Speed())
+	if v.runner.Paused() {
+		header += "  [ПАУЗА]"
+	}
+	if v.status != "" {
+		header += "  " + v.status
+	}
+	return header + "\n" + body + "p: пауза  .: шаг  +/-: скорость  q: выход\n", nil
+}
+
+// Run advances the simulation in real time and redraws every frame until ctx is done or
+// q is read from in (nil for no commands). It returns ctx.Err() or nil on q. A goroutine
+// blocked reading in is left behind when ctx ends first.
+func (v *Viewer) Run(ctx context.Context, in io.Reader, frame time.Duration) error {
+	if frame <= 0 {
+		return fmt.Errorf("frame duration must be positive, got %s", frame)
+	}
+	commands := make(chan string)
+	if in != nil {
+		go func() {
+			scanner := bufio.NewScanner(in)
+			for scanner.Scan() {
+				select {
+				case commands <- strings.TrimSpace(scanner.Text()):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	fmt.Fprint(v.out, hideCursor)
+	defer fmt.Fprint(v.out, showCursor)
+
+	ticker := time.NewTicker(frame)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case cmd := <-commands:
+			if !v.handleCommand(cmd) {
+				return nil
+			}
+		case now := <-ticker.C:
+			v.runner.Advance(now.Sub(last))
+			last = now
+		}
+		text, err := v.Frame()
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(v.out, clearScreen+text); err != nil {
+			return fmt.Errorf("write frame: %w", err)
+		}
+	}
+}
+
+// handleCommand applies a command line; false means quit.
+func (v *Viewer) handleCommand(cmd string) bool {
+	v.status = ""
+	switch cmd {
+	case "q":
+		return false
+	case "p", "":
+		v.runner.SetPaused(!v.runner.Paused())
+	case ".":
+		v.runner.StepOnce()
+	case "+":
+		v.runner.SetSpeed(v.runner.Speed() * 2)
+	case "-":
+		v.runner.SetSpeed(v.runner.Speed() / 2)
+	default:
+		v.status = fmt.Sprintf("неизвестная команда %q", cmd)
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package tracking_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+)
+
+// Smooth a target moving along the x axis; the third update has only two ranges,
+// which a snapshot solver cannot use, but odometry keeps the pose well defined.
+func ExampleFactorGraphSmoother() {
+	anchors := []common.Vector{{0, 0}, {100, 0}, {0, 100}}
+	smoother, _ := tracking.NewFactorGraphSmoother(2, tracking.DefaultSmootherConfig())
+
+	for step := 0; step < 3; step++ {
+		truth := common.Vector{10 + 10*float64(step), 20}
+		visible := anchors
+		if step == 2 {
+			visible = anchors[:2]
+		}
+		var measurements []multilateration.Measurement
+		for _, a := range visible {
+			d, _ := a.Distance(truth)
+			measurements = append(measurements, multilateration.Measurement{SensorPosition: a, Distance: d})
+		}
+		var odometry common.Vector
+		if step > 0 {
+			odometry = common.Vector{10, 0}
+		}
+		position, err := smoother.Update(float64(step), measurements, odometry)
+		fmt.Println(position, err)
+	}
+	// Output:
+	// [10.000, 20.000] <nil>
+	// [20.000, 20.000] <nil>
+	// [30.000, 20.000] <nil>
+}
+
+// Smooth noisy fixes of a target moving at constant velocity: the smoothed track,
+// which also uses the later fixes, is closer to the truth than the real-time one.
+func ExampleSmoothRTS() {
+	noise := []float64{3, -4, 2, 5, -3, -1, 4, -5, 1, 2, -2, 3}
+	var fixes []tracking.PositionFix
+	for i, n := range noise {
+		t := float64(i)
+		fixes = append(fixes, tracking.PositionFix{Time: t, Position: common.Vector{10 * t, 50 + n}, Variance: common.Vector{16, 16}})
+	}
+	fixes[6].Position = nil // No estimate at t = 6
+
+	filtered, smoothed, _ := tracking.SmoothRTS(fixes, tracking.RTSConfig{ProcessSigma: 1, MeasurementSigma: 4, InitialSpeed: 20})
+	var filteredErr, smoothedErr float64
+	for i := range fixes {
+		truth := common.Vector{10 * float64(i), 50}
+		fe, _ := filtered[i].Distance(truth)
+		se, _ := smoothed[i].Distance(truth)
+		filteredErr += fe / float64(len(fixes))
+		smoothedErr += se / float64(len(fixes))
+	}
+	fmt.Printf("mean error: real-time %.2f, smoothed %.2f\n", filteredErr, smoothedErr)
+	fmt.Printf("gap at t=6: %.1f\n", smoothed[6])
+	// Output:
+	// mean error: real-time 1.70, smoothed 0.66
+	// gap at t=6: [60.0 49.0]
+}
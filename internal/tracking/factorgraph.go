@@ -0,0 +1,239 @@
+package tracking
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SmootherConfig configures a FactorGraphSmoother.
+type SmootherConfig struct {
+	Window        int     // Number of poses kept in the graph
+	RangeSigma    float64 // Standard deviation of range factors (units)
+	OdometrySigma float64 // Standard deviation of odometry factors (units per update)
+	ProcessSigma  float64 // Random-walk prior between poses without odometry (units per sqrt(s))
+	PriorSigma    float64 // Standard deviation of the prior left on the oldest pose after marginalization
+	Kernel        Kernel  // Robust kernel applied to range factors, nil means L2Kernel
+	MaxIterations int     // Gauss-Newton iterations per update
+}
+
+// DefaultSmootherConfig returns a configuration suitable for the default simulation scale.
+func DefaultSmootherConfig() SmootherConfig {
+	return SmootherConfig{
+		Window:        20,
+		RangeSigma:    5,
+		OdometrySigma: 2,
+		ProcessSigma:  50,
+		PriorSigma:    10,
+		Kernel:        HuberKernel(1.345),
+		MaxIterations: 10,
+	}
+}
+
+// poseNode is one variable of the graph together with the factors attached to it.
+type poseNode struct {
+	time         float64
+	position     common.Vector
+	measurements []multilateration.Measurement // Range factors to known anchors
+	odometry     common.Vector                 // Between factor from the previous node, nil = process prior
+	prior        common.Vector                 // Absolute prior, set on the oldest node after marginalization
+}
+
+// FactorGraphSmoother is a sliding-window smoother: the last Window poses are
+// variables of a factor graph with range, odometry (or random-walk) and prior
+// factors, re-optimized with Gauss-Newton on every update. Older poses are
+// dropped and summarized by a prior on the new oldest pose.
+type FactorGraphSmoother struct {
+	dimension int
+	config    SmootherConfig
+	nodes     []poseNode
+}
+
+// NewFactorGraphSmoother creates a smoother for the given dimension.
+func NewFactorGraphSmoother(dimension int, config SmootherConfig) (*FactorGraphSmoother, error) {
+	if dimension < 1 {
+		return nil, fmt.Errorf("dimension must be positive, got %d", dimension)
+	}
+	if config.Window < 1 {
+		return nil, fmt.Errorf("window must be at least 1, got %d", config.Window)
+	}
+	if config.RangeSigma <= 0 || config.OdometrySigma <= 0 || config.ProcessSigma <= 0 || config.PriorSigma <= 0 {
+		return nil, fmt.Errorf("all sigmas must be positive")
+	}
+	if config.Kernel == nil {
+		config.Kernel = L2Kernel
+	}
+	if config.MaxIterations < 1 {
+		config.MaxIterations = 1
+	}
+	return &FactorGraphSmoother{dimension: dimension, config: config}, nil
+}
+
+// Update implements Tracker.
+func (f *FactorGraphSmoother) Update(t float64, measurements []multilateration.Measurement, odometry common.Vector) (common.Vector, error) {
+	if odometry != nil && odometry.Dimension() != f.dimension {
+		return nil, fmt.Errorf("odometry dimension %d does not match %d", odometry.Dimension(), f.dimension)
+	}
+	node := poseNode{time: t, measurements: measurements}
+	if odometry != nil {
+		node.odometry = odometry.Clone()
+	}
+
+	// Initial guess: snapshot solution if possible, otherwise predict from the previous pose
+	snapshot, snapErr := multilateration.SolveLeastSquares(measurements, f.dimension)
+	switch {
+	case snapErr == nil:
+		node.position = snapshot.Position
+	case len(f.nodes) > 0:
+		node.position = f.nodes[len(f.nodes)-1].position.Clone()
+		if odometry != nil {
+			node.position, _ = node.position.Add(odometry)
+		}
+	default:
+		return nil, fmt.Errorf("cannot initialize smoother: %w", snapErr)
+	}
+	if len(f.nodes) == 0 {
+		node.odometry = nil // Nothing to be relative to
+	}
+
+	f.nodes = append(f.nodes, node)
+	if len(f.nodes) > f.config.Window {
+		f.marginalizeOldest()
+	}
+	if err := f.optimize(); err != nil {
+		return nil, err
+	}
+	return f.nodes[len(f.nodes)-1].position.Clone(), nil
+}
+
+// Trajectory returns the smoothed poses of the current window, oldest first.
+func (f *FactorGraphSmoother) Trajectory() []common.Vector {
+	poses := make([]common.Vector, len(f.nodes))
+	for i, n := range f.nodes {
+		poses[i] = n.position.Clone()
+	}
+	return poses
+}
+
+// Velocity implements VelocityTracker. Range-rate measurements of the latest update are
+// preferred; otherwise the velocity is the finite difference of the last two smoothed poses.
+func (f *FactorGraphSmoother) Velocity() common.Vector {
+	if len(f.nodes) == 0 {
+		return nil
+	}
+	last := f.nodes[len(f.nodes)-1]
+	if v, err := multilateration.EstimateVelocity(last.measurements, last.position); err == nil {
+		return v
+	}
+	if len(f.nodes) < 2 {
+		return nil
+	}
+	prev := f.nodes[len(f.nodes)-2]
+	dt := last.time - prev.time
+	if dt <= 0 {
+		return nil
+	}
+	delta, err := last.position.Subtract(prev.position)
+	if err != nil {
+		return nil
+	}
+	return delta.MultiplyByScalar(1 / dt)
+}
+
+// marginalizeOldest drops the oldest pose and anchors the new oldest one with a prior
+// at its current estimate (a cheap approximation of proper Schur-complement marginalization).
+func (f *FactorGraphSmoother) marginalizeOldest() {
+	f.nodes = f.nodes[1:]
+	f.nodes[0].prior = f.nodes[0].position.Clone()
+	f.nodes[0].odometry = nil
+}
+
+// optimize runs Gauss-Newton with iteratively reweighted range factors over the window.
+func (f *FactorGraphSmoother) optimize() error {
+	dim := f.dimension
+	numVars := len(f.nodes) * dim
+	for iter := 0; iter < f.config.MaxIterations; iter++ {
+		hessian := mat.NewSymDense(numVars, nil)
+		gradient := mat.NewVecDense(numVars, nil)
+		// addLinear accumulates a residual r with Jacobian entries jac at the given variable indices.
+		addLinear := func(idx []int, jac []float64, r, weight float64) {
+			for a, i := range idx {
+				gradient.SetVec(i, gradient.AtVec(i)+weight*jac[a]*r)
+				for b, j := range idx {
+					if j >= i {
+						hessian.SetSym(i, j, hessian.At(i, j)+weight*jac[a]*jac[b])
+					}
+				}
+			}
+		}
+
+		for k, node := range f.nodes {
+			base := k * dim
+			// Range factors: r = (|p - s| - d) / sigma
+			for _, m := range node.measurements {
+				diff, err := node.position.Subtract(m.SensorPosition)
+				if err != nil {
+					return fmt.Errorf("range factor: %w", err)
+				}
+				dist := diff.Norm()
+				if dist == 0 {
+					continue
+				}
+				r := (dist - m.Distance) / f.config.RangeSigma
+				idx := make([]int, dim)
+				jac := make([]float64, dim)
+				for c := 0; c < dim; c++ {
+					idx[c] = base + c
+					jac[c] = diff[c] / dist / f.config.RangeSigma
+				}
+				addLinear(idx, jac, r, f.config.Kernel(r))
+			}
+			// Prior factor: r = (p - prior) / sigma per axis
+			if node.prior != nil {
+				for c := 0; c < dim; c++ {
+					r := (node.position[c] - node.prior[c]) / f.config.PriorSigma
+					addLinear([]int{base + c}, []float64{1 / f.config.PriorSigma}, r, 1)
+				}
+			}
+			// Between factor to the previous pose: r = (p_k - p_{k-1} - delta) / sigma
+			if k > 0 {
+				prev := f.nodes[k-1]
+				sigma := f.config.ProcessSigma * math.Sqrt(math.Max(node.time-prev.time, 1e-3))
+				if node.odometry != nil {
+					sigma = f.config.OdometrySigma
+				}
+				for c := 0; c < dim; c++ {
+					delta := 0.0
+					if node.odometry != nil {
+						delta = node.odometry[c]
+					}
+					r := (node.position[c] - prev.position[c] - delta) / sigma
+					addLinear([]int{base - dim + c, base + c}, []float64{-1 / sigma, 1 / sigma}, r, 1)
+				}
+			}
+		}
+
+		// Small damping keeps the system solvable for poses with too few ranges
+		for i := 0; i < numVars; i++ {
+			hessian.SetSym(i, i, hessian.At(i, i)+1e-9)
+		}
+		var step mat.VecDense
+		if err := step.SolveVec(hessian, gradient); err != nil {
+			return fmt.Errorf("factor graph solve failed: %w", err)
+		}
+		steps, err := common.Vector(step.RawVector().Data).Split(dim)
+		if err != nil {
+			return fmt.Errorf("factor graph step: %w", err)
+		}
+		for k, d := range steps {
+			_ = f.nodes[k].position.AddScaledInPlace(d, -1) // Same dimension as the step
+		}
+		if mat.Norm(&step, 2) < 1e-6 {
+			break
+		}
+	}
+	return nil
+}
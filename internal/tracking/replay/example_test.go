@@ -0,0 +1,123 @@
+package replay_test
+
+import (
+	"bytes"
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/simulation"
+	"multilateration-sim/internal/tracking"
+	"multilateration-sim/internal/tracking/replay"
+	"os"
+	"time"
+)
+
+// Record the trackers of a simulation run, cut a few steps out of it and replay them
+// through a fresh tracker, as a unit test of the tracking stack would.
+func ExampleRecorder() {
+	sim, _ := simulation.NewSimulation(2, []float64{-100, 100, -100, 100}, time.Second/10)
+	for _, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, nil))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 5})
+	_ = sim.AddObject(target)
+
+	newTracker := func(string) tracking.Tracker {
+		smoother, _ := tracking.NewFactorGraphSmoother(2, tracking.DefaultSmootherConfig())
+		return smoother
+	}
+	recorder := replay.NewRecorder(2)
+	sim.SetTrackerFactory(recorder.Wrap(newTracker))
+	for i := 0; i < 50; i++ {
+		sim.Step(0.1)
+	}
+
+	fixture, err := recorder.Excerpt("drift", target.GetID(), 3.0, 3.25, 2)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%d steps, %d of them warm-up\n", len(fixture.Steps), fixture.Warmup)
+	fmt.Println("exact replay:", replay.Check(newTracker(""), fixture))
+
+	// Two warm-up steps rebuild the smoother's window only approximately
+	fixture.Tolerance = 0.01
+	fmt.Println("replay:", replay.Check(newTracker(""), fixture))
+	// Output:
+	// 5 steps, 2 of them warm-up
+	// exact replay: drift: step 2 (t=3 s): got [29.926, 14.949], expected [29.926, 14.949] (off by 0.000586528)
+	// replay: <nil>
+}
+
+func ExampleWriteGo() {
+	fixture := replay.Fixture{
+		Name:      "two ranges",
+		Dimension: 2,
+		Steps: []replay.Step{{
+			Time: 1,
+			Measurements: []multilateration.Measurement{
+				{SensorPosition: common.Vector{0, 0}, Distance: 5},
+				{SensorPosition: common.Vector{10, 0}, Distance: 5},
+			},
+			Err: "cannot initialize smoother",
+		}},
+	}
+	_ = replay.WriteGo(os.Stdout, fixture, "tracking_test", "twoRanges")
+	// Output:
+	// package tracking_test
+	//
+	// import (
+	// 	"multilateration-sim/internal/common"
+	// 	"multilateration-sim/internal/multilateration"
+	// 	"multilateration-sim/internal/tracking/replay"
+	// )
+	//
+	// // twoRanges was cut from a simulation recording by replay.WriteGo.
+	// var twoRanges = replay.Fixture{
+	// 	Name:      "two ranges",
+	// 	Dimension: 2,
+	// 	Steps: []replay.Step{
+	// 		{Time: 1, Measurements: []multilateration.Measurement{
+	// 			{SensorPosition: common.Vector{0, 0}, Distance: 5},
+	// 			{SensorPosition: common.Vector{10, 0}, Distance: 5},
+	// 		}, Err: "cannot initialize smoother"},
+	// 	},
+	// }
+}
+
+// Record the solver input of a noisy run, then evaluate two solvers on exactly the
+// same measurements after a round trip through the log file format.
+func ExampleCompare() {
+	sim, _ := simulation.NewSimulation(2, []float64{-100, 100, -100, 100}, time.Second/10)
+	sim.SetSeeds(simulation.NewSeeds(7))
+	for _, pos := range []common.Vector{{-100, -100}, {100, -100}, {-100, 100}, {100, 100}} {
+		_ = sim.AddObject(simulation.NewSensor(pos, 0, simulation.GaussianNoise(0.5)))
+	}
+	target := simulation.NewTargetWithMotion(common.Vector{0, 0}, simulation.NewConstantVelocityMotion())
+	_ = target.SetVelocity(common.Vector{10, 5})
+	_ = sim.AddObject(target)
+	recorder := export.NewMeasurementRecorder(sim)
+	for i := 0; i < 50; i++ {
+		sim.Step(0.1)
+	}
+
+	var file bytes.Buffer
+	_ = recorder.WriteJSONL(&file)
+	measurements, err := export.ReadMeasurementLog(&file)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	results, _ := replay.Compare(measurements,
+		replay.Pipeline{Name: "ls", Solver: multilateration.SolveLeastSquares},
+		replay.Pipeline{Name: "robust", Solver: multilateration.RobustSolver(multilateration.DefaultRobustOptions())},
+	)
+	for _, r := range results {
+		fmt.Printf("%s: %d/%d localized, RMSE %.2f\n", r.Pipeline, r.Localized, r.Records, r.Error.RMSE)
+	}
+	// Output:
+	// ls: 50/50 localized, RMSE 0.58
+	// robust: 50/50 localized, RMSE 0.55
+}
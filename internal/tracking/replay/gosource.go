@@ -0,0 +1,92 @@
+package replay
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"strconv"
+	"strings"
+)
+
+// WriteGo writes the fixture as a Go source file of package pkg declaring it as the
+// variable varName, ready to be dropped next to a test that calls Check. Only the
+// fields that are set are written, so short excerpts stay readable in review.
+func WriteGo(w io.Writer, f Fixture, pkg, varName string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"multilateration-sim/internal/common\"\n\t\"multilateration-sim/internal/multilateration\"\n\t\"multilateration-sim/internal/tracking/replay\"\n)\n\n")
+	fmt.Fprintf(&b, "// %s was cut from a simulation recording by replay.WriteGo.\n", varName)
+	fmt.Fprintf(&b, "var %s = replay.Fixture{\n", varName)
+	fmt.Fprintf(&b, "Name: %q,\nDimension: %d,\n", f.Name, f.Dimension)
+	if f.Warmup > 0 {
+		fmt.Fprintf(&b, "Warmup: %d,\n", f.Warmup)
+	}
+	if f.Tolerance > 0 {
+		fmt.Fprintf(&b, "Tolerance: %s,\n", formatFloat(f.Tolerance))
+	}
+	b.WriteString("Steps: []replay.Step{\n")
+	for _, step := range f.Steps {
+		fmt.Fprintf(&b, "{Time: %s, Measurements: []multilateration.Measurement{\n", formatFloat(step.Time))
+		for _, m := range step.Measurements {
+			fmt.Fprintf(&b, "%s,\n", measurementLiteral(m))
+		}
+		b.WriteString("}")
+		if step.Odometry != nil {
+			fmt.Fprintf(&b, ", Odometry: %s", vectorLiteral(step.Odometry))
+		}
+		if step.Expected != nil {
+			fmt.Fprintf(&b, ", Expected: %s", vectorLiteral(step.Expected))
+		}
+		if step.Err != "" {
+			fmt.Fprintf(&b, ", Err: %q", step.Err)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("},\n}\n")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("format fixture source: %w", err)
+	}
+	if _, err := w.Write(src); err != nil {
+		return fmt.Errorf("write fixture source: %w", err)
+	}
+	return nil
+}
+
+// measurementLiteral returns a composite literal with the non-zero fields of m.
+func measurementLiteral(m multilateration.Measurement) string {
+	fields := []string{}
+	if m.SensorID != "" {
+		fields = append(fields, fmt.Sprintf("SensorID: %q", m.SensorID))
+	}
+	fields = append(fields, "SensorPosition: "+vectorLiteral(m.SensorPosition), "Distance: "+formatFloat(m.Distance))
+	if m.Timestamp != 0 {
+		fields = append(fields, "Timestamp: "+formatFloat(m.Timestamp))
+	}
+	if m.Variance != 0 {
+		fields = append(fields, "Variance: "+formatFloat(m.Variance))
+	}
+	if m.HasRangeRate {
+		fields = append(fields, "RangeRate: "+formatFloat(m.RangeRate), "HasRangeRate: true")
+	}
+	if m.SensorVelocity != nil {
+		fields = append(fields, "SensorVelocity: "+vectorLiteral(m.SensorVelocity))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+func vectorLiteral(v common.Vector) string {
+	parts := make([]string, len(v))
+	for i, x := range v {
+		parts[i] = formatFloat(x)
+	}
+	return "common.Vector{" + strings.Join(parts, ", ") + "}"
+}
+
+// formatFloat prints the shortest representation that parses back to exactly x.
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/export"
+	"multilateration-sim/internal/metrics"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+	"sort"
+)
+
+// Pipeline is a solver and tracker combination to evaluate on a measurement log.
+type Pipeline struct {
+	Name    string
+	Solver  multilateration.SolveFunc              // Snapshot solver, nil to rely on the tracker alone
+	Tracker func(targetID string) tracking.Tracker // nil for snapshot estimates only
+}
+
+// Result is the outcome of running a measurement log through one pipeline.
+type Result struct {
+	Pipeline        string
+	Tracks          map[string][]export.TrackPoint // targetID -> one point per record, oldest first
+	Error           metrics.TrajectoryError        // Over all estimated target-steps, zero if there were none
+	Records         int                            // Target-steps in the log
+	Localized       int                            // Target-steps with an estimate
+	SolverFailures  int
+	TrackerFailures int
+}
+
+// LocalizedFraction is the share of target-steps that produced an estimate.
+func (r Result) LocalizedFraction() float64 {
+	if r.Records == 0 {
+		return 0
+	}
+	return float64(r.Localized) / float64(r.Records)
+}
+
+// TargetIDs returns the IDs of all targets in the result, sorted.
+func (r Result) TargetIDs() []string {
+	ids := make([]string, 0, len(r.Tracks))
+	for id := range r.Tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Run feeds a recorded log through a pipeline the way the simulation does: the solver
+// runs when at least dimension+1 measurements are available, and every record also
+// goes to the target's tracker, whose output replaces the snapshot estimate.
+func Run(log export.MeasurementLog, p Pipeline) (Result, error) {
+	if p.Solver == nil && p.Tracker == nil {
+		return Result{}, fmt.Errorf("pipeline %q has neither a solver nor a tracker", p.Name)
+	}
+	result := Result{Pipeline: p.Name, Tracks: make(map[string][]export.TrackPoint), Records: len(log.Records)}
+	trackers := make(map[string]tracking.Tracker)
+	var truths, estimates []common.Vector
+	for _, r := range log.Records {
+		var estimate common.Vector
+		if p.Solver != nil && len(r.Measurements) > log.Dimension {
+			solution, err := p.Solver(cloneMeasurements(r.Measurements), log.Dimension)
+			if err != nil {
+				result.SolverFailures++
+			} else {
+				estimate = solution.Position
+			}
+		}
+		if p.Tracker != nil {
+			tracker, ok := trackers[r.TargetID]
+			if !ok {
+				tracker = p.Tracker(r.TargetID)
+				trackers[r.TargetID] = tracker
+			}
+			if tracker != nil {
+				position, err := tracker.Update(r.Time, cloneMeasurements(r.Measurements), nil)
+				if err != nil {
+					result.TrackerFailures++ // Keeps the snapshot estimate, as the simulation does
+				} else if position != nil {
+					estimate = position
+				}
+			}
+		}
+
+		point := export.TrackPoint{Time: r.Time, Truth: r.Truth}
+		if estimate != nil {
+			point.Estimate = estimate.Clone()
+			result.Localized++
+			truths, estimates = append(truths, r.Truth), append(estimates, point.Estimate)
+		}
+		result.Tracks[r.TargetID] = append(result.Tracks[r.TargetID], point)
+	}
+	if len(estimates) > 0 {
+		ate, err := metrics.AbsoluteTrajectoryError(truths, estimates, metrics.AlignNone)
+		if err != nil {
+			return Result{}, fmt.Errorf("pipeline %q: %w", p.Name, err)
+		}
+		result.Error = ate
+	}
+	return result, nil
+}
+
+// Compare runs the same log through every pipeline, in order.
+func Compare(log export.MeasurementLog, pipelines ...Pipeline) ([]Result, error) {
+	results := make([]Result, 0, len(pipelines))
+	for _, p := range pipelines {
+		result, err := Run(log, p)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,192 @@
+// Package replay turns excerpts of long simulation runs into small deterministic
+// fixtures for the trackers: the measurements a tracker received, step by step, with
+// the outputs it produced. A regression spotted after minutes of simulation can be cut
+// down to a few steps, written out as Go source and kept as a fast unit test.
+//
+// Whole runs recorded as measurement logs (export.MeasurementRecorder) can be re-run
+// through other solver and tracker combinations with Run and Compare, so algorithm
+// changes are evaluated on identical data.
+package replay
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/tracking"
+	"sort"
+	"sync"
+)
+
+// DefaultTolerance is the deviation from the expected position (units) accepted by
+// Check when the fixture does not set one.
+const DefaultTolerance = 1e-6
+
+// Step is one tracker update and its recorded result.
+type Step struct {
+	Time         float64
+	Measurements []multilateration.Measurement
+	Odometry     common.Vector // nil if unknown
+	Expected     common.Vector // Tracker output, nil if the update failed
+	Err          string        // Error message of a failed update, "" otherwise
+}
+
+// Fixture is a replayable sequence of tracker updates. The first Warmup steps are fed
+// to the tracker without checking their outputs, to rebuild the state a tracker had in
+// the middle of the run before the interesting steps.
+type Fixture struct {
+	Name      string
+	Dimension int
+	Warmup    int
+	Tolerance float64 // 0 means DefaultTolerance
+	Steps     []Step
+}
+
+// Recorder captures the updates of every tracker created through its Wrap.
+// Safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	dimension int
+	tracks    map[string][]Step // targetID -> updates, oldest first
+}
+
+// NewRecorder creates a recorder for trackers of the given dimension.
+func NewRecorder(dimension int) *Recorder {
+	return &Recorder{dimension: dimension, tracks: make(map[string][]Step)}
+}
+
+// Wrap returns a tracker factory that records the trackers made by factory. It can be
+// passed directly to Simulation.SetTrackerFactory.
+func (r *Recorder) Wrap(factory func(targetID string) tracking.Tracker) func(targetID string) tracking.Tracker {
+	return func(targetID string) tracking.Tracker {
+		inner := factory(targetID)
+		if inner == nil {
+			return nil
+		}
+		r.mu.Lock()
+		delete(r.tracks, targetID) // A new track starts from scratch
+		r.mu.Unlock()
+		return &recordingTracker{inner: inner, recorder: r, targetID: targetID}
+	}
+}
+
+// TargetIDs returns the IDs of all recorded targets, sorted.
+func (r *Recorder) TargetIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.tracks))
+	for id := range r.tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Excerpt cuts the updates of a target with from <= time <= to into a fixture,
+// preceded by up to warmup earlier updates.
+func (r *Recorder) Excerpt(name, targetID string, from, to float64, warmup int) (Fixture, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	steps, ok := r.tracks[targetID]
+	if !ok {
+		return Fixture{}, fmt.Errorf("no recorded track for target %s", targetID)
+	}
+	first := sort.Search(len(steps), func(i int) bool { return steps[i].Time >= from })
+	last := sort.Search(len(steps), func(i int) bool { return steps[i].Time > to })
+	if first == last {
+		return Fixture{}, fmt.Errorf("no updates of target %s between %g and %g s", targetID, from, to)
+	}
+	start := max(first-warmup, 0)
+	return Fixture{
+		Name:      name,
+		Dimension: r.dimension,
+		Warmup:    first - start,
+		Steps:     append([]Step(nil), steps[start:last]...),
+	}, nil
+}
+
+// Check replays the fixture through a fresh tracker and returns an error describing
+// the first checked step whose output deviates from the expectation.
+func Check(tracker tracking.Tracker, f Fixture) error {
+	tolerance := f.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	for i, step := range f.Steps {
+		var odometry common.Vector
+		if step.Odometry != nil {
+			odometry = step.Odometry.Clone()
+		}
+		position, err := tracker.Update(step.Time, cloneMeasurements(step.Measurements), odometry)
+		if i < f.Warmup {
+			continue
+		}
+		switch {
+		case step.Err != "" && err == nil:
+			return fmt.Errorf("%s: step %d (t=%.6g s): expected error %q, got position %v", f.Name, i, step.Time, step.Err, position)
+		case step.Err == "" && err != nil:
+			return fmt.Errorf("%s: step %d (t=%.6g s): unexpected error: %w", f.Name, i, step.Time, err)
+		case err != nil:
+			continue // Failed as recorded; messages may be reworded
+		}
+		deviation, dErr := position.Distance(step.Expected)
+		if dErr != nil {
+			return fmt.Errorf("%s: step %d (t=%.6g s): %w", f.Name, i, step.Time, dErr)
+		}
+		if deviation > tolerance || math.IsNaN(deviation) {
+			return fmt.Errorf("%s: step %d (t=%.6g s): got %v, expected %v (off by %.6g)", f.Name, i, step.Time, position, step.Expected, deviation)
+		}
+	}
+	return nil
+}
+
+// recordingTracker forwards to the wrapped tracker and records every update.
+type recordingTracker struct {
+	inner    tracking.Tracker
+	recorder *Recorder
+	targetID string
+}
+
+// Update implements tracking.Tracker.
+func (t *recordingTracker) Update(time float64, measurements []multilateration.Measurement, odometry common.Vector) (common.Vector, error) {
+	// Copy the inputs first: trackers may keep and even modify them
+	step := Step{Time: time, Measurements: cloneMeasurements(measurements)}
+	if odometry != nil {
+		step.Odometry = odometry.Clone()
+	}
+	position, err := t.inner.Update(time, measurements, odometry)
+	if err != nil {
+		step.Err = err.Error()
+	} else if position != nil {
+		step.Expected = position.Clone()
+	}
+	t.recorder.mu.Lock()
+	t.recorder.tracks[t.targetID] = append(t.recorder.tracks[t.targetID], step)
+	t.recorder.mu.Unlock()
+	return position, err
+}
+
+// Velocity forwards to the wrapped tracker, so velocity estimates stay available.
+func (t *recordingTracker) Velocity() common.Vector {
+	if vt, ok := t.inner.(tracking.VelocityTracker); ok {
+		return vt.Velocity()
+	}
+	return nil
+}
+
+func cloneMeasurements(ms []multilateration.Measurement) []multilateration.Measurement {
+	if ms == nil {
+		return nil
+	}
+	out := make([]multilateration.Measurement, len(ms))
+	for i, m := range ms {
+		out[i] = m
+		if m.SensorPosition != nil {
+			out[i].SensorPosition = m.SensorPosition.Clone()
+		}
+		if m.SensorVelocity != nil {
+			out[i].SensorVelocity = m.SensorVelocity.Clone()
+		}
+	}
+	return out
+}
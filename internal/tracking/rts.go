@@ -0,0 +1,180 @@
+package tracking
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+)
+
+// RTSConfig configures SmoothRTS.
+type RTSConfig struct {
+	ProcessSigma     float64 // White acceleration noise of the constant-velocity model (units/s^2 per sqrt(Hz))
+	MeasurementSigma float64 // Position noise per axis of fixes without a variance (units)
+	InitialSpeed     float64 // Standard deviation of the unknown initial velocity per axis (units/s)
+}
+
+// DefaultRTSConfig returns a configuration suitable for the default simulation scale.
+func DefaultRTSConfig() RTSConfig {
+	return RTSConfig{ProcessSigma: 20, MeasurementSigma: 5, InitialSpeed: 100}
+}
+
+// Validate checks the parameters.
+func (c RTSConfig) Validate() error {
+	if c.ProcessSigma <= 0 || c.MeasurementSigma <= 0 || c.InitialSpeed <= 0 {
+		return fmt.Errorf("all sigmas must be positive")
+	}
+	return nil
+}
+
+// PositionFix is a position estimate at one time, e.g. a snapshot solution.
+type PositionFix struct {
+	Time     float64
+	Position common.Vector // nil if there was no estimate at this time
+	Variance common.Vector // Per axis, nil uses RTSConfig.MeasurementSigma
+}
+
+// axisState is the position and velocity along one axis with their covariance, and the
+// prediction into the same fix before its update, which the backward pass needs.
+type axisState struct {
+	p, v, pp, pv, vv float64
+	predP, predV     float64
+	predPP, predPV   float64
+	predVV           float64
+	valid, predicted bool
+}
+
+// SmoothRTS smooths a track of position fixes, oldest first, with a constant-velocity
+// Kalman filter followed by the Rauch-Tung-Striebel backward pass, so every fix is revised
+// with the ones after it. The axes are filtered independently with the diagonal of the
+// fix variances. It returns the filtered (real-time) and the smoothed positions; both are
+// nil for the fixes before the first with a position.
+func SmoothRTS(fixes []PositionFix, config RTSConfig) (filtered, smoothed []common.Vector, err error) {
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	dim := -1
+	for i, fix := range fixes {
+		if fix.Position == nil {
+			continue
+		}
+		if dim < 0 {
+			dim = fix.Position.Dimension()
+		}
+		if fix.Position.Dimension() != dim || (fix.Variance != nil && fix.Variance.Dimension() != dim) {
+			return nil, nil, fmt.Errorf("fix %d has dimension %d, expected %d", i, fix.Position.Dimension(), dim)
+		}
+		if i > 0 && fix.Time < fixes[i-1].Time {
+			return nil, nil, fmt.Errorf("fix %d at %f precedes the previous one", i, fix.Time)
+		}
+	}
+	filtered = make([]common.Vector, len(fixes))
+	smoothed = make([]common.Vector, len(fixes))
+	if dim < 0 {
+		return filtered, smoothed, nil
+	}
+	for i := range fixes {
+		if fixes[i].Position != nil || i > 0 && filtered[i-1] != nil {
+			filtered[i], smoothed[i] = common.NewVector(dim), common.NewVector(dim)
+		}
+	}
+
+	q2 := config.ProcessSigma * config.ProcessSigma
+	states := make([]axisState, len(fixes))
+	for axis := range dim {
+		// Forward pass
+		var cur axisState
+		for i, fix := range fixes {
+			if cur.valid {
+				dt := fix.Time - fixes[i-1].Time
+				cur = predict(cur, dt, q2)
+			}
+			if fix.Position != nil {
+				r := config.MeasurementSigma * config.MeasurementSigma
+				if fix.Variance != nil && fix.Variance[axis] > 0 {
+					r = fix.Variance[axis]
+				}
+				if !cur.valid {
+					speed := config.InitialSpeed
+					cur = axisState{p: fix.Position[axis], pp: r, vv: speed * speed, valid: true}
+				} else {
+					cur = correct(cur, fix.Position[axis], r)
+				}
+			}
+			states[i] = cur
+			if cur.valid {
+				filtered[i][axis] = cur.p
+			}
+		}
+		// Backward pass
+		next := -1
+		for i := len(fixes) - 1; i >= 0; i-- {
+			st := states[i]
+			if !st.valid {
+				break
+			}
+			if next >= 0 {
+				st = rtsStep(st, states[next], fixes[next].Time-fixes[i].Time)
+				states[i] = st
+			}
+			smoothed[i][axis] = st.p
+			next = i
+		}
+	}
+	return filtered, smoothed, nil
+}
+
+// predict advances a state by dt seconds and remembers the prediction for the backward pass.
+func predict(s axisState, dt, q2 float64) axisState {
+	p := s.p + dt*s.v
+	pp := s.pp + 2*dt*s.pv + dt*dt*s.vv + q2*dt*dt*dt/3
+	pv := s.pv + dt*s.vv + q2*dt*dt/2
+	vv := s.vv + q2*dt
+	return axisState{
+		p: p, v: s.v, pp: pp, pv: pv, vv: vv, valid: true,
+		predP: p, predV: s.v, predPP: pp, predPV: pv, predVV: vv, predicted: true,
+	}
+}
+
+// correct updates a predicted state with a position observation of variance r.
+func correct(s axisState, z, r float64) axisState {
+	innovation := s.pp + r
+	kp, kv := s.pp/innovation, s.pv/innovation
+	residual := z - s.p
+	out := s
+	out.p += kp * residual
+	out.v += kv * residual
+	out.pp = (1 - kp) * s.pp
+	out.pv = (1 - kp) * s.pv
+	out.vv = s.vv - kv*s.pv
+	return out
+}
+
+// rtsStep revises the filtered state f with the smoothed state of the next fix, dt
+// seconds later, whose prediction is stored in next.
+func rtsStep(f, next axisState, dt float64) axisState {
+	if !next.predicted {
+		return f
+	}
+	// C = Pf F^T Ppred^-1
+	a, b := f.pp+dt*f.pv, f.pv // First row of Pf F^T
+	c, d := f.pv+dt*f.vv, f.vv // Second row
+	det := next.predPP*next.predVV - next.predPV*next.predPV
+	if det <= 0 {
+		return f
+	}
+	i00, i01, i11 := next.predVV/det, -next.predPV/det, next.predPP/det
+	c00, c01 := a*i00+b*i01, a*i01+b*i11
+	c10, c11 := c*i00+d*i01, c*i01+d*i11
+
+	dp, dv := next.p-next.predP, next.v-next.predV
+	out := f
+	out.p += c00*dp + c01*dv
+	out.v += c10*dp + c11*dv
+	// Ps = Pf + C (Ps_next - Ppred) C^T
+	epp, epv, evv := next.pp-next.predPP, next.pv-next.predPV, next.vv-next.predVV
+	m00, m01 := c00*epp+c01*epv, c00*epv+c01*evv
+	m10, m11 := c10*epp+c11*epv, c10*epv+c11*evv
+	out.pp += m00*c00 + m01*c01
+	out.pv += m00*c10 + m01*c11
+	out.vv += m10*c10 + m11*c11
+	return out // Keeps the prediction of f for the step before it
+}
@@ -0,0 +1,50 @@
+// Package tracking estimates target trajectories over time from range measurements,
+// as opposed to the per-step snapshot solvers of package multilateration.
+package tracking
+
+import (
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+)
+
+// Tracker fuses the measurements of one target over time.
+type Tracker interface {
+	// Update adds the measurements taken at time t (simulation seconds) and returns the
+	// current position estimate. odometry is the displacement since the previous update,
+	// nil if unknown. Fewer than dimension+1 measurements are allowed once initialized.
+	Update(t float64, measurements []multilateration.Measurement, odometry common.Vector) (common.Vector, error)
+}
+
+// VelocityTracker is implemented by trackers that also estimate the target velocity.
+type VelocityTracker interface {
+	Tracker
+	// Velocity returns the velocity at the latest update, nil if not available yet.
+	Velocity() common.Vector
+}
+
+// Kernel is a robust loss expressed as an IRLS weight for a normalized residual
+// (residual divided by its standard deviation).
+type Kernel func(normalizedResidual float64) float64
+
+// L2Kernel is the plain least-squares loss (every residual has weight 1).
+func L2Kernel(float64) float64 {
+	return 1
+}
+
+// HuberKernel is quadratic for |r| <= k and linear beyond, k is typically 1.345.
+func HuberKernel(k float64) Kernel {
+	return func(r float64) float64 {
+		if abs := math.Abs(r); abs > k {
+			return k / abs
+		}
+		return 1
+	}
+}
+
+// CauchyKernel down-weights large residuals more aggressively than Huber, c is typically 2.385.
+func CauchyKernel(c float64) Kernel {
+	return func(r float64) float64 {
+		return 1 / (1 + (r/c)*(r/c))
+	}
+}
@@ -0,0 +1,153 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/scenario"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var annotationColor = color.RGBA{160, 0, 160, 255} // Пурпурный
+
+var annotationTools = []string{scenario.AnnotationArrow, scenario.AnnotationCircle, scenario.AnnotationText}
+
+// annotator draws arrows, circles and text labels pinned to world coordinates.
+// Keys: A toggles the mode, Tab switches the tool, Backspace deletes the last
+// annotation (or character while typing), Enter finishes a label.
+type annotator struct {
+	active      bool
+	tool        int           // Index into annotationTools
+	anchor      common.Vector // First click of an arrow or circle, nil if none
+	typing      *scenario.Annotation
+	annotations []scenario.Annotation
+}
+
+// SetAnnotations replaces the annotations, e.g. with those loaded from a scenario.
+func (r *Renderer) SetAnnotations(annotations []scenario.Annotation) {
+	r.annotator.annotations = append([]scenario.Annotation(nil), annotations...)
+}
+
+// Annotations returns the current annotations, e.g. to save them with the scenario.
+func (r *Renderer) Annotations() []scenario.Annotation {
+	return append([]scenario.Annotation(nil), r.annotator.annotations...)
+}
+
+// handleAnnotations processes the annotation mode. Returns true while the mode owns the
+// keyboard and mouse.
+func (r *Renderer) handleAnnotations() bool {
+	a := &r.annotator
+	if a.typing != nil {
+		r.handleLabelTyping()
+		return true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		a.active = !a.active
+		a.anchor = nil
+	}
+	if !a.active {
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		a.tool = (a.tool + 1) % len(annotationTools)
+		a.anchor = nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(a.annotations) > 0 {
+		a.annotations = a.annotations[:len(a.annotations)-1]
+	}
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return true
+	}
+
+	mx, my := ebiten.CursorPosition()
+	point, ok := r.screenToSimulation(float64(mx), float64(my))
+	if !ok {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityWarning, Message: "аннотации недоступны для этой проекции"})
+		return true
+	}
+	switch tool := annotationTools[a.tool]; {
+	case tool == scenario.AnnotationText:
+		a.typing = &scenario.Annotation{Kind: tool, From: point}
+	case a.anchor == nil:
+		a.anchor = point
+	case tool == scenario.AnnotationArrow:
+		a.annotations = append(a.annotations, scenario.Annotation{Kind: tool, From: a.anchor, To: point})
+		a.anchor = nil
+	case tool == scenario.AnnotationCircle:
+		radius, _ := a.anchor.Distance(point)
+		a.annotations = append(a.annotations, scenario.Annotation{Kind: tool, From: a.anchor, Radius: radius})
+		a.anchor = nil
+	}
+	return true
+}
+
+// handleLabelTyping edits the text label being entered.
+func (r *Renderer) handleLabelTyping() {
+	a := &r.annotator
+	a.typing.Text += string(ebiten.AppendInputChars(nil))
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(a.typing.Text) > 0 {
+		runes := []rune(a.typing.Text)
+		a.typing.Text = string(runes[:len(runes)-1])
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if a.typing.Text != "" {
+			a.annotations = append(a.annotations, *a.typing)
+		}
+		a.typing = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		a.typing = nil
+	}
+}
+
+// drawAnnotations draws the annotations and the state of the annotation mode.
+func (r *Renderer) drawAnnotations(screen *ebiten.Image) {
+	a := &r.annotator
+	for _, ann := range a.annotations {
+		r.drawAnnotation(screen, ann)
+	}
+	if a.typing != nil {
+		r.drawAnnotation(screen, scenario.Annotation{Kind: scenario.AnnotationText, From: a.typing.From, Text: a.typing.Text + "_"})
+	}
+	if !a.active {
+		return
+	}
+	if a.anchor != nil {
+		if x, y, ok := r.pointToScreen(a.anchor, true); ok {
+			vector.DrawFilledCircle(screen, x, y, 3, annotationColor, true)
+		}
+	}
+	mx, my := ebiten.CursorPosition()
+	hint := fmt.Sprintf("Аннотации: %s (Tab: инструмент, Backspace: удалить, A: выкл)", annotationTools[a.tool])
+	ebitenutil.DebugPrintAt(screen, hint, mx+10, my+10)
+}
+
+func (r *Renderer) drawAnnotation(screen *ebiten.Image, ann scenario.Annotation) {
+	x0, y0, ok := r.pointToScreen(common.Vector(ann.From), true)
+	if !ok {
+		return
+	}
+	switch ann.Kind {
+	case scenario.AnnotationArrow:
+		x1, y1, ok := r.pointToScreen(common.Vector(ann.To), true)
+		if !ok {
+			return
+		}
+		vector.StrokeLine(screen, x0, y0, x1, y1, 2, annotationColor, true)
+		// Arrow head
+		angle := math.Atan2(float64(y1-y0), float64(x1-x0))
+		for _, side := range []float64{-1, 1} {
+			a := angle + math.Pi - side*math.Pi/7
+			vector.StrokeLine(screen, x1, y1, x1+float32(12*math.Cos(a)), y1+float32(12*math.Sin(a)), 2, annotationColor, true)
+		}
+	case scenario.AnnotationCircle:
+		vector.StrokeCircle(screen, x0, y0, float32(ann.Radius*r.scale), 2, annotationColor, true)
+	case scenario.AnnotationText:
+		ebitenutil.DebugPrintAt(screen, ann.Text, int(x0), int(y0))
+	}
+}
@@ -0,0 +1,43 @@
+package visualization_test
+
+import (
+	"fmt"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"multilateration-sim/internal/visualization"
+	"testing"
+)
+
+// Projecting a scene of 100 objects: PCA fits a covariance and its eigenvectors every
+// frame (O(objects d^2 + d^3)), MDS eigendecomposes the objects x objects distance
+// matrix (O(objects^3), independent of d), the random projection only transforms (O(objects d)).
+func BenchmarkProject(b *testing.B) {
+	projectors := map[string]func() visualization.Projector{
+		"pca":    func() visualization.Projector { return visualization.NewPCAProjector() },
+		"mds":    func() visualization.Projector { return visualization.NewMDSProjector(visualization.MDSEuclidean) },
+		"random": func() visualization.Projector { return visualization.NewRandomProjector(1) },
+	}
+	for _, name := range []string{"pca", "mds", "random"} {
+		for _, dim := range []int{3, 10, 25, 50} {
+			b.Run(fmt.Sprintf("%s/dim=%d", name, dim), func(b *testing.B) {
+				rng := rand.New(rand.NewSource(1))
+				objects := make([]simulation.SimulationObject, 100)
+				for i := range objects {
+					pos := common.NewVector(dim)
+					for j := range pos {
+						pos[j] = rng.Float64()*200 - 100
+					}
+					objects[i] = simulation.NewTarget(pos)
+				}
+				p := projectors[name]()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := visualization.Project(p, objects); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
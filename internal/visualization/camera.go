@@ -0,0 +1,84 @@
+package visualization
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	zoomStep    = 1.15   // Scale factor per mouse wheel notch
+	minZoomFit  = 0.01   // Smallest zoom relative to the auto-fit scale
+	maxZoomFit  = 1000.0 // Largest zoom relative to the auto-fit scale
+	fallbackFit = 1.0    // Auto-fit scale assumed before the first fit
+)
+
+// camera is the user-controlled view: the mouse wheel zooms around the cursor, dragging
+// with the middle button (or Ctrl+left) pans. Either one switches auto-fit off, so the
+// view stays on the region of interest; C switches auto-fit back on.
+type camera struct {
+	manual     bool    // Pan/zoom chosen by the user, calculateTransform keeps the view
+	fitScale   float64 // Scale of the last auto-fit, reference for the zoom limits
+	panning    bool
+	lastCursor [2]int
+}
+
+// SetAutoFit switches between fitting the whole scene into the window every frame
+// (the default) and keeping the current pan and zoom.
+func (r *Renderer) SetAutoFit(on bool) {
+	r.camera.manual = !on
+	r.camera.panning = false
+}
+
+// AutoFit reports whether the view follows the bounding box of the scene.
+func (r *Renderer) AutoFit() bool {
+	return !r.camera.manual
+}
+
+// handleCamera processes zoom, pan and the auto-fit key. Returns true while panning,
+// when the mouse buttons belong to the camera.
+func (r *Renderer) handleCamera() bool {
+	c := &r.camera
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		r.SetAutoFit(c.manual)
+	}
+
+	mx, my := ebiten.CursorPosition()
+	if _, wheelY := ebiten.Wheel(); wheelY != 0 && !r.logPanel.containsCursor() {
+		r.zoomAt(float64(mx), float64(my), math.Pow(zoomStep, wheelY))
+	}
+
+	panPressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) ||
+		ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && ebiten.IsKeyPressed(ebiten.KeyControl)
+	switch {
+	case !panPressed:
+		c.panning = false
+		return false
+	case !c.panning:
+		c.panning = true
+	default:
+		r.offsetX += float64(mx - c.lastCursor[0])
+		r.offsetY += float64(my - c.lastCursor[1])
+		c.manual = true
+	}
+	c.lastCursor = [2]int{mx, my}
+	return true
+}
+
+// zoomAt scales the view by factor, keeping the world point under (sx, sy) in place.
+func (r *Renderer) zoomAt(sx, sy, factor float64) {
+	if r.scale <= 0 {
+		return
+	}
+	fit := r.camera.fitScale
+	if fit <= 0 {
+		fit = fallbackFit
+	}
+	scale := math.Min(math.Max(r.scale*factor, fit*minZoomFit), fit*maxZoomFit)
+	wx, wy := r.screenToWorld(sx, sy)
+	r.scale = scale
+	r.offsetX = sx - wx*scale
+	r.offsetY = sy - wy*scale
+	r.camera.manual = true
+}
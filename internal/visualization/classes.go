@@ -0,0 +1,52 @@
+package visualization
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"multilateration-sim/internal/simulation"
+	"sort"
+	"strings"
+)
+
+// classPalette colours classified targets; unclassified targets keep targetColorBase.
+var classPalette = []color.RGBA{
+	{230, 120, 0, 255},  // Оранжевый
+	{150, 0, 200, 255},  // Фиолетовый
+	{0, 150, 150, 255},  // Бирюзовый
+	{200, 0, 120, 255},  // Малиновый
+	{120, 90, 0, 255},   // Оливковый
+	{90, 140, 255, 255}, // Голубой
+}
+
+// classColor returns the colour of a target class. It depends only on the name, so a
+// class keeps its colour between runs and scenarios.
+func classColor(class string) color.RGBA {
+	if class == "" {
+		return targetColorBase
+	}
+	h := fnv.New32a()
+	h.Write([]byte(class))
+	return classPalette[h.Sum32()%uint32(len(classPalette))]
+}
+
+// classSummary lists the target classes with their counts, e.g. "drone: 2, pedestrian: 3",
+// empty if no target is classified.
+func classSummary(targets []*simulation.Target) string {
+	counts := make(map[string]int)
+	for _, tar := range targets {
+		if class := tar.Class(); class != "" {
+			counts[class]++
+		}
+	}
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	parts := make([]string, len(classes))
+	for i, class := range classes {
+		parts[i] = fmt.Sprintf("%s: %d", class, counts[class])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,190 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	defaultGridStep = 10.0 // World units
+	maxGridLines    = 200  // The grid is not drawn when zoomed out further
+)
+
+var gridColor = color.RGBA{0, 0, 0, 30}
+
+// editor holds the state of interactive editing: dragging objects with the mouse,
+// snapping to a grid (G toggles it) and typing exact coordinates of the selected
+// object (Enter starts and applies the entry, Escape cancels it).
+type editor struct {
+	snap     bool
+	gridStep float64
+
+	dragging   string        // ID of the dragged object, "" if none
+	dragPos    common.Vector // Unsnapped position of the dragged object
+	lastCursor [2]int        // Cursor position of the previous frame while dragging
+
+	input *string // Coordinates being typed for the selected object, nil when not editing
+}
+
+// SetGridStep sets the grid spacing in world units and enables snapping; a step <= 0
+// disables it.
+func (r *Renderer) SetGridStep(step float64) {
+	r.editor.snap = step > 0
+	if step > 0 {
+		r.editor.gridStep = step
+	}
+}
+
+// snapToGrid rounds every coordinate to the grid if snapping is on.
+func (r *Renderer) snapToGrid(pos common.Vector) common.Vector {
+	if !r.editor.snap {
+		return pos
+	}
+	snapped := pos.Clone()
+	for i := range snapped {
+		snapped[i] = math.Round(snapped[i]/r.editor.gridStep) * r.editor.gridStep
+	}
+	return snapped
+}
+
+// handleSnapKey toggles grid snapping.
+func (r *Renderer) handleSnapKey() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		r.editor.snap = !r.editor.snap
+	}
+}
+
+// startDrag begins moving an object with the mouse.
+func (r *Renderer) startDrag(id string) {
+	obj, ok := r.sim.GetObject(id)
+	if !ok {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	r.editor.dragging = id
+	r.editor.dragPos = obj.GetPosition()
+	r.editor.lastCursor = [2]int{mx, my}
+}
+
+// handleDrag moves the dragged object with the cursor. Cursor movement is mapped into
+// the simulation space through the current projection, so in N-D scenes the object
+// moves within the projection plane. Returns true while dragging.
+func (r *Renderer) handleDrag() bool {
+	e := &r.editor
+	if e.dragging == "" {
+		return false
+	}
+	obj, ok := r.sim.GetObject(e.dragging)
+	if !ok || !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if ok {
+			r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: fmt.Sprintf("%s перемещён в %s", e.dragging, r.activeFormat().Vector(obj.GetPosition()))})
+		}
+		e.dragging = ""
+		return false
+	}
+
+	mx, my := ebiten.CursorPosition()
+	if [2]int{mx, my} == e.lastCursor {
+		return true
+	}
+	from, okFrom := r.screenToSimulation(float64(e.lastCursor[0]), float64(e.lastCursor[1]))
+	to, okTo := r.screenToSimulation(float64(mx), float64(my))
+	e.lastCursor = [2]int{mx, my}
+	if !okFrom || !okTo {
+		return true
+	}
+	delta, _ := to.Subtract(from)
+	e.dragPos, _ = e.dragPos.Add(delta)
+	_ = obj.SetPosition(r.snapToGrid(e.dragPos))
+	return true
+}
+
+// handleCoordinateEntry edits the coordinates of the selected object. Returns true while
+// the entry owns the keyboard.
+func (r *Renderer) handleCoordinateEntry() bool {
+	e := &r.editor
+	obj, selected := r.sim.GetObject(r.selectedID)
+	if e.input == nil {
+		if selected && inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			text := strings.Trim(fmt.Sprint([]float64(obj.GetPosition())), "[]")
+			e.input = &text
+			return true
+		}
+		return false
+	}
+	if !selected {
+		e.input = nil
+		return false
+	}
+
+	*e.input += string(ebiten.AppendInputChars(nil))
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(*e.input) > 0 {
+		*e.input = (*e.input)[:len(*e.input)-1] // Coordinates are ASCII
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		e.input = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		pos, err := parseCoordinates(*e.input, r.sim.GetDimension())
+		if err == nil {
+			err = obj.SetPosition(pos)
+		}
+		if err != nil {
+			r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("координаты: %v", err)})
+			return true // Keep editing
+		}
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: fmt.Sprintf("%s перемещён в %s", obj.GetID(), r.activeFormat().Vector(pos))})
+		e.input = nil
+	}
+	return true
+}
+
+// parseCoordinates parses dimension numbers separated by commas and/or spaces.
+func parseCoordinates(text string, dimension int) (common.Vector, error) {
+	fields := strings.FieldsFunc(text, func(c rune) bool { return c == ',' || c == ' ' || c == ';' })
+	if len(fields) != dimension {
+		return nil, fmt.Errorf("expected %d coordinates, got %d", dimension, len(fields))
+	}
+	pos := common.NewVector(dimension)
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("coordinate %d: %w", i, err)
+		}
+		pos[i] = v
+	}
+	return pos, nil
+}
+
+// drawGrid draws the snapping grid. Only for 1D/2D scenes, where the projection plane
+// is the simulation space itself.
+func (r *Renderer) drawGrid(screen *ebiten.Image) {
+	if !r.editor.snap || r.sim.GetDimension() > 2 || r.scale <= 0 {
+		return
+	}
+	step := r.editor.gridStep
+	minX, minY := r.screenToWorld(0, 0)
+	maxX, maxY := r.screenToWorld(float64(r.screenWidth), float64(r.screenHeight))
+	if (maxX-minX)/step > maxGridLines || (maxY-minY)/step > maxGridLines {
+		return
+	}
+	for x := math.Ceil(minX/step) * step; x <= maxX; x += step {
+		sx, _ := r.worldToScreen(x, 0)
+		vector.StrokeLine(screen, sx, 0, sx, float32(r.screenHeight), 1, gridColor, false)
+	}
+	if r.sim.GetDimension() < 2 {
+		return
+	}
+	for y := math.Ceil(minY/step) * step; y <= maxY; y += step {
+		_, sy := r.worldToScreen(0, y)
+		vector.StrokeLine(screen, 0, sy, float32(r.screenWidth), sy, 1, gridColor, false)
+	}
+}
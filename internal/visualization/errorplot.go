@@ -0,0 +1,144 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	defaultErrorPlotWindow = 30.0 // Seconds of simulation time shown
+	errorPlotWidth         = 320  // Pixels
+	errorPlotHeight        = 120  // Pixels
+)
+
+var errorPlotColor = color.RGBA{255, 120, 60, 255} // Оранжевый
+
+// errorSample is the mean localization error of one step, ok is false when no target
+// was localized with a known error.
+type errorSample struct {
+	time float64
+	mean float64
+	ok   bool
+}
+
+// errorPlot is a time-series chart of the mean localization error (O toggles it).
+type errorPlot struct {
+	hidden  bool
+	window  float64
+	samples []errorSample // Oldest first, covering at most window seconds
+}
+
+// SetErrorPlotWindow sets how many seconds of simulation time the error plot shows.
+func (r *Renderer) SetErrorPlotWindow(seconds float64) {
+	if seconds > 0 {
+		r.errorPlot.window = seconds
+		r.errorPlot.trim()
+	}
+}
+
+// recordErrorPlot appends the mean localization error of the step.
+func (r *Renderer) recordErrorPlot(report simulation.StepReport) {
+	p := &r.errorPlot
+	if n := len(p.samples); n > 0 && report.Time < p.samples[n-1].time {
+		p.samples = nil // Time went backwards, e.g. after a restart
+	}
+	sample := errorSample{time: report.Time}
+	count := 0
+	for _, t := range report.Targets {
+		if t.Outcome != simulation.OutcomeLocalized {
+			continue
+		}
+		if st, ok := report.State.Target(t.TargetID); ok && st.Error >= 0 {
+			sample.mean += st.Error
+			count++
+		}
+	}
+	if count > 0 {
+		sample.mean /= float64(count)
+		sample.ok = true
+	}
+	p.samples = append(p.samples, sample)
+	p.trim()
+}
+
+func (p *errorPlot) trim() {
+	if len(p.samples) == 0 {
+		return
+	}
+	oldest := p.samples[len(p.samples)-1].time - p.window
+	drop := 0
+	for drop < len(p.samples) && p.samples[drop].time < oldest {
+		drop++
+	}
+	p.samples = p.samples[drop:]
+}
+
+// handleErrorPlotKey toggles the error plot.
+func (r *Renderer) handleErrorPlotKey() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		r.errorPlot.hidden = !r.errorPlot.hidden
+	}
+}
+
+// drawErrorPlot draws the chart in the bottom-left corner, above the log panel. The
+// vertical axis starts at zero and ends at a round value above the largest error;
+// steps without a localized target break the line.
+func (r *Renderer) drawErrorPlot(screen *ebiten.Image) {
+	p := &r.errorPlot
+	if p.hidden || len(p.samples) == 0 {
+		return
+	}
+	x0 := float32(10)
+	y0 := float32(r.screenHeight-r.logPanel.height()) - errorPlotHeight - 10
+	vector.DrawFilledRect(screen, x0, y0, errorPlotWidth, errorPlotHeight, color.RGBA{0, 0, 0, 150}, false)
+
+	maxErr := 0.0
+	for _, s := range p.samples {
+		if s.ok {
+			maxErr = math.Max(maxErr, s.mean)
+		}
+	}
+	step := niceLength(maxErr)
+	top := math.Max(math.Ceil(maxErr/step)*step, step)
+
+	// Plot area inside the frame, leaving room for the labels
+	left, right := x0+6, x0+errorPlotWidth-6
+	upper, lower := y0+20, y0+errorPlotHeight-6
+	vector.StrokeLine(screen, left, lower, right, lower, 1, color.RGBA{200, 200, 200, 255}, false)
+	vector.StrokeLine(screen, left, upper, right, upper, 1, color.RGBA{120, 120, 120, 255}, false)
+
+	end := p.samples[len(p.samples)-1].time
+	toScreen := func(s errorSample) (float32, float32) {
+		x := right - float32((end-s.time)/p.window)*(right-left)
+		y := lower - float32(s.mean/top)*(lower-upper)
+		return x, y
+	}
+	var prevX, prevY float32
+	havePrev := false
+	for _, s := range p.samples {
+		if !s.ok {
+			havePrev = false
+			continue
+		}
+		x, y := toScreen(s)
+		if havePrev {
+			vector.StrokeLine(screen, prevX, prevY, x, y, 1.5, errorPlotColor, true)
+		}
+		prevX, prevY, havePrev = x, y, true
+	}
+
+	f := r.activeFormat()
+	last := "N/A"
+	if s := p.samples[len(p.samples)-1]; s.ok {
+		last = f.Float(s.mean)
+	}
+	label := fmt.Sprintf("Ошибка: %s (макс. шкалы %s, %.0f с)", last, f.Float(top), p.window)
+	ebitenutil.DebugPrintAt(screen, label, int(x0)+4, int(y0)+2)
+}
@@ -0,0 +1,44 @@
+package visualization_test
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/visualization"
+)
+
+// When the spread of the scene turns from the x axis towards z, a refitted basis follows at
+// once, a fixed one stays put and a smoothed one turns gradually. The length of the
+// projected unit x vector shows how much of the x axis remains in view.
+func ExamplePCAProjector_SetStabilization() {
+	before := targetsAt(common.Vector{-20, 0, 0}, common.Vector{20, 0, 0}, common.Vector{0, -10, 0}, common.Vector{0, 10, 0})
+	d := 30 / math.Sqrt2
+	after := targetsAt(common.Vector{-d, 0, -d}, common.Vector{d, 0, d}, common.Vector{0, -10, 0}, common.Vector{0, 10, 0})
+	inView := func(p *visualization.PCAProjector) float64 {
+		x, _ := p.Transform(common.Vector{1, 0, 0})
+		return x.Norm()
+	}
+
+	if err := visualization.NewPCAProjector().SetStabilization(visualization.PCASmoothed, 0); err != nil {
+		fmt.Println("smoothing 0 rejected")
+	}
+	for _, mode := range []struct {
+		name string
+		mode visualization.PCAStabilization
+	}{{"refit", visualization.PCARefit}, {"fixed", visualization.PCAFixed}, {"smoothed", visualization.PCASmoothed}} {
+		p := visualization.NewPCAProjector()
+		_ = p.SetStabilization(mode.mode, 0.25)
+		_ = p.Fit(before)
+		_ = p.Fit(after)
+		first := inView(p)
+		for range 50 {
+			_ = p.Fit(after)
+		}
+		fmt.Printf("%s: x in view %.2f after one fit, %.2f after 50 more\n", mode.name, first, inView(p))
+	}
+	// Output:
+	// smoothing 0 rejected
+	// refit: x in view 0.71 after one fit, 0.71 after 50 more
+	// fixed: x in view 1.00 after one fit, 1.00 after 50 more
+	// smoothed: x in view 0.98 after one fit, 0.71 after 50 more
+}
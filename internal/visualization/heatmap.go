@@ -0,0 +1,159 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	heatmapCellPixels = 8   // Resolution of the heatmap raster
+	heatmapGoodGDOP   = 1.5 // Drawn fully green
+	heatmapBadGDOP    = 6.0 // Drawn fully red
+)
+
+// heatmapMode selects what the field overlay shows.
+type heatmapMode int
+
+const (
+	heatmapOff      heatmapMode = iota
+	heatmapGDOP                 // GDOP a target would get at each point
+	heatmapCoverage             // Number of sensors having each point in range
+)
+
+// heatmapOverlay colors the field by localization quality (H cycles the modes).
+type heatmapOverlay struct {
+	mode   heatmapMode
+	image  *ebiten.Image // Low-resolution raster, scaled up when drawn
+	pixels []byte
+}
+
+// handleHeatmapKey cycles off -> GDOP -> coverage -> off.
+func (r *Renderer) handleHeatmapKey() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		r.heatmap.mode = (r.heatmap.mode + 1) % 3
+	}
+}
+
+// drawHeatmap sweeps a virtual target over a grid of the visible field and colors each
+// cell by what the sensors that would have it in range can do there. In GDOP mode:
+// green for good geometry, red for poor, gray where fewer than dimension+1 sensors
+// cover it. In coverage mode: by the sensor count, red for none up to green for
+// dimension+1 or more. Needs an invertible projector, since the geometry is evaluated
+// in the simulation space; for N-D scenes the heatmap shows the projection plane.
+func (r *Renderer) drawHeatmap(screen *ebiten.Image) {
+	sensors := r.sim.GetSensors()
+	if r.heatmap.mode == heatmapOff || len(sensors) == 0 || r.screenWidth == 0 || r.screenHeight == 0 {
+		return
+	}
+	if _, world := r.screenToPoint(0, 0); !world {
+		return
+	}
+	cols := (r.screenWidth + heatmapCellPixels - 1) / heatmapCellPixels
+	rows := (r.screenHeight + heatmapCellPixels - 1) / heatmapCellPixels
+	if r.heatmap.image == nil || r.heatmap.image.Bounds().Dx() != cols || r.heatmap.image.Bounds().Dy() != rows {
+		r.heatmap.image = ebiten.NewImage(cols, rows)
+		r.heatmap.pixels = make([]byte, 4*cols*rows)
+	}
+
+	positions := make([]common.Vector, len(sensors))
+	for i, sen := range sensors {
+		positions[i] = sen.GetPosition()
+	}
+	inRange := make([]common.Vector, 0, len(sensors))
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			point, _ := r.screenToPoint((float64(col)+0.5)*heatmapCellPixels, (float64(row)+0.5)*heatmapCellPixels)
+			inRange = coveringSensors(inRange[:0], sensors, positions, point)
+			c := color.RGBA{90, 90, 90, 50} // Not enough coverage to localize
+			if r.heatmap.mode == heatmapCoverage {
+				c = coverageColor(len(inRange), point.Dimension())
+			} else if len(inRange) >= point.Dimension()+1 {
+				if dop, err := multilateration.ComputeDOP(inRange, point); err == nil {
+					c = gdopColor(dop.GDOP)
+				}
+			}
+			i := row*cols + col
+			// Premultiplied alpha
+			r.heatmap.pixels[4*i] = byte(uint16(c.R) * uint16(c.A) / 255)
+			r.heatmap.pixels[4*i+1] = byte(uint16(c.G) * uint16(c.A) / 255)
+			r.heatmap.pixels[4*i+2] = byte(uint16(c.B) * uint16(c.A) / 255)
+			r.heatmap.pixels[4*i+3] = c.A
+		}
+	}
+	r.heatmap.image.WritePixels(r.heatmap.pixels)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(heatmapCellPixels, heatmapCellPixels)
+	screen.DrawImage(r.heatmap.image, op)
+	if r.heatmap.mode == heatmapCoverage {
+		r.drawCoverageLegend(screen, r.sim.GetDimension())
+	}
+}
+
+// drawCoverageLegend lists the coverage colors at the bottom-right, above the log panel.
+func (r *Renderer) drawCoverageLegend(screen *ebiten.Image, dimension int) {
+	const rowHeight = 16
+	x := float32(r.screenWidth - 130)
+	y := float32(r.screenHeight-r.logPanel.height()) - float32(dimension+2)*rowHeight - 10
+	vector.DrawFilledRect(screen, x-4, y-4, 124, float32(dimension+2)*rowHeight+8, color.RGBA{0, 0, 0, 150}, false)
+	for count := 0; count <= dimension+1; count++ {
+		c := coverageColor(count, dimension)
+		c.A = 255
+		rowY := y + float32(count)*rowHeight
+		vector.DrawFilledRect(screen, x, rowY+2, 12, 12, c, false)
+		label := fmt.Sprintf("%d сенс.", count)
+		if count == dimension+1 {
+			label = fmt.Sprintf(">=%d сенс.", count)
+		}
+		ebitenutil.DebugPrintAt(screen, label, int(x)+18, int(rowY))
+	}
+}
+
+// coveringSensors appends the positions of the sensors that have point in range to dst.
+func coveringSensors(dst []common.Vector, sensors []*simulation.Sensor, positions []common.Vector, point common.Vector) []common.Vector {
+	for i, sen := range sensors {
+		d, err := positions[i].Distance(point)
+		if err != nil {
+			continue
+		}
+		if radius := sen.DetectionRadius(); radius <= 0 || d <= radius {
+			dst = append(dst, positions[i])
+		}
+	}
+	return dst
+}
+
+// coverageColor maps a sensor count onto a ramp from red (no coverage) through
+// yellow to green (enough sensors, dimension+1 or more, to localize).
+func coverageColor(count, dimension int) color.RGBA {
+	if count >= dimension+1 {
+		return color.RGBA{0, 180, 0, 90}
+	}
+	if count == 0 {
+		return color.RGBA{200, 0, 0, 110}
+	}
+	t := float64(count) / float64(dimension+1) // Fraction of the required sensors
+	return color.RGBA{255, uint8(80 + 140*t), 0, 90}
+}
+
+// gdopColor maps GDOP onto a green-yellow-red ramp.
+func gdopColor(gdop float64) color.RGBA {
+	t := (gdop - heatmapGoodGDOP) / (heatmapBadGDOP - heatmapGoodGDOP)
+	if math.IsNaN(t) || t > 1 {
+		t = 1
+	}
+	t = math.Max(t, 0)
+	if t < 0.5 {
+		return color.RGBA{uint8(510 * t), 180, 0, 90} // Green to yellow
+	}
+	return color.RGBA{255, uint8(180 * (2 - 2*t)), 0, 90} // Yellow to red
+}
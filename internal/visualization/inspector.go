@@ -0,0 +1,215 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	inspectorWidth   = 380 // Pixels
+	inspectorHistory = 8   // Estimates listed per target
+)
+
+var selectionColor = color.RGBA{255, 200, 0, 255} // Жёлтый
+
+// estimateRecord is one entry of the inspector's estimate history.
+type estimateRecord struct {
+	time     float64
+	position common.Vector
+	err      float64 // Localization error, -1 if unknown
+}
+
+// NoiseDescriber returns a human-readable description of a sensor's noise model.
+// Noise functions are opaque, so the description has to come from whoever built them.
+type NoiseDescriber func(sensorID string) (string, bool)
+
+// SetNoiseDescriber sets how the inspector describes sensor noise.
+func (r *Renderer) SetNoiseDescriber(describe NoiseDescriber) {
+	r.describeNoise = describe
+}
+
+// Select selects an object for the inspector, "" clears the selection.
+func (r *Renderer) Select(id string) {
+	r.selectedID = id
+}
+
+// recordEstimates keeps the last estimates of every target for the inspector.
+func (r *Renderer) recordEstimates(report simulation.StepReport) {
+	for _, t := range report.Targets {
+		if t.Outcome != simulation.OutcomeLocalized {
+			continue
+		}
+		st, ok := report.State.Target(t.TargetID)
+		if !ok || st.Estimate.Position == nil {
+			continue
+		}
+		history := append(r.estimateHistory[t.TargetID], estimateRecord{time: report.Time, position: st.Estimate.Position, err: st.Error})
+		if len(history) > inspectorHistory {
+			history = history[len(history)-inspectorHistory:]
+		}
+		r.estimateHistory[t.TargetID] = history
+	}
+}
+
+// inspectorLines describes the selected object, nil if nothing (valid) is selected.
+func (r *Renderer) inspectorLines() []string {
+	if r.selectedID == "" {
+		return nil
+	}
+	obj, ok := r.sim.GetObject(r.selectedID)
+	if !ok {
+		r.selectedID = "" // Removed in the meantime
+		return nil
+	}
+	f := r.activeFormat()
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	switch o := obj.(type) {
+	case *simulation.Sensor:
+		add("Сенсор %s", o.GetID())
+		if label := o.Label(); label != "" {
+			add("Метка: %s", label)
+		}
+		add("Позиция: %s", f.Vector(o.GetPosition()))
+		if o.MotionModel() != nil {
+			add("Скорость: %s", f.Vector(o.GetVelocity()))
+			add("Движение: %T", o.MotionModel())
+		}
+		add("Радиус: %s", f.Float(o.DetectionRadius()))
+		if r.describeNoise != nil {
+			if desc, ok := r.describeNoise(o.GetID()); ok {
+				add("Шум: %s", desc)
+			}
+		}
+		add("Интервал: %d, задержка: %.3fs, потери: %.0f%%", o.MeasurementInterval(), o.Latency(), o.DropoutProbability()*100)
+		if o.RangeVariance() > 0 {
+			add("Дисперсия дальности: %s", f.Float(o.RangeVariance()))
+		}
+		if o.FalseAlarmRate() > 0 {
+			add("Ложные тревоги: %.2f за измерение", o.FalseAlarmRate())
+		}
+		if offset := o.CalibrationOffset(); offset != nil {
+			add("Ошибка калибровки: %s", f.Vector(offset))
+		}
+		switch o.Status() {
+		case simulation.SensorFailed:
+			add("Состояние: отказ")
+		case simulation.SensorOutage:
+			add("Состояние: перерыв в работе")
+		}
+		if sigma := o.DegradationSigma(); sigma > 0 {
+			add("Старение: +%s σ дальности", f.Float(sigma))
+		}
+		if clock := o.Clock(); clock != (simulation.Clock{}) {
+			add("Часы: смещение %gs, уход %gs/s", clock.Offset, clock.Drift)
+		}
+		if o.MeasuresRangeRate() {
+			add("Измеряет радиальную скорость")
+		}
+		add("Доля потерь (факт.): %.1f%%", r.sim.DropoutRate(o.GetID())*100)
+		add("Последние измерения:")
+		for _, tar := range r.sim.GetTargets() {
+			for _, m := range r.sim.GetLatestMeasurements(tar.GetID()) {
+				if m.SensorID == o.GetID() {
+					add("  -> %s: %s (t=%.2fs)", tar.GetID(), f.Float(m.Distance), m.Timestamp)
+				}
+			}
+		}
+
+	case *simulation.Target:
+		add("Цель %s", o.GetID())
+		if label := o.Label(); label != "" {
+			add("Метка: %s", label)
+		}
+		if class := o.Class(); class != "" {
+			add("Класс: %s", class)
+		}
+		add("Позиция: %s", f.Vector(o.GetPosition()))
+		add("Скорость: %s", f.Vector(o.GetVelocity()))
+		motion := "статична"
+		if o.MotionModel() != nil {
+			motion = fmt.Sprintf("%T", o.MotionModel())
+		}
+		add("Движение: %s", motion)
+		if schedule, ok := o.EmissionSchedule(); ok {
+			add("Излучение: каждые %.3fs ± %.3fs", schedule.Interval, schedule.Jitter)
+		}
+		if clock := o.Clock(); clock != (simulation.Clock{}) {
+			add("Часы: смещение %gs, уход %gs/s", clock.Offset, clock.Drift)
+		}
+		if est, ok := r.sim.GetLastEstimate(o.GetID()); ok && est.Position != nil {
+			add("Оценка: %s", est.FormatWith(f))
+			if est.Velocity != nil {
+				add("Оценка скорости: %s", f.Vector(est.Velocity))
+			}
+			if est.ClockBias != 0 {
+				add("Смещение часов (оценка): %s", f.Float(est.ClockBias))
+			}
+		}
+		add("Последние измерения:")
+		for _, m := range r.sim.GetLatestMeasurements(o.GetID()) {
+			add("  <- %s: %s (t=%.2fs)", m.SensorID, f.Float(m.Distance), m.Timestamp)
+		}
+		add("История оценок:")
+		history := r.estimateHistory[o.GetID()]
+		for i := len(history) - 1; i >= 0; i-- {
+			h := history[i]
+			line := fmt.Sprintf("  [%7.2fs] %s", h.time, f.Vector(h.position))
+			if h.err >= 0 {
+				line += fmt.Sprintf(" (Err: %s)", f.Float(h.err))
+			}
+			lines = append(lines, line)
+		}
+
+	case *simulation.Obstacle:
+		add("Препятствие %s", o.GetID())
+		switch shape := o.Shape().(type) {
+		case *simulation.BoxRegion:
+			add("Параллелепипед: %s - %s", f.Vector(shape.Min()), f.Vector(shape.Max()))
+		case *simulation.BallRegion:
+			add("Шар: центр %s, радиус %s", f.Vector(shape.Center()), f.Float(shape.Radius()))
+		}
+		if o.NLOSBias() > 0 {
+			add("Удлиняет дальности на %s (NLOS)", f.Float(o.NLOSBias()))
+		} else {
+			add("Блокирует измерения")
+		}
+
+	default:
+		add("Объект %s", obj.GetID())
+		add("Позиция: %s", f.Vector(obj.GetPosition()))
+	}
+	return lines
+}
+
+// drawInspector marks the selected object and draws the inspector panel on the right.
+func (r *Renderer) drawInspector(screen *ebiten.Image) {
+	lines := r.inspectorLines()
+	if lines == nil {
+		return
+	}
+	if p, ok := r.projectedCoords[r.selectedID]; ok && len(p) >= 2 {
+		x, y := r.worldToScreen(p[0], p[1])
+		vector.StrokeCircle(screen, x, y, float32(objectRadiusOnScreen*2), 2, selectionColor, true)
+	}
+
+	if r.editor.input != nil {
+		lines = append(lines, "", "Координаты: "+*r.editor.input+"_", "Enter: применить, Esc: отмена")
+	} else {
+		lines = append(lines, "", "Enter: ввести координаты, Esc: снять выделение")
+	}
+	x := r.screenWidth - inspectorWidth
+	height := (len(lines) + 1) * logLineHeight
+	vector.DrawFilledRect(screen, float32(x), 0, inspectorWidth, float32(height), color.RGBA{0, 0, 0, 150}, false)
+	ebitenutil.DebugPrintAt(screen, strings.Join(lines, "\n"), x+6, 4)
+}
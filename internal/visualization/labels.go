@@ -0,0 +1,28 @@
+package visualization
+
+import (
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// drawLabel writes text to the upper right of an object at screen position x, y.
+func drawLabel(screen *ebiten.Image, text string, x, y float32) {
+	if text != "" {
+		ebitenutil.DebugPrintAt(screen, text, int(x)+7, int(y)-7)
+	}
+}
+
+// targetLabel returns the text shown next to a target: its label and class, e.g.
+// "courier (drone)", either one alone, or nothing.
+func targetLabel(tar *simulation.Target) string {
+	switch label, class := tar.Label(), tar.Class(); {
+	case label == "":
+		return class
+	case class == "":
+		return label
+	default:
+		return label + " (" + class + ")"
+	}
+}
@@ -0,0 +1,232 @@
+package visualization
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"multilateration-sim/internal/simulation"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Severity classifies log panel entries.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns a short name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+var severityColors = map[Severity]color.RGBA{
+	SeverityInfo:    {60, 160, 60, 255}, // Зелёный
+	SeverityWarning: {230, 160, 0, 255}, // Оранжевый
+	SeverityError:   {210, 30, 30, 255}, // Красный
+}
+
+const (
+	logLineHeight = 16 // Height of one line of the debug font
+	logPanelLines = 10 // Visible lines
+)
+
+// LogEntry is one event shown in the log panel.
+type LogEntry struct {
+	Time     float64 // Simulation time
+	Severity Severity
+	Message  string
+}
+
+// LogPanel is a scrollable in-window list of recent simulation events with severity
+// coloring and filtering. Keys: L toggles the panel, F cycles the minimum severity,
+// PageUp/PageDown or the mouse wheel over the panel scroll. Safe for concurrent use, since step
+// reports may arrive from the stepping goroutine.
+type LogPanel struct {
+	mu          sync.Mutex
+	entries     []LogEntry // Oldest first, at most capacity
+	capacity    int
+	scroll      int // Lines scrolled up from the newest entry
+	minSeverity Severity
+	hidden      bool
+	area        image.Rectangle // Where the panel was last drawn, receives the mouse wheel
+
+	lastOutcome map[string]simulation.LocalizationOutcome // targetID -> outcome of the previous step
+}
+
+// NewLogPanel creates a panel that keeps the given number of most recent entries.
+func NewLogPanel(capacity int) *LogPanel {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LogPanel{capacity: capacity, lastOutcome: make(map[string]simulation.LocalizationOutcome)}
+}
+
+// Add appends an entry, dropping the oldest one when the panel is full.
+func (p *LogPanel) Add(entry LogEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = append(p.entries, entry)
+	if len(p.entries) > p.capacity {
+		p.entries = p.entries[len(p.entries)-p.capacity:]
+	}
+	if p.scroll > 0 && entry.Severity >= p.minSeverity {
+		p.scroll++ // Keep the view still while the user reads older entries
+	}
+}
+
+// HandleStep turns a step report into log entries: solver and sensor errors, targets
+// that lost their track (no estimate after having one), targets that regained it and
+// targets leaving or entering the region of interest.
+// Register it with Simulation.OnStep.
+func (p *LogPanel) HandleStep(report simulation.StepReport) {
+	for _, t := range report.Targets {
+		if t.Outcome == simulation.OutcomeDeferred {
+			continue // The previous estimate stands
+		}
+		p.mu.Lock()
+		prev, seen := p.lastOutcome[t.TargetID]
+		p.lastOutcome[t.TargetID] = t.Outcome
+		p.mu.Unlock()
+
+		switch {
+		case t.Err != nil:
+			p.Add(LogEntry{Time: report.Time, Severity: SeverityError, Message: fmt.Sprintf("%s: %v", t.TargetID, t.Err)})
+		case t.Outcome == simulation.OutcomeOutsideRegion && seen && prev != simulation.OutcomeOutsideRegion:
+			p.Add(LogEntry{Time: report.Time, Severity: SeverityInfo, Message: fmt.Sprintf("%s: покинула зону интереса", t.TargetID)})
+		case t.Outcome != simulation.OutcomeOutsideRegion && seen && prev == simulation.OutcomeOutsideRegion:
+			p.Add(LogEntry{Time: report.Time, Severity: SeverityInfo, Message: fmt.Sprintf("%s: вошла в зону интереса, новый трек", t.TargetID)})
+		case t.Outcome == simulation.OutcomeInsufficientMeasurements && seen && prev == simulation.OutcomeLocalized:
+			p.Add(LogEntry{Time: report.Time, Severity: SeverityWarning, Message: fmt.Sprintf("%s: трек потерян (%d измерений)", t.TargetID, t.NumMeasurements)})
+		case t.Outcome == simulation.OutcomeLocalized && seen && prev != simulation.OutcomeLocalized:
+			p.Add(LogEntry{Time: report.Time, Severity: SeverityInfo, Message: fmt.Sprintf("%s: трек восстановлен", t.TargetID)})
+		}
+	}
+	for _, se := range report.SensorErrors {
+		p.Add(LogEntry{Time: report.Time, Severity: SeverityError, Message: fmt.Sprintf("%s -> %s: %v", se.SensorID, se.TargetID, se.Err)})
+	}
+	if report.SLAMErr != nil {
+		p.Add(LogEntry{Time: report.Time, Severity: SeverityError, Message: report.SLAMErr.Error()})
+	}
+}
+
+// HandleSensorEvent logs sensor failures, outages and recoveries. Subscribe it to
+// EventSensorFailed, EventSensorOutage and EventSensorRestored.
+func (p *LogPanel) HandleSensorEvent(event simulation.Event) {
+	switch event.Kind {
+	case simulation.EventSensorFailed:
+		p.Add(LogEntry{Time: event.Time, Severity: SeverityError, Message: fmt.Sprintf("%s: отказ датчика", event.SensorID)})
+	case simulation.EventSensorOutage:
+		p.Add(LogEntry{Time: event.Time, Severity: SeverityWarning, Message: fmt.Sprintf("%s: перерыв в работе", event.SensorID)})
+	case simulation.EventSensorRestored:
+		p.Add(LogEntry{Time: event.Time, Severity: SeverityInfo, Message: fmt.Sprintf("%s: снова в работе", event.SensorID)})
+	}
+}
+
+// filtered returns the entries at or above the minimum severity. Caller holds the lock.
+func (p *LogPanel) filtered() []LogEntry {
+	out := make([]LogEntry, 0, len(p.entries))
+	for _, e := range p.entries {
+		if e.Severity >= p.minSeverity {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Update handles the panel's keyboard and mouse input.
+func (p *LogPanel) Update() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		p.hidden = !p.hidden
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		p.minSeverity = (p.minSeverity + 1) % (SeverityError + 1)
+		p.scroll = 0
+	}
+	if p.hidden {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageUp) {
+		p.scroll += logPanelLines
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageDown) {
+		p.scroll -= logPanelLines
+	}
+	if _, wheelY := ebiten.Wheel(); wheelY != 0 && p.underCursor() {
+		p.scroll += int(wheelY)
+	}
+	maxScroll := len(p.filtered()) - logPanelLines
+	if p.scroll > maxScroll {
+		p.scroll = maxScroll
+	}
+	if p.scroll < 0 {
+		p.scroll = 0
+	}
+}
+
+// Draw renders the panel into the rectangle at (x, y) with the given width.
+func (p *LogPanel) Draw(screen *ebiten.Image, x, y, width int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.hidden {
+		return
+	}
+	height := (logPanelLines + 1) * logLineHeight
+	p.area = image.Rect(x, y, x+width, y+height)
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width), float32(height), color.RGBA{0, 0, 0, 150}, false)
+
+	entries := p.filtered()
+	end := len(entries) - p.scroll
+	start := end - logPanelLines
+	if start < 0 {
+		start = 0
+	}
+	header := fmt.Sprintf("Журнал (L: скрыть, F: фильтр >= %s, PgUp/PgDn: прокрутка) %d/%d", p.minSeverity, end, len(entries))
+	ebitenutil.DebugPrintAt(screen, header, x+4, y)
+	for i, e := range entries[start:end] {
+		lineY := y + (i+1)*logLineHeight
+		vector.DrawFilledRect(screen, float32(x+4), float32(lineY+4), 8, 8, severityColors[e.Severity], false)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("[%7.2fs] %s", e.Time, e.Message), x+16, lineY)
+	}
+}
+
+// height returns the on-screen height of the panel, 0 when hidden.
+// underCursor reports whether the mouse is over the visible panel. The caller holds p.mu.
+func (p *LogPanel) underCursor() bool {
+	mx, my := ebiten.CursorPosition()
+	return !p.hidden && image.Pt(mx, my).In(p.area)
+}
+
+// containsCursor is underCursor for callers outside the panel.
+func (p *LogPanel) containsCursor() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.underCursor()
+}
+
+func (p *LogPanel) height() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.hidden {
+		return 0
+	}
+	return (logPanelLines + 1) * logLineHeight
+}
@@ -0,0 +1,209 @@
+package visualization
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// MDSMetric selects the distance between objects that classical MDS preserves.
+type MDSMetric int
+
+const (
+	// MDSEuclidean preserves straight-line distances. The layout then matches PCA up to
+	// rotation, but is fitted to the objects rather than their variance.
+	MDSEuclidean MDSMetric = iota
+	// MDSManhattan preserves sums of per-axis differences, which keeps objects that differ
+	// in many dimensions apart where PCA would overlay them.
+	MDSManhattan
+	// MDSChebyshev preserves the largest per-axis difference.
+	MDSChebyshev
+)
+
+// ParseMDSMetric parses "euclidean", "manhattan" or "chebyshev".
+func ParseMDSMetric(name string) (MDSMetric, error) {
+	switch name {
+	case "euclidean":
+		return MDSEuclidean, nil
+	case "manhattan":
+		return MDSManhattan, nil
+	case "chebyshev":
+		return MDSChebyshev, nil
+	}
+	return 0, fmt.Errorf("unknown MDS metric %q", name)
+}
+
+// String returns the metric name as accepted by ParseMDSMetric.
+func (m MDSMetric) String() string {
+	switch m {
+	case MDSEuclidean:
+		return "euclidean"
+	case MDSManhattan:
+		return "manhattan"
+	case MDSChebyshev:
+		return "chebyshev"
+	}
+	return fmt.Sprintf("MDSMetric(%d)", int(m))
+}
+
+func (m MDSMetric) distance(a, b common.Vector) float64 {
+	d := 0.0
+	for i := range a {
+		diff := math.Abs(a[i] - b[i])
+		switch m {
+		case MDSManhattan:
+			d += diff
+		case MDSChebyshev:
+			d = math.Max(d, diff)
+		default:
+			d += diff * diff
+		}
+	}
+	if m == MDSEuclidean {
+		return math.Sqrt(d)
+	}
+	return d
+}
+
+// MDSProjector lays out the scene with classical multidimensional scaling: the objects
+// are placed in 2D so that their pairwise distances match those in the N-D space as
+// well as possible. Other points (estimates, trails) are mapped with the Nyström
+// extension from their distances to the fitted objects. The mapping is not linear,
+// so uncertainty ellipses are not drawn and positions cannot be picked with the mouse.
+type MDSProjector struct {
+	metric MDSMetric
+
+	landmarks []common.Vector // Object positions of the last fit
+	meanSq    []float64       // Column means of the squared distance matrix
+	axes      [2][]float64    // Eigenvectors of the double-centered matrix, scaled by 1/(2 sqrt(lambda))
+	previous  map[string]common.Vector
+}
+
+// NewMDSProjector creates an MDS projector preserving distances of the given metric.
+func NewMDSProjector(metric MDSMetric) *MDSProjector {
+	return &MDSProjector{metric: metric}
+}
+
+// Metric returns the preserved distance.
+func (p *MDSProjector) Metric() MDSMetric {
+	return p.metric
+}
+
+// Fit implements Projector. With fewer than two objects the previous layout is kept.
+func (p *MDSProjector) Fit(objects []simulation.SimulationObject) error {
+	n := len(objects)
+	if n < 2 {
+		return nil
+	}
+	points := make([]common.Vector, n)
+	for i, obj := range objects {
+		points[i] = obj.GetPosition()
+		if points[i].Dimension() != points[0].Dimension() {
+			return fmt.Errorf("object %s has dimension %d, expected %d", obj.GetID(), points[i].Dimension(), points[0].Dimension())
+		}
+	}
+
+	// B = -1/2 J D² J, the Gram matrix of the centered configuration
+	sq := make([]float64, n*n)
+	meanSq := make([]float64, n)
+	grand := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			d := p.metric.distance(points[i], points[j])
+			sq[i*n+j] = d * d
+			meanSq[j] += d * d / float64(n)
+		}
+	}
+	for _, m := range meanSq {
+		grand += m / float64(n)
+	}
+	gram := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			gram.SetSym(i, j, -0.5*(sq[i*n+j]-meanSq[i]-meanSq[j]+grand))
+		}
+	}
+	var eig mat.EigenSym
+	if !eig.Factorize(gram, true) {
+		return fmt.Errorf("MDS eigendecomposition failed")
+	}
+	values := eig.Values(nil)
+	var vectors mat.Dense
+	eig.VectorsTo(&vectors)
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+	var axes [2][]float64
+	for k := 0; k < 2; k++ {
+		axes[k] = make([]float64, n) // Stays zero if there is no positive eigenvalue left
+		if lambda := values[order[k]]; lambda > 1e-9*math.Max(values[order[0]], 1) {
+			for j := 0; j < n; j++ {
+				axes[k][j] = vectors.At(j, order[k]) / (2 * math.Sqrt(lambda))
+			}
+		}
+	}
+	p.landmarks, p.meanSq, p.axes = points, meanSq, axes
+	p.alignWithPrevious(objects)
+	return nil
+}
+
+// alignWithPrevious flips the axes whose sign changed since the last fit, judged by the
+// objects present in both, so the layout does not mirror from frame to frame.
+func (p *MDSProjector) alignWithPrevious(objects []simulation.SimulationObject) {
+	current := make(map[string]common.Vector, len(objects))
+	var agreement [2]float64
+	for _, obj := range objects {
+		pos, err := p.Transform(obj.GetPosition())
+		if err != nil {
+			return
+		}
+		current[obj.GetID()] = pos
+		if prev, ok := p.previous[obj.GetID()]; ok {
+			agreement[0] += prev[0] * pos[0]
+			agreement[1] += prev[1] * pos[1]
+		}
+	}
+	for k := 0; k < 2; k++ {
+		if agreement[k] >= 0 {
+			continue
+		}
+		for j := range p.axes[k] {
+			p.axes[k][j] = -p.axes[k][j]
+		}
+		for _, pos := range current {
+			pos[k] = -pos[k]
+		}
+	}
+	p.previous = current
+}
+
+// Transform implements Projector: y_k = sum_j v_jk (meanSq_j - d²(x, l_j)) / (2 sqrt(lambda_k)),
+// which reproduces the MDS coordinates of the fitted objects exactly.
+func (p *MDSProjector) Transform(pos common.Vector) (common.Vector, error) {
+	if p.landmarks == nil {
+		return nil, fmt.Errorf("projector has not been fitted")
+	}
+	if pos.Dimension() != p.landmarks[0].Dimension() {
+		return nil, fmt.Errorf("vectors must have the same dimension: %d != %d", pos.Dimension(), p.landmarks[0].Dimension())
+	}
+	out := common.NewVector(2)
+	for j, l := range p.landmarks {
+		d := p.metric.distance(pos, l)
+		delta := p.meanSq[j] - d*d
+		out[0] += p.axes[0][j] * delta
+		out[1] += p.axes[1][j] * delta
+	}
+	return out, nil
+}
+
+// String returns the name shown in the overlay.
+func (p *MDSProjector) String() string {
+	return "MDS (" + p.metric.String() + ")"
+}
@@ -0,0 +1,74 @@
+package visualization_test
+
+import (
+	"fmt"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/visualization"
+	"testing"
+)
+
+func TestMDSProjectorRecoversPlanarConfiguration(t *testing.T) {
+	// A planar scene padded to 4D: Euclidean MDS must lay it out up to rigid motion
+	positions := tiltedPlane(planarConfiguration...)
+	for i, pos := range positions {
+		positions[i] = append(pos, -2)
+	}
+	p := visualization.NewMDSProjector(visualization.MDSEuclidean)
+	projected, err := visualization.Project(p, targetsAt(positions...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]common.Vector, len(positions))
+	for i := range positions {
+		got[i] = projected[fmt.Sprint("t", i)]
+	}
+	checkDistances(t, got, planarConfiguration)
+
+	// Other points of the plane are placed by their distances to the fitted objects
+	extra := common.Vector{3, -5}
+	pos := tiltedPlane(extra)[0]
+	mapped, err := p.Transform(append(pos, -2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDistances(t, append(got, mapped), append(planarConfiguration, extra))
+}
+
+func TestMDSProjectorKeepsOrientation(t *testing.T) {
+	// A small move of one object must not mirror the layout of the others
+	positions := tiltedPlane(planarConfiguration...)
+	p := visualization.NewMDSProjector(visualization.MDSEuclidean)
+	before, err := visualization.Project(p, targetsAt(positions...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	positions[4] = tiltedPlane(common.Vector{-4, 3.5})[0]
+	after, err := visualization.Project(p, targetsAt(positions...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"t0", "t1", "t2", "t3"} {
+		if d, _ := before[id].Distance(after[id]); d > 1 {
+			t.Errorf("%s moved from %v to %v", id, before[id], after[id])
+		}
+	}
+}
+
+func TestMDSProjectorErrors(t *testing.T) {
+	p := visualization.NewMDSProjector(visualization.MDSManhattan)
+	if _, err := p.Transform(common.Vector{1, 2, 3}); err == nil {
+		t.Error("Transform before Fit succeeded")
+	}
+	if err := p.Fit(targetsAt(common.Vector{1, 2, 3}, common.Vector{1, 2})); err == nil {
+		t.Error("Fit of objects of different dimensions succeeded")
+	}
+	if err := p.Fit(targetsAt(tiltedPlane(planarConfiguration...)...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Transform(common.Vector{1, 2}); err == nil {
+		t.Error("Transform of a point of another dimension succeeded")
+	}
+	if _, err := visualization.ParseMDSMetric("cosine"); err == nil {
+		t.Error("unknown metric accepted")
+	}
+}
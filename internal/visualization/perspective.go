@@ -0,0 +1,221 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Camera distance limits of PerspectiveProjector, in radii of the scene around the pivot.
+// Below 1 the camera would be inside the scene and objects behind it could not be drawn.
+const (
+	minCameraDistance     = 1.2
+	maxCameraDistance     = 20.0
+	defaultCameraDistance = 3.0
+	cameraDollySpeed      = 1.5 // Factor per second while Shift+Up/Down is held
+)
+
+var boundsBoxColor = color.RGBA{0, 0, 0, 90}
+
+// PerspectiveProjector is a true 3D view of the first three axes: an orbit camera looks
+// at the centre of the sensors from the direction given by yaw and pitch (as for
+// OrthographicProjector) and projects with perspective, so nearer objects spread apart.
+// The camera distance is a multiple of the scene radius, which keeps every object in
+// front of it. Arrow keys orbit, Shift+Up/Down moves the camera closer or further.
+type PerspectiveProjector struct {
+	yaw, pitch float64 // Radians
+	distance   float64 // Camera distance in scene radii
+	sourceDim  int
+	pivot      [3]float64
+	radius     float64
+}
+
+// NewPerspectiveProjector creates a projector with the given initial view angles and
+// the default camera distance.
+func NewPerspectiveProjector(yaw, pitch float64) *PerspectiveProjector {
+	p := &PerspectiveProjector{distance: defaultCameraDistance}
+	p.SetView(yaw, pitch)
+	return p
+}
+
+// SetView sets the view angles; pitch is clamped to [-pi/2, pi/2].
+func (p *PerspectiveProjector) SetView(yaw, pitch float64) {
+	p.yaw = math.Mod(yaw, 2*math.Pi)
+	p.pitch = math.Max(-math.Pi/2, math.Min(math.Pi/2, pitch))
+}
+
+// View returns the current view angles.
+func (p *PerspectiveProjector) View() (yaw, pitch float64) {
+	return p.yaw, p.pitch
+}
+
+// SetDistance sets the camera distance in scene radii, clamped to [1.2, 20].
+func (p *PerspectiveProjector) SetDistance(distance float64) {
+	p.distance = math.Max(minCameraDistance, math.Min(maxCameraDistance, distance))
+}
+
+// Distance returns the camera distance in scene radii.
+func (p *PerspectiveProjector) Distance() float64 {
+	return p.distance
+}
+
+// Fit implements Projector. The pivot is the centre of the sensors' bounding box (of
+// all objects if there are no sensors), so the camera stays put while targets move;
+// the radius reaches the furthest object.
+func (p *PerspectiveProjector) Fit(objects []simulation.SimulationObject) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	p.sourceDim = objects[0].GetPosition().Dimension()
+	var lo, hi [3]float64
+	found := false
+	for _, sensorsOnly := range []bool{true, false} {
+		for _, obj := range objects {
+			if _, isSensor := obj.(*simulation.Sensor); sensorsOnly && !isSensor {
+				continue
+			}
+			pos := firstThree(obj.GetPosition())
+			for i := range pos {
+				if !found || pos[i] < lo[i] {
+					lo[i] = pos[i]
+				}
+				if !found || pos[i] > hi[i] {
+					hi[i] = pos[i]
+				}
+			}
+			found = true
+		}
+		if found {
+			break
+		}
+	}
+	p.radius = 0
+	for i := range p.pivot {
+		p.pivot[i] = (lo[i] + hi[i]) / 2
+	}
+	for _, obj := range objects {
+		pos := firstThree(obj.GetPosition())
+		d := math.Sqrt(sq(pos[0]-p.pivot[0]) + sq(pos[1]-p.pivot[1]) + sq(pos[2]-p.pivot[2]))
+		p.radius = math.Max(p.radius, d)
+	}
+	if p.radius == 0 {
+		p.radius = 1 // A single point, any scale will do
+	}
+	return nil
+}
+
+// Transform implements Projector. Points at or behind the camera cannot be projected.
+func (p *PerspectiveProjector) Transform(pos common.Vector) (common.Vector, error) {
+	if p.sourceDim == 0 {
+		return nil, fmt.Errorf("projector has not been fitted")
+	}
+	if pos.Dimension() != p.sourceDim {
+		return nil, fmt.Errorf("point has dimension %d, projector was fitted to %d", pos.Dimension(), p.sourceDim)
+	}
+	x := firstThree(pos)
+	for i := range x {
+		x[i] -= p.pivot[i]
+	}
+	// Screen axes as in OrthographicProjector; w points from the pivot to the camera
+	sy, cy, sp, cp := math.Sin(p.yaw), math.Cos(p.yaw), math.Sin(p.pitch), math.Cos(p.pitch)
+	u := cy*x[0] + sy*x[1]
+	v := -sy*sp*x[0] + cy*sp*x[1] + cp*x[2]
+	w := sy*cp*x[0] - cy*cp*x[1] + sp*x[2]
+	camera := p.distance * p.radius
+	depth := camera - w
+	if depth <= 1e-9*camera {
+		return nil, fmt.Errorf("point is behind the camera")
+	}
+	scale := camera / depth // 1 at the pivot, so the view keeps the scene's units there
+	return common.Vector{u * scale, v * scale}, nil
+}
+
+// HandleInput implements interactiveProjector: left/right change yaw, up/down pitch and
+// Shift+Up/Down the camera distance.
+func (p *PerspectiveProjector) HandleInput() {
+	step := orthoRotationSpeed / float64(ebiten.TPS())
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		dolly := math.Pow(cameraDollySpeed, 1/float64(ebiten.TPS()))
+		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+			p.SetDistance(p.distance / dolly)
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+			p.SetDistance(p.distance * dolly)
+		}
+		return
+	}
+	yaw, pitch := p.yaw, p.pitch
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		yaw -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		yaw += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+		pitch += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		pitch -= step
+	}
+	p.SetView(yaw, pitch)
+}
+
+// String returns the name shown in the overlay.
+func (p *PerspectiveProjector) String() string {
+	return fmt.Sprintf("перспектива (рыск %.0f°, тангаж %.0f°, дистанция %.1f)", p.yaw*180/math.Pi, p.pitch*180/math.Pi, p.distance)
+}
+
+// firstThree returns the first three coordinates of pos, padded with zeros.
+func firstThree(pos common.Vector) [3]float64 {
+	var x [3]float64
+	copy(x[:], pos)
+	return x
+}
+
+func sq(x float64) float64 { return x * x }
+
+// drawBoundsBox draws the edges of the simulation bounds over the first three axes
+// when the perspective view is active, so depth and orientation can be read off the
+// scene. Further axes are held at the centre of the bounds.
+func (r *Renderer) drawBoundsBox(screen *ebiten.Image) {
+	p, ok := r.projector.(*PerspectiveProjector)
+	if !ok || r.sim.GetDimension() < 3 {
+		return
+	}
+	bounds := r.sim.GetBounds()
+	corner := func(bits int) common.Vector {
+		pos := common.NewVector(r.sim.GetDimension())
+		for i := range pos {
+			lo, hi := bounds[2*i], bounds[2*i+1]
+			switch {
+			case i >= 3:
+				pos[i] = (lo + hi) / 2
+			case bits&(1<<i) != 0:
+				pos[i] = hi
+			default:
+				pos[i] = lo
+			}
+		}
+		return pos
+	}
+	var projected [8]common.Vector
+	for bits := range projected {
+		projected[bits], _ = p.Transform(corner(bits)) // nil if behind the camera
+	}
+	for bits := range projected {
+		for axis := 0; axis < 3; axis++ {
+			other := bits | 1<<axis
+			if other == bits || projected[bits] == nil || projected[other] == nil {
+				continue
+			}
+			x0, y0 := r.worldToScreen(projected[bits][0], projected[bits][1])
+			x1, y1 := r.worldToScreen(projected[other][0], projected[other][1])
+			vector.StrokeLine(screen, x0, y0, x1, y1, 1, boundsBoxColor, true)
+		}
+	}
+}
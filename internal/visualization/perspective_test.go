@@ -0,0 +1,79 @@
+package visualization_test
+
+import (
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"multilateration-sim/internal/visualization"
+	"testing"
+)
+
+func TestPerspectiveProjectorTransform(t *testing.T) {
+	// The sensors put the pivot at the origin; the target at z = 20 is the furthest
+	// object, so with the default distance of 3 radii the camera is 60 away.
+	objects := []simulation.SimulationObject{
+		simulation.NewSensorWithID("s0", common.Vector{-10, -10, 0}, 0, nil),
+		simulation.NewSensorWithID("s1", common.Vector{10, 10, 0}, 0, nil),
+		simulation.NewTargetWithID("t0", common.Vector{0, 0, 20}, nil),
+	}
+	tests := []struct {
+		name       string
+		yaw, pitch float64
+		pos        common.Vector
+		want       common.Vector
+	}{
+		{"pivot", 0, 0, common.Vector{0, 0, 0}, common.Vector{0, 0}},
+		{"plane through the pivot keeps its scale", 0, 0, common.Vector{4, 0, 3}, common.Vector{4, 3}},
+		{"halfway to the camera doubles", 0, 0, common.Vector{5, -30, 5}, common.Vector{10, 10}},
+		{"beyond the pivot shrinks", 0, 0, common.Vector{6, 60, 0}, common.Vector{3, 0}},
+		{"yaw turns the screen axes", math.Pi / 2, 0, common.Vector{0, 7, 2}, common.Vector{7, 2}},
+		{"from above", 0, math.Pi / 2, common.Vector{3, 4, 0}, common.Vector{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := visualization.NewPerspectiveProjector(tt.yaw, tt.pitch)
+			if err := p.Fit(objects); err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Transform(tt.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want, 1e-9) {
+				t.Errorf("Transform(%v) = %v, want %v", tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerspectiveProjectorFit(t *testing.T) {
+	p := visualization.NewPerspectiveProjector(0, 0)
+	if _, err := p.Transform(common.Vector{0, 0, 0}); err == nil {
+		t.Error("Transform before Fit succeeded")
+	}
+
+	// Without sensors the pivot is the centre of all objects: (10, 0, 0), radius 10
+	if err := p.Fit(targetsAt(common.Vector{0, 0, 0}, common.Vector{20, 0, 0})); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := p.Transform(common.Vector{10, 0, 0}); !got.Equal(common.Vector{0, 0}, 1e-9) {
+		t.Errorf("pivot projects to %v", got)
+	}
+	if _, err := p.Transform(common.Vector{10, -30, 0}); err == nil {
+		t.Error("point at the camera was projected")
+	}
+	if _, err := p.Transform(common.Vector{10, -40, 0}); err == nil {
+		t.Error("point behind the camera was projected")
+	}
+	if _, err := p.Transform(common.Vector{10, 0}); err == nil {
+		t.Error("Transform of a point of another dimension succeeded")
+	}
+
+	// 2D scenes lie in the plane z = 0
+	if err := p.Fit(targetsAt(common.Vector{-5, 0}, common.Vector{5, 0})); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := p.Transform(common.Vector{5, 0}); !got.Equal(common.Vector{5, 0}, 1e-9) {
+		t.Errorf("2D point projects to %v", got)
+	}
+}
@@ -0,0 +1,151 @@
+package visualization
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	defaultPlacedSensorRadius = 100.0 // Detection radius of sensors placed with the mouse
+	pickRadiusPixels          = 20.0  // Maximum cursor distance for Shift+click removal
+)
+
+// SensorFactory creates the sensor placed at a clicked world position.
+type SensorFactory func(pos common.Vector) *simulation.Sensor
+
+// SetSensorFactory overrides how mouse-placed sensors are created, e.g. to give them
+// noise. By default they are noiseless with a detection radius of 100.
+func (r *Renderer) SetSensorFactory(factory SensorFactory) {
+	r.sensorFactory = factory
+}
+
+// handlePlacement processes mouse editing: left click selects (and starts dragging) the
+// object under the cursor or adds a sensor, right click adds a target (or exports the track of the target
+// under the cursor, see SetTrackExporter), Shift+click removes the object nearest to the cursor.
+func (r *Renderer) handlePlacement() {
+	left := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	right := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+	if !left && !right {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		if id, ok := r.nearestObject(float32(mx), float32(my)); ok {
+			r.removeObject(id)
+		}
+		return
+	}
+	if left {
+		if id, ok := r.nearestObject(float32(mx), float32(my)); ok {
+			r.selectedID = id
+			r.startDrag(id)
+			return
+		}
+	}
+	if right && r.exportTrack != nil {
+		if id, ok := r.nearestObject(float32(mx), float32(my)); ok {
+			if obj, _ := r.sim.GetObject(id); isTarget(obj) {
+				r.runTrackExport(id)
+				return
+			}
+		}
+	}
+
+	pos, ok := r.screenToSimulation(float64(mx), float64(my))
+	if !ok {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityWarning, Message: "размещение недоступно для этой проекции"})
+		return
+	}
+	pos = r.snapToGrid(pos)
+	var obj simulation.SimulationObject
+	kind := "цель"
+	if left {
+		kind = "сенсор"
+		if r.sensorFactory != nil {
+			obj = r.sensorFactory(pos)
+		} else {
+			obj = simulation.NewSensor(pos, defaultPlacedSensorRadius, nil)
+		}
+	} else {
+		obj = simulation.NewTarget(pos)
+	}
+	if err := r.sim.AddObject(obj); err != nil {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("добавление: %v", err)})
+		return
+	}
+	r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: fmt.Sprintf("добавлен %s %s в %s", kind, obj.GetID(), r.activeFormat().Vector(pos))})
+}
+
+// screenToSimulation maps a screen position to a point of the simulation space.
+// Only possible with an invertible projector.
+func (r *Renderer) screenToSimulation(sx, sy float64) (common.Vector, bool) {
+	point, world := r.screenToPoint(sx, sy)
+	if !world || point.Dimension() != r.sim.GetDimension() {
+		return nil, false
+	}
+	return point, true
+}
+
+// nearestObject returns the object drawn closest to the cursor, if close enough.
+func (r *Renderer) nearestObject(mx, my float32) (string, bool) {
+	nearestID := ""
+	nearestDist := math.Inf(1)
+	for id, p := range r.projectedCoords {
+		if len(p) < 2 {
+			continue
+		}
+		x, y := r.worldToScreen(p[0], p[1])
+		if d := math.Hypot(float64(x-mx), float64(y-my)); d < nearestDist {
+			nearestID, nearestDist = id, d
+		}
+	}
+	return nearestID, nearestDist <= pickRadiusPixels
+}
+
+// removeObject removes an object from the simulation and the current projection.
+func (r *Renderer) removeObject(id string) {
+	if err := r.sim.RemoveObject(id); err != nil {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("удаление: %v", err)})
+		return
+	}
+	delete(r.projectedCoords, id)
+	delete(r.estimateHistory, id)
+	r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: fmt.Sprintf("удалён объект %s", id)})
+}
+
+func isTarget(obj simulation.SimulationObject) bool {
+	_, ok := obj.(*simulation.Target)
+	return ok
+}
+
+// TrackExporter writes the recorded trajectory of a target, or of all targets if
+// targetID is empty, and returns the paths of the written files.
+type TrackExporter func(targetID string) ([]string, error)
+
+// SetTrackExporter enables track export: right click on a target exports its
+// trajectory, E exports all trajectories. The outcome is shown in the log panel.
+func (r *Renderer) SetTrackExporter(exporter TrackExporter) {
+	r.exportTrack = exporter
+}
+
+// handleExportKey exports all tracks on E.
+func (r *Renderer) handleExportKey() {
+	if r.exportTrack != nil && inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		r.runTrackExport("")
+	}
+}
+
+func (r *Renderer) runTrackExport(targetID string) {
+	paths, err := r.exportTrack(targetID)
+	if err != nil {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("экспорт трека: %v", err)})
+		return
+	}
+	r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: fmt.Sprintf("трек сохранён: %s", strings.Join(paths, ", "))})
+}
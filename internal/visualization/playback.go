@@ -0,0 +1,56 @@
+package visualization
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// handlePlaybackKeys processes the playback keys: space pauses/resumes, right arrow or
+// period single-steps, +/- change the speed. The right arrow is left to the projector
+// when it uses the arrow keys. Reports whether a single step was requested.
+func (r *Renderer) handlePlaybackKeys(arrowStep bool) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		r.runner.SetPaused(!r.runner.Paused())
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		r.runner.SetSpeed(r.runner.Speed() * 2)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		r.runner.SetSpeed(r.runner.Speed() / 2)
+	}
+	return inpututil.IsKeyJustPressed(ebiten.KeyPeriod) || arrowStep && inpututil.IsKeyJustPressed(ebiten.KeyArrowRight)
+}
+
+// advance steps the simulation for one UI tick, handling the playback keys if keys is
+// set; arrowStep enables single-stepping with the right arrow. The runner takes fixed
+// steps of Simulation.NextStepDuration, so adaptive stepping is honoured.
+func (r *Renderer) advance(keys, arrowStep bool) {
+	if keys && r.handlePlaybackKeys(arrowStep) {
+		r.runner.StepOnce() // Single-stepping implies pause
+		return
+	}
+	r.runner.Advance(time.Second / time.Duration(ebiten.TPS()))
+}
+
+// SetPaused pauses or resumes the simulation.
+func (r *Renderer) SetPaused(paused bool) {
+	r.runner.SetPaused(paused)
+}
+
+// Paused reports whether the simulation is paused.
+func (r *Renderer) Paused() bool {
+	return r.runner.Paused()
+}
+
+// SetSpeed sets the simulation speed multiplier relative to real time (clamped to
+// [simulation.MinRunnerSpeed, simulation.MaxRunnerSpeed]).
+func (r *Renderer) SetSpeed(speed float64) {
+	r.runner.SetSpeed(speed)
+}
+
+// Speed returns the simulation speed multiplier relative to real time.
+func (r *Renderer) Speed() float64 {
+	return r.runner.Speed()
+}
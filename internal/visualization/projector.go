@@ -2,6 +2,7 @@ package visualization
 
 import (
 	"fmt"
+	"math"
 	"multilateration-sim/internal/common"     // Замените на ваше имя модуля
 	"multilateration-sim/internal/simulation" // Замените на ваше имя модуля
 
@@ -10,15 +11,69 @@ import (
 )
 
 // Projector is an interface for dimensionality reduction techniques.
+// Fit learns the transform from the current scene, Transform maps any point with it,
+// so that true positions, estimates and other N-D quantities share one 2D basis.
 type Projector interface {
-	// Project takes a slice of simulation objects and returns their 2D projections,
-	// along with a map linking original object IDs to their 2D positions.
-	Project(objects []simulation.SimulationObject) (map[string]common.Vector, error)
+	// Fit computes the projection from the positions of the given objects.
+	Fit(objects []simulation.SimulationObject) error
+	// Transform maps a point with the transform of the last Fit call.
+	Transform(pos common.Vector) (common.Vector, error)
 }
 
+// Project fits the projector to the objects and returns their 2D positions by object ID.
+func Project(p Projector, objects []simulation.SimulationObject) (map[string]common.Vector, error) {
+	if err := p.Fit(objects); err != nil {
+		return nil, err
+	}
+	projected := make(map[string]common.Vector, len(objects))
+	for _, obj := range objects {
+		pos, err := p.Transform(obj.GetPosition())
+		if err != nil {
+			return nil, fmt.Errorf("object %s: %w", obj.GetID(), err)
+		}
+		projected[obj.GetID()] = pos
+	}
+	return projected, nil
+}
+
+// linearProjector is implemented by projectors that map points linearly, so that
+// covariances can be drawn in the same 2D space as the objects.
+type linearProjector interface {
+	Projector
+	// ProjectCovariance maps an n x n covariance to the 2 x 2 covariance of the projection.
+	ProjectCovariance(cov [][]float64) ([][]float64, bool)
+}
+
+// invertibleProjector is implemented by projectors that can map a screen-plane point back
+// into the simulation space, e.g. for picking positions with the mouse.
+type invertibleProjector interface {
+	// UnprojectPoint returns the world point of the last projection that maps to the given
+	// 2D point. For dimension > 2 this is the point in the projection plane.
+	UnprojectPoint(p common.Vector) (common.Vector, bool)
+}
+
+// PCAStabilization selects how the PCA basis evolves between frames.
+type PCAStabilization int
+
+const (
+	// PCARefit fits a new basis every frame. Exact, but the scene may rotate or flip as objects move.
+	PCARefit PCAStabilization = iota
+	// PCAFixed fits the basis once and keeps it until Reset or a change of dimension.
+	PCAFixed
+	// PCASmoothed blends each new fit into the previous basis after aligning the order
+	// and signs of the components, so the view follows the data without jumps.
+	PCASmoothed
+)
+
 // PCAProjector uses Principal Component Analysis to project n-dimensional data to 2D.
 type PCAProjector struct {
 	targetDimension int
+
+	sourceDim int       // Dimension seen by the last Project call, 0 before the first one
+	basis     []float64 // Row-major sourceDim x targetDimension projection, nil means identity/padding
+
+	stabilization PCAStabilization
+	smoothing     float64 // Weight of the new fit in PCASmoothed mode, (0, 1]
 }
 
 // NewPCAProjector creates a new PCA projector targeting 2D.
@@ -26,95 +81,247 @@ func NewPCAProjector() *PCAProjector {
 	return &PCAProjector{targetDimension: 2}
 }
 
-// Project performs PCA on the positions of the given simulation objects.
-// It returns a map of objectID to its new 2D common.Vector position.
-func (p *PCAProjector) Project(objects []simulation.SimulationObject) (map[string]common.Vector, error) {
+// SetStabilization selects how the basis evolves between frames. smoothing is the
+// weight of each new fit in PCASmoothed mode, e.g. 0.05; it is ignored by other modes.
+func (p *PCAProjector) SetStabilization(mode PCAStabilization, smoothing float64) error {
+	if mode == PCASmoothed && (smoothing <= 0 || smoothing > 1) {
+		return fmt.Errorf("smoothing must be in (0, 1], got %f", smoothing)
+	}
+	p.stabilization, p.smoothing = mode, smoothing
+	return nil
+}
+
+// Reset forgets the current basis, so the next Fit starts from scratch.
+func (p *PCAProjector) Reset() {
+	p.sourceDim, p.basis = 0, nil
+}
+
+// Fit performs PCA on the positions of the given simulation objects. Scenes of
+// dimension 2 are drawn as they are, 1D scenes are padded with a zero y-coordinate.
+func (p *PCAProjector) Fit(objects []simulation.SimulationObject) error {
 	if len(objects) == 0 {
-		return make(map[string]common.Vector), nil // No objects, return empty map
+		return nil // Nothing to fit, keep the previous transform
 	}
 
 	sourceDim := objects[0].GetPosition().Dimension()
+	if p.stabilization == PCAFixed && p.basis != nil && p.sourceDim == sourceDim {
+		return nil
+	}
 	if sourceDim <= p.targetDimension {
-		// If source dimension is already 2D (or 1D), we can't reduce to 2D meaningfully via PCA this way.
-		// Or, if it's 2D, we can just return the original coordinates.
-		// For simplicity, if sourceDim < targetDim, let's return an error or handle as a special case.
-		// For now, if source is 2D, we'll just "project" by returning the original 2D coords.
-		if sourceDim == 2 && p.targetDimension == 2 {
-			projectedPositions := make(map[string]common.Vector, len(objects))
-			for _, obj := range objects {
-				projectedPositions[obj.GetID()] = obj.GetPosition().Clone()
-			}
-			return projectedPositions, nil
+		if sourceDim < 1 {
+			return fmt.Errorf("source dimension (%d) is less than target dimension (%d), PCA not applicable in this setup", sourceDim, p.targetDimension)
 		}
-		// If sourceDim is 1D and target is 2D, we could pad with a zero y-coordinate.
-		if sourceDim == 1 && p.targetDimension == 2 {
-			projectedPositions := make(map[string]common.Vector, len(objects))
-			for _, obj := range objects {
-				originalPos := obj.GetPosition()
-				projectedPos := common.NewVector(2)
-				projectedPos[0] = originalPos[0]
-				projectedPos[1] = 0 // Pad with zero for the second dimension
-				projectedPositions[obj.GetID()] = projectedPos
-			}
-			return projectedPositions, nil
-		}
-		return nil, fmt.Errorf("source dimension (%d) is less than target dimension (%d), PCA not applicable in this setup", sourceDim, p.targetDimension)
+		p.sourceDim, p.basis = sourceDim, nil // Identity/padding, see projectionMatrix
+		return nil
 	}
 
-	numSamples := len(objects)
-	data := make([]float64, numSamples*sourceDim)
-	objectIDs := make([]string, numSamples) // To map results back
-
+	// Samples as rows and features (dimensions) as columns
+	positions := make([]common.Vector, len(objects))
 	for i, obj := range objects {
-		pos := obj.GetPosition()
-		objectIDs[i] = obj.GetID()
-		for j := 0; j < sourceDim; j++ {
-			data[i*sourceDim+j] = pos[j]
-		}
+		positions[i] = obj.GetPosition()
 	}
-
-	// Create a Gonum matrix from the data.
-	// The matrix should have samples as rows and features (dimensions) as columns.
-	matrix := mat.NewDense(numSamples, sourceDim, data)
+	data, err := common.StackRows(positions)
+	if err != nil {
+		return fmt.Errorf("PCA samples: %w", err)
+	}
+	matrix := mat.NewDense(len(positions), sourceDim, data)
 
 	// Perform PCA.
 	var pc stat.PC
 	ok := pc.PrincipalComponents(matrix, nil) // nil for weights means all samples weighted equally
 	if !ok {
-		return nil, fmt.Errorf("PCA computation failed")
+		return fmt.Errorf("PCA computation failed")
 	}
 
 	// Check explained variance (optional, for debugging/info)
 	// variances := pc.VarsTo(nil)
 	// fmt.Printf("PCA Variances explained by each component: %v\n", variances)
 
-	// Reduce the dimensionality to targetDimension (2D).
-	// k is the number of principal components to keep.
-	k := p.targetDimension
-	if sourceDim < k { // Should have been caught earlier, but defensive check
-		k = sourceDim
-	}
-
-	var reduced mat.Dense
+	// Keep the first targetDimension principal components as the projection basis.
 	var vec mat.Dense
-	// pc.Reduce(&reduced, k, matrix) // Reduce projects data onto the first k principal components
 	pc.VectorsTo(&vec)
-	reduced.Mul(matrix, vec.Slice(0, sourceDim, 0, k))
-
-	// Store the projected 2D coordinates.
-	projectedPositions := make(map[string]common.Vector, numSamples)
-	for i := 0; i < numSamples; i++ {
-		id := objectIDs[i]
-		pos2D := common.NewVector(p.targetDimension)
-		for j := 0; j < p.targetDimension; j++ {
-			if j < reduced.RawMatrix().Cols { // Ensure we don't go out of bounds if k < targetDimension
-				pos2D[j] = reduced.At(i, j)
-			} else {
-				pos2D[j] = 0 // Pad with zero if k was less than targetDimension (e.g. sourceDim was 1)
+	k := min(p.targetDimension, vec.RawMatrix().Cols)
+	basis := make([]float64, sourceDim*p.targetDimension)
+	for i := 0; i < sourceDim; i++ {
+		for j := 0; j < k; j++ {
+			basis[i*p.targetDimension+j] = vec.At(i, j)
+		}
+	}
+	if p.stabilization == PCASmoothed && p.basis != nil && p.sourceDim == sourceDim {
+		basis = blendBasis(p.basis, basis, sourceDim, p.targetDimension, p.smoothing)
+	}
+	p.sourceDim, p.basis = sourceDim, basis
+	return nil
+}
+
+// blendBasis moves the previous basis towards a new fit. Principal components are only
+// defined up to sign and their order flips when variances cross, so the new columns are
+// first matched to the previous ones; the blend is re-orthonormalized (Gram-Schmidt).
+// Both bases are row-major rows x cols.
+func blendBasis(previous, fitted []float64, rows, cols int, weight float64) []float64 {
+	column := func(m []float64, j int) []float64 {
+		c := make([]float64, rows)
+		for i := range c {
+			c[i] = m[i*cols+j]
+		}
+		return c
+	}
+	dot := func(a, b []float64) float64 {
+		sum := 0.0
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+
+	// Greedy matching: each previous column takes the most similar unused new column
+	used := make([]bool, cols)
+	blended := make([][]float64, cols)
+	for j := 0; j < cols; j++ {
+		prev := column(previous, j)
+		best, bestSim := -1, -1.0
+		for c := 0; c < cols; c++ {
+			if !used[c] && math.Abs(dot(prev, column(fitted, c))) > bestSim {
+				best, bestSim = c, math.Abs(dot(prev, column(fitted, c)))
+			}
+		}
+		used[best] = true
+		next := column(fitted, best)
+		if dot(prev, next) < 0 {
+			for i := range next {
+				next[i] = -next[i]
+			}
+		}
+		blended[j] = make([]float64, rows)
+		for i := range prev {
+			blended[j][i] = (1-weight)*prev[i] + weight*next[i]
+		}
+	}
+
+	out := make([]float64, rows*cols)
+	for j := 0; j < cols; j++ {
+		v := blended[j]
+		for k := 0; k < j; k++ {
+			proj := dot(v, blended[k])
+			for i := range v {
+				v[i] -= proj * blended[k][i]
+			}
+		}
+		norm := math.Sqrt(dot(v, v))
+		if norm == 0 {
+			return fitted // Degenerate blend, fall back to the new fit
+		}
+		for i := range v {
+			v[i] /= norm
+			out[i*cols+j] = v[i]
+		}
+	}
+	return out
+}
+
+// projectionMatrix returns the sourceDim x 2 matrix of the last projection (row-major).
+func (p *PCAProjector) projectionMatrix() ([]float64, bool) {
+	if p.sourceDim == 0 || p.sourceDim > 2 && p.basis == nil {
+		return nil, false
+	}
+	if p.basis != nil {
+		return p.basis, true
+	}
+	m := make([]float64, p.sourceDim*p.targetDimension) // 1D/2D: identity, padded with zeros
+	for i := 0; i < p.sourceDim && i < p.targetDimension; i++ {
+		m[i*p.targetDimension+i] = 1
+	}
+	return m, true
+}
+
+// Transform maps a point with the basis of the last Fit call.
+func (p *PCAProjector) Transform(pos common.Vector) (common.Vector, error) {
+	m, ok := p.projectionMatrix()
+	if !ok {
+		return nil, fmt.Errorf("projector has not been fitted")
+	}
+	return planeBasis{rows: p.sourceDim, m: m}.transform(pos)
+}
+
+// ProjectCovariance maps an n x n covariance C to P^T C P for the projection matrix P.
+func (p *PCAProjector) ProjectCovariance(cov [][]float64) ([][]float64, bool) {
+	m, ok := p.projectionMatrix()
+	if !ok {
+		return nil, false
+	}
+	return planeBasis{rows: p.sourceDim, m: m}.projectCovariance(cov)
+}
+
+// UnprojectPoint implements invertibleProjector. The PCA basis is orthonormal, so the
+// preimage within the projection plane is P p.
+func (p *PCAProjector) UnprojectPoint(point common.Vector) (common.Vector, bool) {
+	m, ok := p.projectionMatrix()
+	if !ok {
+		return nil, false
+	}
+	return planeBasis{rows: p.sourceDim, m: m}.unproject(point)
+}
+
+// String returns the name shown in the overlay.
+func (p *PCAProjector) String() string {
+	return "PCA"
+}
+
+// planeBasis is a row-major rows x 2 projection matrix P with orthonormal columns,
+// shared by the linear projectors.
+type planeBasis struct {
+	rows int
+	m    []float64
+}
+
+// transform returns P^T pos.
+func (b planeBasis) transform(pos common.Vector) (common.Vector, error) {
+	if pos.Dimension() != b.rows {
+		return nil, fmt.Errorf("point dimension %d does not match fitted dimension %d", pos.Dimension(), b.rows)
+	}
+	out := common.NewVector(2)
+	for i := 0; i < b.rows; i++ {
+		for j := 0; j < 2; j++ {
+			out[j] += pos[i] * b.m[i*2+j]
+		}
+	}
+	return out, nil
+}
+
+// projectCovariance returns P^T C P.
+func (b planeBasis) projectCovariance(cov [][]float64) ([][]float64, bool) {
+	if len(cov) != b.rows {
+		return nil, false
+	}
+	out := make([][]float64, 2)
+	for a := 0; a < 2; a++ {
+		out[a] = make([]float64, 2)
+		for c := 0; c < 2; c++ {
+			sum := 0.0
+			for i := 0; i < b.rows; i++ {
+				if len(cov[i]) != b.rows {
+					return nil, false
+				}
+				for j := 0; j < b.rows; j++ {
+					sum += b.m[i*2+a] * cov[i][j] * b.m[j*2+c]
+				}
 			}
+			out[a][c] = sum
 		}
-		projectedPositions[id] = pos2D
 	}
+	return out, true
+}
 
-	return projectedPositions, nil
+// unproject returns P p, the preimage of p within the projection plane.
+func (b planeBasis) unproject(point common.Vector) (common.Vector, bool) {
+	if point.Dimension() != 2 {
+		return nil, false
+	}
+	out := common.NewVector(b.rows)
+	for i := 0; i < b.rows; i++ {
+		for j := 0; j < 2; j++ {
+			out[i] += b.m[i*2+j] * point[j]
+		}
+	}
+	return out, true
 }
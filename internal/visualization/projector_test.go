@@ -0,0 +1,123 @@
+package visualization_test
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"multilateration-sim/internal/visualization"
+	"testing"
+)
+
+// targetsAt returns static targets at the given positions, with IDs t0, t1, ...
+func targetsAt(positions ...common.Vector) []simulation.SimulationObject {
+	objects := make([]simulation.SimulationObject, len(positions))
+	for i, pos := range positions {
+		objects[i] = simulation.NewTargetWithID(fmt.Sprint("t", i), pos, nil)
+	}
+	return objects
+}
+
+// tiltedPlane embeds 2D points into the plane through (5, -3, 7) spanned by the
+// orthonormal vectors (1, 2, 2)/3 and (2, 1, -2)/3.
+func tiltedPlane(points ...common.Vector) []common.Vector {
+	origin, u, v := common.Vector{5, -3, 7}, common.Vector{1.0 / 3, 2.0 / 3, 2.0 / 3}, common.Vector{2.0 / 3, 1.0 / 3, -2.0 / 3}
+	out := make([]common.Vector, len(points))
+	for i, p := range points {
+		out[i] = common.Vector{
+			origin[0] + p[0]*u[0] + p[1]*v[0],
+			origin[1] + p[0]*u[1] + p[1]*v[1],
+			origin[2] + p[0]*u[2] + p[1]*v[2],
+		}
+	}
+	return out
+}
+
+// planarConfiguration is a scene without symmetries, so its layout is unique up to rigid motion.
+var planarConfiguration = []common.Vector{{0, 0}, {10, 0}, {0, 6}, {7, 8}, {-4, 3}}
+
+// checkDistances fails unless the pairwise distances of got match those of want.
+func checkDistances(t *testing.T, got, want []common.Vector) {
+	t.Helper()
+	for i := range want {
+		for j := i + 1; j < len(want); j++ {
+			d, err := got[i].Distance(got[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w, _ := want[i].Distance(want[j]); math.Abs(d-w) > 1e-9 {
+				t.Errorf("distance %d-%d: got %v, want %v", i, j, d, w)
+			}
+		}
+	}
+}
+
+func TestPCAProjectorLowDimensions(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []common.Vector
+		want      []common.Vector
+	}{
+		{"2D is drawn as is", []common.Vector{{1, 2}, {-3, 4}}, []common.Vector{{1, 2}, {-3, 4}}},
+		{"1D is padded", []common.Vector{{1}, {-3}}, []common.Vector{{1, 0}, {-3, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projected, err := visualization.Project(visualization.NewPCAProjector(), targetsAt(tt.positions...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.want {
+				if got := projected[fmt.Sprint("t", i)]; !got.Equal(want, 0) {
+					t.Errorf("t%d: got %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPCAProjectorPlanarScene(t *testing.T) {
+	positions := tiltedPlane(planarConfiguration...)
+	p := visualization.NewPCAProjector()
+	projected, err := visualization.Project(p, targetsAt(positions...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]common.Vector, len(positions))
+	for i := range positions {
+		got[i] = projected[fmt.Sprint("t", i)]
+	}
+	checkDistances(t, got, planarConfiguration)
+
+	// Points other than the objects share the fitted basis: the transform is linear and
+	// drops the offset along the normal (2, -2, 1)/3 of the plane.
+	midpoint, _ := positions[0].Lerp(positions[3], 0.5)
+	mid, err := p.Transform(midpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := got[0].Lerp(got[3], 0.5); !mid.Equal(want, 1e-9) {
+		t.Errorf("midpoint: got %v, want %v", mid, want)
+	}
+	offPlane, _ := positions[1].Add(common.Vector{2, -2, 1})
+	off, err := p.Transform(offPlane)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !off.Equal(got[1], 1e-9) {
+		t.Errorf("point off the plane: got %v, want %v", off, got[1])
+	}
+}
+
+func TestPCAProjectorTransformErrors(t *testing.T) {
+	p := visualization.NewPCAProjector()
+	if _, err := p.Transform(common.Vector{1, 2, 3}); err == nil {
+		t.Error("Transform before Fit succeeded")
+	}
+	if err := p.Fit(targetsAt(tiltedPlane(planarConfiguration...)...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Transform(common.Vector{1, 2}); err == nil {
+		t.Error("Transform of a point of another dimension succeeded")
+	}
+}
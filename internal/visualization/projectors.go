@@ -0,0 +1,218 @@
+package visualization
+
+import (
+	"fmt"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const orthoRotationSpeed = 1.5 // Radians per second while an arrow key is held
+
+// interactiveProjector is implemented by projectors whose view is controlled with the
+// arrow keys while they are active.
+type interactiveProjector interface {
+	// HandleInput reads the arrow keys and updates the view.
+	HandleInput()
+}
+
+// SliceProjector shows two axes of the N-D space, e.g. X/Z, dropping the others.
+// Left/right arrows change the horizontal axis, up/down the vertical one.
+type SliceProjector struct {
+	axisU, axisV int
+	sourceDim    int
+}
+
+// NewSliceProjector creates a projector showing axisU horizontally and axisV vertically.
+func NewSliceProjector(axisU, axisV int) (*SliceProjector, error) {
+	if axisU < 0 || axisV < 0 || axisU == axisV {
+		return nil, fmt.Errorf("invalid slice axes (%d, %d)", axisU, axisV)
+	}
+	return &SliceProjector{axisU: axisU, axisV: axisV}, nil
+}
+
+// Fit implements Projector. It only checks that both axes exist.
+func (p *SliceProjector) Fit(objects []simulation.SimulationObject) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	dim := objects[0].GetPosition().Dimension()
+	if p.axisU >= dim || p.axisV >= dim {
+		return fmt.Errorf("slice axes (%d, %d) do not exist in dimension %d", p.axisU, p.axisV, dim)
+	}
+	p.sourceDim = dim
+	return nil
+}
+
+func (p *SliceProjector) basis() (planeBasis, error) {
+	if p.sourceDim == 0 {
+		return planeBasis{}, fmt.Errorf("projector has not been fitted")
+	}
+	m := make([]float64, p.sourceDim*2)
+	m[p.axisU*2] = 1
+	m[p.axisV*2+1] = 1
+	return planeBasis{rows: p.sourceDim, m: m}, nil
+}
+
+// Transform implements Projector.
+func (p *SliceProjector) Transform(pos common.Vector) (common.Vector, error) {
+	b, err := p.basis()
+	if err != nil {
+		return nil, err
+	}
+	return b.transform(pos)
+}
+
+// ProjectCovariance implements linearProjector: the 2x2 block of the two axes.
+func (p *SliceProjector) ProjectCovariance(cov [][]float64) ([][]float64, bool) {
+	b, err := p.basis()
+	if err != nil {
+		return nil, false
+	}
+	return b.projectCovariance(cov)
+}
+
+// UnprojectPoint implements invertibleProjector; the hidden axes are set to 0.
+func (p *SliceProjector) UnprojectPoint(point common.Vector) (common.Vector, bool) {
+	b, err := p.basis()
+	if err != nil {
+		return nil, false
+	}
+	return b.unproject(point)
+}
+
+// HandleInput implements interactiveProjector.
+func (p *SliceProjector) HandleInput() {
+	if p.sourceDim < 2 {
+		return
+	}
+	next := func(axis, other, step int) int {
+		for {
+			axis = (axis + step + p.sourceDim) % p.sourceDim
+			if axis != other {
+				return axis
+			}
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		p.axisU = next(p.axisU, p.axisV, 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		p.axisU = next(p.axisU, p.axisV, -1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		p.axisV = next(p.axisV, p.axisU, 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		p.axisV = next(p.axisV, p.axisU, -1)
+	}
+}
+
+// String returns the name shown in the overlay.
+func (p *SliceProjector) String() string {
+	return fmt.Sprintf("срез X%d/X%d", p.axisU, p.axisV)
+}
+
+// OrthographicProjector views the first three axes from a camera direction given by
+// yaw (around axis 2) and pitch (elevation above the axis 0/1 plane). Further axes are
+// ignored, lower-dimensional scenes are padded with zeros. Arrow keys rotate the view.
+type OrthographicProjector struct {
+	yaw, pitch float64 // Radians
+	sourceDim  int
+}
+
+// NewOrthographicProjector creates a projector with the given initial view angles.
+func NewOrthographicProjector(yaw, pitch float64) *OrthographicProjector {
+	return &OrthographicProjector{yaw: yaw, pitch: pitch}
+}
+
+// SetView sets the view angles; pitch is clamped to [-pi/2, pi/2].
+func (p *OrthographicProjector) SetView(yaw, pitch float64) {
+	p.yaw = math.Mod(yaw, 2*math.Pi)
+	p.pitch = math.Max(-math.Pi/2, math.Min(math.Pi/2, pitch))
+}
+
+// View returns the current view angles.
+func (p *OrthographicProjector) View() (yaw, pitch float64) {
+	return p.yaw, p.pitch
+}
+
+// Fit implements Projector; the view does not depend on the data.
+func (p *OrthographicProjector) Fit(objects []simulation.SimulationObject) error {
+	if len(objects) > 0 {
+		p.sourceDim = objects[0].GetPosition().Dimension()
+	}
+	return nil
+}
+
+// basis returns the screen axes u = (cos yaw, sin yaw, 0) and
+// v = (-sin yaw sin pitch, cos yaw sin pitch, cos pitch), which are orthonormal.
+func (p *OrthographicProjector) basis() (planeBasis, error) {
+	if p.sourceDim == 0 {
+		return planeBasis{}, fmt.Errorf("projector has not been fitted")
+	}
+	u := [3]float64{math.Cos(p.yaw), math.Sin(p.yaw), 0}
+	v := [3]float64{-math.Sin(p.yaw) * math.Sin(p.pitch), math.Cos(p.yaw) * math.Sin(p.pitch), math.Cos(p.pitch)}
+	m := make([]float64, p.sourceDim*2)
+	for i := 0; i < p.sourceDim && i < 3; i++ {
+		m[i*2] = u[i]
+		m[i*2+1] = v[i]
+	}
+	return planeBasis{rows: p.sourceDim, m: m}, nil
+}
+
+// Transform implements Projector.
+func (p *OrthographicProjector) Transform(pos common.Vector) (common.Vector, error) {
+	b, err := p.basis()
+	if err != nil {
+		return nil, err
+	}
+	return b.transform(pos)
+}
+
+// ProjectCovariance implements linearProjector.
+func (p *OrthographicProjector) ProjectCovariance(cov [][]float64) ([][]float64, bool) {
+	b, err := p.basis()
+	if err != nil {
+		return nil, false
+	}
+	return b.projectCovariance(cov)
+}
+
+// UnprojectPoint implements invertibleProjector. Only exact for 3D scenes, where the
+// basis columns are orthonormal; the point is placed in the view plane through the origin.
+func (p *OrthographicProjector) UnprojectPoint(point common.Vector) (common.Vector, bool) {
+	if p.sourceDim < 3 {
+		return nil, false
+	}
+	b, err := p.basis()
+	if err != nil {
+		return nil, false
+	}
+	return b.unproject(point)
+}
+
+// HandleInput implements interactiveProjector: left/right change yaw, up/down pitch.
+func (p *OrthographicProjector) HandleInput() {
+	step := orthoRotationSpeed / float64(ebiten.TPS())
+	yaw, pitch := p.yaw, p.pitch
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		yaw -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		yaw += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+		pitch += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		pitch -= step
+	}
+	p.SetView(yaw, pitch)
+}
+
+// String returns the name shown in the overlay.
+func (p *OrthographicProjector) String() string {
+	return fmt.Sprintf("орто (рыск %.0f°, тангаж %.0f°)", p.yaw*180/math.Pi, p.pitch*180/math.Pi)
+}
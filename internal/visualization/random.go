@@ -0,0 +1,139 @@
+package visualization
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+)
+
+// RandomProjector projects onto a random plane, in the spirit of the Johnson-Lindenstrauss
+// lemma: for high-dimensional scenes a random projection preserves the relative
+// distances reasonably well without looking at the data. Fit is O(objects) and the plane
+// is drawn once per dimension, so unlike PCA (O(objects * d^2 + d^3) per frame) it stays
+// cheap and stable for d in the tens, at the cost of not choosing the most informative
+// plane. The Gaussian directions are orthonormalized, so the plane itself is not
+// distorted and points can be picked on it. 1D and 2D scenes are drawn as they are.
+type RandomProjector struct {
+	rng       *rand.Rand
+	sourceDim int
+	basis     []float64 // Row-major sourceDim x 2 with orthonormal columns
+}
+
+// NewRandomProjector creates a random projector; the same seed gives the same planes.
+func NewRandomProjector(seed int64) *RandomProjector {
+	return &RandomProjector{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Fit implements Projector. A new plane is drawn only when the dimension changes.
+func (p *RandomProjector) Fit(objects []simulation.SimulationObject) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	dim := objects[0].GetPosition().Dimension()
+	for _, obj := range objects {
+		if obj.GetPosition().Dimension() != dim {
+			return fmt.Errorf("inconsistent dimensions: %d and %d", dim, obj.GetPosition().Dimension())
+		}
+	}
+	if dim != p.sourceDim {
+		p.sourceDim = dim
+		p.Redraw()
+	}
+	return nil
+}
+
+// Redraw picks a new random plane for the current dimension.
+func (p *RandomProjector) Redraw() {
+	n := p.sourceDim
+	if n == 0 {
+		return
+	}
+	m := make([]float64, n*2)
+	if n <= 2 { // Nothing to reduce: identity, padded with zeros
+		for i := 0; i < n; i++ {
+			m[i*2+i] = 1
+		}
+		p.basis = m
+		return
+	}
+	for {
+		for i := range m {
+			m[i] = p.rng.NormFloat64()
+		}
+		if orthonormalizeColumns(m, n) {
+			p.basis = m
+			return
+		}
+	}
+}
+
+// orthonormalizeColumns applies Gram-Schmidt to the two columns of a row-major n x 2
+// matrix in place. Returns false if they are (numerically) parallel.
+func orthonormalizeColumns(m []float64, n int) bool {
+	normalize := func(col int) bool {
+		norm := 0.0
+		for i := 0; i < n; i++ {
+			norm += m[i*2+col] * m[i*2+col]
+		}
+		norm = math.Sqrt(norm)
+		if norm < 1e-9 {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			m[i*2+col] /= norm
+		}
+		return true
+	}
+	if !normalize(0) {
+		return false
+	}
+	dot := 0.0
+	for i := 0; i < n; i++ {
+		dot += m[i*2] * m[i*2+1]
+	}
+	for i := 0; i < n; i++ {
+		m[i*2+1] -= dot * m[i*2]
+	}
+	return normalize(1)
+}
+
+func (p *RandomProjector) plane() (planeBasis, error) {
+	if p.basis == nil {
+		return planeBasis{}, fmt.Errorf("projector has not been fitted")
+	}
+	return planeBasis{rows: p.sourceDim, m: p.basis}, nil
+}
+
+// Transform implements Projector.
+func (p *RandomProjector) Transform(pos common.Vector) (common.Vector, error) {
+	b, err := p.plane()
+	if err != nil {
+		return nil, err
+	}
+	return b.transform(pos)
+}
+
+// ProjectCovariance implements linearProjector.
+func (p *RandomProjector) ProjectCovariance(cov [][]float64) ([][]float64, bool) {
+	b, err := p.plane()
+	if err != nil {
+		return nil, false
+	}
+	return b.projectCovariance(cov)
+}
+
+// UnprojectPoint implements invertibleProjector: the point of the random plane.
+func (p *RandomProjector) UnprojectPoint(point common.Vector) (common.Vector, bool) {
+	b, err := p.plane()
+	if err != nil {
+		return nil, false
+	}
+	return b.unproject(point)
+}
+
+// String returns the name shown in the overlay.
+func (p *RandomProjector) String() string {
+	return "случайная проекция"
+}
@@ -0,0 +1,48 @@
+package visualization
+
+import (
+	"fmt"
+	"image"
+	"multilateration-sim/internal/offscreen"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// frameRecording saves rendered frames to disk.
+type frameRecording struct {
+	writer *offscreen.FrameWriter // nil while not recording
+	every  int                    // Save every n-th frame
+	frames int                    // Frames drawn since recording started
+	failed bool                   // The failure was reported, stop trying
+}
+
+// SetFrameRecorder saves every n-th drawn frame, exactly as shown in the window, through
+// writer (nil stops recording). Closing the writer is up to the caller once the game
+// loop has ended.
+func (r *Renderer) SetFrameRecorder(writer *offscreen.FrameWriter, every int) error {
+	if every < 1 {
+		return fmt.Errorf("frame interval must be at least 1, got %d", every)
+	}
+	r.recording = frameRecording{writer: writer, every: every}
+	return nil
+}
+
+// captureFrame copies the finished screen to the frame recorder. Write errors are
+// reported once in the log panel.
+func (r *Renderer) captureFrame(screen *ebiten.Image) {
+	rec := &r.recording
+	if rec.writer == nil || rec.failed {
+		return
+	}
+	rec.frames++
+	if (rec.frames-1)%rec.every != 0 {
+		return
+	}
+	bounds := screen.Bounds()
+	img := image.NewRGBA(bounds)
+	screen.ReadPixels(img.Pix) // The screen is opaque, so premultiplied alpha is plain RGBA
+	if err := rec.writer.Write(img); err != nil {
+		rec.failed = true
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("запись кадров остановлена: %v", err)})
+	}
+}
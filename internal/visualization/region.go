@@ -0,0 +1,129 @@
+package visualization
+
+import (
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const maxRegionBoxDimension = 6 // Larger boxes have too many edges to be readable
+
+var (
+	regionColor       = color.RGBA{0, 150, 80, 255}   // Зелёный
+	worldColor        = color.RGBA{40, 40, 120, 255}  // Тёмно-синий: граница мира
+	obstacleColor     = color.RGBA{70, 70, 70, 255}   // Тёмно-серый: блокирует измерения
+	nlosObstacleColor = color.RGBA{170, 100, 30, 255} // Коричневый: удлиняет дальности (NLOS)
+)
+
+// drawRegionOfInterest outlines the simulation's region of interest.
+func (r *Renderer) drawRegionOfInterest(screen *ebiten.Image) {
+	r.strokeRegion(screen, r.sim.RegionOfInterest(), regionColor, 1.5)
+}
+
+// drawWorld outlines the world the objects are confined to, if it is not the bounds box.
+func (r *Renderer) drawWorld(screen *ebiten.Image) {
+	if world := r.sim.World(); world != nil {
+		r.strokeRegion(screen, world, worldColor, 2)
+	}
+}
+
+// drawObstacles outlines every obstacle, in a separate color if it lengthens ranges
+// instead of blocking them.
+func (r *Renderer) drawObstacles(screen *ebiten.Image) {
+	for _, obs := range r.sim.GetObstacles() {
+		clr := obstacleColor
+		if obs.NLOSBias() > 0 {
+			clr = nlosObstacleColor
+		}
+		r.strokeRegion(screen, obs.Shape(), clr, 2.5)
+	}
+}
+
+// strokeRegion draws the projected edges of a box, the outline of a ball for linear
+// (orthonormal) projections or of a polygon in 2D. Other regions are not drawn.
+func (r *Renderer) strokeRegion(screen *ebiten.Image, region simulation.Region, clr color.Color, width float32) {
+	switch region := region.(type) {
+	case *simulation.BoxRegion:
+		lo, hi := region.Min(), region.Max()
+		n := lo.Dimension()
+		if n > maxRegionBoxDimension {
+			return
+		}
+		corner := func(bits int) common.Vector {
+			c := lo.Clone()
+			for i := 0; i < n; i++ {
+				if bits&(1<<i) != 0 {
+					c[i] = hi[i]
+				}
+			}
+			return c
+		}
+		for bits := 0; bits < 1<<n; bits++ {
+			for i := 0; i < n; i++ {
+				if bits&(1<<i) != 0 {
+					continue // Each edge is drawn once, from its lower end
+				}
+				x0, y0, ok0 := r.pointToScreen(corner(bits), true)
+				x1, y1, ok1 := r.pointToScreen(corner(bits|1<<i), true)
+				if ok0 && ok1 {
+					vector.StrokeLine(screen, x0, y0, x1, y1, width, clr, true)
+				}
+			}
+		}
+	case *simulation.BallRegion:
+		if _, linear := r.projector.(linearProjector); !linear {
+			return
+		}
+		if x, y, ok := r.pointToScreen(region.Center(), true); ok {
+			vector.StrokeCircle(screen, x, y, float32(region.Radius()*r.scale), width, clr, true)
+		}
+	case *simulation.PolytopeRegion:
+		vertices := polygonVertices(region)
+		for i, v := range vertices {
+			x0, y0, ok0 := r.pointToScreen(v, true)
+			x1, y1, ok1 := r.pointToScreen(vertices[(i+1)%len(vertices)], true)
+			if ok0 && ok1 {
+				vector.StrokeLine(screen, x0, y0, x1, y1, width, clr, true)
+			}
+		}
+	}
+}
+
+// polygonVertices returns the corners of a bounded 2D polytope in counterclockwise
+// order: the intersections of pairs of edge lines that lie inside it. It returns nil in
+// other dimensions.
+func polygonVertices(p *simulation.PolytopeRegion) []common.Vector {
+	normals, offsets := p.Normals(), p.Offsets()
+	if normals[0].Dimension() != 2 {
+		return nil
+	}
+	var vertices []common.Vector
+	center := common.NewVector(2)
+	for i := range normals {
+		for j := i + 1; j < len(normals); j++ {
+			a, b := normals[i], normals[j]
+			det := a[0]*b[1] - a[1]*b[0]
+			if math.Abs(det) < 1e-12 {
+				continue // Parallel edges
+			}
+			v := common.Vector{(offsets[i]*b[1] - offsets[j]*a[1]) / det, (a[0]*offsets[j] - b[0]*offsets[i]) / det}
+			if p.SignedDistance(v) <= 1e-9*(1+v.Norm()) {
+				vertices = append(vertices, v)
+				_ = center.AddInPlace(v)
+			}
+		}
+	}
+	if len(vertices) < 3 {
+		return nil
+	}
+	center.ScaleInPlace(1 / float64(len(vertices)))
+	sort.Slice(vertices, func(i, j int) bool {
+		return math.Atan2(vertices[i][1]-center[1], vertices[i][0]-center[0]) < math.Atan2(vertices[j][1]-center[1], vertices[j][0]-center[0])
+	})
+	return vertices
+}
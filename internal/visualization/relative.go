@@ -0,0 +1,46 @@
+package visualization
+
+import (
+	"image/color"
+	"multilateration-sim/internal/common"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// relativeColor marks the anchor-free map.
+var relativeColor = color.RGBA{0, 130, 130, 220} // Бирюзовый
+
+// drawRelativeMap draws the aligned anchor-free map as hollow squares, each tied to the
+// true position of its object by a thin line, so the distortion of the map shows.
+func (r *Renderer) drawRelativeMap(screen *ebiten.Image) {
+	m, ok := r.sim.LastRelativeMap()
+	if !ok {
+		return
+	}
+	for id, pos := range m.Aligned {
+		obj, ok := r.sim.GetObject(id)
+		if !ok {
+			continue
+		}
+		mx, my, ok := r.screenPoint(pos)
+		if !ok {
+			continue
+		}
+		if tx, ty, ok := r.screenPoint(obj.GetPosition()); ok {
+			vector.StrokeLine(screen, tx, ty, mx, my, 1, relativeColor, true)
+		}
+		const half = 4
+		vector.StrokeRect(screen, mx-half, my-half, 2*half, 2*half, 1.5, relativeColor, true)
+	}
+}
+
+// screenPoint projects a world position onto the screen.
+func (r *Renderer) screenPoint(pos common.Vector) (float32, float32, bool) {
+	p, err := r.projector.Transform(pos)
+	if err != nil || len(p) < 2 {
+		return 0, 0, false
+	}
+	x, y := r.worldToScreen(p[0], p[1])
+	return x, y, true
+}
@@ -4,12 +4,13 @@ import (
 	"fmt"
 	"image/color"
 	"math"
-	"multilateration-sim/internal/common"     // Замените на ваше имя модуля
+	"multilateration-sim/internal/common" // Замените на ваше имя модуля
+	"multilateration-sim/internal/multilateration"
 	"multilateration-sim/internal/simulation" // Замените на ваше имя модуля
-	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -20,16 +21,18 @@ const (
 )
 
 var (
-	sensorColorBase   = color.RGBA{0, 0, 255, 255} // Синий
-	sensorRadiusColor = color.RGBA{0, 0, 200, 50}  // Полупрозрачный синий
-	targetColorBase   = color.RGBA{255, 0, 0, 255} // Красный
-	predictedPosColor = color.RGBA{255, 0, 0, 100} // Полупрозрачный красный
+	sensorColorBase   = color.RGBA{0, 0, 255, 255}     // Синий
+	sensorRadiusColor = color.RGBA{0, 0, 200, 50}      // Полупрозрачный синий
+	sensorDownColor   = color.RGBA{140, 140, 140, 255} // Серый: отказ или перерыв в работе
+	targetColorBase   = color.RGBA{255, 0, 0, 255}     // Красный
+	predictedPosColor = color.RGBA{255, 0, 0, 100}     // Полупрозрачный красный
 )
 
 // Renderer implements ebiten.Game interface for visualization.
 type Renderer struct {
-	sim       *simulation.Simulation
-	projector Projector // PCA projector
+	sim        *simulation.Simulation
+	projector  Projector   // Active projector
+	projectors []Projector // Projectors cycled with P, including the active one
 
 	screenWidth  int
 	screenHeight int
@@ -41,26 +44,126 @@ type Renderer struct {
 
 	// Cached projected coordinates
 	projectedCoords map[string]common.Vector
+
+	format *common.Format // Number format for the overlay, nil means common.DefaultFormat()
+
+	logPanel  *LogPanel                    // Recent simulation events
+	runner    *simulation.SimulationRunner // Simulation stepping: pause, single step and speed
+	ruler     ruler                        // Distance measurement tool
+	voronoi   voronoiOverlay               // Nearest-sensor regions
+	heatmap   heatmapOverlay               // GDOP or sensor coverage over the field
+	trails    trails                       // Recent target trajectories
+	errorPlot errorPlot                    // Mean localization error over time
+
+	camera    camera      // Pan, zoom and auto-fit
+	annotator annotator   // Presentation marks
+	editor    editor      // Dragging, grid snapping and coordinate entry
+	tuning    tuningPanel // Live parameter changes
+
+	sensorFactory SensorFactory // Sensors placed with the mouse, nil means the default sensor
+	exportTrack   TrackExporter // Right click / E handler, nil disables track export
+
+	selectedID      string                      // Object shown in the inspector, "" if none
+	describeNoise   NoiseDescriber              // Sensor noise descriptions for the inspector, may be nil
+	estimateHistory map[string][]estimateRecord // targetID -> recent estimates, oldest first
+
+	saveScenario func() (string, error) // Ctrl+S handler, returns where the scenario was written
+	recording    frameRecording         // Frames saved to disk
 }
 
 // NewRenderer creates a new Ebiten renderer.
 func NewRenderer(sim *simulation.Simulation, projector Projector) *Renderer {
-	return &Renderer{
+	r := &Renderer{
 		sim:             sim,
 		projector:       projector,
+		projectors:      []Projector{projector},
 		projectedCoords: make(map[string]common.Vector),
+		logPanel:        NewLogPanel(500),
+		runner:          simulation.NewSimulationRunner(sim),
+		estimateHistory: make(map[string][]estimateRecord),
+		trails:          trails{length: defaultTrailLength, byID: make(map[string]*trail)},
+		errorPlot:       errorPlot{window: defaultErrorPlotWindow},
+		editor:          editor{gridStep: defaultGridStep},
 		// screenWidth and screenHeight will be set by Layout
 	}
+	sim.OnStep(r.logPanel.HandleStep)
+	sim.OnStep(r.recordEstimates)
+	sim.OnStep(r.recordTrails)
+	sim.OnStep(r.recordErrorPlot)
+	sim.Events().Subscribe(r.logPanel.HandleSensorEvent, simulation.EventSensorFailed, simulation.EventSensorOutage, simulation.EventSensorRestored)
+	return r
+}
+
+// AddProjector makes another projector selectable at runtime with P.
+func (r *Renderer) AddProjector(p Projector) {
+	r.projectors = append(r.projectors, p)
+}
+
+// handleProjectorKeys switches projectors with P and passes the arrow keys to
+// interactive ones. Returns true if the arrow keys were consumed.
+func (r *Renderer) handleProjectorKeys() bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) && len(r.projectors) > 1 {
+		for i, p := range r.projectors {
+			if p == r.projector {
+				r.projector = r.projectors[(i+1)%len(r.projectors)]
+				break
+			}
+		}
+	}
+	interactive, ok := r.projector.(interactiveProjector)
+	if ok {
+		interactive.HandleInput()
+	}
+	return ok
 }
 
-// Update is called every tick.
-// The simulation itself is stepped in the main game loop (main.go) before Ebiten's Update/Draw.
+// LogPanel returns the in-window event log, e.g. to add application messages.
+func (r *Renderer) LogPanel() *LogPanel {
+	return r.logPanel
+}
+
+// SetSaveHandler enables saving the current setup with Ctrl+S. save returns the path
+// it wrote to; the outcome is shown in the log panel.
+func (r *Renderer) SetSaveHandler(save func() (string, error)) {
+	r.saveScenario = save
+}
+
+// SetFormat overrides the number format used in the debug overlay.
+func (r *Renderer) SetFormat(f common.Format) {
+	r.format = &f
+}
+
+// Update is called every tick. It steps the simulation (see playback) and reprojects it.
 func (r *Renderer) Update() error {
+	// Text entry and the annotation mode own keyboard and mouse while active
+	owned := r.handleAnnotations() || r.handleCoordinateEntry() || r.handleDrag() || r.handleTuning()
+	arrowsUsed := !owned && r.handleProjectorKeys()
+	r.advance(!owned, !arrowsUsed)
+	if !owned {
+		r.logPanel.Update()
+		r.handleSaveKey()
+		r.handleExportKey()
+		r.handleVoronoiKey()
+		r.handleHeatmapKey()
+		r.handleTrailKey()
+		r.handleErrorPlotKey()
+		r.handleSnapKey()
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			r.selectedID = ""
+		}
+		if !r.handleCamera() { // Dragging the view owns the mouse
+			r.handleRuler()
+			if !r.ruler.active { // Clicks belong to the ruler while it is on
+				r.handlePlacement()
+			}
+		}
+	}
+
 	// Project all objects for the current frame
 	allObjects := r.sim.GetAllObjects()
 	if len(allObjects) > 0 {
 		var err error
-		r.projectedCoords, err = r.projector.Project(allObjects)
+		r.projectedCoords, err = Project(r.projector, allObjects)
 		if err != nil {
 			// Log error, but don't stop the renderer; previous projection might still be usable or draw nothing
 			fmt.Printf("Renderer Update: PCA Projection failed: %v\n", err)
@@ -76,8 +179,26 @@ func (r *Renderer) Update() error {
 	return nil
 }
 
+// handleSaveKey saves the scenario on Ctrl+S.
+func (r *Renderer) handleSaveKey() {
+	if r.saveScenario == nil || !ebiten.IsKeyPressed(ebiten.KeyControl) || !inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		return
+	}
+	path, err := r.saveScenario()
+	if err != nil {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("сохранение сценария: %v", err)})
+		return
+	}
+	r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: fmt.Sprintf("сценарий сохранён: %s", path)})
+}
+
 // calculateTransform determines the scaling and offset to fit projected points onto the screen.
+// While the user controls the camera the current view is kept.
 func (r *Renderer) calculateTransform() {
+	if r.camera.manual {
+		return
+	}
+	defer func() { r.camera.fitScale = r.scale }()
 	if len(r.projectedCoords) == 0 {
 		r.scale = 1.0
 		r.offsetX = float64(r.screenWidth) / 2.0
@@ -144,6 +265,9 @@ func (r *Renderer) calculateTransform() {
 	centerY := (minY + maxY) / 2.0
 	r.offsetX = float64(r.screenWidth)/2.0 - centerX*r.scale
 	r.offsetY = float64(r.screenHeight)/2.0 - centerY*r.scale
+	if r.timelineMode() { // Leave the space below the line to the time axis
+		r.offsetY = float64(r.screenHeight)*timelineLineFraction - centerY*r.scale
+	}
 }
 
 // worldToScreen converts projected 2D world coordinates to screen coordinates.
@@ -153,6 +277,19 @@ func (r *Renderer) worldToScreen(worldX, worldY float64) (float32, float32) {
 	return float32(screenX), float32(screenY)
 }
 
+// screenToWorld converts screen coordinates back to projected 2D world coordinates.
+func (r *Renderer) screenToWorld(screenX, screenY float64) (float64, float64) {
+	return (screenX - r.offsetX) / r.scale, (screenY - r.offsetY) / r.scale
+}
+
+// activeFormat returns the number format of the overlay.
+func (r *Renderer) activeFormat() common.Format {
+	if r.format != nil {
+		return *r.format
+	}
+	return common.DefaultFormat()
+}
+
 // Draw is called every frame to render the simulation.
 func (r *Renderer) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{230, 230, 230, 255}) // Light gray background
@@ -162,6 +299,17 @@ func (r *Renderer) Draw(screen *ebiten.Image) {
 		return
 	}
 
+	r.drawGrid(screen)
+	r.drawBoundsBox(screen)
+	r.drawWorld(screen)
+	r.drawHeatmap(screen)
+	r.drawVoronoi(screen)
+	r.drawRegionOfInterest(screen)
+	r.drawObstacles(screen)
+	r.drawTimelineAxis(screen)
+	r.drawTrails(screen)
+	r.drawRelativeMap(screen)
+
 	// Draw Sensors and their detection radii
 	for _, sensor := range r.sim.GetSensors() {
 		projPos, ok := r.projectedCoords[sensor.GetID()]
@@ -174,12 +322,20 @@ func (r *Renderer) Draw(screen *ebiten.Image) {
 		// Radius in world units needs to be scaled.
 		// Note: PCA might distort circles. This draws a circle in the 2D projected space.
 		detectionRadiusOnScreen := float32(sensor.DetectionRadius() * r.scale) // DetectionRadius() method needed in Sensor
-		if detectionRadiusOnScreen > 0 {
+		if sensor.Status() != simulation.SensorOperational {                   // A sensor that is down detects nothing
+			vector.DrawFilledCircle(screen, sx, sy, float32(objectRadiusOnScreen), sensorDownColor, true)
+			drawLabel(screen, sensor.Label(), sx, sy)
+			continue
+		}
+		if r.timelineMode() {
+			r.drawSensorTimeline(screen, sensor, sx, sy) // The range is a segment of the line
+		} else if detectionRadiusOnScreen > 0 {
 			vector.DrawFilledCircle(screen, sx, sy, detectionRadiusOnScreen, sensorRadiusColor, true)
 		}
 
 		// Draw sensor
 		vector.DrawFilledCircle(screen, sx, sy, float32(objectRadiusOnScreen), sensorColorBase, true)
+		drawLabel(screen, sensor.Label(), sx, sy)
 	}
 
 	// Draw Targets and their predicted positions
@@ -191,28 +347,10 @@ func (r *Renderer) Draw(screen *ebiten.Image) {
 		}
 		tx, ty := r.worldToScreen(projPos[0], projPos[1])
 
-		// Draw predicted position (if available)
+		// Draw the estimate with its 95% uncertainty ellipse (if available)
 		lastEstimate, estOk := r.sim.GetLastEstimate(targetID)
 		if estOk && lastEstimate.Position != nil {
-			// We need to project the N-D estimated position to 2D as well.
-			// This is tricky: PCA was done on true positions. Applying same transform might not be ideal.
-			// For simplicity, we'll assume the error in N-D translates to a similar region in 2D.
-			// A more robust way would be to include estimates in PCA or project separately.
-			// For now, let's draw the predicted circle around the *projected true position*
-			// if we don't have a direct 2D projection of the estimate.
-			// OR, if the estimate is also N-D, we'd need to project it:
-			// tempObjectsForPCA := []simulation.SimulationObject{simulation.NewPointObject("est", lastEstimate.Position)}
-			// projectedEst, _ := r.projector.Project(tempObjectsForPCA)
-			// if pest, pOk := projectedEst["est"]; pOk {
-			//    esx, esy := r.worldToScreen(pest[0], pest[1])
-			//    vector.DrawFilledCircle(screen, esx, esy, float32(objectRadiusOnScreen*predictedPosRadiusScale), predictedPosColor, true)
-			// }
-			// Simpler: just draw a circle around the target's projected true position as a placeholder for "estimated region"
-			// This is not ideal but simpler for now.
-			// Let's assume lastEstimate.Position is N-D. We need to project it.
-			// This is a bit complex as PCA is fitted to ALL objects. Projecting one point might be unstable.
-			// A simpler visual cue: draw the predicted circle near the true projected target.
-			vector.DrawFilledCircle(screen, tx, ty, float32(objectRadiusOnScreen*predictedPosRadiusScale*2), predictedPosColor, true)
+			r.drawEstimate(screen, lastEstimate)
 		}
 
 		// Draw target as a triangle
@@ -224,19 +362,63 @@ func (r *Renderer) Draw(screen *ebiten.Image) {
 		path.Close()
 		// vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
 		// vector.DrawVertices(screen, vs, is, targetColorBase, &ebiten.DrawTrianglesOptions{AntiAlias: true})
-		vector.DrawFilledCircle(screen, tx, ty, 5, targetColorBase, true)
+		vector.DrawFilledCircle(screen, tx, ty, 5, classColor(target.Class()), true)
+		r.drawTrackStatus(screen, targetID, tx, ty)
+		drawLabel(screen, targetLabel(target), tx, ty)
 
 	}
 
 	// Draw Debug Info
+	r.drawScaleBar(screen)
+	r.drawAnnotations(screen)
+	r.drawRuler(screen)
 	r.drawDebugInfo(screen)
+	r.drawErrorPlot(screen)
+	r.drawInspector(screen)
+	r.drawTuning(screen)
+	r.logPanel.Draw(screen, 0, r.screenHeight-r.logPanel.height(), r.screenWidth)
+	r.captureFrame(screen)
+}
+
+// drawEstimate projects an estimate with the fitted projector and draws its uncertainty
+// ellipse, or a fixed-size marker if there is no covariance or the projection is nonlinear.
+func (r *Renderer) drawEstimate(screen *ebiten.Image, est multilateration.Solution) {
+	center, err := r.projector.Transform(est.Position)
+	if err != nil || len(center) < 2 {
+		return
+	}
+	ex, ey := r.worldToScreen(center[0], center[1])
+	if lp, linear := r.projector.(linearProjector); linear {
+		cov2, ok := lp.ProjectCovariance(est.Covariance)
+		if major, minor, angle, axesOk := ellipseAxes(cov2); ok && axesOk {
+			// Keep tiny ellipses visible
+			major = math.Max(major*r.scale, objectRadiusOnScreen*predictedPosRadiusScale)
+			minor = math.Max(minor*r.scale, objectRadiusOnScreen*predictedPosRadiusScale)
+			drawEllipse(screen, ex, ey, float32(major), float32(minor), float32(angle), predictedPosColor, targetColorBase)
+			return
+		}
+	}
+	vector.DrawFilledCircle(screen, ex, ey, float32(objectRadiusOnScreen*predictedPosRadiusScale), predictedPosColor, true)
 }
 
 func (r *Renderer) drawDebugInfo(screen *ebiten.Image) {
+	f := r.activeFormat()
 	simTime := r.sim.GetCurrentTime()
 	msg := fmt.Sprintf("Время симуляции: %.2fs\n", simTime)
+	if r.Paused() {
+		msg += "Пауза (Space: продолжить, →/.: шаг)\n"
+	} else {
+		msg += fmt.Sprintf("Скорость: x%g (+/-: изменить, Space: пауза)\n", r.Speed())
+	}
 	msg += fmt.Sprintf("FPS: %.1f, TPS: %.1f\n", ebiten.ActualFPS(), ebiten.ActualTPS())
-	msg += fmt.Sprintf("Размерность: %dD -> 2D (PCA)\n", r.sim.GetDimension()) // GetDimension() method needed
+	if r.sim.AdaptiveStepping() != nil {
+		rate, _ := r.sim.Metrics().Gauge(simulation.MetricStepRate)
+		msg += fmt.Sprintf("Адаптивный шаг: %.1f шагов/с\n", rate)
+	}
+	msg += fmt.Sprintf("Размерность: %dD -> 2D (%v, P: сменить)\n", r.sim.GetDimension(), r.projector)
+	if r.timelineMode() {
+		msg += "1D: по вертикали время, следы уходят вниз (T: скрыть)\n"
+	}
 
 	var totalError float64
 	var numErrors int
@@ -250,31 +432,25 @@ func (r *Renderer) drawDebugInfo(screen *ebiten.Image) {
 	avgError := 0.0
 	if numErrors > 0 {
 		avgError = totalError / float64(numErrors)
-		msg += fmt.Sprintf("Средняя ошибка локализации: %.3f\n", avgError)
+		msg += fmt.Sprintf("Средняя ошибка локализации: %s\n", f.Float(avgError))
 	} else {
 		msg += "Средняя ошибка локализации: N/A\n"
 	}
 
 	// Display object counts
 	msg += fmt.Sprintf("Сенсоры: %d, Цели: %d\n", len(r.sim.GetSensors()), len(r.sim.GetTargets()))
+	if classes := classSummary(r.sim.GetTargets()); classes != "" {
+		msg += fmt.Sprintf("Классы целей: %s\n", classes)
+	}
+	msg += fmt.Sprintf("Потери измерений: %.1f%%\n", r.sim.DropoutRate("")*100)
 
-	// Display detailed info for each target
-	targetInfoLines := []string{"Информация по целям:"}
-	for _, target := range r.sim.GetTargets() {
-		line := fmt.Sprintf("  %s: Истин. %s", target.GetID(), target.GetPosition())
-		est, estOk := r.sim.GetLastEstimate(target.GetID())
-		if estOk && est.Position != nil {
-			line += fmt.Sprintf(" | Оценка %s (Res: %.2f)", est.Position, est.ResidualError)
-		} else {
-			line += " | Оценка: нет"
-		}
-		locErr, errOk := r.sim.GetLastLocalizationError(target.GetID())
-		if errOk && locErr >= 0 {
-			line += fmt.Sprintf(" (Err: %.2f)", locErr)
-		}
-		targetInfoLines = append(targetInfoLines, line)
+	msg += "Клик по объекту: инспектор, R: линейка, E: экспорт треков, V: диаграмма Вороного, A: аннотации, T: следы, O: график ошибки\n"
+	msg += "Перетаскивание объектов, G: сетка, Enter: ввод координат, K: настройка параметров, H: карта GDOP / покрытия сенсорами (зелёный: хорошо, красный: плохо, серый: нет покрытия)\n"
+	cameraMode := "авто"
+	if r.camera.manual {
+		cameraMode = "ручная"
 	}
-	msg += strings.Join(targetInfoLines, "\n")
+	msg += fmt.Sprintf("Колесо: масштаб, средняя кнопка/Ctrl+перетаскивание: сдвиг, C: автомасштаб (камера: %s)\n", cameraMode)
 
 	ebitenutil.DebugPrint(screen, msg)
 }
@@ -0,0 +1,137 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const scaleBarTargetPixels = 120.0 // Approximate on-screen length of the scale bar
+
+var rulerColor = color.RGBA{20, 20, 20, 255} // Почти чёрный
+
+// ruler measures the distance between two clicked points. Points are kept in world
+// coordinates when the projector can be inverted, so the ruler stays attached to the
+// scene while the projection changes; otherwise they are kept in the projection plane.
+// Keys: R toggles the tool, Escape clears it.
+type ruler struct {
+	active bool
+	points []common.Vector // 0-2 points
+	world  bool            // Whether points are world coordinates
+}
+
+// handleRuler processes the ruler keys and clicks.
+func (r *Renderer) handleRuler() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		r.ruler.active = !r.ruler.active
+		r.ruler.points = nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		r.ruler.points = nil
+	}
+	if !r.ruler.active || !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+
+	mx, my := ebiten.CursorPosition()
+	point, world := r.screenToPoint(float64(mx), float64(my))
+	if len(r.ruler.points) == 2 || world != r.ruler.world {
+		r.ruler.points = nil // Start a new measurement
+	}
+	r.ruler.points = append(r.ruler.points, point)
+	r.ruler.world = world
+}
+
+// screenToPoint maps a screen position to world coordinates if the projector is
+// invertible (world = true), or to the projection plane otherwise.
+func (r *Renderer) screenToPoint(sx, sy float64) (common.Vector, bool) {
+	px, py := r.screenToWorld(sx, sy)
+	projected := common.Vector{px, py}
+	if inv, ok := r.projector.(invertibleProjector); ok {
+		if world, ok := inv.UnprojectPoint(projected); ok {
+			return world, true
+		}
+	}
+	return projected, false
+}
+
+// pointToScreen is the inverse of screenToPoint for the current projection.
+func (r *Renderer) pointToScreen(point common.Vector, world bool) (float32, float32, bool) {
+	if world {
+		var err error
+		if point, err = r.projector.Transform(point); err != nil {
+			return 0, 0, false
+		}
+	}
+	if len(point) < 2 {
+		return 0, 0, false
+	}
+	x, y := r.worldToScreen(point[0], point[1])
+	return x, y, true
+}
+
+// drawRuler draws the measured segment and its length.
+func (r *Renderer) drawRuler(screen *ebiten.Image) {
+	if !r.ruler.active {
+		return
+	}
+	var screenPoints [][2]float32
+	for _, p := range r.ruler.points {
+		if x, y, ok := r.pointToScreen(p, r.ruler.world); ok {
+			screenPoints = append(screenPoints, [2]float32{x, y})
+			vector.DrawFilledCircle(screen, x, y, 3, rulerColor, true)
+		}
+	}
+	mx, my := ebiten.CursorPosition()
+	label := "Линейка: кликните две точки (R: выкл, Esc: сброс)"
+	if len(screenPoints) == 2 {
+		a, b := screenPoints[0], screenPoints[1]
+		vector.StrokeLine(screen, a[0], a[1], b[0], b[1], 1.5, rulerColor, true)
+		dist, _ := r.ruler.points[0].Distance(r.ruler.points[1])
+		label = fmt.Sprintf("Расстояние: %s", r.activeFormat().Float(dist))
+		if !r.ruler.world {
+			label += " (в плоскости проекции)"
+		}
+		mx, my = int((a[0]+b[0])/2), int((a[1]+b[1])/2)
+	}
+	ebitenutil.DebugPrintAt(screen, label, mx+10, my+10)
+}
+
+// drawScaleBar draws a bar of a round world length in the bottom-right corner.
+// PCA bases are orthonormal, so lengths in the projection plane are world lengths.
+func (r *Renderer) drawScaleBar(screen *ebiten.Image) {
+	if r.scale <= 0 {
+		return
+	}
+	length := niceLength(scaleBarTargetPixels / r.scale)
+	pixels := float32(length * r.scale)
+	x1 := float32(r.screenWidth) - padding/2
+	x0 := x1 - pixels
+	y := float32(r.screenHeight-r.logPanel.height()) - 20
+	vector.StrokeLine(screen, x0, y, x1, y, 2, rulerColor, false)
+	vector.StrokeLine(screen, x0, y-5, x0, y+5, 2, rulerColor, false)
+	vector.StrokeLine(screen, x1, y-5, x1, y+5, 2, rulerColor, false)
+	ebitenutil.DebugPrintAt(screen, r.activeFormat().Float(length), int(x0), int(y)-20)
+}
+
+// niceLength rounds x down to 1, 2 or 5 times a power of ten.
+func niceLength(x float64) float64 {
+	if x <= 0 || math.IsInf(x, 0) || math.IsNaN(x) {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(x)))
+	switch normalized := x / magnitude; {
+	case normalized >= 5:
+		return 5 * magnitude
+	case normalized >= 2:
+		return 2 * magnitude
+	default:
+		return magnitude
+	}
+}
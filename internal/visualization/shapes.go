@@ -0,0 +1,75 @@
+package visualization
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	ellipseSegments = 48
+	// ellipseConfidenceScale is sqrt of the 95% quantile of chi-square with 2 degrees of
+	// freedom: the ellipse contains the true position with 95% probability.
+	ellipseConfidenceScale = 2.4477
+)
+
+var whitePixel *ebiten.Image // Texture source for DrawTriangles, created lazily
+
+// ellipseAxes returns the semi-axes (in world units, already scaled to 95%) and the
+// rotation angle of the uncertainty ellipse of a 2x2 covariance matrix.
+func ellipseAxes(cov [][]float64) (major, minor, angle float64, ok bool) {
+	if len(cov) < 2 || len(cov[0]) < 2 || len(cov[1]) < 2 {
+		return 0, 0, 0, false
+	}
+	a, b, c := cov[0][0], (cov[0][1]+cov[1][0])/2, cov[1][1]
+	// Eigenvalues of the symmetric matrix [[a b] [b c]]
+	mean := (a + c) / 2
+	spread := math.Sqrt(((a-c)/2)*((a-c)/2) + b*b)
+	l1, l2 := mean+spread, mean-spread
+	if l1 < 0 || math.IsNaN(l1) || math.IsInf(l1, 0) {
+		return 0, 0, 0, false
+	}
+	l2 = math.Max(l2, 0)
+	angle = 0.5 * math.Atan2(2*b, a-c)
+	return ellipseConfidenceScale * math.Sqrt(l1), ellipseConfidenceScale * math.Sqrt(l2), angle, true
+}
+
+// drawEllipse fills an ellipse centered at (cx, cy) in screen coordinates and outlines it.
+func drawEllipse(dst *ebiten.Image, cx, cy, major, minor, angle float32, fill, outline color.Color) {
+	if whitePixel == nil {
+		img := ebiten.NewImage(3, 3)
+		img.Fill(color.White)
+		whitePixel = img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+	}
+	cosA, sinA := float32(math.Cos(float64(angle))), float32(math.Sin(float64(angle)))
+	points := make([][2]float32, ellipseSegments)
+	for i := range points {
+		t := 2 * math.Pi * float64(i) / ellipseSegments
+		u, v := major*float32(math.Cos(t)), minor*float32(math.Sin(t))
+		points[i] = [2]float32{cx + u*cosA - v*sinA, cy + u*sinA + v*cosA}
+	}
+
+	// Triangle fan around the center (the ellipse is convex)
+	r, g, b, a := fill.RGBA()
+	vertex := func(x, y float32) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX: x, DstY: y, SrcX: 1, SrcY: 1,
+			ColorR: float32(r) / 0xffff, ColorG: float32(g) / 0xffff, ColorB: float32(b) / 0xffff, ColorA: float32(a) / 0xffff,
+		}
+	}
+	vertices := []ebiten.Vertex{vertex(cx, cy)}
+	indices := make([]uint16, 0, ellipseSegments*3)
+	for i, p := range points {
+		vertices = append(vertices, vertex(p[0], p[1]))
+		indices = append(indices, 0, uint16(i+1), uint16((i+1)%ellipseSegments+1))
+	}
+	dst.DrawTriangles(vertices, indices, whitePixel, &ebiten.DrawTrianglesOptions{AntiAlias: true})
+
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		vector.StrokeLine(dst, p[0], p[1], q[0], q[1], 1, outline, true)
+	}
+}
@@ -0,0 +1,80 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 1D scenes have no vertical extent, so the renderer spends the vertical axis on time:
+// objects move along a horizontal line near the top of the screen and the trails scroll
+// down below it, the oldest step at the bottom.
+
+const timelineLineFraction = 0.2 // Height of the position line as a fraction of the screen
+
+var (
+	timelineSensorColor = color.RGBA{0, 0, 200, 60}   // Sensor positions over time
+	timelineAxisColor   = color.RGBA{80, 80, 80, 255} // Time axis and its ticks
+	sensorRangeColor    = color.RGBA{0, 0, 200, 90}   // Detection range on the line
+)
+
+// timelineMode reports whether the scene is drawn as a position-time diagram.
+func (r *Renderer) timelineMode() bool {
+	return r.sim.GetDimension() == 1
+}
+
+// timelineStepPixels returns how far the trails move down per step of age,
+// 0 outside of the timeline mode. The projectors map a line onto y = 0.
+func (r *Renderer) timelineStepPixels() float32 {
+	if !r.timelineMode() || r.trails.length < 2 {
+		return 0
+	}
+	_, lineY := r.worldToScreen(0, 0)
+	bottom := float32(r.screenHeight-r.logPanel.height()) - padding
+	if bottom <= lineY {
+		return 0
+	}
+	return (bottom - lineY) / float32(r.trails.length-1)
+}
+
+// drawSensorTimeline draws a 1D sensor: its detection range as a segment of the line
+// and a vertical line through the time axis, since sensors do not move along it.
+func (r *Renderer) drawSensorTimeline(screen *ebiten.Image, sensor *simulation.Sensor, sx, sy float32) {
+	if radius := float32(sensor.DetectionRadius() * r.scale); radius > 0 {
+		vector.StrokeLine(screen, sx-radius, sy, sx+radius, sy, 6, sensorRangeColor, false)
+	}
+	if drop := r.timelineStepPixels(); drop > 0 {
+		vector.StrokeLine(screen, sx, sy, sx, sy+drop*float32(r.trails.length-1), 1, timelineSensorColor, false)
+	}
+}
+
+// drawTimelineAxis draws the time axis at the left edge with the age of the oldest
+// trail step at the bottom.
+func (r *Renderer) drawTimelineAxis(screen *ebiten.Image) {
+	drop := r.timelineStepPixels()
+	if drop == 0 || r.trails.hidden {
+		return
+	}
+	_, lineY := r.worldToScreen(0, 0)
+	bottom := lineY + drop*float32(r.trails.length-1)
+	x := float32(padding / 2)
+	vector.StrokeLine(screen, x, lineY, x, bottom, 1, timelineAxisColor, false)
+	vector.StrokeLine(screen, x-4, lineY, x+4, lineY, 1, timelineAxisColor, false)
+	vector.StrokeLine(screen, x-4, bottom, x+4, bottom, 1, timelineAxisColor, false)
+
+	// The longest trail spans the axis
+	span := 0.0
+	for _, t := range r.trails.byID {
+		if len(t.times) == r.trails.length {
+			span = max(span, t.times[len(t.times)-1]-t.times[0])
+		}
+	}
+	ebitenutil.DebugPrintAt(screen, "t", int(x)+6, int(lineY)-16)
+	if span > 0 {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("-%.1f с", span), int(x)+6, int(bottom)-16)
+	}
+}
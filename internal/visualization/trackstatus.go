@@ -0,0 +1,27 @@
+package visualization
+
+import (
+	"image/color"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// trackStatusColors outline a target by the state of its track.
+var trackStatusColors = map[simulation.TrackState]color.RGBA{
+	simulation.TrackInitializing: {230, 200, 0, 255},   // Жёлтый: трасса не подтверждена
+	simulation.TrackTracking:     {0, 180, 0, 255},     // Зелёный: сопровождается
+	simulation.TrackCoasting:     {255, 140, 0, 255},   // Оранжевый: экстраполяция без измерений
+	simulation.TrackLost:         {120, 120, 120, 255}, // Серый: трасса потеряна
+}
+
+// drawTrackStatus outlines a target at (x, y) in the colour of its track state, nothing
+// before its first step.
+func (r *Renderer) drawTrackStatus(screen *ebiten.Image, targetID string, x, y float32) {
+	status, ok := r.sim.GetTrackStatus(targetID)
+	if !ok {
+		return
+	}
+	vector.StrokeCircle(screen, x, y, float32(objectRadiusOnScreen)+3, 2, trackStatusColors[status.State], true)
+}
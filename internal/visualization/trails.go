@@ -0,0 +1,126 @@
+package visualization
+
+import (
+	"image/color"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const defaultTrailLength = 120 // Steps kept per target
+
+var (
+	truthTrailColor    = color.RGBA{200, 0, 0, 255}     // Тёмно-красный
+	estimateTrailColor = color.RGBA{255, 140, 140, 255} // Светло-красный
+)
+
+// trail is the recent history of one target. Positions are kept in simulation
+// coordinates and projected when drawn, so trails follow changes of the projection.
+type trail struct {
+	truth     []common.Vector
+	estimates []common.Vector // nil entries where the target was not localized
+	times     []float64       // Simulation time of each entry
+}
+
+// trails draws the fading true and estimated trajectories of the targets (T toggles them).
+type trails struct {
+	hidden bool
+	length int
+	byID   map[string]*trail
+}
+
+// SetTrailLength sets how many steps of history are drawn per target, 0 disables trails.
+func (r *Renderer) SetTrailLength(steps int) {
+	r.trails.length = max(steps, 0)
+	for _, t := range r.trails.byID {
+		t.trim(r.trails.length)
+	}
+}
+
+// recordTrails appends the positions of the current step.
+func (r *Renderer) recordTrails(report simulation.StepReport) {
+	if r.trails.length == 0 {
+		return
+	}
+	for _, rep := range report.Targets {
+		st, ok := report.State.Target(rep.TargetID)
+		if !ok {
+			continue
+		}
+		t := r.trails.byID[rep.TargetID]
+		if t == nil {
+			t = &trail{}
+			r.trails.byID[rep.TargetID] = t
+		}
+		var estimate common.Vector
+		if rep.Outcome == simulation.OutcomeLocalized {
+			estimate = st.Estimate.Position
+		}
+		t.truth = append(t.truth, st.Position)
+		t.estimates = append(t.estimates, estimate)
+		t.times = append(t.times, report.Time)
+		t.trim(r.trails.length)
+	}
+}
+
+func (t *trail) trim(length int) {
+	if drop := len(t.truth) - length; drop > 0 {
+		t.truth = t.truth[drop:]
+		t.estimates = t.estimates[drop:]
+		t.times = t.times[drop:]
+	}
+}
+
+// handleTrailKey toggles the trails.
+func (r *Renderer) handleTrailKey() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		r.trails.hidden = !r.trails.hidden
+	}
+}
+
+// drawTrails draws each trail as a polyline fading out towards its oldest point.
+func (r *Renderer) drawTrails(screen *ebiten.Image) {
+	if r.trails.hidden {
+		return
+	}
+	drop := r.timelineStepPixels()
+	for id, t := range r.trails.byID {
+		if _, ok := r.sim.GetObject(id); !ok {
+			delete(r.trails.byID, id) // Target was removed
+			continue
+		}
+		r.drawPolyline(screen, t.truth, truthTrailColor, 2, drop)
+		r.drawPolyline(screen, t.estimates, estimateTrailColor, 1.5, drop)
+	}
+}
+
+// drawPolyline strokes consecutive points with increasing opacity; nil points break the line.
+// Each point is moved down by drop pixels per step of age (see timeline.go).
+func (r *Renderer) drawPolyline(screen *ebiten.Image, points []common.Vector, c color.RGBA, width, drop float32) {
+	if len(points) < 2 {
+		return
+	}
+	var prevX, prevY float32
+	havePrev := false
+	for i, p := range points {
+		if p == nil {
+			havePrev = false
+			continue
+		}
+		x, y, ok := r.pointToScreen(p, true)
+		if !ok {
+			havePrev = false
+			continue
+		}
+		y += drop * float32(len(points)-1-i)
+		if havePrev {
+			faded := c
+			faded.A = uint8(float64(c.A) * float64(i) / float64(len(points)-1))
+			vector.StrokeLine(screen, prevX, prevY, x, y, width, faded, true)
+		}
+		prevX, prevY, havePrev = x, y, true
+	}
+}
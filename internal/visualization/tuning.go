@@ -0,0 +1,200 @@
+package visualization
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/multilateration"
+	"multilateration-sim/internal/simulation"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	tuningWidth  = 340  // Pixels
+	tuningFactor = 1.25 // Change of a value per arrow press
+	minNoise     = 0.01 // Smaller noise sigmas snap to noiseless
+)
+
+// Rows of the tuning panel.
+const (
+	tuneNoise = iota
+	tuneRadius
+	tuneSpeed
+	tuneSolver
+	tuneRows
+)
+
+// tuningSolver is a solver selectable in the tuning panel.
+type tuningSolver struct {
+	name  string
+	solve multilateration.SolveFunc // nil restores the default solver
+}
+
+var tuningSolvers = []tuningSolver{
+	{"МНК (по умолчанию)", nil},
+	{"взвешенный МНК", multilateration.SolveWeightedLeastSquares},
+	{"нелинейный МНК", multilateration.RobustSolver(multilateration.RobustOptions{HuberThreshold: math.Inf(1), MaxIterations: 20, Tolerance: 1e-6})},
+	{"робастный (Хьюбер)", multilateration.RobustSolver(multilateration.DefaultRobustOptions())},
+}
+
+// tuningPanel changes simulation parameters while it runs (K toggles it): arrows up and
+// down choose a row, left and right change its value for all sensors or targets.
+type tuningPanel struct {
+	open   bool
+	loaded bool // Values were read from the simulation
+	row    int
+	noise  float64 // Range noise sigma, 0 = noiseless
+	radius float64 // Detection radius, 0 = unlimited
+	speed  float64 // Target speed limit, 0 = unlimited
+	solver int     // Index in tuningSolvers, -1 = as configured at start
+}
+
+// loadTuning reads the starting values from the first sensor and random-walk target.
+func (r *Renderer) loadTuning() {
+	t := &r.tuning
+	t.loaded, t.solver = true, -1
+	if sensors := r.sim.GetSensors(); len(sensors) > 0 {
+		t.noise = math.Sqrt(sensors[0].RangeVariance())
+		t.radius = sensors[0].DetectionRadius()
+	}
+	for _, target := range r.sim.GetTargets() {
+		if walk, ok := target.MotionModel().(*simulation.RandomWalkMotion); ok {
+			t.speed = walk.MaxSpeed
+			break
+		}
+	}
+}
+
+// handleTuning processes the keys of the tuning panel. Returns true while the panel is
+// open and owns the keyboard.
+func (r *Renderer) handleTuning() bool {
+	t := &r.tuning
+	if !t.open {
+		if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+			if !t.loaded {
+				r.loadTuning()
+			}
+			t.open = true
+			return true
+		}
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		t.open = false
+		return false
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		t.row = (t.row + tuneRows - 1) % tuneRows
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		t.row = (t.row + 1) % tuneRows
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		r.adjustTuning(1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		r.adjustTuning(-1)
+	}
+	return true
+}
+
+// scaleTuned steps a value up or down by tuningFactor. 0 (noiseless or unlimited) steps
+// to base; values below floor step to 0 when allowed.
+func scaleTuned(value float64, dir int, base, floor float64, zeroAllowed bool) float64 {
+	switch {
+	case value == 0 && dir > 0:
+		return base
+	case value == 0:
+		return 0
+	case dir > 0:
+		return value * tuningFactor
+	}
+	value /= tuningFactor
+	if value < floor {
+		if zeroAllowed {
+			return 0
+		}
+		return floor
+	}
+	return value
+}
+
+// adjustTuning changes the selected row in direction dir (+1 or -1) and applies it.
+func (r *Renderer) adjustTuning(dir int) {
+	t := &r.tuning
+	f := r.activeFormat()
+	var err error
+	var msg string
+	switch t.row {
+	case tuneNoise:
+		t.noise = scaleTuned(t.noise, dir, 0.1, minNoise, true)
+		err = r.sim.SetSensorNoiseSigma(t.noise)
+		msg = "шум дальности σ = " + f.Float(t.noise)
+	case tuneRadius:
+		t.radius = scaleTuned(t.radius, dir, 10, 1, false)
+		err = r.sim.SetSensorDetectionRadius(t.radius)
+		msg = "радиус обнаружения = " + unlimited(f, t.radius)
+	case tuneSpeed:
+		t.speed = scaleTuned(t.speed, dir, 10, 1, false)
+		var changed int
+		changed, err = r.sim.SetTargetMaxSpeed(t.speed)
+		msg = fmt.Sprintf("макс. скорость целей = %s (%d целей со случайным блужданием)", unlimited(f, t.speed), changed)
+	case tuneSolver:
+		t.solver = (max(t.solver, 0) + dir + len(tuningSolvers)) % len(tuningSolvers)
+		r.sim.SetSolver(tuningSolvers[t.solver].solve)
+		msg = "решатель: " + tuningSolvers[t.solver].name
+	}
+	if err != nil {
+		r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityError, Message: fmt.Sprintf("настройка: %v", err)})
+		return
+	}
+	r.logPanel.Add(LogEntry{Time: r.sim.GetCurrentTime(), Severity: SeverityInfo, Message: msg})
+}
+
+// unlimited formats a limit where 0 means none.
+func unlimited(f common.Format, v float64) string {
+	if v == 0 {
+		return "без ограничения"
+	}
+	return f.Float(v)
+}
+
+// drawTuning draws the open tuning panel in the top-right corner, left of the inspector.
+func (r *Renderer) drawTuning(screen *ebiten.Image) {
+	t := &r.tuning
+	if !t.open {
+		return
+	}
+	f := r.activeFormat()
+	solver := "как задано при запуске"
+	if t.solver >= 0 {
+		solver = tuningSolvers[t.solver].name
+	}
+	rows := [tuneRows]string{
+		"Шум дальности σ: " + f.Float(t.noise),
+		"Радиус обнаружения: " + unlimited(f, t.radius),
+		"Макс. скорость целей: " + unlimited(f, t.speed),
+		"Решатель: " + solver,
+	}
+	lines := []string{"Настройка (K/Esc: закрыть)", ""}
+	for i, row := range rows {
+		marker := "  "
+		if i == t.row {
+			marker = "> "
+		}
+		lines = append(lines, marker+row)
+	}
+	lines = append(lines, "", "↑/↓: параметр, ←/→: изменить")
+
+	x := r.screenWidth - tuningWidth
+	if r.inspectorLines() != nil {
+		x -= inspectorWidth
+	}
+	height := (len(lines) + 1) * logLineHeight
+	vector.DrawFilledRect(screen, float32(x), 0, tuningWidth, float32(height), color.RGBA{0, 0, 0, 150}, false)
+	ebitenutil.DebugPrintAt(screen, strings.Join(lines, "\n"), x+6, 4)
+}
@@ -0,0 +1,104 @@
+package visualization
+
+import (
+	"image/color"
+	"math"
+	"multilateration-sim/internal/common"
+	"multilateration-sim/internal/simulation"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const voronoiCellPixels = 6 // Resolution of the overlay raster
+
+// voronoiPalette colors the regions, cycling for more sensors.
+var voronoiPalette = []color.RGBA{
+	{31, 119, 180, 255}, {255, 127, 14, 255}, {44, 160, 44, 255}, {214, 39, 40, 255},
+	{148, 103, 189, 255}, {140, 86, 75, 255}, {227, 119, 194, 255}, {188, 189, 34, 255},
+	{23, 190, 207, 255}, {127, 127, 127, 255},
+}
+
+// voronoiOverlay partitions the screen by the nearest sensor (V toggles it).
+type voronoiOverlay struct {
+	enabled bool
+	image   *ebiten.Image // Low-resolution raster, scaled up when drawn
+	pixels  []byte
+}
+
+// handleVoronoiKey toggles the overlay.
+func (r *Renderer) handleVoronoiKey() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		r.voronoi.enabled = !r.voronoi.enabled
+	}
+}
+
+// drawVoronoi rasterizes the nearest-sensor regions of the visible area. With an
+// invertible projector distances are measured in the simulation space, so for N-D
+// scenes the overlay shows the partition of the projection plane; otherwise they are
+// measured between projected points.
+func (r *Renderer) drawVoronoi(screen *ebiten.Image) {
+	sensors := r.sim.GetSensors()
+	if !r.voronoi.enabled || len(sensors) == 0 || r.screenWidth == 0 || r.screenHeight == 0 {
+		return
+	}
+	cols := (r.screenWidth + voronoiCellPixels - 1) / voronoiCellPixels
+	rows := (r.screenHeight + voronoiCellPixels - 1) / voronoiCellPixels
+	if r.voronoi.image == nil || r.voronoi.image.Bounds().Dx() != cols || r.voronoi.image.Bounds().Dy() != rows {
+		r.voronoi.image = ebiten.NewImage(cols, rows)
+		r.voronoi.pixels = make([]byte, 4*cols*rows)
+	}
+
+	owners := make([]int, cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			sx := (float64(col) + 0.5) * voronoiCellPixels
+			sy := (float64(row) + 0.5) * voronoiCellPixels
+			owners[row*cols+col] = r.nearestSensor(sensors, sx, sy)
+		}
+	}
+
+	for i, owner := range owners {
+		row, col := i/cols, i%cols
+		c := color.RGBA{}
+		if owner >= 0 {
+			c = voronoiPalette[owner%len(voronoiPalette)]
+			c.A = 40
+			if (col+1 < cols && owners[i+1] != owner) || (row+1 < rows && owners[i+cols] != owner) {
+				c = color.RGBA{60, 60, 60, 160} // Handover boundary
+			}
+		}
+		// Premultiplied alpha
+		r.voronoi.pixels[4*i] = byte(uint16(c.R) * uint16(c.A) / 255)
+		r.voronoi.pixels[4*i+1] = byte(uint16(c.G) * uint16(c.A) / 255)
+		r.voronoi.pixels[4*i+2] = byte(uint16(c.B) * uint16(c.A) / 255)
+		r.voronoi.pixels[4*i+3] = c.A
+	}
+	r.voronoi.image.WritePixels(r.voronoi.pixels)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(voronoiCellPixels, voronoiCellPixels)
+	screen.DrawImage(r.voronoi.image, op)
+}
+
+// nearestSensor returns the index of the sensor closest to a screen position, -1 if none
+// is projected.
+func (r *Renderer) nearestSensor(sensors []*simulation.Sensor, sx, sy float64) int {
+	point, world := r.screenToPoint(sx, sy)
+	best, bestDist := -1, math.Inf(1)
+	for i, sen := range sensors {
+		var pos common.Vector
+		if world {
+			pos = sen.GetPosition()
+		} else if p, ok := r.projectedCoords[sen.GetID()]; ok {
+			pos = p
+		}
+		if pos.Dimension() != point.Dimension() {
+			continue
+		}
+		if d, err := pos.Distance(point); err == nil && d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}